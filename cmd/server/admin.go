@@ -0,0 +1,176 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+	"mcpify/pkg/mcp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// adminTool is a registered tool as listed by the admin API
+type adminTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Debug       bool   `json:"debug"`
+}
+
+// newAdminHandler serves the admin API:
+//
+//	GET  /tools                 registered tools and whether they are enabled
+//	POST /tools/{name}/enable   make a disabled tool available again
+//	POST /tools/{name}/disable  hide a tool from tools/list and reject calls to it
+//	POST /tools/{name}/debug    log the upstream requests and responses of matching tools
+//	DELETE /tools/{name}/debug  stop logging them, unless debug is configured
+//	GET  /config                the running configuration as YAML, with credentials redacted
+//	POST /reload                reload the configuration, as on SIGHUP
+//	GET  /sessions              active MCP sessions of the HTTP transport
+func newAdminHandler(adminCfg config.AdminConfig, server *mcp.Server, reload *reloader) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /tools", func(w http.ResponseWriter, r *http.Request) {
+		tools := []adminTool{}
+		for _, schema := range server.Tools() {
+			tools = append(tools, adminTool{
+				Name:        schema.Name,
+				Description: schema.Description,
+				Enabled:     server.IsToolEnabled(schema.Name),
+				Debug:       server.IsToolDebug(schema.Name),
+			})
+		}
+		writeAdminJSON(w, http.StatusOK, tools)
+	})
+
+	setEnabled := func(enabled bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			name := r.PathValue("name")
+			if err := server.SetToolEnabled(name, enabled); err != nil {
+				writeAdminError(w, http.StatusNotFound, err)
+				return
+			}
+			log.Printf("Admin API: tool %s enabled=%t", name, enabled)
+			writeAdminJSON(w, http.StatusOK, adminTool{Name: name, Enabled: enabled})
+		}
+	}
+	mux.HandleFunc("POST /tools/{name}/enable", setEnabled(true))
+	mux.HandleFunc("POST /tools/{name}/disable", setEnabled(false))
+
+	// Debug logging is set for a tool name or a pattern of names, where "*" matches any characters
+	setDebug := func(enabled bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			pattern := r.PathValue("name")
+			tools := []adminTool{}
+			for _, schema := range server.Tools() {
+				if !config.MatchPath(pattern, schema.Name) {
+					continue
+				}
+				if err := server.SetToolDebug(schema.Name, enabled); err != nil {
+					continue
+				}
+				tools = append(tools, adminTool{Name: schema.Name, Enabled: server.IsToolEnabled(schema.Name), Debug: enabled})
+			}
+			if len(tools) == 0 {
+				writeAdminError(w, http.StatusNotFound, fmt.Errorf("%w: %s", mcp.ErrToolNotFound, pattern))
+				return
+			}
+			log.Printf("Admin API: debug=%t for %d tools matching %s", enabled, len(tools), pattern)
+			writeAdminJSON(w, http.StatusOK, tools)
+		}
+	}
+	mux.HandleFunc("POST /tools/{name}/debug", setDebug(true))
+	mux.HandleFunc("DELETE /tools/{name}/debug", setDebug(false))
+
+	mux.HandleFunc("GET /config", func(w http.ResponseWriter, r *http.Request) {
+		data, err := yaml.Marshal(reload.Config().Redacted())
+		if err != nil {
+			writeAdminError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write(data)
+	})
+
+	mux.HandleFunc("POST /reload", func(w http.ResponseWriter, r *http.Request) {
+		log.Println("Admin API: reloading configuration...")
+		if err := reload.Reload(); err != nil {
+			log.Printf("Configuration reload failed, keeping previous configuration: %v", err)
+			writeAdminError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"tools": len(server.Tools())})
+	})
+
+	mux.HandleFunc("GET /sessions", func(w http.ResponseWriter, r *http.Request) {
+		sessions := []types.Session{}
+		if transport := reload.Transport(); transport != nil {
+			sessions = transport.Sessions()
+		}
+		writeAdminJSON(w, http.StatusOK, sessions)
+	})
+
+	return requireAdminToken(adminCfg, mux)
+}
+
+// requireAdminToken rejects requests without the admin bearer token. The token is resolved
+// per request so a rotated token_file takes effect without a restart.
+func requireAdminToken(adminCfg config.AdminConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected, err := adminCfg.TokenValue()
+		if err != nil || expected == "" {
+			log.Printf("Admin API: token unavailable: %v", err)
+			http.Error(w, "Admin token unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		authorization := r.Header.Get("Authorization")
+		const prefix = "bearer "
+		token := ""
+		if len(authorization) > len(prefix) && strings.EqualFold(authorization[:len(prefix)], prefix) {
+			token = strings.TrimSpace(authorization[len(prefix):])
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcpify-admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeAdminJSON writes an admin API response
+func writeAdminJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeAdminError writes an admin API error as {"error": "..."}
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	writeAdminJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// startAdminServer serves the admin API until the listener fails
+func startAdminServer(adminCfg config.AdminConfig, server *mcp.Server, reload *reloader) error {
+	address := net.JoinHostPort(adminCfg.Host, strconv.Itoa(adminCfg.Port))
+	log.Printf("Starting admin API on %s", address)
+	err := http.ListenAndServe(address, newAdminHandler(adminCfg, server, reload))
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("admin API: %w", err)
+	}
+	return nil
+}
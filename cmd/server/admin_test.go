@@ -0,0 +1,192 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mcpify/internal/config"
+	"mcpify/pkg/mcp"
+)
+
+func newAdminTestServer(t *testing.T) (*httptest.Server, *mcp.Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	writeReloadConfig(t, configPath, specPath, "")
+
+	opts := options{configPath: configPath}
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.OpenAPI.Auth = config.AuthConfig{Type: "bearer", Token: "upstream-secret"}
+	server := mcp.NewServer()
+	if _, err := buildTools(server, cfg, nil); err != nil {
+		t.Fatalf("Failed to build tools: %v", err)
+	}
+
+	admin := config.AdminConfig{Enabled: true, Token: "admin-token"}
+	httpServer := httptest.NewServer(newAdminHandler(admin, server, newReloader(opts, server, cfg)))
+	t.Cleanup(httpServer.Close)
+	return httpServer, server, configPath
+}
+
+func adminRequest(t *testing.T, method, url, token string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = resp.Body.Close()
+	})
+	return resp
+}
+
+func TestAdminHandler_RequiresToken(t *testing.T) {
+	httpServer, _, _ := newAdminTestServer(t)
+
+	for _, token := range []string{"", "wrong-token"} {
+		resp := adminRequest(t, http.MethodGet, httpServer.URL+"/tools", token)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401 with token %q, got %d", token, resp.StatusCode)
+		}
+	}
+	if resp := adminRequest(t, http.MethodGet, httpServer.URL+"/tools", "admin-token"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 with the admin token, got %d", resp.StatusCode)
+	}
+}
+
+func TestAdminHandler_EnableDisableTools(t *testing.T) {
+	httpServer, server, _ := newAdminTestServer(t)
+
+	resp := adminRequest(t, http.MethodPost, httpServer.URL+"/tools/get_orders/disable", "admin-token")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 disabling get_orders, got %d", resp.StatusCode)
+	}
+	if names := listToolNames(t, server); len(names) != 1 || names[0] != "get_users" {
+		t.Errorf("Expected get_orders to be hidden from tools/list, got %v", names)
+	}
+
+	resp = adminRequest(t, http.MethodGet, httpServer.URL+"/tools", "admin-token")
+	var tools []adminTool
+	if err := json.NewDecoder(resp.Body).Decode(&tools); err != nil {
+		t.Fatalf("Failed to decode tools: %v", err)
+	}
+	if len(tools) != 2 || tools[0].Name != "get_orders" || tools[0].Enabled || !tools[1].Enabled {
+		t.Errorf("Expected get_orders listed as disabled, got %+v", tools)
+	}
+
+	if resp := adminRequest(t, http.MethodPost, httpServer.URL+"/tools/get_orders/enable", "admin-token"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 enabling get_orders, got %d", resp.StatusCode)
+	}
+	if names := listToolNames(t, server); len(names) != 2 {
+		t.Errorf("Expected both tools after enabling, got %v", names)
+	}
+
+	if resp := adminRequest(t, http.MethodPost, httpServer.URL+"/tools/missing/disable", "admin-token"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown tool, got %d", resp.StatusCode)
+	}
+}
+
+func TestAdminHandler_DebugTools(t *testing.T) {
+	httpServer, server, _ := newAdminTestServer(t)
+
+	resp := adminRequest(t, http.MethodPost, httpServer.URL+"/tools/get_*/debug", "admin-token")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 enabling debug, got %d", resp.StatusCode)
+	}
+	var tools []adminTool
+	if err := json.NewDecoder(resp.Body).Decode(&tools); err != nil {
+		t.Fatalf("Failed to decode tools: %v", err)
+	}
+	if len(tools) != 2 || !server.IsToolDebug("get_orders") || !server.IsToolDebug("get_users") {
+		t.Errorf("Expected debug enabled for both tools, got %+v", tools)
+	}
+
+	if resp := adminRequest(t, http.MethodDelete, httpServer.URL+"/tools/get_orders/debug", "admin-token"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 disabling debug, got %d", resp.StatusCode)
+	}
+	resp = adminRequest(t, http.MethodGet, httpServer.URL+"/tools", "admin-token")
+	tools = nil
+	if err := json.NewDecoder(resp.Body).Decode(&tools); err != nil {
+		t.Fatalf("Failed to decode tools: %v", err)
+	}
+	if len(tools) != 2 || tools[0].Debug || !tools[1].Debug {
+		t.Errorf("Expected only get_users listed with debug, got %+v", tools)
+	}
+
+	if resp := adminRequest(t, http.MethodPost, httpServer.URL+"/tools/missing/debug", "admin-token"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown tool, got %d", resp.StatusCode)
+	}
+}
+
+func TestAdminHandler_ConfigIsRedacted(t *testing.T) {
+	httpServer, _, _ := newAdminTestServer(t)
+
+	resp := adminRequest(t, http.MethodGet, httpServer.URL+"/config", "admin-token")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if strings.Contains(string(body), "upstream-secret") {
+		t.Errorf("Expected upstream token to be redacted, got:\n%s", body)
+	}
+	if !strings.Contains(string(body), config.Redacted) {
+		t.Errorf("Expected redacted placeholder in config, got:\n%s", body)
+	}
+}
+
+func TestAdminHandler_ReloadAndSessions(t *testing.T) {
+	httpServer, server, configPath := newAdminTestServer(t)
+
+	specPath := filepath.Join(filepath.Dir(configPath), "spec.json")
+	writeReloadConfig(t, configPath, specPath, "/orders")
+	if resp := adminRequest(t, http.MethodPost, httpServer.URL+"/reload", "admin-token"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 from reload, got %d", resp.StatusCode)
+	}
+	if names := listToolNames(t, server); len(names) != 1 || names[0] != "get_users" {
+		t.Errorf("Expected only get_users after reload, got %v", names)
+	}
+
+	if err := os.WriteFile(configPath, []byte("openapi: ["), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if resp := adminRequest(t, http.MethodPost, httpServer.URL+"/reload", "admin-token"); resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected 500 from a failed reload, got %d", resp.StatusCode)
+	}
+
+	// Without an HTTP transport there are no sessions
+	resp := adminRequest(t, http.MethodGet, httpServer.URL+"/sessions", "admin-token")
+	var sessions []interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&sessions); err != nil {
+		t.Fatalf("Failed to decode sessions: %v", err)
+	}
+	if sessions == nil || len(sessions) != 0 {
+		t.Errorf("Expected an empty session list, got %v", sessions)
+	}
+}
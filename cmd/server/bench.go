@@ -0,0 +1,212 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+	"mcpify/pkg/mcp"
+)
+
+// benchSender sends one JSON-RPC request to the server under test
+type benchSender func(req types.MCPRequest) (*types.MCPResponse, error)
+
+// benchStats collects the latency of every request of one method
+type benchStats struct {
+	method    string
+	latencies []time.Duration
+	errors    int
+}
+
+// runBench replays a synthetic workload against the server, in process or at --url, and reports
+// throughput and latency per method. Each iteration lists the tools, then calls the tool named
+// by the first argument when one is given.
+func runBench(args []string, stdout io.Writer) error {
+	var opts options
+	fs := newFlagSet("bench", "[tool] [arguments-json] [options]", &opts)
+	verbose := addVerboseFlag(fs)
+	url := fs.String("url", "", "MCP endpoint of a running instance, e.g. http://127.0.0.1:9090/mcp (default: serve the configuration in process)")
+	token := fs.String("token", "", "Bearer token sent to --url")
+	iterations := fs.Int("requests", 1000, "Number of workload iterations")
+	fs.IntVar(iterations, "n", 1000, "Number of workload iterations")
+	concurrency := fs.Int("concurrency", 10, "Number of concurrent clients")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 2 {
+		fs.Usage()
+		return fmt.Errorf("expected an optional tool name and JSON arguments")
+	}
+	if *iterations < 1 || *concurrency < 1 {
+		return fmt.Errorf("--requests and --concurrency must be at least 1")
+	}
+	defer quietLogs(*verbose)()
+
+	workload := []types.MCPRequest{{JSONRPC: "2.0", Method: "tools/list"}}
+	if len(positional) > 0 {
+		arguments := map[string]interface{}{}
+		if len(positional) == 2 {
+			if err := types.DecodeJSON([]byte(positional[1]), &arguments); err != nil {
+				return fmt.Errorf("invalid JSON arguments: %w", err)
+			}
+		}
+		params, err := json.Marshal(types.CallToolParams{Name: positional[0], Arguments: arguments})
+		if err != nil {
+			return err
+		}
+		workload = append(workload, types.MCPRequest{JSONRPC: "2.0", Method: "tools/call", Params: params})
+	}
+
+	var send benchSender
+	if *url != "" {
+		send = httpBenchSender(*url, *token, *concurrency)
+	} else {
+		cfg, err := loadConfig(opts)
+		if err != nil {
+			return err
+		}
+		server := mcp.NewServer()
+		_, extensions, err := buildServedTools(server, cfg)
+		if err != nil {
+			return err
+		}
+		defer closeExtensions(extensions)
+		send = func(req types.MCPRequest) (*types.MCPResponse, error) {
+			response := server.HandleRequest(req, config.RequestContext{})
+			return &response, nil
+		}
+	}
+
+	stats, elapsed := runBenchWorkload(send, workload, *iterations, *concurrency)
+	return printBenchReport(stdout, stats, elapsed)
+}
+
+// runBenchWorkload sends the workload iterations times from concurrency workers, returning the
+// statistics of each method in workload order and the total duration
+func runBenchWorkload(send benchSender, workload []types.MCPRequest, iterations, concurrency int) ([]*benchStats, time.Duration) {
+	stats := make([]*benchStats, len(workload))
+	for i, req := range workload {
+		stats[i] = &benchStats{method: req.Method, latencies: make([]time.Duration, 0, iterations)}
+	}
+
+	var mu sync.Mutex
+	next := make(chan int)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < min(concurrency, iterations); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for iteration := range next {
+				for i, req := range workload {
+					req.ID = iteration*len(workload) + i + 1
+					sent := time.Now()
+					response, err := send(req)
+					latency := time.Since(sent)
+
+					mu.Lock()
+					stats[i].latencies = append(stats[i].latencies, latency)
+					if err != nil || response.Error != nil {
+						stats[i].errors++
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for iteration := 0; iteration < iterations; iteration++ {
+		next <- iteration
+	}
+	close(next)
+	wg.Wait()
+
+	return stats, time.Since(start)
+}
+
+// httpBenchSender posts JSON-RPC requests to the MCP endpoint of a running instance
+func httpBenchSender(url, token string, concurrency int) benchSender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = concurrency
+	client := &http.Client{Transport: transport, Timeout: 30 * time.Second}
+
+	return func(req types.MCPRequest) (*types.MCPResponse, error) {
+		body, err := json.Marshal(req)
+		if err != nil {
+			return nil, err
+		}
+		httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Accept", "application/json")
+		httpReq.Header.Set("MCP-Protocol-Version", "2024-11-05")
+		if token != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := client.Do(httpReq)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		if resp.StatusCode != http.StatusOK {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+
+		var response types.MCPResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, fmt.Errorf("invalid JSON-RPC response: %w", err)
+		}
+		return &response, nil
+	}
+}
+
+// printBenchReport prints one line per method with its throughput and latency percentiles
+func printBenchReport(w io.Writer, stats []*benchStats, elapsed time.Duration) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tREQUESTS\tERRORS\tREQ/S\tP50\tP90\tP99\tMAX")
+	total := 0
+	for _, s := range stats {
+		sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+		total += len(s.latencies)
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\n", s.method, len(s.latencies), s.errors,
+			float64(len(s.latencies))/elapsed.Seconds(),
+			percentile(s.latencies, 0.50), percentile(s.latencies, 0.90), percentile(s.latencies, 0.99),
+			percentile(s.latencies, 1))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n%d requests in %s (%.1f req/s)\n", total, elapsed.Round(time.Millisecond), float64(total)/elapsed.Seconds())
+	return nil
+}
+
+// percentile returns the latency below which fraction p of the sorted latencies fall
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index].Round(time.Microsecond)
+}
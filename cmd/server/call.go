@@ -0,0 +1,156 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+	"mcpify/pkg/mcp"
+)
+
+// redacted replaces secret values in dry-run output
+const redacted = "[REDACTED]"
+
+// runCall generates the tools from the configuration and invokes one of them with JSON
+// arguments, printing the result, or with --dry-run the request that would be sent
+func runCall(args []string, stdout io.Writer) error {
+	var opts options
+	fs := newFlagSet("call", "<tool> [arguments-json] [options]", &opts)
+	verbose := addVerboseFlag(fs)
+	dryRun := fs.Bool("dry-run", false, "Print the upstream request instead of sending it")
+	showSecrets := fs.Bool("show-secrets", false, "Show credentials in --dry-run output")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 || len(positional) > 2 {
+		fs.Usage()
+		return fmt.Errorf("expected a tool name and optional JSON arguments")
+	}
+	defer quietLogs(*verbose)()
+
+	name := positional[0]
+	arguments := map[string]interface{}{}
+	if len(positional) == 2 {
+		if err := types.DecodeJSON([]byte(positional[1]), &arguments); err != nil {
+			return fmt.Errorf("invalid JSON arguments: %w", err)
+		}
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		return printDryRun(stdout, cfg, name, arguments, *showSecrets)
+	}
+
+	server := mcp.NewServer()
+	_, extensions, err := buildServedTools(server, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeExtensions(extensions)
+	result, err := server.CallTool(name, arguments, config.RequestContext{})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// printDryRun builds the upstream request for a tool call and prints it without sending it
+func printDryRun(w io.Writer, cfg *config.Config, name string, arguments map[string]interface{}, showSecrets bool) error {
+	generated, err := generateTools(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, api := range generated {
+		for _, tool := range api.tools {
+			if tool.Name != name {
+				continue
+			}
+			req, err := api.handler.BuildRequest(tool, arguments, config.RequestContext{})
+			if err != nil {
+				return err
+			}
+			if !showSecrets {
+				redactRequest(req, api.api)
+			}
+			return writeRequest(w, req)
+		}
+	}
+	return fmt.Errorf("%w: %s", mcp.ErrToolNotFound, name)
+}
+
+// redactRequest replaces credentials configured for api in the request headers and query
+func redactRequest(req *http.Request, api *config.OpenAPIConfig) {
+	sensitive := []string{"Authorization", "Proxy-Authorization", "Cookie"}
+	if api.Auth.Type == "api_key" && api.Auth.APIKeyIn == "header" {
+		sensitive = append(sensitive, api.Auth.APIKeyName)
+	}
+	for _, item := range api.Auth.Headers {
+		sensitive = append(sensitive, item.Header.Name)
+	}
+
+	for _, name := range sensitive {
+		value := req.Header.Get(name)
+		if value == "" {
+			continue
+		}
+		// Keep the scheme so the kind of credential can still be checked
+		if scheme, _, found := strings.Cut(value, " "); found && (scheme == "Bearer" || scheme == "Basic") {
+			req.Header.Set(name, scheme+" "+redacted)
+		} else {
+			req.Header.Set(name, redacted)
+		}
+	}
+
+	if api.Auth.Type == "api_key" && api.Auth.APIKeyIn == "query" {
+		query := req.URL.Query()
+		if query.Has(api.Auth.APIKeyName) {
+			query.Set(api.Auth.APIKeyName, redacted)
+			req.URL.RawQuery = query.Encode()
+		}
+	}
+}
+
+// writeRequest prints a request as its method and URL, headers and body
+func writeRequest(w io.Writer, req *http.Request) error {
+	fmt.Fprintf(w, "%s %s\n", req.Method, req.URL.String())
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			fmt.Fprintf(w, "%s: %s\n", name, value)
+		}
+	}
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		if len(body) > 0 {
+			fmt.Fprintf(w, "\n%s\n", body)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"mcpify/internal/config"
+)
+
+// clientTokenInput is the VS Code input that prompts for the client token when JWT validation is enabled
+const clientTokenInput = "mcpify-token"
+
+// runClientConfig prints the snippet an MCP client needs to start or connect to mcpify with
+// the current configuration: a claude_desktop_config.json entry or VS Code MCP settings
+func runClientConfig(args []string, stdout io.Writer) error {
+	var opts options
+	fs := newFlagSet("client-config", "[options]", &opts)
+	addServerFlags(fs, &opts)
+	verbose := addVerboseFlag(fs)
+	client := fs.String("client", "claude-desktop", "Client to configure (claude-desktop, vscode)")
+	name := fs.String("name", "mcpify", "Server name shown in the client")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	defer quietLogs(*verbose)()
+
+	if opts.configPath == config.StdinPath {
+		return fmt.Errorf("--config %s cannot be referenced by a client, pass a file instead", config.StdinPath)
+	}
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	var server map[string]interface{}
+	if cfg.Server.Transport == "http" {
+		server = httpClientServer(*client, cfg)
+	} else {
+		server, err = stdioClientServer(*client, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	var snippet map[string]interface{}
+	switch *client {
+	case "claude-desktop":
+		snippet = map[string]interface{}{"mcpServers": map[string]interface{}{*name: server}}
+	case "vscode":
+		snippet = map[string]interface{}{"servers": map[string]interface{}{*name: server}}
+		if _, ok := server["headers"]; ok {
+			snippet["inputs"] = []map[string]interface{}{{
+				"type":        "promptString",
+				"id":          clientTokenInput,
+				"description": "Token for " + *name,
+				"password":    true,
+			}}
+		}
+	default:
+		return fmt.Errorf("unknown client %q, expected claude-desktop or vscode", *client)
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(snippet)
+}
+
+// stdioClientServer returns the entry that has the client start mcpify with the same options
+func stdioClientServer(client string, opts options) (map[string]interface{}, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the mcpify executable: %w", err)
+	}
+
+	// Clients start servers from their own working directory
+	serveArgs := []string{"serve"}
+	if opts.configPath != "" {
+		configPath, err := filepath.Abs(opts.configPath)
+		if err != nil {
+			return nil, err
+		}
+		serveArgs = append(serveArgs, "--config", configPath)
+	}
+	if opts.specPath != "" {
+		specPath := opts.specPath
+		if _, err := os.Stat(specPath); err == nil {
+			if specPath, err = filepath.Abs(specPath); err != nil {
+				return nil, err
+			}
+		}
+		serveArgs = append(serveArgs, "--spec", specPath)
+	}
+	if opts.baseURL != "" {
+		serveArgs = append(serveArgs, "--base-url", opts.baseURL)
+	}
+	if opts.profile != "" {
+		serveArgs = append(serveArgs, "--profile", opts.profile)
+	}
+	if opts.transport != "" {
+		serveArgs = append(serveArgs, "--transport", opts.transport)
+	}
+
+	server := map[string]interface{}{"command": executable, "args": serveArgs}
+	if client == "vscode" {
+		server["type"] = "stdio"
+	}
+	return server, nil
+}
+
+// httpClientServer returns the entry that connects the client to a running HTTP server
+func httpClientServer(client string, cfg *config.Config) map[string]interface{} {
+	host := cfg.Server.HTTP.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	scheme := "http"
+	if cfg.Server.HTTP.TLS.Enabled {
+		scheme = "https"
+	}
+	endpoint := scheme + "://" + net.JoinHostPort(host, strconv.Itoa(cfg.Server.HTTP.Port)) + "/mcp"
+
+	if client == "vscode" {
+		server := map[string]interface{}{"type": "http", "url": endpoint}
+		if cfg.Security.JWT.Enabled {
+			server["headers"] = map[string]string{"Authorization": "Bearer ${input:" + clientTokenInput + "}"}
+		}
+		return server
+	}
+
+	// Claude Desktop starts local servers only, so it reaches HTTP servers through mcp-remote
+	server := map[string]interface{}{"command": "npx", "args": []string{"-y", "mcp-remote", endpoint}}
+	if cfg.Security.JWT.Enabled {
+		server["args"] = []string{"-y", "mcp-remote", endpoint, "--header", "Authorization:${MCPIFY_AUTH_HEADER}"}
+		server["env"] = map[string]string{"MCPIFY_AUTH_HEADER": "Bearer <token>"}
+	}
+	return server
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// command is a mcpify subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout io.Writer) error
+}
+
+// commands returns the available subcommands in the order they are listed in the usage
+func commands() []command {
+	return []command{
+		{name: "serve", summary: "Start the MCP server (default)", run: runServe},
+		{name: "validate", summary: "Check the configuration and OpenAPI specifications", run: runValidate},
+		{name: "tools", summary: "Inspect the generated tools", run: runTools},
+		{name: "call", summary: "Invoke a tool once and print the result", run: runCall},
+		{name: "bench", summary: "Measure tools/list and tools/call throughput and latency", run: runBench},
+		{name: "doctor", summary: "Diagnose connectivity, credentials and port problems", run: runDoctor},
+		{name: "init", summary: "Generate a starter configuration for a spec", run: runInit},
+		{name: "client-config", summary: "Print MCP client settings for this server", run: runClientConfig},
+		{name: "registry", summary: "Print or publish the MCP registry server.json", run: runRegistry},
+		{name: "version", summary: "Print version information", run: runVersion},
+		{name: "help", summary: "Show this help message", run: runHelp},
+	}
+}
+
+// resolveCommand selects the subcommand named by the first argument. Arguments starting
+// with flags run serve, so existing invocations like "mcpify --config config.yaml" keep working.
+func resolveCommand(args []string) (command, []string, error) {
+	name := "serve"
+	switch {
+	case len(args) > 0 && (args[0] == "--version" || args[0] == "-version"):
+		name, args = "version", args[1:]
+	case len(args) > 0 && !strings.HasPrefix(args[0], "-"):
+		name, args = args[0], args[1:]
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, args, nil
+		}
+	}
+	return command{}, nil, fmt.Errorf("unknown command %q", name)
+}
+
+// printUsage writes the list of subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: mcpify [command] [options]\n\n")
+	fmt.Fprintf(w, "Commands:\n")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun 'mcpify <command> --help' for the options of a command.\n")
+}
+
+func runHelp(args []string, stdout io.Writer) error {
+	printUsage(stdout)
+	return nil
+}
+
+// newFlagSet creates the flag set for a command with the configuration flags shared by all commands
+func newFlagSet(name, arguments string, opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&opts.configPath, "config", "", "Path to configuration file, or - to read it from stdin")
+	fs.StringVar(&opts.configPath, "c", "", "Path to configuration file, or - to read it from stdin")
+	fs.StringVar(&opts.specPath, "spec", "", "Path to OpenAPI specification (local file or URL)")
+	fs.StringVar(&opts.specPath, "s", "", "Path to OpenAPI specification (local file or URL)")
+	fs.StringVar(&opts.baseURL, "base-url", "", "Base URL for API requests (defaults to domain from spec URL)")
+	fs.StringVar(&opts.baseURL, "b", "", "Base URL for API requests (defaults to domain from spec URL)")
+	fs.BoolVar(&opts.debug, "debug", false, "Enable debug logging for API requests and responses")
+	fs.BoolVar(&opts.debug, "d", false, "Enable debug logging for API requests and responses")
+	fs.BoolVar(&opts.strict, "strict", false, "Reject configuration files containing unknown keys")
+	fs.StringVar(&opts.profile, "profile", os.Getenv("MCPIFY_PROFILE"), "Configuration profile to apply (defaults to $MCPIFY_PROFILE)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: mcpify %s %s\n", name, arguments)
+		printFlags(fs.Output(), fs)
+	}
+	return fs
+}
+
+// addVerboseFlag registers the flag that shows log output for commands that are quiet by default
+func addVerboseFlag(fs *flag.FlagSet) *bool {
+	verbose := fs.Bool("verbose", false, "Show log output")
+	fs.BoolVar(verbose, "v", false, "Show log output")
+	return verbose
+}
+
+// quietLogs discards log output unless verbose is set, returning a function that restores it
+func quietLogs(verbose bool) func() {
+	if verbose {
+		return func() {}
+	}
+	previous := log.Writer()
+	log.SetOutput(io.Discard)
+	return func() { log.SetOutput(previous) }
+}
+
+// parseInterspersed parses flags that may appear before, between or after positional
+// arguments, returning the positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// printFlags lists the flags of a flag set, showing short aliases alongside their long form
+func printFlags(w io.Writer, fs *flag.FlagSet) {
+	type entry struct {
+		names []string
+		kind  string
+		usage string
+	}
+
+	// Aliases are registered with identical usage text
+	byUsage := make(map[string]*entry)
+	entries := []*entry{{names: []string{"help"}, usage: "Show this help message"}}
+	fs.VisitAll(func(f *flag.Flag) {
+		kind, usage := flag.UnquoteUsage(f)
+		key := kind + "\x00" + usage
+		if byUsage[key] == nil {
+			byUsage[key] = &entry{kind: kind, usage: usage}
+			entries = append(entries, byUsage[key])
+		}
+		byUsage[key].names = append(byUsage[key].names, f.Name)
+	})
+
+	for _, e := range entries {
+		sort.Slice(e.names, func(i, j int) bool { return len(e.names[i]) < len(e.names[j]) })
+	}
+	// Order by long flag name
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].names[len(entries[i].names)-1] < entries[j].names[len(entries[j].names)-1]
+	})
+
+	for _, e := range entries {
+		names := make([]string, len(e.names))
+		for i, name := range e.names {
+			if len(name) == 1 {
+				names[i] = "-" + name
+			} else {
+				names[i] = "--" + name
+			}
+		}
+		line := "  " + strings.Join(names, ", ")
+		if e.kind != "" {
+			line += " " + e.kind
+		}
+		fmt.Fprintf(w, "%s\n        %s\n", line, e.usage)
+	}
+}
@@ -0,0 +1,902 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+	"mcpify/pkg/mcp"
+)
+
+// writeCommandConfig writes the reload test spec and a config pointing at baseURL, returning the config path
+func writeCommandConfig(t *testing.T, baseURL string) string {
+	t.Helper()
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	content := "openapi:\n  spec_path: \"" + specPath + "\"\n  base_url: \"" + baseURL + "\"\n"
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return configPath
+}
+
+func TestResolveCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		expectedCmd  string
+		expectedArgs []string
+		expectError  bool
+	}{
+		{name: "no arguments", args: nil, expectedCmd: "serve"},
+		{name: "flags only", args: []string{"--config", "config.yaml"}, expectedCmd: "serve", expectedArgs: []string{"--config", "config.yaml"}},
+		{name: "explicit serve", args: []string{"serve", "-p", "8080"}, expectedCmd: "serve", expectedArgs: []string{"-p", "8080"}},
+		{name: "subcommand", args: []string{"tools", "list"}, expectedCmd: "tools", expectedArgs: []string{"list"}},
+		{name: "version flag", args: []string{"--version"}, expectedCmd: "version"},
+		{name: "unknown command", args: []string{"deploy"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, args, err := resolveCommand(tt.args)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %v", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if cmd.name != tt.expectedCmd {
+				t.Errorf("Expected command %s, got %s", tt.expectedCmd, cmd.name)
+			}
+			if strings.Join(args, " ") != strings.Join(tt.expectedArgs, " ") {
+				t.Errorf("Expected arguments %v, got %v", tt.expectedArgs, args)
+			}
+		})
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	var opts options
+	fs := newFlagSet("call", "<tool>", &opts)
+	positional, err := parseInterspersed(fs, []string{"-c", "config.yaml", "get_users", `{"id":1}`, "--debug"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(positional) != 2 || positional[0] != "get_users" || positional[1] != `{"id":1}` {
+		t.Errorf("Unexpected positional arguments %v", positional)
+	}
+	if opts.configPath != "config.yaml" || !opts.debug {
+		t.Errorf("Expected flags around positional arguments to be parsed, got %+v", opts)
+	}
+}
+
+func TestPrintFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("config", "", "Path to configuration file")
+	fs.String("c", "", "Path to configuration file")
+	fs.Bool("strict", false, "Reject unknown keys")
+
+	var out bytes.Buffer
+	printFlags(&out, fs)
+	expected := "  -c, --config string\n        Path to configuration file\n" +
+		"  --help\n        Show this help message\n" +
+		"  --strict\n        Reject unknown keys\n"
+	if out.String() != expected {
+		t.Errorf("Expected usage:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestRunValidate(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	// Loads, but an operation without responses violates the OpenAPI schema
+	invalidSpecPath := filepath.Join(dir, "invalid.json")
+	invalidSpec := `{"openapi": "3.0.0", "info": {"title": "Invalid", "version": "1"}, "paths": {"/users": {"get": {}}}}`
+	if err := os.WriteFile(invalidSpecPath, []byte(invalidSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		config      string
+		args        []string
+		expectError string
+		expected    []string
+	}{
+		{
+			name:     "valid configuration",
+			config:   "openapi:\n  spec_path: \"" + specPath + "\"\n  base_url: \"http://127.0.0.1:1\"\n",
+			expected: []string{"ok       spec " + specPath, "ok       2 tools from 1 APIs", "Configuration is valid"},
+		},
+		{
+			name:        "unknown key",
+			config:      "openapi:\n  spec_path: \"" + specPath + "\"\n  includ_paths: [\"/users\"]\n",
+			expectError: "validation failed with 1 error",
+			expected:    []string{`error    failed to load configuration: unknown configuration keys: openapi.includ_paths (did you mean "include_paths"?)`},
+		},
+		{
+			name: "static header replaces auth",
+			config: "openapi:\n  spec_path: \"" + specPath + "\"\n  base_url: \"http://127.0.0.1:1\"\n" +
+				"  auth:\n    type: bearer\n    token: \"abc\"\n  headers:\n    Authorization: \"Bearer other\"\n",
+			expectError: "validation failed with 1 error",
+			expected:    []string{"error    " + specPath + ": headers sets Authorization, replacing the bearer credentials from auth"},
+		},
+		{
+			name:        "missing spec",
+			config:      "openapi:\n  spec_path: \"" + filepath.Join(dir, "missing.json") + "\"\n",
+			expectError: "validation failed with 1 error",
+			expected:    []string{"error    spec " + filepath.Join(dir, "missing.json")},
+		},
+		{
+			name:     "schema violations are warnings",
+			config:   "openapi:\n  spec_path: \"" + invalidSpecPath + "\"\n  base_url: \"http://127.0.0.1:1\"\n",
+			expected: []string{"warning  spec " + invalidSpecPath + ": OpenAPI spec validation failed", "Configuration is valid"},
+		},
+		{
+			name:        "fail on warnings",
+			config:      "openapi:\n  spec_path: \"" + invalidSpecPath + "\"\n  base_url: \"http://127.0.0.1:1\"\n",
+			args:        []string{"--fail-on-warnings"},
+			expectError: "validation failed with 1 warning",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(dir, fmt.Sprintf("config%d.yaml", i))
+			if err := os.WriteFile(configPath, []byte(tt.config), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			var out bytes.Buffer
+			err := runValidate(append([]string{"--config", configPath}, tt.args...), &out)
+			if tt.expectError == "" && err != nil {
+				t.Fatalf("Expected no error, got %v\n%s", err, out.String())
+			}
+			if tt.expectError != "" && (err == nil || err.Error() != tt.expectError) {
+				t.Errorf("Expected error %q, got %v", tt.expectError, err)
+			}
+			for _, line := range tt.expected {
+				if !strings.Contains(out.String(), line) {
+					t.Errorf("Expected output to contain %q, got:\n%s", line, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunToolsList(t *testing.T) {
+	configPath := writeCommandConfig(t, "http://127.0.0.1:1")
+
+	var out bytes.Buffer
+	if err := runTools([]string{"list", "-c", configPath}, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "NAME") ||
+		!strings.HasPrefix(lines[1], "get_orders") || !strings.HasPrefix(lines[2], "get_users") || lines[4] != "2 tools" {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runTools([]string{"list", "-c", configPath, "--output", "json"}, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var result types.ListToolsResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("Expected tools/list JSON, got %v: %s", err, out.String())
+	}
+	if len(result.Tools) != 2 || result.Tools[0].Name != "get_orders" || result.Tools[0].InputSchema["type"] != "object" {
+		t.Errorf("Unexpected tools: %+v", result.Tools)
+	}
+
+	if err := runTools([]string{"list", "-c", configPath, "-o", "yaml"}, &out); err == nil {
+		t.Error("Expected error for unsupported output format")
+	}
+	if err := runTools(nil, &out); err == nil {
+		t.Error("Expected error without a tools subcommand")
+	}
+}
+
+func TestRunToolsExport(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Export", "version": "1.0.0"},
+  "paths": {
+    "/users/{id}": {"get": {"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+      "responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}}}}}},
+    "/users": {"delete": {"responses": {"204": {"description": "deleted"}}}}
+  }
+}`
+	if err := os.WriteFile(specPath, []byte(spec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	content := "openapi:\n  spec_path: \"" + specPath + "\"\n  base_url: \"http://127.0.0.1:1\"\n"
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runTools([]string{"export", "-c", configPath}, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var manifest toolManifest
+	if err := json.Unmarshal(out.Bytes(), &manifest); err != nil {
+		t.Fatalf("Expected a JSON manifest, got %v: %s", err, out.String())
+	}
+	if len(manifest.Tools) != 2 {
+		t.Fatalf("Expected 2 tools, got %+v", manifest.Tools)
+	}
+
+	deleteUsers, getUser := manifest.Tools[0], manifest.Tools[1]
+	if deleteUsers.Name != "delete_users" || deleteUsers.Method != "DELETE" || deleteUsers.OutputSchema != nil {
+		t.Errorf("Unexpected tool: %+v", deleteUsers)
+	}
+	if getUser.Name != "get_users_by_id" || getUser.Path != "/users/{id}" || getUser.InputSchema["type"] != "object" {
+		t.Errorf("Unexpected tool: %+v", getUser)
+	}
+	if properties, _ := getUser.OutputSchema["properties"].(map[string]interface{}); properties["name"] == nil {
+		t.Errorf("Expected the response schema as output schema, got %v", getUser.OutputSchema)
+	}
+
+	// The manifest is stable, so it can be committed and diffed
+	var again bytes.Buffer
+	if err := runTools([]string{"export", "-c", configPath}, &again); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if again.String() != out.String() {
+		t.Errorf("Expected identical manifests, got:\n%s\n%s", out.String(), again.String())
+	}
+}
+
+func TestSummarizeParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   map[string]interface{}
+		expected string
+	}{
+		{name: "no parameters", schema: map[string]interface{}{"type": "object"}, expected: "-"},
+		{
+			name: "required marked",
+			schema: map[string]interface{}{
+				"properties": map[string]interface{}{"limit": map[string]interface{}{}, "id": map[string]interface{}{}, "body": map[string]interface{}{}},
+				"required":   []string{"id", "body"},
+			},
+			expected: "body*, id*, limit",
+		},
+		{
+			name: "decoded required list",
+			schema: map[string]interface{}{
+				"properties": map[string]interface{}{"id": map[string]interface{}{}},
+				"required":   []interface{}{"id"},
+			},
+			expected: "id*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeParameters(tt.schema); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSummarizeDescription(t *testing.T) {
+	if got := summarizeDescription("List users\n\nReturns every user."); got != "List users" {
+		t.Errorf("Expected first line, got %q", got)
+	}
+	long := strings.Repeat("a", 100)
+	if got := summarizeDescription(long); len(got) != maxTableDescription || !strings.HasSuffix(got, "...") {
+		t.Errorf("Expected shortened description, got %q", got)
+	}
+}
+
+func TestRunCall(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":1,"name":"Ada"}]`))
+	}))
+	defer upstream.Close()
+	configPath := writeCommandConfig(t, upstream.URL)
+
+	var out bytes.Buffer
+	if err := runCall([]string{"get_users", "{}", "--config", configPath}, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), `"name": "Ada"`) || !strings.Contains(out.String(), `"status_code": 200`) {
+		t.Errorf("Unexpected output: %s", out.String())
+	}
+
+	if err := runCall([]string{"get_missing", "--config", configPath}, &out); err == nil || !strings.Contains(err.Error(), "tool not found") {
+		t.Errorf("Expected tool not found error, got %v", err)
+	}
+	if err := runCall([]string{"get_users", "{not json", "--config", configPath}, &out); err == nil {
+		t.Error("Expected error for invalid JSON arguments")
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer upstream.Close()
+	configPath := writeCommandConfig(t, upstream.URL)
+
+	var out bytes.Buffer
+	if err := runBench([]string{"get_users", "{}", "-c", configPath, "-n", "20", "--concurrency", "4"}, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "METHOD") ||
+		strings.Join(strings.Fields(lines[1])[:3], " ") != "tools/list 20 0" ||
+		strings.Join(strings.Fields(lines[2])[:3], " ") != "tools/call 20 0" ||
+		!strings.HasPrefix(lines[4], "40 requests in") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+
+	if err := runBench([]string{"-c", configPath, "-n", "0"}, &out); err == nil {
+		t.Error("Expected error for zero requests")
+	}
+}
+
+func TestRunBench_URL(t *testing.T) {
+	var calls int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.MCPRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		response := types.MCPResponse{JSONRPC: "2.0", ID: req.ID, Result: map[string]interface{}{}}
+		if req.Method == "tools/call" {
+			response = types.MCPResponse{JSONRPC: "2.0", ID: req.ID, Error: &types.MCPError{Code: mcp.ErrorCodeMethodNotFound, Message: "Tool not found"}}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := runBench([]string{"get_missing", "--url", server.URL, "--token", "secret", "-n", "5"}, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || strings.Join(strings.Fields(lines[1])[:3], " ") != "tools/list 5 0" ||
+		strings.Join(strings.Fields(lines[2])[:3], " ") != "tools/call 5 5" {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+	if calls != 10 {
+		t.Errorf("Expected 10 requests, got %d", calls)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{1 * time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond, 4 * time.Millisecond}
+	if p := percentile(latencies, 0.5); p != 2*time.Millisecond {
+		t.Errorf("Expected p50 of 2ms, got %s", p)
+	}
+	if p := percentile(latencies, 1); p != 4*time.Millisecond {
+		t.Errorf("Expected max of 4ms, got %s", p)
+	}
+	if p := percentile(nil, 0.5); p != 0 {
+		t.Errorf("Expected 0 for no latencies, got %s", p)
+	}
+}
+
+const callTestSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Call", "version": "1.0.0"},
+  "paths": {
+    "/users/{id}": {"get": {"parameters": [
+      {"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}},
+      {"name": "limit", "in": "query", "schema": {"type": "integer"}}
+    ], "responses": {"200": {"description": "ok", "content": {"application/json": {}, "text/html": {}}}}}},
+    "/users": {"post": {"requestBody": {"required": true, "content": {"application/json": {"schema": {"type": "object"}}}}, "responses": {"201": {"description": "created"}}}}
+  }
+}`
+
+func TestRunCall_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(specPath, []byte(callTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	content := "openapi:\n  spec_path: \"" + specPath + "\"\n  base_url: \"http://127.0.0.1:1\"\n" +
+		"  auth:\n    type: bearer\n    token: \"secret-token\"\n"
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "path and query parameters with redacted token",
+			args:     []string{"get_users_by_id", `{"id":42,"limit":5}`, "--dry-run"},
+			expected: "GET http://127.0.0.1:1/users/42?limit=5\nAccept: application/json, text/html;q=0.9\nAuthorization: Bearer [REDACTED]\n",
+		},
+		{
+			name:     "request body with secrets shown",
+			args:     []string{"post_users", `{"body":{"name":"Ada"}}`, "--dry-run", "--show-secrets"},
+			expected: "POST http://127.0.0.1:1/users\nAccept: application/json, */*;q=0.8\nAuthorization: Bearer secret-token\nContent-Type: application/json\n\n{\"name\":\"Ada\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := runCall(append(tt.args, "-c", configPath), &out); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("Expected request:\n%s\ngot:\n%s", tt.expected, out.String())
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	if err := runCall([]string{"get_missing", "--dry-run", "-c", configPath}, &out); !errors.Is(err, mcp.ErrToolNotFound) {
+		t.Errorf("Expected tool not found error, got %v", err)
+	}
+}
+
+func TestRedactRequest(t *testing.T) {
+	api := &config.OpenAPIConfig{Auth: config.AuthConfig{Type: "api_key", APIKeyName: "key", APIKeyIn: "query"}}
+	req := httptest.NewRequest(http.MethodGet, "http://api.example.com/users?key=secret&limit=5", nil)
+	req.Header.Set("Cookie", "session=abc")
+	req.Header.Set("Authorization", "Token abc")
+
+	redactRequest(req, api)
+	if got := req.URL.Query().Get("key"); got != redacted {
+		t.Errorf("Expected query key to be redacted, got %q", got)
+	}
+	if got := req.URL.Query().Get("limit"); got != "5" {
+		t.Errorf("Expected other query parameters to be kept, got %q", got)
+	}
+	if req.Header.Get("Cookie") != redacted || req.Header.Get("Authorization") != redacted {
+		t.Errorf("Expected sensitive headers to be redacted, got %v", req.Header)
+	}
+}
+
+func TestRunVersion(t *testing.T) {
+	defer func(version, commit, buildTime string) {
+		Version, Commit, BuildTime = version, commit, buildTime
+	}(Version, Commit, BuildTime)
+	Version, Commit, BuildTime = "v1.2.3", "0123abc", "2025-01-01T00:00:00Z"
+
+	var out bytes.Buffer
+	if err := runVersion(nil, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, line := range []string{"mcpify v1.2.3\n", "  commit: 0123abc\n", "  built:  2025-01-01T00:00:00Z\n", "  go:     go"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestRunInit(t *testing.T) {
+	t.Setenv("API_TOKEN", "token")
+	t.Setenv("API_KEY", "key")
+	t.Setenv("API_USERNAME", "user")
+	t.Setenv("API_PASSWORD", "password")
+
+	tests := []struct {
+		name     string
+		servers  string
+		schemes  string
+		security string
+		expected []string
+	}{
+		{
+			name:     "bearer scheme",
+			servers:  `[{"url": "https://api.example.com/v1/"}]`,
+			schemes:  `{"bearerAuth": {"type": "http", "scheme": "bearer"}}`,
+			security: `[]`,
+			expected: []string{`base_url: "https://api.example.com/v1"`, "type: bearer", `token: "${API_TOKEN}"`},
+		},
+		{
+			name:     "required api key scheme first",
+			servers:  `[{"url": "https://{region}.example.com", "variables": {"region": {"default": "eu"}}}]`,
+			schemes:  `{"basicAuth": {"type": "http", "scheme": "basic"}, "keyAuth": {"type": "apiKey", "in": "query", "name": "key"}}`,
+			security: `[{"keyAuth": []}]`,
+			expected: []string{`base_url: "https://eu.example.com"`, "type: api_key", `api_key_name: "key"`, "api_key_in: query", `also declares "basicAuth"`},
+		},
+		{
+			name:     "unsupported scheme",
+			servers:  `[]`,
+			schemes:  `{"cookieAuth": {"type": "apiKey", "in": "cookie", "name": "session"}}`,
+			security: `[]`,
+			expected: []string{"# base_url:", `declares "cookieAuth" (api key session in cookie), which is not supported`, "type: none"},
+		},
+		{
+			name:     "no schemes",
+			servers:  `[{"url": "http://localhost:8080"}]`,
+			schemes:  `{}`,
+			security: `[]`,
+			expected: []string{`base_url: "http://localhost:8080"`, "type: none"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			specPath := filepath.Join(dir, "spec.json")
+			spec := `{"openapi": "3.0.0", "info": {"title": "Test API", "version": "1.0.0"}, "servers": ` + tt.servers +
+				`, "security": ` + tt.security + `, "components": {"securitySchemes": ` + tt.schemes + `},` +
+				` "paths": {"/users": {"get": {"responses": {"200": {"description": "ok"}}}}}}`
+			if err := os.WriteFile(specPath, []byte(spec), 0o600); err != nil {
+				t.Fatalf("Failed to write spec: %v", err)
+			}
+
+			configPath := filepath.Join(dir, "config.yaml")
+			var out bytes.Buffer
+			if err := runInit([]string{"-s", specPath, "-o", configPath}, &out); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			content, err := os.ReadFile(configPath)
+			if err != nil {
+				t.Fatalf("Failed to read generated config: %v", err)
+			}
+			for _, text := range tt.expected {
+				if !strings.Contains(string(content), text) {
+					t.Errorf("Expected generated config to contain %q, got:\n%s", text, content)
+				}
+			}
+
+			// The generated configuration must load without unknown keys
+			if _, err := loadConfig(options{configPath: configPath, strict: true}); err != nil {
+				t.Errorf("Expected generated config to load, got %v\n%s", err, content)
+			}
+
+			if err := runInit([]string{"-s", specPath, "-o", configPath}, &out); err == nil || !strings.Contains(err.Error(), "already exists") {
+				t.Errorf("Expected existing file to be kept, got %v", err)
+			}
+			if err := runInit([]string{"-s", specPath, "-o", configPath, "--force"}, &out); err != nil {
+				t.Errorf("Expected --force to overwrite, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDetectBaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		specPath string
+		servers  []string
+		expected string
+	}{
+		{"absolute server", "spec.json", []string{"https://api.example.com/v1/", "https://other.example.com"}, "https://api.example.com/v1"},
+		{"relative server with spec URL", "https://example.com/docs/openapi.json", []string{"/api"}, "https://example.com/api"},
+		{"relative server with local spec", "spec.json", []string{"/api"}, ""},
+		{"no servers with spec URL", "https://example.com/docs/openapi.json", nil, "https://example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectBaseURL(tt.specPath, tt.servers); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	var out bytes.Buffer
+	input := bufio.NewReader(strings.NewReader("\nhttp\n"))
+	if got := prompt(input, &out, "Base URL", "https://api.example.com"); got != "https://api.example.com" {
+		t.Errorf("Expected default for empty answer, got %q", got)
+	}
+	if got := prompt(input, &out, "Transport", "stdio"); got != "http" {
+		t.Errorf("Expected answer, got %q", got)
+	}
+	if out.String() != "Base URL [https://api.example.com]: Transport [stdio]: " {
+		t.Errorf("Unexpected prompts %q", out.String())
+	}
+}
+
+func TestRunDoctor(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users" && r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer busy.Close()
+	busyPort := busy.Addr().(*net.TCPAddr).Port
+
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	stdio := "server:\n  transport: stdio\n"
+	apiConfig := func(baseURL, token string) string {
+		return "openapi:\n  spec_path: \"" + specPath + "\"\n  base_url: \"" + baseURL + "\"\n" +
+			"  auth:\n    type: bearer\n    token: \"" + token + "\"\n"
+	}
+
+	tests := []struct {
+		name        string
+		config      string
+		expectError string
+		expected    []string
+	}{
+		{
+			name:     "healthy",
+			config:   stdio + apiConfig(upstream.URL, "good"),
+			expected: []string{"ok       base URL " + upstream.URL + " (HTTP 200)", "credentials accepted by GET /users (HTTP 200)", "All checks passed"},
+		},
+		{
+			name:        "rejected credentials",
+			config:      stdio + apiConfig(upstream.URL, "bad"),
+			expectError: "doctor found 1 problem",
+			expected:    []string{"error    auth for " + upstream.URL + ": bearer credentials rejected by GET /users (HTTP 401)"},
+		},
+		{
+			name:        "unreachable base URL",
+			config:      stdio + apiConfig("http://127.0.0.1:1", "good"),
+			expectError: "doctor found 1 problem",
+			expected:    []string{"error    base URL http://127.0.0.1:1:"},
+		},
+		{
+			name:        "port in use",
+			config:      fmt.Sprintf("server:\n  transport: http\n  http:\n    port: %d\n", busyPort) + apiConfig(upstream.URL, "good"),
+			expectError: "doctor found 1 problem",
+			expected:    []string{fmt.Sprintf("error    port 127.0.0.1:%d:", busyPort)},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(dir, fmt.Sprintf("config%d.yaml", i))
+			if err := os.WriteFile(configPath, []byte(tt.config), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			var out bytes.Buffer
+			err := runDoctor([]string{"--config", configPath}, &out)
+			if tt.expectError == "" && err != nil {
+				t.Fatalf("Expected no error, got %v\n%s", err, out.String())
+			}
+			if tt.expectError != "" && (err == nil || err.Error() != tt.expectError) {
+				t.Errorf("Expected error %q, got %v\n%s", tt.expectError, err, out.String())
+			}
+			for _, line := range tt.expected {
+				if !strings.Contains(out.String(), line) {
+					t.Errorf("Expected output to contain %q, got:\n%s", line, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunClientConfig(t *testing.T) {
+	configPath := writeCommandConfig(t, "http://127.0.0.1:1")
+	httpConfigPath := filepath.Join(filepath.Dir(configPath), "http.yaml")
+	content := "server:\n  transport: http\n  http:\n    host: 0.0.0.0\n    port: 8080\n" +
+		"security:\n  jwt:\n    enabled: true\n    jwks_url: https://auth.example.com/jwks.json\n" +
+		"openapi:\n  spec_path: \"" + filepath.Join(filepath.Dir(configPath), "spec.json") + "\"\n  base_url: \"http://127.0.0.1:1\"\n"
+	if err := os.WriteFile(httpConfigPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Failed to locate executable: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "claude desktop over stdio",
+			args: []string{"--config", configPath, "--transport", "stdio", "--name", "users"},
+			expected: `{"mcpServers":{"users":{"args":["serve","--config","` + configPath + `","--transport","stdio"],` +
+				`"command":"` + executable + `"}}}`,
+		},
+		{
+			name:     "claude desktop over http with JWT",
+			args:     []string{"--config", httpConfigPath},
+			expected: `{"mcpServers":{"mcpify":{"args":["-y","mcp-remote","http://127.0.0.1:8080/mcp","--header","Authorization:${MCPIFY_AUTH_HEADER}"],"command":"npx","env":{"MCPIFY_AUTH_HEADER":"Bearer <token>"}}}}`,
+		},
+		{
+			name: "vscode over http with JWT",
+			args: []string{"--config", httpConfigPath, "--client", "vscode"},
+			expected: `{"inputs":[{"description":"Token for mcpify","id":"mcpify-token","password":true,"type":"promptString"}],` +
+				`"servers":{"mcpify":{"headers":{"Authorization":"Bearer ${input:mcpify-token}"},"type":"http","url":"http://127.0.0.1:8080/mcp"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := runClientConfig(tt.args, &out); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, out.Bytes()); err != nil {
+				t.Fatalf("Expected JSON output, got %v:\n%s", err, out.String())
+			}
+			if compact.String() != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, compact.String())
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	if err := runClientConfig([]string{"--config", configPath, "--client", "cursor"}, &out); err == nil {
+		t.Error("Expected error for unknown client")
+	}
+}
+
+func TestRunRegistry(t *testing.T) {
+	configPath := writeCommandConfig(t, "${API_URL:-http://127.0.0.1:1}")
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	content = append(content, "  auth:\n    type: bearer\n    token: \"${API_TOKEN}\"\n"...)
+	if err := os.WriteFile(configPath, content, 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("API_TOKEN", "secret")
+
+	httpConfigPath := filepath.Join(filepath.Dir(configPath), "http.yaml")
+	httpContent := "server:\n  transport: http\n" +
+		"security:\n  jwt:\n    enabled: true\n    jwks_url: https://auth.example.com/jwks.json\n" +
+		"openapi:\n  spec_path: \"" + filepath.Join(filepath.Dir(configPath), "spec.json") + "\"\n  base_url: \"http://127.0.0.1:1\"\n"
+	if err := os.WriteFile(httpConfigPath, []byte(httpContent), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		errorMsg string
+	}{
+		{
+			name: "stdio package with environment variables",
+			args: []string{"--config", configPath, "--transport", "stdio", "--name", "io.github.acme/users", "--image", "ghcr.io/acme/users"},
+			expected: `{"$schema":"` + registrySchema + `","name":"io.github.acme/users","description":"MCP tools for Reload","version":"1.0.0",` +
+				`"packages":[{"registryType":"oci","identifier":"ghcr.io/acme/users","version":"1.0.0","transport":{"type":"stdio"},"environmentVariables":[` +
+				`{"name":"API_URL","description":"Referenced as ${API_URL} by the configuration","default":"http://127.0.0.1:1"},` +
+				`{"name":"API_TOKEN","description":"Referenced as ${API_TOKEN} by the configuration","isRequired":true,"isSecret":true}]}]}`,
+		},
+		{
+			name: "http remote with JWT",
+			args: []string{"--config", httpConfigPath, "--name", "com.example/users", "--url", "https://mcp.example.com/mcp",
+				"--description", "Users API", "--server-version", "2.1.0"},
+			expected: `{"$schema":"` + registrySchema + `","name":"com.example/users","description":"Users API","version":"2.1.0",` +
+				`"remotes":[{"type":"streamable-http","url":"https://mcp.example.com/mcp","headers":[` +
+				`{"name":"Authorization","description":"Bearer token issued by the configured identity provider","isRequired":true,"isSecret":true}]}]}`,
+		},
+		{name: "invalid name", args: []string{"--config", configPath, "--name", "users", "--image", "ghcr.io/acme/users"}, errorMsg: "namespace/name"},
+		{name: "stdio without image", args: []string{"--config", configPath, "--transport", "stdio", "--name", "io.github.acme/users"}, errorMsg: "--image"},
+		{name: "http without url", args: []string{"--config", httpConfigPath, "--name", "com.example/users"}, errorMsg: "--url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := runRegistry(tt.args, &out)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("Expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, out.Bytes()); err != nil {
+				t.Fatalf("Expected JSON output, got %v:\n%s", err, out.String())
+			}
+			if compact.String() != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, compact.String())
+			}
+		})
+	}
+}
+
+func TestRunRegistry_Publish(t *testing.T) {
+	var published registryServer
+	var authorization string
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v0/publish" {
+			http.NotFound(w, r)
+			return
+		}
+		authorization = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&published); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if published.Version == "1.0.0" {
+			http.Error(w, "version already published", http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer registry.Close()
+
+	configPath := writeCommandConfig(t, "http://127.0.0.1:1")
+	var out bytes.Buffer
+	publish := func(version string, extra ...string) error {
+		args := []string{"--config", configPath, "--transport", "stdio", "--name", "io.github.acme/users",
+			"--image", "ghcr.io/acme/users:" + version, "--server-version", version, "--publish", "--registry", registry.URL}
+		return runRegistry(append(args, extra...), &out)
+	}
+
+	if err := publish("2.0.0", "--token", "registry-token"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if authorization != "Bearer registry-token" {
+		t.Errorf("Expected the registry token, got %q", authorization)
+	}
+	if published.Name != "io.github.acme/users" || len(published.Packages) != 1 || published.Packages[0].Version != "2.0.0" {
+		t.Errorf("Unexpected published server: %+v", published)
+	}
+	if !strings.Contains(out.String(), "Published io.github.acme/users 2.0.0") {
+		t.Errorf("Unexpected output: %s", out.String())
+	}
+
+	if err := publish("1.0.0", "--token", "registry-token"); err == nil || !strings.Contains(err.Error(), "version already published") {
+		t.Errorf("Expected the registry error, got %v", err)
+	}
+
+	t.Setenv("MCP_REGISTRY_TOKEN", "")
+	if err := publish("2.0.0"); err == nil || !strings.Contains(err.Error(), "token") {
+		t.Errorf("Expected error without a token, got %v", err)
+	}
+}
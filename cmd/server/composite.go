@@ -0,0 +1,68 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"mcpify/internal/config"
+	"mcpify/internal/handlers"
+	"mcpify/pkg/mcp"
+)
+
+// registerCompositeTools registers the composite tools of the configuration, whose steps call
+// the generated tools in registered directly
+func registerCompositeTools(server *mcp.Server, composites map[string]config.CompositeTool, registered map[string]mcp.ToolHandler) (int, error) {
+	names := make([]string, 0, len(composites))
+	for name := range composites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	call := func(name string, params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+		return registered[name](params, requestContext)
+	}
+	for _, name := range names {
+		composite := composites[name]
+		if _, exists := registered[name]; exists {
+			return 0, fmt.Errorf("composite tool %s has the name of a generated tool", name)
+		}
+		for _, step := range composite.Steps {
+			if _, exists := registered[step.Tool]; !exists {
+				return 0, fmt.Errorf("composite tool %s: step %s calls unknown tool %s", name, step.Name, step.Tool)
+			}
+		}
+
+		server.RegisterTool(name, composite.Description, compositeInputSchema(composite), handlers.NewCompositeHandler(composite, call))
+		log.Printf("Registered composite tool: %s (%d steps)", name, len(composite.Steps))
+	}
+	return len(names), nil
+}
+
+// compositeInputSchema returns the input schema of a composite tool from its declared arguments
+func compositeInputSchema(composite config.CompositeTool) map[string]interface{} {
+	properties := make(map[string]interface{}, len(composite.Arguments))
+	required := []string{}
+	for _, argument := range composite.Arguments {
+		argumentType := argument.Type
+		if argumentType == "" {
+			argumentType = "string"
+		}
+		properties[argument.Name] = map[string]interface{}{
+			"type":        argumentType,
+			"description": argument.Description,
+		}
+		if argument.Required {
+			required = append(required, argument.Name)
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"mcpify/internal/config"
+	"mcpify/internal/openapi"
+	"mcpify/pkg/mcp"
+)
+
+// docsIndexTemplate lists the loaded specifications and the tools agents see
+var docsIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>mcpify API documentation</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 0.3em 1em 0.3em 0; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>APIs</h1>
+<ul>
+{{range .APIs}}<li><a href="/docs/apis/{{.Index}}">{{.SpecPath}}</a>{{if .ToolPrefix}} (tool prefix <code>{{.ToolPrefix}}</code>){{end}}</li>
+{{end}}</ul>
+<h1>Tools</h1>
+<table>
+<tr><th>Name</th><th>Description</th><th>Enabled</th></tr>
+{{range .Tools}}<tr><td><code>{{.Name}}</code></td><td>{{.Description}}</td><td>{{.Enabled}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// docsUITemplates render one specification, loading the UI from its CDN
+var docsUITemplates = map[string]*template.Template{
+	"swagger": template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.SpecPath}}</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>SwaggerUIBundle({url: "{{.SpecURL}}", dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`)),
+	"redoc": template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.SpecPath}}</title>
+</head>
+<body>
+<redoc spec-url="{{.SpecURL}}"></redoc>
+<script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`)),
+}
+
+// docsAPI is a loaded specification as listed by the documentation index
+type docsAPI struct {
+	Index      int
+	SpecPath   string
+	ToolPrefix string
+	SpecURL    string
+}
+
+// docsSpecs keeps the specifications served by /docs converted to OpenAPI 3 JSON, for one
+// configuration at a time, so requests never fetch or convert a specification themselves
+type docsSpecs struct {
+	mu    sync.Mutex
+	cfg   *config.Config
+	specs []docsSpec
+}
+
+// docsSpec is a converted specification, or the error converting it
+type docsSpec struct {
+	data []byte
+	err  error
+}
+
+// load converts the specifications of cfg, unless they already are, and returns them
+func (d *docsSpecs) load(cfg *config.Config) []docsSpec {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cfg == cfg {
+		return d.specs
+	}
+	specs := make([]docsSpec, 0, len(cfg.APIConfigs()))
+	for _, api := range cfg.APIConfigs() {
+		var spec docsSpec
+		converted, err := openapi.NewParser(api).Spec()
+		if err == nil {
+			spec.data, err = converted.MarshalJSON()
+		}
+		if err != nil {
+			log.Printf("Docs: %v", err)
+			spec.err = err
+		}
+		specs = append(specs, spec)
+	}
+	d.cfg, d.specs = cfg, specs
+	return specs
+}
+
+// newDocsHandler serves the API documentation:
+//
+//	GET /docs                           the loaded specifications and the registered tools
+//	GET /docs/apis/{index}              one specification rendered with Swagger UI or Redoc
+//	GET /docs/apis/{index}/openapi.json the specification as loaded, converted to OpenAPI 3
+//
+// Specifications are read from the running configuration, so the pages follow reloads. They
+// are converted when the handler is created and on each reload.
+func newDocsHandler(docsCfg config.DocsConfig, server *mcp.Server, reload *reloader) http.Handler {
+	mux := http.NewServeMux()
+	specs := &docsSpecs{}
+	reload.setDocs(specs)
+	go specs.load(reload.Config())
+
+	index := func(w http.ResponseWriter, r *http.Request) {
+		var tools []adminTool
+		for _, schema := range server.Tools() {
+			tools = append(tools, adminTool{
+				Name:        schema.Name,
+				Description: schema.Description,
+				Enabled:     server.IsToolEnabled(schema.Name),
+			})
+		}
+		data := struct {
+			APIs  []docsAPI
+			Tools []adminTool
+		}{docsAPIs(reload.Config()), tools}
+		writeDocsPage(w, docsIndexTemplate, data)
+	}
+	mux.HandleFunc("GET /docs", index)
+	mux.HandleFunc("GET /docs/{$}", index)
+
+	ui := docsUITemplates[docsCfg.UI]
+	if ui == nil {
+		ui = docsUITemplates["swagger"]
+	}
+	mux.HandleFunc("GET /docs/apis/{index}", func(w http.ResponseWriter, r *http.Request) {
+		api, ok := docsAPIFromPath(w, r, reload.Config())
+		if !ok {
+			return
+		}
+		writeDocsPage(w, ui, api)
+	})
+
+	mux.HandleFunc("GET /docs/apis/{index}/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		cfg := reload.Config()
+		api, ok := docsAPIFromPath(w, r, cfg)
+		if !ok {
+			return
+		}
+		spec := specs.load(cfg)[api.Index]
+		if spec.err != nil {
+			http.Error(w, "Failed to load the specification", http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(spec.data)
+	})
+
+	return mux
+}
+
+// docsAPIs lists the specifications of cfg
+func docsAPIs(cfg *config.Config) []docsAPI {
+	var apis []docsAPI
+	for i, api := range cfg.APIConfigs() {
+		apis = append(apis, docsAPI{
+			Index:      i,
+			SpecPath:   api.SpecPath,
+			ToolPrefix: api.ToolPrefix,
+			SpecURL:    "/docs/apis/" + strconv.Itoa(i) + "/openapi.json",
+		})
+	}
+	return apis
+}
+
+// docsAPIFromPath returns the specification selected by the {index} path segment, or writes
+// 404 when there is none
+func docsAPIFromPath(w http.ResponseWriter, r *http.Request, cfg *config.Config) (docsAPI, bool) {
+	apis := docsAPIs(cfg)
+	i, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil || i < 0 || i >= len(apis) {
+		http.NotFound(w, r)
+		return docsAPI{}, false
+	}
+	return apis[i], true
+}
+
+// writeDocsPage renders a documentation page
+func writeDocsPage(w http.ResponseWriter, page *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := page.Execute(w, data); err != nil {
+		log.Printf("Docs: failed to render page: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mcpify/internal/config"
+	"mcpify/pkg/mcp"
+)
+
+func TestDocsHandler(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	cfg := config.Default()
+	cfg.OpenAPI.SpecPath = specPath
+	cfg.OpenAPI.BaseURL = "http://localhost"
+	server := mcp.NewServer()
+	if _, err := buildTools(server, cfg, nil); err != nil {
+		t.Fatalf("Failed to build tools: %v", err)
+	}
+
+	handler := newDocsHandler(config.DocsConfig{Enabled: true, UI: "redoc"}, server, newReloader(options{}, server, cfg))
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(httpServer.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := get("/docs")
+	if status != http.StatusOK || !strings.Contains(body, "get_users") || !strings.Contains(body, `href="/docs/apis/0"`) {
+		t.Errorf("Expected the index to list the API and its tools, got %d: %s", status, body)
+	}
+
+	status, body = get("/docs/apis/0")
+	if status != http.StatusOK || !strings.Contains(body, "<redoc") {
+		t.Errorf("Expected a Redoc page, got %d: %s", status, body)
+	}
+
+	status, body = get("/docs/apis/0/openapi.json")
+	if status != http.StatusOK {
+		t.Fatalf("Expected the spec, got %d: %s", status, body)
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &spec); err != nil {
+		t.Fatalf("Expected JSON spec, got %v", err)
+	}
+	if _, ok := spec["paths"].(map[string]interface{})["/users"]; !ok {
+		t.Errorf("Expected the /users path in the spec, got %v", spec["paths"])
+	}
+
+	if status, _ := get("/docs/apis/1"); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown API, got %d", status)
+	}
+}
+
+func TestDocsHandler_ConvertsSpecsOncePerConfiguration(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	writeReloadConfig(t, configPath, specPath, "")
+	opts := options{configPath: configPath}
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	server := mcp.NewServer()
+	reload := newReloader(opts, server, cfg)
+	handler := newDocsHandler(config.DocsConfig{Enabled: true}, server, reload)
+
+	get := func() string {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/docs/apis/0/openapi.json", nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected the spec, got %d: %s", recorder.Code, recorder.Body)
+		}
+		return recorder.Body.String()
+	}
+	if !strings.Contains(get(), "/orders") {
+		t.Fatal("Expected the /orders path in the spec")
+	}
+
+	// Requests are served from the converted spec, which is only read again on reload
+	changed := strings.Replace(reloadTestSpec, `"/orders"`, `"/invoices"`, 1)
+	if err := os.WriteFile(specPath, []byte(changed), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	if body := get(); !strings.Contains(body, "/orders") {
+		t.Errorf("Expected the spec converted before the change, got %s", body)
+	}
+	if err := reload.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if body := get(); !strings.Contains(body, "/invoices") {
+		t.Errorf("Expected the reloaded spec, got %s", body)
+	}
+}
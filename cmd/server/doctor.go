@@ -0,0 +1,171 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mcpify/internal/config"
+	"mcpify/internal/httpclient"
+	"mcpify/internal/types"
+)
+
+// runDoctor diagnoses a deployment: it checks the configuration, fetches each spec, connects to
+// each base URL, verifies the credentials with a harmless GET request and checks that the HTTP
+// port is free. Unlike validate it talks to the upstream APIs.
+func runDoctor(args []string, stdout io.Writer) error {
+	var opts options
+	fs := newFlagSet("doctor", "[options]", &opts)
+	addServerFlags(fs, &opts)
+	verbose := addVerboseFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	defer quietLogs(*verbose)()
+
+	report := &validationReport{w: stdout}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		report.fail("%v", err)
+		return fmt.Errorf("doctor found %s", countOf(report.errors, "problem"))
+	}
+	if opts.configPath != "" {
+		report.ok("configuration %s", opts.configPath)
+	}
+
+	loaded := true
+	for _, api := range cfg.APIConfigs() {
+		if !report.checkAPI(api) {
+			loaded = false
+		}
+	}
+
+	// Tools are only needed to pick the request for the auth check
+	if loaded {
+		generated, err := generateTools(cfg, nil)
+		if err != nil {
+			report.fail("%v", err)
+		}
+		for _, api := range generated {
+			client := httpclient.New(api.api)
+			if checkBaseURL(report, client, api.api) {
+				checkAuth(report, client, api)
+			}
+		}
+	}
+
+	if cfg.Server.Transport == "http" {
+		checkPort(report, cfg.Server.HTTP.Host, cfg.Server.HTTP.Port)
+	}
+
+	if report.errors > 0 {
+		return fmt.Errorf("doctor found %s", countOf(report.errors, "problem"))
+	}
+	fmt.Fprintf(stdout, "All checks passed\n")
+	return nil
+}
+
+// checkBaseURL reports whether the base URL of an API accepts connections. Any HTTP response
+// counts, since many APIs answer 404 at their root.
+func checkBaseURL(report *validationReport, client *http.Client, api *config.OpenAPIConfig) bool {
+	if api.BaseURL == "" {
+		report.fail("base URL for %s: not configured", api.SpecPath)
+		return false
+	}
+
+	resp, err := client.Get(api.BaseURL)
+	if err != nil {
+		report.fail("base URL %s: %v", api.BaseURL, err)
+		return false
+	}
+	resp.Body.Close()
+	report.ok("base URL %s (HTTP %d)", api.BaseURL, resp.StatusCode)
+	return true
+}
+
+// checkAuth sends a GET request without required parameters using the configured credentials
+// and reports whether the API rejects them
+func checkAuth(report *validationReport, client *http.Client, api apiTools) {
+	if (api.api.Auth.Type == "" || api.api.Auth.Type == "none") && len(api.api.Auth.Headers) == 0 {
+		return
+	}
+
+	tool, found := probeTool(api.tools)
+	if !found {
+		report.warn("auth for %s: no GET operation without required parameters to check the credentials with", api.api.BaseURL)
+		return
+	}
+
+	req, err := api.handler.BuildRequest(tool, map[string]interface{}{}, config.RequestContext{})
+	if err != nil {
+		report.fail("auth for %s: %v", api.api.BaseURL, err)
+		return
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		report.fail("auth for %s: GET %s: %v", api.api.BaseURL, tool.Path, err)
+		return
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		report.fail("auth for %s: %s credentials rejected by GET %s (HTTP %d)", api.api.BaseURL, api.api.Auth.Type, tool.Path, resp.StatusCode)
+	case resp.StatusCode >= 400:
+		report.warn("auth for %s: could not verify the credentials, GET %s returned HTTP %d", api.api.BaseURL, tool.Path, resp.StatusCode)
+	default:
+		report.ok("auth for %s: %s credentials accepted by GET %s (HTTP %d)", api.api.BaseURL, api.api.Auth.Type, tool.Path, resp.StatusCode)
+	}
+}
+
+// probeTool picks the GET operation with the shortest path that has no required parameters
+func probeTool(tools []types.APITool) (types.APITool, bool) {
+	var candidates []types.APITool
+	for _, tool := range tools {
+		if tool.Method != http.MethodGet {
+			continue
+		}
+		required := false
+		for _, param := range tool.Parameters {
+			required = required || param.Required
+		}
+		if !required {
+			candidates = append(candidates, tool)
+		}
+	}
+	if len(candidates) == 0 {
+		return types.APITool{}, false
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i].Path) != len(candidates[j].Path) {
+			return len(candidates[i].Path) < len(candidates[j].Path)
+		}
+		return candidates[i].Path < candidates[j].Path
+	})
+	return candidates[0], true
+}
+
+// checkPort reports whether the HTTP transport could listen on host and port
+func checkPort(report *validationReport, host string, port int) {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		report.fail("port %s: %v", address, err)
+		return
+	}
+	listener.Close()
+	report.ok("port %s is available", address)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/httpclient"
+	"mcpify/pkg/mcp"
+)
+
+// healthCheckToolName is the name of the meta tool probing the upstream APIs
+const healthCheckToolName = "health_check"
+
+// upstreamHealth is the health_check result for one API
+type upstreamHealth struct {
+	BaseURL string `json:"base_url"`
+	URL     string `json:"url,omitempty"`
+	// Reachable is set when the API answered, whatever the status
+	Reachable bool `json:"reachable"`
+	// Healthy is set when the API answered and, when a health path is probed, without an
+	// error status; the base URL itself often answers 404
+	Healthy   bool   `json:"healthy"`
+	Status    int    `json:"status,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// registerHealthCheck registers the health_check tool, probing the base URL or health path of
+// each API concurrently
+func registerHealthCheck(server *mcp.Server, check config.HealthCheckToolConfig, generated []apiTools) {
+	server.RegisterTool(
+		healthCheckToolName,
+		"Checks that the upstream APIs are reachable and reports their HTTP status and latency. Call it when tool calls fail with connection errors or timeouts.",
+		map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			results := make([]upstreamHealth, len(generated))
+			var wg sync.WaitGroup
+			for i, api := range generated {
+				wg.Add(1)
+				go func(i int, api *config.OpenAPIConfig) {
+					defer wg.Done()
+					results[i] = probeUpstream(httpclient.New(api), api.BaseURL, check)
+				}(i, api.api)
+			}
+			wg.Wait()
+
+			healthy := true
+			for _, result := range results {
+				healthy = healthy && result.Healthy
+			}
+			return map[string]interface{}{"healthy": healthy, "apis": results}, nil
+		},
+	)
+}
+
+// probeUpstream sends a GET request to the health path under baseURL, or to baseURL itself,
+// and reports whether and how fast the API answered
+func probeUpstream(client *http.Client, baseURL string, check config.HealthCheckToolConfig) upstreamHealth {
+	health := upstreamHealth{BaseURL: baseURL}
+	if baseURL == "" {
+		health.Error = "base URL not configured"
+		return health
+	}
+	health.URL = baseURL
+	if check.Path != "" {
+		health.URL = strings.TrimSuffix(baseURL, "/") + check.Path
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), check.TimeoutOrDefault())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, health.URL, nil)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	health.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+
+	health.Reachable = true
+	health.Status = resp.StatusCode
+	health.Healthy = check.Path == "" || resp.StatusCode < http.StatusBadRequest
+	if !health.Healthy {
+		health.Error = fmt.Sprintf("health path returned HTTP %d", resp.StatusCode)
+	}
+	return health
+}
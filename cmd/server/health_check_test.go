@@ -0,0 +1,67 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mcpify/internal/config"
+	"mcpify/pkg/mcp"
+)
+
+func TestHealthCheck(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name      string
+		baseURL   string
+		path      string
+		reachable bool
+		healthy   bool
+		status    float64
+	}{
+		{name: "base URL", baseURL: upstream.URL, reachable: true, healthy: true, status: 404},
+		{name: "health path", baseURL: upstream.URL + "/", path: "/health", reachable: true, healthy: true, status: 200},
+		{name: "failing health path", baseURL: upstream.URL, path: "/ready", reachable: true, status: 404},
+		{name: "unreachable", baseURL: "http://127.0.0.1:1", path: "/health"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newMetaToolsConfig(t)
+			cfg.OpenAPI.BaseURL = tt.baseURL
+			cfg.MetaTools.HealthCheck = config.HealthCheckToolConfig{Enabled: true, Path: tt.path}
+
+			server := mcp.NewServer()
+			if _, err := buildTools(server, cfg, nil); err != nil {
+				t.Fatalf("Failed to build tools: %v", err)
+			}
+			result := callMetaTool(t, server, healthCheckToolName, nil)
+			apis, _ := result["apis"].([]interface{})
+			if len(apis) != 1 {
+				t.Fatalf("Expected one API, got %v", result)
+			}
+			health := apis[0].(map[string]interface{})
+			if health["reachable"] != tt.reachable || health["healthy"] != tt.healthy || result["healthy"] != tt.healthy {
+				t.Errorf("Expected reachable %v and healthy %v, got %v", tt.reachable, tt.healthy, result)
+			}
+			if status, _ := health["status"].(float64); status != tt.status {
+				t.Errorf("Expected status %v, got %v", tt.status, health["status"])
+			}
+			if _, exists := health["latency_ms"]; !exists {
+				t.Errorf("Expected the latency, got %v", health)
+			}
+			if !tt.healthy && health["error"] == nil {
+				t.Errorf("Expected an error, got %v", health)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"mcpify/internal/types"
+)
+
+// parameterDescription describes a parameter for the input schema: its description, its
+// location and the constraints of its schema, which some clients do not show to the model
+// when they are only JSON Schema keywords, e.g. "Sort order (in query; one of: "asc", "desc";
+// default: "asc")"
+func parameterDescription(param types.OpenAPIParameter) string {
+	hints := append([]string{"in " + param.In}, constraintHints(param.SchemaMap())...)
+	return param.Description + " (" + strings.Join(hints, "; ") + ")"
+}
+
+// constraintHints returns human-readable hints for the allowed values, ranges, lengths,
+// pattern, format and default of a schema, and the allowed values of array items
+func constraintHints(schema map[string]interface{}) []string {
+	if schema == nil {
+		return nil
+	}
+	var hints []string
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		hints = append(hints, "one of: "+hintValues(enum))
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		if enum, ok := items["enum"].([]interface{}); ok && len(enum) > 0 {
+			hints = append(hints, "items one of: "+hintValues(enum))
+		}
+	}
+
+	// OpenAPI 3.0 marks bounds as exclusive with booleans, OpenAPI 3.1 gives exclusive bounds as numbers
+	if minimum, ok := schema["minimum"]; ok {
+		if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive {
+			hints = append(hints, "greater than "+hintValue(minimum))
+		} else {
+			hints = append(hints, "minimum "+hintValue(minimum))
+		}
+	}
+	if minimum, ok := schema["exclusiveMinimum"]; ok {
+		if _, isBool := minimum.(bool); !isBool {
+			hints = append(hints, "greater than "+hintValue(minimum))
+		}
+	}
+	if maximum, ok := schema["maximum"]; ok {
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive {
+			hints = append(hints, "less than "+hintValue(maximum))
+		} else {
+			hints = append(hints, "maximum "+hintValue(maximum))
+		}
+	}
+	if maximum, ok := schema["exclusiveMaximum"]; ok {
+		if _, isBool := maximum.(bool); !isBool {
+			hints = append(hints, "less than "+hintValue(maximum))
+		}
+	}
+
+	for _, bound := range []struct{ keyword, format string }{
+		{"minLength", "at least %s characters"},
+		{"maxLength", "at most %s characters"},
+		{"minItems", "at least %s items"},
+		{"maxItems", "at most %s items"},
+	} {
+		if value, ok := schema[bound.keyword]; ok {
+			hints = append(hints, fmt.Sprintf(bound.format, hintValue(value)))
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok && pattern != "" {
+		hints = append(hints, "pattern: "+pattern)
+	}
+	if format, ok := schema["format"].(string); ok && format != "" {
+		hints = append(hints, "format: "+format)
+	}
+	if value, ok := schema["default"]; ok {
+		hints = append(hints, "default: "+hintValue(value))
+	}
+	return hints
+}
+
+// hintValues formats a list of allowed values
+func hintValues(values []interface{}) string {
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = hintValue(value)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// hintValue formats a value as JSON, so strings are quoted and numbers have no exponent
+func hintValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
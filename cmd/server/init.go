@@ -0,0 +1,245 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"mcpify/internal/config"
+	"mcpify/internal/openapi"
+)
+
+// initOptions holds the values used to generate a starter configuration
+type initOptions struct {
+	specPath  string
+	baseURL   string
+	transport string
+	output    string
+	force     bool
+}
+
+// runInit generates a commented starter configuration for a spec, detecting the base URL and
+// authentication from the spec. Values not given as flags are prompted for on a terminal.
+func runInit(args []string, stdout io.Writer) error {
+	var opts initOptions
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	fs.StringVar(&opts.specPath, "spec", "", "Path to OpenAPI specification (local file or URL)")
+	fs.StringVar(&opts.specPath, "s", "", "Path to OpenAPI specification (local file or URL)")
+	fs.StringVar(&opts.baseURL, "base-url", "", "Base URL for API requests (defaults to the spec's first server)")
+	fs.StringVar(&opts.baseURL, "b", "", "Base URL for API requests (defaults to the spec's first server)")
+	fs.StringVar(&opts.transport, "transport", "", "Transport method (stdio, http)")
+	fs.StringVar(&opts.transport, "t", "", "Transport method (stdio, http)")
+	fs.StringVar(&opts.output, "output", "config.yaml", "File to write, or - for stdout")
+	fs.StringVar(&opts.output, "o", "config.yaml", "File to write, or - for stdout")
+	fs.BoolVar(&opts.force, "force", false, "Overwrite an existing output file")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: mcpify init [options]\n")
+		printFlags(fs.Output(), fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Prompt for missing values when run interactively
+	interactive := opts.specPath == "" && isTerminal(os.Stdin)
+	input := bufio.NewReader(os.Stdin)
+	if interactive {
+		opts.specPath = prompt(input, os.Stderr, "OpenAPI spec (file or URL)", "")
+	}
+	if opts.specPath == "" {
+		fs.Usage()
+		return errors.New("--spec is required")
+	}
+
+	defer quietLogs(false)()
+	summary, err := openapi.NewParser(&config.OpenAPIConfig{SpecPath: opts.specPath, Timeout: config.Default().OpenAPI.Timeout}).Describe()
+	if err != nil {
+		return err
+	}
+
+	if opts.baseURL == "" {
+		opts.baseURL = detectBaseURL(opts.specPath, summary.Servers)
+		if interactive {
+			opts.baseURL = prompt(input, os.Stderr, "Base URL", opts.baseURL)
+		}
+	}
+	if opts.transport == "" {
+		opts.transport = "stdio"
+		if interactive {
+			opts.transport = prompt(input, os.Stderr, "Transport (stdio, http)", opts.transport)
+		}
+	}
+	if opts.transport != "stdio" && opts.transport != "http" {
+		return fmt.Errorf("invalid transport %q, expected stdio or http", opts.transport)
+	}
+
+	content := generateStarterConfig(opts, summary)
+	if opts.output == "-" {
+		_, err := io.WriteString(stdout, content)
+		return err
+	}
+
+	if !opts.force {
+		if _, err := os.Stat(opts.output); err == nil {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", opts.output)
+		}
+	}
+	if err := os.WriteFile(opts.output, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.output, err)
+	}
+	fmt.Fprintf(stdout, "Wrote %s for %s (%d operations)\n", opts.output, summary.Title, summary.Operations)
+	fmt.Fprintf(stdout, "Check it with: mcpify validate --config %s\n", opts.output)
+	return nil
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// prompt asks a question and returns the answer, or def when the answer is empty
+func prompt(input *bufio.Reader, w io.Writer, question, def string) string {
+	if def != "" {
+		fmt.Fprintf(w, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w, "%s: ", question)
+	}
+	answer, _ := input.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// detectBaseURL returns the first server of the spec, resolving relative server URLs
+// against the spec URL, or the spec URL's origin when the spec declares no servers
+func detectBaseURL(specPath string, servers []string) string {
+	if len(servers) == 0 {
+		return extractBaseURLFromSpec(specPath)
+	}
+
+	serverURL, err := url.Parse(servers[0])
+	if err != nil || serverURL.IsAbs() {
+		return strings.TrimSuffix(servers[0], "/")
+	}
+	specURL, err := url.Parse(specPath)
+	if err != nil || !specURL.IsAbs() {
+		// A relative server in a local spec cannot be resolved
+		return ""
+	}
+	return strings.TrimSuffix(specURL.ResolveReference(serverURL).String(), "/")
+}
+
+// generateStarterConfig renders a commented YAML configuration
+func generateStarterConfig(opts initOptions, summary *openapi.SpecSummary) string {
+	defaults := config.Default()
+	title := summary.Title
+	if title == "" {
+		title = opts.specPath
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# MCPify configuration for %s\n", title)
+	fmt.Fprintf(&b, "# Generated by \"mcpify init\". Check changes with \"mcpify validate --config <file>\".\n\n")
+
+	fmt.Fprintf(&b, "server:\n")
+	fmt.Fprintf(&b, "  # stdio for local clients that start mcpify themselves, http for remote clients\n")
+	fmt.Fprintf(&b, "  transport: %s\n", opts.transport)
+	fmt.Fprintf(&b, "  http:\n")
+	fmt.Fprintf(&b, "    host: %q\n", defaults.Server.HTTP.Host)
+	fmt.Fprintf(&b, "    port: %d\n\n", defaults.Server.HTTP.Port)
+
+	fmt.Fprintf(&b, "openapi:\n")
+	fmt.Fprintf(&b, "  spec_path: %q\n", opts.specPath)
+	if opts.baseURL != "" {
+		fmt.Fprintf(&b, "  # Base URL for API requests\n")
+		fmt.Fprintf(&b, "  base_url: %q\n", opts.baseURL)
+	} else {
+		fmt.Fprintf(&b, "  # Base URL for API requests; the spec does not declare an absolute server URL\n")
+		fmt.Fprintf(&b, "  # base_url: \"https://api.example.com\"\n")
+	}
+	fmt.Fprintf(&b, "  timeout: %s\n", defaults.OpenAPI.Timeout)
+	fmt.Fprintf(&b, "  max_retries: %d\n", defaults.OpenAPI.MaxRetries)
+	fmt.Fprintf(&b, "  # Prefix added to every tool name\n")
+	fmt.Fprintf(&b, "  # tool_prefix: \"api\"\n")
+	fmt.Fprintf(&b, "  # Only expose some of the %d operations (\"*\" matches any characters)\n", summary.Operations)
+	fmt.Fprintf(&b, "  # include_paths:\n")
+	fmt.Fprintf(&b, "  #   - \"/users/*\"\n")
+	fmt.Fprintf(&b, "  # exclude_paths:\n")
+	fmt.Fprintf(&b, "  #   - \"/admin/*\"\n\n")
+	writeAuthBlock(&b, summary.SecuritySchemes)
+
+	fmt.Fprintf(&b, "\nlogging:\n")
+	fmt.Fprintf(&b, "  level: %s\n", defaults.Logging.Level)
+	return b.String()
+}
+
+// writeAuthBlock scaffolds the auth section for the first supported security scheme
+func writeAuthBlock(b *strings.Builder, schemes []openapi.SecurityScheme) {
+	for _, scheme := range schemes {
+		switch {
+		case scheme.Type == "http" && scheme.Scheme == "bearer",
+			scheme.Type == "oauth2", scheme.Type == "openIdConnect":
+			fmt.Fprintf(b, "  # Detected security scheme %q (%s)\n", scheme.Name, describeScheme(scheme))
+			if scheme.Type != "http" {
+				fmt.Fprintf(b, "  # Obtain an access token from the identity provider and pass it as a bearer token.\n")
+			}
+			fmt.Fprintf(b, "  # Set API_TOKEN in the environment, or use token_file to read it from a file.\n")
+			fmt.Fprintf(b, "  auth:\n")
+			fmt.Fprintf(b, "    type: bearer\n")
+			fmt.Fprintf(b, "    token: \"${API_TOKEN}\"\n")
+		case scheme.Type == "http" && scheme.Scheme == "basic":
+			fmt.Fprintf(b, "  # Detected security scheme %q (%s)\n", scheme.Name, describeScheme(scheme))
+			fmt.Fprintf(b, "  # Set API_USERNAME and API_PASSWORD in the environment.\n")
+			fmt.Fprintf(b, "  auth:\n")
+			fmt.Fprintf(b, "    type: basic\n")
+			fmt.Fprintf(b, "    username: \"${API_USERNAME}\"\n")
+			fmt.Fprintf(b, "    password: \"${API_PASSWORD}\"\n")
+		case scheme.Type == "apiKey" && (scheme.In == "header" || scheme.In == "query"):
+			fmt.Fprintf(b, "  # Detected security scheme %q (%s)\n", scheme.Name, describeScheme(scheme))
+			fmt.Fprintf(b, "  # Set API_KEY in the environment, or use api_key_file to read it from a file.\n")
+			fmt.Fprintf(b, "  auth:\n")
+			fmt.Fprintf(b, "    type: api_key\n")
+			fmt.Fprintf(b, "    api_key: \"${API_KEY}\"\n")
+			fmt.Fprintf(b, "    api_key_name: %q\n", scheme.Key)
+			fmt.Fprintf(b, "    api_key_in: %s\n", scheme.In)
+		default:
+			continue
+		}
+
+		for _, other := range schemes {
+			if other.Name != scheme.Name {
+				fmt.Fprintf(b, "  # The spec also declares %q (%s)\n", other.Name, describeScheme(other))
+			}
+		}
+		return
+	}
+
+	for _, scheme := range schemes {
+		fmt.Fprintf(b, "  # The spec declares %q (%s), which is not supported; add headers for it below.\n", scheme.Name, describeScheme(scheme))
+	}
+	fmt.Fprintf(b, "  auth:\n")
+	fmt.Fprintf(b, "    type: none\n")
+}
+
+// describeScheme returns a short description of a security scheme
+func describeScheme(scheme openapi.SecurityScheme) string {
+	switch scheme.Type {
+	case "http":
+		return "http " + scheme.Scheme
+	case "apiKey":
+		return fmt.Sprintf("api key %s in %s", scheme.Key, scheme.In)
+	default:
+		return scheme.Type
+	}
+}
@@ -8,171 +8,629 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"mcpify/internal/auth"
 	"mcpify/internal/config"
 	"mcpify/internal/handlers"
 	"mcpify/internal/openapi"
+	"mcpify/internal/ratelimit"
+	"mcpify/internal/redis"
+	"mcpify/internal/secrets"
 	"mcpify/internal/types"
+	"mcpify/pkg/extension"
 	"mcpify/pkg/mcp"
 	"net/url"
 	"os"
 	"os/signal"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
 )
 
 func main() {
-	// Parse command line flags with combined long/short form help
-	transport := flag.String("transport", "", "Transport method (stdio, http)")
-	port := flag.Int("port", 0, "Port for HTTP transport")
-	host := flag.String("host", "", "Host for HTTP transport")
-	configPath := flag.String("config", "", "Path to configuration file")
-	specPath := flag.String("spec", "", "Path to OpenAPI specification (local file or URL)")
-	baseURL := flag.String("base-url", "", "Base URL for API requests (defaults to domain from spec URL)")
-	debug := flag.Bool("debug", false, "Enable debug logging for API requests and responses")
-
-	// Add short flag aliases
-	flag.StringVar(transport, "t", "", "Transport method (stdio, http)")
-	flag.IntVar(port, "p", 0, "Port for HTTP transport")
-	flag.StringVar(host, "h", "", "Host for HTTP transport")
-	flag.StringVar(configPath, "c", "", "Path to configuration file")
-	flag.StringVar(specPath, "s", "", "Path to OpenAPI specification (local file or URL)")
-	flag.StringVar(baseURL, "b", "", "Base URL for API requests (defaults to domain from spec URL)")
-	flag.BoolVar(debug, "d", false, "Enable debug logging for API requests and responses")
-
-	// Customize flag usage to show both long and short forms on same line
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  -b, --base-url string\n")
-		fmt.Fprintf(os.Stderr, "        Base URL for API requests (defaults to domain from spec URL)\n")
-		fmt.Fprintf(os.Stderr, "  -c, --config string\n")
-		fmt.Fprintf(os.Stderr, "        Path to configuration file\n")
-		fmt.Fprintf(os.Stderr, "  -h, --host string\n")
-		fmt.Fprintf(os.Stderr, "        Host for HTTP transport\n")
-		fmt.Fprintf(os.Stderr, "  -p, --port int\n")
-		fmt.Fprintf(os.Stderr, "        Port for HTTP transport\n")
-		fmt.Fprintf(os.Stderr, "  -s, --spec string\n")
-		fmt.Fprintf(os.Stderr, "        Path to OpenAPI specification (local file or URL)\n")
-		fmt.Fprintf(os.Stderr, "  -t, --transport string\n")
-		fmt.Fprintf(os.Stderr, "        Transport method (stdio, http)\n")
-		fmt.Fprintf(os.Stderr, "  --help\n")
-		fmt.Fprintf(os.Stderr, "        Show this help message\n")
-	}
-
-	flag.Parse()
+	cmd, args, err := resolveCommand(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+
+	// Allow auth secrets to reference Vault and cloud secret managers
+	secrets.Register()
+
+	if err := cmd.run(args, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runServe starts the MCP server with the configured transport
+func runServe(args []string, stdout io.Writer) error {
+	var opts options
+	fs := newFlagSet("serve", "[options]", &opts)
+	addServerFlags(fs, &opts)
+	trace := fs.Bool("trace", false, "Log every JSON-RPC message received and sent, with secrets redacted, to stderr")
+	traceFile := fs.String("trace-file", "", "Log the --trace messages to this file instead of stderr")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
 
 	// Load configuration
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	if opts.configPath == config.StdinPath && cfg.Server.Transport == "stdio" {
+		return fmt.Errorf("--config %s cannot be used with the stdio transport, which reads MCP messages from stdin", config.StdinPath)
+	}
+
+	// Create MCP server
+	server := mcp.NewServer()
+	server.SetVersion(currentVersion().Version)
+	server.SetErrorMappings(cfg.ErrorMappings)
+	server.SetMaxRequestSize(cfg.Security.RequestSizeLimitBytes())
+	if *trace || *traceFile != "" {
+		tracer, closeTrace, err := openTrace(cfg, *traceFile)
+		if err != nil {
+			return err
+		}
+		defer closeTrace()
+		server.SetTracer(tracer)
+	}
+
+	// Reload configuration on SIGHUP, and when its files change if watching is enabled
+	reload := newReloader(opts, server, cfg)
+	defer reload.closeExtensions()
+
+	// Parse OpenAPI specifications and register the generated tools, in the background when
+	// clients are to be served at once
+	if cfg.Server.LazyStart {
+		reload.loadInBackground(cfg)
+	} else {
+		toolCount, extensions, err := buildServedTools(server, cfg)
+		if err != nil {
+			return err
+		}
+		reload.setExtensions(extensions)
+		log.Printf("Successfully parsed OpenAPI specs, generated %d tools", toolCount)
+	}
+	if cfg.Server.Watch.Enabled {
+		if opts.configPath == config.StdinPath {
+			log.Printf("WARNING: Ignoring server.watch, a configuration read from stdin cannot be reloaded")
+		} else {
+			reload.watchFiles(nil)
+		}
+	}
+
+	// Log configuration summary
+	log.Printf("=== MCPify Configuration Summary ===")
+	log.Printf("Version: %s", currentVersion().Version)
+	for _, api := range cfg.APIConfigs() {
+		log.Printf("OpenAPI Spec: %s", api.SpecPath)
+		log.Printf("Base URL: %s", api.BaseURL)
+	}
+	log.Printf("Transport: %s", cfg.Server.Transport)
+	if cfg.Server.Transport == "http" {
+		log.Printf("HTTP Server: %s:%d", cfg.Server.HTTP.Host, cfg.Server.HTTP.Port)
+	}
+	log.Printf("=====================================")
+
+	// Serve the admin API on its own listener
+	if cfg.Server.Admin.Enabled {
+		go func() {
+			if err := startAdminServer(cfg.Server.Admin, server, reload); err != nil {
+				log.Printf("Admin API stopped: %v", err)
+			}
+		}()
+	}
+
+	// Start server based on transport
+	switch cfg.Server.Transport {
+	case "stdio":
+		log.Println("Starting mcpify server with stdio transport...")
+		go reload.watch()
+		transport := mcp.NewStdioTransport(server)
+		transport.SetFraming(cfg.Server.Stdio.FramingOrDefault())
+		go stopStdioOnSignal(transport, server, cfg.Server.Shutdown)
+		if err := transport.Start(); err != nil {
+			return fmt.Errorf("server error: %w", err)
+		}
+	case "http":
+		startHTTPServerWithConfig(server, cfg, reload)
+	default:
+		return fmt.Errorf("unknown transport: %s", cfg.Server.Transport)
+	}
+	return nil
+}
+
+// addServerFlags registers the flags that override the server transport settings
+func addServerFlags(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.transport, "transport", "", "Transport method (stdio, http)")
+	fs.StringVar(&opts.transport, "t", "", "Transport method (stdio, http)")
+	fs.StringVar(&opts.host, "host", "", "Host for HTTP transport")
+	fs.StringVar(&opts.host, "h", "", "Host for HTTP transport")
+	fs.IntVar(&opts.port, "port", 0, "Port for HTTP transport")
+	fs.IntVar(&opts.port, "p", 0, "Port for HTTP transport")
+}
+
+// options holds command line values that override the configuration file
+type options struct {
+	transport  string
+	port       int
+	host       string
+	configPath string
+	specPath   string
+	baseURL    string
+	debug      bool
+	strict     bool
+	profile    string
+}
+
+// loadConfig loads the configuration file, applies command line overrides and validates the result
+func loadConfig(opts options) (*config.Config, error) {
 	loader := config.NewLoader()
-	cfg, err := loader.Load(*configPath)
+	loader.Strict = opts.strict
+	loader.Profile = opts.profile
+	cfg, err := loader.Load(opts.configPath)
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	for _, key := range loader.UnknownKeys() {
+		log.Printf("WARNING: Ignoring unknown configuration key %s", key)
+	}
+	if opts.profile != "" {
+		log.Printf("Using configuration profile %s", opts.profile)
 	}
 
 	// Override configuration with command line flags and log warnings
-	if *transport != "" {
-		if cfg.Server.Transport != "" && cfg.Server.Transport != *transport {
-			log.Printf("WARNING: Overriding config transport '%s' with command line value '%s'", cfg.Server.Transport, *transport)
+	if opts.transport != "" {
+		if cfg.Server.Transport != "" && cfg.Server.Transport != opts.transport {
+			log.Printf("WARNING: Overriding config transport '%s' with command line value '%s'", cfg.Server.Transport, opts.transport)
 		}
-		cfg.Server.Transport = *transport
+		cfg.Server.Transport = opts.transport
 	}
-	if *host != "" {
-		if cfg.Server.HTTP.Host != "" && cfg.Server.HTTP.Host != *host {
-			log.Printf("WARNING: Overriding config host '%s' with command line value '%s'", cfg.Server.HTTP.Host, *host)
+	if opts.host != "" {
+		if cfg.Server.HTTP.Host != "" && cfg.Server.HTTP.Host != opts.host {
+			log.Printf("WARNING: Overriding config host '%s' with command line value '%s'", cfg.Server.HTTP.Host, opts.host)
 		}
-		cfg.Server.HTTP.Host = *host
+		cfg.Server.HTTP.Host = opts.host
 	}
-	if *port != 0 {
-		if cfg.Server.HTTP.Port != 0 && cfg.Server.HTTP.Port != *port {
-			log.Printf("WARNING: Overriding config port %d with command line value %d", cfg.Server.HTTP.Port, *port)
+	if opts.port != 0 {
+		if cfg.Server.HTTP.Port != 0 && cfg.Server.HTTP.Port != opts.port {
+			log.Printf("WARNING: Overriding config port %d with command line value %d", cfg.Server.HTTP.Port, opts.port)
 		}
-		cfg.Server.HTTP.Port = *port
+		cfg.Server.HTTP.Port = opts.port
 	}
-	if *specPath != "" {
-		if cfg.OpenAPI.SpecPath != "" && cfg.OpenAPI.SpecPath != *specPath {
-			log.Printf("WARNING: Overriding config spec_path '%s' with command line value '%s'", cfg.OpenAPI.SpecPath, *specPath)
+	if opts.specPath != "" {
+		if cfg.OpenAPI.SpecPath != "" && cfg.OpenAPI.SpecPath != opts.specPath {
+			log.Printf("WARNING: Overriding config spec_path '%s' with command line value '%s'", cfg.OpenAPI.SpecPath, opts.specPath)
 		}
-		cfg.OpenAPI.SpecPath = *specPath
+		cfg.OpenAPI.SpecPath = opts.specPath
 	}
-	if *baseURL != "" {
-		if cfg.OpenAPI.BaseURL != "" && cfg.OpenAPI.BaseURL != *baseURL {
-			log.Printf("WARNING: Overriding config base_url '%s' with command line value '%s'", cfg.OpenAPI.BaseURL, *baseURL)
+	if opts.baseURL != "" {
+		if cfg.OpenAPI.BaseURL != "" && cfg.OpenAPI.BaseURL != opts.baseURL {
+			log.Printf("WARNING: Overriding config base_url '%s' with command line value '%s'", cfg.OpenAPI.BaseURL, opts.baseURL)
 		}
-		cfg.OpenAPI.BaseURL = *baseURL
-	}
-	if *debug {
-		cfg.OpenAPI.Debug = true
+		cfg.OpenAPI.BaseURL = opts.baseURL
 	}
 
-	// Set default base URL from spec URL if not provided
-	if cfg.OpenAPI.BaseURL == "" && cfg.OpenAPI.SpecPath != "" {
-		if extractedBaseURL := extractBaseURLFromSpec(cfg.OpenAPI.SpecPath); extractedBaseURL != "" {
-			cfg.OpenAPI.BaseURL = extractedBaseURL
-			log.Printf("Using base URL extracted from spec: %s", cfg.OpenAPI.BaseURL)
+	for _, api := range cfg.APIConfigs() {
+		if opts.debug {
+			api.Debug = true
+		}
+
+		// Set default base URL from spec URL if not provided
+		if api.BaseURL == "" {
+			if extractedBaseURL := extractBaseURLFromSpec(api.SpecPath); extractedBaseURL != "" {
+				api.BaseURL = extractedBaseURL
+				log.Printf("Using base URL extracted from spec: %s", api.BaseURL)
+			}
 		}
 	}
 
 	// Validate final configuration
 	if err := cfg.Validate(); err != nil {
-		log.Fatalf("Configuration validation failed: %v", err)
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	// Create MCP server
-	server := mcp.NewServer()
+	return cfg, nil
+}
+
+// apiTools holds the tools generated from one upstream API and the handler that calls it
+type apiTools struct {
+	api     *config.OpenAPIConfig
+	handler *handlers.APIHandler
+	tools   []types.APITool
+}
+
+// generateTools parses each configured OpenAPI specification and returns its tools with overrides
+// applied, running extensions on their calls; nil leaves them without plugin hooks
+func generateTools(cfg *config.Config, extensions extension.Extension) ([]apiTools, error) {
+	toolSpecs := make(map[string]string)
+	var result []apiTools
+
+	// Every API shares one response memory budget
+	var budget *handlers.ResponseBudget
+	if size, err := config.ParseSize(cfg.Server.MaxResponseMemory); err == nil && size > 0 {
+		budget = handlers.NewResponseBudget(size)
+	}
 
-	// Parse OpenAPI specification and generate tools
-	parser := openapi.NewParser(&cfg.OpenAPI)
-	apiTools, err := parser.ParseSpec()
+	// Every API shares one retry policy, so the retry budget covers all tools
+	retry := handlers.NewRetryPolicy(cfg.Retry)
+
+	descriptions, err := cfg.Descriptions.Load()
 	if err != nil {
-		log.Fatalf("Failed to parse OpenAPI specification: %v", err)
+		return nil, err
 	}
 
-	log.Printf("Parsing OpenAPI spec from %s", cfg.OpenAPI.SpecPath)
+	aliased := make(map[string]bool, len(cfg.Aliases))
+	for _, api := range cfg.APIConfigs() {
+		log.Printf("Parsing OpenAPI spec from %s", api.SpecPath)
+		parser := openapi.NewParser(api)
+		tools, err := parser.ParseSpec()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OpenAPI specification %s: %w", api.SpecPath, err)
+		}
+
+		// Apply the tools section before checking names, so disabled tools cannot collide.
+		// Overrides match generated names, which aliases then replace.
+		tools = applyToolOverrides(cfg, tools)
+		tools = applyAliases(cfg, tools, aliased)
+		tools = applyDescriptions(cfg.Descriptions.ModeOrDefault(), descriptions, tools)
+		tools = applyDeprecations(cfg, tools)
+		tools = applyDescriptionCap(cfg.ToolSize, tools)
+
+		// Tools from different APIs share one namespace
+		for _, tool := range tools {
+			if specPath, exists := toolSpecs[tool.Name]; exists {
+				if aliased[tool.Name] {
+					return nil, fmt.Errorf("alias %q from %s is already the name of a tool from %s; choose another alias",
+						tool.Name, api.SpecPath, specPath)
+				}
+				return nil, fmt.Errorf("duplicate tool name %q from %s and %s; set a distinct tool_prefix for each API",
+					tool.Name, specPath, api.SpecPath)
+			}
+			toolSpecs[tool.Name] = api.SpecPath
+		}
 
-	// Create API handler
-	apiHandler := handlers.NewAPIHandler(&cfg.OpenAPI)
+		handler := handlers.NewAPIHandler(api)
+		handler.SetResponseBudget(budget)
+		handler.SetRetryPolicy(retry)
+		// Every API runs the same plugin hooks
+		handler.SetExtensions(extensions)
+		result = append(result, apiTools{api: api, handler: handler, tools: tools})
+	}
 
-	// Register tools from OpenAPI specification
-	registerAPITools(server, apiTools, apiHandler)
-	log.Printf("Successfully parsed OpenAPI spec, generated %d tools", len(apiTools))
+	warnUnusedAliases(cfg, aliased)
+	return result, nil
+}
 
-	// Log configuration summary
-	log.Printf("=== MCPify Configuration Summary ===")
-	log.Printf("OpenAPI Spec: %s", cfg.OpenAPI.SpecPath)
-	log.Printf("Base URL: %s", cfg.OpenAPI.BaseURL)
-	log.Printf("Transport: %s", cfg.Server.Transport)
-	if cfg.Server.Transport == "http" {
-		log.Printf("HTTP Server: %s:%d", cfg.Server.HTTP.Host, cfg.Server.HTTP.Port)
+// applyDescriptions replaces the descriptions of tools that have a localized description, or
+// appends it to them in append mode
+func applyDescriptions(mode string, descriptions map[string]string, apiTools []types.APITool) []types.APITool {
+	for i, tool := range apiTools {
+		description, exists := descriptions[tool.Name]
+		if !exists || description == "" {
+			continue
+		}
+		if mode == config.DescriptionsAppend && tool.Description != "" {
+			description = tool.Description + "\n\n" + description
+		}
+		apiTools[i].Description = description
 	}
-	log.Printf("=====================================")
+	return apiTools
+}
 
-	// Start server based on transport
-	switch cfg.Server.Transport {
-	case "stdio":
-		log.Println("Starting mcpify server with stdio transport...")
-		if err := server.Run(); err != nil {
-			log.Fatalf("Server error: %v", err)
+// applyDeprecations starts the descriptions of deprecated tools with a notice naming their
+// replacement, under its alias when it has one, so agents prefer current endpoints
+func applyDeprecations(cfg *config.Config, apiTools []types.APITool) []types.APITool {
+	for i, tool := range apiTools {
+		if !tool.Deprecated {
+			continue
 		}
-	case "http":
-		startHTTPServerWithConfig(server, cfg)
+		notice := "DEPRECATED."
+		switch {
+		case tool.DeprecatedBy != "":
+			replacement := tool.DeprecatedBy
+			if alias, exists := cfg.Aliases[replacement]; exists {
+				replacement = alias.Name
+			}
+			notice = fmt.Sprintf("DEPRECATED: use %s instead.", replacement)
+		case tool.DeprecationNote != "" && !strings.Contains(tool.Description, tool.DeprecationNote):
+			notice = "DEPRECATED: " + tool.DeprecationNote
+		}
+		apiTools[i].Description = notice + " " + tool.Description
+	}
+	return apiTools
+}
+
+// applyAliases renames tools that have an alias, keeping a copy under the generated name when
+// keep_original is set, and records the aliases applied in aliased
+func applyAliases(cfg *config.Config, apiTools []types.APITool, aliased map[string]bool) []types.APITool {
+	if len(cfg.Aliases) == 0 {
+		return apiTools
+	}
+
+	result := make([]types.APITool, 0, len(apiTools))
+	for _, tool := range apiTools {
+		alias, exists := cfg.Aliases[tool.Name]
+		if !exists {
+			result = append(result, tool)
+			continue
+		}
+		if alias.KeepOriginal {
+			result = append(result, tool)
+		}
+		log.Printf("Tool %s is aliased as %s", tool.Name, alias.Name)
+		tool.Name = alias.Name
+		aliased[alias.Name] = true
+		result = append(result, tool)
+	}
+	return result
+}
+
+// warnUnusedAliases logs the aliases whose generated tool does not exist, e.g. after the
+// spec renamed an operation
+func warnUnusedAliases(cfg *config.Config, aliased map[string]bool) {
+	var unused []string
+	for generated, alias := range cfg.Aliases {
+		if !aliased[alias.Name] {
+			unused = append(unused, generated)
+		}
+	}
+	sort.Strings(unused)
+	for _, generated := range unused {
+		log.Printf("WARNING: Alias %s is configured for unknown tool %s", cfg.Aliases[generated].Name, generated)
+	}
+}
+
+// loadExtensions opens the configured Go plugins and WASM transforms, returning nil when
+// there are none
+func loadExtensions(cfg *config.Config) (extension.Extension, error) {
+	if len(cfg.Extensions) == 0 {
+		return nil, nil
+	}
+	chain := make(extension.Chain, 0, len(cfg.Extensions))
+	for _, ext := range cfg.Extensions {
+		var loaded extension.Extension
+		var err error
+		source := ext.Path
+		if ext.WASM != "" {
+			source = ext.WASM
+			loaded, err = extension.OpenWASM(ext.WASM)
+		} else {
+			loaded, err = extension.Open(ext.Path, ext.Options)
+		}
+		if err != nil {
+			closeExtensions(chain)
+			return nil, fmt.Errorf("failed to load extension: %w", err)
+		}
+		if len(ext.Tools) > 0 {
+			loaded = extension.ForTools(loaded, ext.AppliesTo)
+		}
+		log.Printf("Loaded extension: %s", source)
+		chain = append(chain, loaded)
+	}
+	return chain, nil
+}
+
+// closeExtensions closes extensions that are no longer called, logging failures
+func closeExtensions(extensions extension.Extension) {
+	if extensions == nil {
+		return
+	}
+	if err := extensions.Close(); err != nil {
+		log.Printf("WARNING: Failed to close extensions: %v", err)
+	}
+}
+
+// buildTools generates the tools for every configured API and the composite tools built on
+// them, and registers them on server
+func buildTools(server *mcp.Server, cfg *config.Config, extensions extension.Extension) (int, error) {
+	generated, err := generateTools(cfg, extensions)
+	if err != nil {
+		return 0, err
+	}
+	return registerTools(server, cfg, generated)
+}
+
+// buildServedTools loads the configured extensions and builds the tools running them. Only
+// the commands calling tools load extensions; the caller closes them once the tools are
+// no longer served.
+func buildServedTools(server *mcp.Server, cfg *config.Config) (int, extension.Extension, error) {
+	extensions, err := loadExtensions(cfg)
+	if err != nil {
+		return 0, nil, err
+	}
+	count, err := buildTools(server, cfg, extensions)
+	if err != nil {
+		closeExtensions(extensions)
+		return 0, nil, err
+	}
+	return count, extensions, nil
+}
+
+// registerTools registers generated tools, the composite tools built on them and the enabled meta tools
+func registerTools(server *mcp.Server, cfg *config.Config, generated []apiTools) (int, error) {
+	count := 0
+	registered := make(map[string]mcp.ToolHandler)
+	for _, api := range generated {
+		registerAPITools(server, api.tools, api.handler, cfg.ToolSize, registered)
+		count += len(api.tools)
+	}
+
+	composites, err := registerCompositeTools(server, cfg.Composites, registered)
+	if err != nil {
+		return 0, err
+	}
+	metaTools, err := registerMetaTools(server, cfg, generated, registered)
+	if err != nil {
+		return 0, err
+	}
+	return count + composites + metaTools, nil
+}
+
+// applyToolOverrides applies the tools section of the configuration, dropping disabled tools
+func applyToolOverrides(cfg *config.Config, apiTools []types.APITool) []types.APITool {
+	if len(cfg.Tools) == 0 {
+		return apiTools
+	}
+
+	result := make([]types.APITool, 0, len(apiTools))
+	for _, tool := range apiTools {
+		override := cfg.ResolveToolOverride(tool.Name, tool.Method, tool.Path)
+		if !override.IsEnabled() {
+			log.Printf("Tool disabled by configuration: %s (%s %s)", tool.Name, tool.Method, tool.Path)
+			continue
+		}
+		if override.Description != "" {
+			tool.Description = override.Description
+		}
+		tool.Timeout = override.Timeout
+		tool.Headers = override.Headers
+		tool.RateLimit = override.RateLimit
+		if override.Accept != "" {
+			tool.Accept = override.Accept
+		}
+		tool.SkipMiddleware = override.SkipMiddleware
+		tool.Pagination = override.Pagination
+		tool.SubpathParams = override.SubpathParams
+		tool.HedgeDelay = override.HedgeDelay
+		tool.Debug = override.Debug
+		tool.CacheTTL = override.CacheTTL
+		tool.PreCall = override.PreCall
+		tool.PostCall = override.PostCall
+		result = append(result, tool)
+	}
+	return result
+}
+
+// newTokenValidator creates the client token validator, or returns nil when JWT validation is disabled
+func newTokenValidator(cfg *config.Config) mcp.TokenValidator {
+	if !cfg.Security.JWT.Enabled {
+		return nil
+	}
+	return auth.NewJWTValidator(cfg.Security.JWT)
+}
+
+// newBasicAuthenticator creates the checker of client Basic auth credentials, or returns nil
+// when Basic auth is disabled
+func newBasicAuthenticator(cfg *config.Config) (mcp.BasicAuthenticator, error) {
+	if !cfg.Security.BasicAuth.Enabled {
+		return nil, nil
+	}
+	return auth.NewBasicAuthenticator(cfg.Security.BasicAuth)
+}
+
+// newSessionStore creates the configured session store, or returns nil to keep sessions in memory
+func newSessionStore(cfg *config.Config, redisClient *redis.Client) (mcp.SessionStore, error) {
+	sessions := cfg.Server.HTTP.Sessions
+	switch sessions.Store {
+	case config.SessionStoreFile:
+		return mcp.NewFileSessionStore(sessions.Directory)
+	case config.SessionStoreRedis:
+		return mcp.NewRedisSessionStore(redisClient, sessions.KeyPrefixOrDefault(), cfg.Server.HTTP.SessionTimeout), nil
+	case config.SessionStoreSigned:
+		secret, err := sessions.SecretValue()
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewSignedSessionStore([]byte(secret), sessions.MaxAgeOrDefault()), nil
 	default:
-		log.Fatalf("Unknown transport: %s", cfg.Server.Transport)
+		return nil, nil
 	}
 }
 
-func startHTTPServerWithConfig(server *mcp.Server, cfg *config.Config) {
+// newRedisClient creates the client of the configured Redis server, or returns nil when none is configured
+func newRedisClient(cfg *config.Config) (*redis.Client, error) {
+	if cfg.Redis.IsZero() {
+		return nil, nil
+	}
+	return redis.New(cfg.Redis)
+}
+
+// newRateLimiter creates the limiter of tool calls per client, or returns nil when rate
+// limiting is disabled. The redis store shares the limit with the replicas using the same server.
+func newRateLimiter(cfg *config.Config, redisClient *redis.Client) mcp.RateLimiter {
+	rateLimiting := cfg.Security.RateLimiting
+	if !rateLimiting.Enabled {
+		return nil
+	}
+	if rateLimiting.Store == config.RateLimitStoreRedis && redisClient != nil {
+		return ratelimit.NewRedisLimiter(redisClient, rateLimiting.KeyPrefixOrDefault(), rateLimiting.RequestsPerMinute)
+	}
+	return ratelimit.NewMemoryLimiter(rateLimiting.RequestsPerMinute)
+}
+
+func startHTTPServerWithConfig(server *mcp.Server, cfg *config.Config, reload *reloader) {
+	redisClient, err := newRedisClient(cfg)
+	if err != nil {
+		log.Fatalf("Invalid redis configuration: %v", err)
+	}
+
 	// Configure MCP-compliant streamable HTTP transport from config
 	httpConfig := &mcp.StreamableHTTPConfig{
-		Host:           cfg.Server.HTTP.Host,
-		Port:           cfg.Server.HTTP.Port,
-		SessionTimeout: cfg.Server.HTTP.SessionTimeout,
-		MaxConnections: cfg.Server.HTTP.MaxConnections,
-		CORSEnabled:    cfg.Server.HTTP.CORS.Enabled,
-		CORSOrigins:    cfg.Server.HTTP.CORS.Origins,
+		Host:            cfg.Server.HTTP.Host,
+		Port:            cfg.Server.HTTP.Port,
+		SessionTimeout:  cfg.Server.HTTP.SessionTimeout,
+		MaxConnections:  cfg.Server.HTTP.MaxConnections,
+		CORSEnabled:     cfg.Server.HTTP.CORS.Enabled,
+		CORSOrigins:     cfg.Server.HTTP.CORS.Origins,
+		CORSMethods:     cfg.Server.HTTP.CORS.AllowedMethods,
+		CORSHeaders:     cfg.Server.HTTP.CORS.AllowedHeaders,
+		CORSExposed:     cfg.Server.HTTP.CORS.ExposedHeaders,
+		CORSCredentials: cfg.Server.HTTP.CORS.AllowCredentials,
+		CORSMaxAge:      cfg.Server.HTTP.CORS.MaxAge,
+		MaxRequestSize:  cfg.Security.RequestSizeLimitBytes(),
+		TrustedProxies:  cfg.Security.TrustedProxyPrefixes(),
+		RateLimiter:     newRateLimiter(cfg, redisClient),
+	}
+	if cfg.Server.HTTP.TLS.Enabled {
+		tlsConfig, err := cfg.Server.HTTP.TLS.Build()
+		if err != nil {
+			log.Fatalf("Invalid TLS configuration: %v", err)
+		}
+		httpConfig.TLSConfig = tlsConfig
+		httpConfig.TLSCertFile = cfg.Server.HTTP.TLS.CertFile
+		httpConfig.TLSKeyFile = cfg.Server.HTTP.TLS.KeyFile
+	}
+	if validator := newTokenValidator(cfg); validator != nil {
+		httpConfig.TokenValidator = validator
+		log.Printf("JWT validation enabled (JWKS: %s)", cfg.Security.JWT.JWKSURL)
+	}
+	basicAuth, err := newBasicAuthenticator(cfg)
+	if err != nil {
+		log.Fatalf("Invalid basic auth configuration: %v", err)
+	}
+	if basicAuth != nil {
+		httpConfig.BasicAuth = basicAuth
+		httpConfig.BasicAuthRealm = cfg.Security.BasicAuth.RealmOrDefault()
+		log.Printf("Basic auth enabled for %d users", len(cfg.Security.BasicAuth.Users))
+	}
+
+	sessionStore, err := newSessionStore(cfg, redisClient)
+	if err != nil {
+		log.Fatalf("Failed to open session store: %v", err)
+	}
+	if sessionStore != nil {
+		httpConfig.SessionStore = sessionStore
+		log.Printf("Keeping sessions in the %s store", cfg.Server.HTTP.Sessions.Store)
+	}
+
+	if cfg.Server.HTTP.Docs.Enabled {
+		httpConfig.Docs = newDocsHandler(cfg.Server.HTTP.Docs, server, reload)
+		log.Printf("Serving API documentation on /docs")
 	}
 
 	// Create MCP-compliant streamable HTTP transport
 	httpTransport := mcp.NewStreamableHTTPTransport(server, httpConfig)
+	reload.setTransport(httpTransport, redisClient)
+	go reload.watch()
 
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -196,42 +654,108 @@ func startHTTPServerWithConfig(server *mcp.Server, cfg *config.Config) {
 	// Wait for shutdown signal
 	select {
 	case <-c:
-		log.Println("Received shutdown signal...")
+		log.Println("Received shutdown signal, draining...")
 	case <-ctx.Done():
 		log.Println("Server context cancelled...")
 	}
 
-	// Create a timeout context for shutdown
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Fail readiness and refuse new sessions, giving load balancers the configured delay to
+	// stop routing here while existing sessions are still served
+	shutdown := cfg.Server.Shutdown
+	httpTransport.Drain()
+	if shutdown.Delay > 0 {
+		log.Printf("Waiting %s for load balancers to stop routing new clients", shutdown.Delay)
+		time.Sleep(shutdown.Delay)
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdown.TimeoutOrDefault())
 	defer shutdownCancel()
+	stopCtx := shutdownCtx
+	if !waitForToolCalls(shutdownCtx, server) {
+		var stopCancel context.CancelFunc
+		stopCtx, stopCancel = context.WithTimeout(context.Background(), abortGracePeriod)
+		defer stopCancel()
+	}
 
 	// Graceful shutdown
-	if err := httpTransport.Stop(shutdownCtx); err != nil {
+	if err := httpTransport.Stop(stopCtx); err != nil {
 		log.Printf("Error during shutdown: %v", err)
 	} else {
 		log.Println("Server shut down gracefully")
 	}
 }
 
-func registerAPITools(server *mcp.Server, apiTools []types.APITool, apiHandler *handlers.APIHandler) {
+// abortGracePeriod is how long tool calls aborted on shutdown have to send their error
+// before connections are closed
+const abortGracePeriod = time.Second
+
+// waitForToolCalls refuses new tool calls and waits for those in progress to finish, until
+// ctx ends. Calls still in progress then are aborted, answering them with a shutdown error,
+// and false is returned.
+func waitForToolCalls(ctx context.Context, server *mcp.Server) bool {
+	server.StopCalls()
+	if calls := server.InFlightCalls(); calls > 0 {
+		log.Printf("Waiting for %d tool calls in progress", calls)
+	}
+	if err := server.WaitForCalls(ctx); err != nil {
+		log.Printf("WARNING: Aborting %d tool calls still in progress: %v", server.InFlightCalls(), err)
+		server.AbortCalls()
+		return false
+	}
+	return true
+}
+
+// stopStdioOnSignal exits once the request being handled on SIGTERM or SIGINT has been
+// answered, aborting it with an error when the shutdown timeout passes
+func stopStdioOnSignal(transport *mcp.StdioTransport, server *mcp.Server, shutdown config.ShutdownConfig) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	<-c
+	log.Println("Received shutdown signal, finishing the current request...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdown.TimeoutOrDefault())
+	if !waitForToolCalls(ctx, server) {
+		cancel()
+		ctx, cancel = context.WithTimeout(context.Background(), abortGracePeriod)
+	}
+	if err := transport.Stop(ctx); err != nil {
+		log.Printf("WARNING: Shutting down with a request still in progress: %v", err)
+	}
+	cancel()
+	os.Exit(0)
+}
+
+// registerAPITools registers the generated tools of one API, recording their handlers in registered
+func registerAPITools(server *mcp.Server, apiTools []types.APITool, apiHandler *handlers.APIHandler, size config.ToolSizeConfig, registered map[string]mcp.ToolHandler) {
 	for _, tool := range apiTools {
 		// Create tool handler
 		handler := func(tool types.APITool) func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			var limiter ratelimit.Limiter
+			if tool.RateLimit > 0 {
+				limiter = ratelimit.NewMemoryLimiter(tool.RateLimit)
+			}
 			return func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+				if limiter != nil && !limiter.Allow(tool.Name) {
+					return nil, fmt.Errorf("%w for tool %s", types.ErrRateLimited, tool.Name)
+				}
 				return apiHandler.HandleAPICall(tool, params, requestContext)
 			}
 		}(tool)
 
-		// Generate input schema from OpenAPI parameters
-		inputSchema := generateInputSchema(tool)
+		// Generate input schema from OpenAPI parameters when it is first listed
+		tool := tool
+		inputSchema := func() map[string]interface{} {
+			return checkSchemaSize(tool.Name, capSchema(generateInputSchema(tool), size), size)
+		}
 
 		// Register tool
-		server.RegisterTool(
+		server.RegisterLazyTool(
 			tool.Name,
 			tool.Description,
 			inputSchema,
 			handler,
 		)
+		registered[tool.Name] = handler
 
 		log.Printf("Registered tool: %s (%s %s)", tool.Name, tool.Method, tool.Path)
 	}
@@ -241,80 +765,37 @@ func generateInputSchema(tool types.APITool) map[string]interface{} {
 	properties := make(map[string]interface{})
 	required := []string{}
 
-	// Add parameters
+	// Add parameters, with their location so arguments are routed unambiguously
 	for _, param := range tool.Parameters {
-		// Add parameter location as a property
-		properties[param.Name] = map[string]interface{}{
-			"type":        getParameterType(param),
-			"description": param.Description + " (in " + param.In + ")",
+		property := map[string]interface{}{
+			"type":                getParameterType(param),
+			"description":         parameterDescription(param),
+			types.LocationKeyword: param.In,
+		}
+		name := tool.ArgumentName(param)
+		if name != param.Name {
+			property[types.ParameterNameKeyword] = param.Name
 		}
+		properties[name] = property
 
 		if param.Required {
-			required = append(required, param.Name)
+			required = append(required, name)
 		}
 	}
 
-	// Add request body if present
+	// Add the request body as one argument, whichever way the specification describes it
 	if tool.RequestBody != nil {
-		// Use the actual request body schema from OpenAPI spec
-		if tool.RequestBody.Content != nil {
-			if jsonContent, exists := tool.RequestBody.Content["application/json"]; exists {
-				// Check if this is a resolved schema (from our new schema resolution)
-				if contentMap, ok := jsonContent.(map[string]interface{}); ok {
-					if schema, hasSchema := contentMap["schema"]; hasSchema {
-						// Use the resolved schema
-						properties["body"] = schema
-					} else {
-						// Fallback to the content itself
-						properties["body"] = jsonContent
-					}
-				} else {
-					// Fallback to the content itself
-					properties["body"] = jsonContent
-				}
-			} else {
-				// Fallback to generic object if no JSON content type found
-				properties["body"] = map[string]interface{}{
-					"type":        "object",
-					"description": "Request body data",
-				}
-			}
-		} else {
-			// Fallback to generic object if no content defined
-			properties["body"] = map[string]interface{}{
-				"type":        "object",
-				"description": "Request body data",
-			}
+		body := make(map[string]interface{})
+		for key, value := range tool.RequestBody.Schema() {
+			body[key] = value
 		}
-
-		// Add body to required fields if the request body is required
-		if tool.RequestBody.Required {
-			required = append(required, "body")
+		if strings.HasPrefix(tool.RequestBody.MediaType(), "multipart/form-data") {
+			body["properties"] = uploadProperties(body["properties"])
 		}
-	}
-
-	// Handle Swagger 2.0 body parameters (parameters with in: "body")
-	// These should be treated as request body parameters
-	for _, param := range tool.Parameters {
-		if param.In == "body" {
-			// This is a body parameter from Swagger 2.0, use the parameter name
-			paramSchema := map[string]interface{}{
-				"type":        "object",
-				"description": param.Description,
-			}
-
-			// Try to use the actual schema if available
-			if param.Schema != nil {
-				if schemaMap, ok := param.Schema.(map[string]interface{}); ok {
-					paramSchema = schemaMap
-				}
-			}
-
-			properties[param.Name] = paramSchema
-
-			if param.Required {
-				required = append(required, param.Name)
-			}
+		body[types.LocationKeyword] = "body"
+		properties[types.BodyArgument] = body
+		if tool.RequestBody.Required {
+			required = append(required, types.BodyArgument)
 		}
 	}
 
@@ -327,6 +808,33 @@ func generateInputSchema(tool types.APITool) map[string]interface{} {
 	return finalSchema
 }
 
+// uploadProperties replaces the file properties of a multipart body, and arrays of them, with
+// file arguments taking base64 content or a local path
+func uploadProperties(properties interface{}) interface{} {
+	fields, ok := properties.(map[string]interface{})
+	if !ok {
+		return properties
+	}
+	result := make(map[string]interface{}, len(fields))
+	for name, property := range fields {
+		schema, _ := property.(map[string]interface{})
+		items, _ := schema["items"].(map[string]interface{})
+		switch {
+		case types.IsFileSchema(schema):
+			file := types.FileArgumentSchema()
+			if description, ok := schema["description"].(string); ok && description != "" {
+				file["description"] = description
+			}
+			result[name] = file
+		case schema["type"] == "array" && types.IsFileSchema(items):
+			result[name] = map[string]interface{}{"type": "array", "items": types.FileArgumentSchema()}
+		default:
+			result[name] = property
+		}
+	}
+	return result
+}
+
 func getParameterType(param types.OpenAPIParameter) string {
 	// Default to string type
 	paramType := "string"
@@ -361,17 +869,19 @@ func extractBaseURLFromSpec(specPath string) string {
 
 	// Reconstruct the base URL with scheme and host
 	// Only include port if it's not the default port
+	baseURL := fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Hostname())
 	if parsedURL.Port() != "" {
 		// Check if it's a non-default port
 		if (parsedURL.Scheme == "http" && parsedURL.Port() != "80") ||
 			(parsedURL.Scheme == "https" && parsedURL.Port() != "443") {
-			baseURL := fmt.Sprintf("%s://%s:%s", parsedURL.Scheme, parsedURL.Hostname(), parsedURL.Port())
-			return baseURL
+			baseURL = fmt.Sprintf("%s://%s:%s", parsedURL.Scheme, parsedURL.Hostname(), parsedURL.Port())
 		}
 	}
 
-	// Use hostname without port for default ports
-	baseURL := fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Hostname())
+	// OData services serve their $metadata at the service root
+	if strings.HasSuffix(parsedURL.Path, "/$metadata") {
+		baseURL += strings.TrimSuffix(parsedURL.Path, "/$metadata")
+	}
 
 	return baseURL
 }
@@ -5,11 +5,21 @@ SPDX-License-Identifier: Apache-2.0
 package main
 
 import (
+	"encoding/json"
 	"flag"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"mcpify/internal/config"
+	"mcpify/internal/types"
+	"mcpify/pkg/mcp"
+
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
 func TestExtractBaseURLFromSpec(t *testing.T) {
@@ -48,6 +58,11 @@ func TestExtractBaseURLFromSpec(t *testing.T) {
 			specPath: "https://api.example.com:8443/swagger",
 			expected: "https://api.example.com:8443",
 		},
+		{
+			name:     "OData metadata",
+			specPath: "https://erp.example.com:8443/odata/v4/sales/$metadata",
+			expected: "https://erp.example.com:8443/odata/v4/sales",
+		},
 		{
 			name:     "complex path",
 			specPath: "https://petstore3.swagger.io/api/v3/openapi.json",
@@ -488,3 +503,398 @@ func TestConfigurationSummary(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildTools_MultipleAPIs(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.OpenAPI.SpecPath = ""
+	cfg.APIs = []config.OpenAPIConfig{
+		{SpecPath: specPath, BaseURL: "http://127.0.0.1:1", ToolPrefix: "first", Timeout: cfg.OpenAPI.Timeout},
+		{SpecPath: specPath, BaseURL: "http://127.0.0.1:2", ToolPrefix: "second", Timeout: cfg.OpenAPI.Timeout},
+	}
+
+	server := mcp.NewServer()
+	count, err := buildTools(server, cfg, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 tools, got %d", count)
+	}
+	names := listToolNames(t, server)
+	expected := []string{"first_get_orders", "first_get_users", "second_get_orders", "second_get_users"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected tools %v, got %v", expected, names)
+	}
+
+	// Tools with the same name from different APIs are rejected
+	cfg.APIs[1].ToolPrefix = "first"
+	if _, err := buildTools(mcp.NewServer(), cfg, nil); err == nil || !strings.Contains(err.Error(), "duplicate tool name") {
+		t.Errorf("Expected duplicate tool name error, got %v", err)
+	}
+}
+
+func TestBuildTools_CompositeTools(t *testing.T) {
+	var paths []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/users" {
+			_, _ = w.Write([]byte(`[{"id": 7, "name": "ada"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"order": 1}]`))
+	}))
+	defer upstream.Close()
+
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.OpenAPI.SpecPath = specPath
+	cfg.OpenAPI.BaseURL = upstream.URL
+	cfg.Composites = map[string]config.CompositeTool{
+		"user_orders": {
+			Description: "Orders of the first user",
+			Arguments:   []config.CompositeArgument{{Name: "name", Required: true}},
+			Steps: []config.CompositeStep{
+				{Name: "users", Tool: "get_users"},
+				{Name: "orders", Tool: "get_orders", Arguments: map[string]string{"user_id": "steps.users.body[0].id"}},
+			},
+			Output: "steps.orders.body",
+		},
+	}
+
+	server := mcp.NewServer()
+	count, err := buildTools(server, cfg, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 tools, got %d", count)
+	}
+
+	call := types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: []byte(`{"name":"user_orders","arguments":{"name":"ada"}}`)}
+	response := server.HandleRequest(call, config.RequestContext{})
+	if response.Error != nil {
+		t.Fatalf("Expected composite call to succeed, got %+v", response.Error)
+	}
+	data, _ := json.Marshal(response.Result)
+	if !strings.Contains(string(data), `[{\"order\":1}]`) {
+		t.Errorf("Expected the orders as result, got %s", data)
+	}
+	if strings.Join(paths, ",") != "/users,/orders" {
+		t.Errorf("Expected the steps to call /users then /orders, got %v", paths)
+	}
+
+	// Steps must call generated tools
+	cfg.Composites["user_orders"].Steps[1].Tool = "get_invoices"
+	if _, err := buildTools(mcp.NewServer(), cfg, nil); err == nil || !strings.Contains(err.Error(), "unknown tool get_invoices") {
+		t.Errorf("Expected unknown tool error, got %v", err)
+	}
+}
+
+func TestBuildTools_Aliases(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.OpenAPI.SpecPath = specPath
+	cfg.OpenAPI.BaseURL = "http://localhost"
+	cfg.Aliases = map[string]config.ToolAlias{
+		"get_users":  {Name: "list_users"},
+		"get_orders": {Name: "list_orders", KeepOriginal: true},
+		"get_gone":   {Name: "gone"},
+	}
+
+	server := mcp.NewServer()
+	count, err := buildTools(server, cfg, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 tools, got %d", count)
+	}
+	var names []string
+	for _, tool := range server.Tools() {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "get_orders,list_orders,list_users" {
+		t.Errorf("Expected the aliased tools, got %v", names)
+	}
+
+	// An alias must not take the name of another tool
+	cfg.Aliases = map[string]config.ToolAlias{"get_users": {Name: "get_orders"}}
+	if _, err := buildTools(mcp.NewServer(), cfg, nil); err == nil || !strings.Contains(err.Error(), `alias "get_orders"`) {
+		t.Errorf("Expected alias collision error, got %v", err)
+	}
+}
+
+func TestBuildTools_LocalizedDescriptions(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	descriptionsPath := filepath.Join(dir, "descriptions.yaml")
+	descriptions := "fr:\n  list_users: \"Liste les utilisateurs\"\nde:\n  list_users: \"Listet die Benutzer auf\"\n"
+	if err := os.WriteFile(descriptionsPath, []byte(descriptions), 0o600); err != nil {
+		t.Fatalf("Failed to write descriptions: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.OpenAPI.SpecPath = specPath
+	cfg.OpenAPI.BaseURL = "http://localhost"
+	cfg.Aliases = map[string]config.ToolAlias{"get_users": {Name: "list_users"}}
+	cfg.Tools = map[string]config.ToolOverride{"get_users": {Description: "List users"}}
+
+	tests := []struct {
+		locale, mode, expected string
+	}{
+		{"fr-CA", "", "Liste les utilisateurs"},
+		{"de", config.DescriptionsAppend, "List users\n\nListet die Benutzer auf"},
+		{"es", "", "List users"},
+	}
+	for _, tt := range tests {
+		cfg.Descriptions = config.DescriptionsConfig{File: descriptionsPath, Locale: tt.locale, Mode: tt.mode}
+		server := mcp.NewServer()
+		if _, err := buildTools(server, cfg, nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, tool := range server.Tools() {
+			if tool.Name == "list_users" && tool.Description != tt.expected {
+				t.Errorf("%s: expected description %q, got %q", tt.locale, tt.expected, tool.Description)
+			}
+		}
+	}
+}
+
+func TestApplyDeprecations(t *testing.T) {
+	cfg := config.Default()
+	cfg.Aliases = map[string]config.ToolAlias{"get_v2_users": {Name: "list_users"}}
+	tools := applyDeprecations(cfg, []types.APITool{
+		{Name: "get_v1_users", Description: "List users", Deprecated: true, DeprecatedBy: "get_v2_users"},
+		{Name: "post_v1_users", Description: "Create a user", Deprecated: true, DeprecationNote: "Use POST /v2/users instead."},
+		{Name: "delete_v1_users", Description: "Delete a user", Deprecated: true},
+		{Name: "get_v2_users", Description: "List users"},
+	})
+
+	expected := []string{
+		"DEPRECATED: use list_users instead. List users",
+		"DEPRECATED: Use POST /v2/users instead. Create a user",
+		"DEPRECATED. Delete a user",
+		"List users",
+	}
+	for i, tool := range tools {
+		if tool.Description != expected[i] {
+			t.Errorf("%s: expected %q, got %q", tool.Name, expected[i], tool.Description)
+		}
+	}
+}
+
+func TestBuildTools_ToolOverrides(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer upstream.Close()
+
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	disabled := false
+	cfg := config.Default()
+	cfg.OpenAPI.SpecPath = specPath
+	cfg.OpenAPI.BaseURL = upstream.URL
+	cfg.Tools = map[string]config.ToolOverride{
+		"/orders":   {Enabled: &disabled},
+		"get_users": {Description: "List all users", RateLimit: 1},
+	}
+
+	server := mcp.NewServer()
+	if _, err := buildTools(server, cfg, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"}, config.RequestContext{})
+	data, _ := json.Marshal(response.Result)
+	var result types.ListToolsResult
+	if err := json.Unmarshal(data, &result); err != nil || len(result.Tools) != 1 {
+		t.Fatalf("Expected only get_users to be registered, got %+v", response.Result)
+	}
+	if result.Tools[0].Description != "List all users" {
+		t.Errorf("Expected overridden description, got %q", result.Tools[0].Description)
+	}
+
+	call := types.MCPRequest{JSONRPC: "2.0", ID: 2, Method: "tools/call", Params: []byte(`{"name":"get_users","arguments":{}}`)}
+	if response := server.HandleRequest(call, config.RequestContext{}); response.Error != nil {
+		t.Fatalf("Expected first call to succeed, got %+v", response.Error)
+	}
+	response = server.HandleRequest(call, config.RequestContext{})
+	if response.Error == nil || response.Error.Message != "Rate limit exceeded" {
+		t.Errorf("Expected rate limit error on second call, got %+v", response.Error)
+	}
+}
+
+func TestGenerateInputSchema_Body(t *testing.T) {
+	userSchema := map[string]interface{}{"type": "object", "properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}}}
+	tool := types.APITool{
+		Name:   "create_user",
+		Method: "POST",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "org", In: "path", Required: true},
+		},
+		RequestBody: &types.OpenAPIRequestBody{
+			Required: true,
+			Content: map[string]interface{}{
+				"application/xml":                 map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+				"application/json; charset=utf-8": map[string]interface{}{"schema": userSchema},
+			},
+		},
+	}
+
+	schema := generateInputSchema(tool)
+	properties := schema["properties"].(map[string]interface{})
+	body, _ := json.Marshal(properties["body"])
+	expected, _ := json.Marshal(map[string]interface{}{
+		"type": "object", "properties": userSchema["properties"], "x-mcpify-in": "body",
+	})
+	if string(body) != string(expected) {
+		t.Errorf("Expected the JSON media type's schema as body, got %s", body)
+	}
+	if required := schema["required"].([]string); len(required) != 2 || required[1] != "body" {
+		t.Errorf("Expected org and body to be required, got %v", required)
+	}
+
+	tool.RequestBody = &types.OpenAPIRequestBody{Content: map[string]interface{}{"text/plain": map[string]interface{}{}}}
+	properties = generateInputSchema(tool)["properties"].(map[string]interface{})
+	if body := properties["body"].(map[string]interface{}); body["type"] != "object" {
+		t.Errorf("Expected the generic body schema without a media type schema, got %v", body)
+	}
+}
+
+func TestGenerateInputSchema_ConstraintHints(t *testing.T) {
+	tool := types.APITool{
+		Name:   "list_orders",
+		Method: "GET",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "status", In: "query", Description: "Order status", Schema: &openapi3.Schema{
+				Type:    &openapi3.Types{"string"},
+				Enum:    []interface{}{"open", "closed"},
+				Default: "open",
+			}},
+			{Name: "limit", In: "query", Description: "Page size", Schema: openapi3.NewIntegerSchema().WithMin(1).WithMax(100).WithDefault(20)},
+			{Name: "price", In: "query", Schema: map[string]interface{}{"type": "number", "minimum": 0, "exclusiveMinimum": true}},
+			{Name: "since", In: "query", Schema: openapi3.NewDateTimeSchema()},
+			{Name: "tags", In: "query", Schema: map[string]interface{}{
+				"type": "array", "maxItems": 3, "items": map[string]interface{}{"enum": []interface{}{"a", "b"}},
+			}},
+			{Name: "id", In: "path", Description: "Order ID", Required: true},
+		},
+	}
+
+	properties := generateInputSchema(tool)["properties"].(map[string]interface{})
+	expected := map[string]string{
+		"status": `Order status (in query; one of: "open", "closed"; default: "open")`,
+		"limit":  `Page size (in query; minimum 1; maximum 100; default: 20)`,
+		"price":  ` (in query; greater than 0)`,
+		"since":  ` (in query; format: date-time)`,
+		"tags":   ` (in query; items one of: "a", "b"; at most 3 items)`,
+		"id":     `Order ID (in path)`,
+	}
+	for name, description := range expected {
+		property := properties[name].(map[string]interface{})
+		if property["description"] != description {
+			t.Errorf("Unexpected description of %s: %q, expected %q", name, property["description"], description)
+		}
+	}
+}
+
+func TestGenerateInputSchema_Locations(t *testing.T) {
+	tool := types.APITool{
+		Name:   "update_item",
+		Method: "PUT",
+		Path:   "/items/{id}",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "id", In: "path", Required: true},
+			{Name: "id", In: "query"},
+			{Name: "body", In: "header"},
+			{Name: "verbose", In: "query"},
+		},
+		RequestBody: &types.OpenAPIRequestBody{Required: true},
+	}
+
+	schema := generateInputSchema(tool)
+	properties := schema["properties"].(map[string]interface{})
+	expected := map[string][2]string{
+		"path_id":     {"path", "id"},
+		"query_id":    {"query", "id"},
+		"header_body": {"header", "body"},
+		"verbose":     {"query", ""},
+		"body":        {"body", ""},
+	}
+	if len(properties) != len(expected) {
+		t.Fatalf("Expected %d arguments, got %v", len(expected), properties)
+	}
+	for name, location := range expected {
+		property, ok := properties[name].(map[string]interface{})
+		if !ok {
+			t.Fatalf("Expected argument %s, got %v", name, properties)
+		}
+		if property["x-mcpify-in"] != location[0] {
+			t.Errorf("Expected %s to be sent in %s, got %v", name, location[0], property["x-mcpify-in"])
+		}
+		if parameter, _ := property["x-mcpify-name"].(string); parameter != location[1] {
+			t.Errorf("Expected %s to set parameter %q, got %q", name, location[1], parameter)
+		}
+	}
+	if required := schema["required"].([]string); strings.Join(required, ",") != "path_id,body" {
+		t.Errorf("Expected path_id and body to be required, got %v", required)
+	}
+}
+
+func TestGenerateInputSchema_Multipart(t *testing.T) {
+	tool := types.APITool{
+		Name:   "upload_document",
+		Method: "POST",
+		RequestBody: &types.OpenAPIRequestBody{Content: map[string]interface{}{
+			"multipart/form-data": map[string]interface{}{"schema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"file":        map[string]interface{}{"type": "string", "format": "binary", "description": "The document"},
+					"attachments": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string", "format": "binary"}},
+					"title":       map[string]interface{}{"type": "string"},
+				},
+			}},
+		}},
+	}
+
+	body := generateInputSchema(tool)["properties"].(map[string]interface{})["body"].(map[string]interface{})
+	properties := body["properties"].(map[string]interface{})
+	file := properties["file"].(map[string]interface{})
+	if file["type"] != "object" || file["description"] != "The document" || file["properties"].(map[string]interface{})["content"] == nil {
+		t.Errorf("Expected file to take base64 content, got %v", file)
+	}
+	attachments := properties["attachments"].(map[string]interface{})
+	if items := attachments["items"].(map[string]interface{}); items["type"] != "object" {
+		t.Errorf("Expected attachments to take files, got %v", attachments)
+	}
+	if title := properties["title"].(map[string]interface{}); title["type"] != "string" {
+		t.Errorf("Expected title to stay a string, got %v", title)
+	}
+
+	original := tool.RequestBody.Schema()["properties"].(map[string]interface{})["file"].(map[string]interface{})
+	if original["type"] != "string" {
+		t.Errorf("Expected the spec's schema to be left unchanged, got %v", original)
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+	"mcpify/pkg/mcp"
+)
+
+// Names of the built-in meta tools
+const (
+	listEndpointsToolName = "list_endpoints"
+	getToolSchemaToolName = "get_tool_schema"
+)
+
+// compositeGroup groups composite tools, which have no tag or path of their own
+const compositeGroup = "composite"
+
+// maxSummaryLength caps the one-line descriptions of list_endpoints, in characters
+const maxSummaryLength = 120
+
+// endpointEntry describes one tool in the list_endpoints result
+type endpointEntry struct {
+	Name        string `json:"name"`
+	Method      string `json:"method,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Description string `json:"description,omitempty"`
+	group       string
+	text        string // Full description, searched by search_tools
+}
+
+// endpointGroup is a group of the list_endpoints result
+type endpointGroup struct {
+	Name  string          `json:"name"`
+	Tools []endpointEntry `json:"tools"`
+}
+
+// toolSchemaResult is the get_tool_schema result: the tool as the manifest describes it, with
+// example arguments from the specification
+type toolSchemaResult struct {
+	manifestTool
+	Examples map[string]interface{} `json:"examples,omitempty"`
+}
+
+// registerMetaTools registers the enabled meta tools, built from the generated and composite
+// tools, and returns how many were registered
+func registerMetaTools(server *mcp.Server, cfg *config.Config, generated []apiTools, registered map[string]mcp.ToolHandler) (int, error) {
+	count := 0
+	if cfg.MetaTools.ListEndpoints {
+		if err := checkMetaToolName(cfg, listEndpointsToolName, registered); err != nil {
+			return 0, err
+		}
+		registerListEndpoints(server, endpointEntries(cfg, generated))
+		count++
+	}
+	if cfg.MetaTools.GetToolSchema {
+		if err := checkMetaToolName(cfg, getToolSchemaToolName, registered); err != nil {
+			return 0, err
+		}
+		registerGetToolSchema(server, generated)
+		count++
+	}
+	if cfg.MetaTools.HealthCheck.Enabled {
+		if err := checkMetaToolName(cfg, healthCheckToolName, registered); err != nil {
+			return 0, err
+		}
+		registerHealthCheck(server, cfg.MetaTools.HealthCheck, generated)
+		count++
+	}
+	if cfg.MetaTools.SearchTools {
+		if err := checkMetaToolName(cfg, searchToolsToolName, registered); err != nil {
+			return 0, err
+		}
+		registerSearchTools(server, endpointEntries(cfg, generated))
+		count++
+	}
+	return count, nil
+}
+
+// checkMetaToolName refuses a meta tool whose name a generated or composite tool already has
+func checkMetaToolName(cfg *config.Config, name string, registered map[string]mcp.ToolHandler) error {
+	if _, exists := registered[name]; exists {
+		return fmt.Errorf("meta tool %s has the name of a generated tool", name)
+	}
+	if _, exists := cfg.Composites[name]; exists {
+		return fmt.Errorf("meta tool %s has the name of a composite tool", name)
+	}
+	return nil
+}
+
+// endpointEntries returns the list_endpoints entries of the generated and composite tools,
+// sorted by group and name
+func endpointEntries(cfg *config.Config, generated []apiTools) []endpointEntry {
+	var entries []endpointEntry
+	for _, api := range generated {
+		for _, tool := range api.tools {
+			group := pathGroup(tool.Path)
+			if len(tool.Tags) > 0 {
+				group = tool.Tags[0]
+			}
+			entries = append(entries, endpointEntry{
+				Name:        tool.Name,
+				Method:      strings.ToUpper(tool.Method),
+				Path:        tool.Path,
+				Description: summaryLine(tool.Description),
+				group:       group,
+				text:        tool.Description,
+			})
+		}
+	}
+	for name, composite := range cfg.Composites {
+		entries = append(entries, endpointEntry{
+			Name:        name,
+			Description: summaryLine(composite.Description),
+			group:       compositeGroup,
+			text:        composite.Description,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].group != entries[j].group {
+			return entries[i].group < entries[j].group
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// registerListEndpoints registers the list_endpoints tool over entries
+func registerListEndpoints(server *mcp.Server, entries []endpointEntry) {
+	server.RegisterTool(
+		listEndpointsToolName,
+		"Lists the available tools grouped by tag or path, with the HTTP method, path and a one-line description of each. Call it first to find the tools for a task; pass group to list one group only.",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"group": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the only group to list, as returned by a previous call",
+				},
+			},
+		},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			only, _ := params["group"].(string)
+			var groups []endpointGroup
+			total := 0
+			for _, entry := range entries {
+				if only != "" && !strings.EqualFold(entry.group, only) {
+					continue
+				}
+				if len(groups) == 0 || groups[len(groups)-1].Name != entry.group {
+					groups = append(groups, endpointGroup{Name: entry.group})
+				}
+				groups[len(groups)-1].Tools = append(groups[len(groups)-1].Tools, entry)
+				total++
+			}
+			if only != "" && total == 0 {
+				return nil, fmt.Errorf("no tools in group %q", only)
+			}
+			return map[string]interface{}{"total": total, "groups": groups}, nil
+		},
+	)
+}
+
+// registerGetToolSchema registers the get_tool_schema tool, describing the tools of server and
+// the operations of the generated tools
+func registerGetToolSchema(server *mcp.Server, generated []apiTools) {
+	operations := make(map[string]types.APITool)
+	for _, api := range generated {
+		for _, tool := range api.tools {
+			operations[tool.Name] = tool
+		}
+	}
+
+	server.RegisterTool(
+		getToolSchemaToolName,
+		"Returns the full input and output schema of a tool, its HTTP method and path and example arguments from the API specification. Call it before using a tool whose arguments are not clear from its listing.",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the tool to describe",
+				},
+			},
+			"required": []string{"name"},
+		},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			name, _ := params["name"].(string)
+			if name == "" {
+				return nil, types.ArgumentErrorf("name is required")
+			}
+			schema, exists := server.Tool(name)
+			if !exists {
+				return nil, types.ArgumentErrorf("unknown tool %q", name)
+			}
+			operation, exists := operations[name]
+			if !exists {
+				return toolSchemaResult{manifestTool: newManifestTool(schema, nil)}, nil
+			}
+			return toolSchemaResult{
+				manifestTool: newManifestTool(schema, &operation),
+				Examples:     toolExamples(operation),
+			}, nil
+		},
+	)
+}
+
+// toolExamples returns the examples the specification gives for a tool's arguments, by
+// argument name, or nil when it gives none
+func toolExamples(tool types.APITool) map[string]interface{} {
+	var examples map[string]interface{}
+	add := func(name string, example interface{}) {
+		if examples == nil {
+			examples = make(map[string]interface{})
+		}
+		examples[name] = example
+	}
+	for _, param := range tool.Parameters {
+		if param.Example != nil {
+			add(tool.ArgumentName(param), param.Example)
+		}
+	}
+	if tool.RequestBody != nil {
+		if example := tool.RequestBody.Example(); example != nil {
+			add(types.BodyArgument, example)
+		}
+	}
+	return examples
+}
+
+// pathGroup groups a tool without tags by the first segment of its path, e.g. /users for
+// /users/{id}/orders
+func pathGroup(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return "/" + segment
+}
+
+// summaryLine returns the first sentence of a description's first line, shortened to
+// maxSummaryLength characters
+func summaryLine(description string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(description), "\n")
+	if end := strings.Index(line, ". "); end >= 0 {
+		line = line[:end+1]
+	}
+	line = strings.TrimSpace(line)
+	if utf8.RuneCountInString(line) <= maxSummaryLength {
+		return line
+	}
+	runes := []rune(line)
+	return strings.TrimSpace(string(runes[:maxSummaryLength-1])) + "…"
+}
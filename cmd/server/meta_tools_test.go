@@ -0,0 +1,158 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mcpify/internal/config"
+	"mcpify/pkg/mcp"
+)
+
+const metaToolsTestSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Meta", "version": "1.0.0"},
+  "paths": {
+    "/users": {
+      "get": {"operationId": "listUsers", "tags": ["Users"], "summary": "List users. Supports paging.", "responses": {"200": {"description": "ok"}}},
+      "post": {"operationId": "createUser", "tags": ["Users"], "summary": "Create a user",
+        "requestBody": {"content": {"application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}, "examples": {"ada": {"value": {"name": "Ada"}}}}}},
+        "responses": {"201": {"description": "ok"}}}
+    },
+    "/orders/{id}": {
+      "get": {"operationId": "getOrder", "summary": "Get an order", "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}, "example": "o-42"}],
+        "responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"type": "object", "properties": {"total": {"type": "number"}}}}}}}}
+    }
+  }
+}`
+
+// newMetaToolsConfig returns a configuration serving metaToolsTestSpec
+func newMetaToolsConfig(t *testing.T) *config.Config {
+	t.Helper()
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(metaToolsTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	cfg := config.Default()
+	cfg.OpenAPI.SpecPath = specPath
+	cfg.OpenAPI.BaseURL = "http://127.0.0.1:1"
+	return cfg
+}
+
+// callMetaTool calls a tool and returns its result decoded from JSON
+func callMetaTool(t *testing.T, server *mcp.Server, name string, params map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	result, err := server.CallTool(name, params, config.RequestContext{})
+	if err != nil {
+		t.Fatalf("Failed to call %s: %v", name, err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Failed to encode result: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	return decoded
+}
+
+func TestListEndpoints(t *testing.T) {
+	cfg := newMetaToolsConfig(t)
+	cfg.MetaTools.ListEndpoints = true
+
+	server := mcp.NewServer()
+	count, err := buildTools(server, cfg, nil)
+	if err != nil {
+		t.Fatalf("Failed to build tools: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected 3 generated tools and list_endpoints, got %d", count)
+	}
+
+	result := callMetaTool(t, server, listEndpointsToolName, nil)
+	data, _ := json.Marshal(result)
+	expected := `{"groups":[` +
+		`{"name":"/orders","tools":[{"description":"Get an order","method":"GET","name":"get_orders_by_id","path":"/orders/{id}"}]},` +
+		`{"name":"Users","tools":[{"description":"List users.","method":"GET","name":"get_users","path":"/users"},` +
+		`{"description":"Create a user","method":"POST","name":"post_users","path":"/users"}]}],"total":3}`
+	if string(data) != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, data)
+	}
+
+	result = callMetaTool(t, server, listEndpointsToolName, map[string]interface{}{"group": "users"})
+	if result["total"] != float64(2) {
+		t.Errorf("Expected the 2 tools of the users group, got %v", result)
+	}
+	if _, err := server.CallTool(listEndpointsToolName, map[string]interface{}{"group": "billing"}, config.RequestContext{}); err == nil {
+		t.Error("Expected an error for an unknown group")
+	}
+
+	// Meta tools cannot take the name of another tool
+	cfg.Composites = map[string]config.CompositeTool{listEndpointsToolName: {}}
+	if _, err := buildTools(mcp.NewServer(), cfg, nil); err == nil || !strings.Contains(err.Error(), "meta tool") {
+		t.Errorf("Expected a name conflict error, got %v", err)
+	}
+}
+
+func TestSummaryLine(t *testing.T) {
+	tests := map[string]string{
+		"Get a user":                       "Get a user",
+		"Get a user. Requires admin.":      "Get a user.",
+		"List users\nPaged with a cursor.": "List users",
+		strings.Repeat("a", 200):           strings.Repeat("a", 119) + "…",
+	}
+	for description, expected := range tests {
+		if line := summaryLine(description); line != expected {
+			t.Errorf("summaryLine(%q) = %q, expected %q", description, line, expected)
+		}
+	}
+}
+
+func TestGetToolSchema(t *testing.T) {
+	cfg := newMetaToolsConfig(t)
+	cfg.MetaTools.GetToolSchema = true
+	cfg.Composites = map[string]config.CompositeTool{
+		"order_details": {Description: "Get an order", Steps: []config.CompositeStep{{Name: "order", Tool: "get_orders_by_id"}}},
+	}
+
+	server := mcp.NewServer()
+	if _, err := buildTools(server, cfg, nil); err != nil {
+		t.Fatalf("Failed to build tools: %v", err)
+	}
+
+	result := callMetaTool(t, server, getToolSchemaToolName, map[string]interface{}{"name": "get_orders_by_id"})
+	if result["method"] != "GET" || result["path"] != "/orders/{id}" {
+		t.Errorf("Expected the operation of the tool, got %v", result)
+	}
+	if properties, _ := result["inputSchema"].(map[string]interface{})["properties"].(map[string]interface{}); properties["id"] == nil {
+		t.Errorf("Expected the input schema, got %v", result["inputSchema"])
+	}
+	if properties, _ := result["outputSchema"].(map[string]interface{})["properties"].(map[string]interface{}); properties["total"] == nil {
+		t.Errorf("Expected the output schema, got %v", result["outputSchema"])
+	}
+	if examples, _ := result["examples"].(map[string]interface{}); examples["id"] != "o-42" {
+		t.Errorf("Expected the parameter example, got %v", result["examples"])
+	}
+
+	result = callMetaTool(t, server, getToolSchemaToolName, map[string]interface{}{"name": "post_users"})
+	if examples, _ := result["examples"].(map[string]interface{}); fmt.Sprint(examples["body"]) != "map[name:Ada]" {
+		t.Errorf("Expected the body example, got %v", result["examples"])
+	}
+
+	result = callMetaTool(t, server, getToolSchemaToolName, map[string]interface{}{"name": "order_details"})
+	if result["description"] != "Get an order" || result["method"] != nil || result["inputSchema"] == nil {
+		t.Errorf("Expected the composite tool without an operation, got %v", result)
+	}
+
+	if _, err := server.CallTool(getToolSchemaToolName, map[string]interface{}{"name": "delete_users"}, config.RequestContext{}); err == nil {
+		t.Error("Expected an error for an unknown tool")
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/openapi"
+)
+
+// Registry defaults
+const (
+	registrySchema         = "https://static.modelcontextprotocol.io/schemas/2025-09-29/server.schema.json"
+	defaultRegistryURL     = "https://registry.modelcontextprotocol.io"
+	maxRegistryDescription = 100
+)
+
+// registryServer is an MCP registry server.json document
+type registryServer struct {
+	Schema      string            `json:"$schema"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Version     string            `json:"version"`
+	Packages    []registryPackage `json:"packages,omitempty"`
+	Remotes     []registryRemote  `json:"remotes,omitempty"`
+}
+
+// registryPackage is a container image clients run to start the server over stdio
+type registryPackage struct {
+	RegistryType         string              `json:"registryType"`
+	Identifier           string              `json:"identifier"`
+	Version              string              `json:"version"`
+	Transport            registryTransport   `json:"transport"`
+	EnvironmentVariables []registryInputSpec `json:"environmentVariables,omitempty"`
+}
+
+type registryTransport struct {
+	Type string `json:"type"`
+}
+
+// registryRemote is a running endpoint clients connect to
+type registryRemote struct {
+	Type    string              `json:"type"`
+	URL     string              `json:"url"`
+	Headers []registryInputSpec `json:"headers,omitempty"`
+}
+
+// registryInputSpec is an environment variable or header the client has to provide
+type registryInputSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	IsRequired  bool   `json:"isRequired,omitempty"`
+	IsSecret    bool   `json:"isSecret,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+// registryOptions are the server.json fields not found in the configuration
+type registryOptions struct {
+	name        string
+	description string
+	version     string
+	url         string
+	image       string
+}
+
+// runRegistry prints the MCP registry server.json describing this deployment, or publishes it
+func runRegistry(args []string, stdout io.Writer) error {
+	var opts options
+	var registry registryOptions
+	fs := newFlagSet("registry", "--name <namespace/name> [options]", &opts)
+	addServerFlags(fs, &opts)
+	verbose := addVerboseFlag(fs)
+	fs.StringVar(&registry.name, "name", "", "Registry name of the server, e.g. io.github.acme/users-api")
+	fs.StringVar(&registry.description, "description", "", "Description (defaults to the title of the first spec)")
+	fs.StringVar(&registry.version, "server-version", "", "Version of the server (defaults to the version of the first spec)")
+	fs.StringVar(&registry.url, "url", "", "Public URL of the /mcp endpoint, required for the HTTP transport")
+	fs.StringVar(&registry.image, "image", "", "Container image that runs this configuration, required for the stdio transport")
+	publish := fs.Bool("publish", false, "Publish to the registry instead of printing server.json")
+	registryURL := fs.String("registry", defaultRegistryURL, "Registry to publish to")
+	token := fs.String("token", os.Getenv("MCP_REGISTRY_TOKEN"), "Registry token (defaults to $MCP_REGISTRY_TOKEN)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	defer quietLogs(*verbose)()
+
+	if opts.configPath == config.StdinPath {
+		return fmt.Errorf("--config %s cannot be inspected for environment variables, pass a file instead", config.StdinPath)
+	}
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	var content []byte
+	if opts.configPath != "" {
+		if content, err = os.ReadFile(opts.configPath); err != nil {
+			return fmt.Errorf("failed to read configuration: %w", err)
+		}
+	}
+
+	server, err := buildRegistryServer(cfg, content, registry)
+	if err != nil {
+		return err
+	}
+	if !*publish {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(server)
+	}
+
+	if *token == "" {
+		return fmt.Errorf("publishing requires a registry token, set --token or $MCP_REGISTRY_TOKEN")
+	}
+	if err := publishRegistryServer(*registryURL, *token, server); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Published %s %s to %s\n", server.Name, server.Version, *registryURL)
+	return nil
+}
+
+// buildRegistryServer describes the deployment of cfg: a remote for the HTTP transport, with
+// the Authorization header when JWT validation is enabled, or a container package for stdio,
+// with the environment variables referenced by the configuration content
+func buildRegistryServer(cfg *config.Config, content []byte, opts registryOptions) (registryServer, error) {
+	if namespace, name, ok := strings.Cut(opts.name, "/"); !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return registryServer{}, fmt.Errorf("--name must be namespace/name, e.g. io.github.acme/users-api, got %q", opts.name)
+	}
+
+	description, version := opts.description, opts.version
+	if description == "" || version == "" {
+		title, specVersion := registrySpecInfo(cfg)
+		if description == "" && title != "" {
+			description = "MCP tools for " + title
+		}
+		if version == "" {
+			version = specVersion
+		}
+	}
+	if description == "" {
+		return registryServer{}, fmt.Errorf("no spec title to describe the server, set --description")
+	}
+	if version == "" {
+		return registryServer{}, fmt.Errorf("no spec version to version the server, set --server-version")
+	}
+	if runes := []rune(description); len(runes) > maxRegistryDescription {
+		description = string(runes[:maxRegistryDescription-3]) + "..."
+	}
+
+	server := registryServer{Schema: registrySchema, Name: opts.name, Description: description, Version: version}
+	if cfg.Server.Transport == "http" {
+		if opts.url == "" {
+			return registryServer{}, fmt.Errorf("--url is required for the HTTP transport, the registry lists public endpoints")
+		}
+		remote := registryRemote{Type: "streamable-http", URL: opts.url}
+		if cfg.Security.JWT.Enabled {
+			remote.Headers = []registryInputSpec{{
+				Name:        "Authorization",
+				Description: "Bearer token issued by the configured identity provider",
+				IsRequired:  true,
+				IsSecret:    true,
+			}}
+		}
+		server.Remotes = []registryRemote{remote}
+		return server, nil
+	}
+
+	if opts.image == "" {
+		return registryServer{}, fmt.Errorf("--image is required for the stdio transport, clients start the server from it")
+	}
+	image, tag, _ := strings.Cut(opts.image, ":")
+	if tag == "" {
+		tag = version
+	}
+	pkg := registryPackage{
+		RegistryType: "oci",
+		Identifier:   image,
+		Version:      tag,
+		Transport:    registryTransport{Type: "stdio"},
+	}
+	for _, ref := range config.EnvReferences(string(content)) {
+		pkg.EnvironmentVariables = append(pkg.EnvironmentVariables, registryInputSpec{
+			Name:        ref.Name,
+			Description: "Referenced as ${" + ref.Name + "} by the configuration",
+			IsRequired:  !ref.HasDefault,
+			IsSecret:    isSecretEnvName(ref.Name),
+			Default:     ref.Default,
+		})
+	}
+	server.Packages = []registryPackage{pkg}
+	return server, nil
+}
+
+// registrySpecInfo returns the title and version of the first specification that loads
+func registrySpecInfo(cfg *config.Config) (string, string) {
+	for _, api := range cfg.APIConfigs() {
+		spec, err := openapi.NewParser(api).Spec()
+		if err != nil || spec.Info == nil {
+			continue
+		}
+		return spec.Info.Title, spec.Info.Version
+	}
+	return "", ""
+}
+
+// isSecretEnvName reports whether a variable name suggests a credential
+func isSecretEnvName(name string) bool {
+	name = strings.ToUpper(name)
+	for _, marker := range []string{"TOKEN", "SECRET", "PASSWORD", "KEY", "CREDENTIAL"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// publishRegistryServer publishes server.json to the registry's publish endpoint
+func publishRegistryServer(registryURL, token string, server registryServer) error {
+	body, err := json.Marshal(server)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(registryURL, "/")+"/v0/publish", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid registry URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("registry rejected %s %s: %s: %s", server.Name, server.Version, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
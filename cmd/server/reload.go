@@ -0,0 +1,312 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"log"
+	"mcpify/internal/config"
+	"mcpify/internal/redis"
+	"mcpify/pkg/extension"
+	"mcpify/pkg/mcp"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// reloader re-reads the configuration and swaps in freshly generated tools without
+// restarting the transport, so active MCP sessions are kept across reloads
+type reloader struct {
+	opts   options
+	server *mcp.Server
+
+	// reloading serializes reloads, which load the configuration and build its tools without
+	// holding mu, so readers of the current configuration are not blocked by spec fetches
+	reloading sync.Mutex
+
+	mu         sync.Mutex
+	current    *config.Config
+	extensions extension.Extension          // Run by the current tools, nil when none are loaded
+	transport  *mcp.StreamableHTTPTransport // nil when serving over stdio
+	redis      *redis.Client                // nil when no redis server is configured
+	docs       *docsSpecs                   // nil unless /docs is served
+	status     *loadStatus                  // nil unless the specs are loaded in the background
+}
+
+// newReloader creates a reloader for a server started with cfg
+func newReloader(opts options, server *mcp.Server, cfg *config.Config) *reloader {
+	return &reloader{
+		opts:    opts,
+		server:  server,
+		current: cfg,
+	}
+}
+
+// Config returns the configuration currently applied
+func (r *reloader) Config() *config.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Transport returns the HTTP transport, or nil when serving over stdio
+func (r *reloader) Transport() *mcp.StreamableHTTPTransport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.transport
+}
+
+// setTransport records the HTTP transport whose token validator is updated on reload, and the
+// redis client its rate limiter is recreated with
+func (r *reloader) setTransport(transport *mcp.StreamableHTTPTransport, redisClient *redis.Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transport = transport
+	r.redis = redisClient
+}
+
+// setDocs records the specifications served by /docs, converted again on reload
+func (r *reloader) setDocs(docs *docsSpecs) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.docs = docs
+}
+
+// setExtensions records the extensions run by the current tools, closing the ones they replace
+func (r *reloader) setExtensions(extensions extension.Extension) {
+	r.mu.Lock()
+	previous := r.swapExtensions(extensions)
+	r.mu.Unlock()
+	closeExtensions(previous)
+}
+
+// swapExtensions replaces the extensions of the current tools and returns the previous ones,
+// which the caller closes once it no longer holds r.mu: closing waits for running calls
+func (r *reloader) swapExtensions(extensions extension.Extension) extension.Extension {
+	previous := r.extensions
+	r.extensions = extensions
+	return previous
+}
+
+// closeExtensions closes the extensions of the current tools when the server stops
+func (r *reloader) closeExtensions() {
+	r.setExtensions(nil)
+}
+
+// loadInBackground registers the status tool and builds the tools of cfg in the background,
+// serving them next to the status tool once they are ready. A reload finishing first wins.
+func (r *reloader) loadInBackground(cfg *config.Config) {
+	r.mu.Lock()
+	r.status = newLoadStatus()
+	r.mu.Unlock()
+	registerStatusTool(r.server, r.status)
+	log.Printf("Loading OpenAPI specs in the background; %s reports progress", statusToolName)
+
+	go func() {
+		staging := mcp.NewServer()
+		toolCount, extensions, err := buildServedTools(staging, cfg)
+
+		r.mu.Lock()
+		if r.current != cfg {
+			r.mu.Unlock()
+			closeExtensions(extensions)
+			return
+		}
+		if err != nil {
+			r.status.failed(err)
+			r.mu.Unlock()
+			log.Printf("ERROR: Loading OpenAPI specs failed: %v", err)
+			return
+		}
+		registerStatusTool(staging, r.status)
+		r.server.ReplaceTools(staging)
+		previous := r.swapExtensions(extensions)
+		r.status.loaded(toolCount)
+		r.mu.Unlock()
+
+		closeExtensions(previous)
+		log.Printf("Successfully parsed OpenAPI specs, generated %d tools", toolCount)
+	}()
+}
+
+// Reload loads the configuration again and applies upstream auth, headers, path filters,
+// error mappings and client token validation. On error the running configuration is left untouched.
+func (r *reloader) Reload() error {
+	// Standard input was consumed at startup
+	if r.opts.configPath == config.StdinPath {
+		return errors.New("configuration read from stdin cannot be reloaded")
+	}
+
+	r.reloading.Lock()
+	defer r.reloading.Unlock()
+
+	cfg, err := loadConfig(r.opts)
+	if err != nil {
+		return err
+	}
+
+	// Build the new tool set on a staging server so a failed parse leaves the current tools in place
+	staging := mcp.NewServer()
+	toolCount, extensions, err := buildServedTools(staging, cfg)
+	if err != nil {
+		return err
+	}
+
+	basicAuth, err := newBasicAuthenticator(cfg)
+	if err != nil {
+		closeExtensions(extensions)
+		return err
+	}
+
+	r.mu.Lock()
+	// Listener settings are bound when the transport starts
+	if !reflect.DeepEqual(r.current.Server, cfg.Server) || !reflect.DeepEqual(r.current.Redis, cfg.Redis) {
+		log.Printf("WARNING: Server settings changed; restart mcpify to apply transport, host, port, TLS, CORS, session and redis changes")
+	}
+
+	if r.status != nil {
+		registerStatusTool(staging, r.status)
+	}
+	r.server.ReplaceTools(staging)
+	previous := r.swapExtensions(extensions)
+	if r.status != nil {
+		r.status.loaded(toolCount)
+	}
+	r.server.SetErrorMappings(cfg.ErrorMappings)
+	r.server.SetMaxRequestSize(cfg.Security.RequestSizeLimitBytes())
+	if r.transport != nil {
+		r.transport.SetTokenValidator(newTokenValidator(cfg))
+		r.transport.SetBasicAuth(basicAuth, cfg.Security.BasicAuth.RealmOrDefault())
+		r.transport.SetMaxRequestSize(cfg.Security.RequestSizeLimitBytes())
+		// Client allowances are kept unless the client settings change
+		if !reflect.DeepEqual(r.current.Security.RateLimiting, cfg.Security.RateLimiting) ||
+			!reflect.DeepEqual(r.current.Security.TrustedProxies, cfg.Security.TrustedProxies) {
+			r.transport.SetClientPolicy(cfg.Security.TrustedProxyPrefixes(), newRateLimiter(cfg, r.redis))
+		}
+	}
+	r.current = cfg
+	docs := r.docs
+	r.mu.Unlock()
+
+	// The previous extensions are closed after the swap, so new calls no longer reach them
+	closeExtensions(previous)
+	if docs != nil {
+		docs.load(cfg)
+	}
+	log.Printf("Configuration reloaded, %d tools registered", toolCount)
+	return nil
+}
+
+// watch reloads the configuration each time the process receives SIGHUP
+func (r *reloader) watch() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+
+	for range c {
+		log.Println("Received SIGHUP, reloading configuration...")
+		if err := r.Reload(); err != nil {
+			log.Printf("Configuration reload failed, keeping previous configuration: %v", err)
+		}
+	}
+}
+
+// watchFiles starts reloading the configuration whenever the configuration file, a local
+// spec file or a configured watch path changes, until stop is closed. Files are compared by
+// content rather than modification time: Kubernetes updates a mounted ConfigMap or Secret by
+// swapping the ..data symlink the files point through, so every file may change at once.
+func (r *reloader) watchFiles(stop <-chan struct{}) {
+	cfg := r.Config()
+	fingerprint := watchFingerprint(watchedPaths(r.opts, cfg))
+	log.Printf("Watching configuration files for changes every %s", cfg.Server.Watch.IntervalOrDefault())
+
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(r.Config().Server.Watch.IntervalOrDefault()):
+			}
+
+			// A failed reload is not retried until the files change again
+			current := watchFingerprint(watchedPaths(r.opts, r.Config()))
+			if current == fingerprint {
+				continue
+			}
+			fingerprint = current
+			log.Println("Configuration files changed, reloading configuration...")
+			if err := r.Reload(); err != nil {
+				log.Printf("Configuration reload failed, keeping previous configuration: %v", err)
+			}
+		}
+	}()
+}
+
+// watchedPaths returns the files and directories whose changes reload cfg
+func watchedPaths(opts options, cfg *config.Config) []string {
+	var paths []string
+	if opts.configPath != "" && opts.configPath != config.StdinPath {
+		paths = append(paths, opts.configPath)
+	}
+	for _, api := range cfg.APIConfigs() {
+		if api.SpecPath != "" && !strings.Contains(api.SpecPath, "://") {
+			paths = append(paths, api.SpecPath)
+		}
+	}
+	return append(paths, cfg.Server.Watch.Paths...)
+}
+
+// watchFingerprint hashes the contents of the given files and directories. Missing files
+// hash differently from empty ones, so a file appearing or disappearing is a change.
+func watchFingerprint(paths []string) string {
+	h := sha256.New()
+	for _, path := range paths {
+		hashPath(h, path)
+	}
+	return string(h.Sum(nil))
+}
+
+// hashPath adds a file, or the files of a directory and its subdirectories, to h. Symlinks
+// are followed, and directory entries starting with ".." are skipped: in ConfigMap and
+// Secret mounts they are the timestamped copies that the visible entries point into.
+func hashPath(h hash.Hash, path string) {
+	io.WriteString(h, path+"\x00")
+	info, err := os.Stat(path)
+	if err != nil {
+		io.WriteString(h, "missing\x00")
+		return
+	}
+	if !info.IsDir() {
+		if file, err := os.Open(path); err == nil {
+			_, _ = io.Copy(h, file)
+			file.Close()
+		}
+		io.WriteString(h, "\x00")
+		return
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "..") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		hashPath(h, filepath.Join(path, name))
+	}
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+	"mcpify/pkg/extension"
+	"mcpify/pkg/mcp"
+)
+
+const reloadTestSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Reload", "version": "1.0.0"},
+  "paths": {
+    "/users": {"get": {"operationId": "listUsers", "responses": {"200": {"description": "ok"}}}},
+    "/orders": {"get": {"operationId": "listOrders", "responses": {"200": {"description": "ok"}}}}
+  }
+}`
+
+func writeReloadConfig(t *testing.T, path, specPath, excludePath string) {
+	t.Helper()
+	content := "openapi:\n  spec_path: \"" + specPath + "\"\n  base_url: \"http://127.0.0.1:1\"\n"
+	if excludePath != "" {
+		content += "  exclude_paths:\n    - \"" + excludePath + "\"\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func listToolNames(t *testing.T, server *mcp.Server) []string {
+	t.Helper()
+	response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"}, config.RequestContext{})
+	data, err := json.Marshal(response.Result)
+	if err != nil {
+		t.Fatalf("Failed to marshal tools/list result: %v", err)
+	}
+	var result types.ListToolsResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Failed to unmarshal tools/list result: %v", err)
+	}
+
+	names := make([]string, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// closingExtension records whether it was closed
+type closingExtension struct {
+	extension.Base
+	closed *bool
+}
+
+func (e closingExtension) Close() error {
+	*e.closed = true
+	return nil
+}
+
+func TestReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	writeReloadConfig(t, configPath, specPath, "")
+
+	opts := options{configPath: configPath}
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	server := mcp.NewServer()
+	if _, err := buildTools(server, cfg, nil); err != nil {
+		t.Fatalf("Failed to build tools: %v", err)
+	}
+	if names := listToolNames(t, server); len(names) != 2 {
+		t.Fatalf("Expected 2 tools before reload, got %v", names)
+	}
+
+	reload := newReloader(opts, server, cfg)
+	var closed bool
+	reload.setExtensions(closingExtension{closed: &closed})
+
+	// Excluding a path removes its tool on reload
+	writeReloadConfig(t, configPath, specPath, "/orders")
+	if err := reload.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	names := listToolNames(t, server)
+	if len(names) != 1 || names[0] != "get_users" {
+		t.Errorf("Expected only get_users after reload, got %v", names)
+	}
+	if !closed {
+		t.Error("Expected the extensions of the replaced tools to be closed")
+	}
+
+	// An invalid configuration keeps the previous tools
+	if err := os.WriteFile(configPath, []byte("openapi: ["), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := reload.Reload(); err == nil {
+		t.Error("Expected reload of invalid config to fail")
+	}
+	names = listToolNames(t, server)
+	if len(names) != 1 || names[0] != "get_users" {
+		t.Errorf("Expected tools to be unchanged after failed reload, got %v", names)
+	}
+}
+
+func TestReloader_ConfigDuringReload(t *testing.T) {
+	// The spec is fetched from a server that answers once released
+	fetching := make(chan struct{}, 1)
+	release := make(chan struct{})
+	spec := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case fetching <- struct{}{}:
+		default:
+		}
+		<-release
+		_, _ = w.Write([]byte(reloadTestSpec))
+	}))
+	var once sync.Once
+	unblock := func() { once.Do(func() { close(release) }) }
+	defer spec.Close()
+	defer unblock()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeReloadConfig(t, configPath, spec.URL+"/spec.json", "")
+	cfg := config.Default()
+	reload := newReloader(options{configPath: configPath}, mcp.NewServer(), cfg)
+
+	done := make(chan error)
+	go func() { done <- reload.Reload() }()
+	<-fetching
+
+	// Readers of the configuration are not blocked by the spec fetch
+	read := make(chan *config.Config)
+	go func() { read <- reload.Config() }()
+	select {
+	case current := <-read:
+		if current != cfg {
+			t.Error("Expected the previous configuration while the reload is in progress")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Config not to wait for the reload")
+	}
+
+	unblock()
+	if err := <-done; err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if reload.Config() == cfg {
+		t.Error("Expected the reloaded configuration once the reload finishes")
+	}
+}
+
+func TestReloader_ReloadFromStdin(t *testing.T) {
+	server := mcp.NewServer()
+	reload := newReloader(options{configPath: config.StdinPath}, server, config.Default())
+	if err := reload.Reload(); err == nil {
+		t.Error("Expected reload of a configuration read from stdin to fail")
+	}
+}
+
+func TestReloader_WatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	// Lay out the mount like the kubelet: config.yaml -> ..data/config.yaml, ..data -> ..<version>
+	mount := filepath.Join(dir, "mount")
+	writeVersion := func(version, excludePath string) {
+		versionDir := filepath.Join(mount, "..v"+version)
+		if err := os.MkdirAll(versionDir, 0o755); err != nil {
+			t.Fatalf("Failed to create %s: %v", versionDir, err)
+		}
+		writeReloadConfig(t, filepath.Join(versionDir, "config.yaml"), specPath, excludePath)
+		content, err := os.ReadFile(filepath.Join(versionDir, "config.yaml"))
+		if err != nil {
+			t.Fatalf("Failed to read config: %v", err)
+		}
+		content = append(content, "server:\n  watch:\n    enabled: true\n    interval: 10ms\n"...)
+		if err := os.WriteFile(filepath.Join(versionDir, "config.yaml"), content, 0o600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		// Swap ..data atomically, as the kubelet does
+		tmp := filepath.Join(mount, "..data_tmp")
+		if err := os.Symlink("..v"+version, tmp); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+		if err := os.Rename(tmp, filepath.Join(mount, "..data")); err != nil {
+			t.Fatalf("Failed to swap symlink: %v", err)
+		}
+	}
+	writeVersion("1", "")
+	configPath := filepath.Join(mount, "config.yaml")
+	if err := os.Symlink(filepath.Join("..data", "config.yaml"), configPath); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	opts := options{configPath: configPath}
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	server := mcp.NewServer()
+	if _, err := buildTools(server, cfg, nil); err != nil {
+		t.Fatalf("Failed to build tools: %v", err)
+	}
+	reload := newReloader(opts, server, cfg)
+	stop := make(chan struct{})
+	defer close(stop)
+	reload.watchFiles(stop)
+
+	writeVersion("2", "/orders")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		names := listToolNames(t, server)
+		if len(names) == 1 && names[0] == "get_users" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected only get_users after the ConfigMap update, got %v", names)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "token")
+	fingerprint := func() string { return watchFingerprint([]string{dir}) }
+
+	empty := fingerprint()
+	if err := os.WriteFile(file, []byte("first"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	first := fingerprint()
+	if first == empty {
+		t.Error("Expected a new file to change the fingerprint")
+	}
+	if fingerprint() != first {
+		t.Error("Expected unchanged files to keep the fingerprint")
+	}
+
+	// Same size and modification time, different content
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if err := os.WriteFile(file, []byte("secnd"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Chtimes(file, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("Failed to set file times: %v", err)
+	}
+	if fingerprint() == first {
+		t.Error("Expected changed content to change the fingerprint")
+	}
+
+	// Hidden kubelet copies are skipped
+	second := fingerprint()
+	if err := os.Mkdir(filepath.Join(dir, "..2025_01_01"), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "..2025_01_01", "token"), []byte("third"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if fingerprint() != second {
+		t.Error("Expected entries starting with .. to be skipped")
+	}
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+	"mcpify/pkg/mcp"
+)
+
+// searchToolsToolName is the name of the meta tool searching the tools
+const searchToolsToolName = "search_tools"
+
+// Number of search_tools matches returned by default and at most
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// Scores of a query term matching a tool, from the strongest match to the weakest
+const (
+	nameWordScore        = 4 // a word of the name
+	namePrefixScore      = 3 // the start of a word of the name
+	groupScore           = 2 // the tag or path group
+	pathScore            = 2 // a segment of the path
+	descriptionWordScore = 1 // a word of the description
+	typoScore            = 1 // a word of the name with one typo
+)
+
+// searchMatch is a search_tools result entry
+type searchMatch struct {
+	endpointEntry
+	Score int `json:"score"`
+}
+
+// searchDocument holds the words of a tool that queries are matched against
+type searchDocument struct {
+	entry       endpointEntry
+	name        []string
+	path        []string
+	group       []string
+	description map[string]bool
+}
+
+// registerSearchTools registers the search_tools tool over entries
+func registerSearchTools(server *mcp.Server, entries []endpointEntry) {
+	documents := make([]searchDocument, len(entries))
+	for i, entry := range entries {
+		documents[i] = searchDocument{
+			entry: entry,
+			name:  searchWords(entry.Name),
+			path:  searchWords(entry.Path),
+			group: searchWords(entry.group),
+		}
+		documents[i].description = make(map[string]bool)
+		for _, word := range searchWords(entry.text) {
+			documents[i].description[word] = true
+		}
+	}
+
+	server.RegisterTool(
+		searchToolsToolName,
+		"Searches the available tools by keywords and returns the best matches, ranked by how well their names, paths and descriptions match, e.g. \"create invoice\". Use it to find the tool for a task instead of reading the whole tool list.",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "Keywords describing the task or the resource",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum number of matches (default %d, at most %d)", defaultSearchLimit, maxSearchLimit),
+					"minimum":     1,
+					"maximum":     maxSearchLimit,
+				},
+			},
+			"required": []string{"query"},
+		},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			query, _ := params["query"].(string)
+			terms := searchWords(query)
+			if len(terms) == 0 {
+				return nil, types.ArgumentErrorf("query must contain at least one word")
+			}
+			limit := searchLimit(params["limit"])
+
+			var matches []searchMatch
+			for _, document := range documents {
+				if score := document.score(terms); score > 0 {
+					matches = append(matches, searchMatch{endpointEntry: document.entry, Score: score})
+				}
+			}
+			sort.SliceStable(matches, func(i, j int) bool {
+				if matches[i].Score != matches[j].Score {
+					return matches[i].Score > matches[j].Score
+				}
+				return matches[i].Name < matches[j].Name
+			})
+			total := len(matches)
+			if len(matches) > limit {
+				matches = matches[:limit]
+			}
+			return map[string]interface{}{"total": total, "tools": matches}, nil
+		},
+	)
+}
+
+// searchLimit returns the number of matches to return for the limit argument
+func searchLimit(value interface{}) int {
+	limit := defaultSearchLimit
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			limit = int(n)
+		}
+	case float64:
+		limit = int(v)
+	case int:
+		limit = v
+	}
+	if limit < 1 {
+		return defaultSearchLimit
+	}
+	return min(limit, maxSearchLimit)
+}
+
+// score ranks how well the document matches the query terms, adding the score of the best
+// match of each term, or returns 0 when no term matches
+func (d searchDocument) score(terms []string) int {
+	total := 0
+	for _, term := range terms {
+		best := 0
+		for _, word := range d.name {
+			switch {
+			case word == term:
+				best = max(best, nameWordScore)
+			case strings.HasPrefix(word, term):
+				best = max(best, namePrefixScore)
+			case len(term) >= 4 && withinOneEdit(word, term):
+				best = max(best, typoScore)
+			}
+		}
+		for _, word := range d.group {
+			if word == term {
+				best = max(best, groupScore)
+			}
+		}
+		for _, segment := range d.path {
+			if segment == term {
+				best = max(best, pathScore)
+			}
+		}
+		if d.description[term] {
+			best = max(best, descriptionWordScore)
+		}
+		total += best
+	}
+	return total
+}
+
+// searchWords splits text into lowercase words at anything but letters and digits, and at
+// camelCase boundaries, dropping a plural s so "users" matches "user"
+func searchWords(text string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, singular(string(word)))
+			word = word[:0]
+		}
+	}
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			word = append(word, unicode.ToLower(r))
+		default:
+			word = append(word, unicode.ToLower(r))
+		}
+	}
+	flush()
+	return words
+}
+
+// singular drops the s of a plural word, leaving short words and words ending in ss alone
+func singular(word string) string {
+	if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// withinOneEdit reports whether a and b differ by at most one inserted, deleted or replaced byte
+func withinOneEdit(a, b string) bool {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	if len(a)-len(b) > 1 {
+		return false
+	}
+	i := 0
+	for i < len(b) && a[i] == b[i] {
+		i++
+	}
+	if i == len(b) {
+		return true
+	}
+	if len(a) == len(b) {
+		return a[i+1:] == b[i+1:]
+	}
+	return a[i+1:] == b[i:]
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"mcpify/internal/config"
+	"mcpify/pkg/mcp"
+)
+
+func TestSearchTools(t *testing.T) {
+	cfg := newMetaToolsConfig(t)
+	cfg.MetaTools.SearchTools = true
+
+	server := mcp.NewServer()
+	if _, err := buildTools(server, cfg, nil); err != nil {
+		t.Fatalf("Failed to build tools: %v", err)
+	}
+
+	matchNames := func(result map[string]interface{}) []string {
+		var names []string
+		tools, _ := result["tools"].([]interface{})
+		for _, tool := range tools {
+			names = append(names, tool.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		expected []string
+		total    float64
+	}{
+		{name: "ranked", params: map[string]interface{}{"query": "create a user"}, expected: []string{"post_users", "get_users"}, total: 2},
+		{name: "limit", params: map[string]interface{}{"query": "create a user", "limit": json.Number("1")}, expected: []string{"post_users"}, total: 2},
+		{name: "typo", params: map[string]interface{}{"query": "ordr"}, expected: []string{"get_orders_by_id"}, total: 1},
+		{name: "tag", params: map[string]interface{}{"query": "Users paging"}, expected: []string{"get_users", "post_users"}, total: 2},
+		{name: "no match", params: map[string]interface{}{"query": "invoices"}, total: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callMetaTool(t, server, searchToolsToolName, tt.params)
+			if names := matchNames(result); !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected matches %v, got %v", tt.expected, names)
+			}
+			if result["total"] != tt.total {
+				t.Errorf("Expected %v matches in total, got %v", tt.total, result["total"])
+			}
+		})
+	}
+
+	if _, err := server.CallTool(searchToolsToolName, map[string]interface{}{"query": " ? "}, config.RequestContext{}); err == nil {
+		t.Error("Expected an error for a query without words")
+	}
+}
+
+func TestSearchWords(t *testing.T) {
+	tests := map[string][]string{
+		"get_orders_by_id":   {"get", "order", "by", "id"},
+		"listUsers":          {"list", "user"},
+		"/orders/{id}/items": {"order", "id", "item"},
+		"Access the class.":  {"access", "the", "class"},
+	}
+	for text, expected := range tests {
+		if words := searchWords(text); !reflect.DeepEqual(words, expected) {
+			t.Errorf("searchWords(%q) = %v, expected %v", text, words, expected)
+		}
+	}
+}
+
+func TestWithinOneEdit(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"order", "order", true},
+		{"order", "ordr", true},
+		{"order", "orders", true},
+		{"order", "ordex", true},
+		{"order", "odrer", false},
+		{"order", "ord", false},
+	}
+	for _, tt := range tests {
+		if got := withinOneEdit(tt.a, tt.b); got != tt.expected {
+			t.Errorf("withinOneEdit(%q, %q) = %v, expected %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"sync"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/pkg/mcp"
+)
+
+// statusToolName is the built-in tool reporting the progress of background spec loading
+const statusToolName = "mcpify_status"
+
+// States of background spec loading
+const (
+	loadStateLoading = "loading"
+	loadStateReady   = "ready"
+	loadStateFailed  = "failed"
+)
+
+// loadStatus tracks the parsing of the specifications when the server starts before its tools
+type loadStatus struct {
+	mu        sync.Mutex
+	state     string
+	startedAt time.Time
+	readyAt   time.Time
+	tools     int
+	err       error
+}
+
+// newLoadStatus creates the status of a load starting now
+func newLoadStatus() *loadStatus {
+	return &loadStatus{state: loadStateLoading, startedAt: time.Now()}
+}
+
+// loaded records that count tools are ready
+func (s *loadStatus) loaded(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = loadStateReady
+	s.readyAt = time.Now()
+	s.tools = count
+	s.err = nil
+}
+
+// failed records why the tools could not be loaded
+func (s *loadStatus) failed(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = loadStateFailed
+	s.err = err
+}
+
+// report returns the status as the result of the status tool
+func (s *loadStatus) report() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := map[string]interface{}{
+		"state":      s.state,
+		"started_at": s.startedAt.UTC().Format(time.RFC3339),
+		"tools":      s.tools,
+	}
+	switch s.state {
+	case loadStateLoading:
+		report["elapsed"] = time.Since(s.startedAt).Round(time.Millisecond).String()
+	case loadStateReady:
+		report["ready_at"] = s.readyAt.UTC().Format(time.RFC3339)
+		report["load_time"] = s.readyAt.Sub(s.startedAt).Round(time.Millisecond).String()
+	}
+	if s.err != nil {
+		report["error"] = s.err.Error()
+	}
+	return report
+}
+
+// registerStatusTool registers the tool reporting status on server
+func registerStatusTool(server *mcp.Server, status *loadStatus) {
+	server.RegisterTool(
+		statusToolName,
+		"Reports whether mcpify has finished loading its OpenAPI specifications, how many tools are available and why loading failed, if it did. Call it when expected tools are missing.",
+		map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			return status.report(), nil
+		},
+	)
+}
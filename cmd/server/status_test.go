@@ -0,0 +1,108 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+	"mcpify/pkg/mcp"
+)
+
+// waitForLoad waits until the background load leaves the loading state and returns its report
+func waitForLoad(t *testing.T, server *mcp.Server) map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		result, err := server.CallTool(statusToolName, nil, config.RequestContext{})
+		if err != nil {
+			t.Fatalf("Failed to call %s: %v", statusToolName, err)
+		}
+		report := result.(map[string]interface{})
+		if report["state"] != loadStateLoading {
+			return report
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the load to finish, got %v", report)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReloader_LoadInBackground(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(specPath, []byte(reloadTestSpec), 0o600); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	writeReloadConfig(t, configPath, specPath, "")
+	opts := options{configPath: configPath}
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	server := mcp.NewServer()
+	notified := make(chan types.MCPNotification, 1)
+	server.OnNotification(func(notification types.MCPNotification) {
+		select {
+		case notified <- notification:
+		default:
+		}
+	})
+	reload := newReloader(opts, server, cfg)
+	reload.loadInBackground(cfg)
+
+	report := waitForLoad(t, server)
+	if report["state"] != loadStateReady || report["tools"] != 2 {
+		t.Errorf("Expected 2 tools ready, got %v", report)
+	}
+	if names := listToolNames(t, server); len(names) != 3 || names[2] != statusToolName {
+		t.Errorf("Expected the spec tools next to %s, got %v", statusToolName, names)
+	}
+	select {
+	case notification := <-notified:
+		if notification.Method != "notifications/tools/list_changed" {
+			t.Errorf("Expected a list_changed notification, got %s", notification.Method)
+		}
+	default:
+		t.Error("Expected clients to be notified of the new tools")
+	}
+
+	// The status tool stays through reloads
+	if err := reload.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if names := listToolNames(t, server); len(names) != 3 {
+		t.Errorf("Expected the status tool after reload, got %v", names)
+	}
+}
+
+func TestReloader_LoadInBackgroundFailure(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeReloadConfig(t, configPath, filepath.Join(t.TempDir(), "missing.json"), "")
+	opts := options{configPath: configPath}
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	server := mcp.NewServer()
+	newReloader(opts, server, cfg).loadInBackground(cfg)
+
+	report := waitForLoad(t, server)
+	if report["state"] != loadStateFailed || !strings.Contains(report["error"].(string), "not found") {
+		t.Errorf("Expected the load to fail with the missing spec, got %v", report)
+	}
+	if names := listToolNames(t, server); len(names) != 1 || names[0] != statusToolName {
+		t.Errorf("Expected only %s, got %v", statusToolName, names)
+	}
+}
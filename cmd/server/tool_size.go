@@ -0,0 +1,207 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+// collapsedNote ends the description of a schema whose nested fields were collapsed
+const collapsedNote = "Nested fields omitted."
+
+// applyDescriptionCap shortens the descriptions of tools longer than the configured maximum
+func applyDescriptionCap(size config.ToolSizeConfig, apiTools []types.APITool) []types.APITool {
+	if size.MaxDescriptionLength == 0 {
+		return apiTools
+	}
+	for i, tool := range apiTools {
+		apiTools[i].Description = truncateDescription(tool.Description, size.MaxDescriptionLength)
+	}
+	return apiTools
+}
+
+// truncateDescription shortens a description to at most limit characters, ending it after
+// its last whole sentence when that keeps at least half of it, or else at a word boundary
+// followed by an ellipsis
+func truncateDescription(description string, limit int) string {
+	runes := []rune(description)
+	if limit <= 0 || len(runes) <= limit {
+		return description
+	}
+	cut := string(runes[:limit])
+	if end := lastSentenceEnd(cut); end >= len(cut)/2 {
+		return cut[:end]
+	}
+	cut = string(runes[:limit-1])
+	if space := strings.LastIndexAny(cut, " \t\n"); space >= len(cut)/2 {
+		cut = cut[:space]
+	}
+	return strings.TrimRight(cut, " \t\n,;:") + "…"
+}
+
+// lastSentenceEnd returns the index just after the last sentence ending punctuation in text
+// that is followed by white space, or -1 when there is none
+func lastSentenceEnd(text string) int {
+	for i := len(text) - 2; i >= 0; i-- {
+		if strings.IndexByte(".!?", text[i]) >= 0 && strings.IndexByte(" \t\n", text[i+1]) >= 0 {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// capSchema returns a copy of an input schema with its descriptions shortened and the levels
+// nested deeper than the configured maximum collapsed. Schemas may be shared between tools,
+// so they are copied rather than modified.
+func capSchema(schema map[string]interface{}, size config.ToolSizeConfig) map[string]interface{} {
+	if size.MaxDescriptionLength == 0 && size.MaxSchemaDepth == 0 {
+		return schema
+	}
+	return capSchemaLevel(schema, size, 0)
+}
+
+// capSchemaLevel caps a schema at the given depth, the input schema being at depth 0
+func capSchemaLevel(schema map[string]interface{}, size config.ToolSizeConfig, depth int) map[string]interface{} {
+	if size.MaxSchemaDepth > 0 && depth >= size.MaxSchemaDepth && hasNestedSchemas(schema) {
+		return collapseSchema(schema, size)
+	}
+
+	capped := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		switch key {
+		case "description":
+			if text, ok := value.(string); ok {
+				value = truncateDescription(text, size.MaxDescriptionLength)
+			}
+		case "properties", "patternProperties":
+			if properties, ok := value.(map[string]interface{}); ok {
+				nested := make(map[string]interface{}, len(properties))
+				for name, property := range properties {
+					if propertySchema, ok := property.(map[string]interface{}); ok {
+						property = capSchemaLevel(propertySchema, size, depth+1)
+					}
+					nested[name] = property
+				}
+				value = nested
+			}
+		case "items", "additionalProperties", "not":
+			if nested, ok := value.(map[string]interface{}); ok {
+				value = capSchemaLevel(nested, size, depth+1)
+			}
+		case "allOf", "anyOf", "oneOf":
+			// Alternatives describe the same value, so they stay at its depth
+			if alternatives, ok := value.([]interface{}); ok {
+				nested := make([]interface{}, len(alternatives))
+				for i, alternative := range alternatives {
+					if alternativeSchema, ok := alternative.(map[string]interface{}); ok {
+						alternative = capSchemaLevel(alternativeSchema, size, depth)
+					}
+					nested[i] = alternative
+				}
+				value = nested
+			}
+		}
+		capped[key] = value
+	}
+	return capped
+}
+
+// checkSchemaSize logs a warning when an input schema is larger or deeper than the configured
+// thresholds, which some clients reject. With simplify set it returns the schema with its
+// deepest levels collapsed, one level at a time, until it is within them.
+func checkSchemaSize(name string, schema map[string]interface{}, size config.ToolSizeConfig) map[string]interface{} {
+	maxBytes, maxDepth := size.WarnSchemaSizeOrDefault(), size.WarnSchemaDepthOrDefault()
+	bytes, depth := schemaBytes(schema), schemaDepth(schema, 0)
+	if bytes <= maxBytes && depth <= maxDepth {
+		return schema
+	}
+	log.Printf("WARNING: Input schema of tool %s has %d bytes and %d levels, above the %d bytes or %d levels some clients accept",
+		name, bytes, depth, maxBytes, maxDepth)
+	if !size.Simplify {
+		return schema
+	}
+
+	simplified := schema
+	for levels := min(depth-1, maxDepth); levels >= 1; levels-- {
+		simplified = capSchema(schema, config.ToolSizeConfig{MaxDescriptionLength: size.MaxDescriptionLength, MaxSchemaDepth: levels})
+		if bytes = schemaBytes(simplified); bytes <= maxBytes {
+			log.Printf("Simplified input schema of tool %s to %d levels and %d bytes", name, levels, bytes)
+			return simplified
+		}
+	}
+	log.Printf("WARNING: Input schema of tool %s still has %d bytes with its arguments collapsed", name, bytes)
+	return simplified
+}
+
+// schemaBytes returns the size of a schema serialized as JSON
+func schemaBytes(schema map[string]interface{}) int64 {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
+
+// schemaDepth returns the depth of the most deeply nested schema, counted as in capSchema
+func schemaDepth(schema map[string]interface{}, depth int) int {
+	deepest := depth
+	for key, value := range schema {
+		switch key {
+		case "properties", "patternProperties":
+			properties, _ := value.(map[string]interface{})
+			for _, property := range properties {
+				if propertySchema, ok := property.(map[string]interface{}); ok {
+					deepest = max(deepest, schemaDepth(propertySchema, depth+1))
+				}
+			}
+		case "items", "additionalProperties", "not":
+			if nested, ok := value.(map[string]interface{}); ok {
+				deepest = max(deepest, schemaDepth(nested, depth+1))
+			}
+		case "allOf", "anyOf", "oneOf":
+			alternatives, _ := value.([]interface{})
+			for _, alternative := range alternatives {
+				if alternativeSchema, ok := alternative.(map[string]interface{}); ok {
+					deepest = max(deepest, schemaDepth(alternativeSchema, depth))
+				}
+			}
+		}
+	}
+	return deepest
+}
+
+// hasNestedSchemas reports whether a schema describes the fields or items of its values
+func hasNestedSchemas(schema map[string]interface{}) bool {
+	for _, key := range []string{"properties", "patternProperties", "items", "additionalProperties", "allOf", "anyOf", "oneOf", "not"} {
+		if _, ok := schema[key].(map[string]interface{}); ok {
+			return true
+		}
+		if _, ok := schema[key].([]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseSchema replaces a schema by one accepting any value of its type, keeping its
+// description and the keywords mcpify routes arguments with
+func collapseSchema(schema map[string]interface{}, size config.ToolSizeConfig) map[string]interface{} {
+	collapsed := make(map[string]interface{})
+	for _, key := range []string{"type", "format", "nullable", types.LocationKeyword, types.ParameterNameKeyword} {
+		if value, exists := schema[key]; exists {
+			collapsed[key] = value
+		}
+	}
+	// The note tells the model why the schema is vague
+	description, _ := schema["description"].(string)
+	description = truncateDescription(description, size.MaxDescriptionLength)
+	collapsed["description"] = strings.TrimSpace(description + " " + collapsedNote)
+	return collapsed
+}
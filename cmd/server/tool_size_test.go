@@ -0,0 +1,128 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+
+	"mcpify/internal/config"
+)
+
+func TestTruncateDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		limit       int
+		expected    string
+	}{
+		{name: "short", description: "Lists users.", limit: 20, expected: "Lists users."},
+		{name: "no limit", description: "Lists users. Supports paging.", limit: 0, expected: "Lists users. Supports paging."},
+		{name: "sentence", description: "Lists the users. Supports paging by cursor.", limit: 30, expected: "Lists the users."},
+		{name: "early sentence", description: "Lists. The users of the organization by team.", limit: 30, expected: "Lists. The users of the…"},
+		{name: "no boundary", description: "Supercalifragilisticexpialidocious", limit: 10, expected: "Supercali…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateDescription(tt.description, tt.limit); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCapSchema(t *testing.T) {
+	address := map[string]interface{}{
+		"type":        "object",
+		"description": "Postal address. Used for shipping.",
+		"properties": map[string]interface{}{
+			"street": map[string]interface{}{"type": "string"},
+		},
+	}
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id": map[string]interface{}{"type": "string", "description": "Identifier of the user to update"},
+			"body": map[string]interface{}{
+				"type":        "object",
+				"x-mcpify-in": "body",
+				"properties": map[string]interface{}{
+					"name":    map[string]interface{}{"type": "string"},
+					"address": address,
+					"tags":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+				},
+			},
+		},
+	}
+
+	capped := capSchema(schema, config.ToolSizeConfig{MaxDescriptionLength: 20, MaxSchemaDepth: 2})
+	data, _ := json.Marshal(capped)
+	expected := `{"properties":{"body":{"properties":{` +
+		`"address":{"description":"Postal address. Nested fields omitted.","type":"object"},` +
+		`"name":{"type":"string"},` +
+		`"tags":{"description":"Nested fields omitted.","type":"array"}},` +
+		`"type":"object","x-mcpify-in":"body"},` +
+		`"id":{"description":"Identifier of the…","type":"string"}},"type":"object"}`
+	if string(data) != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, data)
+	}
+
+	// The schema may be shared with other tools and is left as it was
+	if _, exists := address["properties"]; !exists || address["description"] != "Postal address. Used for shipping." {
+		t.Errorf("Expected the original schema to be unchanged, got %v", address)
+	}
+	if uncapped := capSchema(schema, config.ToolSizeConfig{}); uncapped["properties"].(map[string]interface{})["body"] == nil {
+		t.Error("Expected the schema unchanged without caps")
+	}
+}
+
+func TestCheckSchemaSize(t *testing.T) {
+	// Each level nests an object holding a long description and the next level
+	nested := func(levels int) map[string]interface{} {
+		schema := map[string]interface{}{"type": "string"}
+		for i := 0; i < levels; i++ {
+			schema = map[string]interface{}{
+				"type":        "object",
+				"description": strings.Repeat("x", 100),
+				"properties":  map[string]interface{}{"child": schema},
+			}
+		}
+		return schema
+	}
+
+	schema := nested(8)
+	if depth := schemaDepth(schema, 0); depth != 8 {
+		t.Fatalf("Expected depth 8, got %d", depth)
+	}
+
+	var logs bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&logs)
+	defer log.SetOutput(previous)
+
+	if checked := checkSchemaSize("small", schema, config.ToolSizeConfig{}); schemaDepth(checked, 0) != 8 || logs.Len() != 0 {
+		t.Errorf("Expected a schema within the defaults to be left alone, got logs %q", logs.String())
+	}
+
+	size := config.ToolSizeConfig{WarnSchemaSize: "500", WarnSchemaDepth: 6}
+	if checked := checkSchemaSize("deep", schema, size); schemaDepth(checked, 0) != 8 {
+		t.Error("Expected the schema unchanged without simplify")
+	}
+	if !strings.Contains(logs.String(), "WARNING: Input schema of tool deep has") {
+		t.Errorf("Expected a warning, got %q", logs.String())
+	}
+
+	size.Simplify = true
+	checked := checkSchemaSize("deep", schema, size)
+	if depth, bytes := schemaDepth(checked, 0), schemaBytes(checked); depth != 2 || bytes > 500 {
+		t.Errorf("Expected the schema simplified to 2 levels within 500 bytes, got %d levels and %d bytes", depth, bytes)
+	}
+	if schemaDepth(schema, 0) != 8 {
+		t.Error("Expected the original schema to be unchanged")
+	}
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"mcpify/internal/types"
+	"mcpify/pkg/mcp"
+)
+
+// maxTableDescription is the number of description characters shown in table output
+const maxTableDescription = 60
+
+// runTools dispatches the tools subcommands
+func runTools(args []string, stdout io.Writer) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "list":
+			return runToolsList(args[1:], stdout)
+		case "export":
+			return runToolsExport(args[1:], stdout)
+		}
+	}
+	return fmt.Errorf("usage: mcpify tools list|export [options]")
+}
+
+// runToolsList generates the tools from the configuration and prints them without starting a server
+func runToolsList(args []string, stdout io.Writer) error {
+	var opts options
+	fs := newFlagSet("tools list", "[options]", &opts)
+	verbose := addVerboseFlag(fs)
+	output := fs.String("output", "table", "Output format (table, json)")
+	fs.StringVar(output, "o", "table", "Output format (table, json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("invalid output format %q, expected table or json", *output)
+	}
+	defer quietLogs(*verbose)()
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	server := mcp.NewServer()
+	if _, err := buildTools(server, cfg, nil); err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		return printToolsJSON(stdout, server.Tools())
+	}
+	return printToolsTable(stdout, server.Tools())
+}
+
+// printToolsJSON prints tools in the same shape as the MCP tools/list result
+func printToolsJSON(w io.Writer, schemas []mcp.ToolSchema) error {
+	tools := make([]types.Tool, 0, len(schemas))
+	for _, schema := range schemas {
+		tools = append(tools, types.Tool{
+			Name:        schema.Name,
+			Description: schema.Description,
+			InputSchema: schema.InputSchema,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(types.ListToolsResult{Tools: tools})
+}
+
+// printToolsTable prints one line per tool with its parameters, marking required ones with "*"
+func printToolsTable(w io.Writer, schemas []mcp.ToolSchema) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPARAMETERS\tDESCRIPTION")
+	for _, schema := range schemas {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", schema.Name, summarizeParameters(schema.InputSchema), summarizeDescription(schema.Description))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n%d tools\n", len(schemas))
+	return nil
+}
+
+// summarizeParameters lists the properties of an input schema, marking required ones with "*"
+func summarizeParameters(inputSchema map[string]interface{}) string {
+	properties, _ := inputSchema["properties"].(map[string]interface{})
+	if len(properties) == 0 {
+		return "-"
+	}
+
+	required := make(map[string]bool)
+	switch names := inputSchema["required"].(type) {
+	case []string:
+		for _, name := range names {
+			required[name] = true
+		}
+	case []interface{}:
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		if required[name] {
+			name += "*"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// summarizeDescription returns the first line of a description, shortened for table output
+func summarizeDescription(description string) string {
+	description, _, _ = strings.Cut(strings.TrimSpace(description), "\n")
+	if runes := []rune(description); len(runes) > maxTableDescription {
+		return string(runes[:maxTableDescription-3]) + "..."
+	}
+	return description
+}
+
+// toolManifest describes every tool agents can call, for reviewing and diffing tool changes
+type toolManifest struct {
+	Tools []manifestTool `json:"tools"`
+}
+
+// manifestTool is one tool of the manifest. Method and path are empty for composite tools,
+// and the output schema is omitted when the specification does not describe the response.
+type manifestTool struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	Method       string                 `json:"method,omitempty"`
+	Path         string                 `json:"path,omitempty"`
+	InputSchema  map[string]interface{} `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+}
+
+// runToolsExport prints the manifest of the generated tools without starting a server
+func runToolsExport(args []string, stdout io.Writer) error {
+	var opts options
+	fs := newFlagSet("tools export", "[options]", &opts)
+	verbose := addVerboseFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	defer quietLogs(*verbose)()
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	generated, err := generateTools(cfg, nil)
+	if err != nil {
+		return err
+	}
+	server := mcp.NewServer()
+	if _, err := registerTools(server, cfg, generated); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(buildToolManifest(server.Tools(), generated))
+}
+
+// buildToolManifest describes the registered tools, sorted by name, adding the operation and
+// response schema of the generated tools they were built from
+func buildToolManifest(schemas []mcp.ToolSchema, generated []apiTools) toolManifest {
+	operations := make(map[string]types.APITool)
+	for _, api := range generated {
+		for _, tool := range api.tools {
+			operations[tool.Name] = tool
+		}
+	}
+
+	manifest := toolManifest{Tools: make([]manifestTool, 0, len(schemas))}
+	for _, schema := range schemas {
+		var operation *types.APITool
+		if tool, exists := operations[schema.Name]; exists {
+			operation = &tool
+		}
+		manifest.Tools = append(manifest.Tools, newManifestTool(schema, operation))
+	}
+	return manifest
+}
+
+// newManifestTool describes a registered tool, adding the operation and response schema of
+// the generated tool it was built from, or nil for composite and meta tools
+func newManifestTool(schema mcp.ToolSchema, operation *types.APITool) manifestTool {
+	tool := manifestTool{
+		Name:        schema.Name,
+		Description: schema.Description,
+		InputSchema: schema.InputSchema,
+	}
+	if operation != nil {
+		tool.Method = operation.Method
+		tool.Path = operation.Path
+		if operation.ResolveOutputSchema != nil {
+			tool.OutputSchema = operation.ResolveOutputSchema()
+		}
+	}
+	return tool
+}
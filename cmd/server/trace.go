@@ -0,0 +1,67 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"mcpify/internal/config"
+	"mcpify/pkg/mcp"
+)
+
+// openTrace returns the tracer of --trace, writing to path or, when it is empty, to stderr,
+// and a function closing the file
+func openTrace(cfg *config.Config, path string) (*mcp.Tracer, func(), error) {
+	var w io.Writer = os.Stderr
+	closeFile := func() {}
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		w = file
+		closeFile = func() { file.Close() }
+	}
+	return mcp.NewTracer(w, traceSecrets(cfg)), closeFile, nil
+}
+
+// traceSecrets returns the configured credentials, which traces must not show even where
+// they appear under keys that do not look sensitive, like arguments echoed in errors
+func traceSecrets(cfg *config.Config) []string {
+	var secrets []string
+	add := func(value string, err error) {
+		if err == nil && value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+	addAuth := func(auth config.AuthConfig) {
+		add(auth.TokenValue())
+		add(auth.PasswordValue())
+		add(auth.APIKeyValue())
+		for _, item := range auth.Headers {
+			add(item.Header.Value, nil)
+			for _, value := range item.Header.Values {
+				add(value, nil)
+			}
+		}
+	}
+
+	for _, api := range cfg.APIConfigs() {
+		addAuth(api.Auth)
+		for _, tenant := range api.Tenants.Instances {
+			if tenant.Auth != nil {
+				addAuth(*tenant.Auth)
+			}
+		}
+	}
+	add(cfg.Server.Admin.TokenValue())
+	add(cfg.Server.HTTP.Sessions.SecretValue())
+	for _, user := range cfg.Security.BasicAuth.Users {
+		add(user.Password, nil)
+	}
+	return secrets
+}
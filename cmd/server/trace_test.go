@@ -0,0 +1,25 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"mcpify/internal/config"
+)
+
+func TestTraceSecrets(t *testing.T) {
+	cfg := config.Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.OpenAPI.Auth = config.AuthConfig{Type: "bearer", Token: "api-token"}
+	cfg.Server.Admin.Token = "admin-token"
+	cfg.Security.BasicAuth.Users = []config.BasicAuthUser{{Username: "ada", Password: "ada-password"}}
+
+	expected := []string{"api-token", "admin-token", "ada-password"}
+	if secrets := traceSecrets(cfg); !reflect.DeepEqual(secrets, expected) {
+		t.Errorf("Expected %v, got %v", expected, secrets)
+	}
+}
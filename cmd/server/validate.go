@@ -0,0 +1,123 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"mcpify/internal/config"
+	"mcpify/internal/openapi"
+)
+
+// validationReport prints the outcome of each validate check and counts failures
+type validationReport struct {
+	w        io.Writer
+	errors   int
+	warnings int
+}
+
+func (r *validationReport) ok(format string, args ...interface{}) {
+	fmt.Fprintf(r.w, "ok       "+format+"\n", args...)
+}
+
+func (r *validationReport) warn(format string, args ...interface{}) {
+	r.warnings++
+	fmt.Fprintf(r.w, "warning  "+format+"\n", args...)
+}
+
+func (r *validationReport) fail(format string, args ...interface{}) {
+	r.errors++
+	fmt.Fprintf(r.w, "error    "+format+"\n", args...)
+}
+
+// checkAPI reports conflicting headers and fetches and validates the specification of an API,
+// returning false when the specification cannot be loaded
+func (r *validationReport) checkAPI(api *config.OpenAPIConfig) bool {
+	for _, conflict := range api.HeaderConflicts() {
+		if conflict.Always {
+			r.fail("%s: %s", api.SpecPath, conflict.Message)
+		} else {
+			r.warn("%s: %s", api.SpecPath, conflict.Message)
+		}
+	}
+
+	err := openapi.NewParser(api).ValidateSpec()
+	switch {
+	case err == nil:
+		r.ok("spec %s", api.SpecPath)
+	case errors.Is(err, openapi.ErrSpecValidation):
+		// The server still serves specs that load but do not conform to the schema
+		r.warn("spec %s: %v", api.SpecPath, err)
+	default:
+		r.fail("spec %s: %v", api.SpecPath, err)
+		return false
+	}
+	return true
+}
+
+// runValidate checks the configuration in strict mode, fetches and validates every OpenAPI
+// specification and reports conflicting headers, without starting a server. It fails when
+// any check fails, so it can gate configuration changes in CI.
+func runValidate(args []string, stdout io.Writer) error {
+	var opts options
+	fs := newFlagSet("validate", "[options]", &opts)
+	verbose := addVerboseFlag(fs)
+	failOnWarnings := fs.Bool("fail-on-warnings", false, "Exit with an error when any check reports a warning")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	defer quietLogs(*verbose)()
+
+	report := &validationReport{w: stdout}
+
+	// Unknown keys are always errors here, since they usually hide a typo
+	opts.strict = true
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		report.fail("%v", err)
+		return fmt.Errorf("validation failed with %s", countOf(report.errors, "error"))
+	}
+	if opts.configPath != "" {
+		report.ok("configuration %s", opts.configPath)
+	}
+
+	apis := cfg.APIConfigs()
+	for _, api := range apis {
+		report.checkAPI(api)
+	}
+
+	// Generating the tools catches duplicate names across APIs
+	if report.errors == 0 {
+		generated, err := generateTools(cfg, nil)
+		if err != nil {
+			report.fail("%v", err)
+		} else {
+			count := 0
+			for _, api := range generated {
+				count += len(api.tools)
+			}
+			report.ok("%d tools from %d APIs", count, len(apis))
+		}
+	}
+
+	switch {
+	case report.errors > 0:
+		return fmt.Errorf("validation failed with %s", countOf(report.errors, "error"))
+	case report.warnings > 0 && *failOnWarnings:
+		return fmt.Errorf("validation failed with %s", countOf(report.warnings, "warning"))
+	}
+	fmt.Fprintf(stdout, "Configuration is valid\n")
+	return nil
+}
+
+// countOf formats a count with a singular or plural noun
+func countOf(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
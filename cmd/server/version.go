@@ -0,0 +1,84 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata, set with -ldflags "-X main.Version=v1.2.3 -X main.Commit=... -X main.BuildTime=..."
+// (see Makefile). Values left empty are filled in from the build information embedded by the
+// Go toolchain where available.
+var (
+	Version   = ""
+	Commit    = ""
+	BuildTime = ""
+	GoVersion = ""
+)
+
+// versionInfo describes the running binary
+type versionInfo struct {
+	Version   string
+	Commit    string
+	BuildTime string
+	GoVersion string
+}
+
+// currentVersion returns the build metadata of the running binary
+func currentVersion() versionInfo {
+	info := versionInfo{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: GoVersion}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		// Set for binaries installed with "go install mcpify/cmd/server@version"
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	if info.GoVersion == "" {
+		info.GoVersion = runtime.Version()
+	}
+	return info
+}
+
+// runVersion prints the build metadata
+func runVersion(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	info := currentVersion()
+	fmt.Fprintf(stdout, "mcpify %s\n", info.Version)
+	fmt.Fprintf(stdout, "  commit: %s\n", info.Commit)
+	fmt.Fprintf(stdout, "  built:  %s\n", info.BuildTime)
+	fmt.Fprintf(stdout, "  go:     %s\n", info.GoVersion)
+	return nil
+}
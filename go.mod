@@ -3,14 +3,15 @@ module mcpify
 go 1.22.5
 
 require (
-	github.com/PaesslerAG/jsonpath v0.1.1
+	github.com/BurntSushi/toml v1.4.0
 	github.com/getkin/kin-openapi v0.133.0
 	github.com/stretchr/testify v1.9.0
+	github.com/tetratelabs/wazero v1.8.2
+	golang.org/x/crypto v0.31.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/PaesslerAG/gval v1.0.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/go-openapi/jsonpointer v0.21.0 // indirect
 	github.com/go-openapi/swag v0.23.0 // indirect
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+
+	"mcpify/internal/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// credential is the stored password of a basic auth user: a bcrypt hash, or the SHA-256
+// digest of a plain password
+type credential struct {
+	hash   []byte
+	digest []byte
+}
+
+// matches reports whether password is the stored one. Digests of plain passwords are compared
+// in constant time.
+func (c credential) matches(password string) bool {
+	if c.hash != nil {
+		return bcrypt.CompareHashAndPassword(c.hash, []byte(password)) == nil
+	}
+	return subtle.ConstantTimeCompare(sha256Digest(password), c.digest) == 1
+}
+
+// BasicAuthenticator checks HTTP Basic auth credentials of MCP clients against configured users
+type BasicAuthenticator struct {
+	users map[string]credential
+	// unknown is checked for unknown usernames, so they take as long as wrong passwords
+	unknown credential
+}
+
+// NewBasicAuthenticator creates an authenticator for the configured users. Plain passwords are
+// kept as SHA-256 digests, and hashed passwords are bcrypt hashes.
+func NewBasicAuthenticator(cfg config.BasicAuthConfig) (*BasicAuthenticator, error) {
+	users := make(map[string]credential, len(cfg.Users))
+	unknown := credential{digest: make([]byte, sha256.Size)}
+	for _, user := range cfg.Users {
+		if user.PasswordHash == "" {
+			password, err := user.PasswordValue()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the password of %s: %w", user.Username, err)
+			}
+			users[user.Username] = credential{digest: sha256Digest(password)}
+			continue
+		}
+		hash, err := config.ParsePasswordHash(user.PasswordHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid password hash of %s: %w", user.Username, err)
+		}
+		users[user.Username] = credential{hash: hash}
+		if unknown.hash == nil {
+			unknown = credential{hash: hash}
+		}
+	}
+	return &BasicAuthenticator{users: users, unknown: unknown}, nil
+}
+
+// Authenticate reports whether the username and password match a configured user
+func (a *BasicAuthenticator) Authenticate(username, password string) bool {
+	user, ok := a.users[username]
+	if !ok {
+		a.unknown.matches(password)
+		return false
+	}
+	return user.matches(password)
+}
+
+func sha256Digest(password string) []byte {
+	digest := sha256.Sum256([]byte(password))
+	return digest[:]
+}
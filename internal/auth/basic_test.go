@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"testing"
+
+	"mcpify/internal/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBasicAuthenticator(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	authenticator, err := NewBasicAuthenticator(config.BasicAuthConfig{
+		Enabled: true,
+		Users: []config.BasicAuthUser{
+			{Username: "plain", Password: "secret"},
+			// Written by htpasswd -nbB, with a low cost to keep the test fast
+			{Username: "htpasswd", PasswordHash: "$2y$04$3TwmRpLyzJ8wqySzcgeM9eFWAfGW3bmQ55WiDKqaa1Mgzi/f0Ko3i"},
+			{Username: "bcrypt", PasswordHash: string(hash)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		username string
+		password string
+		expected bool
+	}{
+		{"plain", "secret", true},
+		{"plain", "wrong", false},
+		{"htpasswd", "secret", true},
+		{"htpasswd", "Secret", false},
+		{"bcrypt", "secret", true},
+		{"bcrypt", "", false},
+		{"unknown", "secret", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := authenticator.Authenticate(tt.username, tt.password); got != tt.expected {
+			t.Errorf("Authenticate(%q, %q) = %v, expected %v", tt.username, tt.password, got, tt.expected)
+		}
+	}
+}
+
+func TestNewBasicAuthenticator_RejectsDigests(t *testing.T) {
+	// Unsalted digests are not accepted as password hashes
+	_, err := NewBasicAuthenticator(config.BasicAuthConfig{
+		Enabled: true,
+		Users: []config.BasicAuthUser{
+			{Username: "ops", PasswordHash: "sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"},
+		},
+	})
+	if err == nil {
+		t.Error("expected a sha256 digest to be rejected")
+	}
+}
@@ -0,0 +1,374 @@
+// Package auth implements authentication of inbound MCP clients
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+const (
+	// jwksCacheTTL is how long fetched signing keys are trusted before being refreshed
+	jwksCacheTTL = 1 * time.Hour
+	// jwksMinRefreshInterval throttles refetches triggered by unknown key IDs
+	jwksMinRefreshInterval = 1 * time.Minute
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	ErrInvalidIssuer    = errors.New("invalid token issuer")
+	ErrInvalidAudience  = errors.New("invalid token audience")
+	ErrJWKSFetchFailed  = errors.New("failed to fetch JWKS")
+)
+
+// JWTValidator validates JWTs against keys published at a JWKS endpoint
+type JWTValidator struct {
+	config config.JWTConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// NewJWTValidator creates a new JWT validator from configuration
+func NewJWTValidator(cfg config.JWTConfig) *JWTValidator {
+	return &JWTValidator{
+		config: cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+		keys:   make(map[string]crypto.PublicKey),
+	}
+}
+
+// Validate verifies the token signature and standard claims and returns the verified claims
+func (v *JWTValidator) Validate(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedToken, err)
+	}
+
+	hash, ok := hashForAlg(header.Alg)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedToken, err)
+	}
+
+	key, err := v.lookupKey(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(header.Alg, key, hash, hasher.Sum(nil), signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedToken, err)
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// validateClaims checks expiry, not-before, issuer, and audience claims
+func (v *JWTValidator) validateClaims(claims map[string]interface{}) error {
+	now := v.now()
+	leeway := v.config.Leeway
+
+	exp, ok, err := numericDate(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if ok {
+		if now.After(exp.Add(leeway)) {
+			return ErrTokenExpired
+		}
+	} else if v.config.RequireExpiry {
+		return fmt.Errorf("%w: missing exp claim", ErrTokenExpired)
+	}
+
+	nbf, ok, err := numericDate(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(leeway).Before(nbf) {
+		return ErrTokenNotYetValid
+	}
+
+	if v.config.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.config.Issuer {
+			return ErrInvalidIssuer
+		}
+	}
+
+	if v.config.Audience != "" && !audienceMatches(claims["aud"], v.config.Audience) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+// numericDate returns the time of a NumericDate claim such as exp, reporting whether the claim
+// is present. A claim that is present but not a number, e.g. "exp":"1", makes the token
+// malformed rather than leaving it unchecked.
+func numericDate(claims map[string]interface{}, name string) (time.Time, bool, error) {
+	value, present := claims[name]
+	if !present {
+		return time.Time{}, false, nil
+	}
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("%w: %s claim is not a number", ErrMalformedToken, name)
+	}
+	return time.Unix(int64(seconds), 0), true, nil
+}
+
+// audienceMatches checks the aud claim, which may be a string or an array of strings
+func audienceMatches(aud interface{}, expected string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == expected
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookupKey returns the public key for a key ID, refreshing the JWKS when needed
+func (v *JWTValidator) lookupKey(kid string) (crypto.PublicKey, error) {
+	v.mu.RLock()
+	key, found := v.findKey(kid)
+	stale := v.now().Sub(v.fetchedAt) > jwksCacheTTL
+	canRefresh := v.now().Sub(v.fetchedAt) > jwksMinRefreshInterval
+	v.mu.RUnlock()
+
+	if found && !stale {
+		return key, nil
+	}
+
+	if stale || canRefresh {
+		if err := v.refreshKeys(); err != nil {
+			if found {
+				// Keep serving the cached key if the JWKS endpoint is temporarily unavailable
+				return key, nil
+			}
+			return nil, err
+		}
+		v.mu.RLock()
+		key, found = v.findKey(kid)
+		v.mu.RUnlock()
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// findKey looks up a key by ID; tokens without a kid match a sole published key.
+// Callers must hold v.mu.
+func (v *JWTValidator) findKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// refreshKeys fetches and parses the JWKS document
+func (v *JWTValidator) refreshKeys() error {
+	resp, err := v.client.Get(v.config.JWKSURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrJWKSFetchFailed, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: HTTP %d", ErrJWKSFetchFailed, resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return fmt.Errorf("%w: %v", ErrJWKSFetchFailed, err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Skip keys we cannot use rather than rejecting the whole set
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = v.now()
+	v.mu.Unlock()
+
+	return nil
+}
+
+// jsonWebKey represents a single key in a JWKS document
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey converts the JWK into an RSA or ECDSA public key
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+// hashForAlg maps a JWS algorithm to its hash function
+func hashForAlg(alg string) (crypto.Hash, bool) {
+	switch alg {
+	case "RS256", "ES256":
+		return crypto.SHA256, true
+	case "RS384", "ES384":
+		return crypto.SHA384, true
+	case "RS512", "ES512":
+		return crypto.SHA512, true
+	default:
+		return 0, false
+	}
+}
+
+// verifySignature checks the signature over the digest with the given key
+func verifySignature(alg string, key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) error {
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("%w: %s does not match RSA key", ErrUnsupportedAlg, alg)
+		}
+		if err := rsa.VerifyPKCS1v15(pub, hash, digest, signature); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("%w: %s does not match EC key", ErrUnsupportedAlg, alg)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return ErrUnknownKey
+	}
+}
+
+// decodeSegment decodes a base64url-encoded JSON token segment
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// decodeBigInt decodes a base64url-encoded big-endian integer
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
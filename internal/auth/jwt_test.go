@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal segment: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	signingInput := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest.Sum(nil))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	signingInput := encodeSegment(t, map[string]string{"alg": "ES256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest.Sum(nil))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newJWKSServer(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) *httptest.Server {
+	t.Helper()
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa-key",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec-key",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+				"y":   base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestJWTValidator_Validate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+
+	server := newJWKSServer(t, rsaKey, ecKey)
+	now := time.Unix(1700000000, 0)
+
+	validator := NewJWTValidator(config.JWTConfig{
+		Enabled:  true,
+		JWKSURL:  server.URL,
+		Issuer:   "https://issuer.example.com",
+		Audience: "mcpify",
+		Leeway:   30 * time.Second,
+	})
+	validator.now = func() time.Time { return now }
+
+	baseClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":       "https://issuer.example.com",
+			"aud":       "mcpify",
+			"sub":       "user-123",
+			"tenant_id": "acme",
+			"exp":       now.Add(time.Hour).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr error
+	}{
+		{
+			name:  "valid RS256 token",
+			token: func() string { return signRS256(t, rsaKey, "rsa-key", baseClaims()) },
+		},
+		{
+			name:  "valid ES256 token",
+			token: func() string { return signES256(t, ecKey, "ec-key", baseClaims()) },
+		},
+		{
+			name: "audience array",
+			token: func() string {
+				claims := baseClaims()
+				claims["aud"] = []string{"other", "mcpify"}
+				return signRS256(t, rsaKey, "rsa-key", claims)
+			},
+		},
+		{
+			name: "expired within leeway",
+			token: func() string {
+				claims := baseClaims()
+				claims["exp"] = now.Add(-10 * time.Second).Unix()
+				return signRS256(t, rsaKey, "rsa-key", claims)
+			},
+		},
+		{
+			name: "expired token",
+			token: func() string {
+				claims := baseClaims()
+				claims["exp"] = now.Add(-time.Hour).Unix()
+				return signRS256(t, rsaKey, "rsa-key", claims)
+			},
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name: "not yet valid",
+			token: func() string {
+				claims := baseClaims()
+				claims["nbf"] = now.Add(time.Hour).Unix()
+				return signRS256(t, rsaKey, "rsa-key", claims)
+			},
+			wantErr: ErrTokenNotYetValid,
+		},
+		{
+			name: "string exp",
+			token: func() string {
+				claims := baseClaims()
+				claims["exp"] = "1"
+				return signRS256(t, rsaKey, "rsa-key", claims)
+			},
+			wantErr: ErrMalformedToken,
+		},
+		{
+			name: "string nbf",
+			token: func() string {
+				claims := baseClaims()
+				claims["nbf"] = "2099-01-01"
+				return signRS256(t, rsaKey, "rsa-key", claims)
+			},
+			wantErr: ErrMalformedToken,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				claims := baseClaims()
+				claims["iss"] = "https://evil.example.com"
+				return signRS256(t, rsaKey, "rsa-key", claims)
+			},
+			wantErr: ErrInvalidIssuer,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				claims := baseClaims()
+				claims["aud"] = "someone-else"
+				return signRS256(t, rsaKey, "rsa-key", claims)
+			},
+			wantErr: ErrInvalidAudience,
+		},
+		{
+			name:    "signed with unknown key",
+			token:   func() string { return signRS256(t, otherKey, "rsa-key", baseClaims()) },
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "unknown key id",
+			token:   func() string { return signRS256(t, rsaKey, "missing", baseClaims()) },
+			wantErr: ErrUnknownKey,
+		},
+		{
+			name: "alg none",
+			token: func() string {
+				return encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, baseClaims()) + "."
+			},
+			wantErr: ErrUnsupportedAlg,
+		},
+		{
+			name:    "malformed token",
+			token:   func() string { return "not-a-jwt" },
+			wantErr: ErrMalformedToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := validator.Validate(tt.token())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims["sub"] != "user-123" {
+				t.Errorf("expected sub claim user-123, got %v", claims["sub"])
+			}
+		})
+	}
+}
+
+func TestJWTValidator_RequireExpiry(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+	server := newJWKSServer(t, rsaKey, ecKey)
+
+	validator := NewJWTValidator(config.JWTConfig{Enabled: true, JWKSURL: server.URL, RequireExpiry: true})
+	_, err = validator.Validate(signRS256(t, rsaKey, "rsa-key", map[string]interface{}{"sub": "user-123"}))
+	if !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("expected missing exp to be rejected, got %v", err)
+	}
+}
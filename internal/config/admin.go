@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+)
+
+// AdminConfig configures the admin HTTP API, served on its own listener so it can be bound
+// to a private interface. Every request must present the admin token as a bearer token.
+type AdminConfig struct {
+	Enabled   bool   `yaml:"enabled" json:"enabled"`
+	Host      string `yaml:"host" json:"host"`
+	Port      int    `yaml:"port" json:"port"`
+	Token     string `yaml:"token" json:"token"`
+	TokenFile string `yaml:"token_file" json:"token_file"` // Read the token from a file instead
+}
+
+// TokenValue returns the admin token, reading token_file when configured
+func (a *AdminConfig) TokenValue() (string, error) {
+	return resolveSecret(a.Token, a.TokenFile)
+}
+
+// Validate validates the AdminConfig
+func (a *AdminConfig) Validate() error {
+	if !a.Enabled {
+		return nil
+	}
+	if a.Port < 1 || a.Port > 65535 {
+		return ErrInvalidPort
+	}
+	if a.Token == "" && a.TokenFile == "" {
+		return fmt.Errorf("token or token_file is required")
+	}
+	if a.Token != "" && a.TokenFile != "" {
+		return fmt.Errorf("token and token_file are mutually exclusive")
+	}
+	if _, err := a.TokenValue(); err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// toolNamePattern matches the tool names MCP clients accept
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ToolAlias presents a generated tool under a human-chosen name, which stays stable when the
+// path-derived name changes
+type ToolAlias struct {
+	Name string `yaml:"name" json:"name"`
+	// KeepOriginal registers the tool under its generated name as well as the alias
+	KeepOriginal bool `yaml:"keep_original" json:"keep_original"`
+}
+
+// Validate validates the ToolAlias
+func (a *ToolAlias) Validate() error {
+	if a.Name == "" {
+		return errors.New("name is required")
+	}
+	if !toolNamePattern.MatchString(a.Name) {
+		return fmt.Errorf("invalid name %q: use up to 64 letters, digits, underscores and hyphens", a.Name)
+	}
+	return nil
+}
+
+// validateAliases checks each alias and that no two generated tools share an alias
+func validateAliases(aliases map[string]ToolAlias) error {
+	owners := make(map[string]string, len(aliases))
+	for generated, alias := range aliases {
+		if err := alias.Validate(); err != nil {
+			return fmt.Errorf("aliases[%s]: %w", generated, err)
+		}
+		if owner, exists := owners[alias.Name]; exists {
+			// Report the pair in a stable order
+			if owner > generated {
+				owner, generated = generated, owner
+			}
+			return fmt.Errorf("aliases[%s]: alias %q is also used by %s", generated, alias.Name, owner)
+		}
+		owners[alias.Name] = generated
+	}
+	return nil
+}
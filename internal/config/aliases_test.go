@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolAlias_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		alias   ToolAlias
+		message string
+	}{
+		{"valid", ToolAlias{Name: "list-users"}, ""},
+		{"keep original", ToolAlias{Name: "users", KeepOriginal: true}, ""},
+		{"missing name", ToolAlias{}, "name is required"},
+		{"invalid characters", ToolAlias{Name: "list users"}, `invalid name "list users"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.alias.Validate()
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestValidate_Aliases(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.Aliases = map[string]ToolAlias{
+		"get_api_v1_users": {Name: "list_users"},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Aliases["get_api_v2_users"] = ToolAlias{Name: "list_users"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `aliases[get_api_v2_users]: alias "list_users" is also used by get_api_v1_users`)
+
+	cfg.Aliases = map[string]ToolAlias{"get_api_v1_users": {}}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "aliases[get_api_v1_users]: name is required")
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultBasicAuthRealm is the realm sent to clients when none is configured
+const DefaultBasicAuthRealm = "mcpify"
+
+// bcryptHashLength is the length of an encoded bcrypt hash such as "$2y$10$..."
+const bcryptHashLength = 60
+
+// BasicAuthConfig protects the MCP endpoint with HTTP Basic auth
+type BasicAuthConfig struct {
+	Enabled bool            `yaml:"enabled" json:"enabled"`
+	Realm   string          `yaml:"realm" json:"realm"` // Realm shown by clients, "mcpify" by default
+	Users   []BasicAuthUser `yaml:"users" json:"users"`
+}
+
+// BasicAuthUser is a user allowed to call the MCP endpoint, with a plain or hashed password
+type BasicAuthUser struct {
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"password"`
+	// PasswordHash is the bcrypt hash of the password, e.g. "$2y$10$..." as written by
+	// "htpasswd -nB"
+	PasswordHash string `yaml:"password_hash" json:"password_hash"`
+}
+
+// PasswordValue returns the plain password, resolving secret provider references such as
+// "vault:path#key"
+func (u *BasicAuthUser) PasswordValue() (string, error) {
+	return resolveSecret(u.Password, "")
+}
+
+// RealmOrDefault returns the configured realm, or DefaultBasicAuthRealm
+func (b *BasicAuthConfig) RealmOrDefault() string {
+	if b.Realm != "" {
+		return b.Realm
+	}
+	return DefaultBasicAuthRealm
+}
+
+// Validate validates the BasicAuthConfig
+func (b *BasicAuthConfig) Validate() error {
+	if !b.Enabled {
+		return nil
+	}
+	if len(b.Users) == 0 {
+		return fmt.Errorf("at least one user is required")
+	}
+	seen := make(map[string]bool, len(b.Users))
+	for i, user := range b.Users {
+		if user.Username == "" || strings.Contains(user.Username, ":") {
+			return fmt.Errorf("users[%d]: username is required and cannot contain ':'", i)
+		}
+		if seen[user.Username] {
+			return fmt.Errorf("users[%d]: duplicate username %q", i, user.Username)
+		}
+		seen[user.Username] = true
+		if (user.Password == "") == (user.PasswordHash == "") {
+			return fmt.Errorf("users[%d]: set either password or password_hash", i)
+		}
+		if user.PasswordHash != "" {
+			if _, err := ParsePasswordHash(user.PasswordHash); err != nil {
+				return fmt.Errorf("users[%d]: %w", i, err)
+			}
+		} else if isSecretReference(user.Password) {
+			if _, err := user.PasswordValue(); err != nil {
+				return fmt.Errorf("users[%d]: invalid password: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ParsePasswordHash checks that hash is a bcrypt hash with the $2a$, $2b$ or $2y$ prefix, as
+// written by htpasswd, and returns it for bcrypt.CompareHashAndPassword
+func ParsePasswordHash(hash string) ([]byte, error) {
+	hashed := []byte(strings.TrimSpace(hash))
+	prefix := string(hashed[:min(len(hashed), 4)])
+	if prefix != "$2a$" && prefix != "$2b$" && prefix != "$2y$" {
+		return nil, fmt.Errorf("password_hash must be a bcrypt hash starting with $2a$, $2b$ or $2y$")
+	}
+	if _, err := bcrypt.Cost(hashed); err != nil || len(hashed) != bcryptHashLength {
+		return nil, fmt.Errorf("password_hash is not a valid bcrypt hash")
+	}
+	return hashed, nil
+}
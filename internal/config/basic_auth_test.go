@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBasicAuthConfig(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.Security.BasicAuth = BasicAuthConfig{
+		Enabled: true,
+		Users: []BasicAuthUser{
+			{Username: "alice", Password: "secret"},
+			{Username: "bob", PasswordHash: "$2y$10$zIuch55MiXK7e6gEUV7EReoKW68CKi3GiwLYfH2bb1q4rzKE/4Bk."},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, DefaultBasicAuthRealm, cfg.Security.BasicAuth.RealmOrDefault())
+	assert.Equal(t, Redacted, cfg.Redacted().Security.BasicAuth.Users[0].Password)
+	assert.Equal(t, "secret", cfg.Security.BasicAuth.Users[0].Password)
+
+	invalid := []BasicAuthConfig{
+		{Enabled: true},
+		{Enabled: true, Users: []BasicAuthUser{{Username: "alice"}}},
+		{Enabled: true, Users: []BasicAuthUser{{Username: "alice", Password: "a", PasswordHash: "$2y$10$zIuch55MiXK7e6gEUV7EReoKW68CKi3GiwLYfH2bb1q4rzKE/4Bk."}}},
+		{Enabled: true, Users: []BasicAuthUser{{Username: "alice", PasswordHash: "md5:5ebe2294ecd0e0f08eab7690d2a6ee69"}}},
+		{Enabled: true, Users: []BasicAuthUser{{Username: "alice", PasswordHash: "sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"}}},
+		{Enabled: true, Users: []BasicAuthUser{{Username: "alice", PasswordHash: "$2y$10$zIuch55MiXK7e6gEUV7ERe"}}},
+		{Enabled: true, Users: []BasicAuthUser{{Username: "alice", PasswordHash: "$2x$10$zIuch55MiXK7e6gEUV7EReoKW68CKi3GiwLYfH2bb1q4rzKE/4Bk."}}},
+		{Enabled: true, Users: []BasicAuthUser{{Username: "a:b", Password: "a"}}},
+		{Enabled: true, Users: []BasicAuthUser{{Username: "alice", Password: "a"}, {Username: "alice", Password: "b"}}},
+	}
+	for _, basicAuth := range invalid {
+		cfg.Security.BasicAuth = basicAuth
+		err := cfg.Validate()
+		require.Error(t, err, "%+v", basicAuth)
+		assert.Contains(t, err.Error(), "invalid basic_auth")
+	}
+
+	cfg.Security.BasicAuth = BasicAuthConfig{Enabled: true, Users: []BasicAuthUser{{Username: "alice", Password: "a"}}}
+	cfg.Security.JWT = JWTConfig{Enabled: true, JWKSURL: "https://issuer.example.com/jwks.json"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "jwt")
+}
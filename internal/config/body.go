@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BodyFieldConfig represents a field merged into the JSON body of every upstream request that
+// sends one. Name is a dot-separated path such as "caller.id"; the field takes the same value,
+// values and valueFrom forms as a header.
+type BodyFieldConfig struct {
+	Name      string   `yaml:"name" json:"name"`
+	Value     string   `yaml:"value,omitempty" json:"value,omitempty"`
+	Values    []string `yaml:"values,omitempty" json:"values,omitempty"`
+	ValueFrom string   `yaml:"valueFrom,omitempty" json:"valueFrom,omitempty"`
+	// When limits the requests the value is sent with
+	When *HeaderCondition `yaml:"when,omitempty" json:"when,omitempty"`
+}
+
+// Validate validates the BodyFieldConfig
+func (b *BodyFieldConfig) Validate() error {
+	if err := (*HeaderConfig)(b).validate("body field"); err != nil {
+		return err
+	}
+	for _, segment := range strings.Split(b.Name, ".") {
+		if segment == "" {
+			return fmt.Errorf("body field %s: empty path segment", b.Name)
+		}
+	}
+	return nil
+}
+
+// BodyItem represents a body field item in the configuration
+type BodyItem struct {
+	Field BodyFieldConfig `yaml:"field" json:"field"`
+}
+
+// BodyConfig represents a list of body field configurations
+type BodyConfig []BodyItem
+
+// Validate validates the BodyConfig
+func (b BodyConfig) Validate() error {
+	seen := make(map[string]bool)
+	for _, item := range b {
+		if err := item.Field.Validate(); err != nil {
+			return err
+		}
+		if seen[item.Field.Name] {
+			return fmt.Errorf("duplicate body field name: %s", item.Field.Name)
+		}
+		seen[item.Field.Name] = true
+	}
+	return nil
+}
+
+// EvaluateBody evaluates configured body fields against the request context, keyed by field
+// path. valueFrom fields keep the JSON type of the selected value, static values are strings
+// and values are lists of strings. Fields without a value are omitted.
+func (e *RequestEvaluator) EvaluateBody(body BodyConfig, requestContext RequestContext) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for _, item := range body {
+		field := item.Field
+		if !field.When.matches(requestContext) {
+			continue
+		}
+		if field.ValueFrom != "" {
+			expr, err := compileExpression(field.ValueFrom)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate body field %s: %w", field.Name, err)
+			}
+			if value, found := expr.Evaluate(requestContext); found {
+				result[field.Name] = value
+			}
+			continue
+		}
+
+		values, err := (*HeaderConfig)(&field).evaluate(requestContext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate body field %s: %w", field.Name, err)
+		}
+		switch {
+		case len(values) == 0:
+		case field.Value != "":
+			result[field.Name] = values[0]
+		default:
+			list := make([]interface{}, len(values))
+			for i, value := range values {
+				list[i] = value
+			}
+			result[field.Name] = list
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyConfig_Validate(t *testing.T) {
+	field := func(name, value, valueFrom string) BodyItem {
+		return BodyItem{Field: BodyFieldConfig{Name: name, Value: value, ValueFrom: valueFrom}}
+	}
+
+	tests := []struct {
+		name    string
+		body    BodyConfig
+		message string
+	}{
+		{"valid", BodyConfig{field("caller.id", "", "request.claims.sub"), field("source", "mcpify", "")}, ""},
+		{"missing name", BodyConfig{field("", "mcpify", "")}, "body field name is required"},
+		{"empty path segment", BodyConfig{field("caller..id", "x", "")}, "body field caller..id: empty path segment"},
+		{"malformed valueFrom", BodyConfig{field("caller", "", "claims[")}, "body field caller: invalid valueFrom expression"},
+		{"duplicate name", BodyConfig{field("source", "a", ""), field("source", "b", "")}, "duplicate body field name: source"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.body.Validate()
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestRequestEvaluator_EvaluateBody(t *testing.T) {
+	body := BodyConfig{
+		{Field: BodyFieldConfig{Name: "caller.id", ValueFrom: "request.claims.sub"}},
+		{Field: BodyFieldConfig{Name: "caller.roles", ValueFrom: "request.claims.roles"}},
+		{Field: BodyFieldConfig{Name: "caller.tenant", ValueFrom: "request.claims.tenant"}},
+		{Field: BodyFieldConfig{Name: "source", Value: "mcpify {{ request.method }}"}},
+		{Field: BodyFieldConfig{Name: "tags", Values: []string{"a", "b"}}},
+	}
+	ctx := RequestContext{
+		Method: "POST",
+		Claims: map[string]interface{}{"sub": "user-1", "roles": []interface{}{"admin"}},
+	}
+
+	result, err := NewRequestEvaluator().EvaluateBody(body, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"caller.id":    "user-1",
+		"caller.roles": []interface{}{"admin"},
+		"source":       "mcpify POST",
+		"tags":         []interface{}{"a", "b"},
+	}, result)
+}
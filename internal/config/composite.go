@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CompositeTool exposes a sequence of calls to generated tools as a single MCP tool, e.g. a
+// search followed by fetching the details of the first match
+type CompositeTool struct {
+	Description string              `yaml:"description" json:"description"`
+	Arguments   []CompositeArgument `yaml:"arguments" json:"arguments"` // Inputs, read by steps as params
+	Steps       []CompositeStep     `yaml:"steps" json:"steps"`
+	// Output is a valueFrom expression selecting the result, e.g. "steps.details.body";
+	// the result of the last step is returned when empty
+	Output string `yaml:"output" json:"output"`
+}
+
+// CompositeArgument is an input of a composite tool
+type CompositeArgument struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	Type        string `yaml:"type" json:"type"` // JSON Schema type, string by default
+	Required    bool   `yaml:"required" json:"required"`
+}
+
+// CompositeStep calls one generated tool. Its arguments are valueFrom expressions that can
+// read the composite tool's arguments as params and earlier results as steps.<name>, e.g.
+// "steps.search.body.items[0].id".
+type CompositeStep struct {
+	Name      string            `yaml:"name" json:"name"`
+	Tool      string            `yaml:"tool" json:"tool"`
+	Arguments map[string]string `yaml:"arguments" json:"arguments"`
+}
+
+// compositeArgumentTypes are the accepted argument types
+var compositeArgumentTypes = map[string]bool{
+	"": true, "string": true, "number": true, "integer": true, "boolean": true, "object": true, "array": true,
+}
+
+// Validate validates the CompositeTool
+func (c *CompositeTool) Validate() error {
+	for _, argument := range c.Arguments {
+		if argument.Name == "" {
+			return errors.New("argument name is required")
+		}
+		if !compositeArgumentTypes[argument.Type] {
+			return fmt.Errorf("argument %s: invalid type %q", argument.Name, argument.Type)
+		}
+	}
+
+	if len(c.Steps) == 0 {
+		return errors.New("at least one step is required")
+	}
+	names := make(map[string]bool, len(c.Steps))
+	for i, step := range c.Steps {
+		switch {
+		case step.Name == "":
+			return fmt.Errorf("steps[%d]: name is required", i)
+		case names[step.Name]:
+			return fmt.Errorf("steps[%d]: duplicate step name %q", i, step.Name)
+		case step.Tool == "":
+			return fmt.Errorf("step %s: tool is required", step.Name)
+		}
+		names[step.Name] = true
+		for name, source := range step.Arguments {
+			if _, err := compileExpression(source); err != nil {
+				return fmt.Errorf("step %s: argument %s: %w", step.Name, name, err)
+			}
+		}
+	}
+
+	if c.Output != "" {
+		if _, err := compileExpression(c.Output); err != nil {
+			return fmt.Errorf("output: %w", err)
+		}
+	}
+	return nil
+}
+
+// EvaluateArguments returns the arguments of the step. Arguments whose expression selects
+// nothing are omitted, so the tool's own required checks apply.
+func (s *CompositeStep) EvaluateArguments(requestContext RequestContext) (map[string]interface{}, error) {
+	return EvaluateFields(s.Arguments, requestContext)
+}
+
+// EvaluateOutput returns the value the output expression selects, or nil when it selects nothing
+func (c *CompositeTool) EvaluateOutput(requestContext RequestContext) (interface{}, error) {
+	expr, err := compileExpression(c.Output)
+	if err != nil {
+		return nil, err
+	}
+	value, _ := expr.Evaluate(requestContext)
+	return value, nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompositeTool_Validate(t *testing.T) {
+	step := CompositeStep{Name: "search", Tool: "search_users", Arguments: map[string]string{"q": "params.query"}}
+
+	tests := []struct {
+		name      string
+		composite CompositeTool
+		message   string
+	}{
+		{"valid", CompositeTool{Steps: []CompositeStep{step}, Output: "steps.search.body"}, ""},
+		{"no steps", CompositeTool{}, "at least one step is required"},
+		{"missing step name", CompositeTool{Steps: []CompositeStep{{Tool: "search_users"}}}, "steps[0]: name is required"},
+		{"duplicate step", CompositeTool{Steps: []CompositeStep{step, step}}, `steps[1]: duplicate step name "search"`},
+		{"missing tool", CompositeTool{Steps: []CompositeStep{{Name: "search"}}}, "step search: tool is required"},
+		{"invalid argument", CompositeTool{Steps: []CompositeStep{{Name: "search", Tool: "search_users", Arguments: map[string]string{"q": "cookies.q"}}}}, "step search: argument q"},
+		{"invalid output", CompositeTool{Steps: []CompositeStep{step}, Output: "steps["}, "output:"},
+		{"invalid argument type", CompositeTool{Arguments: []CompositeArgument{{Name: "query", Type: "date"}}, Steps: []CompositeStep{step}}, `argument query: invalid type "date"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.composite.Validate()
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestCompositeStep_EvaluateArguments(t *testing.T) {
+	step := CompositeStep{Name: "details", Tool: "get_user", Arguments: map[string]string{
+		"id":     "steps.search.body.items[0].id",
+		"fields": "params.fields",
+		"locale": "params.locale",
+	}}
+	requestContext := RequestContext{
+		Params: map[string]interface{}{"fields": "name,email"},
+		Steps: map[string]interface{}{
+			"search": map[string]interface{}{"body": map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 42.0}}}},
+		},
+	}
+
+	arguments, err := step.EvaluateArguments(requestContext)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": 42.0, "fields": "name,email"}, arguments, "values keep their type and missing ones are omitted")
+
+	composite := CompositeTool{Output: "steps.search.body.items"}
+	output, err := composite.EvaluateOutput(requestContext)
+	require.NoError(t, err)
+	assert.Len(t, output, 1)
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HeaderCondition limits when a header, query parameter or body field is sent. All of the
+// configured conditions must hold.
+type HeaderCondition struct {
+	// Present is a valueFrom expression that must have a value, e.g. request.headers['x-tenant']
+	Present string `yaml:"present,omitempty" json:"present,omitempty"`
+	// Methods are the HTTP methods of the upstream operations the value is sent to
+	Methods []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+	// Paths are upstream operation path patterns, where "*" matches any sequence of characters
+	Paths []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+}
+
+// OperationContext identifies the upstream operation a request is built for
+type OperationContext struct {
+	Method string
+	Path   string
+}
+
+// Validate validates the HeaderCondition
+func (c *HeaderCondition) Validate() error {
+	if c.Present == "" && len(c.Methods) == 0 && len(c.Paths) == 0 {
+		return fmt.Errorf("when must set at least one of 'present', 'methods' or 'paths'")
+	}
+	if c.Present != "" {
+		if _, err := compileExpression(c.Present); err != nil {
+			return fmt.Errorf("when.present: %w", err)
+		}
+	}
+	for _, method := range c.Methods {
+		if method == "" {
+			return fmt.Errorf("when.methods cannot contain empty entries")
+		}
+	}
+	for _, path := range c.Paths {
+		if path == "" {
+			return fmt.Errorf("when.paths cannot contain empty entries")
+		}
+	}
+	return nil
+}
+
+// matches reports whether the conditions hold for a request. A nil condition always holds;
+// method and path conditions never hold when the operation is unknown, as when fetching a spec.
+func (c *HeaderCondition) matches(requestContext RequestContext) bool {
+	if c == nil {
+		return true
+	}
+
+	if c.Present != "" {
+		expr, err := compileExpression(c.Present)
+		if err != nil {
+			return false
+		}
+		if value, err := expr.EvaluateString(requestContext); err != nil || value == "" {
+			return false
+		}
+	}
+
+	operation := requestContext.Operation
+	if len(c.Methods) > 0 {
+		if operation == nil || !containsFold(c.Methods, operation.Method) {
+			return false
+		}
+	}
+	if len(c.Paths) > 0 {
+		if operation == nil {
+			return false
+		}
+		matched := false
+		for _, pattern := range c.Paths {
+			matched = matched || MatchPath(pattern, operation.Path)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
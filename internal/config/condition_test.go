@@ -0,0 +1,95 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestHeaderCondition_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition HeaderCondition
+		message   string
+	}{
+		{"present", HeaderCondition{Present: "request.headers['x-tenant']"}, ""},
+		{"methods and paths", HeaderCondition{Methods: []string{"POST"}, Paths: []string{"/orders/*"}}, ""},
+		{"empty", HeaderCondition{}, "when must set at least one of 'present', 'methods' or 'paths'"},
+		{"malformed present", HeaderCondition{Present: "request.headers["}, "when.present: invalid valueFrom expression"},
+		{"empty method", HeaderCondition{Methods: []string{""}}, "when.methods cannot contain empty entries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.condition.Validate()
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestHeaderCondition_Matches(t *testing.T) {
+	ctx := NewRequestContextFromMap(map[string]string{"X-Tenant": "acme"}, nil, nil, "POST", "/mcp")
+	ctx.Operation = &OperationContext{Method: "POST", Path: "/orders/{id}"}
+
+	tests := []struct {
+		name      string
+		condition *HeaderCondition
+		context   RequestContext
+		expected  bool
+	}{
+		{"no condition", nil, ctx, true},
+		{"present header", &HeaderCondition{Present: "request.headers['x-tenant']"}, ctx, true},
+		{"absent header", &HeaderCondition{Present: "request.headers['x-region']"}, ctx, false},
+		{"matching method", &HeaderCondition{Methods: []string{"get", "post"}}, ctx, true},
+		{"other method", &HeaderCondition{Methods: []string{"GET"}}, ctx, false},
+		{"matching path", &HeaderCondition{Paths: []string{"/users", "/orders/*"}}, ctx, true},
+		{"other path", &HeaderCondition{Paths: []string{"/users/*"}}, ctx, false},
+		{"all conditions", &HeaderCondition{Present: "headers['x-tenant']", Methods: []string{"POST"}, Paths: []string{"/orders/*"}}, ctx, true},
+		{"unknown operation", &HeaderCondition{Methods: []string{"POST"}}, RequestContext{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.condition.matches(tt.context))
+		})
+	}
+}
+
+func TestHeaderConfig_When(t *testing.T) {
+	var headers HeadersConfig
+	err := yaml.Unmarshal([]byte(`
+- header:
+    name: X-Tenant
+    valueFrom: "request.headers['x-tenant']"
+- header:
+    name: Idempotency-Key
+    value: "{{ request.headers['x-request-id'] }}"
+    when:
+      methods: [POST]
+      paths: ["/orders*"]
+`), &headers)
+	require.NoError(t, err)
+	require.NotNil(t, headers[1].Header.When)
+
+	ctx := NewRequestContextFromMap(map[string]string{"X-Tenant": "acme", "X-Request-Id": "req-1"}, nil, nil, "POST", "/mcp")
+	for _, tt := range []struct {
+		operation OperationContext
+		expected  map[string]string
+	}{
+		{OperationContext{Method: "POST", Path: "/orders"}, map[string]string{"X-Tenant": "acme", "Idempotency-Key": "req-1"}},
+		{OperationContext{Method: "GET", Path: "/orders"}, map[string]string{"X-Tenant": "acme"}},
+	} {
+		operation := tt.operation
+		ctx.Operation = &operation
+		result, err := NewRequestEvaluator().EvaluateHeaders(headers, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, result)
+	}
+}
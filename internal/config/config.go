@@ -10,25 +10,79 @@ import (
 
 // Config represents the complete server configuration
 type Config struct {
-	Server   ServerConfig   `yaml:"server" json:"server"`
-	Logging  LoggingConfig  `yaml:"logging" json:"logging"`
-	OpenAPI  OpenAPIConfig  `yaml:"openapi" json:"openapi"`
-	Security SecurityConfig `yaml:"security" json:"security"`
+	Server  ServerConfig  `yaml:"server" json:"server"`
+	Logging LoggingConfig `yaml:"logging" json:"logging"`
+	OpenAPI OpenAPIConfig `yaml:"openapi" json:"openapi"`
+	// APIs lists additional upstream APIs served alongside (or instead of) the openapi block
+	APIs     []OpenAPIConfig `yaml:"apis" json:"apis"`
+	Security SecurityConfig  `yaml:"security" json:"security"`
+	// Tools customizes generated tools, keyed by tool name or path pattern
+	Tools map[string]ToolOverride `yaml:"tools" json:"tools"`
+	// Profiles holds named partial configurations overlaid on these settings when selected
+	Profiles map[string]map[string]interface{} `yaml:"profiles" json:"profiles"`
+	// Aliases renames generated tools, keyed by generated tool name
+	Aliases map[string]ToolAlias `yaml:"aliases" json:"aliases"`
+	// Composites defines tools that chain calls to generated tools, keyed by tool name
+	Composites map[string]CompositeTool `yaml:"composites" json:"composites"`
+	// Extensions lists Go plugins that hook into tool calls and upstream requests, run in order
+	Extensions []ExtensionConfig `yaml:"extensions" json:"extensions"`
+	// Retry is the retry policy of upstream requests for every API
+	Retry RetryConfig `yaml:"retry" json:"retry"`
+	// ErrorMappings set the MCP errors of failed tool calls; the first matching mapping wins
+	// over the built-in categories
+	ErrorMappings []ErrorMapping `yaml:"error_mappings" json:"error_mappings"`
+	// Descriptions replaces or extends tool descriptions with those of a locale
+	Descriptions DescriptionsConfig `yaml:"descriptions" json:"descriptions"`
+	// MetaTools registers built-in tools that describe the generated tools
+	MetaTools MetaToolsConfig `yaml:"meta_tools" json:"meta_tools"`
+	// ToolSize caps the descriptions and schema depth of generated tools
+	ToolSize ToolSizeConfig `yaml:"tool_size" json:"tool_size"`
+	// Redis is the server replicas share sessions and rate limits through
+	Redis RedisConfig `yaml:"redis" json:"redis"`
+}
+
+// APIConfigs returns every upstream API served by this instance: the openapi block
+// when it has a spec, followed by the entries of apis
+func (c *Config) APIConfigs() []*OpenAPIConfig {
+	apis := make([]*OpenAPIConfig, 0, len(c.APIs)+1)
+	if c.OpenAPI.SpecPath != "" {
+		apis = append(apis, &c.OpenAPI)
+	}
+	for i := range c.APIs {
+		apis = append(apis, &c.APIs[i])
+	}
+	return apis
 }
 
 // ServerConfig contains server-specific configuration
 type ServerConfig struct {
-	Transport string     `yaml:"transport" json:"transport"`
-	HTTP      HTTPConfig `yaml:"http" json:"http"`
+	Transport string      `yaml:"transport" json:"transport"`
+	HTTP      HTTPConfig  `yaml:"http" json:"http"`
+	Stdio     StdioConfig `yaml:"stdio" json:"stdio"`
+	// MaxResponseMemory caps the upstream response bytes buffered by all tool calls at once
+	// (e.g. "256MB"); calls wait for room instead of exhausting memory. Empty means no cap.
+	MaxResponseMemory string `yaml:"max_response_memory" json:"max_response_memory"`
+	// Admin serves the admin HTTP API on a separate listener
+	Admin AdminConfig `yaml:"admin" json:"admin"`
+	// Watch reloads the configuration when its files change
+	Watch WatchConfig `yaml:"watch" json:"watch"`
+	// Shutdown controls draining on SIGTERM
+	Shutdown ShutdownConfig `yaml:"shutdown" json:"shutdown"`
+	// LazyStart serves clients at once and parses the specifications in the background,
+	// with the mcpify_status tool reporting progress until the tools are ready
+	LazyStart bool `yaml:"lazy_start" json:"lazy_start"`
 }
 
 // HTTPConfig contains MCP-compliant HTTP transport configuration
 type HTTPConfig struct {
-	Host           string        `yaml:"host" json:"host"`
-	Port           int           `yaml:"port" json:"port"`
-	SessionTimeout time.Duration `yaml:"session_timeout" json:"session_timeout"`
-	MaxConnections int           `yaml:"max_connections" json:"max_connections"`
-	CORS           CORSConfig    `yaml:"cors" json:"cors"`
+	Host           string          `yaml:"host" json:"host"`
+	Port           int             `yaml:"port" json:"port"`
+	SessionTimeout time.Duration   `yaml:"session_timeout" json:"session_timeout"`
+	MaxConnections int             `yaml:"max_connections" json:"max_connections"`
+	CORS           CORSConfig      `yaml:"cors" json:"cors"`
+	TLS            ServerTLSConfig `yaml:"tls" json:"tls"`
+	Docs           DocsConfig      `yaml:"docs" json:"docs"`
+	Sessions       SessionsConfig  `yaml:"sessions" json:"sessions"`
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling for HTTPConfig
@@ -56,12 +110,6 @@ func (h *HTTPConfig) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// CORSConfig contains CORS configuration
-type CORSConfig struct {
-	Enabled bool     `yaml:"enabled" json:"enabled"`
-	Origins []string `yaml:"origins" json:"origins"`
-}
-
 // LoggingConfig contains logging configuration
 type LoggingConfig struct {
 	Level  string `yaml:"level" json:"level"`
@@ -71,17 +119,23 @@ type LoggingConfig struct {
 
 // HeaderConfig represents a single header configuration
 type HeaderConfig struct {
-	Name      string `yaml:"name" json:"name"`
-	Value     string `yaml:"value,omitempty" json:"value,omitempty"`
-	ValueFrom string `yaml:"valueFrom,omitempty" json:"valueFrom,omitempty"`
+	Name  string `yaml:"name" json:"name"`
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+	// Values sends the header once per entry, e.g. several Accept or Cookie lines
+	Values    []string `yaml:"values,omitempty" json:"values,omitempty"`
+	ValueFrom string   `yaml:"valueFrom,omitempty" json:"valueFrom,omitempty"`
+	// When limits the requests the value is sent with
+	When *HeaderCondition `yaml:"when,omitempty" json:"when,omitempty"`
 }
 
 // UnmarshalYAML implements custom YAML unmarshaling for HeaderConfig
 func (h *HeaderConfig) UnmarshalYAML(value *yaml.Node) error {
 	var aux struct {
-		Name      string `yaml:"name"`
-		Value     string `yaml:"value,omitempty"`
-		ValueFrom string `yaml:"valueFrom,omitempty"`
+		Name      string           `yaml:"name"`
+		Value     string           `yaml:"value,omitempty"`
+		Values    []string         `yaml:"values,omitempty"`
+		ValueFrom string           `yaml:"valueFrom,omitempty"`
+		When      *HeaderCondition `yaml:"when,omitempty"`
 	}
 
 	if err := value.Decode(&aux); err != nil {
@@ -90,7 +144,9 @@ func (h *HeaderConfig) UnmarshalYAML(value *yaml.Node) error {
 
 	h.Name = aux.Name
 	h.Value = aux.Value
+	h.Values = aux.Values
 	h.ValueFrom = aux.ValueFrom
+	h.When = aux.When
 
 	return h.Validate()
 }
@@ -98,9 +154,11 @@ func (h *HeaderConfig) UnmarshalYAML(value *yaml.Node) error {
 // UnmarshalJSON implements custom JSON unmarshaling for HeaderConfig
 func (h *HeaderConfig) UnmarshalJSON(data []byte) error {
 	var aux struct {
-		Name      string `json:"name"`
-		Value     string `json:"value,omitempty"`
-		ValueFrom string `json:"valueFrom,omitempty"`
+		Name      string           `json:"name"`
+		Value     string           `json:"value,omitempty"`
+		Values    []string         `json:"values,omitempty"`
+		ValueFrom string           `json:"valueFrom,omitempty"`
+		When      *HeaderCondition `json:"when,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &aux); err != nil {
@@ -109,31 +167,72 @@ func (h *HeaderConfig) UnmarshalJSON(data []byte) error {
 
 	h.Name = aux.Name
 	h.Value = aux.Value
+	h.Values = aux.Values
 	h.ValueFrom = aux.ValueFrom
+	h.When = aux.When
 
 	return h.Validate()
 }
 
 // Validate validates the HeaderConfig
 func (h *HeaderConfig) Validate() error {
+	return h.validate("header")
+}
+
+// validate checks the name and value forms of a header, or of a query parameter which takes
+// the same forms, naming it kind in errors
+func (h *HeaderConfig) validate(kind string) error {
 	if h.Name == "" {
-		return fmt.Errorf("header name is required")
+		return fmt.Errorf("%s name is required", kind)
 	}
 
 	hasValue := h.Value != ""
+	hasValues := len(h.Values) > 0
 	hasValueFrom := h.ValueFrom != ""
 
-	if !hasValue && !hasValueFrom {
-		return fmt.Errorf("header must have either 'value' or 'valueFrom'")
+	if !hasValue && !hasValues && !hasValueFrom {
+		return fmt.Errorf("%s must have either 'value', 'values' or 'valueFrom'", kind)
 	}
 
 	if hasValue && hasValueFrom {
-		return fmt.Errorf("header cannot have both 'value' and 'valueFrom'")
+		return fmt.Errorf("%s cannot have both 'value' and 'valueFrom'", kind)
+	}
+	if hasValues && (hasValue || hasValueFrom) {
+		return fmt.Errorf("%s cannot combine 'values' with 'value' or 'valueFrom'", kind)
+	}
+
+	if hasValueFrom {
+		if _, err := compileExpression(h.ValueFrom); err != nil {
+			return fmt.Errorf("%s %s: %w", kind, h.Name, err)
+		}
+	}
+	for _, value := range h.staticValues() {
+		if value == "" {
+			return fmt.Errorf("%s %s: values cannot be empty", kind, h.Name)
+		}
+		if IsTemplate(value) {
+			if _, err := compileTemplate(value); err != nil {
+				return fmt.Errorf("%s %s: %w", kind, h.Name, err)
+			}
+		}
+	}
+	if h.When != nil {
+		if err := h.When.Validate(); err != nil {
+			return fmt.Errorf("%s %s: %w", kind, h.Name, err)
+		}
 	}
 
 	return nil
 }
 
+// staticValues returns the configured value or values, which may be templates
+func (h *HeaderConfig) staticValues() []string {
+	if h.Value != "" {
+		return []string{h.Value}
+	}
+	return h.Values
+}
+
 // HeaderItem represents a header item in the configuration
 type HeaderItem struct {
 	Header HeaderConfig `yaml:"header" json:"header"`
@@ -244,12 +343,57 @@ type OpenAPIConfig struct {
 	BaseURL      string        `yaml:"base_url" json:"base_url"`
 	Auth         AuthConfig    `yaml:"auth" json:"auth"`
 	Headers      HeadersConfig `yaml:"headers" json:"headers"`
+	Query        QueryConfig   `yaml:"query" json:"query"`
+	Body         BodyConfig    `yaml:"body" json:"body"`
 	Timeout      time.Duration `yaml:"timeout" json:"timeout"`
 	MaxRetries   int           `yaml:"max_retries" json:"max_retries"`
 	ToolPrefix   string        `yaml:"tool_prefix" json:"tool_prefix"`
 	ExcludePaths []string      `yaml:"exclude_paths" json:"exclude_paths"`
 	IncludePaths []string      `yaml:"include_paths" json:"include_paths"`
 	Debug        bool          `yaml:"debug" json:"debug"`
+	// MaxResponseSize is a hard cap on upstream response bodies (e.g. "10MB");
+	// larger responses abort the tool call instead of being buffered in memory
+	MaxResponseSize string `yaml:"max_response_size" json:"max_response_size"`
+	// TLS pins the TLS policy used when connecting to the upstream API
+	TLS TLSConfig `yaml:"tls" json:"tls"`
+	// Connections tunes the pool of connections to the upstream API
+	Connections ConnectionConfig `yaml:"connections" json:"connections"`
+	// DNS selects the DNS servers and the resolution cache for the upstream API's hosts
+	DNS DNSConfig `yaml:"dns" json:"dns"`
+	// Tenants selects the upstream instance of each request in multi-tenant deployments
+	Tenants TenantsConfig `yaml:"tenants" json:"tenants"`
+	// JSONAPI follows the JSON:API conventions: responses are flattened and bracketed query
+	// parameters such as fields[articles] are grouped into one object argument
+	JSONAPI bool `yaml:"json_api" json:"json_api"`
+	// Uploads controls the files sent by multipart operations
+	Uploads UploadsConfig `yaml:"uploads" json:"uploads"`
+	// Servers sends the operations of some paths or tags to another base URL; the first
+	// matching route wins over base_url and the servers the spec declares for the operation
+	Servers []ServerRoute `yaml:"servers" json:"servers"`
+	// Failover lists secondary base URLs used while base_url is down
+	Failover FailoverConfig `yaml:"failover" json:"failover"`
+	// ResponseHeaders lists the upstream response headers returned to clients, as
+	// case-insensitive names where "*" matches any characters; DefaultResponseHeaders when empty
+	ResponseHeaders []string `yaml:"response_headers" json:"response_headers"`
+	// Summarize shrinks JSON responses above a size instead of returning them whole
+	Summarize SummarizeConfig `yaml:"summarize" json:"summarize"`
+	// SpecFetch retries fetching a remote spec_path and falls back to a cached copy
+	SpecFetch SpecFetchConfig `yaml:"spec_fetch" json:"spec_fetch"`
+}
+
+// DefaultResponseHeaders are the response headers returned to clients unless configured:
+// the content type, rate limits and pagination
+var DefaultResponseHeaders = []string{
+	"Content-Type", "Retry-After", "RateLimit*", "X-RateLimit-*", "X-Rate-Limit-*",
+	"Link", "X-Total*", "X-Page*", "X-Per-Page", "X-Next-*", "X-Prev-*",
+}
+
+// ResponseHeadersOrDefault returns the response headers returned to clients
+func (o *OpenAPIConfig) ResponseHeadersOrDefault() []string {
+	if len(o.ResponseHeaders) > 0 {
+		return o.ResponseHeaders
+	}
+	return DefaultResponseHeaders
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling for OpenAPIConfig
@@ -279,26 +423,72 @@ func (o *OpenAPIConfig) UnmarshalJSON(data []byte) error {
 
 // AuthConfig contains authentication configuration
 type AuthConfig struct {
-	Type       string        `yaml:"type" json:"type"` // "none", "bearer", "basic", "api_key"
-	Token      string        `yaml:"token" json:"token"`
-	Username   string        `yaml:"username" json:"username"`
-	Password   string        `yaml:"password" json:"password"`
-	APIKey     string        `yaml:"api_key" json:"api_key"`
-	APIKeyName string        `yaml:"api_key_name" json:"api_key_name"`
-	APIKeyIn   string        `yaml:"api_key_in" json:"api_key_in"` // "header", "query"
-	Headers    HeadersConfig `yaml:"headers" json:"headers"`
+	Type         string        `yaml:"type" json:"type"` // "none", "bearer", "basic", "api_key"
+	Token        string        `yaml:"token" json:"token"`
+	TokenFile    string        `yaml:"token_file" json:"token_file"` // Read the token from a file instead
+	Username     string        `yaml:"username" json:"username"`
+	Password     string        `yaml:"password" json:"password"`
+	PasswordFile string        `yaml:"password_file" json:"password_file"` // Read the password from a file instead
+	APIKey       string        `yaml:"api_key" json:"api_key"`
+	APIKeyFile   string        `yaml:"api_key_file" json:"api_key_file"` // Read the API key from a file instead
+	APIKeyName   string        `yaml:"api_key_name" json:"api_key_name"`
+	APIKeyIn     string        `yaml:"api_key_in" json:"api_key_in"` // "header", "query"
+	Headers      HeadersConfig `yaml:"headers" json:"headers"`
 }
 
 // SecurityConfig contains security configuration
 type SecurityConfig struct {
 	RateLimiting     RateLimitingConfig `yaml:"rate_limiting" json:"rate_limiting"`
-	RequestSizeLimit string             `yaml:"request_size_limit" json:"request_size_limit"`
+	RequestSizeLimit string             `yaml:"request_size_limit" json:"request_size_limit"` // Largest HTTP request body and tool call arguments, e.g. "1MB"; empty for no limit
+	JWT              JWTConfig          `yaml:"jwt" json:"jwt"`
+	BasicAuth        BasicAuthConfig    `yaml:"basic_auth" json:"basic_auth"`
+	// TrustedProxies are the IP addresses and CIDR ranges of the reverse proxies in front of
+	// mcpify; X-Forwarded-For and X-Real-IP are only believed from these peers
+	TrustedProxies []string `yaml:"trusted_proxies" json:"trusted_proxies"`
+}
+
+// RequestSizeLimitBytes returns the request size limit in bytes, or 0 when there is no limit
+func (s *SecurityConfig) RequestSizeLimitBytes() int64 {
+	if s.RequestSizeLimit == "" {
+		return 0
+	}
+	size, _ := ParseSize(s.RequestSizeLimit)
+	return size
 }
 
-// RateLimitingConfig contains rate limiting configuration
-type RateLimitingConfig struct {
-	Enabled           bool `yaml:"enabled" json:"enabled"`
-	RequestsPerMinute int  `yaml:"requests_per_minute" json:"requests_per_minute"`
+// JWTConfig contains validation settings for JWTs presented by MCP HTTP clients
+type JWTConfig struct {
+	Enabled       bool          `yaml:"enabled" json:"enabled"`
+	JWKSURL       string        `yaml:"jwks_url" json:"jwks_url"`
+	Issuer        string        `yaml:"issuer" json:"issuer"`
+	Audience      string        `yaml:"audience" json:"audience"`
+	Leeway        time.Duration `yaml:"leeway" json:"leeway"`                 // Allowed clock skew for exp/nbf checks
+	RequireExpiry bool          `yaml:"require_expiry" json:"require_expiry"` // Reject tokens without an exp claim
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for JWTConfig
+func (j *JWTConfig) UnmarshalJSON(data []byte) error {
+	type Alias JWTConfig
+	aux := &struct {
+		Leeway string `json:"leeway"`
+		*Alias
+	}{
+		Alias: (*Alias)(j),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Leeway != "" {
+		duration, err := time.ParseDuration(aux.Leeway)
+		if err != nil {
+			return err
+		}
+		j.Leeway = duration
+	}
+
+	return nil
 }
 
 // Default returns a configuration with default values
@@ -316,6 +506,10 @@ func Default() *Config {
 					Origins: []string{"http://localhost:3000", "http://127.0.0.1:3000"},
 				},
 			},
+			Admin: AdminConfig{
+				Host: "127.0.0.1",
+				Port: 9091,
+			},
 		},
 		Logging: LoggingConfig{
 			Level:  "info",
@@ -329,6 +523,8 @@ func Default() *Config {
 			MaxRetries: 3,
 			ToolPrefix: "",
 			Debug:      false,
+
+			MaxResponseSize: "10MB",
 			Auth: AuthConfig{
 				Type:    "none",
 				Headers: HeadersConfig{},
@@ -336,8 +532,9 @@ func Default() *Config {
 			Headers: HeadersConfig{},
 		},
 		Security: SecurityConfig{
+			// Rate limiting is opt-in: behind a proxy without trusted_proxies every client
+			// would share one limit
 			RateLimiting: RateLimitingConfig{
-				Enabled:           true,
 				RequestsPerMinute: 100,
 			},
 			RequestSizeLimit: "1MB",
@@ -355,7 +552,53 @@ func (c *Config) Validate() error {
 		return ErrInvalidPort
 	}
 
-	if c.OpenAPI.SpecPath == "" {
+	if err := c.Server.HTTP.TLS.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Server.Stdio.Validate(); err != nil {
+		return fmt.Errorf("invalid stdio: %w", err)
+	}
+
+	if err := c.Server.HTTP.CORS.Validate(); err != nil {
+		return fmt.Errorf("invalid cors: %w", err)
+	}
+
+	if err := c.Server.HTTP.Sessions.Validate(); err != nil {
+		return fmt.Errorf("invalid sessions: %w", err)
+	}
+
+	if c.Server.HTTP.Sessions.Store == SessionStoreRedis && c.Redis.IsZero() {
+		return fmt.Errorf("invalid sessions: the redis store requires redis.url")
+	}
+
+	if err := c.Redis.Validate(); err != nil {
+		return fmt.Errorf("invalid redis: %w", err)
+	}
+
+	if err := c.Server.HTTP.Docs.Validate(); err != nil {
+		return fmt.Errorf("invalid docs: %w", err)
+	}
+
+	if err := c.Server.Admin.Validate(); err != nil {
+		return fmt.Errorf("invalid admin: %w", err)
+	}
+
+	if err := c.Server.Watch.Validate(); err != nil {
+		return fmt.Errorf("invalid watch: %w", err)
+	}
+
+	if err := c.Server.Shutdown.Validate(); err != nil {
+		return fmt.Errorf("invalid shutdown: %w", err)
+	}
+
+	if c.Server.MaxResponseMemory != "" {
+		if size, err := ParseSize(c.Server.MaxResponseMemory); err != nil || size <= 0 {
+			return fmt.Errorf("invalid max_response_memory: %q", c.Server.MaxResponseMemory)
+		}
+	}
+
+	if c.OpenAPI.SpecPath == "" && len(c.APIs) == 0 {
 		return ErrMissingOpenAPISpec
 	}
 
@@ -367,20 +610,162 @@ func (c *Config) Validate() error {
 		return ErrInvalidMaxRetries
 	}
 
+	if err := c.Retry.Validate(); err != nil {
+		return fmt.Errorf("invalid retry: %w", err)
+	}
+
+	for i, mapping := range c.ErrorMappings {
+		if err := mapping.Validate(); err != nil {
+			return fmt.Errorf("invalid error_mappings[%d]: %w", i, err)
+		}
+	}
+
+	if err := c.Descriptions.Validate(); err != nil {
+		return fmt.Errorf("invalid descriptions: %w", err)
+	}
+
+	if err := c.MetaTools.Validate(); err != nil {
+		return fmt.Errorf("invalid meta_tools: %w", err)
+	}
+
+	if err := c.ToolSize.Validate(); err != nil {
+		return fmt.Errorf("invalid tool_size: %w", err)
+	}
+
 	if c.Security.RateLimiting.RequestsPerMinute < 1 {
 		return ErrInvalidRateLimit
 	}
 
+	if err := c.Security.RateLimiting.Validate(); err != nil {
+		return fmt.Errorf("invalid rate_limiting: %w", err)
+	}
+
+	if c.Security.RateLimiting.Enabled && c.Security.RateLimiting.Store == RateLimitStoreRedis && c.Redis.IsZero() {
+		return fmt.Errorf("invalid rate_limiting: the redis store requires redis.url")
+	}
+
+	if c.Security.RequestSizeLimit != "" {
+		if size, err := ParseSize(c.Security.RequestSizeLimit); err != nil || size <= 0 {
+			return fmt.Errorf("invalid request_size_limit: %q", c.Security.RequestSizeLimit)
+		}
+	}
+
+	if _, err := ParseTrustedProxies(c.Security.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid trusted_proxies: %w", err)
+	}
+
+	if err := c.Security.BasicAuth.Validate(); err != nil {
+		return fmt.Errorf("invalid basic_auth: %w", err)
+	}
+
+	if c.Security.BasicAuth.Enabled && c.Security.JWT.Enabled {
+		return fmt.Errorf("invalid basic_auth: cannot be enabled together with jwt, both use the Authorization header")
+	}
+
+	if c.Security.JWT.Enabled && c.Security.JWT.JWKSURL == "" {
+		return ErrMissingJWKSURL
+	}
+
 	// Validate OpenAPI config
 	if err := c.OpenAPI.Validate(); err != nil {
 		return err
 	}
 
+	// Validate additional APIs
+	for i := range c.APIs {
+		api := &c.APIs[i]
+		var err error
+		switch {
+		case api.SpecPath == "":
+			err = ErrMissingOpenAPISpec
+		case api.Timeout < 1*time.Second:
+			err = ErrInvalidTimeout
+		case api.MaxRetries < 0:
+			err = ErrInvalidMaxRetries
+		default:
+			err = api.Validate()
+		}
+		if err != nil {
+			return fmt.Errorf("apis[%d]: %w", i, err)
+		}
+	}
+
+	// Validate tool overrides
+	for key, override := range c.Tools {
+		if err := override.Validate(); err != nil {
+			return fmt.Errorf("tools[%s]: %w", key, err)
+		}
+	}
+
+	if err := validateAliases(c.Aliases); err != nil {
+		return err
+	}
+
+	for name, composite := range c.Composites {
+		if err := composite.Validate(); err != nil {
+			return fmt.Errorf("composites[%s]: %w", name, err)
+		}
+	}
+
+	for i := range c.Extensions {
+		if err := c.Extensions[i].Validate(); err != nil {
+			return fmt.Errorf("extensions[%d]: %w", i, err)
+		}
+	}
+
 	return nil
 }
 
 // Validate validates the OpenAPIConfig
 func (o *OpenAPIConfig) Validate() error {
+	if o.MaxResponseSize != "" {
+		if size, err := ParseSize(o.MaxResponseSize); err != nil || size <= 0 {
+			return fmt.Errorf("invalid max_response_size: %q", o.MaxResponseSize)
+		}
+	}
+
+	if _, err := o.TLS.Build(); err != nil {
+		return fmt.Errorf("invalid upstream TLS configuration: %w", err)
+	}
+
+	if err := o.Auth.Validate(); err != nil {
+		return fmt.Errorf("invalid auth: %w", err)
+	}
+
+	if err := o.Connections.Validate(); err != nil {
+		return fmt.Errorf("invalid connections: %w", err)
+	}
+
+	if err := o.DNS.Validate(); err != nil {
+		return fmt.Errorf("invalid dns: %w", err)
+	}
+
+	if err := o.Tenants.Validate(); err != nil {
+		return fmt.Errorf("invalid tenants: %w", err)
+	}
+
+	if err := o.Uploads.Validate(); err != nil {
+		return fmt.Errorf("invalid uploads: %w", err)
+	}
+
+	for _, server := range o.Servers {
+		if err := server.Validate(); err != nil {
+			return fmt.Errorf("invalid servers: %w", err)
+		}
+	}
+
+	if err := o.Failover.Validate(); err != nil {
+		return fmt.Errorf("invalid failover: %w", err)
+	}
+
+	if err := o.Summarize.Validate(); err != nil {
+		return fmt.Errorf("invalid summarize: %w", err)
+	}
+
+	if err := o.SpecFetch.Validate(); err != nil {
+		return fmt.Errorf("invalid spec_fetch: %w", err)
+	}
+
 	// Validate headers
 	if err := o.Headers.Validate(); err != nil {
 		return fmt.Errorf("invalid headers: %w", err)
@@ -391,6 +776,13 @@ func (o *OpenAPIConfig) Validate() error {
 		return fmt.Errorf("invalid auth headers: %w", err)
 	}
 
+	if err := o.Query.Validate(); err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+	if err := o.Body.Validate(); err != nil {
+		return fmt.Errorf("invalid body: %w", err)
+	}
+
 	// Check for duplicate header names between auth and general headers
 	authHeaderNames := make(map[string]bool)
 	for _, item := range o.Auth.Headers {
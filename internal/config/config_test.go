@@ -1,6 +1,9 @@
 package config
 
 import (
+	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -69,8 +72,8 @@ func TestDefault(t *testing.T) {
 	}
 
 	// Test security defaults
-	if !config.Security.RateLimiting.Enabled {
-		t.Error("Expected default rate limiting to be enabled")
+	if config.Security.RateLimiting.Enabled {
+		t.Error("Expected rate limiting to be disabled by default")
 	}
 
 	if config.Security.RateLimiting.RequestsPerMinute != 100 {
@@ -223,6 +226,75 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errType: ErrInvalidRateLimit,
 		},
+		{
+			name: "invalid max response memory",
+			config: &Config{
+				Server: ServerConfig{
+					Transport: "http",
+					HTTP: HTTPConfig{
+						Port: 8080,
+					},
+					MaxResponseMemory: "lots",
+				},
+				OpenAPI: OpenAPIConfig{
+					SpecPath:   "https://api.example.com/openapi.json",
+					Timeout:    30 * time.Second,
+					MaxRetries: 3,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "jwt enabled without jwks url",
+			config: &Config{
+				Server: ServerConfig{
+					Transport: "http",
+					HTTP: HTTPConfig{
+						Port: 8080,
+					},
+				},
+				OpenAPI: OpenAPIConfig{
+					SpecPath:   "https://api.example.com/openapi.json",
+					Timeout:    30 * time.Second,
+					MaxRetries: 3,
+				},
+				Security: SecurityConfig{
+					RateLimiting: RateLimitingConfig{
+						Enabled:           true,
+						RequestsPerMinute: 100,
+					},
+					JWT: JWTConfig{
+						Enabled: true,
+					},
+				},
+			},
+			wantErr: true,
+			errType: ErrMissingJWKSURL,
+		},
+		{
+			name: "invalid request size limit",
+			config: &Config{
+				Server: ServerConfig{
+					Transport: "http",
+					HTTP: HTTPConfig{
+						Port: 8080,
+					},
+				},
+				OpenAPI: OpenAPIConfig{
+					SpecPath:   "https://api.example.com/openapi.json",
+					Timeout:    30 * time.Second,
+					MaxRetries: 3,
+				},
+				Security: SecurityConfig{
+					RateLimiting: RateLimitingConfig{
+						Enabled:           true,
+						RequestsPerMinute: 100,
+					},
+					RequestSizeLimit: "huge",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -245,6 +317,117 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidate_APIs(t *testing.T) {
+	base := func() *Config {
+		config := Default()
+		config.OpenAPI.SpecPath = ""
+		return config
+	}
+	api := func(specPath string) OpenAPIConfig {
+		return OpenAPIConfig{SpecPath: specPath, Timeout: 30 * time.Second, MaxRetries: 3}
+	}
+
+	tests := []struct {
+		name    string
+		apis    []OpenAPIConfig
+		errType error
+	}{
+		{
+			name: "apis without openapi block",
+			apis: []OpenAPIConfig{api("https://a.example.com/openapi.json"), api("https://b.example.com/openapi.json")},
+		},
+		{
+			name:    "no specs at all",
+			errType: ErrMissingOpenAPISpec,
+		},
+		{
+			name:    "api missing spec path",
+			apis:    []OpenAPIConfig{api("https://a.example.com/openapi.json"), api("")},
+			errType: ErrMissingOpenAPISpec,
+		},
+		{
+			name:    "api with invalid timeout",
+			apis:    []OpenAPIConfig{{SpecPath: "https://a.example.com/openapi.json", Timeout: 0}},
+			errType: ErrInvalidTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := base()
+			config.APIs = tt.apis
+			err := config.Validate()
+			if tt.errType == nil {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.errType) {
+				t.Errorf("Expected error %v, got %v", tt.errType, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Extensions(t *testing.T) {
+	config := Default()
+	config.OpenAPI.SpecPath = "https://api.example.com/openapi.json"
+	config.Extensions = []ExtensionConfig{
+		{Path: "/etc/mcpify/signer.so"},
+		{WASM: "/etc/mcpify/transforms/redact.wasm", Tools: []string{"get_users*"}},
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		extension ExtensionConfig
+		message   string
+	}{
+		{"missing path", ExtensionConfig{}, "extensions[2]: path or wasm is required"},
+		{"plugin and wasm", ExtensionConfig{Path: "a.so", WASM: "a.wasm"}, "path and wasm are mutually exclusive"},
+		{"wasm options", ExtensionConfig{WASM: "a.wasm", Options: map[string]interface{}{"key": "k"}}, "options are passed to plugins only"},
+		{"empty tool", ExtensionConfig{WASM: "a.wasm", Tools: []string{""}}, "tools must not contain an empty pattern"},
+	}
+	valid := config.Extensions
+	for _, tt := range tests {
+		config.Extensions = append(slices.Clone(valid), tt.extension)
+		err := config.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("%s: expected %q, got %v", tt.name, tt.message, err)
+		}
+	}
+
+	wasm := valid[1]
+	if !wasm.AppliesTo("get_users_by_id") || wasm.AppliesTo("delete_users_by_id") {
+		t.Error("Expected the extension to apply to the matching tools only")
+	}
+	if !valid[0].AppliesTo("delete_users_by_id") {
+		t.Error("Expected an extension without tools to apply to every tool")
+	}
+}
+
+func TestAPIConfigs(t *testing.T) {
+	config := Default()
+	config.OpenAPI.SpecPath = "https://main.example.com/openapi.json"
+	config.APIs = []OpenAPIConfig{{SpecPath: "https://extra.example.com/openapi.json"}}
+
+	apis := config.APIConfigs()
+	if len(apis) != 2 {
+		t.Fatalf("Expected 2 APIs, got %d", len(apis))
+	}
+	if apis[0] != &config.OpenAPI || apis[1] != &config.APIs[0] {
+		t.Error("Expected APIConfigs to return pointers into the config")
+	}
+
+	config.OpenAPI.SpecPath = ""
+	if apis := config.APIConfigs(); len(apis) != 1 || apis[0].SpecPath != "https://extra.example.com/openapi.json" {
+		t.Errorf("Expected only the apis entry when openapi has no spec, got %v", apis)
+	}
+}
+
 func TestConfigStructs(t *testing.T) {
 	// Test that all config structs can be instantiated
 	config := &Config{
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HeaderConflict describes a header that is set by more than one part of an API configuration
+type HeaderConflict struct {
+	Header  string
+	Message string
+	// Always is true when the later value always replaces the earlier one. Conflicts with a
+	// valueFrom or template header only apply when its values are present.
+	Always bool
+}
+
+// authHeaderName returns the header carrying the configured credentials, if any
+func (a *AuthConfig) authHeaderName() string {
+	switch a.Type {
+	case "bearer", "basic":
+		return "Authorization"
+	case "api_key":
+		if a.APIKeyIn == "header" {
+			return a.APIKeyName
+		}
+	}
+	return ""
+}
+
+// HeaderConflicts reports headers whose values override each other. Headers are applied in
+// order: auth credentials, then auth.headers, then headers.
+func (c *OpenAPIConfig) HeaderConflicts() []HeaderConflict {
+	var conflicts []HeaderConflict
+
+	if name := c.Auth.authHeaderName(); name != "" {
+		source := fmt.Sprintf("the %s credentials from auth", c.Auth.Type)
+		conflicts = append(conflicts, overriddenBy(name, source, "auth.headers", c.Auth.Headers)...)
+		conflicts = append(conflicts, overriddenBy(name, source, "headers", c.Headers)...)
+	}
+	for _, item := range c.Auth.Headers {
+		source := fmt.Sprintf("auth.headers %s", item.Header.Name)
+		conflicts = append(conflicts, overriddenBy(item.Header.Name, source, "headers", c.Headers)...)
+	}
+
+	return conflicts
+}
+
+// overriddenBy reports the entries of headers that replace the header name set by source
+func overriddenBy(name, source, section string, headers HeadersConfig) []HeaderConflict {
+	var conflicts []HeaderConflict
+	for _, item := range headers {
+		if !strings.EqualFold(item.Header.Name, name) {
+			continue
+		}
+
+		dynamic := item.Header.ValueFrom
+		for _, value := range item.Header.staticValues() {
+			if dynamic == "" && IsTemplate(value) {
+				dynamic = value
+			}
+		}
+		conflict := HeaderConflict{Header: item.Header.Name}
+		switch {
+		case dynamic != "":
+			conflict.Message = fmt.Sprintf("%s sets %s from %s, replacing %s %s",
+				section, item.Header.Name, dynamic, source, valueCondition(item.Header, dynamic))
+		case item.Header.When != nil:
+			conflict.Message = fmt.Sprintf("%s sets %s, replacing %s when its conditions hold", section, item.Header.Name, source)
+		default:
+			conflict.Always = true
+			conflict.Message = fmt.Sprintf("%s sets %s, replacing %s", section, item.Header.Name, source)
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+// valueCondition describes when a dynamic header has a value, based on where the first
+// expression of its valueFrom or template reads from
+func valueCondition(header HeaderConfig, dynamic string) string {
+	var expr *Expression
+	if header.ValueFrom != "" {
+		expr, _ = ParseExpression(header.ValueFrom)
+	} else if tmpl, err := ParseTemplate(dynamic); err == nil {
+		for _, part := range tmpl.parts {
+			if part.expr != nil {
+				expr = part.expr
+				break
+			}
+		}
+	}
+
+	if expr != nil {
+		switch expr.variable() {
+		case "env":
+			return "when the variable is set"
+		case "params":
+			return "when the tool call provides it"
+		}
+	}
+	return "when the request provides it"
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIConfig_HeaderConflicts(t *testing.T) {
+	header := func(name, value, valueFrom string) HeaderItem {
+		return HeaderItem{Header: HeaderConfig{Name: name, Value: value, ValueFrom: valueFrom}}
+	}
+
+	tests := []struct {
+		name     string
+		config   OpenAPIConfig
+		expected []HeaderConflict
+	}{
+		{
+			name: "no conflicts",
+			config: OpenAPIConfig{
+				Auth:    AuthConfig{Type: "bearer", Token: "t", Headers: HeadersConfig{header("X-Tenant", "a", "")}},
+				Headers: HeadersConfig{header("Accept", "application/json", "")},
+			},
+		},
+		{
+			name: "static header replaces bearer token",
+			config: OpenAPIConfig{
+				Auth:    AuthConfig{Type: "bearer", Token: "t"},
+				Headers: HeadersConfig{header("authorization", "Bearer other", "")},
+			},
+			expected: []HeaderConflict{{
+				Header:  "authorization",
+				Message: "headers sets authorization, replacing the bearer credentials from auth",
+				Always:  true,
+			}},
+		},
+		{
+			name: "forwarded header may replace api key",
+			config: OpenAPIConfig{
+				Auth: AuthConfig{Type: "api_key", APIKey: "k", APIKeyName: "X-API-Key", APIKeyIn: "header",
+					Headers: HeadersConfig{header("X-API-Key", "", "request.headers['x-api-key']")}},
+			},
+			expected: []HeaderConflict{{
+				Header:  "X-API-Key",
+				Message: "auth.headers sets X-API-Key from request.headers['x-api-key'], replacing the api_key credentials from auth when the request provides it",
+			}},
+		},
+		{
+			name: "environment header may replace bearer token",
+			config: OpenAPIConfig{
+				Auth:    AuthConfig{Type: "bearer", Token: "t"},
+				Headers: HeadersConfig{header("Authorization", "", "env['UPSTREAM_AUTHORIZATION']")},
+			},
+			expected: []HeaderConflict{{
+				Header:  "Authorization",
+				Message: "headers sets Authorization from env['UPSTREAM_AUTHORIZATION'], replacing the bearer credentials from auth when the variable is set",
+			}},
+		},
+		{
+			name: "template header may replace bearer token",
+			config: OpenAPIConfig{
+				Auth:    AuthConfig{Type: "bearer", Token: "t"},
+				Headers: HeadersConfig{header("Authorization", "Bearer {{ params.token }}", "")},
+			},
+			expected: []HeaderConflict{{
+				Header:  "Authorization",
+				Message: "headers sets Authorization from Bearer {{ params.token }}, replacing the bearer credentials from auth when the tool call provides it",
+			}},
+		},
+		{
+			name: "conditional header may replace bearer token",
+			config: OpenAPIConfig{
+				Auth: AuthConfig{Type: "bearer", Token: "t"},
+				Headers: HeadersConfig{{Header: HeaderConfig{Name: "Authorization", Value: "Bearer other",
+					When: &HeaderCondition{Paths: []string{"/admin/*"}}}}},
+			},
+			expected: []HeaderConflict{{
+				Header:  "Authorization",
+				Message: "headers sets Authorization, replacing the bearer credentials from auth when its conditions hold",
+			}},
+		},
+		{
+			name: "query api key has no header",
+			config: OpenAPIConfig{
+				Auth:    AuthConfig{Type: "api_key", APIKey: "k", APIKeyName: "key", APIKeyIn: "query"},
+				Headers: HeadersConfig{header("key", "v", "")},
+			},
+		},
+		{
+			name: "headers replace auth headers",
+			config: OpenAPIConfig{
+				Auth:    AuthConfig{Headers: HeadersConfig{header("X-Tenant", "a", "")}},
+				Headers: HeadersConfig{header("X-Tenant", "b", "")},
+			},
+			expected: []HeaderConflict{{
+				Header:  "X-Tenant",
+				Message: "headers sets X-Tenant, replacing auth.headers X-Tenant",
+				Always:  true,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.HeaderConflicts())
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ConnectionConfig tunes the connection pool used to reach an upstream API. Zero values keep
+// mcpify's defaults, which allow more idle connections per host than Go's default of two.
+type ConnectionConfig struct {
+	MaxIdleConns        int           `yaml:"max_idle_conns" json:"max_idle_conns"`                   // Idle connections kept across all hosts
+	MaxIdleConnsPerHost int           `yaml:"max_idle_conns_per_host" json:"max_idle_conns_per_host"` // Idle connections kept per host
+	IdleConnTimeout     time.Duration `yaml:"idle_conn_timeout" json:"idle_conn_timeout"`             // How long idle connections are kept
+	DialTimeout         time.Duration `yaml:"dial_timeout" json:"dial_timeout"`                       // Limit for establishing a TCP connection
+	TLSHandshakeTimeout time.Duration `yaml:"tls_handshake_timeout" json:"tls_handshake_timeout"`     // Limit for the TLS handshake
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for ConnectionConfig
+func (c *ConnectionConfig) UnmarshalJSON(data []byte) error {
+	type Alias ConnectionConfig
+	aux := &struct {
+		IdleConnTimeout     string `json:"idle_conn_timeout"`
+		DialTimeout         string `json:"dial_timeout"`
+		TLSHandshakeTimeout string `json:"tls_handshake_timeout"`
+		*Alias
+	}{
+		Alias: (*Alias)(c),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	for _, field := range []struct {
+		value  string
+		target *time.Duration
+	}{
+		{aux.IdleConnTimeout, &c.IdleConnTimeout},
+		{aux.DialTimeout, &c.DialTimeout},
+		{aux.TLSHandshakeTimeout, &c.TLSHandshakeTimeout},
+	} {
+		if field.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(field.value)
+		if err != nil {
+			return err
+		}
+		*field.target = duration
+	}
+
+	return nil
+}
+
+// Validate validates the ConnectionConfig
+func (c *ConnectionConfig) Validate() error {
+	if c.MaxIdleConns < 0 || c.MaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("idle connection limits cannot be negative")
+	}
+	if c.IdleConnTimeout < 0 || c.DialTimeout < 0 || c.TLSHandshakeTimeout < 0 {
+		return fmt.Errorf("connection timeouts cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionConfig_UnmarshalJSON(t *testing.T) {
+	var connections ConnectionConfig
+	err := json.Unmarshal([]byte(`{"max_idle_conns_per_host": 64, "idle_conn_timeout": "2m", "dial_timeout": "5s"}`), &connections)
+	require.NoError(t, err)
+	assert.Equal(t, ConnectionConfig{MaxIdleConnsPerHost: 64, IdleConnTimeout: 2 * time.Minute, DialTimeout: 5 * time.Second}, connections)
+
+	err = json.Unmarshal([]byte(`{"dial_timeout": "soon"}`), &connections)
+	assert.Error(t, err)
+}
+
+func TestConnectionConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&ConnectionConfig{}).Validate())
+	assert.Error(t, (&ConnectionConfig{MaxIdleConnsPerHost: -1}).Validate())
+	assert.Error(t, (&ConnectionConfig{DialTimeout: -time.Second}).Validate())
+}
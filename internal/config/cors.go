@@ -0,0 +1,87 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CORSConfig contains CORS configuration
+type CORSConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Origins are the allowed origins: exact origins, "*" for any origin, or patterns with a
+	// wildcard subdomain such as "https://*.example.com"
+	Origins []string `yaml:"origins" json:"origins"`
+	// AllowedMethods and AllowedHeaders answer preflight requests; empty uses the methods and
+	// headers MCP clients send
+	AllowedMethods []string `yaml:"allowed_methods" json:"allowed_methods"`
+	AllowedHeaders []string `yaml:"allowed_headers" json:"allowed_headers"`
+	// ExposedHeaders are the response headers browser clients may read, Mcp-Session-Id by default
+	ExposedHeaders []string `yaml:"exposed_headers" json:"exposed_headers"`
+	// AllowCredentials lets browsers send cookies and HTTP authentication with requests
+	AllowCredentials bool `yaml:"allow_credentials" json:"allow_credentials"`
+	// MaxAge is how long browsers may cache preflight responses, 24h by default
+	MaxAge time.Duration `yaml:"max_age" json:"max_age"`
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for CORSConfig
+func (c *CORSConfig) UnmarshalJSON(data []byte) error {
+	type Alias CORSConfig
+	aux := &struct {
+		MaxAge string `json:"max_age"`
+		*Alias
+	}{
+		Alias: (*Alias)(c),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.MaxAge != "" {
+		duration, err := time.ParseDuration(aux.MaxAge)
+		if err != nil {
+			return err
+		}
+		c.MaxAge = duration
+	}
+
+	return nil
+}
+
+// Validate validates the CORSConfig
+func (c *CORSConfig) Validate() error {
+	for _, origin := range c.Origins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				return fmt.Errorf("allow_credentials cannot be used with the \"*\" origin")
+			}
+			continue
+		}
+		if err := validateOriginPattern(origin); err != nil {
+			return fmt.Errorf("invalid origin %q: %w", origin, err)
+		}
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("max_age cannot be negative")
+	}
+	return nil
+}
+
+// validateOriginPattern checks that an origin is a scheme and host, with an optional port and
+// an optional "*." wildcard in front of the host
+func validateOriginPattern(origin string) error {
+	parsed, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("expected scheme://host")
+	}
+	if strings.Contains(parsed.Host, "*") || parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("only a leading \"*.\" wildcard in the host is supported")
+	}
+	return nil
+}
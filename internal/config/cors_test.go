@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORSConfig(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.Server.HTTP.CORS.Origins = []string{"https://app.example.com", "https://*.example.org", "http://localhost:3000"}
+	cfg.Server.HTTP.CORS.AllowCredentials = true
+	assert.NoError(t, cfg.Validate())
+
+	for _, origins := range [][]string{
+		{"*"},
+		{"app.example.com"},
+		{"https://app.*.example.com"},
+		{"https://app.example.com/"},
+	} {
+		cfg.Server.HTTP.CORS.Origins = origins
+		err := cfg.Validate()
+		require.Error(t, err, "origins %v", origins)
+		assert.Contains(t, err.Error(), "invalid cors")
+	}
+}
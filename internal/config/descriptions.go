@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ways localized descriptions are applied
+const (
+	DescriptionsReplace = "replace" // The localized description replaces the generated one
+	DescriptionsAppend  = "append"  // The localized description follows the generated one
+)
+
+// DescriptionsConfig presents tools with descriptions in the team's language, from a YAML or
+// JSON file mapping each locale to tool descriptions, e.g. {"fr": {"list_users": "..."}}
+type DescriptionsConfig struct {
+	File   string `yaml:"file" json:"file"`     // File of descriptions per locale and tool
+	Locale string `yaml:"locale" json:"locale"` // Locale used, e.g. "fr" or "pt-BR", falling back to its language
+	Mode   string `yaml:"mode" json:"mode"`     // "replace" (default) or "append"
+}
+
+// ModeOrDefault returns how localized descriptions are applied
+func (d *DescriptionsConfig) ModeOrDefault() string {
+	if d.Mode == "" {
+		return DescriptionsReplace
+	}
+	return d.Mode
+}
+
+// Load reads the descriptions of the configured locale, keyed by tool name. It returns nil
+// when no file is configured, and an empty map when the file has no entry for the locale.
+func (d *DescriptionsConfig) Load() (map[string]string, error) {
+	if d.File == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(d.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptions file: %w", err)
+	}
+	var locales map[string]map[string]string
+	if err := yaml.Unmarshal(content, &locales); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptions file %s: %w", d.File, err)
+	}
+
+	// Locales match case-insensitively, and pt-BR or pt_BR fall back to pt
+	language, _, _ := strings.Cut(strings.ReplaceAll(d.Locale, "_", "-"), "-")
+	for _, candidate := range []string{d.Locale, language} {
+		for locale, descriptions := range locales {
+			if strings.EqualFold(strings.ReplaceAll(locale, "_", "-"), strings.ReplaceAll(candidate, "_", "-")) {
+				return descriptions, nil
+			}
+		}
+	}
+	return map[string]string{}, nil
+}
+
+// Validate validates the DescriptionsConfig
+func (d *DescriptionsConfig) Validate() error {
+	if d.File != "" && d.Locale == "" {
+		return fmt.Errorf("locale is required with a descriptions file")
+	}
+	if mode := d.ModeOrDefault(); mode != DescriptionsReplace && mode != DescriptionsAppend {
+		return fmt.Errorf("mode must be %q or %q, got %q", DescriptionsReplace, DescriptionsAppend, d.Mode)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescriptionsConfig_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "descriptions.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"pt_BR": {"get_users": "Lista os usuários"}, "fr": {"get_users": "Liste les utilisateurs"}}`), 0o600))
+
+	for locale, expected := range map[string]string{"pt-br": "Lista os usuários", "fr-CA": "Liste les utilisateurs", "de": ""} {
+		descriptions, err := (&DescriptionsConfig{File: path, Locale: locale}).Load()
+		require.NoError(t, err)
+		assert.Equal(t, expected, descriptions["get_users"], locale)
+	}
+
+	descriptions, err := (&DescriptionsConfig{}).Load()
+	require.NoError(t, err)
+	assert.Nil(t, descriptions)
+}
+
+func TestDescriptionsConfig_Validate(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.Descriptions = DescriptionsConfig{File: "descriptions.yaml", Locale: "fr", Mode: DescriptionsAppend}
+	assert.NoError(t, cfg.Validate())
+
+	for _, descriptions := range []DescriptionsConfig{
+		{File: "descriptions.yaml"},
+		{File: "descriptions.yaml", Locale: "fr", Mode: "prepend"},
+	} {
+		cfg.Descriptions = descriptions
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid descriptions")
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DNSConfig controls how the hosts of upstream APIs are resolved, for split-horizon networks
+// whose internal names only some DNS servers know, and to spare busy servers a lookup per
+// connection
+type DNSConfig struct {
+	Servers  []string      `yaml:"servers" json:"servers"`     // DNS servers queried in order, as host or host:port; the system resolver when empty
+	CacheTTL time.Duration `yaml:"cache_ttl" json:"cache_ttl"` // How long resolved addresses are reused, 0 to resolve each connection
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for DNSConfig
+func (d *DNSConfig) UnmarshalJSON(data []byte) error {
+	type Alias DNSConfig
+	aux := &struct {
+		CacheTTL string `json:"cache_ttl"`
+		*Alias
+	}{
+		Alias: (*Alias)(d),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.CacheTTL != "" {
+		duration, err := time.ParseDuration(aux.CacheTTL)
+		if err != nil {
+			return err
+		}
+		d.CacheTTL = duration
+	}
+
+	return nil
+}
+
+// IsZero reports whether the system resolver is used as is
+func (d *DNSConfig) IsZero() bool {
+	return len(d.Servers) == 0 && d.CacheTTL == 0
+}
+
+// ServerAddresses returns the DNS servers as host:port, with port 53 when none is given
+func (d *DNSConfig) ServerAddresses() []string {
+	addresses := make([]string, len(d.Servers))
+	for i, server := range d.Servers {
+		if _, _, err := net.SplitHostPort(server); err == nil {
+			addresses[i] = server
+		} else {
+			addresses[i] = net.JoinHostPort(server, "53")
+		}
+	}
+	return addresses
+}
+
+// Validate validates the DNSConfig
+func (d *DNSConfig) Validate() error {
+	for i, address := range d.ServerAddresses() {
+		host, _, _ := net.SplitHostPort(address)
+		if net.ParseIP(host) == nil {
+			return fmt.Errorf("servers must be IP addresses, got %q", d.Servers[i])
+		}
+	}
+	if d.CacheTTL < 0 {
+		return fmt.Errorf("cache_ttl cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSConfig(t *testing.T) {
+	dns := DNSConfig{Servers: []string{"10.0.0.53", "10.0.0.54:5353", "fd00::53"}}
+	assert.Equal(t, []string{"10.0.0.53:53", "10.0.0.54:5353", "[fd00::53]:53"}, dns.ServerAddresses())
+	assert.False(t, dns.IsZero())
+
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.OpenAPI.DNS = dns
+	assert.NoError(t, cfg.Validate())
+
+	cfg.OpenAPI.DNS = DNSConfig{Servers: []string{"dns.internal"}}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid dns")
+}
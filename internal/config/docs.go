@@ -0,0 +1,23 @@
+package config
+
+import (
+	"fmt"
+)
+
+// DocsConfig configures the API documentation served by the HTTP transport under /docs, which
+// lists the registered tools and renders each loaded specification
+type DocsConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// UI renders the specifications with "swagger" (Swagger UI, the default) or "redoc"
+	UI string `yaml:"ui" json:"ui"`
+}
+
+// Validate validates the DocsConfig
+func (d *DocsConfig) Validate() error {
+	switch d.UI {
+	case "", "swagger", "redoc":
+		return nil
+	default:
+		return fmt.Errorf("invalid ui %q: use swagger or redoc", d.UI)
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocsConfig_Validate(t *testing.T) {
+	for _, ui := range []string{"", "swagger", "redoc"} {
+		docs := DocsConfig{Enabled: true, UI: ui}
+		assert.NoError(t, docs.Validate(), "ui %q", ui)
+	}
+
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.Server.HTTP.Docs = DocsConfig{Enabled: true, UI: "rapidoc"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid docs: invalid ui "rapidoc"`)
+}
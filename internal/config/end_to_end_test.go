@@ -138,8 +138,8 @@ openapi:
 		t.Errorf("Expected default auth type, got %s", config.OpenAPI.Auth.Type)
 	}
 
-	if !config.Security.RateLimiting.Enabled {
-		t.Error("Expected default rate limiting to be enabled")
+	if config.Security.RateLimiting.Enabled {
+		t.Error("Expected rate limiting to be disabled by default")
 	}
 
 	if config.Security.RateLimiting.RequestsPerMinute != 100 {
@@ -0,0 +1,368 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// expandEnv substitutes ${VAR} and ${VAR:-default} references in configuration content.
+// Unset variables without a default expand to an empty string, and "$${" escapes a
+// literal "${" sequence.
+func expandEnv(content string, lookup func(string) (string, bool)) string {
+	return replaceEnvReferences(content, func(ref EnvReference) string {
+		value, ok := lookup(ref.Name)
+		if (!ok || value == "") && ref.HasDefault {
+			value = ref.Default
+		}
+		return value
+	})
+}
+
+// EnvReference is a ${VAR} or ${VAR:-default} reference in configuration content
+type EnvReference struct {
+	Name       string
+	Default    string
+	HasDefault bool
+}
+
+// EnvReferences returns the environment variables referenced by configuration content, in
+// order of first reference. A variable referenced both with and without a default is
+// reported without one, since the reference without a default needs it set.
+func EnvReferences(content string) []EnvReference {
+	var references []EnvReference
+	index := make(map[string]int)
+	replaceEnvReferences(content, func(ref EnvReference) string {
+		if i, exists := index[ref.Name]; exists {
+			if !ref.HasDefault {
+				references[i] = ref
+			}
+			return ""
+		}
+		index[ref.Name] = len(references)
+		references = append(references, ref)
+		return ""
+	})
+	return references
+}
+
+// replaceEnvReferences replaces each variable reference in content with the result of
+// replace, unescaping "$${" and keeping anything else verbatim
+func replaceEnvReferences(content string, replace func(EnvReference) string) string {
+	var result strings.Builder
+	result.Grow(len(content))
+
+	for i := 0; i < len(content); i++ {
+		// Escaped reference: $${VAR} -> ${VAR}
+		if strings.HasPrefix(content[i:], "$${") {
+			result.WriteString("${")
+			i += 2
+			continue
+		}
+
+		if !strings.HasPrefix(content[i:], "${") {
+			result.WriteByte(content[i])
+			continue
+		}
+
+		end := strings.IndexByte(content[i+2:], '}')
+		if end == -1 {
+			// Unterminated reference, keep the remainder verbatim
+			result.WriteString(content[i:])
+			break
+		}
+
+		reference := content[i+2 : i+2+end]
+		name, defaultValue, hasDefault := strings.Cut(reference, ":-")
+		if !isValidEnvName(name) {
+			// Not a variable reference we understand, keep it verbatim
+			result.WriteString(content[i : i+3+end])
+			i += 2 + end
+			continue
+		}
+
+		result.WriteString(replace(EnvReference{Name: name, Default: defaultValue, HasDefault: hasDefault}))
+		i += 2 + end
+	}
+
+	return result.String()
+}
+
+// isValidEnvName checks that a name is a valid environment variable identifier
+func isValidEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		isLetter := (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || c == '_'
+		isDigit := c >= '0' && c <= '9'
+		if !isLetter && (!isDigit || i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpandEnv substitutes environment variable references using the process environment
+func ExpandEnv(content string) string {
+	return expandEnv(content, os.LookupEnv)
+}
+
+// expandEnvValues substitutes environment variable references in the string values of
+// configuration content, once it is parsed, so values containing quotes, newlines or YAML
+// syntax cannot change the structure of the file. Unquoted YAML values are resolved again
+// after substitution, so "port: ${PORT}" still gives a number.
+func expandEnvValues(content []byte, ext string, lookup func(string) (string, bool)) ([]byte, error) {
+	if !strings.Contains(string(content), "${") {
+		return content, nil
+	}
+
+	if ext == ".yaml" || ext == ".yml" {
+		var node yaml.Node
+		if err := yaml.Unmarshal(content, &node); err != nil {
+			return nil, err
+		}
+		if !expandEnvNode(&node, lookup) {
+			return content, nil
+		}
+		return yaml.Marshal(&node)
+	}
+
+	doc, err := parseDocument([]byte(expandBareEnvReferences(string(content), ext, lookup)), ext)
+	if err != nil {
+		return nil, err
+	}
+	return encodeDocument(expandEnvValue(doc, lookup).(map[string]interface{}), ext)
+}
+
+// expandBareEnvReferences substitutes the references of JSON or TOML content that are outside
+// strings, as in "port": ${PORT}. Numbers and booleans are written as they are and other
+// values as quoted strings, with "${" escaped so they are not expanded again.
+func expandBareEnvReferences(content, ext string, lookup func(string) (string, bool)) string {
+	var result strings.Builder
+	result.Grow(len(content))
+
+	var quote byte // the quote of the string being scanned, 0 outside strings
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' && i+1 < len(content) {
+				result.WriteString(content[i : i+2])
+				i++
+				continue
+			}
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || (c == '\'' && ext == ".toml"):
+			quote = c
+		case c == '#' && ext == ".toml":
+			end := strings.IndexByte(content[i:], '\n')
+			if end == -1 {
+				end = len(content) - i
+			}
+			result.WriteString(content[i : i+end])
+			i += end - 1
+			continue
+		case strings.HasPrefix(content[i:], "${"):
+			end := strings.IndexByte(content[i:], '}')
+			if end == -1 {
+				break
+			}
+			reference := content[i : i+end+1]
+			if expanded := expandEnv(reference, lookup); expanded != reference {
+				result.WriteString(bareValue(expanded))
+				i += end
+				continue
+			}
+		}
+		result.WriteByte(c)
+	}
+	return result.String()
+}
+
+// bareValue formats the value of a reference outside strings: numbers and booleans as they
+// are, anything else as a quoted string
+func bareValue(value string) string {
+	if value == "true" || value == "false" {
+		return value
+	}
+	if value != "" && strings.ContainsAny(value[:1], "-0123456789") && json.Valid([]byte(value)) {
+		return value
+	}
+	quoted, _ := json.Marshal(strings.ReplaceAll(value, "${", "$${"))
+	return string(quoted)
+}
+
+// expandEnvNode substitutes environment variable references in the string values under a
+// YAML node, leaving mapping keys alone, and reports whether any value changed
+func expandEnvNode(node *yaml.Node, lookup func(string) (string, bool)) bool {
+	changed := false
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.ShortTag() != "!!str" {
+			return false
+		}
+		expanded := expandEnv(node.Value, lookup)
+		if expanded == node.Value {
+			return false
+		}
+		node.Value = expanded
+		if node.Style == 0 {
+			// Unquoted values take the type of what they expand to
+			node.Tag = ""
+		}
+		return true
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			changed = expandEnvNode(node.Content[i], lookup) || changed
+		}
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			changed = expandEnvNode(child, lookup) || changed
+		}
+	}
+	return changed
+}
+
+// expandEnvValue substitutes environment variable references in the strings of a decoded
+// JSON or TOML value
+func expandEnvValue(value interface{}, lookup func(string) (string, bool)) interface{} {
+	switch v := value.(type) {
+	case string:
+		return expandEnv(v, lookup)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = expandEnvValue(item, lookup)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = expandEnvValue(item, lookup)
+		}
+	case []map[string]interface{}:
+		for _, item := range v {
+			expandEnvValue(item, lookup)
+		}
+	}
+	return value
+}
+
+// envPrefix is the prefix for environment variables that override configuration fields
+const envPrefix = "MCPIFY"
+
+// ApplyEnvOverrides overrides configuration fields from MCPIFY_* environment variables.
+// Variable names are derived from the YAML keys of each field, e.g. server.http.port
+// becomes MCPIFY_SERVER_HTTP_PORT and openapi.base_url becomes MCPIFY_OPENAPI_BASE_URL.
+func ApplyEnvOverrides(cfg *Config) error {
+	return applyEnvOverrides(reflect.ValueOf(cfg).Elem(), envPrefix, os.LookupEnv)
+}
+
+// applyEnvOverrides walks the struct fields and sets any that have a matching variable
+func applyEnvOverrides(v reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, inline := yamlKey(field)
+		if key == "-" {
+			continue
+		}
+
+		name := prefix
+		if !inline {
+			name = prefix + "_" + strings.ToUpper(key)
+		}
+
+		fieldValue := v.Field(i)
+		if fieldValue.Kind() == reflect.Struct {
+			if err := applyEnvOverrides(fieldValue, name, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if err := setFieldFromEnv(fieldValue, value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// yamlKey returns the YAML key for a struct field and whether it is inlined
+func yamlKey(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("yaml")
+	name, options, _ := strings.Cut(tag, ",")
+	if strings.Contains(options, "inline") || (field.Anonymous && name == "") {
+		return "", true
+	}
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, false
+}
+
+// setFieldFromEnv parses an environment variable value into a field of any supported type
+func setFieldFromEnv(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		// Plain string lists accept a comma-separated form
+		if field.Type().Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(value), "[") {
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			field.Set(reflect.ValueOf(items).Convert(field.Type()))
+			return nil
+		}
+		return yaml.Unmarshal([]byte(value), field.Addr().Interface())
+	default:
+		// Complex values (maps, header lists) are parsed as inline YAML/JSON
+		return yaml.Unmarshal([]byte(value), field.Addr().Interface())
+	}
+	return nil
+}
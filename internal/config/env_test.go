@@ -0,0 +1,206 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{
+		"API_TOKEN": "secret-token",
+		"PORT":      "8080",
+		"EMPTY":     "",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"no references", "token: abc", "token: abc"},
+		{"simple reference", "token: ${API_TOKEN}", "token: secret-token"},
+		{"multiple references", "${PORT}/${API_TOKEN}", "8080/secret-token"},
+		{"unset without default", "token: ${MISSING}", "token: "},
+		{"unset with default", "host: ${HOST:-127.0.0.1}", "host: 127.0.0.1"},
+		{"set ignores default", "port: ${PORT:-9090}", "port: 8080"},
+		{"empty uses default", "value: ${EMPTY:-fallback}", "value: fallback"},
+		{"empty default", "value: ${MISSING:-}", "value: "},
+		{"default with colon", "url: ${URL:-http://localhost:8080}", "url: http://localhost:8080"},
+		{"escaped reference", "literal: $${API_TOKEN}", "literal: ${API_TOKEN}"},
+		{"bare dollar untouched", "price: $5", "price: $5"},
+		{"shell style untouched", "value: $API_TOKEN", "value: $API_TOKEN"},
+		{"invalid name untouched", "expr: ${1abc}", "expr: ${1abc}"},
+		{"unterminated reference", "value: ${API_TOKEN", "value: ${API_TOKEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, expandEnv(tt.input, lookup))
+		})
+	}
+}
+
+func TestEnvReferences(t *testing.T) {
+	content := "token: ${API_TOKEN}\nhost: ${HOST:-127.0.0.1}\nport: ${PORT:-8080}\n" +
+		"literal: $${ESCAPED}\nexpr: ${1abc}\nport_again: ${PORT}\ntoken_again: ${API_TOKEN:-unused}\n"
+
+	assert.Equal(t, []EnvReference{
+		{Name: "API_TOKEN"},
+		{Name: "HOST", Default: "127.0.0.1", HasDefault: true},
+		{Name: "PORT"},
+	}, EnvReferences(content))
+	assert.Empty(t, EnvReferences("token: abc"))
+}
+
+func TestLoad_EnvSubstitution(t *testing.T) {
+	t.Setenv("MCPIFY_TEST_TOKEN", "env-token")
+	t.Setenv("MCPIFY_TEST_PORT", "9443")
+
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte(`
+server:
+  http:
+    host: ${MCPIFY_TEST_HOST:-0.0.0.0}
+    port: ${MCPIFY_TEST_PORT}
+openapi:
+  spec_path: "https://api.example.com/openapi.json"
+  auth:
+    type: bearer
+    token: "${MCPIFY_TEST_TOKEN}"
+`), 0o600))
+
+	cfg, err := NewLoader().Load(yamlPath)
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0", cfg.Server.HTTP.Host)
+	assert.Equal(t, 9443, cfg.Server.HTTP.Port)
+	assert.Equal(t, "env-token", cfg.OpenAPI.Auth.Token)
+
+	jsonPath := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{
+  "server": {"http": {"port": ${MCPIFY_TEST_PORT}}},
+  "openapi": {"spec_path": "https://api.example.com/openapi.json", "auth": {"type": "bearer", "token": "${MCPIFY_TEST_TOKEN}"}}
+}`), 0o600))
+
+	cfg, err = NewLoader().Load(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, 9443, cfg.Server.HTTP.Port)
+	assert.Equal(t, "env-token", cfg.OpenAPI.Auth.Token)
+}
+
+func TestLoad_EnvSubstitutionSpecialCharacters(t *testing.T) {
+	secret := "a\"b\nc: d # e"
+	t.Setenv("MCPIFY_TEST_TOKEN", secret)
+	t.Setenv("MCPIFY_TEST_HOST", "x\"\n  extra_key: injected")
+
+	tests := []struct {
+		name    string
+		ext     string
+		content string
+	}{
+		{"yaml quoted", ".yaml", "server:\n  http:\n    host: \"${MCPIFY_TEST_HOST}\"\nopenapi:\n  spec_path: \"spec.json\"\n  auth:\n    type: bearer\n    token: \"${MCPIFY_TEST_TOKEN}\"\n"},
+		{"yaml unquoted", ".yaml", "server:\n  http:\n    host: ${MCPIFY_TEST_HOST}\nopenapi:\n  spec_path: spec.json\n  auth:\n    type: bearer\n    token: ${MCPIFY_TEST_TOKEN}\n"},
+		{"json", ".json", `{"server": {"http": {"host": "${MCPIFY_TEST_HOST}"}}, "openapi": {"spec_path": "spec.json", "auth": {"type": "bearer", "token": "${MCPIFY_TEST_TOKEN}"}}}`},
+		{"json unquoted", ".json", `{"server": {"http": {"host": ${MCPIFY_TEST_HOST}}}, "openapi": {"spec_path": "spec.json", "auth": {"type": "bearer", "token": ${MCPIFY_TEST_TOKEN}}}}`},
+		{"toml", ".toml", "[server.http]\nhost = \"${MCPIFY_TEST_HOST}\"\n\n[openapi]\nspec_path = \"spec.json\"\n\n[openapi.auth]\ntype = \"bearer\"\ntoken = \"${MCPIFY_TEST_TOKEN}\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config"+tt.ext)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			loader := NewLoader()
+			cfg, err := loader.Load(path)
+			require.NoError(t, err)
+			assert.Equal(t, secret, cfg.OpenAPI.Auth.Token)
+			assert.Equal(t, "x\"\n  extra_key: injected", cfg.Server.HTTP.Host)
+			assert.Empty(t, loader.UnknownKeys(), "values cannot add keys")
+		})
+	}
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	env := map[string]string{
+		"MCPIFY_SERVER_TRANSPORT":                           "stdio",
+		"MCPIFY_SERVER_HTTP_PORT":                           "8443",
+		"MCPIFY_SERVER_HTTP_SESSION_TIMEOUT":                "15m",
+		"MCPIFY_SERVER_HTTP_CORS_ENABLED":                   "false",
+		"MCPIFY_SERVER_HTTP_CORS_ORIGINS":                   "https://a.example.com, https://b.example.com",
+		"MCPIFY_SERVER_HTTP_TLS_MIN_VERSION":                "1.3",
+		"MCPIFY_OPENAPI_BASE_URL":                           "https://env.example.com",
+		"MCPIFY_OPENAPI_AUTH_TOKEN":                         "env-token",
+		"MCPIFY_OPENAPI_HEADERS":                            `{"X-Env": "yes"}`,
+		"MCPIFY_SECURITY_RATE_LIMITING_ENABLED":             "true",
+		"MCPIFY_SECURITY_RATE_LIMITING_REQUESTS_PER_MINUTE": "250",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	cfg := Default()
+	require.NoError(t, applyEnvOverrides(reflect.ValueOf(cfg).Elem(), envPrefix, lookup))
+
+	assert.Equal(t, "stdio", cfg.Server.Transport)
+	assert.Equal(t, 8443, cfg.Server.HTTP.Port)
+	assert.Equal(t, 15*time.Minute, cfg.Server.HTTP.SessionTimeout)
+	assert.False(t, cfg.Server.HTTP.CORS.Enabled)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.Server.HTTP.CORS.Origins)
+	assert.Equal(t, "1.3", cfg.Server.HTTP.TLS.MinVersion)
+	assert.Equal(t, "https://env.example.com", cfg.OpenAPI.BaseURL)
+	assert.Equal(t, "env-token", cfg.OpenAPI.Auth.Token)
+	assert.Equal(t, "yes", cfg.OpenAPI.Headers.GetValue("X-Env"))
+	assert.Equal(t, 250, cfg.Security.RateLimiting.RequestsPerMinute)
+
+	// Untouched fields keep their values
+	assert.Equal(t, "127.0.0.1", cfg.Server.HTTP.Host)
+}
+
+func TestApplyEnvOverrides_InvalidValue(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		if name == "MCPIFY_SERVER_HTTP_PORT" {
+			return "not-a-port", true
+		}
+		return "", false
+	}
+
+	err := applyEnvOverrides(reflect.ValueOf(Default()).Elem(), envPrefix, lookup)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MCPIFY_SERVER_HTTP_PORT")
+}
+
+func TestLoad_EnvOverridesTakePrecedenceOverFile(t *testing.T) {
+	t.Setenv("MCPIFY_OPENAPI_BASE_URL", "https://override.example.com")
+	t.Setenv("MCPIFY_SERVER_HTTP_PORT", "7000")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+server:
+  http:
+    port: 9000
+openapi:
+  spec_path: "https://api.example.com/openapi.json"
+  base_url: "https://file.example.com"
+`), 0o600))
+
+	cfg, err := NewLoader().Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "https://override.example.com", cfg.OpenAPI.BaseURL)
+	assert.Equal(t, 7000, cfg.Server.HTTP.Port)
+
+	// Overrides also apply when running without a config file
+	cfg, err = NewLoader().Load("")
+	require.NoError(t, err)
+	assert.Equal(t, 7000, cfg.Server.HTTP.Port)
+}
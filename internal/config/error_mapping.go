@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ErrorMapping sets the MCP error code and message clients receive for failed tool calls. A
+// mapping applies when the upstream status is one of its statuses and the error message, which
+// includes the upstream response body, matches its pattern; either condition may be left out.
+type ErrorMapping struct {
+	Statuses []string `yaml:"statuses" json:"statuses"` // Upstream statuses, such as "404" or "4xx"
+	Match    string   `yaml:"match" json:"match"`       // Regular expression over the error message
+	Code     int      `yaml:"code" json:"code"`         // JSON-RPC error code returned to the client
+	Message  string   `yaml:"message" json:"message"`   // Error message returned to the client
+}
+
+// Validate validates the ErrorMapping
+func (e *ErrorMapping) Validate() error {
+	if len(e.Statuses) == 0 && e.Match == "" {
+		return fmt.Errorf("statuses or match is required")
+	}
+	for _, pattern := range e.Statuses {
+		if err := validateStatusPattern(pattern); err != nil {
+			return err
+		}
+	}
+	if _, err := regexp.Compile(e.Match); err != nil {
+		return fmt.Errorf("invalid match: %w", err)
+	}
+	if e.Code == 0 || e.Message == "" {
+		return fmt.Errorf("code and message are required")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorMapping_Validate(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.ErrorMappings = []ErrorMapping{{Statuses: []string{"409"}, Match: "already exists", Code: -1401, Message: "Item already exists"}}
+	assert.NoError(t, cfg.Validate())
+
+	for _, mapping := range []ErrorMapping{
+		{Code: -1401, Message: "Any error"},
+		{Statuses: []string{"4xy"}, Code: -1401, Message: "Bad status"},
+		{Match: "(", Code: -1401, Message: "Bad pattern"},
+		{Statuses: []string{"409"}},
+	} {
+		cfg.ErrorMappings = []ErrorMapping{mapping}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid error_mappings[0]")
+	}
+}
@@ -3,10 +3,15 @@ package config
 import "errors"
 
 var (
-	ErrInvalidTransport   = errors.New("invalid transport method")
-	ErrInvalidPort        = errors.New("invalid port number")
-	ErrMissingOpenAPISpec = errors.New("OpenAPI spec path is required")
-	ErrInvalidTimeout     = errors.New("invalid timeout value")
-	ErrInvalidMaxRetries  = errors.New("invalid max retries value")
-	ErrInvalidRateLimit   = errors.New("invalid rate limit value")
+	ErrInvalidTransport      = errors.New("invalid transport method")
+	ErrInvalidPort           = errors.New("invalid port number")
+	ErrMissingOpenAPISpec    = errors.New("OpenAPI spec path is required")
+	ErrInvalidTimeout        = errors.New("invalid timeout value")
+	ErrInvalidMaxRetries     = errors.New("invalid max retries value")
+	ErrInvalidRateLimit      = errors.New("invalid rate limit value")
+	ErrMissingJWKSURL        = errors.New("JWKS URL is required when JWT validation is enabled")
+	ErrMissingTLSCertificate = errors.New("TLS cert_file and key_file are required when TLS is enabled")
+	ErrUnknownProfile        = errors.New("unknown configuration profile")
+	ErrInvalidExpression     = errors.New("invalid valueFrom expression")
+	ErrUnknownTenant         = errors.New("unknown tenant")
 )
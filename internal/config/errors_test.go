@@ -41,6 +41,31 @@ func TestErrors(t *testing.T) {
 			err:      ErrInvalidRateLimit,
 			expected: "invalid rate limit value",
 		},
+		{
+			name:     "ErrMissingJWKSURL",
+			err:      ErrMissingJWKSURL,
+			expected: "JWKS URL is required when JWT validation is enabled",
+		},
+		{
+			name:     "ErrMissingTLSCertificate",
+			err:      ErrMissingTLSCertificate,
+			expected: "TLS cert_file and key_file are required when TLS is enabled",
+		},
+		{
+			name:     "ErrUnknownProfile",
+			err:      ErrUnknownProfile,
+			expected: "unknown configuration profile",
+		},
+		{
+			name:     "ErrInvalidExpression",
+			err:      ErrInvalidExpression,
+			expected: "invalid valueFrom expression",
+		},
+		{
+			name:     "ErrUnknownTenant",
+			err:      ErrUnknownTenant,
+			expected: "unknown tenant",
+		},
 	}
 
 	for _, tt := range tests {
@@ -88,6 +113,8 @@ func TestErrorUniqueness(t *testing.T) {
 		ErrInvalidTimeout,
 		ErrInvalidMaxRetries,
 		ErrInvalidRateLimit,
+		ErrMissingJWKSURL,
+		ErrMissingTLSCertificate,
 	}
 
 	for i, err1 := range errors {
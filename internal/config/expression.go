@@ -0,0 +1,561 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Expression is a parsed valueFrom expression such as request.headers['x-data'].auth.key.
+//
+// An expression names a part of the request context, optionally prefixed with "request.",
+// or a server environment variable as env['NAME'], followed by any number of accessors: ".name" for identifiers, ['key'] or ["key"] for
+// arbitrary keys and [0] for list elements. Header names are matched case-insensitively.
+// Accessors applied to a string holding a JSON object or array look inside the decoded
+// JSON, so values can be extracted from JSON-encoded headers and query parameters.
+//
+// An expression can also call a built-in function on another expression or on a quoted
+// literal, as in base64encode(request.headers['x-credentials']) or days_ago('7'), and apply
+// accessors to its result.
+type Expression struct {
+	source    string
+	root      string
+	function  string
+	argument  *Expression
+	literal   string
+	isLiteral bool
+	steps     []expressionStep
+}
+
+// expressionStep is one accessor of an expression
+type expressionStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// expressionRoots are the parts of the request context an expression can start from,
+// plus env for the server environment and now for the current time
+var expressionRoots = []string{"headers", "query", "form", "body", "claims", "client_ip", "method", "path", "params", "steps", "response", "env", "now"}
+
+// expressionNow returns the current time of now and the date functions, replaced in tests
+var expressionNow = time.Now
+
+// expressionFunctions are the built-in functions of valueFrom expressions. Each takes the
+// string value of its argument and reports false when the argument cannot be converted.
+var expressionFunctions = map[string]func(string) (string, bool){
+	"base64encode": func(s string) (string, bool) {
+		return base64.StdEncoding.EncodeToString([]byte(s)), true
+	},
+	"base64decode": func(s string) (string, bool) {
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			// Accept unpadded and URL-safe input as found in tokens
+			if decoded, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "=")); err != nil {
+				return "", false
+			}
+		}
+		return string(decoded), true
+	},
+	"urlencode": func(s string) (string, bool) {
+		return url.QueryEscape(s), true
+	},
+	"sha256": func(s string) (string, bool) {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:]), true
+	},
+	"trim": func(s string) (string, bool) {
+		return strings.TrimSpace(s), true
+	},
+	"lower": func(s string) (string, bool) {
+		return strings.ToLower(s), true
+	},
+	"upper": func(s string) (string, bool) {
+		return strings.ToUpper(s), true
+	},
+	"date": func(s string) (string, bool) {
+		for _, layout := range []string{time.RFC3339, time.DateOnly} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.Format(time.DateOnly), true
+			}
+		}
+		return "", false
+	},
+	"days_ago": func(s string) (string, bool) {
+		return addDays(s, -1)
+	},
+	"days_ahead": func(s string) (string, bool) {
+		return addDays(s, 1)
+	},
+}
+
+// addDays returns the time a number of days from now, in the given direction
+func addDays(s string, direction int) (string, bool) {
+	days, err := strconv.Atoi(s)
+	if err != nil {
+		return "", false
+	}
+	return expressionNow().UTC().AddDate(0, 0, direction*days).Format(time.RFC3339), true
+}
+
+// ParseExpression parses a valueFrom expression, returning an error wrapping
+// ErrInvalidExpression that points at the offending position
+func ParseExpression(source string) (*Expression, error) {
+	p := &expressionParser{source: source}
+	return p.parse()
+}
+
+// compiledExpressions caches parsed expressions by source. Configured expressions are compiled
+// when the configuration is validated, so evaluating them per request skips parsing.
+var compiledExpressions sync.Map // map[string]*Expression
+
+// compileExpression returns the parsed expression for source, parsing it on first use.
+// Expressions are not modified once parsed, so one may be evaluated concurrently.
+func compileExpression(source string) (*Expression, error) {
+	if expr, ok := compiledExpressions.Load(source); ok {
+		return expr.(*Expression), nil
+	}
+	expr, err := ParseExpression(source)
+	if err != nil {
+		return nil, err
+	}
+	compiledExpressions.Store(source, expr)
+	return expr, nil
+}
+
+// String returns the expression as written
+func (x *Expression) String() string {
+	return x.source
+}
+
+// Evaluate returns the value the expression selects from the request context, and false
+// when any part of the path is missing
+func (x *Expression) Evaluate(requestContext RequestContext) (interface{}, bool) {
+	if x.isLiteral {
+		return x.literal, true
+	}
+	steps := x.steps
+	var current interface{}
+	switch x.root {
+	case "":
+		// Functions are applied to the string value of their argument
+		value, found := x.argument.Evaluate(requestContext)
+		if !found {
+			return nil, false
+		}
+		argument, err := formatValue(value)
+		if err != nil || argument == "" {
+			return nil, false
+		}
+		if current, found = expressionFunctions[x.function](argument); !found {
+			return nil, false
+		}
+	case "env":
+		// The parser guarantees a variable name
+		value, ok := os.LookupEnv(steps[0].key)
+		if !ok {
+			return nil, false
+		}
+		current, steps = value, steps[1:]
+	case "now":
+		current = expressionNow().UTC().Format(time.RFC3339)
+	case "headers":
+		current = requestContext.Headers
+	case "query":
+		current = requestContext.Query
+	case "form":
+		current = requestContext.Form
+	case "body":
+		current = requestContext.Body
+	case "claims":
+		current = requestContext.Claims
+	case "client_ip":
+		current = requestContext.ClientIP
+	case "method":
+		current = requestContext.Method
+	case "path":
+		current = requestContext.Path
+	case "params":
+		current = requestContext.Params
+	case "steps":
+		current = requestContext.Steps
+	case "response":
+		current = requestContext.Response
+	}
+
+	for i, step := range steps {
+		// Headers are stored lower-cased
+		if x.root == "headers" && i == 0 {
+			step.key = strings.ToLower(step.key)
+		}
+		var found bool
+		if current, found = selectValue(current, step); !found {
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+// EvaluateString evaluates the expression and formats the result as a header value:
+// strings are used as is, missing and null values are empty and other values are JSON
+func (x *Expression) EvaluateString(requestContext RequestContext) (string, error) {
+	value, found := x.Evaluate(requestContext)
+	if !found {
+		return "", nil
+	}
+	s, err := formatValue(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert result of %s to string: %w", x.source, err)
+	}
+	return s, nil
+}
+
+// EvaluateStrings evaluates the expression as a list of header values. An expression that
+// selects a header the request repeated, such as request.headers['cookie'], returns every
+// value; other expressions return at most one.
+func (x *Expression) EvaluateStrings(requestContext RequestContext) ([]string, error) {
+	if x.root == "headers" && len(x.steps) == 1 && !x.steps[0].isIndex {
+		if values := requestContext.HeaderValues[strings.ToLower(x.steps[0].key)]; len(values) > 1 {
+			var result []string
+			for _, value := range values {
+				if value != "" {
+					result = append(result, value)
+				}
+			}
+			return result, nil
+		}
+	}
+
+	value, err := x.EvaluateString(requestContext)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	return []string{value}, nil
+}
+
+// variable returns the part of the request context, or env, the expression reads from
+func (x *Expression) variable() string {
+	if x.argument != nil {
+		return x.argument.variable()
+	}
+	return x.root
+}
+
+// formatValue converts a value to text: strings are used as is and other values are JSON
+func formatValue(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// selectValue applies one accessor to a value
+func selectValue(value interface{}, step expressionStep) (interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]string:
+		if step.isIndex {
+			return nil, false
+		}
+		s, ok := v[step.key]
+		return s, ok
+	case map[string]interface{}:
+		if step.isIndex {
+			return nil, false
+		}
+		item, ok := v[step.key]
+		return item, ok
+	case []interface{}:
+		if !step.isIndex || step.index >= len(v) {
+			return nil, false
+		}
+		return v[step.index], true
+	case string:
+		// Look inside JSON-encoded objects and arrays
+		trimmed := strings.TrimSpace(v)
+		if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+			return nil, false
+		}
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(trimmed), &decoded); err != nil {
+			return nil, false
+		}
+		return selectValue(decoded, step)
+	case nil:
+		return nil, false
+	}
+
+	// Other Go values, such as structs or typed slices in claims, are viewed as JSON
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Ptr:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, false
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			return nil, false
+		}
+		switch decoded.(type) {
+		case map[string]interface{}, []interface{}:
+			return selectValue(decoded, step)
+		}
+	}
+	return nil, false
+}
+
+// expressionParser is a recursive descent parser for valueFrom expressions
+type expressionParser struct {
+	source string
+	pos    int
+}
+
+func (p *expressionParser) parse() (*Expression, error) {
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.source) {
+		return nil, p.errorf("unexpected %q", p.source[p.pos:p.pos+1])
+	}
+	expr.source = p.source
+	return expr, nil
+}
+
+// expression reads a variable or function call followed by its accessors
+func (p *expressionParser) expression() (*Expression, error) {
+	p.skipSpace()
+	start := p.pos
+	root, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.consume('(') {
+		return p.call(start, root)
+	}
+
+	inRequest := root == "request"
+	if inRequest {
+		if !p.consume('.') {
+			return nil, p.errorf("expected '.' after request")
+		}
+		p.skipSpace()
+		if root, err = p.identifier(); err != nil {
+			return nil, err
+		}
+	}
+	if !isExpressionRoot(root) || (inRequest && (root == "env" || root == "now")) {
+		p.pos -= len(root)
+		return nil, p.errorf("unknown variable %q, expected one of %s", root, strings.Join(expressionRoots, ", "))
+	}
+
+	expr := &Expression{root: root}
+	if root == "env" {
+		// Environment variables are looked up by name, e.g. env['API_TOKEN']
+		p.skipSpace()
+		start := p.pos
+		if !p.consume('[') {
+			return nil, p.errorf("expected a variable name in brackets after env")
+		}
+		step, err := p.bracket()
+		if err != nil {
+			return nil, err
+		}
+		if step.isIndex {
+			p.pos = start
+			return nil, p.errorf("expected a quoted variable name")
+		}
+		expr.steps = append(expr.steps, step)
+	}
+	return p.accessors(start, expr)
+}
+
+// call reads the argument and closing parenthesis of a function call, then its accessors.
+// The argument is an expression or a quoted literal.
+func (p *expressionParser) call(start int, function string) (*Expression, error) {
+	if _, ok := expressionFunctions[function]; !ok {
+		p.pos = start
+		return nil, p.errorf("unknown function %q, expected one of %s", function, strings.Join(functionNames(), ", "))
+	}
+	p.skipSpace()
+	var argument *Expression
+	if p.pos < len(p.source) && (p.source[p.pos] == '\'' || p.source[p.pos] == '"') {
+		literalStart := p.pos
+		literal, err := p.quoted(p.source[p.pos])
+		if err != nil {
+			return nil, err
+		}
+		argument = &Expression{source: p.source[literalStart:p.pos], literal: literal, isLiteral: true}
+	} else {
+		var err error
+		if argument, err = p.expression(); err != nil {
+			return nil, err
+		}
+	}
+	p.skipSpace()
+	if !p.consume(')') {
+		return nil, p.errorf("expected ')'")
+	}
+	return p.accessors(start, &Expression{function: function, argument: argument})
+}
+
+// accessors reads the accessors following a variable or function call
+func (p *expressionParser) accessors(start int, expr *Expression) (*Expression, error) {
+	for {
+		p.skipSpace()
+		switch {
+		case p.consume('.'):
+			p.skipSpace()
+			name, err := p.identifier()
+			if err != nil {
+				return nil, err
+			}
+			expr.steps = append(expr.steps, expressionStep{key: name})
+		case p.consume('['):
+			step, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			expr.steps = append(expr.steps, step)
+		default:
+			expr.source = strings.TrimSpace(p.source[start:p.pos])
+			return expr, nil
+		}
+	}
+}
+
+// identifier reads a name made of letters, digits and underscores, not starting with a digit
+func (p *expressionParser) identifier() (string, error) {
+	start := p.pos
+	for p.pos < len(p.source) {
+		r, size := utf8.DecodeRuneInString(p.source[p.pos:])
+		if !unicode.IsLetter(r) && r != '_' && (p.pos == start || !unicode.IsDigit(r)) {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a name")
+	}
+	return p.source[start:p.pos], nil
+}
+
+// bracket reads the subscript and closing bracket of an accessor after its opening bracket
+func (p *expressionParser) bracket() (expressionStep, error) {
+	p.skipSpace()
+	step, err := p.subscript()
+	if err != nil {
+		return expressionStep{}, err
+	}
+	p.skipSpace()
+	if !p.consume(']') {
+		return expressionStep{}, p.errorf("expected ']'")
+	}
+	return step, nil
+}
+
+// subscript reads a quoted key or a list index
+func (p *expressionParser) subscript() (expressionStep, error) {
+	if p.pos >= len(p.source) {
+		return expressionStep{}, p.errorf("expected a quoted key or an index")
+	}
+
+	quote := p.source[p.pos]
+	if quote == '\'' || quote == '"' {
+		key, err := p.quoted(quote)
+		return expressionStep{key: key}, err
+	}
+
+	start := p.pos
+	for p.pos < len(p.source) && p.source[p.pos] >= '0' && p.source[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return expressionStep{}, p.errorf("expected a quoted key or an index")
+	}
+	index, err := strconv.Atoi(p.source[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return expressionStep{}, p.errorf("invalid index")
+	}
+	return expressionStep{index: index, isIndex: true}, nil
+}
+
+// quoted reads a string literal in single or double quotes. A backslash escapes the
+// next character, so keys can contain quotes.
+func (p *expressionParser) quoted(quote byte) (string, error) {
+	start := p.pos
+	p.pos++
+	var key strings.Builder
+	for p.pos < len(p.source) {
+		c := p.source[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return key.String(), nil
+		case c == '\\' && p.pos+1 < len(p.source):
+			key.WriteByte(p.source[p.pos+1])
+			p.pos += 2
+		default:
+			key.WriteByte(c)
+			p.pos++
+		}
+	}
+	p.pos = start
+	return "", p.errorf("unterminated string")
+}
+
+func (p *expressionParser) consume(c byte) bool {
+	if p.pos < len(p.source) && p.source[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *expressionParser) skipSpace() {
+	for p.pos < len(p.source) && (p.source[p.pos] == ' ' || p.source[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// errorf reports a syntax error at the current position
+func (p *expressionParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w %q: %s at position %d", ErrInvalidExpression, p.source, fmt.Sprintf(format, args...), p.pos+1)
+}
+
+// functionNames returns the names of the built-in functions in alphabetical order
+func functionNames() []string {
+	names := make([]string, 0, len(expressionFunctions))
+	for name := range expressionFunctions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isExpressionRoot reports whether name is a part of the request context
+func isExpressionRoot(name string) bool {
+	for _, root := range expressionRoots {
+		if name == root {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpression_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		message    string
+	}{
+		{"empty", "", "expected a name at position 1"},
+		{"unknown variable", "invalid.jsonpath[expression", `unknown variable "invalid"`},
+		{"unknown request variable", "request.cookies['id']", `unknown variable "cookies"`},
+		{"missing dot after request", "request headers", "expected '.' after request at position 9"},
+		{"unterminated bracket", "headers['key'", "expected ']' at position 14"},
+		{"unterminated string", "headers['key", "unterminated string at position 9"},
+		{"unquoted key", "headers[key]", "expected a quoted key or an index at position 9"},
+		{"trailing dot", "claims.", "expected a name at position 8"},
+		{"stray character", "headers['key']extra", `unexpected "e" at position 15`},
+		{"jsonpath syntax", "$.headers.key", "expected a name at position 1"},
+		{"env without name", "env", "expected a variable name in brackets after env at position 4"},
+		{"env with index", "env[0]", "expected a quoted variable name at position 4"},
+		{"env is not part of the request", "request.env['TOKEN']", `unknown variable "env"`},
+		{"unknown function", "md5(headers['key'])", `unknown function "md5", expected one of base64decode, base64encode, date, days_ago, days_ahead, lower, sha256, trim, upper, urlencode at position 1`},
+		{"now is not part of the request", "request.now", `unknown variable "now"`},
+		{"unterminated literal", "days_ago('7)", "unterminated string at position 10"},
+		{"unclosed call", "trim(headers['key']", "expected ')' at position 20"},
+		{"call without argument", "trim()", "expected a name at position 6"},
+		{"stray parenthesis", "trim(headers['key']))", `unexpected ")" at position 21`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseExpression(tt.expression)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidExpression), "expected ErrInvalidExpression, got %v", err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestExpression_Evaluate(t *testing.T) {
+	t.Setenv("MCPIFY_TEST_ENV_TOKEN", "env-token")
+	t.Setenv("MCPIFY_TEST_ENV_JSON", `{"tenant": "acme"}`)
+	ctx := NewRequestContextFromMap(
+		map[string]string{
+			"Authorization":          "Bearer token123",
+			"X-Session":              "eyJ1c2VyIjoidTEifQ==",
+			"X-Padded":               "  Mixed Case  ",
+			"X-Search":               "a b&c",
+			"X-Mcpify-Provider-Data": `{"auth": {"api_key": "key-abc"}, "items": [{"id": 1}, {"id": 2}], "key[0]": "bracket", "it's": "quote", "empty": null}`,
+		},
+		map[string]string{"client_data": `{"id": "client123"}`, "Tenant": "acme"},
+		map[string]string{"token": "form-token"},
+		"POST", "/api/test",
+	)
+	ctx.Body = map[string]interface{}{"user": map[string]interface{}{"id": "user-1"}}
+	ctx.Claims = map[string]interface{}{"sub": "user-123", "roles": []string{"admin", "dev"}}
+	ctx.Params = map[string]interface{}{"tenant_id": "acme", "limit": float64(10), "filter": map[string]interface{}{"owner": "me"}}
+	ctx.ClientIP = "198.51.100.1"
+	ctx.Params["days"] = float64(7)
+	ctx.Response = map[string]interface{}{"status_code": 200, "body": map[string]interface{}{"data": []interface{}{"a"}}}
+	now := expressionNow
+	expressionNow = func() time.Time { return time.Date(2025, 3, 10, 15, 4, 5, 0, time.FixedZone("CET", 3600)) }
+	defer func() { expressionNow = now }()
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{"header", "request.headers['authorization']", "Bearer token123"},
+		{"header names are case-insensitive", "headers['AUTHORIZATION']", "Bearer token123"},
+		{"double quotes", `request.headers["authorization"]`, "Bearer token123"},
+		{"whitespace around tokens", "request . headers[ 'authorization' ]", "Bearer token123"},
+		{"nested JSON in header", "request.headers['x-mcpify-provider-data'].auth.api_key", "key-abc"},
+		{"brackets inside keys", "request.headers['x-mcpify-provider-data']['key[0]']", "bracket"},
+		{"escaped quote in key", `request.headers['x-mcpify-provider-data']['it\'s']`, "quote"},
+		{"list index", "request.headers['x-mcpify-provider-data'].items[1].id", "2"},
+		{"index out of range", "request.headers['x-mcpify-provider-data'].items[5].id", ""},
+		{"object result", "request.headers['x-mcpify-provider-data'].auth", `{"api_key":"key-abc"}`},
+		{"null result", "request.headers['x-mcpify-provider-data'].empty", ""},
+		{"member of a plain string", "request.headers['authorization'].extra", ""},
+		{"query keys are case-sensitive", "request.query['tenant']", ""},
+		{"nested JSON in query", "request.query['client_data'].id", "client123"},
+		{"form", "request.form['token']", "form-token"},
+		{"body", "request.body.user.id", "user-1"},
+		{"typed claim list", "request.claims.roles[0]", "admin"},
+		{"method", "request.method", "POST"},
+		{"client ip", "request.client_ip", "198.51.100.1"},
+		{"path", "path", "/api/test"},
+		{"missing header", "request.headers['missing'].value", ""},
+		{"tool argument", "params['tenant_id']", "acme"},
+		{"numeric tool argument", "request.params.limit", "10"},
+		{"nested tool argument", "params.filter.owner", "me"},
+		{"missing tool argument", "params['region']", ""},
+		{"environment variable", "env['MCPIFY_TEST_ENV_TOKEN']", "env-token"},
+		{"nested JSON in environment variable", `env["MCPIFY_TEST_ENV_JSON"].tenant`, "acme"},
+		{"missing environment variable", "env['MCPIFY_TEST_ENV_MISSING']", ""},
+		{"base64encode", "base64encode(request.query['client_data'].id)", "Y2xpZW50MTIz"},
+		{"base64decode with accessor", "base64decode(headers['x-session']).user", "u1"},
+		{"invalid base64", "base64decode(headers['x-padded'])", ""},
+		{"urlencode", "urlencode(headers['x-search'])", "a+b%26c"},
+		{"sha256", "sha256(headers['x-mcpify-provider-data'].auth.api_key)", "5f4c6d215dcdc1bdb4b8a95f255b15cb2d45c083e14ad6e7e5c0bf2591a246cd"},
+		{"nested functions", "upper( trim(headers['x-padded']) )", "MIXED CASE"},
+		{"lower", "lower(method)", "post"},
+		{"function of a missing value", "base64encode(headers['missing'])", ""},
+		{"function of a JSON value", "base64encode(params.filter)", "eyJvd25lciI6Im1lIn0="},
+		{"now", "now", "2025-03-10T14:04:05Z"},
+		{"date", "date(now)", "2025-03-10"},
+		{"days ago", "date(days_ago(params.days))", "2025-03-03"},
+		{"days ahead of a literal", "days_ahead('1')", "2025-03-11T14:04:05Z"},
+		{"date of a literal", `date("2025-01-02T23:00:00Z")`, "2025-01-02"},
+		{"invalid date", "date(method)", ""},
+		{"response", "response.body.data[0]", "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseExpression(tt.expression)
+			require.NoError(t, err)
+			result, err := expr.EvaluateString(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCompileExpression_CachesParsedExpressions(t *testing.T) {
+	source := "request.headers['x-compiled'].apikey"
+	header := HeaderConfig{Name: "X-Key", ValueFrom: source}
+	require.NoError(t, header.Validate())
+
+	cached, ok := compiledExpressions.Load(source)
+	require.True(t, ok, "Validate should compile the expression")
+
+	expr, err := compileExpression(source)
+	require.NoError(t, err)
+	assert.Same(t, cached, expr)
+
+	_, err = compileExpression("request.cookies['id']")
+	assert.True(t, errors.Is(err, ErrInvalidExpression))
+	_, ok = compiledExpressions.Load("request.cookies['id']")
+	assert.False(t, ok, "invalid expressions are not cached")
+}
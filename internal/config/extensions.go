@@ -0,0 +1,48 @@
+package config
+
+import (
+	"errors"
+	"slices"
+)
+
+// ExtensionConfig loads a Go plugin or a WASM transform module whose hooks run on the tool
+// calls of the upstream APIs
+type ExtensionConfig struct {
+	Path string `yaml:"path" json:"path"` // Shared object built with -buildmode=plugin
+	// WASM is a WebAssembly module rewriting upstream requests and responses in a sandbox,
+	// used instead of a plugin path
+	WASM string `yaml:"wasm" json:"wasm"`
+	// Tools limits the extension to the tools matching these names or patterns; empty runs
+	// it for every tool
+	Tools []string `yaml:"tools" json:"tools"`
+	// Options are passed to the plugin's NewExtension function
+	Options map[string]interface{} `yaml:"options" json:"options"`
+}
+
+// Validate validates the ExtensionConfig
+func (e *ExtensionConfig) Validate() error {
+	switch {
+	case e.Path == "" && e.WASM == "":
+		return errors.New("path or wasm is required")
+	case e.Path != "" && e.WASM != "":
+		return errors.New("path and wasm are mutually exclusive")
+	case e.WASM != "" && len(e.Options) > 0:
+		return errors.New("options are passed to plugins only, not to wasm modules")
+	case slices.Contains(e.Tools, ""):
+		return errors.New("tools must not contain an empty pattern")
+	}
+	return nil
+}
+
+// AppliesTo reports whether the extension runs for the named tool
+func (e *ExtensionConfig) AppliesTo(tool string) bool {
+	if len(e.Tools) == 0 {
+		return true
+	}
+	for _, pattern := range e.Tools {
+		if MatchPath(pattern, tool) {
+			return true
+		}
+	}
+	return false
+}
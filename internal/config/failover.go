@@ -0,0 +1,72 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// DefaultFailoverInterval is the time between health checks of a base URL that is down
+const DefaultFailoverInterval = 30 * time.Second
+
+// FailoverConfig lists secondary base URLs that take over when the API's base_url is down. A
+// base URL is down after a request to it fails to get a response or gets a 502, 503 or 504,
+// and is used again once a health check of it succeeds.
+type FailoverConfig struct {
+	BaseURLs   []string      `yaml:"base_urls" json:"base_urls"`     // Secondary base URLs, tried in order after base_url
+	HealthPath string        `yaml:"health_path" json:"health_path"` // Path requested to check a base URL, the base URL itself when empty
+	Interval   time.Duration `yaml:"interval" json:"interval"`       // Time between health checks of a base URL that is down, 30s by default
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for FailoverConfig
+func (f *FailoverConfig) UnmarshalJSON(data []byte) error {
+	type Alias FailoverConfig
+	aux := &struct {
+		Interval string `json:"interval"`
+		*Alias
+	}{
+		Alias: (*Alias)(f),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Interval != "" {
+		duration, err := time.ParseDuration(aux.Interval)
+		if err != nil {
+			return err
+		}
+		f.Interval = duration
+	}
+
+	return nil
+}
+
+// Enabled reports whether secondary base URLs are configured
+func (f *FailoverConfig) Enabled() bool {
+	return len(f.BaseURLs) > 0
+}
+
+// IntervalOrDefault returns the time between health checks of a base URL that is down
+func (f *FailoverConfig) IntervalOrDefault() time.Duration {
+	if f.Interval > 0 {
+		return f.Interval
+	}
+	return DefaultFailoverInterval
+}
+
+// Validate validates the FailoverConfig
+func (f *FailoverConfig) Validate() error {
+	for _, baseURL := range f.BaseURLs {
+		parsed, err := url.Parse(baseURL)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("base URLs must be absolute URLs, got %q", baseURL)
+		}
+	}
+	if f.Interval < 0 {
+		return fmt.Errorf("interval cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailoverConfig(t *testing.T) {
+	var failover FailoverConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"base_urls": ["https://eu.api.example.com"], "interval": "10s"}`), &failover))
+	assert.True(t, failover.Enabled())
+	assert.Equal(t, 10*time.Second, failover.IntervalOrDefault())
+	assert.Equal(t, DefaultFailoverInterval, (&FailoverConfig{}).IntervalOrDefault())
+
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.OpenAPI.Failover = failover
+	assert.NoError(t, cfg.Validate())
+
+	cfg.OpenAPI.Failover.BaseURLs = []string{"eu.api.example.com"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid failover")
+}
@@ -112,6 +112,15 @@ func TestHeaderConfig_UnmarshalJSON(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:     "header with several values",
+			jsonData: `{"name": "Accept", "values": ["application/json", "text/plain"]}`,
+			expected: HeaderConfig{
+				Name:   "Accept",
+				Values: []string{"application/json", "text/plain"},
+			},
+			wantErr: false,
+		},
 		{
 			name:     "header with both value and valueFrom (should error)",
 			jsonData: `{"name": "Test-Header", "value": "static-value", "valueFrom": "request.headers['dynamic']"}`,
@@ -203,6 +212,55 @@ func TestHeaderConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid template header",
+			header: HeaderConfig{
+				Name:  "Authorization",
+				Value: "Bearer {{ request.headers['x-mcpify-provider-data'].apikey }}",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid: malformed template",
+			header: HeaderConfig{
+				Name:  "Authorization",
+				Value: "Bearer {{ request.headers['x-mcpify-provider-data']",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid multi-value header",
+			header: HeaderConfig{
+				Name:   "Accept",
+				Values: []string{"application/json", "text/plain"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid: values with value",
+			header: HeaderConfig{
+				Name:   "Accept",
+				Value:  "application/json",
+				Values: []string{"text/plain"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid: empty entry in values",
+			header: HeaderConfig{
+				Name:   "Accept",
+				Values: []string{"application/json", ""},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid: malformed valueFrom",
+			header: HeaderConfig{
+				Name:      "Test-Header",
+				ValueFrom: "request.headers['unterminated",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
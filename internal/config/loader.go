@@ -3,48 +3,84 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
 )
 
 // Loader handles configuration loading from various sources
-type Loader struct{}
+type Loader struct {
+	// Strict rejects configuration files containing keys that do not match any setting
+	Strict bool
+	// Profile selects an entry of the profiles section to overlay on the base settings
+	Profile string
+
+	unknownKeys []string
+	stdin       io.Reader
+}
+
+// StdinPath is the configuration path that reads the configuration from standard input
+const StdinPath = "-"
 
 // NewLoader creates a new configuration loader
 func NewLoader() *Loader {
-	return &Loader{}
+	return &Loader{stdin: os.Stdin}
 }
 
-// Load loads configuration from a file or returns default config
+// Load loads configuration from a file, from standard input when configPath is StdinPath,
+// or returns the default config when configPath is empty.
+// MCPIFY_* environment variables override values from the file and the defaults.
+// Unknown keys are reported by UnknownKeys, or rejected when Strict is set.
 func (l *Loader) Load(configPath string) (*Config, error) {
-	// If no config path provided, return default config
-	if configPath == "" {
-		return Default(), nil
-	}
+	l.unknownKeys = nil
 
-	// Check if file exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("configuration file not found: %s", configPath)
+	// Without a file, the configuration comes from the defaults and the environment only
+	if configPath == "" {
+		if l.Profile != "" {
+			return nil, fmt.Errorf("profile %s requires a configuration file", l.Profile)
+		}
+		config := Default()
+		if err := ApplyEnvOverrides(config); err != nil {
+			return nil, err
+		}
+		// Upstream APIs set through MCPIFY_APIS need their defaults too
+		merged := l.mergeWithDefaults(*config)
+		return &merged, nil
+	}
+
+	content, ext, err := l.read(configPath)
+	if err != nil {
+		return nil, err
 	}
 
-	// Read file content
-	content, err := os.ReadFile(configPath)
+	// Substitute ${VAR} and ${VAR:-default} references from the environment in string values
+	content, err = expandEnvValues(content, ext, os.LookupEnv)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+		return nil, fmt.Errorf("failed to parse configuration file: %w", err)
 	}
 
-	// Determine file format and parse
-	ext := strings.ToLower(filepath.Ext(configPath))
 	var config Config
 
+	// Overlay the selected profile on the base settings
+	if l.Profile != "" {
+		content, err = applyProfile(content, ext, l.Profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	switch ext {
 	case ".yaml", ".yml":
 		err = yaml.Unmarshal(content, &config)
 	case ".json":
 		err = json.Unmarshal(content, &config)
+	case ".toml":
+		err = unmarshalTOML(content, &config)
 	default:
 		return nil, fmt.Errorf("unsupported configuration file format: %s", ext)
 	}
@@ -53,12 +89,86 @@ func (l *Loader) Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse configuration file: %w", err)
 	}
 
+	// Detect misspelled or unsupported keys, which the decoders silently ignore
+	l.unknownKeys, err = findUnknownKeys(content, ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file: %w", err)
+	}
+	if l.Strict && len(l.unknownKeys) > 0 {
+		return nil, fmt.Errorf("unknown configuration keys: %s", strings.Join(l.unknownKeys, ", "))
+	}
+
 	// Merge with defaults for missing values
 	config = l.mergeWithDefaults(config)
 
+	// Apply environment variable overrides on top of file values
+	if err := ApplyEnvOverrides(&config); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
+// read returns the configuration content and the extension that selects its format. Content
+// from standard input is JSON when it starts with "{" and YAML otherwise.
+func (l *Loader) read(configPath string) ([]byte, string, error) {
+	if configPath == StdinPath {
+		stdin := l.stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read configuration from stdin: %w", err)
+		}
+		if strings.HasPrefix(strings.TrimSpace(string(content)), "{") {
+			return content, ".json", nil
+		}
+		return content, ".yaml", nil
+	}
+
+	// Check if file exists
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, "", fmt.Errorf("configuration file not found: %s", configPath)
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	return content, strings.ToLower(filepath.Ext(configPath)), nil
+}
+
+// UnknownKeys returns the keys from the last loaded file that do not match any setting
+func (l *Loader) UnknownKeys() []string {
+	return l.unknownKeys
+}
+
+// findUnknownKeys decodes the raw document and lists keys that do not map to a Config field
+func findUnknownKeys(content []byte, ext string) ([]string, error) {
+	doc, err := parseDocument(content, ext)
+	if err != nil {
+		return nil, err
+	}
+	return unknownKeys(doc, reflect.TypeOf(Config{}), ""), nil
+}
+
+// unmarshalTOML decodes TOML content by converting it to JSON, so TOML files go through
+// the same field names and custom unmarshaling (durations, headers) as JSON files
+func unmarshalTOML(content []byte, config *Config) error {
+	var raw map[string]interface{}
+	if _, err := toml.Decode(string(content), &raw); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, config)
+}
+
 // mergeWithDefaults merges the loaded config with default values
 func (l *Loader) mergeWithDefaults(config Config) Config {
 	defaults := Default()
@@ -97,25 +207,13 @@ func (l *Loader) mergeWithDefaults(config Config) Config {
 	}
 
 	// Merge OpenAPI config
-	if config.OpenAPI.Timeout == 0 {
-		config.OpenAPI.Timeout = defaults.OpenAPI.Timeout
-	}
-	if config.OpenAPI.MaxRetries == 0 {
-		config.OpenAPI.MaxRetries = defaults.OpenAPI.MaxRetries
-	}
-	// ToolPrefix defaults to empty string, no need to override
-	if config.OpenAPI.Auth.Type == "" {
-		config.OpenAPI.Auth.Type = defaults.OpenAPI.Auth.Type
-	}
-	if config.OpenAPI.Headers == nil {
-		config.OpenAPI.Headers = HeadersConfig{}
+	mergeOpenAPIDefaults(&config.OpenAPI, defaults.OpenAPI)
+	for i := range config.APIs {
+		mergeOpenAPIDefaults(&config.APIs[i], defaults.OpenAPI)
 	}
 
-	// Merge security config
-	// Note: For boolean fields, we can't easily detect if they were explicitly set to false
-	// So we'll always apply the default if the struct is zero-valued
+	// Merge security config; rate limiting stays disabled unless enabled explicitly
 	if config.Security.RateLimiting.RequestsPerMinute == 0 {
-		config.Security.RateLimiting.Enabled = defaults.Security.RateLimiting.Enabled
 		config.Security.RateLimiting.RequestsPerMinute = defaults.Security.RateLimiting.RequestsPerMinute
 	}
 	if config.Security.RequestSizeLimit == "" {
@@ -124,3 +222,23 @@ func (l *Loader) mergeWithDefaults(config Config) Config {
 
 	return config
 }
+
+// mergeOpenAPIDefaults fills missing values of an upstream API block with defaults
+func mergeOpenAPIDefaults(api *OpenAPIConfig, defaults OpenAPIConfig) {
+	if api.Timeout == 0 {
+		api.Timeout = defaults.Timeout
+	}
+	if api.MaxRetries == 0 {
+		api.MaxRetries = defaults.MaxRetries
+	}
+	// ToolPrefix defaults to empty string, no need to override
+	if api.Auth.Type == "" {
+		api.Auth.Type = defaults.Auth.Type
+	}
+	if api.Headers == nil {
+		api.Headers = HeadersConfig{}
+	}
+	if api.MaxResponseSize == "" {
+		api.MaxResponseSize = defaults.MaxResponseSize
+	}
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -484,6 +487,436 @@ func TestLoad_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestLoad_TOML(t *testing.T) {
+	tomlContent := `
+[server]
+transport = "http"
+
+[server.http]
+host = "0.0.0.0"
+port = 7070
+session_timeout = "10m"
+
+[server.http.cors]
+enabled = true
+origins = ["https://app.example.com"]
+
+[logging]
+level = "debug"
+
+[openapi]
+spec_path = "https://api.test.com/openapi.json"
+base_url = "https://api.test.com"
+timeout = "20s"
+exclude_paths = ["/health"]
+
+[openapi.auth]
+type = "bearer"
+token = "toml-token"
+
+[[openapi.headers]]
+header = { name = "Accept", value = "application/json" }
+
+[[openapi.headers]]
+header = { name = "X-Request-ID", valueFrom = "request.headers['X-Request-ID']" }
+
+[security.rate_limiting]
+enabled = true
+requests_per_minute = 75
+`
+
+	tmpFile, err := os.CreateTemp("", "test_config.*.toml")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer func() {
+		_ = os.Remove(tmpFile.Name())
+	}()
+
+	if _, err := tmpFile.WriteString(tomlContent); err != nil {
+		t.Fatalf("Failed to write TOML content: %v", err)
+	}
+	_ = tmpFile.Close()
+
+	loader := NewLoader()
+	config, err := loader.Load(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Expected no error loading TOML, got %v", err)
+	}
+
+	if config.Server.HTTP.Host != "0.0.0.0" {
+		t.Errorf("Expected host 0.0.0.0, got %s", config.Server.HTTP.Host)
+	}
+	if config.Server.HTTP.Port != 7070 {
+		t.Errorf("Expected port 7070, got %d", config.Server.HTTP.Port)
+	}
+	if config.Server.HTTP.SessionTimeout != 10*time.Minute {
+		t.Errorf("Expected session timeout 10m, got %v", config.Server.HTTP.SessionTimeout)
+	}
+	if len(config.Server.HTTP.CORS.Origins) != 1 || config.Server.HTTP.CORS.Origins[0] != "https://app.example.com" {
+		t.Errorf("Expected CORS origins [https://app.example.com], got %v", config.Server.HTTP.CORS.Origins)
+	}
+	if config.Logging.Level != "debug" {
+		t.Errorf("Expected log level debug, got %s", config.Logging.Level)
+	}
+	if config.OpenAPI.Timeout != 20*time.Second {
+		t.Errorf("Expected timeout 20s, got %v", config.OpenAPI.Timeout)
+	}
+	if config.OpenAPI.Auth.Token != "toml-token" {
+		t.Errorf("Expected token toml-token, got %s", config.OpenAPI.Auth.Token)
+	}
+	if config.OpenAPI.Headers.GetValue("Accept") != "application/json" {
+		t.Errorf("Expected Accept header application/json, got %s", config.OpenAPI.Headers.GetValue("Accept"))
+	}
+	if len(config.OpenAPI.Headers) != 2 {
+		t.Errorf("Expected 2 headers, got %d", len(config.OpenAPI.Headers))
+	}
+	if config.Security.RateLimiting.RequestsPerMinute != 75 {
+		t.Errorf("Expected 75 requests per minute, got %d", config.Security.RateLimiting.RequestsPerMinute)
+	}
+	// Defaults still apply to fields missing from the file
+	if config.Logging.Format != Default().Logging.Format {
+		t.Errorf("Expected default log format, got %s", config.Logging.Format)
+	}
+}
+
+func TestLoad_InvalidTOML(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_config.*.toml")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer func() {
+		_ = os.Remove(tmpFile.Name())
+	}()
+
+	if _, err := tmpFile.WriteString("[server\ntransport = \"http\"\n"); err != nil {
+		t.Fatalf("Failed to write invalid TOML content: %v", err)
+	}
+	_ = tmpFile.Close()
+
+	loader := NewLoader()
+	config, err := loader.Load(tmpFile.Name())
+
+	if err == nil {
+		t.Error("Expected error for invalid TOML, got nil")
+	}
+
+	if config != nil {
+		t.Error("Expected nil config for invalid TOML")
+	}
+}
+
+func TestLoad_MultipleAPIs(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_config.*.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer func() {
+		_ = os.Remove(tmpFile.Name())
+	}()
+
+	content := `
+apis:
+  - spec_path: "https://petstore.example.com/openapi.json"
+    tool_prefix: "pets"
+    auth:
+      type: bearer
+      token: "pets-token"
+  - spec_path: "https://weather.example.com/openapi.json"
+    base_url: "https://weather.example.com/v2"
+    tool_prefix: "weather"
+    timeout: "5s"
+    exclude_paths:
+      - "/admin/*"
+`
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write config content: %v", err)
+	}
+	_ = tmpFile.Close()
+
+	config, err := NewLoader().Load(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+
+	apis := config.APIConfigs()
+	if len(apis) != 2 {
+		t.Fatalf("Expected 2 APIs, got %d", len(apis))
+	}
+	if apis[0].ToolPrefix != "pets" || apis[0].Auth.Token != "pets-token" {
+		t.Errorf("Unexpected first API: %+v", apis[0])
+	}
+	// Defaults are merged into each API
+	if apis[0].Timeout != Default().OpenAPI.Timeout {
+		t.Errorf("Expected default timeout, got %v", apis[0].Timeout)
+	}
+	if apis[0].MaxResponseSize != Default().OpenAPI.MaxResponseSize {
+		t.Errorf("Expected default max response size, got %s", apis[0].MaxResponseSize)
+	}
+	if apis[1].Timeout != 5*time.Second {
+		t.Errorf("Expected timeout 5s, got %v", apis[1].Timeout)
+	}
+	if len(apis[1].ExcludePaths) != 1 || apis[1].ExcludePaths[0] != "/admin/*" {
+		t.Errorf("Expected exclude paths [/admin/*], got %v", apis[1].ExcludePaths)
+	}
+}
+
+func TestLoad_UnknownKeys(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_config.*.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer func() {
+		_ = os.Remove(tmpFile.Name())
+	}()
+
+	content := `
+openapi:
+  spec_path: "https://api.example.com/openapi.json"
+  includ_paths:
+    - "/users/*"
+`
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write config content: %v", err)
+	}
+	_ = tmpFile.Close()
+
+	// Unknown keys are reported but ignored by default
+	loader := NewLoader()
+	config, err := loader.Load(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(config.OpenAPI.IncludePaths) != 0 {
+		t.Errorf("Expected misspelled include paths to be ignored, got %v", config.OpenAPI.IncludePaths)
+	}
+	want := `openapi.includ_paths (did you mean "include_paths"?)`
+	if keys := loader.UnknownKeys(); len(keys) != 1 || keys[0] != want {
+		t.Errorf("Expected unknown keys [%s], got %v", want, keys)
+	}
+
+	// Strict mode rejects them
+	loader.Strict = true
+	config, err = loader.Load(tmpFile.Name())
+	if err == nil {
+		t.Fatal("Expected error in strict mode")
+	}
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected error to mention %s, got %v", want, err)
+	}
+	if config != nil {
+		t.Error("Expected nil config in strict mode")
+	}
+}
+
+func TestLoad_SamplesHaveNoUnknownKeys(t *testing.T) {
+	samples, err := filepath.Glob("../../config/samples/config.*")
+	if err != nil {
+		t.Fatalf("Failed to list samples: %v", err)
+	}
+	if len(samples) == 0 {
+		t.Fatal("Expected sample configuration files")
+	}
+
+	for _, sample := range samples {
+		t.Run(filepath.Base(sample), func(t *testing.T) {
+			loader := NewLoader()
+			loader.Strict = true
+			if _, err := loader.Load(sample); err != nil {
+				t.Errorf("Expected sample to load in strict mode, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad_Profiles(t *testing.T) {
+	files := map[string]string{
+		"yaml": `
+openapi:
+  spec_path: "https://api.example.com/openapi.json"
+  base_url: "https://dev.api.example.com"
+  timeout: "10s"
+  include_paths: ["/users/*", "/orders/*"]
+  auth:
+    type: bearer
+    token: "dev-token"
+profiles:
+  prod:
+    openapi:
+      base_url: "https://api.example.com"
+      include_paths: ["/users/*"]
+      auth:
+        token: "prod-token"
+    security:
+      rate_limiting:
+        enabled: true
+        requests_per_minute: 50
+`,
+		"json": `{
+  "openapi": {
+    "spec_path": "https://api.example.com/openapi.json",
+    "base_url": "https://dev.api.example.com",
+    "timeout": "10s",
+    "include_paths": ["/users/*", "/orders/*"],
+    "auth": {"type": "bearer", "token": "dev-token"}
+  },
+  "profiles": {
+    "prod": {
+      "openapi": {
+        "base_url": "https://api.example.com",
+        "include_paths": ["/users/*"],
+        "auth": {"token": "prod-token"}
+      },
+      "security": {"rate_limiting": {"enabled": true, "requests_per_minute": 50}}
+    }
+  }
+}`,
+		"toml": `
+[openapi]
+spec_path = "https://api.example.com/openapi.json"
+base_url = "https://dev.api.example.com"
+timeout = "10s"
+include_paths = ["/users/*", "/orders/*"]
+
+[openapi.auth]
+type = "bearer"
+token = "dev-token"
+
+[profiles.prod.openapi]
+base_url = "https://api.example.com"
+include_paths = ["/users/*"]
+
+[profiles.prod.openapi.auth]
+token = "prod-token"
+
+[profiles.prod.security.rate_limiting]
+enabled = true
+requests_per_minute = 50
+`,
+	}
+
+	for format, content := range files {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config."+format)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatalf("Failed to write config content: %v", err)
+			}
+
+			// Without a profile the base settings apply
+			config, err := NewLoader().Load(path)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if config.OpenAPI.BaseURL != "https://dev.api.example.com" || config.OpenAPI.Auth.Token != "dev-token" {
+				t.Errorf("Expected base settings, got base URL %s and token %s", config.OpenAPI.BaseURL, config.OpenAPI.Auth.Token)
+			}
+
+			loader := NewLoader()
+			loader.Profile = "prod"
+			loader.Strict = true
+			config, err = loader.Load(path)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if config.OpenAPI.BaseURL != "https://api.example.com" {
+				t.Errorf("Expected profile base URL, got %s", config.OpenAPI.BaseURL)
+			}
+			// Mappings are merged, so settings the profile leaves out are kept
+			if config.OpenAPI.Auth.Type != "bearer" || config.OpenAPI.Auth.Token != "prod-token" {
+				t.Errorf("Expected bearer auth with profile token, got %+v", config.OpenAPI.Auth)
+			}
+			if config.OpenAPI.Timeout != 10*time.Second {
+				t.Errorf("Expected base timeout 10s, got %v", config.OpenAPI.Timeout)
+			}
+			// Lists are replaced
+			if len(config.OpenAPI.IncludePaths) != 1 || config.OpenAPI.IncludePaths[0] != "/users/*" {
+				t.Errorf("Expected include paths [/users/*], got %v", config.OpenAPI.IncludePaths)
+			}
+			if !config.Security.RateLimiting.Enabled || config.Security.RateLimiting.RequestsPerMinute != 50 {
+				t.Errorf("Expected profile rate limiting, got %+v", config.Security.RateLimiting)
+			}
+
+			loader.Profile = "staging"
+			_, err = loader.Load(path)
+			if !errors.Is(err, ErrUnknownProfile) {
+				t.Errorf("Expected ErrUnknownProfile, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad_ProfileWithoutFile(t *testing.T) {
+	loader := NewLoader()
+	loader.Profile = "prod"
+	if _, err := loader.Load(""); err == nil {
+		t.Error("Expected error when selecting a profile without a configuration file")
+	}
+}
+
+func TestLoad_Stdin(t *testing.T) {
+	t.Setenv("MCPIFY_TEST_TOKEN", "stdin-token")
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "yaml",
+			content: "openapi:\n  spec_path: \"spec.json\"\n  timeout: 5s\n  auth:\n    type: bearer\n    token: \"${MCPIFY_TEST_TOKEN}\"\n",
+		},
+		{
+			name:    "json",
+			content: `{"openapi": {"spec_path": "spec.json", "timeout": "5s", "auth": {"type": "bearer", "token": "${MCPIFY_TEST_TOKEN}"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := NewLoader()
+			loader.stdin = strings.NewReader(tt.content)
+			config, err := loader.Load(StdinPath)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if config.OpenAPI.SpecPath != "spec.json" || config.OpenAPI.Timeout != 5*time.Second {
+				t.Errorf("Expected values from stdin, got spec %q and timeout %v", config.OpenAPI.SpecPath, config.OpenAPI.Timeout)
+			}
+			if config.OpenAPI.Auth.Token != "stdin-token" {
+				t.Errorf("Expected token from environment, got %q", config.OpenAPI.Auth.Token)
+			}
+			if config.Server.HTTP.Port != Default().Server.HTTP.Port {
+				t.Errorf("Expected default port, got %d", config.Server.HTTP.Port)
+			}
+		})
+	}
+}
+
+func TestLoad_EnvironmentOnly(t *testing.T) {
+	t.Setenv("MCPIFY_SERVER_TRANSPORT", "stdio")
+	t.Setenv("MCPIFY_APIS", `[{"spec_path": "users.json", "base_url": "https://users.example.com"}]`)
+
+	config, err := NewLoader().Load("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.Server.Transport != "stdio" {
+		t.Errorf("Expected transport from environment, got %s", config.Server.Transport)
+	}
+	if len(config.APIs) != 1 || config.APIs[0].SpecPath != "users.json" {
+		t.Fatalf("Expected one API from environment, got %+v", config.APIs)
+	}
+	// APIs from the environment get the same defaults as APIs from a file
+	if config.APIs[0].Timeout != Default().OpenAPI.Timeout || config.APIs[0].Auth.Type != "none" {
+		t.Errorf("Expected API defaults, got timeout %v and auth %q", config.APIs[0].Timeout, config.APIs[0].Auth.Type)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected valid configuration, got %v", err)
+	}
+}
+
 func TestMergeWithDefaults(t *testing.T) {
 	loader := NewLoader()
 
@@ -553,8 +986,8 @@ func TestMergeWithDefaults(t *testing.T) {
 		t.Error("Expected headers to be initialized")
 	}
 
-	if !merged.Security.RateLimiting.Enabled {
-		t.Error("Expected default rate limiting to be enabled")
+	if merged.Security.RateLimiting.Enabled {
+		t.Error("Expected rate limiting to be disabled by default")
 	}
 
 	if merged.Security.RateLimiting.RequestsPerMinute != 100 {
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultHealthCheckTimeout bounds the health_check probe when no timeout is configured
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// MetaToolsConfig enables built-in tools that help agents find their way in large APIs
+type MetaToolsConfig struct {
+	// ListEndpoints registers list_endpoints, which lists the tools grouped by tag or path
+	// with one-line descriptions
+	ListEndpoints bool `yaml:"list_endpoints" json:"list_endpoints"`
+	// GetToolSchema registers get_tool_schema, which returns the full schemas, operation and
+	// examples of a tool, so clients can list tools in brief and fetch details on use
+	GetToolSchema bool `yaml:"get_tool_schema" json:"get_tool_schema"`
+	// SearchTools registers search_tools, which ranks the tools by how well their names and
+	// descriptions match a query
+	SearchTools bool `yaml:"search_tools" json:"search_tools"`
+	// HealthCheck registers health_check, which probes the upstream APIs
+	HealthCheck HealthCheckToolConfig `yaml:"health_check" json:"health_check"`
+}
+
+// HealthCheckToolConfig configures the health_check tool, which sends a GET request to each
+// API's base URL, or to a health path under it, and reports reachability and latency
+type HealthCheckToolConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Path is requested under the base URL, e.g. /health; the base URL itself when empty
+	Path string `yaml:"path" json:"path"`
+	// Timeout bounds each probe, 5s by default
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for HealthCheckToolConfig
+func (h *HealthCheckToolConfig) UnmarshalJSON(data []byte) error {
+	type Alias HealthCheckToolConfig
+	aux := &struct {
+		Timeout string `json:"timeout"`
+		*Alias
+	}{
+		Alias: (*Alias)(h),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Timeout != "" {
+		timeout, err := time.ParseDuration(aux.Timeout)
+		if err != nil {
+			return err
+		}
+		h.Timeout = timeout
+	}
+
+	return nil
+}
+
+// TimeoutOrDefault returns the configured probe timeout, or DefaultHealthCheckTimeout
+func (h *HealthCheckToolConfig) TimeoutOrDefault() time.Duration {
+	if h.Timeout > 0 {
+		return h.Timeout
+	}
+	return DefaultHealthCheckTimeout
+}
+
+// Validate validates the MetaToolsConfig
+func (m *MetaToolsConfig) Validate() error {
+	if m.HealthCheck.Path != "" && !strings.HasPrefix(m.HealthCheck.Path, "/") {
+		return fmt.Errorf("health_check path %q must start with /", m.HealthCheck.Path)
+	}
+	if m.HealthCheck.Timeout < 0 {
+		return fmt.Errorf("health_check timeout cannot be negative")
+	}
+	return nil
+}
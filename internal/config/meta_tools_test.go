@@ -0,0 +1,31 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaToolsConfig(t *testing.T) {
+	var metaTools MetaToolsConfig
+	assert.Equal(t, DefaultHealthCheckTimeout, metaTools.HealthCheck.TimeoutOrDefault())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"list_endpoints": true, "health_check": {"enabled": true, "path": "/health", "timeout": "2s"}}`), &metaTools))
+	assert.Equal(t, MetaToolsConfig{
+		ListEndpoints: true,
+		HealthCheck:   HealthCheckToolConfig{Enabled: true, Path: "/health", Timeout: 2 * time.Second},
+	}, metaTools)
+
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.MetaTools = metaTools
+	require.NoError(t, cfg.Validate())
+
+	cfg.MetaTools.HealthCheck.Path = "health"
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid meta_tools")
+}
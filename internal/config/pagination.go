@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Pagination styles
+const (
+	PaginationPage   = "page"   // Increment a page number argument
+	PaginationCursor = "cursor" // Pass the cursor returned by each page to the next request
+	PaginationLink   = "link"   // Follow the rel="next" URL of the Link response header
+)
+
+// defaultMaxPages caps the pages fetched by one call when max_pages is not set
+const defaultMaxPages = 10
+
+// PaginationConfig makes a tool fetch every page of a paginated operation and return the
+// combined items, so clients do not have to loop themselves. Response values are read with
+// valueFrom expressions over the response: body for the decoded body and headers for the
+// response headers, e.g. "body.data" or "headers['x-next-cursor']".
+type PaginationConfig struct {
+	Style       string `yaml:"style" json:"style"`               // page, cursor or link
+	MaxPages    int    `yaml:"max_pages" json:"max_pages"`       // Most pages fetched per call, 10 by default
+	Items       string `yaml:"items" json:"items"`               // Expression selecting the items of a page, "body" by default
+	PageParam   string `yaml:"page_param" json:"page_param"`     // Page number argument for the page style, "page" by default
+	CursorParam string `yaml:"cursor_param" json:"cursor_param"` // Cursor argument for the cursor style, "cursor" by default
+	NextCursor  string `yaml:"next_cursor" json:"next_cursor"`   // Expression selecting the next cursor, required for the cursor style
+}
+
+// Validate validates the PaginationConfig
+func (p *PaginationConfig) Validate() error {
+	switch p.Style {
+	case PaginationPage, PaginationLink:
+	case PaginationCursor:
+		if p.NextCursor == "" {
+			return errors.New("next_cursor is required for the cursor style")
+		}
+	default:
+		return fmt.Errorf("invalid style %q, expected one of %s", p.Style, strings.Join([]string{PaginationPage, PaginationCursor, PaginationLink}, ", "))
+	}
+	if p.MaxPages < 0 {
+		return errors.New("max_pages must not be negative")
+	}
+	for _, source := range []string{p.Items, p.NextCursor} {
+		if source == "" {
+			continue
+		}
+		if _, err := compileExpression(source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PageLimit returns the most pages fetched per call
+func (p *PaginationConfig) PageLimit() int {
+	if p.MaxPages > 0 {
+		return p.MaxPages
+	}
+	return defaultMaxPages
+}
+
+// PageParamName returns the page number argument of the page style
+func (p *PaginationConfig) PageParamName() string {
+	if p.PageParam != "" {
+		return p.PageParam
+	}
+	return "page"
+}
+
+// CursorParamName returns the cursor argument of the cursor style
+func (p *PaginationConfig) CursorParamName() string {
+	if p.CursorParam != "" {
+		return p.CursorParam
+	}
+	return "cursor"
+}
+
+// PageItems returns the items of a page from its decoded body and headers. A page whose
+// items expression selects something other than a list counts as one item.
+func (p *PaginationConfig) PageItems(body interface{}, headers map[string]string) ([]interface{}, error) {
+	source := p.Items
+	if source == "" {
+		source = "body"
+	}
+	value, err := evaluateResponse(source, body, headers)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	if items, ok := value.([]interface{}); ok {
+		return items, nil
+	}
+	return []interface{}{value}, nil
+}
+
+// PageCursor returns the cursor of the next page, or "" on the last page
+func (p *PaginationConfig) PageCursor(body interface{}, headers map[string]string) (string, error) {
+	value, err := evaluateResponse(p.NextCursor, body, headers)
+	if err != nil || value == nil {
+		return "", err
+	}
+	return formatValue(value)
+}
+
+// evaluateResponse evaluates an expression over a response, whose headers are matched
+// case-insensitively like request headers
+func evaluateResponse(source string, body interface{}, headers map[string]string) (interface{}, error) {
+	expr, err := compileExpression(source)
+	if err != nil {
+		return nil, err
+	}
+	lowered := make(map[string]string, len(headers))
+	for name, value := range headers {
+		lowered[strings.ToLower(name)] = value
+	}
+	value, _ := expr.Evaluate(RequestContext{Body: body, Headers: lowered})
+	return value, nil
+}
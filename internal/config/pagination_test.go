@@ -0,0 +1,56 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginationConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination PaginationConfig
+		message    string
+	}{
+		{"page", PaginationConfig{Style: "page", MaxPages: 5}, ""},
+		{"cursor", PaginationConfig{Style: "cursor", NextCursor: "headers['x-next-cursor']"}, ""},
+		{"link", PaginationConfig{Style: "link", Items: "body.items"}, ""},
+		{"unknown style", PaginationConfig{Style: "offset"}, `invalid style "offset"`},
+		{"cursor without next_cursor", PaginationConfig{Style: "cursor"}, "next_cursor is required"},
+		{"negative max_pages", PaginationConfig{Style: "page", MaxPages: -1}, "max_pages must not be negative"},
+		{"invalid items", PaginationConfig{Style: "page", Items: "body["}, "invalid valueFrom expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.pagination.Validate()
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestPaginationConfig_PageValues(t *testing.T) {
+	pagination := PaginationConfig{Style: "cursor", Items: "body.data", NextCursor: "headers['x-next-cursor']"}
+	body := map[string]interface{}{"data": []interface{}{"a", "b"}}
+
+	items, err := pagination.PageItems(body, map[string]string{"X-Next-Cursor": "c2"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, items)
+
+	cursor, err := pagination.PageCursor(body, map[string]string{"X-Next-Cursor": "c2"})
+	require.NoError(t, err)
+	assert.Equal(t, "c2", cursor, "response headers are matched case-insensitively")
+
+	cursor, err = pagination.PageCursor(body, nil)
+	require.NoError(t, err)
+	assert.Empty(t, cursor)
+
+	assert.Equal(t, 10, pagination.PageLimit())
+	assert.Equal(t, "cursor", pagination.CursorParamName())
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// profilesType is the type of Config.Profiles, whose entries are partial configurations
+var profilesType = reflect.TypeOf(map[string]map[string]interface{}{})
+
+// parseDocument decodes configuration content into generic maps and lists
+func parseDocument(content []byte, ext string) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	var err error
+
+	switch ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &doc)
+	case ".json":
+		err = json.Unmarshal(content, &doc)
+	case ".toml":
+		_, err = toml.Decode(string(content), &doc)
+	default:
+		return nil, fmt.Errorf("unsupported configuration file format: %s", ext)
+	}
+	return doc, err
+}
+
+// encodeDocument encodes a generic document back into the given configuration format
+func encodeDocument(doc map[string]interface{}, ext string) ([]byte, error) {
+	switch ext {
+	case ".yaml", ".yml":
+		return yaml.Marshal(doc)
+	case ".json":
+		return json.Marshal(doc)
+	case ".toml":
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported configuration file format: %s", ext)
+	}
+}
+
+// applyProfile overlays the named entry of the profiles section on the base settings and
+// returns the merged content in the original format
+func applyProfile(content []byte, ext, name string) ([]byte, error) {
+	doc, err := parseDocument(content, ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file: %w", err)
+	}
+
+	profiles, _ := doc["profiles"].(map[string]interface{})
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (available: %s)", ErrUnknownProfile, name, availableProfiles(profiles))
+	}
+	overlay, ok := profile.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("profile %s must be a mapping of configuration settings", name)
+	}
+	if _, nested := overlay["profiles"]; nested {
+		return nil, fmt.Errorf("profile %s must not define profiles", name)
+	}
+
+	return encodeDocument(mergeDocuments(doc, overlay), ext)
+}
+
+// availableProfiles lists the profile names defined in a document
+func availableProfiles(profiles map[string]interface{}) string {
+	if len(profiles) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// mergeDocuments returns base with overlay applied: mappings are merged key by key,
+// while lists and scalar values in overlay replace those in base
+func mergeDocuments(base, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		baseMap, baseIsMap := merged[key].(map[string]interface{})
+		overlayMap, overlayIsMap := value.(map[string]interface{})
+		if baseIsMap && overlayIsMap {
+			merged[key] = mergeDocuments(baseMap, overlayMap)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
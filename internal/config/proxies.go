@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ParseTrustedProxies parses trusted proxy entries, each an IP address or a CIDR range such
+// as "10.0.0.0/8", into network prefixes
+func ParseTrustedProxies(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR range", entry)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", entry)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// TrustedProxyPrefixes returns the trusted proxies as network prefixes, skipping invalid
+// entries, which Validate reports
+func (s *SecurityConfig) TrustedProxyPrefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range s.TrustedProxies {
+		if parsed, err := ParseTrustedProxies([]string{entry}); err == nil {
+			prefixes = append(prefixes, parsed...)
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedProxies(t *testing.T) {
+	prefixes, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "fd00::/8", "::ffff:192.0.2.2"})
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.0.2.1/32"),
+		netip.MustParsePrefix("fd00::/8"),
+		netip.MustParsePrefix("192.0.2.2/32"),
+	}, prefixes)
+
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.Security.TrustedProxies = []string{"10.0.0.0/8", "proxy.internal"}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid trusted_proxies")
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}, cfg.Security.TrustedProxyPrefixes())
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// QueryParamConfig represents a query parameter added to every upstream API request. It takes
+// the same value, values and valueFrom forms as a header.
+type QueryParamConfig struct {
+	Name      string   `yaml:"name" json:"name"`
+	Value     string   `yaml:"value,omitempty" json:"value,omitempty"`
+	Values    []string `yaml:"values,omitempty" json:"values,omitempty"`
+	ValueFrom string   `yaml:"valueFrom,omitempty" json:"valueFrom,omitempty"`
+	// When limits the requests the value is sent with
+	When *HeaderCondition `yaml:"when,omitempty" json:"when,omitempty"`
+}
+
+// Validate validates the QueryParamConfig
+func (q *QueryParamConfig) Validate() error {
+	return (*HeaderConfig)(q).validate("query parameter")
+}
+
+// QueryItem represents a query parameter item in the configuration
+type QueryItem struct {
+	Param QueryParamConfig `yaml:"param" json:"param"`
+}
+
+// QueryConfig represents a list of query parameter configurations
+type QueryConfig []QueryItem
+
+// Validate validates the QueryConfig
+func (q QueryConfig) Validate() error {
+	seen := make(map[string]bool)
+	for _, item := range q {
+		if err := item.Param.Validate(); err != nil {
+			return err
+		}
+		if seen[item.Param.Name] {
+			return fmt.Errorf("duplicate query parameter name: %s", item.Param.Name)
+		}
+		seen[item.Param.Name] = true
+	}
+	return nil
+}
+
+// EvaluateQuery evaluates configured query parameters against the request context.
+// Parameters without a value are omitted.
+func (e *RequestEvaluator) EvaluateQuery(query QueryConfig, requestContext RequestContext) (url.Values, error) {
+	result := url.Values{}
+	for _, item := range query {
+		values, err := (*HeaderConfig)(&item.Param).evaluate(requestContext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate query parameter %s: %w", item.Param.Name, err)
+		}
+		if len(values) > 0 {
+			result[item.Param.Name] = values
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestQueryConfig_UnmarshalYAML(t *testing.T) {
+	var api OpenAPIConfig
+	err := yaml.Unmarshal([]byte(`
+query:
+  - param:
+      name: org
+      valueFrom: "request.headers['x-org']"
+  - param:
+      name: format
+      value: json
+`), &api)
+	require.NoError(t, err)
+	assert.Equal(t, QueryConfig{
+		{Param: QueryParamConfig{Name: "org", ValueFrom: "request.headers['x-org']"}},
+		{Param: QueryParamConfig{Name: "format", Value: "json"}},
+	}, api.Query)
+}
+
+func TestQueryConfig_Validate(t *testing.T) {
+	param := func(name, value, valueFrom string) QueryItem {
+		return QueryItem{Param: QueryParamConfig{Name: name, Value: value, ValueFrom: valueFrom}}
+	}
+
+	tests := []struct {
+		name    string
+		query   QueryConfig
+		message string
+	}{
+		{"valid", QueryConfig{param("org", "", "request.headers['x-org']"), param("format", "json", "")}, ""},
+		{"missing name", QueryConfig{param("", "json", "")}, "query parameter name is required"},
+		{"missing value", QueryConfig{param("org", "", "")}, "query parameter must have either 'value', 'values' or 'valueFrom'"},
+		{"malformed valueFrom", QueryConfig{param("org", "", "request.headers['x-org")}, "query parameter org: invalid valueFrom expression"},
+		{"duplicate name", QueryConfig{param("org", "a", ""), param("org", "b", "")}, "duplicate query parameter name: org"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestRequestEvaluator_EvaluateQuery(t *testing.T) {
+	query := QueryConfig{
+		{Param: QueryParamConfig{Name: "org", ValueFrom: "request.headers['x-org']"}},
+		{Param: QueryParamConfig{Name: "tag", Values: []string{"a", "b"}}},
+		{Param: QueryParamConfig{Name: "region", Value: "{{ request.headers['x-region'] }}"}},
+	}
+	ctx := NewRequestContextFromMap(map[string]string{"X-Org": "acme"}, nil, nil, "POST", "/mcp")
+
+	result, err := NewRequestEvaluator().EvaluateQuery(query, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{"org": {"acme"}, "tag": {"a", "b"}}, result)
+}
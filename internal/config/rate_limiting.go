@@ -0,0 +1,40 @@
+package config
+
+import "fmt"
+
+// Rate limit stores of the limiter of tool calls per client
+const (
+	RateLimitStoreMemory = "memory"
+	RateLimitStoreRedis  = "redis"
+)
+
+// DefaultRateLimitKeyPrefix starts the Redis keys of rate limit counters when no prefix is configured
+const DefaultRateLimitKeyPrefix = "mcpify:ratelimit:"
+
+// RateLimitingConfig contains rate limiting configuration
+type RateLimitingConfig struct {
+	Enabled           bool `yaml:"enabled" json:"enabled"`
+	RequestsPerMinute int  `yaml:"requests_per_minute" json:"requests_per_minute"`
+	// Store is "memory" (default) to count the calls of each replica alone, or "redis" to
+	// enforce the limit across replicas, using the top-level redis server
+	Store     string `yaml:"store" json:"store"`
+	KeyPrefix string `yaml:"key_prefix" json:"key_prefix"` // Key prefix of the redis store, "mcpify:ratelimit:" by default
+}
+
+// KeyPrefixOrDefault returns the configured key prefix, or DefaultRateLimitKeyPrefix
+func (r *RateLimitingConfig) KeyPrefixOrDefault() string {
+	if r.KeyPrefix != "" {
+		return r.KeyPrefix
+	}
+	return DefaultRateLimitKeyPrefix
+}
+
+// Validate validates the RateLimitingConfig
+func (r *RateLimitingConfig) Validate() error {
+	switch r.Store {
+	case "", RateLimitStoreMemory, RateLimitStoreRedis:
+		return nil
+	default:
+		return fmt.Errorf("store must be %q or %q, got %q", RateLimitStoreMemory, RateLimitStoreRedis, r.Store)
+	}
+}
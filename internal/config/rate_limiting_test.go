@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitingConfig(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, DefaultRateLimitKeyPrefix, cfg.Security.RateLimiting.KeyPrefixOrDefault())
+
+	cfg.Security.RateLimiting.Store = "memcached"
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid rate_limiting")
+
+	cfg.Security.RateLimiting.Store = RateLimitStoreRedis
+	cfg.Security.RateLimiting.Enabled = true
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires redis.url")
+
+	// Redis is only needed while rate limiting is enabled
+	cfg.Security.RateLimiting.Enabled = false
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Security.RateLimiting.Enabled = true
+	cfg.Redis = RedisConfig{URL: "redis://cache:6379"}
+	assert.NoError(t, cfg.Validate())
+}
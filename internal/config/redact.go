@@ -0,0 +1,160 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Redacted is the placeholder that replaces secrets in a redacted configuration
+const Redacted = "[REDACTED]"
+
+// sensitiveNames are the parts of header, field and key names whose values are credentials,
+// compared in lowercase without separators, so "api_key" and "X-Api-Key" both match "apikey"
+var sensitiveNames = []string{"authorization", "password", "passwd", "secret", "token", "apikey", "cookie", "credential", "privatekey", "signature"}
+
+// IsSensitiveName reports whether a header, field or key name looks like it holds a credential
+func IsSensitiveName(name string) bool {
+	normalized := strings.NewReplacer("-", "", "_", "", ".", "", " ", "").Replace(strings.ToLower(name))
+	for _, sensitive := range sensitiveNames {
+		if strings.Contains(normalized, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redacted returns a copy of the configuration with inline credentials replaced by Redacted,
+// suitable for display. The static values of headers, query parameters and body fields are
+// redacted too, since API keys are commonly sent in them. Secret file paths and valueFrom
+// expressions are kept, and profiles are dropped since the selected one has already been
+// applied.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Server.Admin.Token = redactSecret(c.Server.Admin.Token)
+	redacted.Server.HTTP.Sessions.Secret = redactSecret(c.Server.HTTP.Sessions.Secret)
+	if parsed, err := url.Parse(c.Redis.URL); err == nil && parsed.User != nil {
+		if _, hasPassword := parsed.User.Password(); hasPassword {
+			redacted.Redis.URL = parsed.Redacted()
+		}
+	}
+	if c.Security.BasicAuth.Users != nil {
+		redacted.Security.BasicAuth.Users = make([]BasicAuthUser, len(c.Security.BasicAuth.Users))
+		for i, user := range c.Security.BasicAuth.Users {
+			user.Password = redactSecret(user.Password)
+			redacted.Security.BasicAuth.Users[i] = user
+		}
+	}
+	redacted.OpenAPI = c.OpenAPI.redacted()
+	redacted.APIs = make([]OpenAPIConfig, len(c.APIs))
+	for i := range c.APIs {
+		redacted.APIs[i] = c.APIs[i].redacted()
+	}
+	if c.Tools != nil {
+		redacted.Tools = make(map[string]ToolOverride, len(c.Tools))
+		for key, override := range c.Tools {
+			override.Headers = redactHeaders(override.Headers)
+			redacted.Tools[key] = override
+		}
+	}
+	redacted.Profiles = nil
+	redacted.Extensions = make([]ExtensionConfig, len(c.Extensions))
+	for i := range c.Extensions {
+		redacted.Extensions[i] = c.Extensions[i].redacted()
+	}
+	return &redacted
+}
+
+// redacted returns a copy of the extension configuration with string options redacted, since
+// plugins commonly take credentials as options
+func (e *ExtensionConfig) redacted() ExtensionConfig {
+	redacted := *e
+	if e.Options == nil {
+		return redacted
+	}
+	redacted.Options = make(map[string]interface{}, len(e.Options))
+	for name, value := range e.Options {
+		if s, ok := value.(string); ok {
+			value = redactSecret(s)
+		}
+		redacted.Options[name] = value
+	}
+	return redacted
+}
+
+// redacted returns a copy of the API configuration with its credentials redacted
+func (o *OpenAPIConfig) redacted() OpenAPIConfig {
+	redacted := *o
+	redacted.Auth = o.Auth.redacted()
+	redacted.Headers = redactHeaders(o.Headers)
+	if o.Query != nil {
+		redacted.Query = make(QueryConfig, len(o.Query))
+		for i, item := range o.Query {
+			item.Param = QueryParamConfig(redactStaticValues(HeaderConfig(item.Param)))
+			redacted.Query[i] = item
+		}
+	}
+	if o.Body != nil {
+		redacted.Body = make(BodyConfig, len(o.Body))
+		for i, item := range o.Body {
+			item.Field = BodyFieldConfig(redactStaticValues(HeaderConfig(item.Field)))
+			redacted.Body[i] = item
+		}
+	}
+	if o.Tenants.Instances != nil {
+		redacted.Tenants.Instances = make(map[string]TenantConfig, len(o.Tenants.Instances))
+		for name, instance := range o.Tenants.Instances {
+			if instance.Auth != nil {
+				auth := instance.Auth.redacted()
+				instance.Auth = &auth
+			}
+			redacted.Tenants.Instances[name] = instance
+		}
+	}
+	return redacted
+}
+
+// redacted returns a copy of the auth configuration with inline secrets and static auth
+// header values redacted
+func (a *AuthConfig) redacted() AuthConfig {
+	redacted := *a
+	redacted.Token = redactSecret(a.Token)
+	redacted.Password = redactSecret(a.Password)
+	redacted.APIKey = redactSecret(a.APIKey)
+	redacted.Headers = redactHeaders(a.Headers)
+	return redacted
+}
+
+// redactHeaders returns a copy of headers with their static values redacted
+func redactHeaders(headers HeadersConfig) HeadersConfig {
+	if headers == nil {
+		return nil
+	}
+	redacted := make(HeadersConfig, len(headers))
+	for i, item := range headers {
+		item.Header = redactStaticValues(item.Header)
+		redacted[i] = item
+	}
+	return redacted
+}
+
+// redactStaticValues returns a copy of a header, query parameter or body field with its
+// static values redacted; valueFrom expressions are kept
+func redactStaticValues(field HeaderConfig) HeaderConfig {
+	field.Value = redactSecret(field.Value)
+	if field.Values != nil {
+		values := make([]string, len(field.Values))
+		for i, value := range field.Values {
+			values[i] = redactSecret(value)
+		}
+		field.Values = values
+	}
+	return field
+}
+
+// redactSecret replaces a non-empty inline secret, keeping references to secret providers
+func redactSecret(value string) string {
+	if value == "" || isSecretReference(value) {
+		return value
+	}
+	return Redacted
+}
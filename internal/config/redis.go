@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultRedisPoolSize is the number of idle Redis connections kept when none is configured
+const DefaultRedisPoolSize = 10
+
+// RedisConfig is the Redis server shared by the session store and rate limiter of replicas
+type RedisConfig struct {
+	// URL is redis://[user:password@]host:port/db, or rediss:// for TLS
+	URL      string `yaml:"url" json:"url"`
+	PoolSize int    `yaml:"pool_size" json:"pool_size"` // Idle connections kept, 10 by default
+}
+
+// IsZero reports whether no Redis server is configured
+func (r *RedisConfig) IsZero() bool {
+	return r.URL == ""
+}
+
+// PoolSizeOrDefault returns the configured pool size, or DefaultRedisPoolSize
+func (r *RedisConfig) PoolSizeOrDefault() int {
+	if r.PoolSize > 0 {
+		return r.PoolSize
+	}
+	return DefaultRedisPoolSize
+}
+
+// Validate validates the RedisConfig
+func (r *RedisConfig) Validate() error {
+	if r.URL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(r.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if parsed.Scheme != "redis" && parsed.Scheme != "rediss" {
+		return fmt.Errorf("url must start with redis:// or rediss://")
+	}
+	if parsed.Hostname() == "" {
+		return fmt.Errorf("url must include a host")
+	}
+	if db := strings.Trim(parsed.Path, "/"); db != "" {
+		if n, err := strconv.Atoi(db); err != nil || n < 0 {
+			return fmt.Errorf("url path must be a database number, got %q", db)
+		}
+	}
+	if r.PoolSize < 0 {
+		return fmt.Errorf("pool_size cannot be negative")
+	}
+	return nil
+}
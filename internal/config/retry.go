@@ -0,0 +1,144 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Retry policy defaults
+const (
+	DefaultRetryInitialBackoff = time.Second
+	DefaultRetryMaxBackoff     = 30 * time.Second
+	DefaultRetryMultiplier     = 2.0
+)
+
+// DefaultRetryMethods are the methods whose responses are retried when methods is not set:
+// the safe methods, which cannot change anything upstream when sent twice
+var DefaultRetryMethods = []string{"GET", "HEAD", "OPTIONS"}
+
+// RetryConfig is the retry policy of upstream requests, shared by every API and tool. Requests
+// that get no response are always retried; responses are retried when their status is listed
+// and their request method is one of the retried methods.
+type RetryConfig struct {
+	MaxAttempts     int           `yaml:"max_attempts" json:"max_attempts"`           // Attempts per call including the first; 0 uses each API's max_retries + 1
+	InitialBackoff  time.Duration `yaml:"initial_backoff" json:"initial_backoff"`     // Wait before the first retry, 1s by default
+	MaxBackoff      time.Duration `yaml:"max_backoff" json:"max_backoff"`             // Longest wait between attempts, including Retry-After, 30s by default
+	Multiplier      float64       `yaml:"multiplier" json:"multiplier"`               // Growth of the wait after each retry, 2 by default; 1 waits the same each time
+	Jitter          bool          `yaml:"jitter" json:"jitter"`                       // Wait a random time between half and all of the backoff
+	Statuses        []string      `yaml:"statuses" json:"statuses"`                   // Response statuses retried, e.g. "429", "503" or "5xx"
+	Methods         []string      `yaml:"methods" json:"methods"`                     // Request methods whose responses are retried, DefaultRetryMethods by default
+	BudgetPerMinute int           `yaml:"budget_per_minute" json:"budget_per_minute"` // Retries allowed per minute across all tools, 0 for no limit
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for RetryConfig
+func (r *RetryConfig) UnmarshalJSON(data []byte) error {
+	type Alias RetryConfig
+	aux := &struct {
+		InitialBackoff string `json:"initial_backoff"`
+		MaxBackoff     string `json:"max_backoff"`
+		*Alias
+	}{
+		Alias: (*Alias)(r),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	for _, field := range []struct {
+		value  string
+		target *time.Duration
+	}{
+		{aux.InitialBackoff, &r.InitialBackoff},
+		{aux.MaxBackoff, &r.MaxBackoff},
+	} {
+		if field.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(field.value)
+		if err != nil {
+			return err
+		}
+		*field.target = duration
+	}
+
+	return nil
+}
+
+// InitialBackoffOrDefault returns the wait before the first retry
+func (r *RetryConfig) InitialBackoffOrDefault() time.Duration {
+	if r.InitialBackoff > 0 {
+		return r.InitialBackoff
+	}
+	return DefaultRetryInitialBackoff
+}
+
+// MaxBackoffOrDefault returns the longest wait between attempts
+func (r *RetryConfig) MaxBackoffOrDefault() time.Duration {
+	if r.MaxBackoff > 0 {
+		return r.MaxBackoff
+	}
+	return DefaultRetryMaxBackoff
+}
+
+// MultiplierOrDefault returns the growth of the wait after each retry
+func (r *RetryConfig) MultiplierOrDefault() float64 {
+	if r.Multiplier > 0 {
+		return r.Multiplier
+	}
+	return DefaultRetryMultiplier
+}
+
+// MethodsOrDefault returns the request methods whose responses are retried
+func (r *RetryConfig) MethodsOrDefault() []string {
+	if len(r.Methods) > 0 {
+		return r.Methods
+	}
+	return DefaultRetryMethods
+}
+
+// RetriesMethod reports whether responses to requests with the method are retried
+func (r *RetryConfig) RetriesMethod(method string) bool {
+	for _, retried := range r.MethodsOrDefault() {
+		if strings.EqualFold(retried, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetriesStatus reports whether responses with the status are retried
+func (r *RetryConfig) RetriesStatus(status int) bool {
+	for _, pattern := range r.Statuses {
+		if MatchStatus(pattern, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the RetryConfig
+func (r *RetryConfig) Validate() error {
+	if r.MaxAttempts < 0 || r.BudgetPerMinute < 0 {
+		return fmt.Errorf("max_attempts and budget_per_minute cannot be negative")
+	}
+	if r.InitialBackoff < 0 || r.MaxBackoff < 0 {
+		return fmt.Errorf("backoffs cannot be negative")
+	}
+	if r.Multiplier != 0 && r.Multiplier < 1 {
+		return fmt.Errorf("multiplier must be at least 1")
+	}
+	for _, pattern := range r.Statuses {
+		if err := validateStatusPattern(pattern); err != nil {
+			return err
+		}
+	}
+	for _, method := range r.Methods {
+		if method == "" || strings.ContainsAny(method, " \t") {
+			return fmt.Errorf("invalid method %q", method)
+		}
+	}
+	return nil
+}
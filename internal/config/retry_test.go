@@ -0,0 +1,45 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryConfig_UnmarshalJSON(t *testing.T) {
+	var retry RetryConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"max_attempts": 4, "initial_backoff": "250ms", "max_backoff": "10s", "statuses": ["429", "5xx"]}`), &retry))
+	assert.Equal(t, 4, retry.MaxAttempts)
+	assert.Equal(t, 250*time.Millisecond, retry.InitialBackoffOrDefault())
+	assert.Equal(t, 10*time.Second, retry.MaxBackoffOrDefault())
+	assert.Equal(t, DefaultRetryMultiplier, retry.MultiplierOrDefault())
+
+	assert.True(t, retry.RetriesStatus(429))
+	assert.True(t, retry.RetriesStatus(503))
+	assert.False(t, retry.RetriesStatus(404))
+	assert.False(t, retry.RetriesStatus(200))
+}
+
+func TestRetryConfig_Validate(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.Retry = RetryConfig{MaxAttempts: 3, Multiplier: 1.5, Statuses: []string{"503", "5xx"}, BudgetPerMinute: 60}
+	assert.NoError(t, cfg.Validate())
+
+	for _, retry := range []RetryConfig{
+		{MaxAttempts: -1},
+		{Multiplier: 0.5},
+		{InitialBackoff: -time.Second},
+		{Statuses: []string{"server errors"}},
+		{Statuses: []string{"700"}},
+		{Methods: []string{""}},
+	} {
+		cfg.Retry = retry
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid retry")
+	}
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// schemaEnums lists the accepted values of enumerated fields, keyed by "Type.Field"
+var schemaEnums = map[string][]string{
+	"ServerConfig.Transport": {"stdio", "http"},
+	"LoggingConfig.Level":    {"debug", "info", "warn", "error"},
+	"LoggingConfig.Format":   {"json", "text"},
+	"AuthConfig.Type":        {"none", "bearer", "basic", "api_key"},
+	"AuthConfig.APIKeyIn":    {"header", "query"},
+	"TLSConfig.MinVersion":   {"1.0", "1.1", "1.2", "1.3"},
+	"CompositeArgument.Type": {"string", "number", "integer", "boolean", "object", "array"},
+	"PaginationConfig.Style": {"page", "cursor", "link"},
+	"DocsConfig.UI":          {"swagger", "redoc"},
+}
+
+var (
+	durationType      = reflect.TypeOf(time.Duration(0))
+	headersConfigType = reflect.TypeOf(HeadersConfig{})
+)
+
+// Schema returns a JSON Schema describing the configuration file format
+func Schema() map[string]interface{} {
+	defs := make(map[string]interface{})
+	root := structSchema(reflect.TypeOf(Config{}), defs)
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = "https://github.com/akram/mcpify/config/config.schema.json"
+	root["title"] = "MCPify configuration"
+	// Profiles are partial configurations with the same keys as the root
+	root["properties"].(map[string]interface{})["profiles"] = map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"$ref": "#"},
+	}
+	root["$defs"] = defs
+	return root
+}
+
+// typeSchema returns the schema for a field type, registering struct types in defs
+func typeSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	switch {
+	case t == durationType:
+		return map[string]interface{}{
+			"type":        "string",
+			"pattern":     `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`,
+			"description": "Go duration, e.g. \"30s\" or \"5m\"",
+		}
+	case t == headersConfigType:
+		return headersSchema(defs)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem(), defs)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			// Register before recursing so recursive types terminate
+			defs[t.Name()] = nil
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema returns an object schema with a property per configuration key
+func structSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for key, field := range configFields(t) {
+		schema := typeSchema(field.Type, defs)
+		if enum, ok := schemaEnums[field.owner+"."+field.Name]; ok {
+			schema["enum"] = enum
+		}
+		properties[key] = schema
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if t == reflect.TypeOf(HeaderConfig{}) || t == reflect.TypeOf(QueryParamConfig{}) || t == reflect.TypeOf(BodyFieldConfig{}) {
+		schema["required"] = []string{"name"}
+	}
+	return schema
+}
+
+// headersSchema describes both accepted header formats: a list of header items or a name to value map
+func headersSchema(defs map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{
+				"type":  "array",
+				"items": typeSchema(reflect.TypeOf(HeaderItem{}), defs),
+			},
+			map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+			},
+		},
+	}
+}
+
+// configField is a struct field reachable from a configuration key
+type configField struct {
+	reflect.StructField
+	owner string // name of the struct declaring the field
+}
+
+// configFields maps configuration keys to struct fields, flattening inlined structs
+func configFields(t reflect.Type) map[string]configField {
+	fields := make(map[string]configField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, inline := yamlKey(field)
+		if key == "-" {
+			continue
+		}
+		if inline {
+			for k, f := range configFields(field.Type) {
+				fields[k] = f
+			}
+			continue
+		}
+		fields[key] = configField{StructField: field, owner: t.Name()}
+	}
+	return fields
+}
+
+// unknownKeys returns a description of each key in a decoded document that does not
+// correspond to a configuration field, with a suggestion when a known key is similar
+func unknownKeys(doc interface{}, t reflect.Type, path string) []string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// Durations and other scalars have no nested keys
+	if t == durationType {
+		return nil
+	}
+	// Profiles are checked as partial configurations
+	if t == profilesType {
+		t = reflect.TypeOf(map[string]Config{})
+	}
+
+	value := reflect.ValueOf(doc)
+	if !value.IsValid() {
+		return nil
+	}
+
+	var problems []string
+	switch t.Kind() {
+	case reflect.Struct:
+		if value.Kind() != reflect.Map {
+			return nil
+		}
+		fields := configFields(t)
+		for _, key := range sortedMapKeys(value) {
+			field, ok := fields[key]
+			if !ok {
+				problems = append(problems, unknownKeyMessage(joinKeyPath(path, key), key, fields))
+				continue
+			}
+			problems = append(problems, unknownKeys(value.MapIndex(reflect.ValueOf(key)).Interface(), field.Type, joinKeyPath(path, key))...)
+		}
+	case reflect.Map:
+		if value.Kind() != reflect.Map {
+			return nil
+		}
+		for _, key := range sortedMapKeys(value) {
+			problems = append(problems, unknownKeys(value.MapIndex(reflect.ValueOf(key)).Interface(), t.Elem(), joinKeyPath(path, key))...)
+		}
+	case reflect.Slice:
+		// Lists may also be written in another form (e.g. headers as a name to value map)
+		if value.Kind() != reflect.Slice {
+			return nil
+		}
+		for i := 0; i < value.Len(); i++ {
+			problems = append(problems, unknownKeys(value.Index(i).Interface(), t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return problems
+}
+
+// sortedMapKeys returns the string keys of a decoded map in sorted order
+func sortedMapKeys(value reflect.Value) []string {
+	keys := make([]string, 0, value.Len())
+	for _, key := range value.MapKeys() {
+		if key.Kind() == reflect.Interface {
+			key = key.Elem()
+		}
+		if key.Kind() == reflect.String {
+			keys = append(keys, key.String())
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// joinKeyPath appends a key to a dotted configuration path
+func joinKeyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// unknownKeyMessage describes an unknown key, suggesting the closest known key
+func unknownKeyMessage(path, key string, fields map[string]configField) string {
+	best, bestDistance := "", 3
+	for known := range fields {
+		if d := editDistance(key, known); d < bestDistance || (d == bestDistance && known < best) {
+			best, bestDistance = known, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("%s (did you mean %q?)", path, best)
+	}
+	return path
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var updateSchema = flag.Bool("update-schema", false, "regenerate config/config.schema.json")
+
+const schemaFile = "../../config/config.schema.json"
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	assert.Equal(t, false, schema["additionalProperties"])
+
+	properties := schema["properties"].(map[string]interface{})
+	for _, key := range []string{"server", "logging", "openapi", "apis", "security", "tools"} {
+		assert.Contains(t, properties, key)
+	}
+
+	defs := schema["$defs"].(map[string]interface{})
+	openapi := defs["OpenAPIConfig"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Contains(t, openapi, "include_paths")
+	assert.Equal(t, "string", openapi["timeout"].(map[string]interface{})["type"])
+
+	server := defs["ServerConfig"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, []string{"stdio", "http"}, server["transport"].(map[string]interface{})["enum"])
+
+	// Inlined TLS settings appear directly on the server TLS block
+	serverTLS := defs["ServerTLSConfig"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Contains(t, serverTLS, "min_version")
+	assert.Contains(t, serverTLS, "cert_file")
+}
+
+func TestSchemaFile(t *testing.T) {
+	generated, err := json.MarshalIndent(Schema(), "", "  ")
+	require.NoError(t, err)
+	generated = append(generated, '\n')
+
+	if *updateSchema {
+		require.NoError(t, os.WriteFile(schemaFile, generated, 0o644))
+	}
+
+	published, err := os.ReadFile(schemaFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(published),
+		"config/config.schema.json is out of date, run: go test ./internal/config -run TestSchemaFile -update-schema")
+}
+
+func TestUnknownKeys(t *testing.T) {
+	var doc interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+server:
+  transprt: http
+  http:
+    port: 9090
+    tls:
+      min_version: "1.3"
+      cert_fil: /tls.crt
+openapi:
+  spec_path: spec.json
+  includ_paths: ["/api/*"]
+  auth:
+    type: bearer
+    token: abc
+  headers:
+    - header:
+        name: X-One
+        valu: one
+apis:
+  - spec_path: other.json
+    tool_prefx: other
+  - spec_path: third.json
+    headers:
+      Accept: application/json
+tools:
+  get_users:
+    timout: 5s
+    rate_limit: 10
+profiles:
+  prod:
+    openapi:
+      bse_url: https://api.example.com
+unrelated: true
+`), &doc))
+
+	problems := unknownKeys(doc, reflect.TypeOf(Config{}), "")
+	assert.Equal(t, []string{
+		`apis[0].tool_prefx (did you mean "tool_prefix"?)`,
+		`openapi.headers[0].header.valu (did you mean "value"?)`,
+		`openapi.includ_paths (did you mean "include_paths"?)`,
+		`profiles.prod.openapi.bse_url (did you mean "base_url"?)`,
+		`server.http.tls.cert_fil (did you mean "cert_file"?)`,
+		`server.transprt (did you mean "transport"?)`,
+		`tools.get_users.timout (did you mean "timeout"?)`,
+		`unrelated`,
+	}, problems)
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretCacheTTL is how long a value fetched from a secret provider is reused
+const secretCacheTTL = 5 * time.Minute
+
+// SecretProvider fetches secrets from an external store such as Vault or a cloud secret manager
+type SecretProvider interface {
+	// Resolve returns the secret for a reference, without the "scheme:" prefix
+	Resolve(ref string) (string, error)
+}
+
+// cachedSecret is a resolved secret reference and when it was fetched
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// secretRegistry maps reference schemes to providers and caches resolved values
+type secretRegistry struct {
+	mu        sync.RWMutex
+	providers map[string]SecretProvider
+	cache     map[string]cachedSecret
+	now       func() time.Time
+}
+
+// secretProviders is the process-wide registry used to resolve auth secrets
+var secretProviders = &secretRegistry{
+	providers: make(map[string]SecretProvider),
+	cache:     make(map[string]cachedSecret),
+	now:       time.Now,
+}
+
+// RegisterSecretProvider registers a provider for references of the form "<scheme>:<ref>",
+// e.g. "vault:secret/data/api#token"
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretProviders.mu.Lock()
+	defer secretProviders.mu.Unlock()
+	secretProviders.providers[scheme] = provider
+}
+
+// lookup returns the provider and reference for a value using a registered scheme
+func (r *secretRegistry) lookup(value string) (SecretProvider, string, bool) {
+	scheme, ref, found := strings.Cut(value, ":")
+	if !found || ref == "" {
+		return nil, "", false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	provider, ok := r.providers[scheme]
+	return provider, ref, ok
+}
+
+// resolve returns the secret for a reference, fetching it again once the cached value expires
+func (r *secretRegistry) resolve(value string, provider SecretProvider, ref string) (string, error) {
+	r.mu.RLock()
+	cached, ok := r.cache[value]
+	r.mu.RUnlock()
+	if ok && r.now().Sub(cached.fetchedAt) < secretCacheTTL {
+		return cached.value, nil
+	}
+
+	secret, err := provider.Resolve(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %q: %w", value, err)
+	}
+
+	r.mu.Lock()
+	r.cache[value] = cachedSecret{value: secret, fetchedAt: r.now()}
+	r.mu.Unlock()
+	return secret, nil
+}
+
+// isSecretReference reports whether a value refers to a registered secret provider
+func isSecretReference(value string) bool {
+	_, _, ok := secretProviders.lookup(value)
+	return ok
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretFile is a cached secret file value with the file metadata used to detect rotation
+type secretFile struct {
+	modTime time.Time
+	size    int64
+	value   string
+}
+
+// secretFileCache caches secret file contents, re-reading a file when it changes on disk
+type secretFileCache struct {
+	mu    sync.Mutex
+	files map[string]secretFile
+}
+
+// secretFiles is the process-wide cache used by AuthConfig
+var secretFiles = &secretFileCache{files: make(map[string]secretFile)}
+
+// read returns the contents of a secret file, reloading it if its modification time or size changed.
+// Trailing newlines are trimmed, since mounted secrets are often written with one.
+func (c *secretFileCache) read(path string) (string, error) {
+	// Stat follows symlinks, so Kubernetes secret mounts (which swap a ..data symlink) are detected
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.files[path]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.value, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	value := strings.TrimRight(string(content), "\r\n")
+	c.files[path] = secretFile{modTime: info.ModTime(), size: info.Size(), value: value}
+	return value, nil
+}
+
+// resolveSecret returns the inline value, the contents of file when one is configured,
+// or the secret from an external provider when value is a reference such as "vault:path#key"
+func resolveSecret(value, file string) (string, error) {
+	if file != "" {
+		return secretFiles.read(file)
+	}
+	if provider, ref, ok := secretProviders.lookup(value); ok {
+		return secretProviders.resolve(value, provider, ref)
+	}
+	return value, nil
+}
+
+// TokenValue returns the bearer token, reading token_file when configured
+func (a *AuthConfig) TokenValue() (string, error) {
+	return resolveSecret(a.Token, a.TokenFile)
+}
+
+// PasswordValue returns the basic auth password, reading password_file when configured
+func (a *AuthConfig) PasswordValue() (string, error) {
+	return resolveSecret(a.Password, a.PasswordFile)
+}
+
+// APIKeyValue returns the API key, reading api_key_file when configured
+func (a *AuthConfig) APIKeyValue() (string, error) {
+	return resolveSecret(a.APIKey, a.APIKeyFile)
+}
+
+// Validate checks that inline secrets and secret files are not both set, and that
+// configured secret files and provider references can be read
+func (a *AuthConfig) Validate() error {
+	secrets := []struct {
+		name  string
+		value string
+		file  string
+	}{
+		{"token", a.Token, a.TokenFile},
+		{"password", a.Password, a.PasswordFile},
+		{"api_key", a.APIKey, a.APIKeyFile},
+	}
+
+	for _, secret := range secrets {
+		if secret.file == "" {
+			if isSecretReference(secret.value) {
+				if _, err := resolveSecret(secret.value, ""); err != nil {
+					return fmt.Errorf("invalid %s: %w", secret.name, err)
+				}
+			}
+			continue
+		}
+		if secret.value != "" {
+			return fmt.Errorf("%s and %s_file are mutually exclusive", secret.name, secret.name)
+		}
+		if _, err := secretFiles.read(secret.file); err != nil {
+			return fmt.Errorf("invalid %s_file: %w", secret.name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthConfig_SecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0o600))
+
+	auth := AuthConfig{Type: "bearer", TokenFile: tokenFile}
+	token, err := auth.TokenValue()
+	require.NoError(t, err)
+	assert.Equal(t, "file-token", token)
+
+	// Rotated secrets are picked up on the next read
+	require.NoError(t, os.WriteFile(tokenFile, []byte("rotated-token-value\n"), 0o600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(tokenFile, future, future))
+
+	token, err = auth.TokenValue()
+	require.NoError(t, err)
+	assert.Equal(t, "rotated-token-value", token)
+
+	// Inline values are used when no file is configured
+	inline := AuthConfig{Type: "basic", Username: "user", Password: "inline"}
+	password, err := inline.PasswordValue()
+	require.NoError(t, err)
+	assert.Equal(t, "inline", password)
+
+	missing := AuthConfig{Type: "api_key", APIKeyFile: filepath.Join(dir, "missing")}
+	_, err = missing.APIKeyValue()
+	assert.Error(t, err)
+}
+
+func TestAuthConfig_Validate(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("secret"), 0o600))
+
+	tests := []struct {
+		name    string
+		auth    AuthConfig
+		wantErr string
+	}{
+		{name: "inline values", auth: AuthConfig{Token: "t", Password: "p", APIKey: "k"}},
+		{name: "readable files", auth: AuthConfig{TokenFile: secretFile, PasswordFile: secretFile, APIKeyFile: secretFile}},
+		{name: "token and token_file", auth: AuthConfig{Token: "t", TokenFile: secretFile}, wantErr: "token and token_file are mutually exclusive"},
+		{name: "password and password_file", auth: AuthConfig{Password: "p", PasswordFile: secretFile}, wantErr: "password and password_file are mutually exclusive"},
+		{name: "missing api_key_file", auth: AuthConfig{APIKeyFile: filepath.Join(dir, "missing")}, wantErr: "invalid api_key_file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.auth.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+type fakeSecretProvider struct {
+	secrets map[string]string
+	calls   int
+}
+
+func (f *fakeSecretProvider) Resolve(ref string) (string, error) {
+	f.calls++
+	value, ok := f.secrets[ref]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", ref)
+	}
+	return value, nil
+}
+
+func TestAuthConfig_SecretProviderReferences(t *testing.T) {
+	provider := &fakeSecretProvider{secrets: map[string]string{"api#token": "provider-token"}}
+	RegisterSecretProvider("fake", provider)
+
+	now := time.Now()
+	secretProviders.now = func() time.Time { return now }
+	defer func() { secretProviders.now = time.Now }()
+
+	auth := AuthConfig{Type: "bearer", Token: "fake:api#token"}
+	require.NoError(t, auth.Validate())
+
+	token, err := auth.TokenValue()
+	require.NoError(t, err)
+	assert.Equal(t, "provider-token", token)
+
+	// Values are cached until the TTL expires
+	_, _ = auth.TokenValue()
+	assert.Equal(t, 1, provider.calls)
+	now = now.Add(secretCacheTTL)
+	_, _ = auth.TokenValue()
+	assert.Equal(t, 2, provider.calls)
+
+	// Unknown schemes are plain values
+	plain := AuthConfig{Type: "bearer", Token: "unknown:value"}
+	token, err = plain.TokenValue()
+	require.NoError(t, err)
+	assert.Equal(t, "unknown:value", token)
+
+	missing := AuthConfig{Type: "bearer", Token: "fake:missing"}
+	err = missing.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid token")
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// ServerRoute sends the operations of some paths or tags to their own base URL, for APIs split
+// across hosts such as upload.api.example.com and api.example.com
+type ServerRoute struct {
+	BaseURL string   `yaml:"base_url" json:"base_url"` // Base URL of the matching operations
+	Paths   []string `yaml:"paths" json:"paths"`       // Path patterns, where "*" matches anything, e.g. "/uploads/*"
+	Tags    []string `yaml:"tags" json:"tags"`         // Operation tags
+}
+
+// Matches reports whether an operation with the path and tags is routed to the server
+func (s *ServerRoute) Matches(path string, tags []string) bool {
+	for _, pattern := range s.Paths {
+		if MatchPath(pattern, path) {
+			return true
+		}
+	}
+	for _, tag := range tags {
+		if slices.Contains(s.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the ServerRoute
+func (s *ServerRoute) Validate() error {
+	parsed, err := url.Parse(s.BaseURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("base_url must be an absolute URL, got %q", s.BaseURL)
+	}
+	if len(s.Paths) == 0 && len(s.Tags) == 0 {
+		return fmt.Errorf("%s: paths or tags are required", s.BaseURL)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerRoute_Matches(t *testing.T) {
+	route := ServerRoute{BaseURL: "https://upload.example.com", Paths: []string{"/uploads/*"}, Tags: []string{"files"}}
+	assert.True(t, route.Matches("/uploads/images", nil))
+	assert.True(t, route.Matches("/documents", []string{"admin", "files"}))
+	assert.False(t, route.Matches("/documents", []string{"admin"}))
+}
+
+func TestServerRoute_Validate(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.OpenAPI.Servers = []ServerRoute{{BaseURL: "https://upload.example.com", Tags: []string{"files"}}}
+	assert.NoError(t, cfg.Validate())
+
+	for _, route := range []ServerRoute{
+		{BaseURL: "/uploads", Tags: []string{"files"}},
+		{BaseURL: "https://upload.example.com"},
+	} {
+		cfg.OpenAPI.Servers = []ServerRoute{route}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid servers")
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Session stores of the HTTP transport
+const (
+	SessionStoreMemory = "memory"
+	SessionStoreFile   = "file"
+	SessionStoreRedis  = "redis"
+	SessionStoreSigned = "signed"
+)
+
+// DefaultSessionKeyPrefix starts the Redis keys of sessions when no prefix is configured
+const DefaultSessionKeyPrefix = "mcpify:session:"
+
+// DefaultSessionMaxAge is how long signed sessions last when no maximum age is configured
+const DefaultSessionMaxAge = 24 * time.Hour
+
+// minSessionSecretLength is the shortest secret accepted for signing sessions, in bytes
+const minSessionSecretLength = 32
+
+// SessionsConfig selects where the HTTP transport keeps sessions
+type SessionsConfig struct {
+	// Store is "memory" (default), "file" to survive restarts, "redis" to share sessions
+	// between replicas, using the top-level redis server, or "signed" to keep no sessions at
+	// all, handing out signed session IDs any replica with the secret accepts
+	Store     string        `yaml:"store" json:"store"`
+	Directory string        `yaml:"directory" json:"directory"`   // Directory of the file store
+	KeyPrefix string        `yaml:"key_prefix" json:"key_prefix"` // Key prefix of the redis store, "mcpify:session:" by default
+	Secret    string        `yaml:"secret" json:"secret"`         // Signing secret of the signed store, at least 32 bytes
+	MaxAge    time.Duration `yaml:"max_age" json:"max_age"`       // Lifetime of signed sessions, 24h by default
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for SessionsConfig
+func (s *SessionsConfig) UnmarshalJSON(data []byte) error {
+	type Alias SessionsConfig
+	aux := &struct {
+		MaxAge string `json:"max_age"`
+		*Alias
+	}{
+		Alias: (*Alias)(s),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.MaxAge != "" {
+		duration, err := time.ParseDuration(aux.MaxAge)
+		if err != nil {
+			return err
+		}
+		s.MaxAge = duration
+	}
+
+	return nil
+}
+
+// KeyPrefixOrDefault returns the configured key prefix, or DefaultSessionKeyPrefix
+func (s *SessionsConfig) KeyPrefixOrDefault() string {
+	if s.KeyPrefix != "" {
+		return s.KeyPrefix
+	}
+	return DefaultSessionKeyPrefix
+}
+
+// MaxAgeOrDefault returns the configured lifetime of signed sessions, or DefaultSessionMaxAge
+func (s *SessionsConfig) MaxAgeOrDefault() time.Duration {
+	if s.MaxAge > 0 {
+		return s.MaxAge
+	}
+	return DefaultSessionMaxAge
+}
+
+// SecretValue returns the signing secret, resolving secret provider references such as
+// "vault:path#key"
+func (s *SessionsConfig) SecretValue() (string, error) {
+	return resolveSecret(s.Secret, "")
+}
+
+// Validate validates the SessionsConfig
+func (s *SessionsConfig) Validate() error {
+	if s.MaxAge < 0 {
+		return fmt.Errorf("max_age must not be negative")
+	}
+	switch s.Store {
+	case "", SessionStoreMemory, SessionStoreRedis:
+	case SessionStoreFile:
+		if s.Directory == "" {
+			return fmt.Errorf("the file store requires a directory")
+		}
+	case SessionStoreSigned:
+		secret, err := s.SecretValue()
+		if err != nil {
+			return fmt.Errorf("invalid secret: %w", err)
+		}
+		if len(secret) < minSessionSecretLength {
+			return fmt.Errorf("the signed store requires a secret of at least %d bytes", minSessionSecretLength)
+		}
+	default:
+		return fmt.Errorf("store must be %q, %q, %q or %q, got %q",
+			SessionStoreMemory, SessionStoreFile, SessionStoreRedis, SessionStoreSigned, s.Store)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultShutdownTimeout bounds the wait for in-flight work when no timeout is configured
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ShutdownConfig controls how the server stops on SIGTERM or SIGINT
+type ShutdownConfig struct {
+	// Delay keeps serving with /readyz failing for this long before draining, so load
+	// balancers stop routing new clients first
+	Delay time.Duration `yaml:"delay" json:"delay"`
+	// Timeout bounds the wait for in-flight tool calls and connections, 30s by default
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for ShutdownConfig
+func (s *ShutdownConfig) UnmarshalJSON(data []byte) error {
+	type Alias ShutdownConfig
+	aux := &struct {
+		Delay   string `json:"delay"`
+		Timeout string `json:"timeout"`
+		*Alias
+	}{
+		Alias: (*Alias)(s),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	for _, field := range []struct {
+		value  string
+		target *time.Duration
+	}{
+		{aux.Delay, &s.Delay},
+		{aux.Timeout, &s.Timeout},
+	} {
+		if field.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(field.value)
+		if err != nil {
+			return err
+		}
+		*field.target = duration
+	}
+
+	return nil
+}
+
+// TimeoutOrDefault returns the configured shutdown timeout, or DefaultShutdownTimeout
+func (s *ShutdownConfig) TimeoutOrDefault() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return DefaultShutdownTimeout
+}
+
+// Validate validates the ShutdownConfig
+func (s *ShutdownConfig) Validate() error {
+	if s.Delay < 0 || s.Timeout < 0 {
+		return fmt.Errorf("delay and timeout cannot be negative")
+	}
+	return nil
+}
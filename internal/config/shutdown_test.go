@@ -0,0 +1,31 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownConfig_Validate(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.Server.Shutdown = ShutdownConfig{Delay: 5 * time.Second, Timeout: time.Minute}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Server.Shutdown = ShutdownConfig{Delay: -time.Second}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid shutdown: delay and timeout cannot be negative")
+}
+
+func TestShutdownConfig_Durations(t *testing.T) {
+	var shutdown ShutdownConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"delay": "5s", "timeout": "1m"}`), &shutdown))
+	assert.Equal(t, 5*time.Second, shutdown.Delay)
+	assert.Equal(t, time.Minute, shutdown.TimeoutOrDefault())
+
+	assert.Equal(t, DefaultShutdownTimeout, (&ShutdownConfig{}).TimeoutOrDefault())
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits maps human-readable size suffixes to byte multipliers (binary units)
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"KIB", 1 << 10},
+	{"MIB", 1 << 20},
+	{"GIB", 1 << 30},
+	{"KB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"K", 1 << 10},
+	{"M", 1 << 20},
+	{"G", 1 << 30},
+	{"B", 1},
+}
+
+// ParseSize parses a human-readable size such as "512", "64KB", or "10MB" into bytes
+func ParseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	if s == "" {
+		return 0, fmt.Errorf("empty size value")
+	}
+
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			multiplier = unit.multiplier
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size value: %q", size)
+	}
+
+	return int64(value * float64(multiplier)), nil
+}
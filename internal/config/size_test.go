@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{"1024", 1024, false},
+		{"512B", 512, false},
+		{"1KB", 1024, false},
+		{"64kb", 64 * 1024, false},
+		{"1MB", 1 << 20, false},
+		{"10 MB", 10 << 20, false},
+		{"1.5MB", 3 << 19, false},
+		{"2GB", 2 << 30, false},
+		{"4MiB", 4 << 20, false},
+		{"8K", 8 << 10, false},
+		{"", 0, true},
+		{"MB", 0, true},
+		{"ten megabytes", 0, true},
+		{"-1MB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSize(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %d", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for %q: %v", tt.input, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Default backoff between attempts to fetch a remote specification
+const (
+	DefaultSpecFetchBackoff    = time.Second
+	DefaultSpecFetchMaxBackoff = 30 * time.Second
+)
+
+// SpecFetchConfig controls how a specification served over HTTP is fetched at startup and on
+// reload
+type SpecFetchConfig struct {
+	// Retries is the number of attempts after a failed fetch; network errors, 429 and 5xx
+	// responses are retried, 0 fails at once
+	Retries int `yaml:"retries" json:"retries"`
+	// Backoff is the wait before the first retry, doubled for each one up to MaxBackoff
+	Backoff    time.Duration `yaml:"backoff" json:"backoff"`
+	MaxBackoff time.Duration `yaml:"max_backoff" json:"max_backoff"`
+	// CacheFile keeps the last specification fetched, used when every attempt fails
+	CacheFile string `yaml:"cache_file" json:"cache_file"`
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for SpecFetchConfig
+func (s *SpecFetchConfig) UnmarshalJSON(data []byte) error {
+	type Alias SpecFetchConfig
+	aux := &struct {
+		Backoff    string `json:"backoff"`
+		MaxBackoff string `json:"max_backoff"`
+		*Alias
+	}{
+		Alias: (*Alias)(s),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	for _, field := range []struct {
+		value  string
+		target *time.Duration
+	}{
+		{aux.Backoff, &s.Backoff},
+		{aux.MaxBackoff, &s.MaxBackoff},
+	} {
+		if field.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(field.value)
+		if err != nil {
+			return err
+		}
+		*field.target = duration
+	}
+
+	return nil
+}
+
+// BackoffOrDefault returns the wait before the first retry, or DefaultSpecFetchBackoff
+func (s *SpecFetchConfig) BackoffOrDefault() time.Duration {
+	if s.Backoff > 0 {
+		return s.Backoff
+	}
+	return DefaultSpecFetchBackoff
+}
+
+// MaxBackoffOrDefault returns the longest wait between retries, or DefaultSpecFetchMaxBackoff
+func (s *SpecFetchConfig) MaxBackoffOrDefault() time.Duration {
+	if s.MaxBackoff > 0 {
+		return s.MaxBackoff
+	}
+	return DefaultSpecFetchMaxBackoff
+}
+
+// Validate validates the SpecFetchConfig
+func (s *SpecFetchConfig) Validate() error {
+	if s.Retries < 0 {
+		return fmt.Errorf("retries cannot be negative")
+	}
+	if s.Backoff < 0 || s.MaxBackoff < 0 {
+		return fmt.Errorf("backoff and max_backoff cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecFetchConfig(t *testing.T) {
+	fetch := SpecFetchConfig{}
+	assert.Equal(t, DefaultSpecFetchBackoff, fetch.BackoffOrDefault())
+	assert.Equal(t, DefaultSpecFetchMaxBackoff, fetch.MaxBackoffOrDefault())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"retries": 5, "backoff": "500ms", "max_backoff": "10s", "cache_file": "spec.cache"}`), &fetch))
+	assert.Equal(t, SpecFetchConfig{Retries: 5, Backoff: 500 * time.Millisecond, MaxBackoff: 10 * time.Second, CacheFile: "spec.cache"}, fetch)
+
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "https://api.example.com/openapi.json"
+	cfg.OpenAPI.SpecFetch = fetch
+	require.NoError(t, cfg.Validate())
+
+	cfg.OpenAPI.SpecFetch = SpecFetchConfig{Retries: -1}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid spec_fetch")
+}
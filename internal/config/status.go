@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// MatchStatus reports whether an HTTP status matches a pattern: a code such as "503", or a
+// class such as "5xx"
+func MatchStatus(pattern string, status int) bool {
+	code := strconv.Itoa(status)
+	return pattern == code || (len(pattern) == 3 && pattern[1:] == "xx" && pattern[0] == code[0])
+}
+
+// validateStatusPattern checks that a pattern is a status code or class MatchStatus understands
+func validateStatusPattern(pattern string) error {
+	if len(pattern) == 3 && pattern[1:] == "xx" && pattern[0] >= '1' && pattern[0] <= '5' {
+		return nil
+	}
+	if status, err := strconv.Atoi(pattern); err != nil || status < 100 || status > 599 {
+		return fmt.Errorf("invalid status %q, expected a code such as 503 or a class such as 5xx", pattern)
+	}
+	return nil
+}
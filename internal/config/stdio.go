@@ -0,0 +1,37 @@
+package config
+
+import "fmt"
+
+// Ways messages are delimited on the stdio transport
+const (
+	// StdioFramingAuto detects the framing from the first message and answers in kind
+	StdioFramingAuto = "auto"
+	// StdioFramingNewline sends each message as one line of JSON, as the MCP specification does
+	StdioFramingNewline = "newline"
+	// StdioFramingContentLength precedes each message with a Content-Length header, as the
+	// Language Server Protocol does
+	StdioFramingContentLength = "content-length"
+)
+
+// StdioConfig configures the stdio transport
+type StdioConfig struct {
+	// Framing is "auto" (default), "newline" or "content-length"
+	Framing string `yaml:"framing" json:"framing"`
+}
+
+// FramingOrDefault returns the configured framing, or StdioFramingAuto
+func (s *StdioConfig) FramingOrDefault() string {
+	if s.Framing == "" {
+		return StdioFramingAuto
+	}
+	return s.Framing
+}
+
+// Validate validates the StdioConfig
+func (s *StdioConfig) Validate() error {
+	switch s.FramingOrDefault() {
+	case StdioFramingAuto, StdioFramingNewline, StdioFramingContentLength:
+		return nil
+	}
+	return fmt.Errorf("framing must be %q, %q or %q, got %q", StdioFramingAuto, StdioFramingNewline, StdioFramingContentLength, s.Framing)
+}
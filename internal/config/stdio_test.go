@@ -0,0 +1,22 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdioConfig(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	assert.Equal(t, StdioFramingAuto, cfg.Server.Stdio.FramingOrDefault())
+
+	cfg.Server.Stdio.Framing = StdioFramingContentLength
+	require.NoError(t, cfg.Validate())
+
+	cfg.Server.Stdio.Framing = "lsp"
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid stdio")
+}
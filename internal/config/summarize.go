@@ -0,0 +1,57 @@
+package config
+
+import "fmt"
+
+// Summary defaults used for settings left unset
+const (
+	DefaultSummaryMaxItems        = 10
+	DefaultSummaryMaxStringLength = 500
+)
+
+// SummarizeConfig shrinks large JSON responses before they reach the model: arrays keep their
+// first items and long strings are cut, with a note of what was left out
+type SummarizeConfig struct {
+	Threshold       string `yaml:"threshold" json:"threshold"`                 // Size of the JSON body above which it is summarized, e.g. "256KB"; empty disables summaries
+	MaxItems        int    `yaml:"max_items" json:"max_items"`                 // Items kept per array, 10 by default
+	MaxStringLength int    `yaml:"max_string_length" json:"max_string_length"` // Characters kept per string, 500 by default
+}
+
+// Enabled reports whether large responses are summarized
+func (s *SummarizeConfig) Enabled() bool {
+	return s.Threshold != ""
+}
+
+// ThresholdBytes returns the size above which responses are summarized
+func (s *SummarizeConfig) ThresholdBytes() int64 {
+	size, _ := ParseSize(s.Threshold)
+	return size
+}
+
+// MaxItemsOrDefault returns the items kept per array
+func (s *SummarizeConfig) MaxItemsOrDefault() int {
+	if s.MaxItems > 0 {
+		return s.MaxItems
+	}
+	return DefaultSummaryMaxItems
+}
+
+// MaxStringLengthOrDefault returns the characters kept per string
+func (s *SummarizeConfig) MaxStringLengthOrDefault() int {
+	if s.MaxStringLength > 0 {
+		return s.MaxStringLength
+	}
+	return DefaultSummaryMaxStringLength
+}
+
+// Validate validates the SummarizeConfig
+func (s *SummarizeConfig) Validate() error {
+	if s.Threshold != "" {
+		if size, err := ParseSize(s.Threshold); err != nil || size <= 0 {
+			return fmt.Errorf("invalid threshold: %q", s.Threshold)
+		}
+	}
+	if s.MaxItems < 0 || s.MaxStringLength < 0 {
+		return fmt.Errorf("max_items and max_string_length cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeConfig(t *testing.T) {
+	summarize := SummarizeConfig{}
+	assert.False(t, summarize.Enabled())
+	assert.Equal(t, DefaultSummaryMaxItems, summarize.MaxItemsOrDefault())
+	assert.Equal(t, DefaultSummaryMaxStringLength, summarize.MaxStringLengthOrDefault())
+
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.OpenAPI.Summarize = SummarizeConfig{Threshold: "256KB", MaxItems: 5}
+	require.NoError(t, cfg.Validate())
+	assert.True(t, cfg.OpenAPI.Summarize.Enabled())
+	assert.Equal(t, int64(256*1024), cfg.OpenAPI.Summarize.ThresholdBytes())
+
+	cfg.OpenAPI.Summarize = SummarizeConfig{Threshold: "lots"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid summarize")
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Template is a header value that interpolates valueFrom expressions, such as
+// "Bearer {{ request.headers['x-provider-data'].apikey }}"
+type Template struct {
+	source string
+	parts  []templatePart
+}
+
+// templatePart is either literal text or an interpolated expression
+type templatePart struct {
+	text string
+	expr *Expression
+}
+
+// IsTemplate reports whether a header value interpolates expressions
+func IsTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// ParseTemplate parses a header value with {{ expression }} placeholders, returning an
+// error wrapping ErrInvalidExpression for unterminated placeholders and invalid expressions
+func ParseTemplate(source string) (*Template, error) {
+	t := &Template{source: source}
+	rest := source
+	for {
+		start := strings.Index(rest, "{{")
+		if start == -1 {
+			if rest != "" {
+				t.parts = append(t.parts, templatePart{text: rest})
+			}
+			return t, nil
+		}
+		if start > 0 {
+			t.parts = append(t.parts, templatePart{text: rest[:start]})
+		}
+
+		end := strings.Index(rest[start+2:], "}}")
+		if end == -1 {
+			position := len(source) - len(rest) + start + 1
+			return nil, fmt.Errorf("%w %q: unterminated {{ at position %d", ErrInvalidExpression, source, position)
+		}
+		expr, err := ParseExpression(strings.TrimSpace(rest[start+2 : start+2+end]))
+		if err != nil {
+			return nil, err
+		}
+		t.parts = append(t.parts, templatePart{expr: expr})
+		rest = rest[start+2+end+2:]
+	}
+}
+
+// compiledTemplates caches parsed templates by source, like compiledExpressions
+var compiledTemplates sync.Map // map[string]*Template
+
+// compileTemplate returns the parsed template for source, parsing it on first use
+func compileTemplate(source string) (*Template, error) {
+	if tmpl, ok := compiledTemplates.Load(source); ok {
+		return tmpl.(*Template), nil
+	}
+	tmpl, err := ParseTemplate(source)
+	if err != nil {
+		return nil, err
+	}
+	compiledTemplates.Store(source, tmpl)
+	return tmpl, nil
+}
+
+// String returns the template as written
+func (t *Template) String() string {
+	return t.source
+}
+
+// Evaluate renders the template. The result is empty when any interpolated value is
+// missing or empty, so a partially rendered value such as "Bearer " is never sent.
+func (t *Template) Evaluate(requestContext RequestContext) (string, error) {
+	var result strings.Builder
+	for _, part := range t.parts {
+		if part.expr == nil {
+			result.WriteString(part.text)
+			continue
+		}
+		value, err := part.expr.EvaluateString(requestContext)
+		if err != nil || value == "" {
+			return "", err
+		}
+		result.WriteString(value)
+	}
+	return result.String(), nil
+}
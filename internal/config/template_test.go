@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		message  string
+	}{
+		{"unterminated placeholder", "Bearer {{ request.headers['token']", "unterminated {{ at position 8"},
+		{"empty placeholder", "Bearer {{ }}", "expected a name at position 1"},
+		{"invalid expression", "Bearer {{ cookies.token }}", `unknown variable "cookies"`},
+		{"closing braces end the placeholder", "{{ headers['}}'] }}", "unterminated string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTemplate(tt.template)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidExpression), "expected ErrInvalidExpression, got %v", err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestTemplate_Evaluate(t *testing.T) {
+	ctx := NewRequestContextFromMap(
+		map[string]string{"X-Provider-Data": `{"apikey": "key-abc", "region": "eu"}`},
+		map[string]string{"tenant": "acme"},
+		nil, "GET", "/api/test",
+	)
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"prefix", "Bearer {{ request.headers['x-provider-data'].apikey }}", "Bearer key-abc"},
+		{"without spaces", "Bearer {{request.headers['x-provider-data'].apikey}}", "Bearer key-abc"},
+		{"several placeholders", "{{ query.tenant }}/{{ headers['x-provider-data'].region }}", "acme/eu"},
+		{"text only", "no placeholders", "no placeholders"},
+		{"missing value omits the header", "Bearer {{ request.headers['x-missing'] }}", ""},
+		{"one missing value omits the header", "{{ query.tenant }}/{{ query.region }}", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate(tt.template)
+			require.NoError(t, err)
+			result, err := tmpl.Evaluate(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCompileTemplate_CachesParsedTemplates(t *testing.T) {
+	source := "Bearer {{ request.headers['x-compiled-token'] }}"
+	first, err := compileTemplate(source)
+	require.NoError(t, err)
+	second, err := compileTemplate(source)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	value, err := second.Evaluate(RequestContext{Headers: map[string]string{"x-compiled-token": "abc"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer abc", value)
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TenantsConfig lets one deployment serve several customers' instances of an API. The tenant
+// of each request is read with a valueFrom expression, so every session reaches the instance
+// selected by its own headers or token claims.
+type TenantsConfig struct {
+	From      string                  `yaml:"from" json:"from"`           // valueFrom expression naming the tenant, e.g. claims.tenant
+	Instances map[string]TenantConfig `yaml:"instances" json:"instances"` // Upstream instance of each tenant
+	Required  bool                    `yaml:"required" json:"required"`   // Reject requests without a tenant instead of using the API's own base_url
+}
+
+// TenantConfig is the upstream instance of one tenant
+type TenantConfig struct {
+	BaseURL string      `yaml:"base_url" json:"base_url"`
+	Auth    *AuthConfig `yaml:"auth" json:"auth"` // Credentials for this instance; the API's auth when omitted
+}
+
+// Enabled reports whether requests select a tenant
+func (t *TenantsConfig) Enabled() bool {
+	return t.From != ""
+}
+
+// Validate validates the TenantsConfig
+func (t *TenantsConfig) Validate() error {
+	if !t.Enabled() {
+		if len(t.Instances) > 0 {
+			return fmt.Errorf("'from' is required when instances are configured")
+		}
+		return nil
+	}
+	if _, err := compileExpression(t.From); err != nil {
+		return fmt.Errorf("from: %w", err)
+	}
+	if len(t.Instances) == 0 {
+		return fmt.Errorf("at least one instance is required")
+	}
+	for _, name := range t.Names() {
+		instance := t.Instances[name]
+		if instance.BaseURL == "" {
+			return fmt.Errorf("instance %s: base_url is required", name)
+		}
+		if instance.Auth != nil {
+			if err := instance.Auth.Validate(); err != nil {
+				return fmt.Errorf("instance %s: invalid auth: %w", name, err)
+			}
+			if err := instance.Auth.Headers.Validate(); err != nil {
+				return fmt.Errorf("instance %s: invalid auth headers: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Names returns the configured tenant names in sorted order
+func (t *TenantsConfig) Names() []string {
+	names := make([]string, 0, len(t.Instances))
+	for name := range t.Instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select returns the tenant named by the request, or "" when the request names none and
+// tenants are optional. Requests naming an unconfigured tenant fail with ErrUnknownTenant.
+func (t *TenantsConfig) Select(requestContext RequestContext) (string, error) {
+	expr, err := compileExpression(t.From)
+	if err != nil {
+		return "", err
+	}
+	name, err := expr.EvaluateString(requestContext)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		if t.Required {
+			return "", fmt.Errorf("%w: request does not name a tenant", ErrUnknownTenant)
+		}
+		return "", nil
+	}
+	if _, exists := t.Instances[name]; !exists {
+		return "", fmt.Errorf("%w: %q", ErrUnknownTenant, name)
+	}
+	return name, nil
+}
+
+// ForTenant returns a copy of the API configuration that reaches the named tenant's instance
+func (o *OpenAPIConfig) ForTenant(name string) *OpenAPIConfig {
+	instance := o.Tenants.Instances[name]
+	tenant := *o
+	tenant.BaseURL = instance.BaseURL
+	if instance.Auth != nil {
+		tenant.Auth = *instance.Auth
+	}
+	tenant.Tenants = TenantsConfig{}
+	// The secondary base URLs are the API's own, not the tenant's
+	tenant.Failover = FailoverConfig{}
+	return &tenant
+}
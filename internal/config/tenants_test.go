@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTenantsConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tenants TenantsConfig
+		message string
+	}{
+		{"disabled", TenantsConfig{}, ""},
+		{"valid", TenantsConfig{From: "claims.tenant", Instances: map[string]TenantConfig{"acme": {BaseURL: "https://acme.example.com"}}}, ""},
+		{"instances without from", TenantsConfig{Instances: map[string]TenantConfig{"acme": {BaseURL: "https://acme.example.com"}}}, "'from' is required"},
+		{"invalid from", TenantsConfig{From: "cookies.tenant", Instances: map[string]TenantConfig{"acme": {BaseURL: "https://acme.example.com"}}}, "from: invalid valueFrom expression"},
+		{"no instances", TenantsConfig{From: "claims.tenant"}, "at least one instance is required"},
+		{"missing base url", TenantsConfig{From: "claims.tenant", Instances: map[string]TenantConfig{"acme": {}}}, "instance acme: base_url is required"},
+		{"invalid auth", TenantsConfig{From: "claims.tenant", Instances: map[string]TenantConfig{
+			"acme": {BaseURL: "https://acme.example.com", Auth: &AuthConfig{Type: "bearer", Token: "a", TokenFile: "/tmp/token"}},
+		}}, "instance acme: invalid auth: token and token_file are mutually exclusive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tenants.Validate()
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestTenantsConfig_Select(t *testing.T) {
+	tenants := TenantsConfig{
+		From: "request.headers['x-tenant']",
+		Instances: map[string]TenantConfig{
+			"acme":   {BaseURL: "https://acme.example.com"},
+			"globex": {BaseURL: "https://globex.example.com"},
+		},
+	}
+
+	name, err := tenants.Select(RequestContext{Headers: map[string]string{"x-tenant": "globex"}})
+	require.NoError(t, err)
+	assert.Equal(t, "globex", name)
+
+	name, err = tenants.Select(RequestContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "", name)
+
+	_, err = tenants.Select(RequestContext{Headers: map[string]string{"x-tenant": "initech"}})
+	assert.True(t, errors.Is(err, ErrUnknownTenant))
+
+	tenants.Required = true
+	_, err = tenants.Select(RequestContext{})
+	assert.True(t, errors.Is(err, ErrUnknownTenant))
+}
+
+func TestOpenAPIConfig_ForTenant(t *testing.T) {
+	var api OpenAPIConfig
+	require.NoError(t, yaml.Unmarshal([]byte(`
+base_url: "https://api.example.com"
+auth:
+  type: bearer
+  token: shared
+tenants:
+  from: claims.tenant
+  instances:
+    acme:
+      base_url: "https://acme.example.com"
+      auth:
+        type: api_key
+        api_key: acme-key
+        api_key_name: X-API-Key
+        api_key_in: header
+    globex:
+      base_url: "https://globex.example.com"
+`), &api))
+	require.NoError(t, api.Validate())
+
+	acme := api.ForTenant("acme")
+	assert.Equal(t, "https://acme.example.com", acme.BaseURL)
+	assert.Equal(t, "api_key", acme.Auth.Type)
+	assert.False(t, acme.Tenants.Enabled())
+
+	globex := api.ForTenant("globex")
+	assert.Equal(t, "https://globex.example.com", globex.BaseURL)
+	assert.Equal(t, "shared", globex.Auth.Token)
+	assert.Equal(t, "https://api.example.com", api.BaseURL, "the API configuration is not modified")
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// TLSConfig contains TLS policy settings shared by the HTTP transport and the upstream client
+type TLSConfig struct {
+	MinVersion   string   `yaml:"min_version" json:"min_version"`     // "1.2" or "1.3"
+	CipherSuites []string `yaml:"cipher_suites" json:"cipher_suites"` // IANA names; only applies to TLS 1.2 and below
+}
+
+// ServerTLSConfig contains TLS configuration for serving the HTTP transport over HTTPS
+type ServerTLSConfig struct {
+	Enabled   bool   `yaml:"enabled" json:"enabled"`
+	CertFile  string `yaml:"cert_file" json:"cert_file"`
+	KeyFile   string `yaml:"key_file" json:"key_file"`
+	TLSConfig `yaml:",inline"`
+}
+
+// tlsVersions maps accepted version spellings to crypto/tls constants. TLS 1.0 and 1.1 are
+// deprecated (RFC 8996) and cannot be enabled.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// IsZero reports whether no TLS policy has been configured
+func (t TLSConfig) IsZero() bool {
+	return t.MinVersion == "" && len(t.CipherSuites) == 0
+}
+
+// Build converts the TLS policy into a crypto/tls configuration
+func (t TLSConfig) Build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if t.MinVersion != "" {
+		version := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(t.MinVersion)), "tls")
+		version = strings.TrimSpace(version)
+		minVersion, ok := tlsVersions[version]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS min_version: %q (expected 1.2 or 1.3)", t.MinVersion)
+		}
+		tlsConfig.MinVersion = minVersion
+	}
+
+	if len(t.CipherSuites) > 0 {
+		available := make(map[string]uint16)
+		for _, suite := range tls.CipherSuites() {
+			available[suite.Name] = suite.ID
+		}
+
+		for _, name := range t.CipherSuites {
+			id, ok := available[strings.TrimSpace(name)]
+			if !ok {
+				return nil, fmt.Errorf("unsupported or insecure TLS cipher suite: %q", name)
+			}
+			tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// Validate validates the server TLS configuration
+func (s *ServerTLSConfig) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	if s.CertFile == "" || s.KeyFile == "" {
+		return ErrMissingTLSCertificate
+	}
+	if _, err := s.Build(); err != nil {
+		return fmt.Errorf("invalid server TLS configuration: %w", err)
+	}
+	return nil
+}
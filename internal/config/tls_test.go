@@ -0,0 +1,126 @@
+package config
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTLSConfig_Build(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         TLSConfig
+		expectedMin    uint16
+		expectedSuites []uint16
+		wantErr        bool
+	}{
+		{
+			name:        "defaults to TLS 1.2",
+			config:      TLSConfig{},
+			expectedMin: tls.VersionTLS12,
+		},
+		{
+			name:        "TLS 1.3 minimum",
+			config:      TLSConfig{MinVersion: "1.3"},
+			expectedMin: tls.VersionTLS13,
+		},
+		{
+			name:        "TLS prefix accepted",
+			config:      TLSConfig{MinVersion: "TLS1.2"},
+			expectedMin: tls.VersionTLS12,
+		},
+		{
+			name: "explicit cipher suites",
+			config: TLSConfig{
+				MinVersion: "1.2",
+				CipherSuites: []string{
+					"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+					"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+				},
+			},
+			expectedMin: tls.VersionTLS12,
+			expectedSuites: []uint16{
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			},
+		},
+		{
+			name:    "unknown version",
+			config:  TLSConfig{MinVersion: "2.0"},
+			wantErr: true,
+		},
+		{
+			name:    "TLS 1.0 rejected",
+			config:  TLSConfig{MinVersion: "1.0"},
+			wantErr: true,
+		},
+		{
+			name:    "TLS 1.1 rejected",
+			config:  TLSConfig{MinVersion: "TLS1.1"},
+			wantErr: true,
+		},
+		{
+			name:    "insecure cipher suite rejected",
+			config:  TLSConfig{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown cipher suite",
+			config:  TLSConfig{CipherSuites: []string{"TLS_MADE_UP"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := tt.config.Build()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMin, tlsConfig.MinVersion)
+			assert.Equal(t, tt.expectedSuites, tlsConfig.CipherSuites)
+		})
+	}
+}
+
+func TestServerTLSConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&ServerTLSConfig{}).Validate())
+	assert.Equal(t, ErrMissingTLSCertificate, (&ServerTLSConfig{Enabled: true, CertFile: "cert.pem"}).Validate())
+	assert.NoError(t, (&ServerTLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem"}).Validate())
+	assert.Error(t, (&ServerTLSConfig{
+		Enabled:   true,
+		CertFile:  "cert.pem",
+		KeyFile:   "key.pem",
+		TLSConfig: TLSConfig{MinVersion: "0.9"},
+	}).Validate())
+}
+
+func TestServerTLSConfig_Unmarshal(t *testing.T) {
+	var cfg HTTPConfig
+	err := yaml.Unmarshal([]byte(`
+port: 8443
+tls:
+  enabled: true
+  cert_file: /etc/mcpify/tls.crt
+  key_file: /etc/mcpify/tls.key
+  min_version: "1.3"
+  cipher_suites:
+    - TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384
+`), &cfg)
+	require.NoError(t, err)
+	assert.True(t, cfg.TLS.Enabled)
+	assert.Equal(t, "/etc/mcpify/tls.crt", cfg.TLS.CertFile)
+	assert.Equal(t, "1.3", cfg.TLS.MinVersion)
+	assert.Equal(t, []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}, cfg.TLS.CipherSuites)
+
+	var jsonCfg HTTPConfig
+	err = jsonCfg.UnmarshalJSON([]byte(`{"port": 8443, "tls": {"enabled": true, "cert_file": "c", "key_file": "k", "min_version": "1.2"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "1.2", jsonCfg.TLS.MinVersion)
+	assert.Equal(t, "k", jsonCfg.TLS.KeyFile)
+}
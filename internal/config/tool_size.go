@@ -0,0 +1,63 @@
+package config
+
+import "fmt"
+
+// Input schema thresholds above which tools are reported, as some MCP clients reject them
+const (
+	DefaultWarnSchemaSize  = "32KB"
+	DefaultWarnSchemaDepth = 10
+)
+
+// ToolSizeConfig caps the size of what tools/list sends for each tool, for specs with very
+// long descriptions or deeply nested schemas. Zero values leave tools as generated.
+type ToolSizeConfig struct {
+	// MaxDescriptionLength caps tool, argument and schema descriptions, in characters. Longer
+	// descriptions are cut at the end of a sentence when possible.
+	MaxDescriptionLength int `yaml:"max_description_length" json:"max_description_length"`
+	// MaxSchemaDepth collapses input schema levels nested deeper than this into schemas
+	// accepting any value of their type; the arguments of a tool are at depth 1
+	MaxSchemaDepth int `yaml:"max_schema_depth" json:"max_schema_depth"`
+	// WarnSchemaSize and WarnSchemaDepth log a warning for tools whose serialized input
+	// schema is larger or deeper, 32KB and 10 levels by default
+	WarnSchemaSize  string `yaml:"warn_schema_size" json:"warn_schema_size"`
+	WarnSchemaDepth int    `yaml:"warn_schema_depth" json:"warn_schema_depth"`
+	// Simplify collapses the deepest levels of the schemas of such tools until they are
+	// within the thresholds
+	Simplify bool `yaml:"simplify" json:"simplify"`
+}
+
+// WarnSchemaSizeOrDefault returns the input schema size above which tools are reported, in bytes
+func (t *ToolSizeConfig) WarnSchemaSizeOrDefault() int64 {
+	if size, err := ParseSize(t.WarnSchemaSize); err == nil && size > 0 {
+		return size
+	}
+	size, _ := ParseSize(DefaultWarnSchemaSize)
+	return size
+}
+
+// WarnSchemaDepthOrDefault returns the input schema depth above which tools are reported
+func (t *ToolSizeConfig) WarnSchemaDepthOrDefault() int {
+	if t.WarnSchemaDepth > 0 {
+		return t.WarnSchemaDepth
+	}
+	return DefaultWarnSchemaDepth
+}
+
+// Validate validates the ToolSizeConfig
+func (t *ToolSizeConfig) Validate() error {
+	if t.MaxDescriptionLength < 0 {
+		return fmt.Errorf("max_description_length cannot be negative")
+	}
+	if t.MaxSchemaDepth < 0 {
+		return fmt.Errorf("max_schema_depth cannot be negative")
+	}
+	if t.WarnSchemaSize != "" {
+		if size, err := ParseSize(t.WarnSchemaSize); err != nil || size <= 0 {
+			return fmt.Errorf("invalid warn_schema_size: %q", t.WarnSchemaSize)
+		}
+	}
+	if t.WarnSchemaDepth < 0 {
+		return fmt.Errorf("warn_schema_depth cannot be negative")
+	}
+	return nil
+}
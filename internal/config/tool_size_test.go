@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolSizeConfig(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.ToolSize = ToolSizeConfig{MaxDescriptionLength: 500, MaxSchemaDepth: 4}
+	require.NoError(t, cfg.Validate())
+
+	assert.Equal(t, int64(32*1024), cfg.ToolSize.WarnSchemaSizeOrDefault())
+	assert.Equal(t, DefaultWarnSchemaDepth, cfg.ToolSize.WarnSchemaDepthOrDefault())
+	cfg.ToolSize.WarnSchemaSize = "8KB"
+	cfg.ToolSize.WarnSchemaDepth = 6
+	assert.Equal(t, int64(8*1024), cfg.ToolSize.WarnSchemaSizeOrDefault())
+	assert.Equal(t, 6, cfg.ToolSize.WarnSchemaDepthOrDefault())
+
+	for _, invalid := range []ToolSizeConfig{{MaxSchemaDepth: -1}, {WarnSchemaSize: "big"}, {WarnSchemaDepth: -1}} {
+		cfg.ToolSize = invalid
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid tool_size")
+	}
+}
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ToolOverride customizes an individual generated tool. Keys in the tools section are
+// a tool name ("get_users"), a path pattern ("/admin/*"), or a method and path pattern
+// ("DELETE /users/*"); wildcards are supported in all three forms.
+type ToolOverride struct {
+	Enabled     *bool         `yaml:"enabled" json:"enabled"`         // nil keeps the tool enabled
+	Description string        `yaml:"description" json:"description"` // Replaces the generated description
+	Timeout     time.Duration `yaml:"timeout" json:"timeout"`         // Per-call timeout for the upstream request
+	Headers     HeadersConfig `yaml:"headers" json:"headers"`         // Extra headers sent with this tool's requests
+	RateLimit   int           `yaml:"rate_limit" json:"rate_limit"`   // Maximum calls per minute, 0 for no limit
+	Accept      string        `yaml:"accept" json:"accept"`           // Replaces the Accept header derived from the operation's responses
+	// SkipMiddleware names stages of the upstream call pipeline not run for this tool
+	SkipMiddleware []string `yaml:"skip_middleware" json:"skip_middleware"`
+	// Pagination fetches every page of a paginated operation in one call
+	Pagination *PaginationConfig `yaml:"pagination" json:"pagination"`
+	// SubpathParams names path parameters whose values may span several segments, such as
+	// file paths: their slashes are kept while each segment is still percent-encoded
+	SubpathParams []string `yaml:"subpath_params" json:"subpath_params"`
+	// HedgeDelay sends a second request when a GET gets no response within it, and takes
+	// whichever response arrives first; 0 disables hedging
+	HedgeDelay time.Duration `yaml:"hedge_delay" json:"hedge_delay"`
+	// Debug logs the requests and responses of the tool, as debug does for every tool of an API
+	Debug bool `yaml:"debug" json:"debug"`
+	// CacheTTL reuses the 200 responses of GET requests for this long, keyed by the full
+	// request; 0 disables caching
+	CacheTTL time.Duration `yaml:"cache_ttl" json:"cache_ttl"`
+	// PreCall sets arguments from valueFrom expressions before the call, e.g. a date range
+	// computed from "days_ago(params.days)"; the client's arguments are read as params
+	PreCall map[string]string `yaml:"pre_call" json:"pre_call"`
+	// PostCall replaces the response body with fields selected by valueFrom expressions, which
+	// read the result as response, e.g. "response.body.data.items"
+	PostCall map[string]string `yaml:"post_call" json:"post_call"`
+}
+
+// Stages of the upstream call pipeline that tools can skip with skip_middleware
+const (
+	MiddlewareValidation = "validation" // Checks required arguments before anything is sent
+	MiddlewareExtensions = "extensions" // Runs the hooks of plugin extensions
+	MiddlewareAuth       = "auth"       // Applies the API's upstream credentials
+	MiddlewareHeaders    = "headers"    // Adds the configured API and tool headers
+	MiddlewareCaching    = "caching"    // Answers GET calls from earlier responses when cache_ttl is set
+	MiddlewareLogging    = "logging"    // Logs requests and responses when debug is enabled
+	MiddlewareRetry      = "retry"      // Retries failed requests up to max_retries
+)
+
+// middlewareStages lists the stages that can be skipped, in pipeline order
+var middlewareStages = []string{
+	MiddlewareValidation, MiddlewareExtensions, MiddlewareAuth, MiddlewareHeaders, MiddlewareCaching,
+	MiddlewareLogging, MiddlewareRetry,
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for ToolOverride
+func (t *ToolOverride) UnmarshalJSON(data []byte) error {
+	type Alias ToolOverride
+	aux := &struct {
+		Timeout    string `json:"timeout"`
+		HedgeDelay string `json:"hedge_delay"`
+		CacheTTL   string `json:"cache_ttl"`
+		*Alias
+	}{
+		Alias: (*Alias)(t),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Timeout != "" {
+		duration, err := time.ParseDuration(aux.Timeout)
+		if err != nil {
+			return err
+		}
+		t.Timeout = duration
+	}
+	if aux.HedgeDelay != "" {
+		duration, err := time.ParseDuration(aux.HedgeDelay)
+		if err != nil {
+			return err
+		}
+		t.HedgeDelay = duration
+	}
+	if aux.CacheTTL != "" {
+		duration, err := time.ParseDuration(aux.CacheTTL)
+		if err != nil {
+			return err
+		}
+		t.CacheTTL = duration
+	}
+
+	return nil
+}
+
+// IsEnabled reports whether the tool should be registered
+func (t *ToolOverride) IsEnabled() bool {
+	return t.Enabled == nil || *t.Enabled
+}
+
+// Validate validates the ToolOverride
+func (t *ToolOverride) Validate() error {
+	if t.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if t.RateLimit < 0 {
+		return fmt.Errorf("rate_limit must not be negative")
+	}
+	if t.HedgeDelay < 0 {
+		return fmt.Errorf("hedge_delay must not be negative")
+	}
+	if t.CacheTTL < 0 {
+		return fmt.Errorf("cache_ttl must not be negative")
+	}
+	if err := t.Headers.Validate(); err != nil {
+		return fmt.Errorf("invalid headers: %w", err)
+	}
+	if strings.ContainsAny(t.Accept, "\r\n") {
+		return fmt.Errorf("accept must not contain CR or LF characters")
+	}
+	if t.Pagination != nil {
+		if err := t.Pagination.Validate(); err != nil {
+			return fmt.Errorf("invalid pagination: %w", err)
+		}
+	}
+	for _, hook := range []struct {
+		name   string
+		fields map[string]string
+	}{{"pre_call", t.PreCall}, {"post_call", t.PostCall}} {
+		for name, source := range hook.fields {
+			if name == "" {
+				return fmt.Errorf("%s: field name is required", hook.name)
+			}
+			if _, err := compileExpression(source); err != nil {
+				return fmt.Errorf("%s: %s: %w", hook.name, name, err)
+			}
+		}
+	}
+	for _, stage := range t.SkipMiddleware {
+		if !slices.Contains(middlewareStages, stage) {
+			return fmt.Errorf("unknown middleware %q in skip_middleware, expected one of %s",
+				stage, strings.Join(middlewareStages, ", "))
+		}
+	}
+	return nil
+}
+
+// EvaluateFields evaluates a map of valueFrom expressions, such as the pre_call arguments of a
+// tool. Fields whose expression selects nothing are omitted.
+func EvaluateFields(fields map[string]string, requestContext RequestContext) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(fields))
+	for name, source := range fields {
+		expr, err := compileExpression(source)
+		if err != nil {
+			return nil, err
+		}
+		if value, found := expr.Evaluate(requestContext); found {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// mergeFields returns the fields of base with those of other on top
+func mergeFields(base, other map[string]string) map[string]string {
+	if len(other) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(other))
+	for name, source := range base {
+		merged[name] = source
+	}
+	for name, source := range other {
+		merged[name] = source
+	}
+	return merged
+}
+
+// merge applies the fields set in other on top of t
+func (t *ToolOverride) merge(other ToolOverride) {
+	if other.Enabled != nil {
+		t.Enabled = other.Enabled
+	}
+	if other.Description != "" {
+		t.Description = other.Description
+	}
+	if other.Timeout != 0 {
+		t.Timeout = other.Timeout
+	}
+	if other.RateLimit != 0 {
+		t.RateLimit = other.RateLimit
+	}
+	if other.HedgeDelay != 0 {
+		t.HedgeDelay = other.HedgeDelay
+	}
+	if other.CacheTTL != 0 {
+		t.CacheTTL = other.CacheTTL
+	}
+	if other.Accept != "" {
+		t.Accept = other.Accept
+	}
+	if other.Debug {
+		t.Debug = true
+	}
+	for _, item := range other.Headers {
+		t.Headers = append(removeHeader(t.Headers, item.Header.Name), item)
+	}
+	if other.Pagination != nil {
+		t.Pagination = other.Pagination
+	}
+	for _, stage := range other.SkipMiddleware {
+		if !slices.Contains(t.SkipMiddleware, stage) {
+			t.SkipMiddleware = append(t.SkipMiddleware, stage)
+		}
+	}
+	t.PreCall = mergeFields(t.PreCall, other.PreCall)
+	t.PostCall = mergeFields(t.PostCall, other.PostCall)
+	for _, param := range other.SubpathParams {
+		if !slices.Contains(t.SubpathParams, param) {
+			t.SubpathParams = append(t.SubpathParams, param)
+		}
+	}
+}
+
+// removeHeader returns headers without the entry named name
+func removeHeader(headers HeadersConfig, name string) HeadersConfig {
+	result := make(HeadersConfig, 0, len(headers))
+	for _, item := range headers {
+		if item.Header.Name != name {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// ResolveToolOverride returns the combined overrides matching a tool. More specific keys win:
+// a tool name over a method and path pattern, which wins over a path pattern.
+func (c *Config) ResolveToolOverride(name, method, path string) ToolOverride {
+	var pathKeys, methodKeys, nameKeys []string
+	for key := range c.Tools {
+		switch {
+		case strings.HasPrefix(key, "/"):
+			if MatchPath(key, path) {
+				pathKeys = append(pathKeys, key)
+			}
+		case strings.Contains(key, " /"):
+			keyMethod, keyPath, _ := strings.Cut(key, " ")
+			if strings.EqualFold(keyMethod, method) && MatchPath(keyPath, path) {
+				methodKeys = append(methodKeys, key)
+			}
+		default:
+			if MatchPath(key, name) {
+				nameKeys = append(nameKeys, key)
+			}
+		}
+	}
+
+	var result ToolOverride
+	for _, keys := range [][]string{pathKeys, methodKeys, nameKeys} {
+		// Sort so overlapping patterns of the same kind apply in a stable order
+		sort.Strings(keys)
+		for _, key := range keys {
+			result.merge(c.Tools[key])
+		}
+	}
+	return result
+}
+
+// MatchPath matches a pattern against a path or name, where "*" matches any sequence of characters
+func MatchPath(pattern, path string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == path
+	}
+
+	regexPattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	matched, err := regexp.MatchString(regexPattern, path)
+	return err == nil && matched
+}
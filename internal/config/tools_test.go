@@ -0,0 +1,138 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/users", "/users", true},
+		{"/users", "/users/1", false},
+		{"/users/*", "/users/1", true},
+		{"/users/*/orders", "/users/1/orders", true},
+		{"*_users", "get_users", true},
+		{"/v1.0/*", "/v1.0/items", true},
+		{"/v1.0/*", "/v1x0/items", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchPath(tt.pattern, tt.path), "MatchPath(%q, %q)", tt.pattern, tt.path)
+	}
+}
+
+func TestResolveToolOverride(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+tools:
+  "/admin/*":
+    enabled: false
+  "/users/*":
+    timeout: 10s
+    rate_limit: 60
+    cache_ttl: 30s
+    debug: true
+    pre_call:
+      limit: "params.page_size"
+    skip_middleware: ["retry"]
+    headers:
+      - header:
+          name: "X-Scope"
+          value: "users"
+  "DELETE /users/*":
+    enabled: false
+  get_users_by_id:
+    description: "Look up a single user"
+    timeout: 2s
+    skip_middleware: ["logging", "retry"]
+    pre_call:
+      since: "date(days_ago('7'))"
+    post_call:
+      user: "response.body.data"
+    headers:
+      - header:
+          name: "X-Scope"
+          value: "user"
+`), &cfg))
+
+	admin := cfg.ResolveToolOverride("get_admin_stats", "GET", "/admin/stats")
+	assert.False(t, admin.IsEnabled())
+
+	deleteUser := cfg.ResolveToolOverride("delete_users_by_id", "DELETE", "/users/{id}")
+	assert.False(t, deleteUser.IsEnabled())
+	assert.Equal(t, 10*time.Second, deleteUser.Timeout)
+	assert.True(t, deleteUser.Debug)
+
+	// The tool name entry wins over the path pattern; unset fields are inherited
+	getUser := cfg.ResolveToolOverride("get_users_by_id", "GET", "/users/{id}")
+	assert.True(t, getUser.IsEnabled())
+	assert.Equal(t, "Look up a single user", getUser.Description)
+	assert.Equal(t, 2*time.Second, getUser.Timeout)
+	assert.Equal(t, 60, getUser.RateLimit)
+	assert.True(t, getUser.Debug)
+	assert.Equal(t, 30*time.Second, getUser.CacheTTL)
+	assert.Equal(t, map[string]string{"limit": "params.page_size", "since": "date(days_ago('7'))"}, getUser.PreCall)
+	assert.Equal(t, map[string]string{"user": "response.body.data"}, getUser.PostCall)
+	require.Len(t, getUser.Headers, 1)
+	assert.Equal(t, "user", getUser.Headers.GetValue("X-Scope"))
+	assert.Equal(t, []string{"retry", "logging"}, getUser.SkipMiddleware)
+
+	other := cfg.ResolveToolOverride("get_orders", "GET", "/orders")
+	assert.Equal(t, ToolOverride{}, other)
+}
+
+func TestToolOverride_UnmarshalJSON(t *testing.T) {
+	var override ToolOverride
+	require.NoError(t, override.UnmarshalJSON([]byte(`{"enabled": false, "timeout": "45s", "rate_limit": 5, "accept": "text/csv", "cache_ttl": "1m", "headers": {"X-Tool": "yes"}}`)))
+	assert.False(t, override.IsEnabled())
+	assert.Equal(t, 45*time.Second, override.Timeout)
+	assert.Equal(t, 5, override.RateLimit)
+	assert.Equal(t, "text/csv", override.Accept)
+	assert.Equal(t, time.Minute, override.CacheTTL)
+	assert.Equal(t, "yes", override.Headers.GetValue("X-Tool"))
+
+	assert.Error(t, (&ToolOverride{}).UnmarshalJSON([]byte(`{"timeout": "soon"}`)))
+}
+
+func TestValidate_ToolOverrides(t *testing.T) {
+	config := Default()
+	config.OpenAPI.SpecPath = "https://api.example.com/openapi.json"
+	config.Tools = map[string]ToolOverride{"get_users": {RateLimit: 10}}
+	assert.NoError(t, config.Validate())
+
+	config.Tools = map[string]ToolOverride{"get_users": {RateLimit: -1}}
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tools[get_users]")
+
+	config.Tools = map[string]ToolOverride{"get_users": {CacheTTL: -time.Second}}
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache_ttl must not be negative")
+
+	config.Tools = map[string]ToolOverride{"get_users": {PreCall: map[string]string{"since": "days_ago(7)"}}}
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pre_call: since: invalid valueFrom expression")
+
+	config.Tools = map[string]ToolOverride{"get_users": {PostCall: map[string]string{"": "response.body"}}}
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "post_call: field name is required")
+
+	config.Tools = map[string]ToolOverride{"get_users": {SkipMiddleware: []string{"caching"}}}
+	assert.NoError(t, config.Validate())
+
+	config.Tools = map[string]ToolOverride{"get_users": {SkipMiddleware: []string{"retry", "compression"}}}
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown middleware "compression"`)
+}
@@ -0,0 +1,19 @@
+package config
+
+import "fmt"
+
+// UploadsConfig controls the files sent by multipart operations. Callers always can pass file
+// content as base64; reading files named by path is for trusted deployments, such as a stdio
+// server started by the user whose files it uploads.
+type UploadsConfig struct {
+	LocalFiles bool   `yaml:"local_files" json:"local_files"` // Accept paths of files on the server instead of content
+	Directory  string `yaml:"directory" json:"directory"`     // Only read local files under this directory, any readable file when empty
+}
+
+// Validate validates the UploadsConfig
+func (u *UploadsConfig) Validate() error {
+	if u.Directory != "" && !u.LocalFiles {
+		return fmt.Errorf("directory requires local_files")
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadsConfig_Validate(t *testing.T) {
+	cfg := Default()
+	cfg.OpenAPI.SpecPath = "spec.json"
+	cfg.OpenAPI.Uploads = UploadsConfig{LocalFiles: true, Directory: "/srv/uploads"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.OpenAPI.Uploads = UploadsConfig{Directory: "/srv/uploads"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid uploads: directory requires local_files")
+}
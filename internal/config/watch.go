@@ -0,0 +1,66 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultWatchInterval is how often watched files are checked when no interval is configured
+const DefaultWatchInterval = 10 * time.Second
+
+// WatchConfig reloads the configuration when the files it is loaded from change on disk, as
+// when Kubernetes updates a mounted ConfigMap or Secret by swapping its ..data symlink
+type WatchConfig struct {
+	Enabled  bool          `yaml:"enabled" json:"enabled"`
+	Interval time.Duration `yaml:"interval" json:"interval"` // How often files are checked, 10s by default
+	// Paths are additional files or directories to watch; the configuration file and local
+	// spec files are always watched
+	Paths []string `yaml:"paths" json:"paths"`
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for WatchConfig
+func (w *WatchConfig) UnmarshalJSON(data []byte) error {
+	type Alias WatchConfig
+	aux := &struct {
+		Interval string `json:"interval"`
+		*Alias
+	}{
+		Alias: (*Alias)(w),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Interval != "" {
+		duration, err := time.ParseDuration(aux.Interval)
+		if err != nil {
+			return err
+		}
+		w.Interval = duration
+	}
+
+	return nil
+}
+
+// IntervalOrDefault returns the configured check interval, or DefaultWatchInterval
+func (w *WatchConfig) IntervalOrDefault() time.Duration {
+	if w.Interval > 0 {
+		return w.Interval
+	}
+	return DefaultWatchInterval
+}
+
+// Validate validates the WatchConfig
+func (w *WatchConfig) Validate() error {
+	if w.Interval < 0 {
+		return fmt.Errorf("interval cannot be negative")
+	}
+	for _, path := range w.Paths {
+		if path == "" {
+			return fmt.Errorf("paths cannot contain empty entries")
+		}
+	}
+	return nil
+}
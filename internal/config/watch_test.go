@@ -0,0 +1,46 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		watch    WatchConfig
+		errorMsg string
+	}{
+		{name: "disabled", watch: WatchConfig{}},
+		{name: "with paths", watch: WatchConfig{Enabled: true, Interval: time.Second, Paths: []string{"/etc/mcpify/secrets"}}},
+		{name: "negative interval", watch: WatchConfig{Enabled: true, Interval: -time.Second}, errorMsg: "interval cannot be negative"},
+		{name: "empty path", watch: WatchConfig{Enabled: true, Paths: []string{""}}, errorMsg: "paths cannot contain empty entries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.OpenAPI.SpecPath = "spec.json"
+			cfg.Server.Watch = tt.watch
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid watch: "+tt.errorMsg)
+		})
+	}
+}
+
+func TestWatchConfig_Interval(t *testing.T) {
+	var watch WatchConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"enabled": true, "interval": "30s"}`), &watch))
+	assert.Equal(t, 30*time.Second, watch.IntervalOrDefault())
+
+	assert.Equal(t, DefaultWatchInterval, (&WatchConfig{}).IntervalOrDefault())
+}
@@ -2,140 +2,234 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"mcpify/internal/config"
+	"mcpify/internal/httpclient"
 	"mcpify/internal/types"
+	"mcpify/pkg/extension"
 )
 
+// defaultMaxResponseSize caps upstream response bodies when no limit is configured
+const defaultMaxResponseSize = 10 << 20
+
+// ErrResponseTooLarge is returned when an upstream response exceeds the configured maximum size
+var ErrResponseTooLarge = errors.New("upstream response too large")
+
 // APIHandler handles HTTP requests to external APIs
 type APIHandler struct {
-	config    *config.OpenAPIConfig
-	client    *http.Client
-	evaluator *config.RequestEvaluator
+	config          *config.OpenAPIConfig
+	client          *http.Client
+	evaluator       *config.RequestEvaluator
+	maxResponseSize int64
+	budget          *ResponseBudget
+	retry           *RetryPolicy
+	cache           *responseCache         // GET responses of tools with a cache_ttl
+	failover        *failover              // Picks among the API's base URLs, nil without secondary base URLs
+	extensions      extension.Extension    // Plugin hooks run on every call, nil when none are loaded
+	tenants         map[string]*APIHandler // Handlers for the instance of each tenant
 }
 
 // NewAPIHandler creates a new API handler
 func NewAPIHandler(cfg *config.OpenAPIConfig) *APIHandler {
-	return &APIHandler{
-		config: cfg,
-		client: &http.Client{
-			Timeout: cfg.Timeout,
-		},
-		evaluator: config.NewRequestEvaluator(),
+	maxResponseSize := int64(defaultMaxResponseSize)
+	if cfg.MaxResponseSize != "" {
+		if size, err := config.ParseSize(cfg.MaxResponseSize); err == nil && size > 0 {
+			maxResponseSize = size
+		}
 	}
-}
 
-// HandleAPICall handles an API call based on the tool configuration
-func (h *APIHandler) HandleAPICall(tool types.APITool, params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
-	// Log tool and parameters for debugging
-	if h.config.Debug {
-		log.Printf("DEBUG: Tool: %s (%s %s)", tool.Name, tool.Method, tool.Path)
-		log.Printf("DEBUG: Tool description: %s", tool.Description)
-		log.Printf("DEBUG: Parameters received: %+v", params)
-		log.Printf("DEBUG: Request context: %+v", requestContext)
+	h := &APIHandler{
+		config:          cfg,
+		client:          httpclient.New(cfg),
+		evaluator:       config.NewRequestEvaluator(),
+		maxResponseSize: maxResponseSize,
+		retry:           NewRetryPolicy(config.RetryConfig{}),
+		cache:           newResponseCache(),
+	}
+	h.failover = newFailover(cfg, h.client)
+	if cfg.Tenants.Enabled() {
+		h.tenants = make(map[string]*APIHandler, len(cfg.Tenants.Instances))
+		for name := range cfg.Tenants.Instances {
+			h.tenants[name] = NewAPIHandler(cfg.ForTenant(name))
+		}
 	}
+	return h
+}
 
-	// Build the request URL
-	requestURL, err := h.buildRequestURL(tool, params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build request URL: %w", err)
+// SetResponseBudget shares a response memory budget with the handler; nil removes the limit
+func (h *APIHandler) SetResponseBudget(budget *ResponseBudget) {
+	h.budget = budget
+	for _, tenant := range h.tenants {
+		tenant.budget = budget
 	}
+}
 
-	// Create HTTP request
-	req, err := h.createRequest(tool, requestURL, params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// SetRetryPolicy shares a retry policy, and with it its retry budget, with the handler
+func (h *APIHandler) SetRetryPolicy(policy *RetryPolicy) {
+	h.retry = policy
+	for _, tenant := range h.tenants {
+		tenant.retry = policy
 	}
+}
 
-	// Add authentication headers
-	h.addAuthHeaders(req, requestContext)
+// SetExtensions installs the plugin hooks run on every call; nil removes them
+func (h *APIHandler) SetExtensions(ext extension.Extension) {
+	h.extensions = ext
+	for _, tenant := range h.tenants {
+		tenant.extensions = ext
+	}
+}
 
-	// Add custom headers (static and dynamic)
-	// Convert headers map to http.Header for evaluation
-	evaluatedHeaders, err := h.evaluator.EvaluateHeaders(h.config.Headers, requestContext)
+// forRequest returns the handler for the tenant instance the request selects, or h itself
+// when the API is not multi-tenant or the request names no tenant
+func (h *APIHandler) forRequest(requestContext config.RequestContext) (*APIHandler, error) {
+	if !h.config.Tenants.Enabled() {
+		return h, nil
+	}
+	name, err := h.config.Tenants.Select(requestContext)
 	if err != nil {
-		return nil, fmt.Errorf("failed to evaluate headers: %w", err)
+		return nil, fmt.Errorf("failed to select tenant: %w", err)
 	}
+	if name == "" {
+		return h, nil
+	}
+	return h.tenants[name], nil
+}
 
-	for name, value := range evaluatedHeaders {
-		req.Header.Set(name, value)
+// HandleAPICall handles an API call based on the tool configuration
+func (h *APIHandler) HandleAPICall(tool types.APITool, params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+	// Multi-tenant APIs send each call to the instance of the caller's tenant
+	target, err := h.forRequest(requestContext)
+	if err != nil {
+		return nil, err
+	}
+	if target != h {
+		return target.HandleAPICall(tool, params, requestContext)
 	}
 
-	// Log request details for debugging
-	if h.config.Debug {
-		log.Printf("DEBUG: Making %s request to: %s", req.Method, req.URL.String())
-		log.Printf("DEBUG: Request headers: %+v", req.Header)
-		if req.Body != nil {
-			// Read the body to log it, then recreate it
-			bodyBytes, _ := io.ReadAll(req.Body)
-			log.Printf("DEBUG: Request body: %s", string(bodyBytes))
-			req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-		}
+	if params, err = preCall(tool, params, requestContext); err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if tool.Pagination != nil {
+		result, err = h.paginate(tool, params, requestContext)
+	} else {
+		result, err = h.call(tool, params, requestContext)
+	}
+	if err != nil {
+		return nil, err
 	}
+	// Pagination reads any header, so headers are only filtered for the client
+	headers, _ := result["headers"].(map[string]string)
+	result["headers"] = h.allowedHeaders(headers)
+	if err := postCall(tool, params, result, requestContext); err != nil {
+		return nil, err
+	}
+	if summary := h.summarize(result); summary != nil {
+		result["summarized"] = summary
+	}
+	return result, nil
+}
 
-	// Make the request with retries
-	var resp *http.Response
-	for attempt := 0; attempt <= h.config.MaxRetries; attempt++ {
-		if h.config.Debug && attempt > 0 {
-			log.Printf("DEBUG: Retry attempt %d/%d", attempt, h.config.MaxRetries)
-		}
-		resp, err = h.client.Do(req)
-		if err == nil {
-			if h.config.Debug && attempt > 0 {
-				log.Printf("DEBUG: Request succeeded on attempt %d", attempt+1)
+// allowedHeaders returns the response headers matching the response_headers allowlist
+func (h *APIHandler) allowedHeaders(headers map[string]string) map[string]string {
+	allowed := make(map[string]string)
+	patterns := h.config.ResponseHeadersOrDefault()
+	for name, value := range headers {
+		for _, pattern := range patterns {
+			if config.MatchPath(strings.ToLower(pattern), strings.ToLower(name)) {
+				allowed[name] = value
+				break
 			}
-			break
-		}
-		if attempt < h.config.MaxRetries {
-			if h.config.Debug {
-				log.Printf("DEBUG: Request failed (attempt %d): %v, retrying in %d seconds", attempt+1, err, attempt+1)
-			}
-			time.Sleep(time.Duration(attempt+1) * time.Second)
 		}
 	}
+	return allowed
+}
 
+// call sends one upstream request for a tool call through the pipeline and returns the
+// status code, headers and decoded body of the response
+func (h *APIHandler) call(tool types.APITool, params map[string]interface{}, requestContext config.RequestContext) (map[string]interface{}, error) {
+	call := &Call{Tool: tool, Params: params, RequestContext: requestContext}
+	resp, err := pipeline(tool, h.stages(), h.send)(call)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request after %d attempts: %w", h.config.MaxRetries+1, err)
+		// Failures after the request is built report the URL they tried
+		var argumentErr *types.ArgumentError
+		if call.Request != nil && !errors.As(err, &argumentErr) {
+			err = &types.RequestError{URL: h.redactURL(call.Request.URL), Err: err}
+		}
+		return nil, err
 	}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	// Reserve room for the body in the shared budget while it is buffered and decoded
+	if h.budget != nil {
+		timeout := h.client.Timeout
+		if tool.Timeout > 0 {
+			timeout = tool.Timeout
+		}
+		reserved, err := h.reserveResponse(call.Request.Context(), resp, timeout)
+		if err != nil {
+			return nil, err
+		}
+		defer h.budget.Release(reserved)
 	}
 
-	// Log response details for debugging
-	if h.config.Debug {
-		log.Printf("DEBUG: Response status: %d", resp.StatusCode)
-		log.Printf("DEBUG: Response headers: %+v", resp.Header)
+	// Read response body, refusing to buffer more than the configured maximum
+	body, err := h.readResponseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if h.debug(call) && !slices.Contains(tool.SkipMiddleware, config.MiddlewareLogging) {
 		log.Printf("DEBUG: Response body: %s", string(body))
 	}
 
+	// SOAP services report faults with status 500, so their envelope is decoded first
+	var result interface{}
+	if tool.SOAP != nil {
+		if result, err = decodeSOAPEnvelope(body); err != nil {
+			return nil, err
+		}
+	}
+
 	// Handle response based on status code
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		requested := call.Request.URL
+		if resp.Request != nil {
+			requested = resp.Request.URL
+		}
+		return nil, &types.UpstreamError{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RequestID:  responseRequestID(resp),
+			URL:        h.redactURL(requested),
+		}
 	}
 
 	// Parse response body
-	var result interface{}
-	if len(body) > 0 {
+	if tool.SOAP == nil && len(body) > 0 {
 		// Try to parse as JSON
-		if err := json.Unmarshal(body, &result); err != nil {
+		if err := types.DecodeJSON(body, &result); err != nil {
 			// If not JSON, return as string - this is valid for APIs that return plain text
 			result = string(body)
 		}
 	}
+	if h.config.JSONAPI {
+		result = flattenJSONAPI(result)
+	}
 
 	// Convert headers to a serializable map
 	headers := make(map[string]string)
@@ -152,12 +246,94 @@ func (h *APIHandler) HandleAPICall(tool types.APITool, params map[string]interfa
 	}, nil
 }
 
+// BuildRequest creates the upstream request for a tool call, with authentication and
+// configured headers and query parameters applied, without sending it. It runs the request,
+// auth and headers stages of the pipeline that the tool does not skip.
+func (h *APIHandler) BuildRequest(tool types.APITool, params map[string]interface{}, requestContext config.RequestContext) (*http.Request, error) {
+	target, err := h.forRequest(requestContext)
+	if err != nil {
+		return nil, err
+	}
+	if target != h {
+		return target.BuildRequest(tool, params, requestContext)
+	}
+
+	call := &Call{Tool: tool, Params: params, RequestContext: requestContext}
+	built := func(call *Call) (*http.Response, error) { return nil, nil }
+	if _, err := pipeline(tool, h.requestStages(), built)(call); err != nil {
+		return nil, err
+	}
+	return call.Request, nil
+}
+
+// reserveResponse reserves the response's Content-Length from the budget, or the maximum response
+// size when the length is unknown, waiting at most timeout
+func (h *APIHandler) reserveResponse(ctx context.Context, resp *http.Response, timeout time.Duration) (int64, error) {
+	reserved := resp.ContentLength
+	if reserved < 0 || reserved > h.maxResponseSize {
+		reserved = h.maxResponseSize
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := h.budget.Acquire(ctx, reserved); err != nil {
+		return 0, err
+	}
+	return reserved, nil
+}
+
+// readResponseBody reads the response body up to maxResponseSize bytes
+func (h *APIHandler) readResponseBody(resp *http.Response) ([]byte, error) {
+	if resp.ContentLength > h.maxResponseSize {
+		return nil, fmt.Errorf("%w: Content-Length %d exceeds limit of %d bytes", ErrResponseTooLarge, resp.ContentLength, h.maxResponseSize)
+	}
+
+	// Read one byte past the limit so an oversized body can be detected
+	body, err := io.ReadAll(io.LimitReader(resp.Body, h.maxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(body)) > h.maxResponseSize {
+		return nil, fmt.Errorf("%w: body exceeds limit of %d bytes", ErrResponseTooLarge, h.maxResponseSize)
+	}
+
+	return body, nil
+}
+
+// baseURL returns the base URL of the tool's requests: the operation's own base URL, resolved
+// against the API's when it is relative, or the API's base URL
+func (h *APIHandler) baseURL(tool types.APITool) (string, error) {
+	if tool.BaseURL != "" {
+		operationURL, err := url.Parse(tool.BaseURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid base URL %q: %w", tool.BaseURL, err)
+		}
+		if operationURL.IsAbs() {
+			return tool.BaseURL, nil
+		}
+		if h.config.BaseURL != "" {
+			apiURL, err := url.Parse(h.config.BaseURL)
+			if err != nil {
+				return "", fmt.Errorf("invalid base URL %q: %w", h.config.BaseURL, err)
+			}
+			return apiURL.ResolveReference(operationURL).String(), nil
+		}
+	}
+	if h.config.BaseURL == "" {
+		return "", fmt.Errorf("base URL not configured")
+	}
+	return h.config.BaseURL, nil
+}
+
 // buildRequestURL builds the complete request URL
 func (h *APIHandler) buildRequestURL(tool types.APITool, params map[string]interface{}) (string, error) {
 	// Start with base URL
-	baseURL := h.config.BaseURL
-	if baseURL == "" {
-		return "", fmt.Errorf("base URL not configured")
+	baseURL, err := h.baseURL(tool)
+	if err != nil {
+		return "", err
 	}
 
 	// Ensure base URL ends with /
@@ -174,13 +350,23 @@ func (h *APIHandler) buildRequestURL(tool types.APITool, params map[string]inter
 	// Replace path parameters
 	for _, param := range tool.Parameters {
 		if param.In == "path" {
-			paramValue, exists := params[param.Name]
+			paramValue, exists := parameterValue(tool, params, param)
 			if !exists && param.Required {
-				return "", fmt.Errorf("required path parameter '%s' not provided", param.Name)
+				return "", types.ArgumentErrorf("required path parameter '%s' not provided", param.Name)
 			}
 			if exists {
 				placeholder := "{" + param.Name + "}"
-				requestURL = strings.ReplaceAll(requestURL, placeholder, fmt.Sprintf("%v", paramValue))
+				value := fmt.Sprintf("%v", paramValue)
+				if param.ODataString {
+					// OData string literals escape a quote by doubling it, e.g. 'O''Brien'
+					value = strings.ReplaceAll(value, "'", "''")
+				}
+				if slices.Contains(tool.SubpathParams, param.Name) {
+					value = escapeSubpath(value)
+				} else {
+					value = escapePathParam(value)
+				}
+				requestURL = strings.ReplaceAll(requestURL, placeholder, value)
 			}
 		}
 	}
@@ -189,20 +375,17 @@ func (h *APIHandler) buildRequestURL(tool types.APITool, params map[string]inter
 	queryParams := url.Values{}
 	for _, param := range tool.Parameters {
 		if param.In == "query" {
-			paramValue, exists := params[param.Name]
-			if exists {
+			paramValue, exists := parameterValue(tool, params, param)
+			if exists && h.config.JSONAPI {
+				addJSONAPIQuery(queryParams, param.Name, paramValue)
+			} else if exists {
 				queryParams.Add(param.Name, fmt.Sprintf("%v", paramValue))
 			} else if param.Required {
-				return "", fmt.Errorf("required query parameter '%s' not provided", param.Name)
+				return "", types.ArgumentErrorf("required query parameter '%s' not provided", param.Name)
 			}
 		}
 	}
 
-	// Add API key as query parameter if configured
-	if h.config.Auth.Type == "api_key" && h.config.Auth.APIKeyIn == "query" {
-		queryParams.Add(h.config.Auth.APIKeyName, h.config.Auth.APIKey)
-	}
-
 	// Append query parameters to URL
 	if len(queryParams) > 0 {
 		requestURL += "?" + queryParams.Encode()
@@ -217,33 +400,29 @@ func (h *APIHandler) createRequest(tool types.APITool, requestURL string, params
 	var contentType string
 
 	// Handle request body for POST, PUT, PATCH methods
-	if (tool.RequestBody != nil || hasBodyParameter(tool)) && (tool.Method == "POST" || tool.Method == "PUT" || tool.Method == "PATCH") {
-		// Look for body parameter in params
-		// Try multiple possible parameter names for compatibility
-		var bodyData interface{}
-		var exists bool
-
-		// First try "body" (OpenAPI 3.0 style)
-		if bodyData, exists = params["body"]; !exists {
-			// Then try "request" (Swagger 2.0 style)
-			if bodyData, exists = params["request"]; !exists {
-				// Finally, look for any body parameter from the tool definition
-				for _, param := range tool.Parameters {
-					if param.In == "body" {
-						if bodyData, exists = params[param.Name]; exists {
-							break
-						}
-					}
-				}
-			}
-		}
+	if sendsBody(tool) {
+		bodyData, exists := params[types.BodyArgument]
 
-		if exists {
+		if exists && sendsMultipart(tool) {
+			encoded, multipartType, err := h.encodeMultipart(tool, bodyData)
+			if err != nil {
+				return nil, err
+			}
+			body = encoded
+			contentType = multipartType
+		} else if exists && sendsForm(tool) {
+			encoded, err := encodeForm(bodyData)
+			if err != nil {
+				return nil, err
+			}
+			body = strings.NewReader(encoded)
+			contentType = formMediaType
+		} else if exists {
 			switch v := bodyData.(type) {
 			case string:
 				// Try to parse as JSON first
 				var jsonData interface{}
-				if err := json.Unmarshal([]byte(v), &jsonData); err == nil {
+				if err := types.DecodeJSON([]byte(v), &jsonData); err == nil {
 					// Successfully parsed as JSON, marshal it back to ensure proper formatting
 					jsonBytes, err := json.Marshal(jsonData)
 					if err != nil {
@@ -263,6 +442,10 @@ func (h *APIHandler) createRequest(tool types.APITool, requestURL string, params
 				}
 				body = bytes.NewReader(jsonData)
 				contentType = "application/json"
+			case nil:
+				// An explicit null is sent as such, for APIs where it clears a resource
+				body = strings.NewReader("null")
+				contentType = "application/json"
 			default:
 				body = strings.NewReader(fmt.Sprintf("%v", v))
 				contentType = "text/plain"
@@ -281,14 +464,26 @@ func (h *APIHandler) createRequest(tool types.APITool, requestURL string, params
 		req.Header.Set("Content-Type", contentType)
 	}
 
+	// Ask for the media types the operation responds with, rather than the upstream's default,
+	// which for some APIs is HTML
+	accept := tool.Accept
+	if accept == "" {
+		accept = defaultAccept
+	}
+	req.Header.Set("Accept", accept)
+
 	// Add header parameters
 	for _, param := range tool.Parameters {
 		if param.In == "header" {
-			paramValue, exists := params[param.Name]
+			paramValue, exists := parameterValue(tool, params, param)
 			if exists {
-				req.Header.Set(param.Name, fmt.Sprintf("%v", paramValue))
+				value := fmt.Sprintf("%v", paramValue)
+				if err := validateHeaderValue(param.Name, value); err != nil {
+					return nil, err
+				}
+				req.Header.Set(param.Name, value)
 			} else if param.Required {
-				return nil, fmt.Errorf("required header parameter '%s' not provided", param.Name)
+				return nil, types.ArgumentErrorf("required header parameter '%s' not provided", param.Name)
 			}
 		}
 	}
@@ -296,41 +491,186 @@ func (h *APIHandler) createRequest(tool types.APITool, requestURL string, params
 	return req, nil
 }
 
-// hasBodyParameter checks if the tool has any body parameters (Swagger 2.0 style)
-func hasBodyParameter(tool types.APITool) bool {
-	for _, param := range tool.Parameters {
-		if param.In == "body" {
-			return true
+// parameterValue returns the argument of a path, query or header parameter. A null argument
+// counts as omitted, since these locations cannot carry a null; only bodies keep nulls.
+func parameterValue(tool types.APITool, params map[string]interface{}, param types.OpenAPIParameter) (interface{}, bool) {
+	value := params[tool.ArgumentName(param)]
+	return value, value != nil
+}
+
+// escapePathParam percent-encodes a path parameter value so it always stays within
+// a single path segment. Slashes are escaped by url.PathEscape; dot segments are
+// escaped explicitly since PathEscape leaves '.' untouched and upstream servers
+// would otherwise normalize ".." into a parent directory reference.
+func escapePathParam(value string) string {
+	escaped := url.PathEscape(value)
+	if value == "." || strings.Contains(value, "..") {
+		escaped = strings.ReplaceAll(escaped, ".", "%2E")
+	}
+	return escaped
+}
+
+// escapeSubpath percent-encodes a path parameter value that may span several segments,
+// keeping its slashes. Each segment is escaped like a single-segment value, so dot segments
+// still cannot climb out of the operation's path.
+func escapeSubpath(value string) string {
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = escapePathParam(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// validateHeaderValue rejects header values containing CR or LF characters,
+// which could otherwise be used to inject additional headers upstream
+func validateHeaderValue(name, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return types.ArgumentErrorf("invalid parameter '%s': header values must not contain CR or LF characters", name)
+	}
+	return nil
+}
+
+// setHeaderValues replaces each header with its evaluated values, sending repeated
+// headers once per value
+func setHeaderValues(header http.Header, values map[string][]string) error {
+	for name, headerValues := range values {
+		for _, value := range headerValues {
+			if err := validateHeaderValue(name, value); err != nil {
+				return err
+			}
+		}
+		header.Del(name)
+		for _, value := range headerValues {
+			header.Add(name, value)
 		}
 	}
-	return false
+	return nil
+}
+
+// defaultAccept is the Accept header of operations that declare no response media types
+const defaultAccept = "application/json, */*;q=0.8"
+
+// sendsBody reports whether requests for the tool carry a body
+func sendsBody(tool types.APITool) bool {
+	return tool.RequestBody != nil && (tool.Method == "POST" || tool.Method == "PUT" || tool.Method == "PATCH")
 }
 
-// addAuthHeaders adds authentication headers to the request
-func (h *APIHandler) addAuthHeaders(req *http.Request, requestContext config.RequestContext) {
+// mergeBodyFields returns a copy of params whose body has the configured fields set, replacing
+// fields of the same name from the caller. A missing body becomes an object holding the fields.
+func mergeBodyFields(params map[string]interface{}, fields map[string]interface{}) (map[string]interface{}, error) {
+	if len(fields) == 0 {
+		return params, nil
+	}
+
+	name := types.BodyArgument
+	_, exists := params[name]
+
+	// Round trip through JSON to copy the body, and to decode bodies passed as JSON strings
+	var body interface{} = map[string]interface{}{}
+	if exists {
+		encoded, ok := params[name].(string)
+		if !ok {
+			data, err := json.Marshal(params[name])
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			}
+			encoded = string(data)
+		}
+		if err := types.DecodeJSON([]byte(encoded), &body); err != nil {
+			return nil, fmt.Errorf("cannot add configured body fields: request body is not JSON")
+		}
+	}
+	object, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot add configured body fields: request body is not a JSON object")
+	}
+
+	// Apply fields in order so nested paths are set predictably
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := setBodyField(object, path, fields[path]); err != nil {
+			return nil, err
+		}
+	}
+
+	merged := make(map[string]interface{}, len(params)+1)
+	for key, value := range params {
+		merged[key] = value
+	}
+	merged[name] = object
+	return merged, nil
+}
+
+// setBodyField sets the value at a dot-separated path, creating intermediate objects
+func setBodyField(object map[string]interface{}, path string, value interface{}) error {
+	segments := strings.Split(path, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		next, exists := object[segment]
+		if !exists || next == nil {
+			next = map[string]interface{}{}
+			object[segment] = next
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot set body field %s: %s is not an object", path, segment)
+		}
+		object = child
+	}
+	object[segments[len(segments)-1]] = value
+	return nil
+}
+
+// addAuthHeaders adds authentication headers, or the API key query parameter, to the request
+func (h *APIHandler) addAuthHeaders(req *http.Request, requestContext config.RequestContext) error {
+	// Secrets are resolved per request so rotated *_file secrets take effect without a restart
 	switch h.config.Auth.Type {
 	case "bearer":
-		if h.config.Auth.Token != "" {
-			req.Header.Set("Authorization", "Bearer "+h.config.Auth.Token)
+		token, err := h.config.Auth.TokenValue()
+		if err != nil {
+			return err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
 		}
 	case "basic":
-		if h.config.Auth.Username != "" && h.config.Auth.Password != "" {
-			req.SetBasicAuth(h.config.Auth.Username, h.config.Auth.Password)
+		password, err := h.config.Auth.PasswordValue()
+		if err != nil {
+			return err
+		}
+		if h.config.Auth.Username != "" && password != "" {
+			req.SetBasicAuth(h.config.Auth.Username, password)
 		}
 	case "api_key":
-		if h.config.Auth.APIKey != "" && h.config.Auth.APIKeyName != "" && h.config.Auth.APIKeyIn == "header" {
-			req.Header.Set(h.config.Auth.APIKeyName, h.config.Auth.APIKey)
+		apiKey, err := h.config.Auth.APIKeyValue()
+		if err != nil {
+			return err
+		}
+		if apiKey != "" && h.config.Auth.APIKeyName != "" && h.config.Auth.APIKeyIn == "header" {
+			req.Header.Set(h.config.Auth.APIKeyName, apiKey)
+		}
+		if h.config.Auth.APIKeyIn == "query" {
+			query := req.URL.Query()
+			query.Add(h.config.Auth.APIKeyName, apiKey)
+			req.URL.RawQuery = query.Encode()
 		}
 	}
 
 	// Add custom auth headers (static and dynamic)
-	evaluatedAuthHeaders, err := h.evaluator.EvaluateHeaders(h.config.Auth.Headers, requestContext)
+	evaluatedAuthHeaders, err := h.evaluator.EvaluateHeaderValues(h.config.Auth.Headers, requestContext)
 	if err != nil {
 		// Log error but continue - don't fail the request
 		log.Printf("Warning: failed to evaluate auth headers: %v", err)
 	} else {
-		for name, value := range evaluatedAuthHeaders {
-			req.Header.Set(name, value)
+		for name, values := range evaluatedAuthHeaders {
+			if err := setHeaderValues(req.Header, map[string][]string{name: values}); err != nil {
+				log.Printf("Warning: skipping auth header: %v", err)
+			}
 		}
 	}
+
+	return nil
 }
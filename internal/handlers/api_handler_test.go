@@ -0,0 +1,834 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+	"mcpify/pkg/extension"
+)
+
+func newTestHandler(baseURL string) *APIHandler {
+	return NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL: baseURL,
+		Timeout: 5 * time.Second,
+	})
+}
+
+func TestBuildRequestURL_PathParamEscaping(t *testing.T) {
+	handler := newTestHandler("https://api.example.com")
+	tool := types.APITool{
+		Name:   "get_files_by_name",
+		Method: "GET",
+		Path:   "/files/{name}",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "name", In: "path", Required: true},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"plain value", "report", "https://api.example.com/files/report"},
+		{"numeric value", 42, "https://api.example.com/files/42"},
+		{"value with dot", "report.pdf", "https://api.example.com/files/report.pdf"},
+		{"slash is escaped", "a/b", "https://api.example.com/files/a%2Fb"},
+		{"space is escaped", "my file", "https://api.example.com/files/my%20file"},
+		{"hash is escaped", "a#b", "https://api.example.com/files/a%23b"},
+		{"question mark is escaped", "a?b=c", "https://api.example.com/files/a%3Fb=c"},
+		{"parent traversal", "..", "https://api.example.com/files/%2E%2E"},
+		{"current directory", ".", "https://api.example.com/files/%2E"},
+		{"nested traversal", "../../admin", "https://api.example.com/files/%2E%2E%2F%2E%2E%2Fadmin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := handler.buildRequestURL(tool, map[string]interface{}{"name": tt.value})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandleAPICall_ResponseHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("Link", `<https://api.example.com/items?page=2>; rel="next"`)
+		w.Header().Set("X-Request-Id", "abc")
+		w.Header().Set("Strict-Transport-Security", "max-age=31536000")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	tool := types.APITool{Name: "list_items", Method: "GET", Path: "/items"}
+
+	tests := []struct {
+		name     string
+		allow    []string
+		expected []string
+	}{
+		{"defaults", nil, []string{"Content-Type", "Link", "X-Ratelimit-Remaining"}},
+		{"configured", []string{"x-request-*"}, []string{"X-Request-Id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAPIHandler(&config.OpenAPIConfig{BaseURL: server.URL, Timeout: 5 * time.Second, ResponseHeaders: tt.allow})
+			result, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			headers := result.(map[string]interface{})["headers"].(map[string]string)
+			names := make([]string, 0, len(headers))
+			for name := range headers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected headers %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestBuildRequestURL_OperationBaseURL(t *testing.T) {
+	handler := newTestHandler("https://api.example.com/v1")
+	tests := []struct {
+		name     string
+		baseURL  string
+		expected string
+	}{
+		{"API base URL", "", "https://api.example.com/v1/files"},
+		{"absolute", "https://upload.example.com/", "https://upload.example.com/files"},
+		{"relative", "/v2", "https://api.example.com/v2/files"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := types.APITool{Name: "list_files", Method: "GET", Path: "/files", BaseURL: tt.baseURL}
+			got, err := handler.buildRequestURL(tool, map[string]interface{}{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildRequestURL_SubpathParams(t *testing.T) {
+	handler := newTestHandler("https://api.example.com")
+	tool := types.APITool{
+		Name:   "get_repo_file",
+		Method: "GET",
+		Path:   "/repos/{repo}/contents/{path}",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "repo", In: "path", Required: true},
+			{Name: "path", In: "path", Required: true},
+		},
+		SubpathParams: []string{"path"},
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"slashes are kept", "docs/guide/intro.md", "https://api.example.com/repos/a%2Fb/contents/docs/guide/intro.md"},
+		{"segments are escaped", "my docs/a#b?.md", "https://api.example.com/repos/a%2Fb/contents/my%20docs/a%23b%3F.md"},
+		{"dot segments are escaped", "docs/../../admin", "https://api.example.com/repos/a%2Fb/contents/docs/%2E%2E/%2E%2E/admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := handler.buildRequestURL(tool, map[string]interface{}{"repo": "a/b", "path": tt.value})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildRequestURL_ODataStringKeys(t *testing.T) {
+	handler := newTestHandler("https://erp.example.com/odata")
+	tool := types.APITool{
+		Name:       "get_customers_by_customerid",
+		Method:     "GET",
+		Path:       "/Customers('{CustomerID}')",
+		Parameters: []types.OpenAPIParameter{{Name: "CustomerID", In: "path", Required: true, ODataString: true}},
+	}
+
+	// Quotes in the value are doubled, so it cannot end the literal
+	got, err := handler.buildRequestURL(tool, map[string]interface{}{"CustomerID": "O'Brien"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "https://erp.example.com/odata/Customers('O%27%27Brien')"; got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestBuildRequestURL_CollidingNames(t *testing.T) {
+	handler := newTestHandler("https://api.example.com")
+	tool := types.APITool{
+		Name:   "get_item",
+		Method: "GET",
+		Path:   "/items/{id}",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "id", In: "path", Required: true},
+			{Name: "id", In: "query"},
+		},
+	}
+
+	got, err := handler.buildRequestURL(tool, map[string]interface{}{"path_id": "a", "query_id": "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "https://api.example.com/items/a?id=b" {
+		t.Errorf("expected each id in its own location, got %s", got)
+	}
+
+	if _, err := handler.buildRequestURL(tool, map[string]interface{}{"id": "a"}); err == nil {
+		t.Error("expected an error for the ambiguous id argument")
+	}
+}
+
+func TestCreateRequest_HeaderInjection(t *testing.T) {
+	handler := newTestHandler("https://api.example.com")
+	tool := types.APITool{
+		Name:   "get_items",
+		Method: "GET",
+		Path:   "/items",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "X-Request-Id", In: "header"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"plain value", "abc-123", false},
+		{"carriage return", "abc\rInjected: true", true},
+		{"line feed", "abc\nInjected: true", true},
+		{"crlf", "abc\r\nInjected: true", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := handler.createRequest(tool, "https://api.example.com/items", map[string]interface{}{"X-Request-Id": tt.value})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), "invalid parameter") {
+					t.Errorf("expected invalid parameter error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := req.Header.Get("X-Request-Id"); got != tt.value {
+				t.Errorf("expected header %q, got %q", tt.value, got)
+			}
+		})
+	}
+}
+
+func TestHandleAPICall_RejectsInjectedForwardedHeader(t *testing.T) {
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+		Headers: config.HeadersConfig{
+			{Header: config.HeaderConfig{Name: "X-Tenant", ValueFrom: "request.headers['x-tenant']"}},
+		},
+	})
+	tool := types.APITool{Name: "get_items", Method: "GET", Path: "/items"}
+	requestContext := config.NewRequestContextFromMap(
+		map[string]string{"X-Tenant": "acme\r\nX-Admin: true"},
+		map[string]string{},
+		map[string]string{},
+		"POST", "/mcp",
+	)
+
+	_, err := handler.HandleAPICall(tool, map[string]interface{}{}, requestContext)
+	if err == nil {
+		t.Fatal("expected error for header value containing CRLF")
+	}
+	if !strings.Contains(err.Error(), "CR or LF") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHandleAPICall_ResponseSizeLimit(t *testing.T) {
+	payload := strings.Repeat("x", 2048)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("chunked") == "true" {
+			// Flushing before writing forces chunked encoding without Content-Length
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	tool := types.APITool{
+		Name:   "get_data",
+		Method: "GET",
+		Path:   "/data",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "chunked", In: "query"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		limit   string
+		chunked bool
+		wantErr bool
+	}{
+		{"within limit", "4KB", false, false},
+		{"content length over limit", "1KB", false, true},
+		{"streamed body over limit", "1KB", true, true},
+		{"exactly at limit", "2KB", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAPIHandler(&config.OpenAPIConfig{
+				BaseURL:         server.URL,
+				Timeout:         5 * time.Second,
+				MaxResponseSize: tt.limit,
+			})
+			params := map[string]interface{}{}
+			if tt.chunked {
+				params["chunked"] = "true"
+			}
+
+			result, err := handler.HandleAPICall(tool, params, config.RequestContext{})
+			if tt.wantErr {
+				if !errors.Is(err, ErrResponseTooLarge) {
+					t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			body := result.(map[string]interface{})["body"]
+			if body != payload {
+				t.Errorf("expected full payload, got %d bytes", len(body.(string)))
+			}
+		})
+	}
+}
+
+func TestHandleAPICall_ResponseBudget(t *testing.T) {
+	payload := strings.Repeat("x", 2048)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	tool := types.APITool{Name: "get_data", Method: "GET", Path: "/data", Timeout: 200 * time.Millisecond}
+	handler := NewAPIHandler(&config.OpenAPIConfig{BaseURL: server.URL, Timeout: 5 * time.Second})
+	budget := NewResponseBudget(1024)
+	handler.SetResponseBudget(budget)
+
+	// A response larger than the budget still succeeds on its own and is released afterwards
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if used := budget.InUse(); used != 0 {
+		t.Errorf("expected budget to be released, %d bytes in use", used)
+	}
+
+	// While the budget is spent elsewhere, the call gives up after the tool timeout
+	if err := budget.Acquire(context.Background(), 1024); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); !errors.Is(err, ErrResponseBudgetExhausted) {
+		t.Errorf("expected ErrResponseBudgetExhausted, got %v", err)
+	}
+}
+
+func TestHandleAPICall_Tenants(t *testing.T) {
+	newUpstream := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"instance":%q,"authorization":%q}`, name, r.Header.Get("Authorization"))
+		}))
+	}
+	shared, acme := newUpstream("shared"), newUpstream("acme")
+	defer shared.Close()
+	defer acme.Close()
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL: shared.URL,
+		Timeout: 5 * time.Second,
+		Auth:    config.AuthConfig{Type: "bearer", Token: "shared-token"},
+		Tenants: config.TenantsConfig{
+			From: "claims.tenant",
+			Instances: map[string]config.TenantConfig{
+				"acme": {BaseURL: acme.URL, Auth: &config.AuthConfig{Type: "bearer", Token: "acme-token"}},
+			},
+		},
+	})
+	tool := types.APITool{Name: "get_data", Method: "GET", Path: "/data"}
+
+	tests := []struct {
+		name          string
+		claims        map[string]interface{}
+		instance      string
+		authorization string
+		wantErr       bool
+	}{
+		{"tenant instance", map[string]interface{}{"tenant": "acme"}, "acme", "Bearer acme-token", false},
+		{"no tenant", nil, "shared", "Bearer shared-token", false},
+		{"unknown tenant", map[string]interface{}{"tenant": "initech"}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{Claims: tt.claims})
+			if tt.wantErr {
+				if !errors.Is(err, config.ErrUnknownTenant) {
+					t.Fatalf("expected ErrUnknownTenant, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			body := result.(map[string]interface{})["body"].(map[string]interface{})
+			if body["instance"] != tt.instance || body["authorization"] != tt.authorization {
+				t.Errorf("expected %s instance with %q, got %+v", tt.instance, tt.authorization, body)
+			}
+		})
+	}
+}
+
+// testExtension signs upstream requests, injects an argument and rewrites responses
+type testExtension struct {
+	extension.Base
+	calls []string
+}
+
+func (e *testExtension) OnToolCall(ctx context.Context, call *extension.ToolCall) error {
+	e.calls = append(e.calls, "tool:"+call.Tool)
+	if call.Arguments["forbidden"] != nil {
+		return errors.New("forbidden argument")
+	}
+	call.Arguments["limit"] = 10
+	return nil
+}
+
+func (e *testExtension) OnUpstreamRequest(ctx context.Context, req *http.Request) error {
+	e.calls = append(e.calls, "request:"+extension.ToolFromContext(ctx)+req.URL.Path)
+	req.Header.Set("X-Signature", "signed")
+	return nil
+}
+
+func (e *testExtension) OnUpstreamResponse(ctx context.Context, resp *http.Response) error {
+	e.calls = append(e.calls, fmt.Sprintf("response:%d", resp.StatusCode))
+	resp.Body = io.NopCloser(strings.NewReader(`{"rewritten":true}`))
+	return nil
+}
+
+func TestHandleAPICall_Extensions(t *testing.T) {
+	var signature, limit string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Signature")
+		limit = r.URL.Query().Get("limit")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	ext := &testExtension{}
+	handler := newTestHandler(server.URL)
+	handler.SetExtensions(extension.Chain{ext})
+	tool := types.APITool{
+		Name:       "list_items",
+		Method:     "GET",
+		Path:       "/items",
+		Parameters: []types.OpenAPIParameter{{Name: "limit", In: "query"}},
+	}
+
+	result, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if signature != "signed" || limit != "10" {
+		t.Errorf("expected signed request with injected limit, got signature %q and limit %q", signature, limit)
+	}
+	body := result.(map[string]interface{})["body"].(map[string]interface{})
+	if body["rewritten"] != true {
+		t.Errorf("expected rewritten response body, got %+v", body)
+	}
+	if strings.Join(ext.calls, ",") != "tool:list_items,request:list_items/items,response:200" {
+		t.Errorf("unexpected hook order: %v", ext.calls)
+	}
+
+	// A hook error aborts the call before anything is sent upstream
+	signature = ""
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{"forbidden": true}, config.RequestContext{}); err == nil {
+		t.Error("expected the extension to reject the call")
+	}
+	if signature != "" {
+		t.Error("expected no upstream request after a rejected call")
+	}
+}
+
+func TestHandleAPICall_TokenFile(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("mounted-token\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+		Auth:    config.AuthConfig{Type: "bearer", TokenFile: tokenFile},
+	})
+	tool := types.APITool{Name: "get_items", Method: "GET", Path: "/items"}
+
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if received != "Bearer mounted-token" {
+		t.Errorf("Expected token from file, got %q", received)
+	}
+
+	// A secret file that disappears fails the call instead of sending it unauthenticated
+	if err := os.Remove(tokenFile); err != nil {
+		t.Fatalf("Failed to remove token file: %v", err)
+	}
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err == nil {
+		t.Error("Expected error when token file is missing")
+	}
+}
+
+func TestHandleAPICall_ToolOverrides(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"scope":"` + r.Header.Get("X-Scope") + `"}`))
+	}))
+	defer server.Close()
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+		Headers: config.HeadersConfig{{Header: config.HeaderConfig{Name: "X-Scope", Value: "api"}}},
+	})
+
+	tool := types.APITool{
+		Name:    "get_items",
+		Method:  "GET",
+		Path:    "/items",
+		Headers: config.HeadersConfig{{Header: config.HeaderConfig{Name: "X-Scope", Value: "tool"}}},
+	}
+	result, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	response, _ := result.(map[string]interface{})
+	if body, ok := response["body"].(map[string]interface{}); !ok || body["scope"] != "tool" {
+		t.Errorf("Expected tool header to take precedence, got %v", result)
+	}
+
+	slow := types.APITool{Name: "get_slow", Method: "GET", Path: "/slow", Timeout: 50 * time.Millisecond}
+	if _, err := handler.HandleAPICall(slow, map[string]interface{}{}, config.RequestContext{}); err == nil {
+		t.Error("Expected per-tool timeout to abort the slow request")
+	}
+}
+
+func TestHandleAPICall_HeadersFromParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"tenant":"` + r.Header.Get("X-Tenant") + `","query":"` + r.URL.RawQuery + `"}`))
+	}))
+	defer server.Close()
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+		Headers: config.HeadersConfig{{Header: config.HeaderConfig{Name: "X-Tenant", ValueFrom: "params['tenant_id']"}}},
+	})
+
+	tool := types.APITool{
+		Name:   "get_items",
+		Method: "GET",
+		Path:   "/items",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "tenant_id", In: "query", Schema: map[string]interface{}{"type": "string"}},
+		},
+	}
+	result, err := handler.HandleAPICall(tool, map[string]interface{}{"tenant_id": "acme"}, config.RequestContext{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	response, _ := result.(map[string]interface{})
+	body, _ := response["body"].(map[string]interface{})
+	if body["tenant"] != "acme" || body["query"] != "tenant_id=acme" {
+		t.Errorf("Expected tenant_id as header and query parameter, got %v", body)
+	}
+}
+
+func TestBuildRequest_MultiValueHeaders(t *testing.T) {
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL: "https://api.example.com",
+		Headers: config.HeadersConfig{
+			{Header: config.HeaderConfig{Name: "Accept", Values: []string{"application/json", "text/plain"}}},
+			{Header: config.HeaderConfig{Name: "Cookie", ValueFrom: "request.headers['cookie']"}},
+		},
+	})
+
+	requestContext := config.NewRequestContextFromHTTP(
+		http.Header{"Cookie": {"session=abc", "theme=dark"}}, nil, nil, "POST", "/mcp")
+	req, err := handler.BuildRequest(types.APITool{Name: "get_items", Method: "GET", Path: "/items"}, map[string]interface{}{}, requestContext)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := req.Header.Values("Accept"); len(got) != 2 || got[0] != "application/json" || got[1] != "text/plain" {
+		t.Errorf("Expected both Accept values, got %v", got)
+	}
+	if got := req.Header.Values("Cookie"); len(got) != 2 || got[0] != "session=abc" || got[1] != "theme=dark" {
+		t.Errorf("Expected both forwarded Cookie values, got %v", got)
+	}
+}
+
+func TestBuildRequest_ConfiguredQuery(t *testing.T) {
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL: "https://api.example.com",
+		Query: config.QueryConfig{
+			{Param: config.QueryParamConfig{Name: "org", ValueFrom: "request.headers['x-org']"}},
+			{Param: config.QueryParamConfig{Name: "format", Value: "json"}},
+		},
+	})
+	tool := types.APITool{
+		Name:   "get_items",
+		Method: "GET",
+		Path:   "/items",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "limit", In: "query"},
+			{Name: "format", In: "query"},
+		},
+	}
+	requestContext := config.NewRequestContextFromMap(map[string]string{"X-Org": "acme"}, nil, nil, "POST", "/mcp")
+
+	req, err := handler.BuildRequest(tool, map[string]interface{}{"limit": 10, "format": "xml"}, requestContext)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := req.URL.RawQuery; got != "format=json&limit=10&org=acme" {
+		t.Errorf("Expected configured parameters alongside the tool arguments, got %s", got)
+	}
+}
+
+func TestBuildRequest_ConfiguredBodyFields(t *testing.T) {
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL: "https://api.example.com",
+		Body: config.BodyConfig{
+			{Field: config.BodyFieldConfig{Name: "caller.id", ValueFrom: "request.claims.sub"}},
+			{Field: config.BodyFieldConfig{Name: "source", Value: "mcpify"}},
+		},
+	})
+	tool := types.APITool{Name: "create_item", Method: "POST", Path: "/items", RequestBody: &types.OpenAPIRequestBody{}}
+	requestContext := config.RequestContext{Claims: map[string]interface{}{"sub": "user-1"}}
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		expected string
+		wantErr  bool
+	}{
+		{"object body", map[string]interface{}{"body": map[string]interface{}{"name": "x", "source": "agent"}}, `{"caller":{"id":"user-1"},"name":"x","source":"mcpify"}`, false},
+		{"JSON string body", map[string]interface{}{"body": `{"name": "x"}`}, `{"caller":{"id":"user-1"},"name":"x","source":"mcpify"}`, false},
+		{"missing body", map[string]interface{}{}, `{"caller":{"id":"user-1"},"source":"mcpify"}`, false},
+		{"array body", map[string]interface{}{"body": []interface{}{"x"}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := handler.BuildRequest(tool, tt.params, requestContext)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			body, _ := io.ReadAll(req.Body)
+			if string(body) != tt.expected {
+				t.Errorf("expected body %s, got %s", tt.expected, body)
+			}
+		})
+	}
+}
+
+func TestBuildRequest_FormBody(t *testing.T) {
+	handler := newTestHandler("https://auth.example.com")
+	form := map[string]interface{}{"application/x-www-form-urlencoded": map[string]interface{}{}}
+	tool := types.APITool{Name: "create_token", Method: "POST", Path: "/oauth/token", RequestBody: &types.OpenAPIRequestBody{Content: form}}
+
+	tests := []struct {
+		name        string
+		content     map[string]interface{}
+		body        interface{}
+		expected    string
+		contentType string
+		wantErr     bool
+	}{
+		{"object", form, map[string]interface{}{
+			"grant_type": "client_credentials",
+			"scope":      []interface{}{"read", "write"},
+			"ttl":        float64(3600),
+			"claims":     map[string]interface{}{"tenant": "acme"},
+		}, "claims=%7B%22tenant%22%3A%22acme%22%7D&grant_type=client_credentials&scope=read&scope=write&ttl=3600", formMediaType, false},
+		{"JSON string", form, `{"grant_type": "password", "username": "a b"}`, "grant_type=password&username=a+b", formMediaType, false},
+		{"encoded string", form, "grant_type=refresh_token&refresh_token=x", "grant_type=refresh_token&refresh_token=x", formMediaType, false},
+		{"array", form, []interface{}{"x"}, "", "", true},
+		{"JSON preferred", map[string]interface{}{
+			"application/json":                  map[string]interface{}{},
+			"application/x-www-form-urlencoded": map[string]interface{}{},
+		}, map[string]interface{}{"grant_type": "password"}, `{"grant_type":"password"}`, "application/json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool.RequestBody.Content = tt.content
+			req, err := handler.BuildRequest(tool, map[string]interface{}{"body": tt.body}, config.RequestContext{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			body, _ := io.ReadAll(req.Body)
+			if string(body) != tt.expected {
+				t.Errorf("expected body %s, got %s", tt.expected, body)
+			}
+			if got := req.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("expected Content-Type %s, got %s", tt.contentType, got)
+			}
+		})
+	}
+}
+
+func TestHandleAPICall_NumberPrecision(t *testing.T) {
+	var received string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		received = string(data)
+		_, _ = w.Write([]byte(`{"id": 1453932123456789012, "parent_id": 9007199254740993, "score": 0.1}`))
+	}))
+	defer upstream.Close()
+
+	tool := types.APITool{Name: "create_tweet", Method: "POST", Path: "/tweets", RequestBody: &types.OpenAPIRequestBody{}}
+	params := map[string]interface{}{"body": `{"reply_to": 1453932123456789011}`}
+	result, err := newTestHandler(upstream.URL).HandleAPICall(tool, params, config.RequestContext{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if received != `{"reply_to":1453932123456789011}` {
+		t.Errorf("Expected the request body to keep its precision, got %s", received)
+	}
+
+	body, _ := json.Marshal(result.(map[string]interface{})["body"])
+	if string(body) != `{"id":1453932123456789012,"parent_id":9007199254740993,"score":0.1}` {
+		t.Errorf("Expected the response to keep its precision, got %s", body)
+	}
+}
+
+func TestBuildRequest_NullArguments(t *testing.T) {
+	handler := newTestHandler("https://api.example.com")
+	tool := types.APITool{
+		Name:   "patch_users_by_id",
+		Method: "PATCH",
+		Path:   "/users/{id}",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "id", In: "path", Required: true},
+			{Name: "notify", In: "query"},
+			{Name: "X-Reason", In: "header"},
+		},
+		RequestBody: &types.OpenAPIRequestBody{},
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		query  string
+		body   string
+	}{
+		{"null fields are kept", map[string]interface{}{"id": 1, "body": map[string]interface{}{"nickname": nil, "name": "Ada"}}, "", `{"name":"Ada","nickname":null}`},
+		{"null body is sent", map[string]interface{}{"id": 1, "body": nil}, "", "null"},
+		{"omitted body is not sent", map[string]interface{}{"id": 1}, "", ""},
+		{"null parameters are omitted", map[string]interface{}{"id": 1, "notify": nil, "X-Reason": nil, "body": map[string]interface{}{}}, "", "{}"},
+		{"set parameters are sent", map[string]interface{}{"id": 1, "notify": false, "body": map[string]interface{}{}}, "notify=false", "{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := handler.BuildRequest(tool, tt.params, config.RequestContext{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if req.URL.RawQuery != tt.query {
+				t.Errorf("expected query %q, got %q", tt.query, req.URL.RawQuery)
+			}
+			if _, exists := req.Header["X-Reason"]; exists {
+				t.Errorf("expected no X-Reason header for a null argument")
+			}
+			body := ""
+			if req.Body != nil {
+				data, _ := io.ReadAll(req.Body)
+				body = string(data)
+			}
+			if body != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, body)
+			}
+		})
+	}
+
+	if _, err := handler.BuildRequest(tool, map[string]interface{}{"id": nil}, config.RequestContext{}); err == nil {
+		t.Error("expected a null required path parameter to be rejected")
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrResponseBudgetExhausted is returned when a tool call times out waiting for room in the
+// shared response memory budget
+var ErrResponseBudgetExhausted = errors.New("response memory budget exhausted")
+
+// ResponseBudget limits the upstream response bytes buffered by concurrent tool calls. Each call
+// reserves the size of its response before reading it and waits while the budget is spent.
+type ResponseBudget struct {
+	mu       sync.Mutex
+	capacity int64
+	used     int64
+	released chan struct{} // closed and replaced whenever bytes are released
+}
+
+// NewResponseBudget creates a budget allowing capacity bytes of responses in flight
+func NewResponseBudget(capacity int64) *ResponseBudget {
+	return &ResponseBudget{
+		capacity: capacity,
+		released: make(chan struct{}),
+	}
+}
+
+// Acquire reserves n bytes, waiting until they are available or ctx is done. A reservation
+// larger than the whole budget is reduced to it, so the call runs once nothing else is in flight.
+func (b *ResponseBudget) Acquire(ctx context.Context, n int64) error {
+	n = b.clamp(n)
+	for {
+		b.mu.Lock()
+		if b.used+n <= b.capacity {
+			b.used += n
+			b.mu.Unlock()
+			return nil
+		}
+		released := b.released
+		b.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return fmt.Errorf("%w: waited for %d bytes: %v", ErrResponseBudgetExhausted, n, ctx.Err())
+		}
+	}
+}
+
+// Release returns n bytes reserved with Acquire to the budget
+func (b *ResponseBudget) Release(n int64) {
+	n = b.clamp(n)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= n
+	close(b.released)
+	b.released = make(chan struct{})
+}
+
+// InUse returns the number of bytes currently reserved
+func (b *ResponseBudget) InUse() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// clamp limits a reservation to the capacity of the budget
+func (b *ResponseBudget) clamp(n int64) int64 {
+	if n > b.capacity {
+		return b.capacity
+	}
+	return n
+}
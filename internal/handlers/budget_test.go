@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResponseBudget_AcquireWaitsForRelease(t *testing.T) {
+	budget := NewResponseBudget(100)
+	if err := budget.Acquire(context.Background(), 60); err != nil {
+		t.Fatalf("Expected first reservation to succeed, got %v", err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		acquired <- budget.Acquire(context.Background(), 60)
+	}()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("Expected second reservation to wait, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	budget.Release(60)
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("Expected second reservation to succeed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected second reservation after release")
+	}
+	if used := budget.InUse(); used != 60 {
+		t.Errorf("Expected 60 bytes in use, got %d", used)
+	}
+}
+
+func TestResponseBudget_Timeout(t *testing.T) {
+	budget := NewResponseBudget(100)
+	if err := budget.Acquire(context.Background(), 100); err != nil {
+		t.Fatalf("Expected reservation to succeed, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := budget.Acquire(ctx, 1); !errors.Is(err, ErrResponseBudgetExhausted) {
+		t.Errorf("Expected ErrResponseBudgetExhausted, got %v", err)
+	}
+}
+
+func TestResponseBudget_OversizedReservation(t *testing.T) {
+	budget := NewResponseBudget(100)
+	if err := budget.Acquire(context.Background(), 500); err != nil {
+		t.Fatalf("Expected oversized reservation to take the whole budget, got %v", err)
+	}
+	if used := budget.InUse(); used != 100 {
+		t.Errorf("Expected 100 bytes in use, got %d", used)
+	}
+	budget.Release(500)
+	if used := budget.InUse(); used != 0 {
+		t.Errorf("Expected budget to be empty after release, got %d", used)
+	}
+}
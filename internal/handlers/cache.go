@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCachedResponse is the largest response body kept by the caching stage; larger
+	// responses are returned without being cached
+	maxCachedResponse = 1 << 20
+	// maxCacheEntries bounds the responses kept per API; once reached, new responses are not
+	// cached until entries expire
+	maxCacheEntries = 1000
+)
+
+// cachedResponse is a response kept by the caching stage until it expires
+type cachedResponse struct {
+	status  string
+	code    int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// responseCache keeps GET responses of the tools with a cache_ttl
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+	now     func() time.Time
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[string]cachedResponse), now: time.Now}
+}
+
+// get returns a copy of the live response cached under key
+func (c *responseCache) get(key string, req *http.Request) (*http.Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return &http.Response{
+		Status:        entry.status,
+		StatusCode:    entry.code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(entry.body)),
+		ContentLength: int64(len(entry.body)),
+		Request:       req,
+	}, true
+}
+
+// put keeps a response under key for ttl, dropping expired entries when the cache is full
+func (c *responseCache) put(key string, entry cachedResponse, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= maxCacheEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			return
+		}
+	}
+	entry.expires = now.Add(ttl)
+	c.entries[key] = entry
+}
+
+// cacheKey identifies a request by its URL and every header it is sent with, so callers
+// whose credentials or forwarded headers differ never share a response
+func cacheKey(req *http.Request) string {
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, req.Method+" "+req.URL.String()+"\n")
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = io.WriteString(hash, name+": "+strings.Join(req.Header[name], ", ")+"\n")
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// cachingStage answers GET calls of tools with a cache_ttl from the responses of earlier
+// identical requests. Only 200 responses are kept, unless the upstream marks them no-store,
+// and only while they are below maxCachedResponse.
+func (h *APIHandler) cachingStage(next Handler) Handler {
+	return func(call *Call) (*http.Response, error) {
+		if call.Tool.CacheTTL <= 0 || call.Request.Method != http.MethodGet {
+			return next(call)
+		}
+		key := cacheKey(call.Request)
+		if resp, ok := h.cache.get(key, call.Request); ok {
+			if h.debug(call) {
+				log.Printf("DEBUG: Tool %s answered from the response cache", call.Tool.Name)
+			}
+			return resp, nil
+		}
+
+		resp, err := next(call)
+		if err != nil || resp.StatusCode != http.StatusOK ||
+			strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store") {
+			return resp, err
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedResponse+1))
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+		if len(body) > maxCachedResponse {
+			// Too large to keep: hand on the part read followed by the rest of the stream
+			resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+			return resp, nil
+		}
+		_ = resp.Body.Close()
+		h.cache.put(key, cachedResponse{
+			status: resp.Status,
+			code:   resp.StatusCode,
+			header: resp.Header.Clone(),
+			body:   body,
+		}, call.Tool.CacheTTL)
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+		return resp, nil
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+func TestHandleAPICall_Caching(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Query().Get("q") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		if r.URL.Query().Get("q") == "private" {
+			w.Header().Set("Cache-Control", "private, no-store")
+		}
+		_, _ = w.Write([]byte(`{"items": []}`))
+	}))
+	defer server.Close()
+
+	handler := newTestHandler(server.URL)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler.cache.now = func() time.Time { return now }
+	tool := types.APITool{
+		Name:       "list_items",
+		Method:     "GET",
+		Path:       "/items",
+		Parameters: []types.OpenAPIParameter{{Name: "q", In: "query"}},
+		CacheTTL:   time.Minute,
+	}
+	call := func(tool types.APITool, q string) {
+		t.Helper()
+		_, _ = handler.HandleAPICall(tool, map[string]interface{}{"q": q}, config.RequestContext{})
+	}
+	expectRequests := func(expected int32, message string) {
+		t.Helper()
+		if got := atomic.LoadInt32(&requests); got != expected {
+			t.Errorf("%s: expected %d upstream requests, got %d", message, expected, got)
+		}
+	}
+
+	call(tool, "a")
+	call(tool, "a")
+	expectRequests(1, "identical calls")
+
+	call(tool, "b")
+	expectRequests(2, "other arguments")
+
+	call(tool, "missing")
+	call(tool, "missing")
+	expectRequests(4, "error responses")
+
+	call(tool, "private")
+	call(tool, "private")
+	expectRequests(6, "no-store responses")
+
+	skipping := tool
+	skipping.SkipMiddleware = []string{config.MiddlewareCaching}
+	call(skipping, "a")
+	expectRequests(7, "skipped stage")
+
+	uncached := tool
+	uncached.CacheTTL = 0
+	call(uncached, "a")
+	expectRequests(8, "tool without cache_ttl")
+
+	now = now.Add(time.Minute)
+	call(tool, "a")
+	expectRequests(9, "expired response")
+}
+
+func TestCacheKey_Headers(t *testing.T) {
+	first, _ := http.NewRequest(http.MethodGet, "https://api.example.com/items", nil)
+	first.Header.Set("Authorization", "Bearer a")
+	second, _ := http.NewRequest(http.MethodGet, "https://api.example.com/items", nil)
+	second.Header.Set("Authorization", "Bearer b")
+	if cacheKey(first) == cacheKey(second) {
+		t.Error("expected requests with different credentials to have different keys")
+	}
+	second.Header.Set("Authorization", "Bearer a")
+	if cacheKey(first) != cacheKey(second) {
+		t.Error("expected identical requests to share a key")
+	}
+}
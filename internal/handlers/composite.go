@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"fmt"
+
+	"mcpify/internal/config"
+)
+
+// ToolCaller calls a tool by name
+type ToolCaller func(name string, params map[string]interface{}, requestContext config.RequestContext) (interface{}, error)
+
+// NewCompositeHandler returns the handler of a composite tool. Each call runs the steps in
+// order, passing the tool arguments as params and the results of completed steps as steps,
+// and stops at the first step that fails.
+func NewCompositeHandler(composite config.CompositeTool, call ToolCaller) func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+	return func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+		requestContext.Params = params
+		requestContext.Steps = make(map[string]interface{}, len(composite.Steps))
+
+		var result interface{}
+		for _, step := range composite.Steps {
+			arguments, err := step.EvaluateArguments(requestContext)
+			if err != nil {
+				return nil, fmt.Errorf("step %s: %w", step.Name, err)
+			}
+			if result, err = call(step.Tool, arguments, requestContext); err != nil {
+				return nil, fmt.Errorf("step %s (%s): %w", step.Name, step.Tool, err)
+			}
+			requestContext.Steps[step.Name] = result
+		}
+
+		if composite.Output != "" {
+			return composite.EvaluateOutput(requestContext)
+		}
+		return result, nil
+	}
+}
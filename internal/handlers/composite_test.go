@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"mcpify/internal/config"
+)
+
+func TestCompositeHandler(t *testing.T) {
+	composite := config.CompositeTool{Steps: []config.CompositeStep{
+		{Name: "search", Tool: "search_users", Arguments: map[string]string{"q": "params.name"}},
+		{Name: "details", Tool: "get_user", Arguments: map[string]string{"id": "steps.search.body[0].id"}},
+	}}
+
+	var calls []string
+	call := func(name string, params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+		calls = append(calls, name)
+		switch name {
+		case "search_users":
+			if params["q"] != "ada" {
+				t.Errorf("expected the search to receive the name, got %+v", params)
+			}
+			return map[string]interface{}{"body": []interface{}{map[string]interface{}{"id": "u-7"}}}, nil
+		case "get_user":
+			if params["id"] == "u-404" {
+				return nil, errors.New("not found")
+			}
+			return map[string]interface{}{"body": map[string]interface{}{"id": params["id"]}}, nil
+		}
+		return nil, errors.New("unexpected tool")
+	}
+
+	result, err := NewCompositeHandler(composite, call)(map[string]interface{}{"name": "ada"}, config.RequestContext{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body := result.(map[string]interface{})["body"].(map[string]interface{})
+	if body["id"] != "u-7" || strings.Join(calls, ",") != "search_users,get_user" {
+		t.Errorf("expected the details of u-7 after a search, got %+v from %v", result, calls)
+	}
+
+	// A failing step fails the call with the step named
+	composite.Steps[1].Arguments["id"] = "params.id"
+	_, err = NewCompositeHandler(composite, call)(map[string]interface{}{"name": "ada", "id": "u-404"}, config.RequestContext{})
+	if err == nil || !strings.Contains(err.Error(), "step details (get_user): not found") {
+		t.Errorf("expected the failing step in the error, got %v", err)
+	}
+}
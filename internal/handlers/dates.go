@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"mcpify/internal/types"
+)
+
+// dateInputLayouts are the layouts, besides RFC 3339 and epoch timestamps, accepted for date
+// and date-time parameters. Values without a zone are taken as UTC.
+var dateInputLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"20060102",
+}
+
+// epochMillisThreshold separates epoch seconds from epoch milliseconds: as seconds it is in
+// the year 5138, as milliseconds in 1973
+const epochMillisThreshold = 1e11
+
+// normalizeDates returns params with the arguments of date and date-time parameters written
+// in the format the spec declares: 2024-01-31 for dates and RFC 3339 for date-times. Epoch
+// seconds or milliseconds, RFC 3339 and dates with or without a time are accepted; values in
+// none of these forms are sent unchanged for the upstream to judge.
+func normalizeDates(tool types.APITool, params map[string]interface{}) map[string]interface{} {
+	var normalized map[string]interface{}
+	for _, param := range tool.Parameters {
+		name := tool.ArgumentName(param)
+		value, exists := params[name]
+		if !exists || value == nil {
+			continue
+		}
+		format, _ := param.SchemaMap()["format"].(string)
+		if format != "date" && format != "date-time" {
+			continue
+		}
+		t, ok := parseDate(value)
+		if !ok {
+			continue
+		}
+
+		if normalized == nil {
+			normalized = make(map[string]interface{}, len(params))
+			for key, value := range params {
+				normalized[key] = value
+			}
+		}
+		if format == "date" {
+			normalized[name] = t.Format("2006-01-02")
+		} else {
+			normalized[name] = t.Format(time.RFC3339Nano)
+		}
+	}
+	if normalized == nil {
+		return params
+	}
+	return normalized
+}
+
+// parseDate parses a date argument given as an epoch timestamp, in RFC 3339 or in one of the
+// dateInputLayouts. Numbers shorter than nine digits, like 20240131, are not taken as epochs.
+func parseDate(value interface{}) (time.Time, bool) {
+	var text string
+	switch v := value.(type) {
+	case json.Number:
+		text = v.String()
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		text = strconv.Itoa(v)
+	case int64:
+		text = strconv.FormatInt(v, 10)
+	case string:
+		text = strings.TrimSpace(v)
+	default:
+		return time.Time{}, false
+	}
+
+	digits, _, _ := strings.Cut(strings.TrimPrefix(text, "-"), ".")
+	if epoch, err := strconv.ParseFloat(text, 64); err == nil && len(digits) >= 9 {
+		return epochTime(epoch), true
+	}
+	if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+		return t, true
+	}
+	for _, layout := range dateInputLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// epochTime converts epoch seconds, or milliseconds for large values, to a UTC time
+func epochTime(epoch float64) time.Time {
+	if math.Abs(epoch) >= epochMillisThreshold {
+		epoch /= 1000
+	}
+	seconds, fraction := math.Modf(epoch)
+	return time.Unix(int64(seconds), int64(math.Round(fraction*1e9))).UTC()
+}
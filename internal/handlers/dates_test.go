@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"mcpify/internal/types"
+)
+
+func TestNormalizeDates(t *testing.T) {
+	tool := types.APITool{
+		Name:   "list_events",
+		Method: "GET",
+		Path:   "/events/{day}",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "day", In: "path", Schema: map[string]interface{}{"type": "string", "format": "date"}},
+			{Name: "since", In: "query", Schema: map[string]interface{}{"type": "string", "format": "date-time"}},
+			{Name: "name", In: "query", Schema: map[string]interface{}{"type": "string"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		day      interface{}
+		since    interface{}
+		wantDay  interface{}
+		wantFrom interface{}
+	}{
+		{"already formatted", "2024-01-31", "2024-01-31T10:00:00Z", "2024-01-31", "2024-01-31T10:00:00Z"},
+		{"epoch seconds", json.Number("1706695200"), float64(1706695200), "2024-01-31", "2024-01-31T10:00:00Z"},
+		{"epoch milliseconds", "1706695200000", json.Number("1706695200500"), "2024-01-31", "2024-01-31T10:00:00.5Z"},
+		{"date-time to date keeps the offset", "2024-01-31T23:30:00-05:00", "2024-01-31T23:30:00-05:00", "2024-01-31", "2024-01-31T23:30:00-05:00"},
+		{"date to date-time", "20240131", "2024-01-31", "2024-01-31", "2024-01-31T00:00:00Z"},
+		{"local time as UTC", "2024-01-31 10:00", "2024-01-31 10:00:00", "2024-01-31", "2024-01-31T10:00:00Z"},
+		{"unparseable is unchanged", "yesterday", "2024", "yesterday", "2024"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]interface{}{"day": tt.day, "since": tt.since, "name": "2024-01-31T10:00:00+01:00"}
+			normalized := normalizeDates(tool, params)
+			if normalized["day"] != tt.wantDay || normalized["since"] != tt.wantFrom {
+				t.Errorf("Expected %v and %v, got %v and %v", tt.wantDay, tt.wantFrom, normalized["day"], normalized["since"])
+			}
+			if normalized["name"] != "2024-01-31T10:00:00+01:00" {
+				t.Errorf("Expected other arguments unchanged, got %v", normalized["name"])
+			}
+			if params["day"] != tt.day {
+				t.Errorf("Expected the caller's arguments unchanged, got %v", params["day"])
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"mcpify/internal/config"
+)
+
+// requestIDHeaders are the response headers upstream APIs commonly report their request ID
+// in, in order of preference
+var requestIDHeaders = []string{
+	"X-Request-Id",
+	"Request-Id",
+	"X-Correlation-Id",
+	"X-Amzn-Requestid",
+	"X-Amz-Request-Id",
+	"X-Trace-Id",
+	"Cf-Ray",
+}
+
+// sensitiveQueryWords are the words of query parameter names whose values are taken as
+// credentials and left out of diagnostics
+var sensitiveQueryWords = []string{"key", "token", "secret", "password", "signature", "sig", "auth", "credential"}
+
+// responseRequestID returns the request ID of an upstream response, or else the one sent with
+// the request
+func responseRequestID(resp *http.Response) string {
+	for _, name := range requestIDHeaders {
+		if id := resp.Header.Get(name); id != "" {
+			return id
+		}
+	}
+	if resp.Request != nil {
+		for _, name := range requestIDHeaders {
+			if id := resp.Request.Header.Get(name); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// redactURL returns a URL for diagnostics without its user information and with the values of
+// the API key and of credential-like query parameters replaced by config.Redacted
+func (h *APIHandler) redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	redacted := *u
+	redacted.User = nil
+	if redacted.RawQuery == "" {
+		return redacted.String()
+	}
+
+	query := redacted.Query()
+	for name, values := range query {
+		if !h.sensitiveQueryParameter(name) {
+			continue
+		}
+		for i := range values {
+			values[i] = config.Redacted
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+// sensitiveQueryParameter reports whether the query parameter holds the API key or has a name
+// made of a credential word, like access_token or apiKey
+func (h *APIHandler) sensitiveQueryParameter(name string) bool {
+	if h.config.Auth.APIKeyIn == "query" && strings.EqualFold(name, h.config.Auth.APIKeyName) {
+		return true
+	}
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	})
+	for _, word := range words {
+		for _, sensitive := range sensitiveQueryWords {
+			if word == sensitive || strings.HasSuffix(word, sensitive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sensitiveHeader reports whether a header carries credentials: a standard auth header, a
+// name that looks like a credential, the API key header or a header of the auth section
+func (h *APIHandler) sensitiveHeader(name string) bool {
+	if config.IsSensitiveName(name) {
+		return true
+	}
+	auth := h.config.Auth
+	if auth.APIKeyIn == "header" && strings.EqualFold(name, auth.APIKeyName) {
+		return true
+	}
+	for _, item := range auth.Headers {
+		if strings.EqualFold(name, item.Header.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskedHeaders returns a copy of headers for logging, with the values of credential headers
+// replaced by config.Redacted
+func (h *APIHandler) maskedHeaders(headers http.Header) http.Header {
+	masked := make(http.Header, len(headers))
+	for name, values := range headers {
+		if h.sensitiveHeader(name) {
+			values = []string{config.Redacted}
+		}
+		masked[name] = values
+	}
+	return masked
+}
+
+// maskedRequestContext returns a copy of the request context for logging, with the values of
+// the client's credential headers replaced by config.Redacted
+func (h *APIHandler) maskedRequestContext(requestContext config.RequestContext) config.RequestContext {
+	masked := requestContext
+	if requestContext.Headers != nil {
+		masked.Headers = make(map[string]string, len(requestContext.Headers))
+		for name, value := range requestContext.Headers {
+			if h.sensitiveHeader(name) {
+				value = config.Redacted
+			}
+			masked.Headers[name] = value
+		}
+	}
+	if requestContext.HeaderValues != nil {
+		masked.HeaderValues = h.maskedHeaders(requestContext.HeaderValues)
+	}
+	return masked
+}
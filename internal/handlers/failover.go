@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+// failover sends requests to the first of an API's base URLs that is up. A base URL goes down
+// when a request to it gets no response or a gateway error, and comes back once a health check
+// succeeds; health checks run in the background, at most once per interval, while calls are made.
+type failover struct {
+	mu         sync.Mutex
+	baseURLs   []string          // base_url first, then the secondary base URLs in order
+	down       map[int]time.Time // Base URLs that are down, with the time of their last failure or check
+	checking   map[int]bool      // Base URLs with a health check in flight
+	healthPath string
+	interval   time.Duration
+	client     *http.Client
+	debug      bool
+	now        func() time.Time
+}
+
+// newFailover creates the failover of an API, or returns nil when it has no secondary base URLs
+func newFailover(cfg *config.OpenAPIConfig, client *http.Client) *failover {
+	if !cfg.Failover.Enabled() || cfg.BaseURL == "" {
+		return nil
+	}
+	baseURLs := []string{strings.TrimSuffix(cfg.BaseURL, "/")}
+	for _, baseURL := range cfg.Failover.BaseURLs {
+		baseURLs = append(baseURLs, strings.TrimSuffix(baseURL, "/"))
+	}
+	return &failover{
+		baseURLs:   baseURLs,
+		down:       make(map[int]time.Time),
+		checking:   make(map[int]bool),
+		healthPath: cfg.Failover.HealthPath,
+		interval:   cfg.Failover.IntervalOrDefault(),
+		client:     client,
+		debug:      cfg.Debug,
+		now:        time.Now,
+	}
+}
+
+// do sends req to the active base URL in place of base_url, and marks that base URL down when
+// it fails. Requests to another host, such as an operation's own server, are sent unchanged.
+func (f *failover) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	index, baseURL := f.active()
+	requestURL := req.URL.String()
+	if !strings.HasPrefix(requestURL, f.baseURLs[0]+"/") {
+		return client.Do(req)
+	}
+	if index > 0 {
+		target, err := url.Parse(baseURL + strings.TrimPrefix(requestURL, f.baseURLs[0]))
+		if err != nil {
+			return nil, err
+		}
+		// Clone so retries of the call start again from base_url
+		req = req.Clone(req.Context())
+		req.URL = target
+		req.Host = ""
+	}
+
+	resp, err := client.Do(req)
+	// Requests cancelled by the caller, such as the losers of hedged requests, say nothing of
+	// the upstream
+	if req.Context().Err() != nil {
+		return resp, err
+	}
+	if err != nil || isGatewayError(resp.StatusCode) {
+		f.markDown(index)
+	}
+	return resp, err
+}
+
+// active returns the first base URL that is up, or base_url when all are down, and starts the
+// health checks that are due
+func (f *failover) active() (int, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	active := -1
+	for i, baseURL := range f.baseURLs {
+		checked, isDown := f.down[i]
+		if !isDown {
+			if active < 0 {
+				active = i
+			}
+			continue
+		}
+		if !f.checking[i] && f.now().Sub(checked) >= f.interval {
+			f.checking[i] = true
+			go f.check(i, baseURL)
+		}
+	}
+	if active < 0 {
+		active = 0
+	}
+	return active, f.baseURLs[active]
+}
+
+// markDown takes a base URL out of use until a health check of it succeeds
+func (f *failover) markDown(index int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, isDown := f.down[index]; !isDown {
+		log.Printf("WARNING: Upstream %s is down, failing over", f.baseURLs[index])
+	}
+	f.down[index] = f.now()
+}
+
+// check requests the health path of a base URL that is down, and puts it back in use when the
+// response is not a server error
+func (f *failover) check(index int, baseURL string) {
+	healthy := false
+	if resp, err := f.client.Get(baseURL + "/" + strings.TrimPrefix(f.healthPath, "/")); err == nil {
+		_ = resp.Body.Close()
+		healthy = resp.StatusCode < http.StatusInternalServerError
+	} else if f.debug {
+		log.Printf("DEBUG: Health check of %s failed: %v", baseURL, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.checking, index)
+	if healthy {
+		delete(f.down, index)
+		log.Printf("Upstream %s is back up", baseURL)
+	} else {
+		f.down[index] = f.now()
+	}
+}
+
+// isGatewayError reports whether a status means the upstream, rather than the request, failed
+func isGatewayError(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+func TestHandleAPICall_Failover(t *testing.T) {
+	var primaryDown atomic.Bool
+	var primaryCalls, secondaryCalls int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			if primaryDown.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			return
+		}
+		atomic.AddInt32(&primaryCalls, 1)
+		if primaryDown.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&secondaryCalls, 1)
+		if r.URL.Path != "/v1/items" {
+			t.Errorf("expected the path under the secondary base URL, got %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer secondary.Close()
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL:  primary.URL,
+		Timeout:  5 * time.Second,
+		Failover: config.FailoverConfig{BaseURLs: []string{secondary.URL + "/v1"}, HealthPath: "/health", Interval: time.Millisecond},
+	})
+	tool := types.APITool{Name: "list_items", Method: "GET", Path: "/items"}
+
+	// The 503 of the primary is returned and takes it out of use
+	primaryDown.Store(true)
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err == nil {
+		t.Fatal("expected the 503 of the primary")
+	}
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err != nil {
+		t.Fatalf("expected the secondary to answer, got %v", err)
+	}
+	if got, other := atomic.LoadInt32(&primaryCalls), atomic.LoadInt32(&secondaryCalls); got != 1 || other != 1 {
+		t.Fatalf("expected one call to each base URL, got %d and %d", got, other)
+	}
+
+	// Once its health check succeeds the primary is used again
+	primaryDown.Store(false)
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&primaryCalls) == 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected calls to return to the primary")
+		}
+		if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHandleAPICall_FailoverOnRetry(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	primaryURL := primary.URL
+	primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer secondary.Close()
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL:    primaryURL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+		Failover:   config.FailoverConfig{BaseURLs: []string{secondary.URL}},
+	})
+	policy := NewRetryPolicy(config.RetryConfig{})
+	recordWaits(policy)
+	handler.SetRetryPolicy(policy)
+	tool := types.APITool{Name: "list_items", Method: "GET", Path: "/items"}
+
+	// The retry of a request the primary did not answer goes to the secondary
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err != nil {
+		t.Fatalf("expected the retry to fail over, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"mcpify/internal/types"
+)
+
+// formMediaType is the media type of URL-encoded form bodies, as taken by OAuth token endpoints
+const formMediaType = "application/x-www-form-urlencoded"
+
+// sendsForm reports whether the tool's body is sent as a URL-encoded form, which is when the
+// operation accepts forms but not JSON
+func sendsForm(tool types.APITool) bool {
+	mediaType, _, _ := strings.Cut(tool.RequestBody.MediaType(), ";")
+	return strings.TrimSpace(mediaType) == formMediaType
+}
+
+// encodeForm encodes the body argument as a URL-encoded form. Object properties become fields
+// in name order, arrays repeated fields and nested objects JSON text. A string is taken as an
+// object in JSON, or otherwise as an already encoded form.
+func encodeForm(body interface{}) (string, error) {
+	if text, ok := body.(string); ok {
+		var object map[string]interface{}
+		if err := types.DecodeJSON([]byte(text), &object); err != nil {
+			return text, nil
+		}
+		body = object
+	}
+	object, ok := body.(map[string]interface{})
+	if !ok {
+		return "", types.ArgumentErrorf("form request body must be an object, got %T", body)
+	}
+
+	// Encode sorts the fields by name and keeps repeated fields in array order
+	form := url.Values{}
+	for name, field := range object {
+		values, ok := field.([]interface{})
+		if !ok {
+			values = []interface{}{field}
+		}
+		for _, value := range values {
+			text, err := formValue(value)
+			if err != nil {
+				return "", types.ArgumentErrorf("invalid form field '%s': %w", name, err)
+			}
+			form.Add(name, text)
+		}
+	}
+	return form.Encode(), nil
+}
+
+// formValue formats a form field value
+func formValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// hedgeResult is the outcome of one of the requests of a hedged call
+type hedgeResult struct {
+	index int
+	resp  *http.Response
+	err   error
+}
+
+// hedge sends req and, when it has no response within delay, a copy of it, returning the first
+// response to arrive; the other request is cancelled. An error is returned when the first
+// request fails before the delay, or when both fail. Only requests without a body, which can
+// be sent twice, are hedged.
+func hedge(req *http.Request, delay time.Duration, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	send := func() {
+		ctx, cancel := context.WithCancel(req.Context())
+		cancels = append(cancels, cancel)
+		index := len(cancels) - 1
+		go func() {
+			resp, err := do(req.Clone(ctx))
+			results <- hedgeResult{index: index, resp: resp, err: err}
+		}()
+	}
+
+	send()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	var err error
+	for pending := 1; pending > 0; {
+		select {
+		case <-timer.C:
+			send()
+			pending++
+		case result := <-results:
+			pending--
+			if result.err != nil {
+				err = result.err
+				cancels[result.index]()
+				continue
+			}
+
+			for i, cancel := range cancels {
+				if i != result.index {
+					cancel()
+				}
+			}
+			if pending > 0 {
+				go func() {
+					if other := <-results; other.resp != nil {
+						_ = other.resp.Body.Close()
+					}
+				}()
+			}
+			// The request context of the response lives until its body is closed
+			result.resp.Body = &cancelOnClose{ReadCloser: result.resp.Body, cancel: cancels[result.index]}
+			return result.resp, nil
+		}
+	}
+	return nil, err
+}
+
+// cancelOnClose cancels the context of a request when its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request context
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+func TestHandleAPICall_Hedging(t *testing.T) {
+	var requests int32
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			// The first request hangs until the hedged one wins
+			select {
+			case <-r.Context().Done():
+				close(cancelled)
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		_, _ = w.Write([]byte(`{"hedged": true}`))
+	}))
+	defer server.Close()
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{BaseURL: server.URL, Timeout: 10 * time.Second})
+	tool := types.APITool{Name: "get_item", Method: "GET", Path: "/items/1", HedgeDelay: 20 * time.Millisecond}
+
+	start := time.Now()
+	result, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the hedged request to answer, took %s", elapsed)
+	}
+	if body := result.(map[string]interface{})["body"].(map[string]interface{}); body["hedged"] != true {
+		t.Errorf("expected the response of the hedged request, got %+v", body)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Error("expected the slow request to be cancelled")
+	}
+}
+
+func TestHandleAPICall_HedgingOnlyGET(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{BaseURL: server.URL, Timeout: 5 * time.Second})
+	tool := types.APITool{Name: "delete_item", Method: "DELETE", Path: "/items/1", HedgeDelay: time.Millisecond}
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected DELETE not to be hedged, got %d requests", got)
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+// preCall returns the arguments with those set by the tool's pre_call expressions, which read
+// the client's arguments as params. An expression selecting nothing leaves its argument as the
+// client sent it.
+func preCall(tool types.APITool, params map[string]interface{}, requestContext config.RequestContext) (map[string]interface{}, error) {
+	if len(tool.PreCall) == 0 {
+		return params, nil
+	}
+	requestContext.Params = params
+	values, err := config.EvaluateFields(tool.PreCall, requestContext)
+	if err != nil {
+		return nil, fmt.Errorf("pre_call: %w", err)
+	}
+	adjusted := make(map[string]interface{}, len(params)+len(values))
+	for name, value := range params {
+		adjusted[name] = value
+	}
+	for name, value := range values {
+		adjusted[name] = value
+	}
+	return adjusted, nil
+}
+
+// postCall replaces the body of the result with the fields of the tool's post_call
+// expressions, which read the result as response and the arguments as params
+func postCall(tool types.APITool, params map[string]interface{}, result map[string]interface{}, requestContext config.RequestContext) error {
+	if len(tool.PostCall) == 0 {
+		return nil
+	}
+	requestContext.Params = params
+	requestContext.Response = result
+	body, err := config.EvaluateFields(tool.PostCall, requestContext)
+	if err != nil {
+		return fmt.Errorf("post_call: %w", err)
+	}
+	result["body"] = body
+	return nil
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+func TestHandleAPICall_PreAndPostCall(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("X-Total-Count", "42")
+		_, _ = w.Write([]byte(`{"data": {"items": [{"id": 1}], "links": {"self": "/reports"}}}`))
+	}))
+	defer server.Close()
+
+	handler := newTestHandler(server.URL)
+	tool := types.APITool{
+		Name:   "list_reports",
+		Method: "GET",
+		Path:   "/reports",
+		Parameters: []types.OpenAPIParameter{
+			{Name: "from", In: "query"}, {Name: "region", In: "query"}, {Name: "days", In: "query"},
+		},
+		PreCall: map[string]string{
+			"from":   "date(days_ago(params.days))",
+			"region": "lower(params.region)",
+			"days":   "params.missing",
+		},
+		PostCall: map[string]string{
+			"reports": "response.body.data.items",
+			"region":  "params.region",
+			"missing": "response.body.data.next",
+		},
+	}
+
+	result, err := handler.HandleAPICall(tool, map[string]interface{}{"days": "1", "region": "EU"}, config.RequestContext{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Expressions selecting nothing leave the argument as the client sent it
+	if !strings.Contains(query, "region=eu") || !strings.Contains(query, "days=1") || !strings.Contains(query, "from=") {
+		t.Errorf("expected the adjusted arguments, got query %s", query)
+	}
+
+	body := result.(map[string]interface{})["body"].(map[string]interface{})
+	reports, _ := body["reports"].([]interface{})
+	if len(reports) != 1 || body["region"] != "eu" {
+		t.Errorf("expected the reshaped body, got %+v", body)
+	}
+	if _, exists := body["missing"]; exists || body["links"] != nil {
+		t.Errorf("expected only the selected fields, got %+v", body)
+	}
+	if result.(map[string]interface{})["status_code"] != http.StatusOK {
+		t.Errorf("expected the status kept, got %+v", result)
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// maxJSONAPIDepth bounds how deep relationships are resolved from included resources, since
+// resources commonly refer back to each other
+const maxJSONAPIDepth = 3
+
+// addJSONAPIQuery adds a query argument following the JSON:API conventions: objects become
+// name[key] parameters, as in fields[articles]=title, and arrays comma-separated values, as
+// in include=author,comments
+func addJSONAPIQuery(query url.Values, name string, value interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		query.Add(name, jsonAPIQueryValue(value))
+		return
+	}
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		query.Add(name+"["+key+"]", jsonAPIQueryValue(object[key]))
+	}
+}
+
+// jsonAPIQueryValue formats a query value, joining arrays with commas
+func jsonAPIQueryValue(value interface{}) string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return fmt.Sprintf("%v", value)
+	}
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = fmt.Sprintf("%v", item)
+	}
+	return strings.Join(values, ",")
+}
+
+// flattenJSONAPI simplifies a JSON:API document: each resource becomes one object with its id,
+// type and attributes, and relationships are replaced by the included resources they refer
+// to, or by their id and type when not included. The result keeps the document's data, meta
+// and links; bodies that are not JSON:API documents are returned unchanged.
+func flattenJSONAPI(body interface{}) interface{} {
+	document, ok := body.(map[string]interface{})
+	if !ok {
+		return body
+	}
+	data, exists := document["data"]
+	if !exists {
+		return body
+	}
+
+	included := make(map[string]map[string]interface{})
+	if resources, ok := document["included"].([]interface{}); ok {
+		for _, item := range resources {
+			if resource, ok := item.(map[string]interface{}); ok {
+				included[resourceKey(resource)] = resource
+			}
+		}
+	}
+
+	result := map[string]interface{}{"data": flattenLinkage(data, included, 0, true)}
+	for _, member := range []string{"meta", "links"} {
+		if value, exists := document[member]; exists {
+			result[member] = value
+		}
+	}
+	return result
+}
+
+// flattenLinkage flattens primary data or a relationship's resource linkage. Primary data
+// holds full resources; linkage holds identifiers resolved against the included resources.
+func flattenLinkage(data interface{}, included map[string]map[string]interface{}, depth int, primary bool) interface{} {
+	switch v := data.(type) {
+	case []interface{}:
+		flattened := make([]interface{}, len(v))
+		for i, item := range v {
+			flattened[i] = flattenLinkage(item, included, depth, primary)
+		}
+		return flattened
+	case map[string]interface{}:
+		if primary {
+			return flattenResource(v, included, depth)
+		}
+		if resource, exists := included[resourceKey(v)]; exists && depth < maxJSONAPIDepth {
+			return flattenResource(resource, included, depth)
+		}
+		return map[string]interface{}{"id": v["id"], "type": v["type"]}
+	default:
+		return data
+	}
+}
+
+// flattenResource merges a resource's identifier, attributes and resolved relationships
+func flattenResource(resource map[string]interface{}, included map[string]map[string]interface{}, depth int) map[string]interface{} {
+	flat := map[string]interface{}{"id": resource["id"], "type": resource["type"]}
+	if attributes, ok := resource["attributes"].(map[string]interface{}); ok {
+		for name, value := range attributes {
+			flat[name] = value
+		}
+	}
+	if relationships, ok := resource["relationships"].(map[string]interface{}); ok {
+		for name, relationship := range relationships {
+			object, ok := relationship.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// Relationships given only as links have no data to show
+			if linkage, exists := object["data"]; exists {
+				flat[name] = flattenLinkage(linkage, included, depth+1, false)
+			}
+		}
+	}
+	return flat
+}
+
+// resourceKey identifies a resource by its type and id
+func resourceKey(resource map[string]interface{}) string {
+	return fmt.Sprintf("%v/%v", resource["type"], resource["id"])
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+const testJSONAPIDocument = `{
+  "data": [{
+    "type": "articles", "id": "1",
+    "attributes": {"title": "JSON:API"},
+    "relationships": {
+      "author": {"data": {"type": "people", "id": "9"}},
+      "comments": {"data": [{"type": "comments", "id": "5"}]},
+      "tags": {"links": {"related": "/articles/1/tags"}}
+    }
+  }],
+  "included": [
+    {"type": "people", "id": "9", "attributes": {"name": "Dan"},
+     "relationships": {"articles": {"data": [{"type": "articles", "id": "1"}]}}}
+  ],
+  "meta": {"total": 1}
+}`
+
+func TestHandleAPICall_JSONAPI(t *testing.T) {
+	var query string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		_, _ = w.Write([]byte(testJSONAPIDocument))
+	}))
+	defer upstream.Close()
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{BaseURL: upstream.URL, Timeout: 5 * time.Second, JSONAPI: true})
+	tool := types.APITool{
+		Name:       "get_articles",
+		Method:     "GET",
+		Path:       "/articles",
+		Parameters: []types.OpenAPIParameter{{Name: "include", In: "query"}, {Name: "fields", In: "query"}},
+	}
+	params := map[string]interface{}{
+		"include": []interface{}{"author", "comments"},
+		"fields":  map[string]interface{}{"people": "name", "articles": []interface{}{"title", "author"}},
+	}
+
+	result, err := handler.HandleAPICall(tool, params, config.RequestContext{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if query != "fields%5Barticles%5D=title%2Cauthor&fields%5Bpeople%5D=name&include=author%2Ccomments" {
+		t.Errorf("Expected JSON:API query parameters, got %s", query)
+	}
+
+	var expected interface{}
+	_ = types.DecodeJSON([]byte(`{
+	  "data": [{
+	    "id": "1", "type": "articles", "title": "JSON:API",
+	    "author": {"id": "9", "type": "people", "name": "Dan", "articles": [{"id": "1", "type": "articles"}]},
+	    "comments": [{"id": "5", "type": "comments"}]
+	  }],
+	  "meta": {"total": 1}
+	}`), &expected)
+	if body := result.(map[string]interface{})["body"]; !reflect.DeepEqual(body, expected) {
+		data, _ := json.Marshal(body)
+		t.Errorf("Unexpected flattened body %s", data)
+	}
+}
+
+func TestFlattenJSONAPI_OtherBodies(t *testing.T) {
+	for _, body := range []interface{}{"text", []interface{}{1.0}, map[string]interface{}{"items": []interface{}{}}} {
+		if flattened := flattenJSONAPI(body); !reflect.DeepEqual(flattened, body) {
+			t.Errorf("Expected %v unchanged, got %v", body, flattened)
+		}
+	}
+}
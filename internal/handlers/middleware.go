@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+	"mcpify/pkg/extension"
+)
+
+// Call is a tool call moving through the middleware pipeline of an APIHandler
+type Call struct {
+	Tool           types.APITool
+	Params         map[string]interface{}
+	RequestContext config.RequestContext
+	// Request is the upstream request, created by the request stage and completed by the
+	// stages after it
+	Request *http.Request
+}
+
+// Handler completes a call and returns the upstream response
+type Handler func(call *Call) (*http.Response, error)
+
+// Middleware is a stage of the pipeline. It may act on the call before passing it to next,
+// and on the response next returns.
+type Middleware func(next Handler) Handler
+
+// Stage is a named middleware. Tools skip stages by name with skip_middleware.
+type Stage struct {
+	Name       string
+	Middleware Middleware
+}
+
+// stageRequest creates the upstream request; it cannot be skipped
+const stageRequest = "request"
+
+// requestStages returns the stages that build the upstream request, in order
+func (h *APIHandler) requestStages() []Stage {
+	return []Stage{
+		{stageRequest, h.createRequestStage},
+		{config.MiddlewareAuth, h.authStage},
+		{config.MiddlewareHeaders, h.headersStage},
+	}
+}
+
+// stages returns the full pipeline of a tool call, outermost first:
+// validation, extensions, request, auth, headers, extensions, caching, logging, retry.
+// Extensions appear twice since tool call hooks run before the request is built and upstream
+// hooks after. Caching comes once the request is complete, so its key covers everything sent.
+func (h *APIHandler) stages() []Stage {
+	stages := []Stage{
+		{config.MiddlewareValidation, validationStage},
+		{config.MiddlewareExtensions, h.toolCallHooksStage},
+	}
+	stages = append(stages, h.requestStages()...)
+	return append(stages,
+		Stage{config.MiddlewareExtensions, h.upstreamHooksStage},
+		Stage{config.MiddlewareCaching, h.cachingStage},
+		Stage{config.MiddlewareLogging, h.loggingStage},
+		Stage{config.MiddlewareRetry, h.retryStage},
+	)
+}
+
+// pipeline wraps final in the stages the tool does not skip, so the first stage runs first
+func pipeline(tool types.APITool, stages []Stage, final Handler) Handler {
+	handler := final
+	for i := len(stages) - 1; i >= 0; i-- {
+		if slices.Contains(tool.SkipMiddleware, stages[i].Name) {
+			continue
+		}
+		handler = stages[i].Middleware(handler)
+	}
+	return handler
+}
+
+// validationStage rejects calls missing a required argument before anything is sent upstream
+func validationStage(next Handler) Handler {
+	return func(call *Call) (*http.Response, error) {
+		for _, param := range call.Tool.Parameters {
+			if name := call.Tool.ArgumentName(param); param.Required && call.Params[name] == nil {
+				return nil, types.ArgumentErrorf("required %s parameter '%s' not provided", param.In, name)
+			}
+		}
+		if call.Tool.RequestBody != nil && call.Tool.RequestBody.Required && sendsBody(call.Tool) {
+			if _, exists := call.Params[types.BodyArgument]; !exists {
+				return nil, types.ArgumentErrorf("required request body not provided")
+			}
+		}
+		return next(call)
+	}
+}
+
+// toolCallHooksStage runs the OnToolCall hook of extensions, which may rewrite the arguments
+func (h *APIHandler) toolCallHooksStage(next Handler) Handler {
+	if h.extensions == nil {
+		return next
+	}
+	return func(call *Call) (*http.Response, error) {
+		toolCall := &extension.ToolCall{
+			Tool:      call.Tool.Name,
+			Method:    call.Tool.Method,
+			Path:      call.Tool.Path,
+			Arguments: call.Params,
+			Claims:    call.RequestContext.Claims,
+		}
+		if err := h.extensions.OnToolCall(extension.WithTool(context.Background(), call.Tool.Name), toolCall); err != nil {
+			return nil, fmt.Errorf("extension rejected tool call: %w", err)
+		}
+		call.Params = toolCall.Arguments
+		return next(call)
+	}
+}
+
+// createRequestStage creates the upstream request with the URL, body and configured query
+// parameters. Later stages can read the tool arguments as params, and when conditions the
+// operation.
+func (h *APIHandler) createRequestStage(next Handler) Handler {
+	return func(call *Call) (*http.Response, error) {
+		call.RequestContext.Params = call.Params
+		call.RequestContext.Operation = &config.OperationContext{Method: call.Tool.Method, Path: call.Tool.Path}
+
+		// Write date and date-time arguments in the format the spec declares
+		params := normalizeDates(call.Tool, call.Params)
+
+		// Build the request URL
+		requestURL, err := h.buildRequestURL(call.Tool, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request URL: %w", err)
+		}
+
+		// Merge configured fields into the request body
+		if len(h.config.Body) > 0 && sendsBody(call.Tool) {
+			fields, err := h.evaluator.EvaluateBody(h.config.Body, call.RequestContext)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate body fields: %w", err)
+			}
+			if params, err = mergeBodyFields(params, fields); err != nil {
+				return nil, err
+			}
+		}
+
+		var req *http.Request
+		if call.Tool.SOAP != nil {
+			req, err = createSOAPRequest(call.Tool, requestURL, params)
+		} else {
+			req, err = h.createRequest(call.Tool, requestURL, params)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Add configured query parameters (static and dynamic), which replace tool arguments of the same name
+		if len(h.config.Query) > 0 {
+			configuredQuery, err := h.evaluator.EvaluateQuery(h.config.Query, call.RequestContext)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate query parameters: %w", err)
+			}
+			query := req.URL.Query()
+			for name, values := range configuredQuery {
+				query[name] = values
+			}
+			req.URL.RawQuery = query.Encode()
+		}
+
+		call.Request = req
+		return next(call)
+	}
+}
+
+// authStage applies the API's upstream credentials
+func (h *APIHandler) authStage(next Handler) Handler {
+	return func(call *Call) (*http.Response, error) {
+		if err := h.addAuthHeaders(call.Request, call.RequestContext); err != nil {
+			return nil, fmt.Errorf("%w: %w", types.ErrAuthentication, err)
+		}
+		return next(call)
+	}
+}
+
+// headersStage adds the API-wide headers, then the per-tool headers, which take precedence
+func (h *APIHandler) headersStage(next Handler) Handler {
+	return func(call *Call) (*http.Response, error) {
+		evaluatedHeaders, err := h.evaluator.EvaluateHeaderValues(h.config.Headers, call.RequestContext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate headers: %w", err)
+		}
+		if err := setHeaderValues(call.Request.Header, evaluatedHeaders); err != nil {
+			return nil, err
+		}
+
+		if len(call.Tool.Headers) > 0 {
+			toolHeaders, err := h.evaluator.EvaluateHeaderValues(call.Tool.Headers, call.RequestContext)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate tool headers: %w", err)
+			}
+			if err := setHeaderValues(call.Request.Header, toolHeaders); err != nil {
+				return nil, err
+			}
+		}
+		return next(call)
+	}
+}
+
+// upstreamHooksStage runs the OnUpstreamRequest and OnUpstreamResponse hooks of extensions
+func (h *APIHandler) upstreamHooksStage(next Handler) Handler {
+	if h.extensions == nil {
+		return next
+	}
+	return func(call *Call) (*http.Response, error) {
+		ctx := extension.WithTool(call.Request.Context(), call.Tool.Name)
+		if err := h.extensions.OnUpstreamRequest(ctx, call.Request); err != nil {
+			return nil, fmt.Errorf("extension rejected upstream request: %w", err)
+		}
+		resp, err := next(call)
+		if err != nil {
+			return nil, err
+		}
+		if err := h.extensions.OnUpstreamResponse(ctx, resp); err != nil {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("extension rejected upstream response: %w", err)
+		}
+		return resp, nil
+	}
+}
+
+// loggingStage logs the call, the upstream request and the response status when debug is
+// enabled for the API or the tool. Credentials in headers and in the URL are masked, since
+// debug can be enabled on live systems.
+func (h *APIHandler) loggingStage(next Handler) Handler {
+	return func(call *Call) (*http.Response, error) {
+		if !h.debug(call) {
+			return next(call)
+		}
+		req := call.Request
+		log.Printf("DEBUG: Tool: %s (%s %s)", call.Tool.Name, call.Tool.Method, call.Tool.Path)
+		log.Printf("DEBUG: Tool description: %s", call.Tool.Description)
+		log.Printf("DEBUG: Parameters received: %+v", call.Params)
+		log.Printf("DEBUG: Request context: %+v", h.maskedRequestContext(call.RequestContext))
+		log.Printf("DEBUG: Making %s request to: %s", req.Method, h.redactURL(req.URL))
+		log.Printf("DEBUG: Request headers: %+v", h.maskedHeaders(req.Header))
+		if req.Body != nil {
+			// Read the body to log it, then recreate it
+			bodyBytes, _ := io.ReadAll(req.Body)
+			log.Printf("DEBUG: Request body: %s", string(bodyBytes))
+			req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		}
+
+		resp, err := next(call)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("DEBUG: Response status: %d", resp.StatusCode)
+		log.Printf("DEBUG: Response headers: %+v", h.maskedHeaders(resp.Header))
+		return resp, nil
+	}
+}
+
+// debug reports whether the call is logged: when debug is enabled for the API, by the tools
+// section for the tool, or at runtime for the tool
+func (h *APIHandler) debug(call *Call) bool {
+	return h.config.Debug || call.Tool.Debug || call.RequestContext.Debug
+}
+
+// retryStage retries requests that fail to get a response, and responses with a status and
+// request method the retry policy lists, with the policy's backoff while its retry budget lasts. When the attempts
+// or the budget run out, the last response is returned as is.
+func (h *APIHandler) retryStage(next Handler) Handler {
+	return func(call *Call) (*http.Response, error) {
+		policy := h.retry
+		attempts := policy.attempts(h.config.MaxRetries)
+		var resp *http.Response
+		var err error
+		attempt := 1
+		for ; ; attempt++ {
+			resp, err = next(call)
+			if !policy.retries(call.Request.Method, resp, err) {
+				if h.debug(call) && attempt > 1 {
+					log.Printf("DEBUG: Request succeeded on attempt %d", attempt)
+				}
+				return resp, nil
+			}
+			if attempt >= attempts || !policy.allow() {
+				break
+			}
+
+			wait := policy.backoff(attempt, resp)
+			if h.debug(call) {
+				if err != nil {
+					log.Printf("DEBUG: Request failed (attempt %d/%d): %v, retrying in %s", attempt, attempts, err, wait)
+				} else {
+					log.Printf("DEBUG: Request got status %d (attempt %d/%d), retrying in %s", resp.StatusCode, attempt, attempts, wait)
+				}
+			}
+			if resp != nil {
+				discard(resp)
+			}
+			if waitErr := policy.wait(call.Request.Context(), wait); waitErr != nil {
+				return nil, fmt.Errorf("failed to make request after %d attempts: %w", attempt, waitErr)
+			}
+			// Send the body again on each attempt
+			if call.Request.GetBody != nil {
+				if call.Request.Body, err = call.Request.GetBody(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to make request after %d attempts: %w", attempt, err)
+		}
+		return resp, nil
+	}
+}
+
+// send is the end of the pipeline: it sends the request, with the per-tool timeout when one
+// is configured, to the base URL that is up when the API has secondary base URLs, hedging GET
+// requests of tools with a hedge delay
+func (h *APIHandler) send(call *Call) (*http.Response, error) {
+	client := h.client
+	if call.Tool.Timeout > 0 {
+		toolClient := *h.client
+		toolClient.Timeout = call.Tool.Timeout
+		client = &toolClient
+	}
+	do := client.Do
+	if h.failover != nil {
+		do = func(req *http.Request) (*http.Response, error) {
+			return h.failover.do(client, req)
+		}
+	}
+	var resp *http.Response
+	var err error
+	if call.Tool.HedgeDelay > 0 && call.Request.Method == http.MethodGet {
+		resp, err = hedge(call.Request, call.Tool.HedgeDelay, do)
+	} else {
+		resp, err = do(call.Request)
+	}
+	// The client gives the URL in its errors, which must not carry the credentials it holds
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if parsed, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			urlErr.URL = h.redactURL(parsed)
+		}
+	}
+	return resp, err
+}
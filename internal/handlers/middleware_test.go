@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+func TestPipeline_StageOrderAndSkip(t *testing.T) {
+	var order []string
+	stage := func(name string) Stage {
+		return Stage{name, func(next Handler) Handler {
+			return func(call *Call) (*http.Response, error) {
+				order = append(order, name)
+				return next(call)
+			}
+		}}
+	}
+	stages := []Stage{stage("validation"), stage("auth"), stage("retry")}
+	final := func(call *Call) (*http.Response, error) {
+		order = append(order, "send")
+		return nil, nil
+	}
+
+	_, _ = pipeline(types.APITool{}, stages, final)(&Call{})
+	if strings.Join(order, ",") != "validation,auth,retry,send" {
+		t.Errorf("unexpected stage order: %v", order)
+	}
+
+	order = nil
+	_, _ = pipeline(types.APITool{SkipMiddleware: []string{"auth"}}, stages, final)(&Call{})
+	if strings.Join(order, ",") != "validation,retry,send" {
+		t.Errorf("expected auth to be skipped, got %v", order)
+	}
+}
+
+func TestHandleAPICall_Validation(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	handler := newTestHandler(server.URL)
+	tool := types.APITool{
+		Name:        "create_item",
+		Method:      "POST",
+		Path:        "/items",
+		Parameters:  []types.OpenAPIParameter{{Name: "X-Tenant", In: "header", Required: true}},
+		RequestBody: &types.OpenAPIRequestBody{Required: true},
+	}
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		message string
+	}{
+		{"missing header", map[string]interface{}{"body": map[string]interface{}{}}, "required header parameter 'X-Tenant' not provided"},
+		{"missing body", map[string]interface{}{"X-Tenant": "acme"}, "required request body not provided"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.HandleAPICall(tool, tt.params, config.RequestContext{})
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected %q, got %v", tt.message, err)
+			}
+		})
+	}
+	if requests != 0 {
+		t.Errorf("expected invalid calls not to reach the upstream, got %d requests", requests)
+	}
+
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{"X-Tenant": "acme", "body": map[string]interface{}{}}, config.RequestContext{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHandleAPICall_RetryResendsBody(t *testing.T) {
+	var attempts int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			// Drop the connection so the client sees a transport error
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{BaseURL: server.URL, Timeout: 5 * time.Second, MaxRetries: 1})
+	tool := types.APITool{Name: "create_item", Method: "POST", Path: "/items", RequestBody: &types.OpenAPIRequestBody{}}
+	params := map[string]interface{}{"body": map[string]interface{}{"name": "widget"}}
+
+	if _, err := handler.HandleAPICall(tool, params, config.RequestContext{}); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if len(bodies) != 2 || bodies[0] != bodies[1] || bodies[1] != `{"name":"widget"}` {
+		t.Errorf("expected the body to be sent on both attempts, got %q", bodies)
+	}
+
+	// Tools skipping the retry stage fail on the first error
+	atomic.StoreInt32(&attempts, 0)
+	tool.SkipMiddleware = []string{config.MiddlewareRetry}
+	if _, err := handler.HandleAPICall(tool, params, config.RequestContext{}); err == nil {
+		t.Error("expected the call to fail without retries")
+	}
+}
+
+func TestHandleAPICall_ToolDebug(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+		Auth:    config.AuthConfig{Type: "bearer", Token: "upstream-secret"},
+	})
+
+	var buf bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(previous)
+
+	tests := []struct {
+		name           string
+		debug          bool
+		requestContext config.RequestContext
+		logged         bool
+	}{
+		{"off", false, config.RequestContext{}, false},
+		{"tools section", true, config.RequestContext{}, true},
+		{"runtime", false, config.RequestContext{Debug: true, Headers: map[string]string{"Authorization": "Bearer client-secret"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tool := types.APITool{Name: "get_item", Method: "GET", Path: "/item", Debug: tt.debug}
+			if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, tt.requestContext); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			logged := strings.Contains(buf.String(), "DEBUG: Tool: get_item") && strings.Contains(buf.String(), `DEBUG: Response body: {"id": 1}`)
+			if logged != tt.logged {
+				t.Errorf("expected logged %t, got log %q", tt.logged, buf.String())
+			}
+			if strings.Contains(buf.String(), "upstream-secret") || strings.Contains(buf.String(), "client-secret") {
+				t.Errorf("expected credentials masked, got log %q", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mcpify/internal/types"
+)
+
+// multipartMediaType is the media type of upload operations
+const multipartMediaType = "multipart/form-data"
+
+// sendsMultipart reports whether the tool's body is sent as multipart form data, which is when
+// the operation accepts it and neither JSON nor URL-encoded forms
+func sendsMultipart(tool types.APITool) bool {
+	mediaType, _, _ := strings.Cut(tool.RequestBody.MediaType(), ";")
+	return strings.TrimSpace(mediaType) == multipartMediaType
+}
+
+// quoteEscaper escapes the names in Content-Disposition headers, as mime/multipart does
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// encodeMultipart encodes the body argument as multipart form data and returns it with its
+// Content-Type. Properties the body schema describes as files are sent as file parts, from
+// base64 content or a local path; other properties become text fields, in name order, with
+// arrays as repeated fields.
+func (h *APIHandler) encodeMultipart(tool types.APITool, body interface{}) (*bytes.Buffer, string, error) {
+	if text, ok := body.(string); ok {
+		var object map[string]interface{}
+		if err := types.DecodeJSON([]byte(text), &object); err != nil {
+			return nil, "", types.ArgumentErrorf("multipart request body must be a JSON object: %w", err)
+		}
+		body = object
+	}
+	object, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, "", types.ArgumentErrorf("multipart request body must be an object, got %T", body)
+	}
+	files := fileProperties(tool.RequestBody.Schema())
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, name := range names {
+		values, ok := object[name].([]interface{})
+		if !ok {
+			values = []interface{}{object[name]}
+		}
+		for _, value := range values {
+			if !files[name] {
+				text, err := formValue(value)
+				if err != nil {
+					return nil, "", types.ArgumentErrorf("invalid form field '%s': %w", name, err)
+				}
+				if err := writer.WriteField(name, text); err != nil {
+					return nil, "", err
+				}
+				continue
+			}
+
+			filename, contentType, data, err := h.readUpload(name, value)
+			if err != nil {
+				return nil, "", types.ArgumentErrorf("invalid file '%s': %w", name, err)
+			}
+			header := make(textproto.MIMEHeader)
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+				quoteEscaper.Replace(name), quoteEscaper.Replace(filename)))
+			header.Set("Content-Type", contentType)
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := part.Write(data); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, writer.FormDataContentType(), nil
+}
+
+// fileProperties returns the names of the body properties holding files or arrays of files
+func fileProperties(schema map[string]interface{}) map[string]bool {
+	files := make(map[string]bool)
+	properties, _ := schema["properties"].(map[string]interface{})
+	for name, property := range properties {
+		propertySchema, _ := property.(map[string]interface{})
+		items, _ := propertySchema["items"].(map[string]interface{})
+		if types.IsFileSchema(propertySchema) || (propertySchema["type"] == "array" && types.IsFileSchema(items)) {
+			files[name] = true
+		}
+	}
+	return files
+}
+
+// readUpload returns the file name, media type and content of a file argument: an object with
+// base64 content or, when local files are enabled, a path, or the base64 content alone
+func (h *APIHandler) readUpload(name string, value interface{}) (string, string, []byte, error) {
+	if content, ok := value.(string); ok {
+		value = map[string]interface{}{"content": content}
+	}
+	file, ok := value.(map[string]interface{})
+	if !ok {
+		return "", "", nil, fmt.Errorf("expected an object with content or path, got %T", value)
+	}
+	content, hasContent := file["content"].(string)
+	path, hasPath := file["path"].(string)
+	filename, _ := file["filename"].(string)
+	contentType, _ := file["content_type"].(string)
+
+	var data []byte
+	switch {
+	case hasContent && hasPath:
+		return "", "", nil, fmt.Errorf("pass either content or path, not both")
+	case hasContent:
+		decoded, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("content is not valid base64: %w", err)
+		}
+		data = decoded
+	case hasPath:
+		local, err := h.localUploadPath(path)
+		if err != nil {
+			return "", "", nil, err
+		}
+		if data, err = os.ReadFile(local); err != nil {
+			return "", "", nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		if filename == "" {
+			filename = filepath.Base(local)
+		}
+	default:
+		return "", "", nil, fmt.Errorf("content or path is required")
+	}
+
+	if filename == "" {
+		filename = name
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return filename, contentType, data, nil
+}
+
+// localUploadPath resolves the path of a local file to upload, refusing it unless local files
+// are enabled, and files outside the configured directory, after following symbolic links
+func (h *APIHandler) localUploadPath(path string) (string, error) {
+	uploads := h.config.Uploads
+	if !uploads.LocalFiles {
+		return "", fmt.Errorf("local files are not enabled, pass the file content as base64")
+	}
+	if uploads.Directory == "" {
+		return path, nil
+	}
+
+	root, err := filepath.EvalSymlinks(uploads.Directory)
+	if err != nil {
+		return "", fmt.Errorf("invalid uploads directory: %w", err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the uploads directory", path)
+	}
+	return resolved, nil
+}
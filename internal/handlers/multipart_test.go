@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+// uploadTool is a multipart operation taking a file, a list of attachments and a text field
+var uploadTool = types.APITool{
+	Name:   "upload_document",
+	Method: "POST",
+	Path:   "/documents",
+	RequestBody: &types.OpenAPIRequestBody{Content: map[string]interface{}{
+		"multipart/form-data": map[string]interface{}{"schema": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file":        map[string]interface{}{"type": "string", "format": "binary"},
+				"attachments": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string", "format": "binary"}},
+				"title":       map[string]interface{}{"type": "string"},
+				"tags":        map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			},
+		}},
+	}},
+}
+
+type uploadedPart struct {
+	name, filename, contentType, data string
+}
+
+// readParts builds the request for the arguments and returns its parts
+func readParts(t *testing.T, handler *APIHandler, body interface{}) []uploadedPart {
+	t.Helper()
+	req, err := handler.BuildRequest(uploadTool, map[string]interface{}{"body": body}, config.RequestContext{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("Expected a multipart Content-Type, got %q", req.Header.Get("Content-Type"))
+	}
+
+	var parts []uploadedPart
+	reader := multipart.NewReader(req.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatalf("Failed to read part: %v", err)
+		}
+		data, _ := io.ReadAll(part)
+		contentType := ""
+		if part.FileName() != "" {
+			contentType = part.Header.Get("Content-Type")
+		}
+		parts = append(parts, uploadedPart{part.FormName(), part.FileName(), contentType, string(data)})
+	}
+}
+
+func TestBuildRequest_Multipart(t *testing.T) {
+	handler := newTestHandler("https://api.example.com")
+	encoded := base64.StdEncoding.EncodeToString([]byte("%PDF-1.4"))
+
+	parts := readParts(t, handler, map[string]interface{}{
+		"file":        map[string]interface{}{"content": encoded, "filename": "report.pdf"},
+		"attachments": []interface{}{base64.StdEncoding.EncodeToString([]byte("plain text")), map[string]interface{}{"content": encoded, "content_type": "application/x-custom"}},
+		"title":       "Q3 report",
+		"tags":        []interface{}{"finance", "2025"},
+	})
+	expected := []uploadedPart{
+		{"attachments", "attachments", "text/plain; charset=utf-8", "plain text"},
+		{"attachments", "attachments", "application/x-custom", "%PDF-1.4"},
+		{"file", "report.pdf", "application/pdf", "%PDF-1.4"},
+		{"tags", "", "", "finance"},
+		{"tags", "", "", "2025"},
+		{"title", "", "", "Q3 report"},
+	}
+	if len(parts) != len(expected) {
+		t.Fatalf("Expected %d parts, got %+v", len(expected), parts)
+	}
+	for i := range expected {
+		if parts[i] != expected[i] {
+			t.Errorf("Unexpected part %d: %+v, expected %+v", i, parts[i], expected[i])
+		}
+	}
+
+	for name, body := range map[string]interface{}{
+		"invalid base64":  map[string]interface{}{"file": "not base64!"},
+		"content or path": map[string]interface{}{"file": map[string]interface{}{"filename": "a.txt"}},
+		"path disabled":   map[string]interface{}{"file": map[string]interface{}{"path": "/etc/hostname"}},
+		"not an object":   []interface{}{"x"},
+	} {
+		if _, err := handler.BuildRequest(uploadTool, map[string]interface{}{"body": body}, config.RequestContext{}); err == nil {
+			t.Errorf("Expected an error for %s", name)
+		}
+	}
+}
+
+func TestBuildRequest_MultipartLocalFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link.txt")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{
+		BaseURL: "https://api.example.com",
+		Timeout: 5 * time.Second,
+		Uploads: config.UploadsConfig{LocalFiles: true, Directory: dir},
+	})
+	parts := readParts(t, handler, map[string]interface{}{"file": map[string]interface{}{"path": "notes.txt"}})
+	if len(parts) != 1 || parts[0] != (uploadedPart{"file", "notes.txt", "text/plain; charset=utf-8", "hello"}) {
+		t.Errorf("Expected the local file, got %+v", parts)
+	}
+
+	for _, path := range []string{outside, "../" + filepath.Base(filepath.Dir(outside)) + "/secret.txt", "link.txt"} {
+		_, err := handler.BuildRequest(uploadTool, map[string]interface{}{"body": map[string]interface{}{"file": map[string]interface{}{"path": path}}}, config.RequestContext{})
+		if err == nil || !strings.Contains(err.Error(), "outside the uploads directory") {
+			t.Errorf("Expected %s to be refused, got %v", path, err)
+		}
+	}
+}
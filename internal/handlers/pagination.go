@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+// paginate fetches the pages of a paginated operation until the last page or the page limit,
+// returning the items of every page as the body. truncated reports that more pages were
+// available when the limit was reached.
+func (h *APIHandler) paginate(tool types.APITool, params map[string]interface{}, requestContext config.RequestContext) (map[string]interface{}, error) {
+	pagination := tool.Pagination
+	page := startPage(params, pagination.PageParamName())
+
+	var items []interface{}
+	var last map[string]interface{}
+	pages, truncated := 0, false
+	for pages < pagination.PageLimit() {
+		result, err := h.call(tool, params, requestContext)
+		if err != nil {
+			if pages == 0 {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to fetch page %d: %w", pages+1, err)
+		}
+		pages++
+		last = result
+
+		body := result["body"]
+		headers, _ := result["headers"].(map[string]string)
+		pageItems, err := pagination.PageItems(body, headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read items of page %d: %w", pages, err)
+		}
+		items = append(items, pageItems...)
+
+		next, err := nextPageParams(pagination, params, page, len(pageItems), body, headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read next page of page %d: %w", pages, err)
+		}
+		if next == nil {
+			truncated = false
+			break
+		}
+		params, page, truncated = next, page+1, true
+	}
+
+	if items == nil {
+		items = []interface{}{}
+	}
+	return map[string]interface{}{
+		"status_code": last["status_code"],
+		"headers":     last["headers"],
+		"body":        items,
+		"pages":       pages,
+		"truncated":   truncated,
+	}, nil
+}
+
+// nextPageParams returns the arguments requesting the page after the current one, or nil
+// after the last page
+func nextPageParams(pagination *config.PaginationConfig, params map[string]interface{}, page, itemCount int, body interface{}, headers map[string]string) (map[string]interface{}, error) {
+	var changes map[string]interface{}
+	switch pagination.Style {
+	case config.PaginationPage:
+		// An empty page is past the last one
+		if itemCount == 0 {
+			return nil, nil
+		}
+		changes = map[string]interface{}{pagination.PageParamName(): page + 1}
+	case config.PaginationCursor:
+		cursor, err := pagination.PageCursor(body, headers)
+		if err != nil || cursor == "" {
+			return nil, err
+		}
+		changes = map[string]interface{}{pagination.CursorParamName(): cursor}
+	case config.PaginationLink:
+		// The query parameters of the next link become the arguments of the next request
+		next := nextLink(headers["Link"])
+		if next == "" {
+			return nil, nil
+		}
+		nextURL, err := url.Parse(next)
+		if err != nil {
+			return nil, fmt.Errorf("invalid next link %q: %w", next, err)
+		}
+		changes = make(map[string]interface{})
+		for name, values := range nextURL.Query() {
+			changes[name] = values[0]
+		}
+	}
+
+	next := make(map[string]interface{}, len(params)+len(changes))
+	for name, value := range params {
+		next[name] = value
+	}
+	for name, value := range changes {
+		next[name] = value
+	}
+	// A next page that repeats the request would loop until the page limit
+	if reflect.DeepEqual(next, params) {
+		return nil, nil
+	}
+	return next, nil
+}
+
+// startPage returns the page number of the first request, 1 unless the caller asked for another
+func startPage(params map[string]interface{}, name string) int {
+	switch value := params[name].(type) {
+	case float64:
+		return int(value)
+	case json.Number:
+		if page, err := value.Int64(); err == nil {
+			return int(page)
+		}
+	case int:
+		return value
+	case string:
+		if page, err := strconv.Atoi(value); err == nil {
+			return page
+		}
+	}
+	return 1
+}
+
+// nextLink returns the URL of the rel="next" entry of a Link header, e.g.
+// <https://api.example.com/items?page=2>; rel="next"
+func nextLink(header string) string {
+	for _, entry := range strings.Split(header, ",") {
+		target, parameters, found := strings.Cut(entry, ";")
+		if !found {
+			continue
+		}
+		for _, parameter := range strings.Split(parameters, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(parameter), "=")
+			if strings.EqualFold(name, "rel") && strings.EqualFold(strings.Trim(value, `"`), "next") {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+// newPagedServer serves three pages of two items for /items, selected by ?page=
+func newPagedServer(t *testing.T, respond func(w http.ResponseWriter, r *http.Request, page int)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil {
+			page = 1
+		}
+		w.Header().Set("Content-Type", "application/json")
+		respond(w, r, page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func pageItems(page int) string {
+	if page > 3 {
+		return `[]`
+	}
+	return fmt.Sprintf(`[{"id":%d},{"id":%d}]`, page*2-1, page*2)
+}
+
+func TestHandleAPICall_Pagination(t *testing.T) {
+	tool := types.APITool{
+		Name:       "list_items",
+		Method:     "GET",
+		Path:       "/items",
+		Parameters: []types.OpenAPIParameter{{Name: "page", In: "query"}, {Name: "cursor", In: "query"}},
+	}
+
+	tests := []struct {
+		name       string
+		pagination config.PaginationConfig
+		respond    func(w http.ResponseWriter, r *http.Request, page int)
+		items      int
+		pages      int
+		truncated  bool
+	}{
+		{
+			name:       "page numbers until an empty page",
+			pagination: config.PaginationConfig{Style: config.PaginationPage},
+			respond: func(w http.ResponseWriter, r *http.Request, page int) {
+				_, _ = w.Write([]byte(pageItems(page)))
+			},
+			items: 6, pages: 4,
+		},
+		{
+			name:       "page limit",
+			pagination: config.PaginationConfig{Style: config.PaginationPage, MaxPages: 2},
+			respond: func(w http.ResponseWriter, r *http.Request, page int) {
+				_, _ = w.Write([]byte(pageItems(page)))
+			},
+			items: 4, pages: 2, truncated: true,
+		},
+		{
+			name:       "cursor from the body",
+			pagination: config.PaginationConfig{Style: config.PaginationCursor, Items: "body.data", NextCursor: "body.next"},
+			respond: func(w http.ResponseWriter, r *http.Request, page int) {
+				next := ""
+				if r.URL.Query().Get("cursor") == "" {
+					next = "c2"
+				}
+				_, _ = fmt.Fprintf(w, `{"data":[{"id":1}],"next":%q}`, next)
+			},
+			items: 2, pages: 2,
+		},
+		{
+			name:       "link header",
+			pagination: config.PaginationConfig{Style: config.PaginationLink},
+			respond: func(w http.ResponseWriter, r *http.Request, page int) {
+				if page < 3 {
+					w.Header().Set("Link", fmt.Sprintf(`<http://upstream/items?page=%d>; rel="next", <http://upstream/items?page=3>; rel="last"`, page+1))
+				}
+				_, _ = w.Write([]byte(pageItems(page)))
+			},
+			items: 6, pages: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPagedServer(t, tt.respond)
+			pagination := tt.pagination
+			tool.Pagination = &pagination
+
+			result, err := newTestHandler(server.URL).HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			response := result.(map[string]interface{})
+			if items := response["body"].([]interface{}); len(items) != tt.items {
+				t.Errorf("expected %d items, got %v", tt.items, items)
+			}
+			if response["pages"] != tt.pages || response["truncated"] != tt.truncated {
+				t.Errorf("expected %d pages (truncated %t), got %v (truncated %v)", tt.pages, tt.truncated, response["pages"], response["truncated"])
+			}
+		})
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{`<https://api.example.com/items?page=2>; rel="next"`, "https://api.example.com/items?page=2"},
+		{`<https://api.example.com/items?page=1>; rel="prev", <https://api.example.com/items?page=3>; rel=next`, "https://api.example.com/items?page=3"},
+		{`<https://api.example.com/items?page=9>; rel="last"`, ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := nextLink(tt.header); got != tt.expected {
+			t.Errorf("nextLink(%q) = %q, expected %q", tt.header, got, tt.expected)
+		}
+	}
+}
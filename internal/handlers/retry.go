@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/ratelimit"
+)
+
+// retryBudgetKey is the single key of the retry budget, which all tools share
+const retryBudgetKey = "retries"
+
+// RetryPolicy decides whether and when upstream requests are retried. One policy is shared by
+// every API so its retry budget caps the retries of all tools together.
+type RetryPolicy struct {
+	config config.RetryConfig
+	budget ratelimit.Limiter // nil when retries are not limited
+	wait   func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryPolicy creates a retry policy from the retry section of the configuration
+func NewRetryPolicy(cfg config.RetryConfig) *RetryPolicy {
+	p := &RetryPolicy{config: cfg, wait: sleepContext}
+	if cfg.BudgetPerMinute > 0 {
+		p.budget = ratelimit.NewMemoryLimiter(cfg.BudgetPerMinute)
+	}
+	return p
+}
+
+// attempts returns the attempts per call, falling back to the API's max_retries
+func (p *RetryPolicy) attempts(maxRetries int) int {
+	if p.config.MaxAttempts > 0 {
+		return p.config.MaxAttempts
+	}
+	return maxRetries + 1
+}
+
+// retries reports whether an attempt of a request with the method that ended with resp or err
+// is worth retrying. Responses are only retried for the policy's methods, so a request that
+// may have changed something upstream is not sent again.
+func (p *RetryPolicy) retries(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return p.config.RetriesMethod(method) && p.config.RetriesStatus(resp.StatusCode)
+}
+
+// allow spends a retry from the budget, returning false once the budget is exhausted
+func (p *RetryPolicy) allow() bool {
+	return p.budget == nil || p.budget.Allow(retryBudgetKey)
+}
+
+// backoff returns the wait before the given retry, counting from 1: the initial backoff grown by
+// the multiplier after each retry, or the Retry-After of the response, capped at the maximum
+func (p *RetryPolicy) backoff(retry int, resp *http.Response) time.Duration {
+	limit := p.config.MaxBackoffOrDefault()
+	if resp != nil {
+		if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(after, limit)
+		}
+	}
+
+	wait := float64(p.config.InitialBackoffOrDefault()) * math.Pow(p.config.MultiplierOrDefault(), float64(retry-1))
+	wait = math.Min(wait, float64(limit))
+	if p.config.Jitter {
+		wait = wait/2 + rand.Float64()*wait/2
+	}
+	return time.Duration(wait)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d, returning early with the error of ctx when it is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// discard drains and closes a response that is replaced by a retry, so its connection is reused
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
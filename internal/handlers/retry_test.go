@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+// recordWaits makes the policy record its waits instead of sleeping
+func recordWaits(policy *RetryPolicy) *[]time.Duration {
+	var waits []time.Duration
+	policy.wait = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return &waits
+}
+
+func TestHandleAPICall_RetryStatuses(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&attempts, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte(`{"ok": true}`))
+		}
+	}))
+	defer server.Close()
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{BaseURL: server.URL, Timeout: 5 * time.Second})
+	policy := NewRetryPolicy(config.RetryConfig{MaxAttempts: 3, InitialBackoff: 100 * time.Millisecond, Statuses: []string{"429", "5xx"}})
+	waits := recordWaits(policy)
+	handler.SetRetryPolicy(policy)
+	tool := types.APITool{Name: "get_items", Method: "GET", Path: "/items"}
+
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("expected success on the third attempt, got %d attempts", got)
+	}
+	// The first wait is the initial backoff, the second follows Retry-After
+	if len(*waits) != 2 || (*waits)[0] != 100*time.Millisecond || (*waits)[1] != 2*time.Second {
+		t.Errorf("unexpected waits %v", *waits)
+	}
+}
+
+func TestHandleAPICall_RetryReturnsLastResponse(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	handler := NewAPIHandler(&config.OpenAPIConfig{BaseURL: server.URL, Timeout: 5 * time.Second})
+	policy := NewRetryPolicy(config.RetryConfig{MaxAttempts: 3, Statuses: []string{"502"}})
+	recordWaits(policy)
+	handler.SetRetryPolicy(policy)
+	tool := types.APITool{Name: "get_items", Method: "GET", Path: "/items"}
+
+	_, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{})
+	if err == nil || !strings.Contains(err.Error(), "status 502") {
+		t.Fatalf("expected the last 502 response, got %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+
+	// Statuses the policy does not list are not retried
+	atomic.StoreInt32(&attempts, 0)
+	handler.SetRetryPolicy(NewRetryPolicy(config.RetryConfig{MaxAttempts: 3, Statuses: []string{"503"}}))
+	if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err == nil {
+		t.Fatal("expected the 502 response")
+	}
+	if atomic.LoadInt32(&attempts) != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestHandleAPICall_RetryBudget(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := NewRetryPolicy(config.RetryConfig{MaxAttempts: 5, Statuses: []string{"503"}, BudgetPerMinute: 2})
+	recordWaits(policy)
+	tool := types.APITool{Name: "get_items", Method: "GET", Path: "/items"}
+
+	// Two handlers sharing the policy share its budget of two retries
+	for i := 0; i < 2; i++ {
+		handler := NewAPIHandler(&config.OpenAPIConfig{BaseURL: server.URL, Timeout: 5 * time.Second})
+		handler.SetRetryPolicy(policy)
+		if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err == nil {
+			t.Fatal("expected the 503 response")
+		}
+	}
+	if got := atomic.LoadInt32(&attempts); got != 4 {
+		t.Errorf("expected 2 first attempts and 2 budgeted retries, got %d attempts", got)
+	}
+}
+
+func TestHandleAPICall_RetryMethods(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		methods  []string
+		method   string
+		expected int32
+	}{
+		{"POST is not retried by default", nil, "POST", 1},
+		{"PATCH is not retried by default", nil, "PATCH", 1},
+		{"DELETE is not retried by default", nil, "DELETE", 1},
+		{"GET is retried by default", nil, "GET", 3},
+		{"configured methods are retried", []string{"GET", "post"}, "POST", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&attempts, 0)
+			handler := NewAPIHandler(&config.OpenAPIConfig{BaseURL: server.URL, Timeout: 5 * time.Second})
+			policy := NewRetryPolicy(config.RetryConfig{MaxAttempts: 3, Statuses: []string{"503"}, Methods: tt.methods})
+			recordWaits(policy)
+			handler.SetRetryPolicy(policy)
+			tool := types.APITool{Name: "call_items", Method: tt.method, Path: "/items"}
+
+			if _, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{}); err == nil {
+				t.Fatal("expected the 503 response")
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.expected {
+				t.Errorf("expected %d attempts, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := NewRetryPolicy(config.RetryConfig{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second} {
+		if got := policy.backoff(retry, nil); got != want {
+			t.Errorf("retry %d: expected %s, got %s", retry, want, got)
+		}
+	}
+
+	// Retry-After is capped at the maximum backoff
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"3600"}}}
+	if got := policy.backoff(1, resp); got != 5*time.Second {
+		t.Errorf("expected Retry-After to be capped, got %s", got)
+	}
+
+	policy = NewRetryPolicy(config.RetryConfig{InitialBackoff: time.Second, Jitter: true})
+	for i := 0; i < 20; i++ {
+		if got := policy.backoff(1, nil); got < 500*time.Millisecond || got > time.Second {
+			t.Fatalf("expected a jittered backoff between 500ms and 1s, got %s", got)
+		}
+	}
+}
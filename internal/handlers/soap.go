@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"mcpify/internal/types"
+)
+
+// Envelope namespaces of the SOAP versions
+const (
+	soap11EnvelopeNamespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12EnvelopeNamespace = "http://www.w3.org/2003/05/soap-envelope"
+	xsiNamespace            = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// ErrSOAPFault is returned when a SOAP service answers with a fault
+var ErrSOAPFault = errors.New("SOAP fault")
+
+// createSOAPRequest creates a request posting the body argument in a SOAP envelope. Its
+// properties become child elements of the operation's request element, in the order of its
+// sequence; objects become nested elements and arrays repeated elements.
+func createSOAPRequest(tool types.APITool, requestURL string, params map[string]interface{}) (*http.Request, error) {
+	soap := tool.SOAP
+	fields, err := soapFields(params[types.BodyArgument])
+	if err != nil {
+		return nil, err
+	}
+	envelopeNamespace := soap11EnvelopeNamespace
+	if soap.Version == "1.2" {
+		envelopeNamespace = soap12EnvelopeNamespace
+	}
+
+	var envelope bytes.Buffer
+	envelope.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	fmt.Fprintf(&envelope, `<soap:Envelope xmlns:soap="%s" xmlns:xsi="%s"><soap:Body>`, envelopeNamespace, xsiNamespace)
+	fmt.Fprintf(&envelope, `<%s xmlns="%s">`, soap.Element, escapeXML(soap.Namespace))
+	for _, name := range soap.Parts {
+		value, exists := fields[name]
+		if !exists {
+			continue
+		}
+		// Unqualified elements leave the request element's default namespace
+		attributes := ""
+		if !soap.Qualified {
+			attributes = ` xmlns=""`
+		}
+		if err := writeSOAPElement(&envelope, name, attributes, value); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprintf(&envelope, `</%s></soap:Body></soap:Envelope>`, soap.Element)
+
+	req, err := http.NewRequest(tool.Method, requestURL, bytes.NewReader(envelope.Bytes()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	if soap.Version == "1.2" {
+		contentType := "application/soap+xml; charset=utf-8"
+		if soap.Action != "" {
+			contentType += `; action="` + soap.Action + `"`
+		}
+		req.Header.Set("Content-Type", contentType)
+	} else {
+		req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+		req.Header.Set("SOAPAction", `"`+soap.Action+`"`)
+	}
+	return req, nil
+}
+
+// soapFields returns the properties of the body argument, which may be passed as an object
+// or as a JSON string
+func soapFields(body interface{}) (map[string]interface{}, error) {
+	switch v := body.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return v, nil
+	case string:
+		var fields map[string]interface{}
+		if err := types.DecodeJSON([]byte(v), &fields); err != nil {
+			return nil, fmt.Errorf("SOAP request body must be a JSON object: %w", err)
+		}
+		return fields, nil
+	default:
+		return nil, fmt.Errorf("SOAP request body must be an object, got %T", body)
+	}
+}
+
+// writeSOAPElement writes a value as an element named name
+func writeSOAPElement(buf *bytes.Buffer, name, attributes string, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		fmt.Fprintf(buf, `<%s%s xsi:nil="true"/>`, name, attributes)
+	case []interface{}:
+		for _, item := range v {
+			if err := writeSOAPElement(buf, name, attributes, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		fmt.Fprintf(buf, `<%s%s>`, name, attributes)
+		// The schema order of nested elements is unknown; sorting keeps requests stable
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			// Keys come from the client and must not be able to close or open other elements
+			if !isNCName(key) {
+				return types.ArgumentErrorf("invalid SOAP element name %q in request body", key)
+			}
+			if err := writeSOAPElement(buf, key, "", v[key]); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(buf, `</%s>`, name)
+	default:
+		fmt.Fprintf(buf, `<%s%s>%s</%s>`, name, attributes, escapeXML(soapText(v)), name)
+	}
+	return nil
+}
+
+// isNCName reports whether name is a valid XML element name without a prefix
+func isNCName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// soapText formats a scalar argument as element text
+func soapText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		// Avoid exponents, which xsd:int and xsd:decimal do not accept
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// escapeXML escapes text for element content and attribute values
+func escapeXML(text string) string {
+	var buf strings.Builder
+	_ = xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+// decodeSOAPEnvelope returns the content of the response element in the body of a SOAP
+// envelope, or ErrSOAPFault with the fault's code and reason. Elements become objects keyed
+// by local name, repeated elements arrays, and text-only elements strings.
+func decodeSOAPEnvelope(data []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	inBody := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("invalid SOAP response: no Body element")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid SOAP response: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if !inBody {
+				inBody = t.Name.Local == "Body"
+				continue
+			}
+			value, err := decodeXMLElement(decoder, t)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SOAP response: %w", err)
+			}
+			if t.Name.Local == "Fault" {
+				return nil, soapFault(value)
+			}
+			return value, nil
+		case xml.EndElement:
+			if inBody {
+				// Empty body, as for one-way operations
+				return nil, nil
+			}
+		}
+	}
+}
+
+// soapFault builds the error of a SOAP 1.1 (faultcode, faultstring) or SOAP 1.2 (Code/Value,
+// Reason/Text) fault
+func soapFault(value interface{}) error {
+	fault, _ := value.(map[string]interface{})
+	code := firstText(fault["faultcode"], nested(fault["Code"], "Value"))
+	reason := firstText(fault["faultstring"], nested(fault["Reason"], "Text"))
+	detail := fault["detail"]
+	if detail == nil {
+		detail = fault["Detail"]
+	}
+	if detail != nil {
+		data, _ := json.Marshal(detail)
+		return fmt.Errorf("%w %s: %s (detail: %s)", ErrSOAPFault, code, reason, data)
+	}
+	return fmt.Errorf("%w %s: %s", ErrSOAPFault, code, reason)
+}
+
+// nested returns the child of a decoded element, or nil
+func nested(value interface{}, name string) interface{} {
+	if object, ok := value.(map[string]interface{}); ok {
+		return object[name]
+	}
+	return nil
+}
+
+// firstText returns the first of the values that is a non-empty string
+func firstText(values ...interface{}) string {
+	for _, value := range values {
+		if text, ok := value.(string); ok && text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// decodeXMLElement decodes the content of an element whose start has been read
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	for _, attr := range start.Attr {
+		if attr.Name.Space == xsiNamespace && attr.Name.Local == "nil" && attr.Value == "true" {
+			if err := decoder.Skip(); err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+	}
+
+	var text strings.Builder
+	var children map[string]interface{}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			value, err := decodeXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			if children == nil {
+				children = make(map[string]interface{})
+			}
+			name := t.Name.Local
+			switch existing := children[name].(type) {
+			case nil:
+				if _, exists := children[name]; exists {
+					children[name] = []interface{}{nil, value}
+				} else {
+					children[name] = value
+				}
+			case []interface{}:
+				children[name] = append(existing, value)
+			default:
+				children[name] = []interface{}{existing, value}
+			}
+		case xml.EndElement:
+			if children != nil {
+				return children, nil
+			}
+			return text.String(), nil
+		}
+	}
+}
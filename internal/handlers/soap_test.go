@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+func TestHandleAPICall_SOAP(t *testing.T) {
+	var envelope, action string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		envelope = string(data)
+		action = r.Header.Get("SOAPAction")
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <FindUsersResponse xmlns="http://example.com/users">
+      <User><Name>ada</Name></User>
+      <User><Name>alan</Name></User>
+      <Total>2</Total>
+    </FindUsersResponse>
+  </soap:Body>
+</soap:Envelope>`))
+	}))
+	defer upstream.Close()
+
+	tool := types.APITool{
+		Name:   "find_users",
+		Method: "POST",
+		Path:   "/services/Users.asmx",
+		RequestBody: &types.OpenAPIRequestBody{
+			Required: true,
+			Content:  map[string]interface{}{"text/xml": map[string]interface{}{"schema": map[string]interface{}{"type": "object"}}},
+		},
+		SOAP: &types.SOAPOperation{
+			Version:   "1.1",
+			Action:    "http://example.com/users/FindUsers",
+			Element:   "FindUsers",
+			Namespace: "http://example.com/users",
+			Qualified: true,
+			Parts:     []string{"Name", "Limit", "Addresses"},
+		},
+	}
+	params := map[string]interface{}{"body": map[string]interface{}{
+		"Addresses": []interface{}{map[string]interface{}{"City": "Paris"}, map[string]interface{}{"City": "Oslo"}},
+		"Limit":     float64(10),
+		"Name":      "a & b",
+	}}
+
+	result, err := newTestHandler(upstream.URL).HandleAPICall(tool, params, config.RequestContext{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedEnvelope := `<?xml version="1.0" encoding="utf-8"?>` +
+		`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><soap:Body>` +
+		`<FindUsers xmlns="http://example.com/users"><Name>a &amp; b</Name><Limit>10</Limit>` +
+		`<Addresses><City>Paris</City></Addresses><Addresses><City>Oslo</City></Addresses></FindUsers>` +
+		`</soap:Body></soap:Envelope>`
+	if envelope != expectedEnvelope {
+		t.Errorf("Unexpected envelope:\n%s\nexpected:\n%s", envelope, expectedEnvelope)
+	}
+	if action != `"http://example.com/users/FindUsers"` {
+		t.Errorf("Expected the quoted SOAPAction, got %s", action)
+	}
+
+	body := result.(map[string]interface{})["body"]
+	expectedBody := map[string]interface{}{
+		"User":  []interface{}{map[string]interface{}{"Name": "ada"}, map[string]interface{}{"Name": "alan"}},
+		"Total": "2",
+	}
+	if !reflect.DeepEqual(body, expectedBody) {
+		t.Errorf("Expected the response element content, got %v", body)
+	}
+}
+
+func TestCreateSOAPRequest_ElementNames(t *testing.T) {
+	tool := types.APITool{
+		Name:   "get_user",
+		Method: "POST",
+		SOAP:   &types.SOAPOperation{Version: "1.1", Element: "GetUser", Namespace: "http://example.com/users", Parts: []string{"user"}},
+	}
+	hostile := []string{"id></user><Admin>true</Admin><user", "a b", "1id", "ns:id", ""}
+	for _, key := range hostile {
+		params := map[string]interface{}{"body": map[string]interface{}{"user": map[string]interface{}{key: "1"}}}
+		_, err := createSOAPRequest(tool, "http://example.com/soap", params)
+		var argumentErr *types.ArgumentError
+		if !errors.As(err, &argumentErr) {
+			t.Errorf("Expected key %q to be rejected as an argument error, got %v", key, err)
+		}
+	}
+
+	params := map[string]interface{}{"body": map[string]interface{}{"user": map[string]interface{}{"first-name": "Ada", "_id.v2": "1", "Prénom": "A"}}}
+	if _, err := createSOAPRequest(tool, "http://example.com/soap", params); err != nil {
+		t.Errorf("Expected valid element names to be accepted, got %v", err)
+	}
+}
+
+func TestDecodeSOAPEnvelope_Faults(t *testing.T) {
+	tests := []struct {
+		name     string
+		envelope string
+		message  string
+	}{
+		{
+			name: "SOAP 1.1",
+			envelope: `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><s:Fault>` +
+				`<faultcode>s:Client</faultcode><faultstring>Unknown user</faultstring></s:Fault></s:Body></s:Envelope>`,
+			message: "SOAP fault s:Client: Unknown user",
+		},
+		{
+			name: "SOAP 1.2 with detail",
+			envelope: `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>` +
+				`<env:Code><env:Value>env:Sender</env:Value></env:Code><env:Reason><env:Text>Bad name</env:Text></env:Reason>` +
+				`<env:Detail><Field>Name</Field></env:Detail></env:Fault></env:Body></env:Envelope>`,
+			message: `SOAP fault env:Sender: Bad name (detail: {"Field":"Name"})`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeSOAPEnvelope([]byte(tt.envelope))
+			if !errors.Is(err, ErrSOAPFault) {
+				t.Fatalf("Expected ErrSOAPFault, got %v", err)
+			}
+			if err.Error() != tt.message {
+				t.Errorf("Expected %q, got %q", tt.message, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// maxSummaryNotes caps the notes on elided content, so a summary of deeply nested arrays stays small
+const maxSummaryNotes = 50
+
+// summarizer shrinks a response body by keeping the first items of each array and the start
+// of each long string, noting what was elided by its path in the body
+type summarizer struct {
+	maxItems        int
+	maxStringLength int
+	elided          map[string]string
+	omitted         int
+}
+
+// summarize returns a summary of the body of a response when its JSON encoding is larger than
+// the summarize threshold: the body with arrays and strings shortened, plus the size of the
+// original, its top-level keys and notes on what was elided. It returns nil when summaries are
+// disabled or the body is small enough to return whole.
+func (h *APIHandler) summarize(result map[string]interface{}) map[string]interface{} {
+	settings := h.config.Summarize
+	if !settings.Enabled() {
+		return nil
+	}
+	data, err := json.Marshal(result["body"])
+	if err != nil || int64(len(data)) <= settings.ThresholdBytes() {
+		return nil
+	}
+
+	s := &summarizer{
+		maxItems:        settings.MaxItemsOrDefault(),
+		maxStringLength: settings.MaxStringLengthOrDefault(),
+		elided:          make(map[string]string),
+	}
+	result["body"] = s.prune("body", result["body"])
+
+	summary := map[string]interface{}{
+		"note":           fmt.Sprintf("The response was %d bytes, so it was summarized: arrays show their first %d items and strings their first %d characters", len(data), s.maxItems, s.maxStringLength),
+		"original_bytes": len(data),
+		"elided":         s.elided,
+	}
+	if object, ok := result["body"].(map[string]interface{}); ok {
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		summary["top_level_keys"] = keys
+	}
+	if s.omitted > 0 {
+		summary["more_elided"] = s.omitted
+	}
+	return summary
+}
+
+// prune returns value with arrays cut to maxItems and strings to maxStringLength
+func (s *summarizer) prune(path string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		pruned := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			pruned[key] = s.prune(path+"."+key, item)
+		}
+		return pruned
+	case []interface{}:
+		kept := v
+		if len(v) > s.maxItems {
+			kept = v[:s.maxItems]
+			s.note(path, fmt.Sprintf("showing %d of %d items", s.maxItems, len(v)))
+		}
+		pruned := make([]interface{}, len(kept))
+		for i, item := range kept {
+			pruned[i] = s.prune(fmt.Sprintf("%s[%d]", path, i), item)
+		}
+		return pruned
+	case string:
+		if utf8.RuneCountInString(v) <= s.maxStringLength {
+			return v
+		}
+		runes := []rune(v)
+		s.note(path, fmt.Sprintf("showing %d of %d characters", s.maxStringLength, len(runes)))
+		return string(runes[:s.maxStringLength])
+	default:
+		return value
+	}
+}
+
+// note records what was elided at a path, counting the notes beyond maxSummaryNotes
+func (s *summarizer) note(path, note string) {
+	if len(s.elided) >= maxSummaryNotes {
+		s.omitted++
+		return
+	}
+	s.elided[path] = note
+}
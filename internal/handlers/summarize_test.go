@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+func TestHandleAPICall_Summarize(t *testing.T) {
+	items := make([]string, 100)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"id":%d}`, i)
+	}
+	body := fmt.Sprintf(`{"total":100,"description":%q,"items":[%s]}`, strings.Repeat("x", 50), strings.Join(items, ","))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	tool := types.APITool{Name: "list_items", Method: "GET", Path: "/items"}
+
+	tests := []struct {
+		name       string
+		threshold  string
+		summarized bool
+	}{
+		{"disabled", "", false},
+		{"under threshold", "1MB", false},
+		{"over threshold", "100B", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAPIHandler(&config.OpenAPIConfig{
+				BaseURL:   server.URL,
+				Timeout:   5 * time.Second,
+				Summarize: config.SummarizeConfig{Threshold: tt.threshold, MaxItems: 3, MaxStringLength: 10},
+			})
+			result, err := handler.HandleAPICall(tool, map[string]interface{}{}, config.RequestContext{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			response := result.(map[string]interface{})
+			responseBody := response["body"].(map[string]interface{})
+			summary, ok := response["summarized"].(map[string]interface{})
+			if ok != tt.summarized {
+				t.Fatalf("expected summarized %v, got %v", tt.summarized, response["summarized"])
+			}
+			if !tt.summarized {
+				if len(responseBody["items"].([]interface{})) != 100 {
+					t.Errorf("expected all items, got %d", len(responseBody["items"].([]interface{})))
+				}
+				return
+			}
+
+			if len(responseBody["items"].([]interface{})) != 3 {
+				t.Errorf("expected 3 items, got %d", len(responseBody["items"].([]interface{})))
+			}
+			if responseBody["description"] != "xxxxxxxxxx" {
+				t.Errorf("expected a cut description, got %v", responseBody["description"])
+			}
+			if summary["original_bytes"] != len(body) {
+				t.Errorf("expected original size %d, got %v", len(body), summary["original_bytes"])
+			}
+			if keys := summary["top_level_keys"].([]string); strings.Join(keys, ",") != "description,items,total" {
+				t.Errorf("unexpected top-level keys %v", keys)
+			}
+			elided := summary["elided"].(map[string]string)
+			if elided["body.items"] != "showing 3 of 100 items" || elided["body.description"] != "showing 10 of 50 characters" {
+				t.Errorf("unexpected elided notes %v", elided)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Package httpclient builds the HTTP clients used to reach upstream APIs
+package httpclient
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+// Connection pool defaults used for settings left unset in the configuration
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 32
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultDialTimeout         = 10 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+var (
+	transportsMu sync.Mutex
+	// transports holds one transport per distinct TLS and connection configuration, so the
+	// spec parser and the API handler of an API, and APIs with the same settings, share a pool
+	transports = make(map[string]*http.Transport)
+)
+
+// New creates an HTTP client for upstream requests honoring the OpenAPI timeout, TLS policy,
+// connection and DNS settings. Clients for the same settings share their connection pool.
+func New(cfg *config.OpenAPIConfig) *http.Client {
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: sharedTransport(cfg),
+	}
+}
+
+// sharedTransport returns the transport for the TLS, connection and DNS settings of cfg
+func sharedTransport(cfg *config.OpenAPIConfig) *http.Transport {
+	key := fmt.Sprintf("%+v %+v %+v", cfg.TLS, cfg.Connections, cfg.DNS)
+
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if transport, ok := transports[key]; ok {
+		return transport
+	}
+	transport := newTransport(cfg)
+	transports[key] = transport
+	return transport
+}
+
+// newTransport builds a transport with the pool settings, TLS policy and resolver of cfg
+func newTransport(cfg *config.OpenAPIConfig) *http.Transport {
+	connections := cfg.Connections
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = valueOr(connections.MaxIdleConns, defaultMaxIdleConns)
+	transport.MaxIdleConnsPerHost = valueOr(connections.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost)
+	transport.IdleConnTimeout = valueOr(connections.IdleConnTimeout, defaultIdleConnTimeout)
+	transport.TLSHandshakeTimeout = valueOr(connections.TLSHandshakeTimeout, defaultTLSHandshakeTimeout)
+	dialer := &net.Dialer{
+		Timeout:   valueOr(connections.DialTimeout, defaultDialTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+	transport.DialContext = dialer.DialContext
+	if !cfg.DNS.IsZero() {
+		transport.DialContext = newResolver(cfg.DNS, dialer.Timeout).dialContext(dialer)
+	}
+
+	if !cfg.TLS.IsZero() {
+		tlsConfig, err := cfg.TLS.Build()
+		if err != nil {
+			// Validate rejects invalid TLS settings before clients are built,
+			// so this only happens when validation was skipped
+			log.Printf("Warning: ignoring invalid upstream TLS configuration: %v", err)
+		} else {
+			transport.TLSClientConfig = tlsConfig
+		}
+	}
+
+	return transport
+}
+
+// valueOr returns value, or def when value is zero
+func valueOr[T int | time.Duration](value, def T) T {
+	if value == 0 {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,46 @@
+package httpclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+func TestNew_SharesTransport(t *testing.T) {
+	first := New(&config.OpenAPIConfig{Timeout: time.Second})
+	second := New(&config.OpenAPIConfig{Timeout: 2 * time.Second})
+	if first.Transport != second.Transport {
+		t.Error("Expected clients with the same settings to share a transport")
+	}
+	if second.Timeout != 2*time.Second {
+		t.Errorf("Expected the timeout of the second configuration, got %v", second.Timeout)
+	}
+
+	tuned := New(&config.OpenAPIConfig{Connections: config.ConnectionConfig{MaxIdleConnsPerHost: 64}})
+	if tuned.Transport == first.Transport {
+		t.Error("Expected different connection settings to use another transport")
+	}
+}
+
+func TestNew_ConnectionSettings(t *testing.T) {
+	defaults := New(&config.OpenAPIConfig{}).Transport.(*http.Transport)
+	if defaults.MaxIdleConns != defaultMaxIdleConns || defaults.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost ||
+		defaults.IdleConnTimeout != defaultIdleConnTimeout || defaults.TLSHandshakeTimeout != defaultTLSHandshakeTimeout {
+		t.Errorf("Expected default pool settings, got %d/%d/%v/%v", defaults.MaxIdleConns,
+			defaults.MaxIdleConnsPerHost, defaults.IdleConnTimeout, defaults.TLSHandshakeTimeout)
+	}
+
+	tuned := New(&config.OpenAPIConfig{Connections: config.ConnectionConfig{
+		MaxIdleConns:        10,
+		MaxIdleConnsPerHost: 5,
+		IdleConnTimeout:     time.Minute,
+		TLSHandshakeTimeout: 3 * time.Second,
+	}}).Transport.(*http.Transport)
+	if tuned.MaxIdleConns != 10 || tuned.MaxIdleConnsPerHost != 5 ||
+		tuned.IdleConnTimeout != time.Minute || tuned.TLSHandshakeTimeout != 3*time.Second {
+		t.Errorf("Expected configured pool settings, got %d/%d/%v/%v", tuned.MaxIdleConns,
+			tuned.MaxIdleConnsPerHost, tuned.IdleConnTimeout, tuned.TLSHandshakeTimeout)
+	}
+}
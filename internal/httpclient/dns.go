@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+// cachedAddrs are the addresses of a host and when they stop being reused
+type cachedAddrs struct {
+	addrs   []string
+	expires time.Time
+}
+
+// resolver resolves upstream hosts with the configured DNS servers and keeps the addresses for
+// the cache TTL. Failed lookups are not cached.
+type resolver struct {
+	lookup func(ctx context.Context, host string) ([]string, error)
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedAddrs
+}
+
+// newResolver creates the resolver of a DNS configuration
+func newResolver(cfg config.DNSConfig, dialTimeout time.Duration) *resolver {
+	netResolver := net.DefaultResolver
+	if servers := cfg.ServerAddresses(); len(servers) > 0 {
+		dialer := &net.Dialer{Timeout: dialTimeout}
+		netResolver = &net.Resolver{
+			PreferGo: true,
+			// Query the servers in order, moving on when one cannot be reached
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var err error
+				for _, server := range servers {
+					var conn net.Conn
+					if conn, err = dialer.DialContext(ctx, network, server); err == nil {
+						return conn, nil
+					}
+				}
+				return nil, err
+			},
+		}
+	}
+	return &resolver{
+		lookup: netResolver.LookupHost,
+		ttl:    cfg.CacheTTL,
+		now:    time.Now,
+		cache:  make(map[string]cachedAddrs),
+	}
+}
+
+// resolve returns the addresses of host, from the cache while they are fresh
+func (r *resolver) resolve(ctx context.Context, host string) ([]string, error) {
+	if r.ttl > 0 {
+		r.mu.Lock()
+		cached, ok := r.cache[host]
+		r.mu.Unlock()
+		if ok && r.now().Before(cached.expires) {
+			return cached.addrs, nil
+		}
+	}
+
+	addrs, err := r.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if r.ttl > 0 {
+		r.mu.Lock()
+		r.cache[host] = cachedAddrs{addrs: addrs, expires: r.now().Add(r.ttl)}
+		r.mu.Unlock()
+	}
+	return addrs, nil
+}
+
+// dialContext dials the addresses of the host in turn, after resolving it with the resolver
+func (r *resolver) dialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil || net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, address)
+		}
+		addrs, err := r.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		err = errors.New("no addresses for " + host)
+		for _, addr := range addrs {
+			var conn net.Conn
+			if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(addr, port)); err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+func TestResolver_Cache(t *testing.T) {
+	now := time.Now()
+	lookups := 0
+	r := newResolver(config.DNSConfig{CacheTTL: time.Minute}, time.Second)
+	r.now = func() time.Time { return now }
+	r.lookup = func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		return []string{"10.0.0.1"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if addrs, err := r.resolve(context.Background(), "api.internal"); err != nil || addrs[0] != "10.0.0.1" {
+			t.Fatalf("Expected the address of the host, got %v, %v", addrs, err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("Expected one lookup within the TTL, got %d", lookups)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := r.resolve(context.Background(), "api.internal"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lookups != 2 {
+		t.Errorf("Expected another lookup after the TTL, got %d", lookups)
+	}
+}
+
+func TestResolver_Dial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	r := newResolver(config.DNSConfig{CacheTTL: time.Minute}, time.Second)
+	r.lookup = func(ctx context.Context, host string) ([]string, error) {
+		if host != "api.internal" {
+			t.Errorf("Expected a lookup of api.internal, got %s", host)
+		}
+		// The test server only listens on IPv4, so the next address is dialed
+		return []string{"::1", "127.0.0.1"}, nil
+	}
+	transport := &http.Transport{DialContext: r.dialContext(&net.Dialer{Timeout: time.Second})}
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get("http://api.internal:" + port + "/")
+	if err != nil {
+		t.Fatalf("Expected the resolved host to be reached, got %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestNew_DNSSettings(t *testing.T) {
+	plain := New(&config.OpenAPIConfig{})
+	custom := New(&config.OpenAPIConfig{DNS: config.DNSConfig{Servers: []string{"10.0.0.53"}, CacheTTL: time.Minute}})
+	if plain.Transport == custom.Transport {
+		t.Error("Expected DNS settings to use another transport")
+	}
+}
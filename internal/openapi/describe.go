@@ -0,0 +1,92 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SpecSummary describes the parts of a specification used to scaffold a configuration
+type SpecSummary struct {
+	Title           string
+	Servers         []string
+	SecuritySchemes []SecurityScheme
+	Operations      int
+}
+
+// SecurityScheme is an authentication method declared by a specification
+type SecurityScheme struct {
+	Name   string // key in components.securitySchemes
+	Type   string // "http", "apiKey", "oauth2" or "openIdConnect"
+	Scheme string // HTTP auth scheme for type "http", e.g. "bearer" or "basic"
+	In     string // location of an API key: "header", "query" or "cookie"
+	Key    string // name of the API key header or query parameter
+}
+
+// Describe loads the specification and summarizes its title, servers, security schemes and operations
+func (p *Parser) Describe() (*SpecSummary, error) {
+	spec, err := p.loadSpec()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
+	}
+
+	summary := &SpecSummary{}
+	if spec.Info != nil {
+		summary.Title = spec.Info.Title
+	}
+	for _, server := range spec.Servers {
+		if server != nil {
+			summary.Servers = append(summary.Servers, expandServerURL(server))
+		}
+	}
+	if spec.Paths != nil {
+		for _, item := range spec.Paths.Map() {
+			summary.Operations += len(item.Operations())
+		}
+	}
+
+	if spec.Components != nil {
+		// Schemes required by the spec's default security come first
+		required := make(map[string]bool)
+		for _, requirement := range spec.Security {
+			for name := range requirement {
+				required[name] = true
+			}
+		}
+
+		for name, ref := range spec.Components.SecuritySchemes {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			summary.SecuritySchemes = append(summary.SecuritySchemes, SecurityScheme{
+				Name:   name,
+				Type:   ref.Value.Type,
+				Scheme: strings.ToLower(ref.Value.Scheme),
+				In:     ref.Value.In,
+				Key:    ref.Value.Name,
+			})
+		}
+		sort.Slice(summary.SecuritySchemes, func(i, j int) bool {
+			a, b := summary.SecuritySchemes[i], summary.SecuritySchemes[j]
+			if required[a.Name] != required[b.Name] {
+				return required[a.Name]
+			}
+			return a.Name < b.Name
+		})
+	}
+
+	return summary, nil
+}
+
+// expandServerURL substitutes the default value of each server variable
+func expandServerURL(server *openapi3.Server) string {
+	serverURL := server.URL
+	for name, variable := range server.Variables {
+		if variable != nil {
+			serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variable.Default)
+		}
+	}
+	return serverURL
+}
@@ -0,0 +1,83 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// specStatusError is returned when the server of a remote specification answers with an
+// error status
+type specStatusError struct {
+	statusCode int
+}
+
+func (e *specStatusError) Error() string {
+	return fmt.Sprintf("failed to fetch OpenAPI spec: HTTP %d", e.statusCode)
+}
+
+// retryable reports whether a failed fetch may succeed when attempted again: network errors,
+// rate limiting and server errors are, other error statuses are not
+func retryable(err error) bool {
+	var status *specStatusError
+	if errors.As(err, &status) {
+		return status.statusCode == http.StatusTooManyRequests || status.statusCode >= 500
+	}
+	return true
+}
+
+// fetchSpec fetches a remote specification, retrying transient failures as spec_fetch
+// configures. A successful fetch is written to the cache file, which is read instead when
+// every attempt fails.
+func (p *Parser) fetchSpec(url string) ([]byte, error) {
+	fetch := p.config.SpecFetch
+	backoff := fetch.BackoffOrDefault()
+
+	content, err := p.loadFromURL(url)
+	for attempt := 1; err != nil && attempt <= fetch.Retries && retryable(err); attempt++ {
+		log.Printf("WARNING: Fetching OpenAPI spec failed, retrying in %s (%d/%d): %v", backoff, attempt, fetch.Retries, err)
+		p.sleep(backoff)
+		backoff = min(2*backoff, fetch.MaxBackoffOrDefault())
+		content, err = p.loadFromURL(url)
+	}
+
+	if fetch.CacheFile == "" {
+		return content, err
+	}
+	if err == nil {
+		if cacheErr := writeSpecCache(fetch.CacheFile, content); cacheErr != nil {
+			log.Printf("WARNING: Failed to cache OpenAPI spec: %v", cacheErr)
+		}
+		return content, nil
+	}
+	cached, cacheErr := os.ReadFile(fetch.CacheFile)
+	if cacheErr != nil {
+		return nil, fmt.Errorf("%w; no cached spec: %v", err, cacheErr)
+	}
+	log.Printf("WARNING: Using the cached OpenAPI spec %s: %v", fetch.CacheFile, err)
+	return cached, nil
+}
+
+// writeSpecCache replaces the cached specification, writing to a temporary file first so an
+// interrupted write leaves the previous copy intact
+func writeSpecCache(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+const fetchTestSpec = `{"openapi": "3.0.0", "info": {"title": "Test", "version": "1"}, "paths": {}}`
+
+// newFetchTestServer serves the spec after failing the first failures requests with status
+func newFetchTestServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(fetchTestSpec))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestFetchSpec_Retries(t *testing.T) {
+	server, requests := newFetchTestServer(t, 3, http.StatusServiceUnavailable)
+	parser := NewParser(&config.OpenAPIConfig{
+		SpecPath:  server.URL,
+		SpecFetch: config.SpecFetchConfig{Retries: 3, Backoff: time.Second, MaxBackoff: 3 * time.Second},
+	})
+	var waits []time.Duration
+	parser.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	content, err := parser.loadContent()
+	if err != nil {
+		t.Fatalf("Expected the spec after retries, got %v", err)
+	}
+	if string(content) != fetchTestSpec || requests.Load() != 4 {
+		t.Errorf("Expected the spec on the fourth request, got %q after %d", content, requests.Load())
+	}
+	if len(waits) != 3 || waits[0] != time.Second || waits[1] != 2*time.Second || waits[2] != 3*time.Second {
+		t.Errorf("Expected doubling backoff capped at 3s, got %v", waits)
+	}
+
+	// Other client errors are not retried
+	server, requests = newFetchTestServer(t, 1, http.StatusNotFound)
+	parser = NewParser(&config.OpenAPIConfig{SpecPath: server.URL, SpecFetch: config.SpecFetchConfig{Retries: 3}})
+	parser.sleep = func(time.Duration) {}
+	if _, err := parser.loadContent(); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("Expected the 404 to be returned, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected a single request, got %d", requests.Load())
+	}
+}
+
+func TestFetchSpec_CacheFallback(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "cache", "spec.json")
+	server, _ := newFetchTestServer(t, 0, 0)
+	parser := NewParser(&config.OpenAPIConfig{SpecPath: server.URL, SpecFetch: config.SpecFetchConfig{CacheFile: cacheFile}})
+	if _, err := parser.loadContent(); err != nil {
+		t.Fatalf("Expected the spec, got %v", err)
+	}
+	if cached, err := os.ReadFile(cacheFile); err != nil || string(cached) != fetchTestSpec {
+		t.Fatalf("Expected the spec to be cached, got %q, %v", cached, err)
+	}
+
+	// The cached copy is used once the server fails
+	server.Close()
+	content, err := parser.loadContent()
+	if err != nil || string(content) != fetchTestSpec {
+		t.Errorf("Expected the cached spec, got %q, %v", content, err)
+	}
+
+	parser.config.SpecFetch.CacheFile = filepath.Join(t.TempDir(), "missing.json")
+	if _, err := parser.loadContent(); err == nil || !strings.Contains(err.Error(), "no cached spec") {
+		t.Errorf("Expected an error without a cached spec, got %v", err)
+	}
+}
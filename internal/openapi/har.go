@@ -0,0 +1,355 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// harLog is the part of an HTTP Archive (HAR) capture used to infer operations
+type harLog struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request struct {
+		Method      string `json:"method"`
+		URL         string `json:"url"`
+		QueryString []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"queryString"`
+		PostData *struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status  int `json:"status"`
+		Content struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+// requestJSON returns the decoded JSON request body of the entry, if any
+func (e *harEntry) requestJSON() (interface{}, bool) {
+	if e.Request.PostData == nil || !strings.Contains(e.Request.PostData.MimeType, "json") {
+		return nil, false
+	}
+	var body interface{}
+	if err := json.Unmarshal([]byte(e.Request.PostData.Text), &body); err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// responseJSON returns the decoded JSON response body of the entry, if any
+func (e *harEntry) responseJSON() (interface{}, bool) {
+	content := e.Response.Content
+	if !strings.Contains(content.MimeType, "json") || content.Encoding != "" {
+		return nil, false
+	}
+	var body interface{}
+	if err := json.Unmarshal([]byte(content.Text), &body); err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// harIDSegment matches path segments that identify a resource: numbers, UUIDs and long hex strings
+var harIDSegment = regexp.MustCompile(`^(\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+
+// isHAR reports whether the content is an HTTP Archive rather than an OpenAPI specification
+func isHAR(content []byte) bool {
+	var probe struct {
+		Log *struct {
+			Entries json.RawMessage `json:"entries"`
+		} `json:"log"`
+	}
+	return json.Unmarshal(content, &probe) == nil && probe.Log != nil && probe.Log.Entries != nil
+}
+
+// harOperation collects the recorded requests of one inferred operation
+type harOperation struct {
+	method     string
+	path       string   // templated path, e.g. /users/{user_id}
+	pathParams []string // names of the templated segments, in order
+	entries    []harEntry
+	values     [][]string // recorded values of the templated segments, per entry
+}
+
+// convertHARToOpenAPI3 infers an OpenAPI 3 specification from the API requests of a HAR
+// capture. Requests are grouped by method and path, with numeric, UUID and hex segments
+// turned into path parameters; query parameters seen on every request of an operation are
+// required. Request and response schemas are inferred from the recorded JSON bodies.
+//
+// Captures usually include requests to other hosts (CDNs, analytics), so only the host of
+// base_url is kept, or when it is not set the host with the most API requests. Only
+// requests with JSON bodies or non-GET methods are considered API requests.
+func (p *Parser) convertHARToOpenAPI3(content []byte) (*openapi3.T, error) {
+	var har harLog
+	if err := json.Unmarshal(content, &har); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR: %w", err)
+	}
+
+	type recorded struct {
+		entry harEntry
+		url   *url.URL
+	}
+	var requests []recorded
+	hosts := make(map[string]int)
+	for _, entry := range har.Log.Entries {
+		method := strings.ToUpper(entry.Request.Method)
+		if method == "OPTIONS" || method == "HEAD" {
+			continue
+		}
+		_, requestJSON := entry.requestJSON()
+		if !strings.Contains(entry.Response.Content.MimeType, "json") && !requestJSON && method == "GET" {
+			continue
+		}
+		requestURL, err := url.Parse(entry.Request.URL)
+		if err != nil || requestURL.Host == "" {
+			continue
+		}
+		requests = append(requests, recorded{entry: entry, url: requestURL})
+		hosts[requestURL.Host]++
+	}
+
+	host, basePath := "", ""
+	if p.config.BaseURL != "" {
+		baseURL, err := url.Parse(p.config.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base URL %q: %w", p.config.BaseURL, err)
+		}
+		host, basePath = baseURL.Host, strings.TrimSuffix(baseURL.Path, "/")
+	} else {
+		for candidate, count := range hosts {
+			if count > hosts[host] || (count == hosts[host] && candidate < host) {
+				host = candidate
+			}
+		}
+	}
+
+	operations := make(map[string]*harOperation)
+	scheme := "https"
+	for _, request := range requests {
+		if request.url.Host != host || !strings.HasPrefix(request.url.Path, basePath) {
+			continue
+		}
+		scheme = request.url.Scheme
+		path, names, values := templateHARPath(strings.TrimPrefix(request.url.Path, basePath))
+		method := strings.ToUpper(request.entry.Request.Method)
+		key := method + " " + path
+		operation, exists := operations[key]
+		if !exists {
+			operation = &harOperation{method: method, path: path, pathParams: names}
+			operations[key] = operation
+		}
+		operation.entries = append(operation.entries, request.entry)
+		operation.values = append(operation.values, values)
+	}
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("no API requests found in HAR capture")
+	}
+
+	spec := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "Inferred from HAR capture", Version: "1.0.0"},
+		Paths:   openapi3.NewPaths(),
+	}
+	if host != "" {
+		spec.Servers = openapi3.Servers{{URL: scheme + "://" + host + basePath}}
+	}
+	for _, operation := range operations {
+		pathItem := spec.Paths.Value(operation.path)
+		if pathItem == nil {
+			pathItem = &openapi3.PathItem{}
+			spec.Paths.Set(operation.path, pathItem)
+		}
+		pathItem.SetOperation(operation.method, operation.build())
+	}
+	log.Printf("Inferred %d operations from %d HAR entries for host %s", len(operations), len(har.Log.Entries), host)
+	return spec, nil
+}
+
+// build infers the operation from its recorded requests
+func (o *harOperation) build() *openapi3.Operation {
+	operation := &openapi3.Operation{
+		Summary:   fmt.Sprintf("%s %s, inferred from %d recorded request(s)", o.method, o.path, len(o.entries)),
+		Responses: openapi3.NewResponsesWithCapacity(0),
+	}
+
+	for i, name := range o.pathParams {
+		values := make([]string, len(o.values))
+		for j := range o.values {
+			values[j] = o.values[j][i]
+		}
+		operation.AddParameter(&openapi3.Parameter{
+			Name:     name,
+			In:       openapi3.ParameterInPath,
+			Required: true,
+			Schema:   inferValuesSchema(values).NewRef(),
+			Example:  values[0],
+		})
+	}
+
+	// Query parameters seen on every request are required
+	queryValues := make(map[string][]string)
+	var queryNames []string
+	for _, entry := range o.entries {
+		seen := make(map[string]bool)
+		for _, param := range entry.Request.QueryString {
+			if seen[param.Name] {
+				continue
+			}
+			seen[param.Name] = true
+			if _, exists := queryValues[param.Name]; !exists {
+				queryNames = append(queryNames, param.Name)
+			}
+			queryValues[param.Name] = append(queryValues[param.Name], param.Value)
+		}
+	}
+	sort.Strings(queryNames)
+	for _, name := range queryNames {
+		values := queryValues[name]
+		operation.AddParameter(&openapi3.Parameter{
+			Name:     name,
+			In:       openapi3.ParameterInQuery,
+			Required: len(values) == len(o.entries),
+			Schema:   inferValuesSchema(values).NewRef(),
+			Example:  values[0],
+		})
+	}
+
+	for _, entry := range o.entries {
+		if body, ok := entry.requestJSON(); ok {
+			requestBody := openapi3.NewRequestBody().WithJSONSchema(inferJSONSchema(body))
+			requestBody.Content["application/json"].Example = body
+			operation.RequestBody = &openapi3.RequestBodyRef{Value: requestBody}
+			break
+		}
+	}
+
+	statuses := make(map[int]bool)
+	for _, entry := range o.entries {
+		status := entry.Response.Status
+		if status == 0 || statuses[status] {
+			continue
+		}
+		statuses[status] = true
+		response := openapi3.NewResponse().WithDescription(http.StatusText(status))
+		if body, ok := entry.responseJSON(); ok {
+			response = response.WithJSONSchema(inferJSONSchema(body))
+		}
+		operation.Responses.Set(strconv.Itoa(status), &openapi3.ResponseRef{Value: response})
+	}
+	return operation
+}
+
+// templateHARPath replaces identifier segments of a recorded path with parameters named
+// after the preceding segment, e.g. /users/42 becomes /users/{user_id}, and returns the
+// parameter names and the recorded values
+func templateHARPath(path string) (string, []string, []string) {
+	segments := strings.Split(path, "/")
+	var names, values []string
+	used := make(map[string]bool)
+	for i, segment := range segments {
+		if !harIDSegment.MatchString(segment) {
+			continue
+		}
+		name := "id"
+		if i > 0 && segments[i-1] != "" && !strings.HasPrefix(segments[i-1], "{") {
+			name = singular(strings.ToLower(segments[i-1])) + "_id"
+		}
+		for n := 2; used[name]; n++ {
+			name = strings.TrimRight(name, "0123456789") + strconv.Itoa(n)
+		}
+		used[name] = true
+		names = append(names, name)
+		values = append(values, segment)
+		segments[i] = "{" + name + "}"
+	}
+	return strings.Join(segments, "/"), names, values
+}
+
+// singular strips the plural ending of a collection name
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && len(name) > 1:
+		return strings.TrimSuffix(name, "s")
+	default:
+		return name
+	}
+}
+
+// inferValuesSchema infers the schema of recorded parameter values: integer, number or
+// boolean when every value parses as one, otherwise string
+func inferValuesSchema(values []string) *openapi3.Schema {
+	integers, numbers, booleans := true, true, true
+	for _, value := range values {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			integers = false
+		}
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			numbers = false
+		}
+		if value != "true" && value != "false" {
+			booleans = false
+		}
+	}
+	switch {
+	case integers:
+		return openapi3.NewIntegerSchema()
+	case numbers:
+		return openapi3.NewFloat64Schema()
+	case booleans:
+		return openapi3.NewBoolSchema()
+	default:
+		return openapi3.NewStringSchema()
+	}
+}
+
+// inferJSONSchema infers the schema of a recorded JSON value. Array items take the schema
+// of the first item.
+func inferJSONSchema(value interface{}) *openapi3.Schema {
+	switch v := value.(type) {
+	case bool:
+		return openapi3.NewBoolSchema()
+	case float64:
+		if v == float64(int64(v)) {
+			return openapi3.NewIntegerSchema()
+		}
+		return openapi3.NewFloat64Schema()
+	case string:
+		return openapi3.NewStringSchema()
+	case []interface{}:
+		items := openapi3.NewSchema()
+		if len(v) > 0 {
+			items = inferJSONSchema(v[0])
+		}
+		return openapi3.NewArraySchema().WithItems(items)
+	case map[string]interface{}:
+		schema := openapi3.NewObjectSchema()
+		for name, property := range v {
+			schema.WithProperty(name, inferJSONSchema(property))
+		}
+		return schema
+	default:
+		return openapi3.NewSchema().WithNullable()
+	}
+}
@@ -0,0 +1,104 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mcpify/internal/config"
+)
+
+// testHAR records two API requests for the same user operation, an order creation, and
+// requests to other hosts and for static files that are not API calls
+const testHAR = `{"log": {"version": "1.2", "entries": [
+  {"request": {"method": "GET", "url": "https://api.example.com/v1/users/42?expand=orders&page=1",
+      "queryString": [{"name": "expand", "value": "orders"}, {"name": "page", "value": "1"}]},
+   "response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"id\": 42, \"name\": \"ada\"}"}}},
+  {"request": {"method": "GET", "url": "https://api.example.com/v1/users/7?expand=orders",
+      "queryString": [{"name": "expand", "value": "orders"}]},
+   "response": {"status": 404, "content": {"mimeType": "application/json", "text": "{\"error\": \"not found\"}"}}},
+  {"request": {"method": "POST", "url": "https://api.example.com/v1/users/42/orders",
+      "postData": {"mimeType": "application/json", "text": "{\"sku\": \"A-1\", \"quantity\": 2}"}},
+   "response": {"status": 201, "content": {"mimeType": "application/json", "text": "{}"}}},
+  {"request": {"method": "GET", "url": "https://api.example.com/app.js"},
+   "response": {"status": 200, "content": {"mimeType": "application/javascript", "text": ""}}},
+  {"request": {"method": "POST", "url": "https://analytics.example.net/collect",
+      "postData": {"mimeType": "application/json", "text": "{}"}},
+   "response": {"status": 204, "content": {"mimeType": "", "text": ""}}}
+]}}`
+
+func TestParseSpec_HAR(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "capture.har")
+	if err := os.WriteFile(specPath, []byte(testHAR), 0o600); err != nil {
+		t.Fatalf("Failed to write HAR: %v", err)
+	}
+
+	tools, err := NewParser(&config.OpenAPIConfig{SpecPath: specPath, BaseURL: "https://api.example.com/v1"}).ParseSpec()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tools) != 2 {
+		t.Fatalf("Expected 2 tools, got %d: %+v", len(tools), tools)
+	}
+
+	get := tools[0]
+	if get.Name != "get_users_by_user_id" || get.Path != "/users/{user_id}" {
+		t.Errorf("Unexpected tool %s %s", get.Name, get.Path)
+	}
+	required := map[string]bool{}
+	for _, param := range get.Parameters {
+		required[param.In+" "+param.Name] = param.Required
+	}
+	expected := map[string]bool{"path user_id": true, "query expand": true, "query page": false}
+	if len(required) != len(expected) {
+		t.Errorf("Expected parameters %v, got %v", expected, required)
+	}
+	for name, isRequired := range expected {
+		if required[name] != isRequired {
+			t.Errorf("Expected %s required=%t, got %v", name, isRequired, required)
+		}
+	}
+
+	post := tools[1]
+	if post.Name != "post_users_by_user_id_orders" || post.RequestBody == nil {
+		t.Fatalf("Expected the order creation with a body, got %+v", post)
+	}
+	schema := post.RequestBody.MediaTypes()["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	properties := schema["properties"].(map[string]interface{})
+	if properties["sku"].(map[string]interface{})["type"] != "string" || properties["quantity"].(map[string]interface{})["type"] != "integer" {
+		t.Errorf("Expected the body schema inferred from the recording, got %v", properties)
+	}
+}
+
+func TestParseSpec_HARPicksBusiestHost(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "capture.har")
+	if err := os.WriteFile(specPath, []byte(testHAR), 0o600); err != nil {
+		t.Fatalf("Failed to write HAR: %v", err)
+	}
+
+	tools, err := NewParser(&config.OpenAPIConfig{SpecPath: specPath}).ParseSpec()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tools) != 2 || tools[0].Path != "/v1/users/{user_id}" {
+		t.Errorf("Expected the api.example.com operations with their full paths, got %+v", tools)
+	}
+}
+
+func TestTemplateHARPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		template string
+	}{
+		{"/users/42", "/users/{user_id}"},
+		{"/categories/7/items/9", "/categories/{category_id}/items/{item_id}"},
+		{"/files/3fa85f64-5717-4562-b3fc-2c963f66afa6", "/files/{file_id}"},
+		{"/users/me", "/users/me"},
+		{"/42/42", "/{id}/{id2}"},
+	}
+	for _, tt := range tests {
+		if template, _, _ := templateHARPath(tt.path); template != tt.template {
+			t.Errorf("Expected %s for %s, got %s", tt.template, tt.path, template)
+		}
+	}
+}
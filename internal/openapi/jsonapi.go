@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"mcpify/internal/types"
+)
+
+// bracketedQueryParam matches JSON:API family query parameters such as fields[articles] or
+// page[size]
+var bracketedQueryParam = regexp.MustCompile(`^([A-Za-z0-9_-]+)\[([^\]]+)\]$`)
+
+// groupJSONAPIParameters replaces each family of bracketed query parameters with one object
+// argument keyed by the bracketed names, e.g. fields[articles] and fields[people] become
+// fields: {"articles": "title,body", "people": "name"}, and describes the include parameter
+func groupJSONAPIParameters(parameters []types.OpenAPIParameter) []types.OpenAPIParameter {
+	var grouped []types.OpenAPIParameter
+	families := make(map[string]int) // index of each family's argument in grouped
+	for _, param := range parameters {
+		match := bracketedQueryParam.FindStringSubmatch(param.Name)
+		if param.In != "query" || match == nil {
+			if param.In == "query" && param.Name == "include" && param.Description == "" {
+				param.Description = "Comma-separated relationship paths to include, e.g. author,comments.author"
+			}
+			grouped = append(grouped, param)
+			continue
+		}
+
+		family, key := match[1], match[2]
+		i, exists := families[family]
+		if !exists {
+			i = len(grouped)
+			families[family] = i
+			grouped = append(grouped, types.OpenAPIParameter{
+				Name:   family,
+				In:     "query",
+				Schema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+			})
+		}
+		properties := grouped[i].Schema.(map[string]interface{})["properties"].(map[string]interface{})
+		properties[key] = map[string]interface{}{"type": "string", "description": param.Description}
+		grouped[i].Required = grouped[i].Required || param.Required
+	}
+
+	for family, i := range families {
+		properties := grouped[i].Schema.(map[string]interface{})["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(properties))
+		for key := range properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if family == "fields" {
+			grouped[i].Description = fmt.Sprintf("Comma-separated fields to return per resource type (%s), e.g. {\"%s\": \"name\"}",
+				strings.Join(keys, ", "), keys[0])
+		} else {
+			grouped[i].Description = fmt.Sprintf("Sent as %s[key] query parameters, keys: %s", family, strings.Join(keys, ", "))
+		}
+	}
+	return grouped
+}
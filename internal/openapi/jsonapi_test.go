@@ -0,0 +1,44 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+
+	"mcpify/internal/types"
+)
+
+func TestGroupJSONAPIParameters(t *testing.T) {
+	grouped := groupJSONAPIParameters([]types.OpenAPIParameter{
+		{Name: "id", In: "path", Required: true},
+		{Name: "include", In: "query"},
+		{Name: "fields[people]", In: "query"},
+		{Name: "fields[articles]", In: "query", Description: "Article fields"},
+		{Name: "page[size]", In: "query", Required: true},
+	})
+
+	var names []string
+	for _, param := range grouped {
+		names = append(names, param.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"id", "include", "fields", "page"}) {
+		t.Fatalf("Expected bracketed parameters grouped by family, got %v", names)
+	}
+	if grouped[1].Description == "" {
+		t.Errorf("Expected include to be described")
+	}
+
+	fields := grouped[2]
+	if fields.Description != `Comma-separated fields to return per resource type (articles, people), e.g. {"articles": "name"}` {
+		t.Errorf("Unexpected fields description %q", fields.Description)
+	}
+	expected := map[string]interface{}{"type": "object", "properties": map[string]interface{}{
+		"articles": map[string]interface{}{"type": "string", "description": "Article fields"},
+		"people":   map[string]interface{}{"type": "string", "description": ""},
+	}}
+	if !reflect.DeepEqual(fields.Schema, expected) {
+		t.Errorf("Unexpected fields schema %v", fields.Schema)
+	}
+	if fields.Required || !grouped[3].Required {
+		t.Errorf("Expected only page to be required")
+	}
+}
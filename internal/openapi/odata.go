@@ -0,0 +1,263 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"strings"
+
+	"mcpify/internal/types"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// odataEdmx is the root of an OData service's $metadata document (CSDL), in any OData version.
+// Elements are matched by local name, since the namespaces differ between versions.
+type odataEdmx struct {
+	Schemas []odataSchema `xml:"DataServices>Schema"`
+}
+
+type odataSchema struct {
+	Namespace    string            `xml:"Namespace,attr"`
+	EntityTypes  []odataEntityType `xml:"EntityType"`
+	ComplexTypes []odataEntityType `xml:"ComplexType"`
+	Containers   []odataContainer  `xml:"EntityContainer"`
+}
+
+type odataEntityType struct {
+	Name       string          `xml:"Name,attr"`
+	Key        []odataProperty `xml:"Key>PropertyRef"`
+	Properties []odataProperty `xml:"Property"`
+}
+
+type odataProperty struct {
+	Name     string `xml:"Name,attr"`
+	Type     string `xml:"Type,attr"`
+	Nullable string `xml:"Nullable,attr"`
+}
+
+type odataContainer struct {
+	EntitySets []struct {
+		Name       string `xml:"Name,attr"`
+		EntityType string `xml:"EntityType,attr"`
+	} `xml:"EntitySet"`
+}
+
+// isODataMetadata reports whether the content is an OData $metadata document
+func isODataMetadata(content []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(content), []byte("<")) {
+		return false
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "Edmx"
+		}
+	}
+}
+
+// odataStringExtension marks the quoted string key parameters, so their quotes are escaped
+const odataStringExtension = types.ODataStringExtension
+
+// maxODataDepth bounds the nesting of converted complex types, which may be recursive
+const maxODataDepth = 8
+
+// odataQueryOptions are the system query options of entity set queries
+var odataQueryOptions = []struct {
+	name        string
+	description string
+	schema      *openapi3.Schema
+}{
+	{"$filter", "Filter expression, e.g. Price gt 20 and Name eq 'Milk'", openapi3.NewStringSchema()},
+	{"$select", "Comma-separated properties to return", openapi3.NewStringSchema()},
+	{"$orderby", "Comma-separated properties to sort by, each optionally followed by asc or desc", openapi3.NewStringSchema()},
+	{"$top", "Maximum number of entities to return", openapi3.NewIntegerSchema().WithMin(0)},
+	{"$skip", "Number of entities to skip", openapi3.NewIntegerSchema().WithMin(0)},
+	{"$expand", "Comma-separated navigation properties to include", openapi3.NewStringSchema()},
+}
+
+// convertODataToOpenAPI3 converts the entity sets of an OData $metadata document to OpenAPI
+// operations: a query with the system query options and a create on the entity set, and a
+// read, update and delete on the entity addressed by its key, e.g. /Products({ProductID}).
+// Entity sets whose type has no key only get the query and create operations.
+func (p *Parser) convertODataToOpenAPI3(content []byte) (*openapi3.T, error) {
+	var edmx odataEdmx
+	if err := xml.Unmarshal(content, &edmx); err != nil {
+		return nil, fmt.Errorf("failed to parse OData metadata: %w", err)
+	}
+
+	types := make(map[string]*odataEntityType)
+	for i := range edmx.Schemas {
+		schema := &edmx.Schemas[i]
+		for j := range schema.EntityTypes {
+			types[schema.Namespace+"."+schema.EntityTypes[j].Name] = &schema.EntityTypes[j]
+		}
+		for j := range schema.ComplexTypes {
+			types[schema.Namespace+"."+schema.ComplexTypes[j].Name] = &schema.ComplexTypes[j]
+		}
+	}
+
+	spec := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "OData service", Version: "1.0.0"},
+		Paths:   openapi3.NewPaths(),
+	}
+	sets := 0
+	for _, schema := range edmx.Schemas {
+		for _, container := range schema.Containers {
+			for _, set := range container.EntitySets {
+				entityType, exists := types[set.EntityType]
+				if !exists {
+					return nil, fmt.Errorf("entity set %s: unknown entity type %s", set.Name, set.EntityType)
+				}
+				addODataEntitySet(spec, set.Name, entityType, types)
+				sets++
+			}
+		}
+	}
+	if sets == 0 {
+		return nil, fmt.Errorf("no entity sets found in OData metadata")
+	}
+	log.Printf("Converted %d OData entity sets", sets)
+	return spec, nil
+}
+
+// addODataEntitySet adds the operations of one entity set to the specification
+func addODataEntitySet(spec *openapi3.T, name string, entityType *odataEntityType, types map[string]*odataEntityType) {
+	entity := odataObjectSchema(entityType, types, 0)
+
+	query := &openapi3.Operation{
+		Summary:   "Query " + name,
+		Responses: odataResponses("200", openapi3.NewObjectSchema().WithProperty("value", openapi3.NewArraySchema().WithItems(entity))),
+	}
+	for _, option := range odataQueryOptions {
+		query.AddParameter(openapi3.NewQueryParameter(option.name).WithDescription(option.description).WithSchema(option.schema))
+	}
+	create := &openapi3.Operation{
+		Summary:     "Create an entity in " + name,
+		RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchema(entity)},
+		Responses:   odataResponses("201", entity),
+	}
+	spec.Paths.Set("/"+name, &openapi3.PathItem{Get: query, Post: create})
+
+	if len(entityType.Key) == 0 {
+		return
+	}
+
+	// Keys are addressed as Products(1) or Products('abc'), with named values for composite keys
+	var keyParams []*openapi3.Parameter
+	var keyParts []string
+	for _, ref := range entityType.Key {
+		property := entityType.property(ref.Name)
+		literal := "{" + ref.Name + "}"
+		quoted := property != nil && property.Type == "Edm.String"
+		if quoted {
+			literal = "'" + literal + "'"
+		}
+		if len(entityType.Key) > 1 {
+			literal = ref.Name + "=" + literal
+		}
+		keyParts = append(keyParts, literal)
+		schema := openapi3.NewStringSchema()
+		if property != nil {
+			schema = odataPropertySchema(property.Type, types, 0)
+		}
+		param := openapi3.NewPathParameter(ref.Name).WithSchema(schema)
+		if quoted {
+			param.Extensions = map[string]interface{}{odataStringExtension: true}
+		}
+		keyParams = append(keyParams, param)
+	}
+	withKey := func(operation *openapi3.Operation) *openapi3.Operation {
+		for _, param := range keyParams {
+			operation.AddParameter(param)
+		}
+		return operation
+	}
+
+	read := withKey(&openapi3.Operation{Summary: "Get an entity of " + name + " by key", Responses: odataResponses("200", entity)})
+	for _, option := range odataQueryOptions {
+		if option.name == "$select" || option.name == "$expand" {
+			read.AddParameter(openapi3.NewQueryParameter(option.name).WithDescription(option.description).WithSchema(option.schema))
+		}
+	}
+	update := withKey(&openapi3.Operation{
+		Summary:     "Update properties of an entity of " + name,
+		RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchema(entity)},
+		Responses:   odataResponses("204", nil),
+	})
+	remove := withKey(&openapi3.Operation{Summary: "Delete an entity of " + name, Responses: odataResponses("204", nil)})
+
+	spec.Paths.Set("/"+name+"("+strings.Join(keyParts, ",")+")", &openapi3.PathItem{Get: read, Patch: update, Delete: remove})
+}
+
+// odataResponses returns the success response of an operation
+func odataResponses(status string, schema *openapi3.Schema) *openapi3.Responses {
+	response := openapi3.NewResponse().WithDescription("Success")
+	if schema != nil {
+		response = response.WithJSONSchema(schema)
+	}
+	responses := openapi3.NewResponsesWithCapacity(1)
+	responses.Set(status, &openapi3.ResponseRef{Value: response})
+	return responses
+}
+
+// odataObjectSchema converts an entity or complex type to an object schema
+func odataObjectSchema(entityType *odataEntityType, types map[string]*odataEntityType, depth int) *openapi3.Schema {
+	schema := openapi3.NewObjectSchema()
+	if depth >= maxODataDepth {
+		return schema
+	}
+	for _, property := range entityType.Properties {
+		propertySchema := odataPropertySchema(property.Type, types, depth+1)
+		if property.Nullable != "false" {
+			propertySchema.Nullable = true
+		}
+		schema.WithProperty(property.Name, propertySchema)
+	}
+	return schema
+}
+
+// odataPropertySchema converts an EDM type, a complex type or a collection to a schema
+func odataPropertySchema(edmType string, types map[string]*odataEntityType, depth int) *openapi3.Schema {
+	if strings.HasPrefix(edmType, "Collection(") && strings.HasSuffix(edmType, ")") {
+		item := strings.TrimSuffix(strings.TrimPrefix(edmType, "Collection("), ")")
+		return openapi3.NewArraySchema().WithItems(odataPropertySchema(item, types, depth))
+	}
+	if complexType, exists := types[edmType]; exists {
+		return odataObjectSchema(complexType, types, depth)
+	}
+	switch edmType {
+	case "Edm.Int16", "Edm.Int32", "Edm.Byte", "Edm.SByte":
+		return openapi3.NewInt32Schema()
+	case "Edm.Int64":
+		return openapi3.NewInt64Schema()
+	case "Edm.Decimal", "Edm.Double", "Edm.Single":
+		return openapi3.NewFloat64Schema()
+	case "Edm.Boolean":
+		return openapi3.NewBoolSchema()
+	case "Edm.DateTimeOffset":
+		return openapi3.NewDateTimeSchema()
+	case "Edm.Date":
+		return openapi3.NewStringSchema().WithFormat("date")
+	case "Edm.Guid":
+		return openapi3.NewUUIDSchema()
+	default:
+		return openapi3.NewStringSchema()
+	}
+}
+
+// property returns the property of the type with the given name, or nil
+func (t *odataEntityType) property(name string) *odataProperty {
+	for i := range t.Properties {
+		if t.Properties[i].Name == name {
+			return &t.Properties[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mcpify/internal/config"
+)
+
+const testODataMetadata = `<?xml version="1.0" encoding="utf-8"?>
+<edmx:Edmx Version="4.0" xmlns:edmx="http://docs.oasis-open.org/odata/ns/edmx">
+  <edmx:DataServices>
+    <Schema Namespace="Sales" xmlns="http://docs.oasis-open.org/odata/ns/edm">
+      <ComplexType Name="Address">
+        <Property Name="City" Type="Edm.String"/>
+      </ComplexType>
+      <EntityType Name="Customer">
+        <Key><PropertyRef Name="CustomerID"/></Key>
+        <Property Name="CustomerID" Type="Edm.String" Nullable="false"/>
+        <Property Name="Address" Type="Sales.Address"/>
+        <Property Name="Tags" Type="Collection(Edm.String)"/>
+      </EntityType>
+      <EntityType Name="OrderLine">
+        <Key><PropertyRef Name="OrderID"/><PropertyRef Name="Line"/></Key>
+        <Property Name="OrderID" Type="Edm.Int32" Nullable="false"/>
+        <Property Name="Line" Type="Edm.Int32" Nullable="false"/>
+        <Property Name="Amount" Type="Edm.Decimal"/>
+      </EntityType>
+      <EntityContainer Name="Container">
+        <EntitySet Name="Customers" EntityType="Sales.Customer"/>
+        <EntitySet Name="OrderLines" EntityType="Sales.OrderLine"/>
+      </EntityContainer>
+    </Schema>
+  </edmx:DataServices>
+</edmx:Edmx>`
+
+func TestParseSpec_ODataMetadata(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "metadata.xml")
+	if err := os.WriteFile(specPath, []byte(testODataMetadata), 0o600); err != nil {
+		t.Fatalf("Failed to write metadata: %v", err)
+	}
+
+	tools, err := NewParser(&config.OpenAPIConfig{SpecPath: specPath}).ParseSpec()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	paths := map[string]string{}
+	for _, tool := range tools {
+		paths[tool.Name] = tool.Method + " " + tool.Path
+	}
+	expected := map[string]string{
+		"get_customers":                                     "GET /Customers",
+		"post_customers":                                    "POST /Customers",
+		"get_customers_by_customerid":                       "GET /Customers('{CustomerID}')",
+		"patch_customers_by_customerid":                     "PATCH /Customers('{CustomerID}')",
+		"delete_customers_by_customerid":                    "DELETE /Customers('{CustomerID}')",
+		"get_orderlines":                                    "GET /OrderLines",
+		"post_orderlines":                                   "POST /OrderLines",
+		"get_orderlines_orderid_by_orderid_line_by_line":    "GET /OrderLines(OrderID={OrderID},Line={Line})",
+		"patch_orderlines_orderid_by_orderid_line_by_line":  "PATCH /OrderLines(OrderID={OrderID},Line={Line})",
+		"delete_orderlines_orderid_by_orderid_line_by_line": "DELETE /OrderLines(OrderID={OrderID},Line={Line})",
+	}
+	if len(paths) != len(expected) {
+		t.Errorf("Expected %d tools, got %v", len(expected), paths)
+	}
+	for name, path := range expected {
+		if paths[name] != path {
+			t.Errorf("Expected tool %s for %s, got %q", name, path, paths[name])
+		}
+	}
+
+	// String keys are quoted in the path, so their values need their quotes escaped
+	for _, tool := range tools {
+		for _, param := range tool.Parameters {
+			if param.In == "path" && param.ODataString != (param.Name == "CustomerID") {
+				t.Errorf("Expected only CustomerID to be marked as a string key, got %s of %s marked %v", param.Name, tool.Name, param.ODataString)
+			}
+		}
+	}
+
+	for _, tool := range tools {
+		if tool.Name != "get_customers" {
+			continue
+		}
+		var names []string
+		for _, param := range tool.Parameters {
+			names = append(names, param.Name)
+		}
+		if len(names) != 6 || names[0] != "$filter" || names[3] != "$top" {
+			t.Errorf("Expected the system query options, got %v", names)
+		}
+	}
+}
@@ -1,15 +1,22 @@
 package openapi
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"mcpify/internal/config"
+	"mcpify/internal/httpclient"
 	"mcpify/internal/types"
 
 	"github.com/getkin/kin-openapi/openapi2"
@@ -17,29 +24,47 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
+// ErrSpecValidation is returned when a specification loads but does not conform to the OpenAPI schema
+var ErrSpecValidation = errors.New("OpenAPI spec validation failed")
+
 // Parser handles OpenAPI specification parsing and tool generation
 type Parser struct {
 	config    *config.OpenAPIConfig
 	client    *http.Client
 	evaluator *config.RequestEvaluator
+	// schemaMaps memoizes converted schemas by pointer, so component schemas shared by many
+	// operations are converted once
+	schemaMaps sync.Map // map[*openapi3.Schema]map[string]interface{}
+	// sleep waits between attempts to fetch a remote specification
+	sleep func(time.Duration)
 }
 
 // NewParser creates a new OpenAPI parser
 func NewParser(cfg *config.OpenAPIConfig) *Parser {
 	return &Parser{
-		config: cfg,
-		client: &http.Client{
-			Timeout: cfg.Timeout,
-		},
+		config:    cfg,
+		client:    httpclient.New(cfg),
 		evaluator: config.NewRequestEvaluator(),
+		sleep:     time.Sleep,
 	}
 }
 
 // ParseSpec parses an OpenAPI specification and returns generated tools
 func (p *Parser) ParseSpec() ([]types.APITool, error) {
 	log.Printf("Starting to parse OpenAPI spec")
+	content, err := p.loadContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
+	}
+
+	// SOAP services are described by a WSDL instead
+	if isWSDL(content) {
+		log.Printf("Detected WSDL, generating tools for SOAP operations")
+		return p.generateWSDLTools(content)
+	}
+
 	// Load OpenAPI spec
-	spec, err := p.loadSpec()
+	spec, err := p.parseSpec(content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
 	}
@@ -54,8 +79,51 @@ func (p *Parser) ParseSpec() ([]types.APITool, error) {
 	return tools, nil
 }
 
+// ValidateSpec loads the specification and checks it against the OpenAPI schema. ParseSpec
+// skips this check so that specs with minor problems can still be served. A WSDL is checked
+// by generating its tools.
+func (p *Parser) ValidateSpec() error {
+	content, err := p.loadContent()
+	if err != nil {
+		return fmt.Errorf("failed to load OpenAPI spec: %w", err)
+	}
+	if isWSDL(content) {
+		_, err := p.generateWSDLTools(content)
+		return err
+	}
+	spec, err := p.parseSpec(content)
+	if err != nil {
+		return fmt.Errorf("failed to load OpenAPI spec: %w", err)
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		return fmt.Errorf("%w: %v", ErrSpecValidation, err)
+	}
+	return nil
+}
+
+// Spec loads the specification as OpenAPI 3, converting Swagger 2.0 specifications
+func (p *Parser) Spec() (*openapi3.T, error) {
+	spec, err := p.loadSpec()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
+	}
+	return spec, nil
+}
+
 // loadSpec loads OpenAPI specification from file or URL
 func (p *Parser) loadSpec() (*openapi3.T, error) {
+	content, err := p.loadContent()
+	if err != nil {
+		return nil, err
+	}
+	if isWSDL(content) {
+		return nil, fmt.Errorf("%s is a WSDL, not an OpenAPI specification", p.config.SpecPath)
+	}
+	return p.parseSpec(content)
+}
+
+// loadContent reads the specification from file or URL
+func (p *Parser) loadContent() ([]byte, error) {
 	var content []byte
 	var err error
 
@@ -63,7 +131,7 @@ func (p *Parser) loadSpec() (*openapi3.T, error) {
 
 	// Check if spec path is a URL
 	if strings.HasPrefix(p.config.SpecPath, "http://") || strings.HasPrefix(p.config.SpecPath, "https://") {
-		content, err = p.loadFromURL(p.config.SpecPath)
+		content, err = p.fetchSpec(p.config.SpecPath)
 	} else {
 		content, err = p.loadFromFile(p.config.SpecPath)
 	}
@@ -73,6 +141,25 @@ func (p *Parser) loadSpec() (*openapi3.T, error) {
 	}
 
 	log.Printf("Successfully loaded spec, content length: %d bytes", len(content))
+	return content, nil
+}
+
+// parseSpec parses an OpenAPI 3.x or Swagger 2.0 specification, an OData $metadata document
+// or a HAR capture, converting the others to OpenAPI 3.x
+func (p *Parser) parseSpec(content []byte) (*openapi3.T, error) {
+	var err error
+
+	// OData services describe themselves with their $metadata document
+	if isODataMetadata(content) {
+		log.Printf("Detected OData metadata, generating entity set operations")
+		return p.convertODataToOpenAPI3(content)
+	}
+
+	// APIs without a specification can be described by a HAR capture of their traffic
+	if isHAR(content) {
+		log.Printf("Detected HAR capture, inferring operations from recorded requests")
+		return p.convertHARToOpenAPI3(content)
+	}
 
 	// Check if it's Swagger 2.0 first
 	var swagger2Spec openapi2.T
@@ -151,7 +238,9 @@ func (p *Parser) loadFromURL(url string) ([]byte, error) {
 	}
 
 	// Add authentication headers
-	p.addAuthHeaders(req)
+	if err := p.addAuthHeaders(req); err != nil {
+		return nil, fmt.Errorf("failed to add authentication: %w", err)
+	}
 
 	// Add custom headers (static and dynamic)
 	evaluatedHeaders, err := p.evaluateHeaders(p.config.Headers, req.Header)
@@ -159,8 +248,11 @@ func (p *Parser) loadFromURL(url string) ([]byte, error) {
 		return nil, fmt.Errorf("failed to evaluate headers: %w", err)
 	}
 
-	for name, value := range evaluatedHeaders {
-		req.Header.Set(name, value)
+	for name, values := range evaluatedHeaders {
+		req.Header.Del(name)
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
 	}
 
 	// Make request
@@ -173,7 +265,7 @@ func (p *Parser) loadFromURL(url string) ([]byte, error) {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch OpenAPI spec: HTTP %d", resp.StatusCode)
+		return nil, &specStatusError{statusCode: resp.StatusCode}
 	}
 
 	// Read response body
@@ -186,21 +278,33 @@ func (p *Parser) loadFromURL(url string) ([]byte, error) {
 }
 
 // addAuthHeaders adds authentication headers to the request
-func (p *Parser) addAuthHeaders(req *http.Request) {
+func (p *Parser) addAuthHeaders(req *http.Request) error {
 	switch p.config.Auth.Type {
 	case "bearer":
-		if p.config.Auth.Token != "" {
-			req.Header.Set("Authorization", "Bearer "+p.config.Auth.Token)
+		token, err := p.config.Auth.TokenValue()
+		if err != nil {
+			return err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
 		}
 	case "basic":
-		if p.config.Auth.Username != "" && p.config.Auth.Password != "" {
-			req.SetBasicAuth(p.config.Auth.Username, p.config.Auth.Password)
+		password, err := p.config.Auth.PasswordValue()
+		if err != nil {
+			return err
+		}
+		if p.config.Auth.Username != "" && password != "" {
+			req.SetBasicAuth(p.config.Auth.Username, password)
 		}
 	case "api_key":
-		if p.config.Auth.APIKey != "" && p.config.Auth.APIKeyName != "" {
+		apiKey, err := p.config.Auth.APIKeyValue()
+		if err != nil {
+			return err
+		}
+		if apiKey != "" && p.config.Auth.APIKeyName != "" {
 			switch p.config.Auth.APIKeyIn {
 			case "header":
-				req.Header.Set(p.config.Auth.APIKeyName, p.config.Auth.APIKey)
+				req.Header.Set(p.config.Auth.APIKeyName, apiKey)
 			case "query":
 				// This would be handled when building the URL
 			}
@@ -214,51 +318,49 @@ func (p *Parser) addAuthHeaders(req *http.Request) {
 		// TODO: Add proper logging
 		log.Printf("Warning: failed to evaluate auth headers: %v", err)
 	} else {
-		for name, value := range evaluatedAuthHeaders {
-			req.Header.Set(name, value)
+		for name, values := range evaluatedAuthHeaders {
+			req.Header.Del(name)
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
 		}
 	}
+
+	return nil
 }
 
 // evaluateHeaders evaluates dynamic headers using the request evaluator
-func (p *Parser) evaluateHeaders(headers config.HeadersConfig, requestHeaders http.Header) (map[string]string, error) {
+func (p *Parser) evaluateHeaders(headers config.HeadersConfig, requestHeaders http.Header) (map[string][]string, error) {
 	// Create a minimal request context for OpenAPI spec fetching
-	requestContext := config.RequestContext{
-		Headers: make(map[string]string),
-		Query:   make(map[string]string),
-		Form:    make(map[string]string),
-		Method:  "GET",
-		Path:    "/",
-	}
+	requestContext := config.NewRequestContextFromHTTP(requestHeaders, nil, nil, "GET", "/")
 
-	// Convert HTTP headers to map (normalize to lowercase for case-insensitive matching)
-	for name, values := range requestHeaders {
-		if len(values) > 0 {
-			requestContext.Headers[strings.ToLower(name)] = values[0] // Take first value
-		}
-	}
-
-	return p.evaluator.EvaluateHeaders(headers, requestContext)
+	return p.evaluator.EvaluateHeaderValues(headers, requestContext)
 }
 
-// generateTools generates MCP tools from OpenAPI specification
+// generateTools generates MCP tools from OpenAPI specification. Operations are converted by a
+// pool of workers; tools are returned sorted by path and then method, whatever the worker count.
 func (p *Parser) generateTools(spec *openapi3.T) ([]types.APITool, error) {
-	var tools []types.APITool
-
-	// fmt.Printf("Generating tools from spec with %d paths\n", len(spec.Paths.Map()))
-
-	// Iterate through all paths and operations
-	for path, pathItem := range spec.Paths.Map() {
-		// fmt.Printf("Processing path: %s\n", path)
-		// Check if path should be excluded
-		if p.shouldExcludePath(path) {
+	type operationJob struct {
+		path     string
+		method   string
+		op       *openapi3.Operation
+		pathItem *openapi3.PathItem
+	}
+
+	pathItems := spec.Paths.Map()
+	paths := make([]string, 0, len(pathItems))
+	for path := range pathItems {
+		// Check if path should be excluded, or not included when an include list is specified
+		if p.shouldExcludePath(path) || !p.shouldIncludePath(path) {
 			continue
 		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
 
-		// Check if path should be included (if include list is specified)
-		if !p.shouldIncludePath(path) {
-			continue
-		}
+	var jobs []operationJob
+	for _, path := range paths {
+		pathItem := pathItems[path]
 
 		// Generate tools for each HTTP method
 		operations := []struct {
@@ -273,19 +375,58 @@ func (p *Parser) generateTools(spec *openapi3.T) ([]types.APITool, error) {
 		}
 
 		for _, opInfo := range operations {
-			if opInfo.op == nil {
-				continue
+			if opInfo.op != nil {
+				jobs = append(jobs, operationJob{path: path, method: opInfo.method, op: opInfo.op, pathItem: pathItem})
 			}
+		}
+	}
 
-			tool, err := p.generateToolFromOperation(path, opInfo.method, opInfo.op)
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate tool for %s %s: %w", opInfo.method, path, err)
+	// Each worker writes only the slots of the jobs it takes, so results keep the job order
+	tools := make([]types.APITool, len(jobs))
+	errs := make([]error, len(jobs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(runtime.GOMAXPROCS(0), len(jobs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				job := jobs[i]
+				tool, err := p.generateToolFromOperation(job.path, job.method, job.op)
+				if err != nil {
+					errs[i] = fmt.Errorf("failed to generate tool for %s %s: %w", job.method, job.path, err)
+					continue
+				}
+				tool.BaseURL = p.operationBaseURL(job.path, job.op, job.pathItem)
+				tools[i] = tool
 			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
 
-			tools = append(tools, tool)
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
 	}
 
+	// Replacements are named by operationId or method and path, and presented by tool name
+	toolNames := make(map[string]string, 2*len(jobs))
+	for i, job := range jobs {
+		if job.op.OperationID != "" {
+			toolNames[job.op.OperationID] = tools[i].Name
+		}
+		toolNames[job.method+" "+job.path] = tools[i].Name
+	}
+	for i := range tools {
+		if replacement, ok := toolNames[tools[i].DeprecatedBy]; ok {
+			tools[i].DeprecatedBy = replacement
+		}
+	}
 	return tools, nil
 }
 
@@ -305,17 +446,75 @@ func (p *Parser) generateToolFromOperation(path, method string, operation *opena
 
 	// Create tool
 	tool := types.APITool{
-		Name:        toolName,
-		Description: description,
-		Method:      method,
-		Path:        path,
-		Parameters:  parameters,
-		RequestBody: requestBody,
+		Name:                toolName,
+		Description:         description,
+		Method:              method,
+		Path:                path,
+		Tags:                operation.Tags,
+		Parameters:          parameters,
+		RequestBody:         requestBody,
+		ResolveOutputSchema: p.extractOutputSchema(operation),
+		Accept:              extractAccept(operation),
+		Deprecated:          operation.Deprecated,
+	}
+	if operation.Deprecated {
+		tool.DeprecatedBy, tool.DeprecationNote = deprecationHint(operation)
 	}
 
 	return tool, nil
 }
 
+// operationBaseURL returns the base URL of an operation: the first server route of the
+// configuration matching its path or tags, or else the first server the spec declares for the
+// operation or its path, with server variables set to their defaults. It returns an empty
+// string for operations served from the API's base URL.
+func (p *Parser) operationBaseURL(path string, operation *openapi3.Operation, pathItem *openapi3.PathItem) string {
+	for _, route := range p.config.Servers {
+		if route.Matches(path, operation.Tags) {
+			return route.BaseURL
+		}
+	}
+
+	var servers openapi3.Servers
+	if operation.Servers != nil && len(*operation.Servers) > 0 {
+		servers = *operation.Servers
+	} else if pathItem != nil {
+		servers = pathItem.Servers
+	}
+	if len(servers) == 0 || servers[0] == nil {
+		return ""
+	}
+	server := servers[0]
+	serverURL := server.URL
+	for name, variable := range server.Variables {
+		if variable != nil {
+			serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variable.Default)
+		}
+	}
+	return serverURL
+}
+
+// DeprecatedByExtension names the operation replacing a deprecated one, by operationId or as
+// a method and path such as "GET /v2/users"
+const DeprecatedByExtension = "x-deprecated-by"
+
+// deprecationSentence finds the sentences of descriptions that point to a replacement
+var deprecationSentence = regexp.MustCompile(`(?i)[^.!?\n]*\b(instead|replaced by|superseded by|in favou?r of|migrate to)\b[^.!?\n]*[.!?]?`)
+
+// deprecationHint returns the replacement of a deprecated operation from its x-deprecated-by
+// extension or else the sentence of its description that tells what to use instead
+func deprecationHint(operation *openapi3.Operation) (string, string) {
+	if replacement, ok := operation.Extensions[DeprecatedByExtension].(string); ok && replacement != "" {
+		return strings.TrimSpace(replacement), ""
+	}
+	for _, text := range []string{operation.Description, operation.Summary} {
+		if sentence := deprecationSentence.FindString(text); sentence != "" {
+			return "", strings.TrimSpace(sentence)
+		}
+	}
+	return "", ""
+}
+
 // generateToolName generates a unique tool name from path, method, and operation
 func (p *Parser) generateToolName(path, method string, operation *openapi3.Operation) string {
 	// Always generate name from path and method to ensure uniqueness
@@ -351,15 +550,27 @@ func (p *Parser) generateSnakeCaseName(path, method string) string {
 		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
 			paramName := strings.Trim(segment, "{}")
 			result.WriteString("_by_" + strings.ToLower(paramName))
-		} else {
-			// Add segment in lowercase
-			result.WriteString("_" + strings.ToLower(segment))
+		} else if name := toolNameSegment(segment); name != "" {
+			result.WriteString("_" + name)
 		}
 	}
 
 	return result.String()
 }
 
+// embeddedPathParam matches a path parameter inside a segment, as in the OData key Products({id})
+var embeddedPathParam = regexp.MustCompile(`\{([^}]*)\}`)
+
+// toolNameSeparators matches the runs of characters that become one underscore in tool names
+var toolNameSeparators = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// toolNameSegment converts a path segment to lowercase tool name characters, naming embedded
+// parameters by_<name> and replacing other characters with underscores
+func toolNameSegment(segment string) string {
+	name := embeddedPathParam.ReplaceAllString(strings.ToLower(segment), "_by_${1}_")
+	return strings.Trim(toolNameSeparators.ReplaceAllString(name, "_"), "_")
+}
+
 // generateToolDescription generates a description for the tool
 func (p *Parser) generateToolDescription(operation *openapi3.Operation) string {
 	if operation.Summary != "" {
@@ -385,7 +596,9 @@ func (p *Parser) extractParameters(operation *openapi3.Operation) []types.OpenAP
 			In:          param.Value.In,
 			Description: param.Value.Description,
 			Required:    param.Value.Required,
+			Example:     exampleValue(param.Value.Example, param.Value.Examples),
 		}
+		parameter.ODataString, _ = param.Value.Extensions[types.ODataStringExtension].(bool)
 
 		// Convert schema to interface{} for JSON serialization
 		if param.Value.Schema != nil {
@@ -395,6 +608,10 @@ func (p *Parser) extractParameters(operation *openapi3.Operation) []types.OpenAP
 		parameters = append(parameters, parameter)
 	}
 
+	if p.config.JSONAPI {
+		parameters = groupJSONAPIParameters(parameters)
+	}
+
 	return parameters
 }
 
@@ -407,23 +624,143 @@ func (p *Parser) extractRequestBody(operation *openapi3.Operation) *types.OpenAP
 	requestBody := &types.OpenAPIRequestBody{
 		Description: operation.RequestBody.Value.Description,
 		Required:    operation.RequestBody.Value.Required,
-		Content:     make(map[string]interface{}),
 	}
 
-	// Convert content to interface{} for JSON serialization
-	for mediaType, content := range operation.RequestBody.Value.Content {
-		// Resolve schema references if present
-		if content.Schema != nil {
-			resolvedSchema := p.resolveSchemaRef(content.Schema)
-			requestBody.Content[mediaType] = map[string]interface{}{
-				"schema": resolvedSchema,
+	// Schemas are converted when the tool's input schema is first requested, so specs with
+	// thousands of schemas start without converting ones no client ever lists
+	content := operation.RequestBody.Value.Content
+	requestBody.ResolveContent = sync.OnceValue(func() map[string]interface{} {
+		return p.convertContent(content)
+	})
+
+	return requestBody
+}
+
+// extractOutputSchema returns a function resolving the JSON schema of the operation's first
+// successful response, by status code, or nil when no 2xx response has a JSON schema
+func (p *Parser) extractOutputSchema(operation *openapi3.Operation) func() map[string]interface{} {
+	if operation.Responses == nil {
+		return nil
+	}
+	var statuses []string
+	for status := range operation.Responses.Map() {
+		if strings.HasPrefix(status, "2") {
+			statuses = append(statuses, status)
+		}
+	}
+	// Exact codes sort before the 2XX range
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		response := operation.Responses.Value(status)
+		if response == nil || response.Value == nil {
+			continue
+		}
+		mediaTypes := make([]string, 0, len(response.Value.Content))
+		for mediaType := range response.Value.Content {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+		sort.Strings(mediaTypes)
+		for _, mediaType := range mediaTypes {
+			schema := response.Value.Content[mediaType].Schema
+			if schema == nil || !isJSONMediaType(mediaType) {
+				continue
 			}
+			return sync.OnceValue(func() map[string]interface{} {
+				return p.resolveSchemaRef(schema)
+			})
+		}
+	}
+	return nil
+}
+
+// extractAccept returns the Accept header of the operation's requests, listing the media types
+// of its successful and default responses with JSON first, or "" when none are declared.
+// Other media types are accepted with a lower preference when JSON is offered.
+func extractAccept(operation *openapi3.Operation) string {
+	if operation.Responses == nil {
+		return ""
+	}
+	seen := make(map[string]bool)
+	var jsonTypes, otherTypes []string
+	for status, response := range operation.Responses.Map() {
+		if (!strings.HasPrefix(status, "2") && status != "default") || response == nil || response.Value == nil {
+			continue
+		}
+		for mediaType := range response.Value.Content {
+			mediaType = strings.TrimSpace(mediaType)
+			if mediaType == "" || seen[mediaType] {
+				continue
+			}
+			seen[mediaType] = true
+			if isJSONMediaType(mediaType) {
+				jsonTypes = append(jsonTypes, mediaType)
+			} else {
+				otherTypes = append(otherTypes, mediaType)
+			}
+		}
+	}
+	sort.Slice(jsonTypes, func(i, j int) bool {
+		// application/json before vendor types
+		if (jsonTypes[i] == "application/json") != (jsonTypes[j] == "application/json") {
+			return jsonTypes[i] == "application/json"
+		}
+		return jsonTypes[i] < jsonTypes[j]
+	})
+	sort.Strings(otherTypes)
+	if len(jsonTypes) > 0 {
+		for i, mediaType := range otherTypes {
+			if !strings.Contains(mediaType, ";") {
+				otherTypes[i] = mediaType + ";q=0.9"
+			}
+		}
+	}
+	return strings.Join(append(jsonTypes, otherTypes...), ", ")
+}
+
+// isJSONMediaType reports whether a media type is JSON, including vendor types like application/vnd.api+json
+func isJSONMediaType(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// convertContent converts request body content to interface{} for JSON serialization,
+// resolving schema references and keeping the example of each media type
+func (p *Parser) convertContent(content openapi3.Content) map[string]interface{} {
+	result := make(map[string]interface{}, len(content))
+	for mediaType, mediaTypeContent := range content {
+		if mediaTypeContent.Schema != nil {
+			converted := map[string]interface{}{
+				"schema": p.resolveSchemaRef(mediaTypeContent.Schema),
+			}
+			if example := exampleValue(mediaTypeContent.Example, mediaTypeContent.Examples); example != nil {
+				converted["example"] = example
+			}
+			result[mediaType] = converted
 		} else {
-			requestBody.Content[mediaType] = content
+			result[mediaType] = mediaTypeContent
 		}
 	}
+	return result
+}
 
-	return requestBody
+// exampleValue returns the example of a parameter or media type, or the first of its named
+// examples by name, or nil when it has none
+func exampleValue(example interface{}, examples openapi3.Examples) interface{} {
+	if example != nil {
+		return example
+	}
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ref := examples[name]; ref != nil && ref.Value != nil && ref.Value.Value != nil {
+			return ref.Value.Value
+		}
+	}
+	return nil
 }
 
 // resolveSchemaRef resolves a schema reference to its actual schema definition
@@ -447,8 +784,18 @@ func (p *Parser) resolveSchemaRef(schemaRef *openapi3.SchemaRef) map[string]inte
 	}
 }
 
-// schemaToMap converts an OpenAPI schema to a map for JSON serialization
+// schemaToMap converts an OpenAPI schema to a map for JSON serialization. The map may be shared
+// with other tools referencing the same schema and must not be modified.
 func (p *Parser) schemaToMap(schema *openapi3.Schema) map[string]interface{} {
+	if cached, ok := p.schemaMaps.Load(schema); ok {
+		return cached.(map[string]interface{})
+	}
+	cached, _ := p.schemaMaps.LoadOrStore(schema, p.convertSchema(schema))
+	return cached.(map[string]interface{})
+}
+
+// convertSchema converts an OpenAPI schema to a map, using schemaToMap for nested schemas
+func (p *Parser) convertSchema(schema *openapi3.Schema) map[string]interface{} {
 	result := make(map[string]interface{})
 
 	// Add basic schema properties
@@ -576,19 +923,5 @@ func (p *Parser) shouldIncludePath(path string) bool {
 
 // matchPath matches a pattern against a path, supporting wildcards
 func (p *Parser) matchPath(pattern, path string) bool {
-	// Simple wildcard matching for URL paths
-	if strings.Contains(pattern, "*") {
-		// Convert pattern to regex
-		regexPattern := strings.ReplaceAll(pattern, "*", ".*")
-		regexPattern = "^" + regexPattern + "$"
-
-		matched, err := regexp.MatchString(regexPattern, path)
-		if err != nil {
-			return false
-		}
-		return matched
-	}
-
-	// Exact match
-	return pattern == path
+	return config.MatchPath(pattern, path)
 }
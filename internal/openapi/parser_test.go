@@ -0,0 +1,282 @@
+package openapi
+
+import (
+	"fmt"
+	"testing"
+
+	"mcpify/internal/config"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestGenerateTools_DeterministicOrder(t *testing.T) {
+	spec := &openapi3.T{Paths: openapi3.NewPaths()}
+	for i := 0; i < 50; i++ {
+		spec.Paths.Set(fmt.Sprintf("/items%02d", i), &openapi3.PathItem{
+			Get:    &openapi3.Operation{Responses: openapi3.NewResponses()},
+			Delete: &openapi3.Operation{Responses: openapi3.NewResponses()},
+		})
+	}
+	spec.Paths.Set("/internal/health", &openapi3.PathItem{Get: &openapi3.Operation{Responses: openapi3.NewResponses()}})
+
+	parser := NewParser(&config.OpenAPIConfig{ExcludePaths: []string{"/internal/*"}})
+	for run := 0; run < 5; run++ {
+		tools, err := parser.generateTools(spec)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(tools) != 100 {
+			t.Fatalf("Expected 100 tools, got %d", len(tools))
+		}
+		for i, tool := range tools {
+			path, method := fmt.Sprintf("/items%02d", i/2), []string{"GET", "DELETE"}[i%2]
+			if tool.Path != path || tool.Method != method {
+				t.Fatalf("Expected tool %d to be %s %s, got %s %s", i, method, path, tool.Method, tool.Path)
+			}
+		}
+	}
+}
+
+func TestSchemaToMap_SharedSchemasConvertedOnce(t *testing.T) {
+	user := &openapi3.SchemaRef{Ref: "#/components/schemas/User", Value: &openapi3.Schema{
+		Type: &openapi3.Types{"object"},
+		Properties: openapi3.Schemas{
+			"name": {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+		},
+	}}
+	operation := func() *openapi3.Operation {
+		return &openapi3.Operation{RequestBody: &openapi3.RequestBodyRef{Value: &openapi3.RequestBody{
+			Content: openapi3.NewContentWithJSONSchemaRef(user),
+		}}}
+	}
+
+	parser := NewParser(&config.OpenAPIConfig{})
+	first := parser.extractRequestBody(operation()).MediaTypes()["application/json"].(map[string]interface{})["schema"]
+	second := parser.extractRequestBody(operation()).MediaTypes()["application/json"].(map[string]interface{})["schema"]
+
+	if fmt.Sprintf("%p", first) != fmt.Sprintf("%p", second) {
+		t.Error("Expected operations sharing a schema to share its converted map")
+	}
+	properties := first.(map[string]interface{})["properties"].(map[string]interface{})
+	if properties["name"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("Unexpected converted schema: %+v", first)
+	}
+}
+
+func TestExtractOutputSchema(t *testing.T) {
+	responses := func(entries map[string]*openapi3.Response) *openapi3.Responses {
+		result := openapi3.NewResponsesWithCapacity(len(entries))
+		for status, response := range entries {
+			result.Set(status, &openapi3.ResponseRef{Value: response})
+		}
+		return result
+	}
+	object := func(property string) *openapi3.Schema {
+		return openapi3.NewObjectSchema().WithProperty(property, openapi3.NewStringSchema())
+	}
+
+	tests := []struct {
+		name     string
+		entries  map[string]*openapi3.Response
+		property string // property of the expected schema, empty for none
+	}{
+		{name: "no responses", entries: map[string]*openapi3.Response{}},
+		{name: "no schema", entries: map[string]*openapi3.Response{"204": openapi3.NewResponse()}},
+		{
+			name: "first success",
+			entries: map[string]*openapi3.Response{
+				"201":     openapi3.NewResponse().WithJSONSchema(object("created")),
+				"200":     openapi3.NewResponse().WithJSONSchema(object("ok")),
+				"default": openapi3.NewResponse().WithJSONSchema(object("error")),
+			},
+			property: "ok",
+		},
+		{
+			name: "vendor JSON",
+			entries: map[string]*openapi3.Response{
+				"200": openapi3.NewResponse().WithContent(openapi3.Content{
+					"text/plain":               openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema()),
+					"application/vnd.api+json": openapi3.NewMediaType().WithSchema(object("data")),
+				}),
+			},
+			property: "data",
+		},
+		{name: "errors only", entries: map[string]*openapi3.Response{"404": openapi3.NewResponse().WithJSONSchema(object("error"))}},
+	}
+
+	parser := NewParser(&config.OpenAPIConfig{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolve := parser.extractOutputSchema(&openapi3.Operation{Responses: responses(tt.entries)})
+			if tt.property == "" {
+				if resolve != nil {
+					t.Errorf("Expected no output schema, got %v", resolve())
+				}
+				return
+			}
+			if resolve == nil {
+				t.Fatal("Expected an output schema")
+			}
+			properties, _ := resolve()["properties"].(map[string]interface{})
+			if _, exists := properties[tt.property]; !exists {
+				t.Errorf("Expected property %s, got %v", tt.property, resolve())
+			}
+		})
+	}
+}
+
+func TestExtractAccept(t *testing.T) {
+	response := func(mediaTypes ...string) *openapi3.ResponseRef {
+		content := openapi3.Content{}
+		for _, mediaType := range mediaTypes {
+			content[mediaType] = openapi3.NewMediaType()
+		}
+		return &openapi3.ResponseRef{Value: openapi3.NewResponse().WithContent(content)}
+	}
+
+	tests := []struct {
+		name      string
+		responses map[string]*openapi3.ResponseRef
+		expected  string
+	}{
+		{"no content", map[string]*openapi3.ResponseRef{"204": response()}, ""},
+		{"JSON first", map[string]*openapi3.ResponseRef{
+			"200":     response("text/html", "application/vnd.api+json", "application/json"),
+			"201":     response("application/json"),
+			"default": response("application/problem+json"),
+			"404":     response("text/plain"),
+		}, "application/json, application/problem+json, application/vnd.api+json, text/html;q=0.9"},
+		{"no JSON", map[string]*openapi3.ResponseRef{"200": response("text/csv", "application/pdf")}, "application/pdf, text/csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := openapi3.NewResponsesWithCapacity(len(tt.responses))
+			for status, ref := range tt.responses {
+				responses.Set(status, ref)
+			}
+			if accept := extractAccept(&openapi3.Operation{Responses: responses}); accept != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, accept)
+			}
+		})
+	}
+}
+
+func TestGenerateTools_OperationBaseURL(t *testing.T) {
+	operationServers := openapi3.Servers{{
+		URL:       "https://{region}.files.example.com",
+		Variables: map[string]*openapi3.ServerVariable{"region": {Default: "eu"}},
+	}}
+	spec := &openapi3.T{Paths: openapi3.NewPaths()}
+	spec.Paths.Set("/files", &openapi3.PathItem{
+		Get: &openapi3.Operation{Responses: openapi3.NewResponses(), Servers: &operationServers},
+	})
+	spec.Paths.Set("/reports", &openapi3.PathItem{
+		Servers: openapi3.Servers{{URL: "/v2"}},
+		Get:     &openapi3.Operation{Responses: openapi3.NewResponses()},
+	})
+	spec.Paths.Set("/uploads/images", &openapi3.PathItem{
+		Post: &openapi3.Operation{Responses: openapi3.NewResponses(), Servers: &operationServers},
+	})
+	spec.Paths.Set("/users", &openapi3.PathItem{
+		Get:  &openapi3.Operation{Responses: openapi3.NewResponses()},
+		Post: &openapi3.Operation{Responses: openapi3.NewResponses(), Tags: []string{"admin"}},
+	})
+
+	parser := NewParser(&config.OpenAPIConfig{Servers: []config.ServerRoute{
+		{BaseURL: "https://upload.example.com", Paths: []string{"/uploads/*"}},
+		{BaseURL: "https://admin.example.com", Tags: []string{"admin"}},
+	}})
+	tools, err := parser.generateTools(spec)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"GET /files":           "https://eu.files.example.com",
+		"GET /reports":         "/v2",
+		"POST /uploads/images": "https://upload.example.com", // Configured routes win over the spec
+		"GET /users":           "",
+		"POST /users":          "https://admin.example.com",
+	}
+	for _, tool := range tools {
+		if want := expected[tool.Method+" "+tool.Path]; tool.BaseURL != want {
+			t.Errorf("%s %s: expected base URL %q, got %q", tool.Method, tool.Path, want, tool.BaseURL)
+		}
+	}
+}
+
+func TestGenerateTools_Deprecation(t *testing.T) {
+	spec := &openapi3.T{Paths: openapi3.NewPaths()}
+	spec.Paths.Set("/v1/users", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			OperationID: "listUsersV1",
+			Deprecated:  true,
+			Extensions:  map[string]interface{}{DeprecatedByExtension: "listUsers"},
+			Responses:   openapi3.NewResponses(),
+		},
+		Post: &openapi3.Operation{
+			Summary:     "Create a user",
+			Description: "Creates a user account. Use POST /v2/users instead, which validates emails.",
+			Deprecated:  true,
+			Responses:   openapi3.NewResponses(),
+		},
+		Delete: &openapi3.Operation{Deprecated: true, Responses: openapi3.NewResponses()},
+	})
+	spec.Paths.Set("/v2/users", &openapi3.PathItem{
+		Get: &openapi3.Operation{OperationID: "listUsers", Responses: openapi3.NewResponses()},
+	})
+
+	tools, err := NewParser(&config.OpenAPIConfig{}).generateTools(spec)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := map[string][2]string{
+		"GET /v1/users":    {"get_v2_users", ""},
+		"POST /v1/users":   {"", "Use POST /v2/users instead, which validates emails."},
+		"DELETE /v1/users": {"", ""},
+	}
+	for _, tool := range tools {
+		want, ok := expected[tool.Method+" "+tool.Path]
+		if !ok {
+			if tool.Deprecated {
+				t.Errorf("%s %s: expected the operation not to be deprecated", tool.Method, tool.Path)
+			}
+			continue
+		}
+		if !tool.Deprecated || tool.DeprecatedBy != want[0] || tool.DeprecationNote != want[1] {
+			t.Errorf("%s %s: expected deprecation %q/%q, got %v %q/%q", tool.Method, tool.Path,
+				want[0], want[1], tool.Deprecated, tool.DeprecatedBy, tool.DeprecationNote)
+		}
+	}
+}
+
+func TestExtractExamples(t *testing.T) {
+	operation := &openapi3.Operation{
+		Parameters: openapi3.Parameters{
+			{Value: &openapi3.Parameter{Name: "limit", In: "query", Example: 10}},
+			{Value: &openapi3.Parameter{Name: "sort", In: "query", Examples: openapi3.Examples{
+				"newest": {Value: openapi3.NewExample("-created")},
+				"name":   {Value: openapi3.NewExample("name")},
+			}}},
+			{Value: &openapi3.Parameter{Name: "cursor", In: "query"}},
+		},
+		RequestBody: &openapi3.RequestBodyRef{Value: &openapi3.RequestBody{Content: openapi3.Content{
+			"application/json": openapi3.NewMediaType().
+				WithSchema(openapi3.NewObjectSchema()).
+				WithExample("ada", map[string]interface{}{"name": "Ada"}),
+		}}},
+	}
+
+	parser := NewParser(&config.OpenAPIConfig{})
+	parameters := parser.extractParameters(operation)
+	for i, expected := range []interface{}{10, "name", nil} {
+		if parameters[i].Example != expected {
+			t.Errorf("Expected example %v for %s, got %v", expected, parameters[i].Name, parameters[i].Example)
+		}
+	}
+	body := parser.extractRequestBody(operation).Example()
+	if fmt.Sprint(body) != "map[name:Ada]" {
+		t.Errorf("Expected the body example, got %v", body)
+	}
+}
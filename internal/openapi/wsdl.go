@@ -0,0 +1,511 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"mcpify/internal/types"
+)
+
+// Namespaces of the SOAP bindings of WSDL 1.1
+const (
+	wsdlSOAP11Namespace = "http://schemas.xmlsoap.org/wsdl/soap/"
+	wsdlSOAP12Namespace = "http://schemas.xmlsoap.org/wsdl/soap12/"
+)
+
+// maxXSDDepth bounds the nesting of converted XML schema types, which may be recursive
+const maxXSDDepth = 8
+
+// wsdlDefinitions is the root of a WSDL 1.1 document. Elements are matched by local name, so
+// any namespace prefixes work.
+type wsdlDefinitions struct {
+	TargetNamespace string         `xml:"targetNamespace,attr"`
+	Schemas         []xsdSchema    `xml:"types>schema"`
+	Messages        []wsdlMessage  `xml:"message"`
+	PortTypes       []wsdlPortType `xml:"portType"`
+	Bindings        []wsdlBinding  `xml:"binding"`
+	Services        []wsdlService  `xml:"service"`
+}
+
+type wsdlMessage struct {
+	Name  string     `xml:"name,attr"`
+	Parts []wsdlPart `xml:"part"`
+}
+
+type wsdlPart struct {
+	Name    string `xml:"name,attr"`
+	Element string `xml:"element,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+type wsdlPortType struct {
+	Name       string          `xml:"name,attr"`
+	Operations []wsdlOperation `xml:"operation"`
+}
+
+type wsdlOperation struct {
+	Name          string `xml:"name,attr"`
+	Documentation string `xml:"documentation"`
+	Input         struct {
+		Message string `xml:"message,attr"`
+	} `xml:"input"`
+}
+
+type wsdlBinding struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+	SOAP struct {
+		Style string `xml:"style,attr"`
+	} `xml:"binding"`
+	Operations []wsdlBindingOperation `xml:"operation"`
+}
+
+type wsdlBindingOperation struct {
+	Name string `xml:"name,attr"`
+	SOAP struct {
+		Action string `xml:"soapAction,attr"`
+		Style  string `xml:"style,attr"`
+	} `xml:"operation"`
+	Input struct {
+		Body struct {
+			Namespace string `xml:"namespace,attr"`
+		} `xml:"body"`
+	} `xml:"input"`
+}
+
+type wsdlService struct {
+	Name  string     `xml:"name,attr"`
+	Ports []wsdlPort `xml:"port"`
+}
+
+type wsdlPort struct {
+	Name    string `xml:"name,attr"`
+	Binding string `xml:"binding,attr"`
+	Address struct {
+		XMLName  xml.Name
+		Location string `xml:"location,attr"`
+	} `xml:"address"`
+}
+
+type xsdSchema struct {
+	TargetNamespace    string           `xml:"targetNamespace,attr"`
+	ElementFormDefault string           `xml:"elementFormDefault,attr"`
+	Elements           []xsdElement     `xml:"element"`
+	ComplexTypes       []xsdComplexType `xml:"complexType"`
+	SimpleTypes        []xsdSimpleType  `xml:"simpleType"`
+}
+
+type xsdElement struct {
+	Name          string          `xml:"name,attr"`
+	Type          string          `xml:"type,attr"`
+	Ref           string          `xml:"ref,attr"`
+	MinOccurs     string          `xml:"minOccurs,attr"`
+	MaxOccurs     string          `xml:"maxOccurs,attr"`
+	Documentation string          `xml:"annotation>documentation"`
+	ComplexType   *xsdComplexType `xml:"complexType"`
+	SimpleType    *xsdSimpleType  `xml:"simpleType"`
+}
+
+type xsdComplexType struct {
+	Name     string       `xml:"name,attr"`
+	Sequence []xsdElement `xml:"sequence>element"`
+	All      []xsdElement `xml:"all>element"`
+	Choice   []xsdElement `xml:"choice>element"`
+}
+
+// elements returns the child elements of the type, in document order
+func (c *xsdComplexType) elements() []xsdElement {
+	elements := append([]xsdElement{}, c.Sequence...)
+	elements = append(elements, c.All...)
+	return append(elements, c.Choice...)
+}
+
+type xsdSimpleType struct {
+	Name        string `xml:"name,attr"`
+	Restriction struct {
+		Base         string `xml:"base,attr"`
+		Enumerations []struct {
+			Value string `xml:"value,attr"`
+		} `xml:"enumeration"`
+	} `xml:"restriction"`
+}
+
+// xsdGlobalElement is a top-level schema element with the schema defining it
+type xsdGlobalElement struct {
+	element xsdElement
+	schema  *xsdSchema
+}
+
+// xsdIndex looks up the global elements and types of the WSDL's schemas by local name
+type xsdIndex struct {
+	elements     map[string]xsdGlobalElement
+	complexTypes map[string]*xsdComplexType
+	simpleTypes  map[string]*xsdSimpleType
+}
+
+func newXSDIndex(schemas []xsdSchema) *xsdIndex {
+	index := &xsdIndex{
+		elements:     make(map[string]xsdGlobalElement),
+		complexTypes: make(map[string]*xsdComplexType),
+		simpleTypes:  make(map[string]*xsdSimpleType),
+	}
+	for i := range schemas {
+		schema := &schemas[i]
+		for _, element := range schema.Elements {
+			index.elements[element.Name] = xsdGlobalElement{element: element, schema: schema}
+		}
+		for j := range schema.ComplexTypes {
+			index.complexTypes[schema.ComplexTypes[j].Name] = &schema.ComplexTypes[j]
+		}
+		for j := range schema.SimpleTypes {
+			index.simpleTypes[schema.SimpleTypes[j].Name] = &schema.SimpleTypes[j]
+		}
+	}
+	return index
+}
+
+// isWSDL reports whether the content is a WSDL document rather than an OpenAPI specification
+func isWSDL(content []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(content), []byte("<")) {
+		return false
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "definitions"
+		}
+	}
+}
+
+// generateWSDLTools generates a tool for each operation of the SOAP ports of a WSDL 1.1
+// document. Tools call the path of the port's address on the configured base URL.
+func (p *Parser) generateWSDLTools(content []byte) ([]types.APITool, error) {
+	var defs wsdlDefinitions
+	if err := xml.Unmarshal(content, &defs); err != nil {
+		return nil, fmt.Errorf("failed to parse WSDL: %w", err)
+	}
+	index := newXSDIndex(defs.Schemas)
+
+	var tools []types.APITool
+	generated := make(map[string]bool)
+	for _, service := range defs.Services {
+		for _, port := range service.Ports {
+			version := soapVersion(port.Address.XMLName.Space)
+			if version == "" {
+				// HTTP bindings describe plain HTTP operations, not SOAP
+				continue
+			}
+			binding := defs.binding(localName(port.Binding))
+			if binding == nil {
+				return nil, fmt.Errorf("port %s: unknown binding %s", port.Name, port.Binding)
+			}
+			portType := defs.portType(localName(binding.Type))
+			if portType == nil {
+				return nil, fmt.Errorf("binding %s: unknown port type %s", binding.Name, binding.Type)
+			}
+			address, err := url.Parse(port.Address.Location)
+			if err != nil {
+				return nil, fmt.Errorf("port %s: invalid address %q: %w", port.Name, port.Address.Location, err)
+			}
+			path := address.Path
+			if path == "" {
+				path = "/"
+			}
+			if p.shouldExcludePath(path) || !p.shouldIncludePath(path) {
+				continue
+			}
+
+			for _, bindingOp := range binding.Operations {
+				// Services commonly offer the same operations over SOAP 1.1 and 1.2 ports
+				name := p.soapToolName(bindingOp.Name)
+				if generated[name] {
+					continue
+				}
+				operation := portType.operation(bindingOp.Name)
+				if operation == nil {
+					return nil, fmt.Errorf("binding %s: operation %s is not in port type %s", binding.Name, bindingOp.Name, portType.Name)
+				}
+				style := bindingOp.SOAP.Style
+				if style == "" {
+					style = binding.SOAP.Style
+				}
+				tool, err := p.generateSOAPTool(&defs, index, name, path, version, style, bindingOp, operation)
+				if err != nil {
+					return nil, fmt.Errorf("failed to generate tool for SOAP operation %s: %w", bindingOp.Name, err)
+				}
+				tools = append(tools, tool)
+				generated[name] = true
+			}
+		}
+	}
+	log.Printf("Generated %d tools from WSDL", len(tools))
+	return tools, nil
+}
+
+// generateSOAPTool generates the tool of one operation. Document style operations take the
+// children of the input message's element as properties of the body argument; RPC style
+// operations take the parts of the input message, wrapped in an element named after the
+// operation.
+func (p *Parser) generateSOAPTool(defs *wsdlDefinitions, index *xsdIndex, name, path, version, style string, bindingOp wsdlBindingOperation, operation *wsdlOperation) (types.APITool, error) {
+	soap := &types.SOAPOperation{Version: version, Action: bindingOp.SOAP.Action}
+	properties := make(map[string]interface{})
+	var required []string
+
+	message := defs.message(localName(operation.Input.Message))
+	if message == nil && operation.Input.Message != "" {
+		return types.APITool{}, fmt.Errorf("unknown input message %s", operation.Input.Message)
+	}
+
+	if style == "rpc" {
+		soap.Element = operation.Name
+		soap.Namespace = bindingOp.Input.Body.Namespace
+		if soap.Namespace == "" {
+			soap.Namespace = defs.TargetNamespace
+		}
+		if message != nil {
+			for _, part := range message.Parts {
+				properties[part.Name] = index.typeSchema(part.Type, 0)
+				required = append(required, part.Name)
+				soap.Parts = append(soap.Parts, part.Name)
+			}
+		}
+	} else if message != nil && len(message.Parts) > 0 {
+		part := message.Parts[0]
+		global, exists := index.elements[localName(part.Element)]
+		if !exists {
+			return types.APITool{}, fmt.Errorf("unknown element %s of message %s", part.Element, message.Name)
+		}
+		soap.Element = global.element.Name
+		soap.Namespace = global.schema.TargetNamespace
+		soap.Qualified = global.schema.ElementFormDefault == "qualified"
+		if complexType := index.elementType(global.element); complexType != nil {
+			for _, child := range complexType.elements() {
+				child = index.resolve(child)
+				properties[child.Name] = index.elementSchema(child, 0)
+				if child.MinOccurs != "0" {
+					required = append(required, child.Name)
+				}
+				soap.Parts = append(soap.Parts, child.Name)
+			}
+		}
+	} else {
+		soap.Element = operation.Name
+		soap.Namespace = defs.TargetNamespace
+	}
+
+	description := strings.TrimSpace(operation.Documentation)
+	if description == "" {
+		description = "SOAP operation " + operation.Name
+	}
+
+	// The elements of the request are the properties of the body argument
+	body := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		body["required"] = required
+	}
+	mediaType := "text/xml"
+	if version == "1.2" {
+		mediaType = "application/soap+xml"
+	}
+	return types.APITool{
+		Name:        name,
+		Description: description,
+		Method:      "POST",
+		Path:        path,
+		RequestBody: &types.OpenAPIRequestBody{
+			Required: len(required) > 0,
+			Content:  map[string]interface{}{mediaType: map[string]interface{}{"schema": body}},
+		},
+		SOAP: soap,
+	}, nil
+}
+
+// soapToolName generates a snake_case tool name from a SOAP operation name, e.g. GetUserByID
+// becomes get_user_by_id
+func (p *Parser) soapToolName(operation string) string {
+	var result strings.Builder
+	runes := []rune(operation)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			// Start a word at a lower-to-upper change, or at the last capital of an acronym
+			if unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+				result.WriteRune('_')
+			}
+		}
+		result.WriteRune(unicode.ToLower(r))
+	}
+	if p.config.ToolPrefix != "" {
+		return p.config.ToolPrefix + "_" + result.String()
+	}
+	return result.String()
+}
+
+// resolve returns the global element an element refers to with ref, or the element itself
+func (x *xsdIndex) resolve(element xsdElement) xsdElement {
+	if element.Ref == "" {
+		return element
+	}
+	global, exists := x.elements[localName(element.Ref)]
+	if !exists {
+		return xsdElement{Name: localName(element.Ref), MinOccurs: element.MinOccurs, MaxOccurs: element.MaxOccurs}
+	}
+	resolved := global.element
+	resolved.MinOccurs, resolved.MaxOccurs = element.MinOccurs, element.MaxOccurs
+	return resolved
+}
+
+// elementType returns the complex type of an element, declared inline or by name
+func (x *xsdIndex) elementType(element xsdElement) *xsdComplexType {
+	if element.ComplexType != nil {
+		return element.ComplexType
+	}
+	return x.complexTypes[localName(element.Type)]
+}
+
+// elementSchema converts an element to a JSON schema, as an array when it may repeat
+func (x *xsdIndex) elementSchema(element xsdElement, depth int) map[string]interface{} {
+	var schema map[string]interface{}
+	switch {
+	case element.ComplexType != nil:
+		schema = x.complexSchema(element.ComplexType, depth)
+	case element.SimpleType != nil:
+		schema = simpleSchema(element.SimpleType)
+	default:
+		schema = x.typeSchema(element.Type, depth)
+	}
+	if documentation := strings.TrimSpace(element.Documentation); documentation != "" {
+		schema["description"] = documentation
+	}
+	if maxOccurs, err := strconv.Atoi(element.MaxOccurs); element.MaxOccurs == "unbounded" || (err == nil && maxOccurs > 1) {
+		return map[string]interface{}{"type": "array", "items": schema}
+	}
+	return schema
+}
+
+// typeSchema converts a named schema type or XML Schema built-in type to a JSON schema
+func (x *xsdIndex) typeSchema(name string, depth int) map[string]interface{} {
+	local := localName(name)
+	if complexType, exists := x.complexTypes[local]; exists {
+		return x.complexSchema(complexType, depth)
+	}
+	if simpleType, exists := x.simpleTypes[local]; exists {
+		return simpleSchema(simpleType)
+	}
+	return map[string]interface{}{"type": xsdBuiltinType(local)}
+}
+
+// complexSchema converts a complex type to an object schema
+func (x *xsdIndex) complexSchema(complexType *xsdComplexType, depth int) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object"}
+	if depth >= maxXSDDepth {
+		return schema
+	}
+	properties := make(map[string]interface{})
+	var required []string
+	for _, child := range complexType.elements() {
+		child = x.resolve(child)
+		properties[child.Name] = x.elementSchema(child, depth+1)
+		if child.MinOccurs != "0" {
+			required = append(required, child.Name)
+		}
+	}
+	schema["properties"] = properties
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// simpleSchema converts a simple type restriction, with its enumeration if any
+func simpleSchema(simpleType *xsdSimpleType) map[string]interface{} {
+	schema := map[string]interface{}{"type": xsdBuiltinType(localName(simpleType.Restriction.Base))}
+	if len(simpleType.Restriction.Enumerations) > 0 {
+		values := make([]interface{}, len(simpleType.Restriction.Enumerations))
+		for i, enumeration := range simpleType.Restriction.Enumerations {
+			values[i] = enumeration.Value
+		}
+		schema["enum"] = values
+	}
+	return schema
+}
+
+// xsdBuiltinType maps an XML Schema built-in type to a JSON schema type
+func xsdBuiltinType(name string) string {
+	switch name {
+	case "int", "integer", "long", "short", "byte", "unsignedInt", "unsignedLong", "unsignedShort",
+		"unsignedByte", "positiveInteger", "nonNegativeInteger", "negativeInteger", "nonPositiveInteger":
+		return "integer"
+	case "decimal", "double", "float":
+		return "number"
+	case "boolean":
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// soapVersion returns the SOAP version of a port address namespace, or "" for other bindings
+func soapVersion(namespace string) string {
+	switch namespace {
+	case wsdlSOAP11Namespace:
+		return "1.1"
+	case wsdlSOAP12Namespace:
+		return "1.2"
+	default:
+		return ""
+	}
+}
+
+// localName strips the namespace prefix of a qualified name such as tns:GetUser
+func localName(qualified string) string {
+	if i := strings.LastIndex(qualified, ":"); i >= 0 {
+		return qualified[i+1:]
+	}
+	return qualified
+}
+
+func (d *wsdlDefinitions) message(name string) *wsdlMessage {
+	for i := range d.Messages {
+		if d.Messages[i].Name == name {
+			return &d.Messages[i]
+		}
+	}
+	return nil
+}
+
+func (d *wsdlDefinitions) portType(name string) *wsdlPortType {
+	for i := range d.PortTypes {
+		if d.PortTypes[i].Name == name {
+			return &d.PortTypes[i]
+		}
+	}
+	return nil
+}
+
+func (d *wsdlDefinitions) binding(name string) *wsdlBinding {
+	for i := range d.Bindings {
+		if d.Bindings[i].Name == name {
+			return &d.Bindings[i]
+		}
+	}
+	return nil
+}
+
+func (p *wsdlPortType) operation(name string) *wsdlOperation {
+	for i := range p.Operations {
+		if p.Operations[i].Name == name {
+			return &p.Operations[i]
+		}
+	}
+	return nil
+}
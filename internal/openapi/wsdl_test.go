@@ -0,0 +1,151 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"mcpify/internal/config"
+)
+
+// testWSDL describes a document/literal service offered over SOAP 1.1 and 1.2 ports
+const testWSDL = `<?xml version="1.0" encoding="utf-8"?>
+<wsdl:definitions xmlns:wsdl="http://schemas.xmlsoap.org/wsdl/"
+    xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/"
+    xmlns:soap12="http://schemas.xmlsoap.org/wsdl/soap12/"
+    xmlns:xs="http://www.w3.org/2001/XMLSchema"
+    xmlns:tns="http://example.com/users"
+    targetNamespace="http://example.com/users">
+  <wsdl:types>
+    <xs:schema targetNamespace="http://example.com/users" elementFormDefault="qualified">
+      <xs:simpleType name="Status">
+        <xs:restriction base="xs:string">
+          <xs:enumeration value="active"/>
+          <xs:enumeration value="disabled"/>
+        </xs:restriction>
+      </xs:simpleType>
+      <xs:complexType name="Address">
+        <xs:sequence>
+          <xs:element name="City" type="xs:string"/>
+        </xs:sequence>
+      </xs:complexType>
+      <xs:element name="FindUsers">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Name" type="xs:string">
+              <xs:annotation><xs:documentation>Name prefix</xs:documentation></xs:annotation>
+            </xs:element>
+            <xs:element name="Limit" type="xs:int" minOccurs="0"/>
+            <xs:element name="Status" type="tns:Status" minOccurs="0"/>
+            <xs:element name="Addresses" type="tns:Address" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="FindUsersResponse">
+        <xs:complexType/>
+      </xs:element>
+    </xs:schema>
+  </wsdl:types>
+  <wsdl:message name="FindUsersIn">
+    <wsdl:part name="parameters" element="tns:FindUsers"/>
+  </wsdl:message>
+  <wsdl:message name="FindUsersOut">
+    <wsdl:part name="parameters" element="tns:FindUsersResponse"/>
+  </wsdl:message>
+  <wsdl:portType name="UsersPort">
+    <wsdl:operation name="FindUsers">
+      <wsdl:documentation>Find users by name</wsdl:documentation>
+      <wsdl:input message="tns:FindUsersIn"/>
+      <wsdl:output message="tns:FindUsersOut"/>
+    </wsdl:operation>
+  </wsdl:portType>
+  <wsdl:binding name="UsersSoap" type="tns:UsersPort">
+    <soap:binding transport="http://schemas.xmlsoap.org/soap/http" style="document"/>
+    <wsdl:operation name="FindUsers">
+      <soap:operation soapAction="http://example.com/users/FindUsers"/>
+      <wsdl:input><soap:body use="literal"/></wsdl:input>
+    </wsdl:operation>
+  </wsdl:binding>
+  <wsdl:binding name="UsersSoap12" type="tns:UsersPort">
+    <soap12:binding transport="http://schemas.xmlsoap.org/soap/http" style="document"/>
+    <wsdl:operation name="FindUsers">
+      <soap12:operation soapAction="http://example.com/users/FindUsers"/>
+      <wsdl:input><soap12:body use="literal"/></wsdl:input>
+    </wsdl:operation>
+  </wsdl:binding>
+  <wsdl:service name="Users">
+    <wsdl:port name="UsersSoap" binding="tns:UsersSoap">
+      <soap:address location="http://example.com/services/Users.asmx"/>
+    </wsdl:port>
+    <wsdl:port name="UsersSoap12" binding="tns:UsersSoap12">
+      <soap12:address location="http://example.com/services/Users.asmx"/>
+    </wsdl:port>
+  </wsdl:service>
+</wsdl:definitions>`
+
+func TestParseSpec_WSDL(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "users.wsdl")
+	if err := os.WriteFile(specPath, []byte(testWSDL), 0o600); err != nil {
+		t.Fatalf("Failed to write WSDL: %v", err)
+	}
+
+	tools, err := NewParser(&config.OpenAPIConfig{SpecPath: specPath}).ParseSpec()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tools) != 1 {
+		t.Fatalf("Expected one tool for both ports, got %d", len(tools))
+	}
+
+	tool := tools[0]
+	if tool.Name != "find_users" || tool.Method != "POST" || tool.Path != "/services/Users.asmx" {
+		t.Errorf("Unexpected tool %s %s %s", tool.Name, tool.Method, tool.Path)
+	}
+	if tool.Description != "Find users by name" {
+		t.Errorf("Expected the operation documentation as description, got %q", tool.Description)
+	}
+	soap := tool.SOAP
+	if soap == nil || soap.Version != "1.1" || soap.Action != "http://example.com/users/FindUsers" ||
+		soap.Element != "FindUsers" || soap.Namespace != "http://example.com/users" || !soap.Qualified {
+		t.Fatalf("Unexpected SOAP operation %+v", soap)
+	}
+	if !reflect.DeepEqual(soap.Parts, []string{"Name", "Limit", "Status", "Addresses"}) {
+		t.Errorf("Expected the parts in sequence order, got %v", soap.Parts)
+	}
+
+	if len(tool.Parameters) != 0 || tool.RequestBody == nil || !tool.RequestBody.Required {
+		t.Fatalf("Expected the elements as a required body, got %+v and %+v", tool.Parameters, tool.RequestBody)
+	}
+	body := tool.RequestBody.Schema()
+	if !reflect.DeepEqual(body["required"], []string{"Name"}) {
+		t.Errorf("Expected only Name to be required, got %v", body["required"])
+	}
+	expected := map[string]interface{}{
+		"Name":   map[string]interface{}{"type": "string", "description": "Name prefix"},
+		"Limit":  map[string]interface{}{"type": "integer"},
+		"Status": map[string]interface{}{"type": "string", "enum": []interface{}{"active", "disabled"}},
+		"Addresses": map[string]interface{}{"type": "array", "items": map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"City": map[string]interface{}{"type": "string"}},
+			"required":   []string{"City"},
+		}},
+	}
+	if !reflect.DeepEqual(body["properties"], expected) {
+		t.Errorf("Unexpected body properties: %v", body["properties"])
+	}
+}
+
+func TestSOAPToolName(t *testing.T) {
+	parser := NewParser(&config.OpenAPIConfig{})
+	for operation, expected := range map[string]string{
+		"FindUsers":   "find_users",
+		"GetUserByID": "get_user_by_id",
+		"getHTTPLog":  "get_http_log",
+		"ping":        "ping",
+	} {
+		if name := parser.soapToolName(operation); name != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, operation, name)
+		}
+	}
+}
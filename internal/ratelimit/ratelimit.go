@@ -0,0 +1,90 @@
+// Package ratelimit provides request rate limiting for tool calls
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter decides whether a request identified by key may proceed
+type Limiter interface {
+	Allow(key string) bool
+}
+
+// bucket is a token bucket for a single key
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryLimiter is an in-process token bucket limiter allowing a number of requests per
+// minute for each key, with bursts up to the full per-minute allowance. Buckets left idle
+// long enough to refill are dropped, so keys such as client addresses do not accumulate.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens added per second
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryLimiter creates a limiter allowing requestsPerMinute requests per key
+func NewMemoryLimiter(requestsPerMinute int) *MemoryLimiter {
+	return &MemoryLimiter{
+		rate:    float64(requestsPerMinute) / 60,
+		burst:   float64(requestsPerMinute),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow consumes a token for key, returning false when the key has exhausted its allowance
+func (l *MemoryLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	// Refill for the time elapsed since the last request
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refillPeriod is the time an empty bucket takes to fill up
+func (l *MemoryLimiter) refillPeriod() time.Duration {
+	if l.rate <= 0 {
+		return time.Minute
+	}
+	return time.Duration(l.burst / l.rate * float64(time.Second))
+}
+
+// sweep drops, at most once per refill period, the buckets idle for a whole refill period:
+// they are full again, so they are the same as the bucket a new key gets
+func (l *MemoryLimiter) sweep(now time.Time) {
+	period := l.refillPeriod()
+	if now.Sub(l.lastSweep) < period {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= period {
+			delete(l.buckets, key)
+		}
+	}
+}
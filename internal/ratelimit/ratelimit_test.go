@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter(3)
+	limiter.now = func() time.Time { return now }
+
+	// The full allowance is available as a burst
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow("tool") {
+			t.Fatalf("Expected request %d to be allowed", i+1)
+		}
+	}
+	if limiter.Allow("tool") {
+		t.Error("Expected request beyond the allowance to be rejected")
+	}
+
+	// Keys are limited independently
+	if !limiter.Allow("other") {
+		t.Error("Expected a different key to be allowed")
+	}
+
+	// Tokens refill over time: 3 per minute is one every 20 seconds
+	now = now.Add(19 * time.Second)
+	if limiter.Allow("tool") {
+		t.Error("Expected request before refill to be rejected")
+	}
+	now = now.Add(time.Second)
+	if !limiter.Allow("tool") {
+		t.Error("Expected request after refill to be allowed")
+	}
+}
+
+func TestMemoryLimiter_EvictsIdleBuckets(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("10.0.0.1")
+	limiter.Allow("10.0.0.1")
+	limiter.Allow("10.0.0.2")
+
+	// 10.0.0.2 stays active while 10.0.0.1 is idle for a whole refill period
+	now = now.Add(30 * time.Second)
+	limiter.Allow("10.0.0.2")
+	now = now.Add(30 * time.Second)
+	limiter.Allow("10.0.0.3")
+	if _, ok := limiter.buckets["10.0.0.1"]; ok {
+		t.Error("Expected the idle bucket to be dropped")
+	}
+	if len(limiter.buckets) != 2 {
+		t.Errorf("Expected the active buckets to be kept, got %d", len(limiter.buckets))
+	}
+
+	// A dropped key gets its full allowance back, as it would have by refilling
+	if !limiter.Allow("10.0.0.1") || !limiter.Allow("10.0.0.1") || limiter.Allow("10.0.0.1") {
+		t.Error("Expected a dropped key to get exactly its full allowance")
+	}
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"mcpify/internal/redis"
+)
+
+// window is the period the per-minute allowance of the Redis limiter applies to
+const window = time.Minute
+
+// RedisLimiter is a limiter shared by every replica using the same Redis server, so a key's
+// allowance covers its requests to all of them. It counts requests per key and minute, and
+// weighs the previous minute's count by the part of it still inside the sliding minute.
+type RedisLimiter struct {
+	client *redis.Client
+	prefix string
+	limit  float64
+	now    func() time.Time
+}
+
+// NewRedisLimiter creates a limiter allowing requestsPerMinute requests per key, counted under
+// Redis keys starting with prefix
+func NewRedisLimiter(client *redis.Client, prefix string, requestsPerMinute int) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		prefix: prefix,
+		limit:  float64(requestsPerMinute),
+		now:    time.Now,
+	}
+}
+
+// Allow counts a request for key, returning false when the key has exhausted its allowance.
+// Rejected requests count too, so clients retrying at once stay limited. The limiter fails
+// open: when Redis cannot be reached or answers with an error, the request is allowed and a
+// warning is logged, rather than failing every call.
+func (l *RedisLimiter) Allow(key string) bool {
+	now := l.now()
+	current := now.UnixNano() / int64(window)
+	elapsed := float64(now.UnixNano()%int64(window)) / float64(window)
+
+	count, previous, err := l.counts(key, current)
+	if err != nil {
+		log.Printf("WARNING: Rate limit store unavailable, allowing request: %v", err)
+		return true
+	}
+	return previous*(1-elapsed)+count <= l.limit
+}
+
+// counts increments the count of key in the current window and returns it with the count of
+// the previous window
+func (l *RedisLimiter) counts(key string, current int64) (float64, float64, error) {
+	ctx := context.Background()
+	counter := l.prefix + key + ":" + strconv.FormatInt(current, 10)
+	// The counter is created with its expiry before it is incremented, so it can never be left
+	// without one. It is read as the previous window during the next one.
+	if _, err := l.client.Do(ctx, "SET", counter, "0", "NX", "PX", strconv.FormatInt((2*window).Milliseconds(), 10)); err != nil {
+		return 0, 0, err
+	}
+	reply, err := l.client.Do(ctx, "INCR", counter)
+	if err != nil {
+		return 0, 0, err
+	}
+	count, _ := reply.(int64)
+
+	reply, err = l.client.Do(ctx, "GET", l.prefix+key+":"+strconv.FormatInt(current-1, 10))
+	if err != nil {
+		return 0, 0, err
+	}
+	previous := 0.0
+	if text, ok := reply.(string); ok {
+		if previous, err = strconv.ParseFloat(text, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	return float64(count), previous, nil
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/redis"
+	"mcpify/internal/redis/redistest"
+)
+
+func newTestRedisLimiter(t *testing.T, url string, requestsPerMinute int) *RedisLimiter {
+	t.Helper()
+	client, err := redis.New(config.RedisConfig{URL: url})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return NewRedisLimiter(client, "test:", requestsPerMinute)
+}
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	server := redistest.NewServer(t)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Two replicas share the allowance through the same server
+	first := newTestRedisLimiter(t, server.URL, 3)
+	second := newTestRedisLimiter(t, server.URL, 3)
+	first.now = func() time.Time { return now }
+	second.now = first.now
+
+	for i, limiter := range []*RedisLimiter{first, second, first} {
+		if !limiter.Allow("client") {
+			t.Fatalf("Expected request %d to be allowed", i+1)
+		}
+	}
+	if second.Allow("client") {
+		t.Error("Expected request beyond the shared allowance to be rejected")
+	}
+
+	// Keys are limited independently
+	if !second.Allow("other") {
+		t.Error("Expected a different key to be allowed")
+	}
+
+	// A third into the next minute, the previous minute's four requests still weigh over 2
+	now = now.Add(80 * time.Second)
+	if first.Allow("client") {
+		t.Error("Expected request while the previous minute still weighs to be rejected")
+	}
+
+	// Near the end of the minute they weigh less than one
+	now = now.Add(35 * time.Second)
+	if !first.Allow("client") {
+		t.Error("Expected request once the previous minute has slid out to be allowed")
+	}
+
+	for _, key := range server.Keys() {
+		if key[:5] != "test:" {
+			t.Errorf("Expected counters under the key prefix, got %s", key)
+		}
+		// Every counter expires, even one whose first increment was its last
+		reply, err := first.client.Do(context.Background(), "PTTL", key)
+		if err != nil {
+			t.Fatalf("Failed to read the expiry of %s: %v", key, err)
+		}
+		if ttl, _ := reply.(int64); ttl <= 0 || ttl > (2*window).Milliseconds() {
+			t.Errorf("Expected %s to expire within two windows, got %v", key, reply)
+		}
+	}
+}
+
+func TestRedisLimiter_AllowsWhenUnavailable(t *testing.T) {
+	// Nothing listens on port 1
+	limiter := newTestRedisLimiter(t, "redis://127.0.0.1:1", 1)
+
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow("client") {
+			t.Fatalf("Expected request %d to be allowed while Redis is unavailable", i+1)
+		}
+	}
+}
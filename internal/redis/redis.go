@@ -0,0 +1,232 @@
+// Package redis implements a minimal Redis client, enough for sharing sessions and rate limits
+// between mcpify replicas
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+// defaultTimeout bounds commands whose context has no deadline
+const defaultTimeout = 5 * time.Second
+
+// Error is an error reply from the Redis server
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Client sends commands to a Redis server over a pool of connections. It is safe for
+// concurrent use.
+type Client struct {
+	address  string
+	username string
+	password string
+	db       int
+	tls      *tls.Config
+	idle     chan *conn
+}
+
+// conn is a connection to the server with its reply reader
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// New creates a client for the configured server; connections are opened when needed
+func New(cfg config.RedisConfig) (*Client, error) {
+	parsed, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	client := &Client{
+		address: parsed.Host,
+		idle:    make(chan *conn, cfg.PoolSizeOrDefault()),
+	}
+	if parsed.Port() == "" {
+		client.address = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+	if parsed.User != nil {
+		client.username = parsed.User.Username()
+		client.password, _ = parsed.User.Password()
+	}
+	if db := strings.Trim(parsed.Path, "/"); db != "" {
+		if client.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+	if parsed.Scheme == "rediss" {
+		client.tls = &tls.Config{ServerName: parsed.Hostname(), MinVersion: tls.VersionTLS12}
+	}
+	return client, nil
+}
+
+// Do sends a command and returns its reply: a string, an int64, nil, or a []interface{} of
+// replies. Error replies are returned as Error.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := cn.do(ctx, args)
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection is in an unknown state after a network error
+		_ = cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Close closes the idle connections
+func (c *Client) Close() error {
+	for {
+		select {
+		case cn := <-c.idle:
+			_ = cn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// get returns an idle connection or opens a new one
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: defaultTimeout}
+	var netConn net.Conn
+	var err error
+	if c.tls != nil {
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: c.tls}).DialContext(ctx, "tcp", c.address)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", c.address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	cn := &conn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	var setup [][]string
+	if c.password != "" {
+		if c.username != "" {
+			setup = append(setup, []string{"AUTH", c.username, c.password})
+		} else {
+			setup = append(setup, []string{"AUTH", c.password})
+		}
+	}
+	if c.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	for _, args := range setup {
+		if _, err := cn.do(ctx, args); err != nil {
+			_ = cn.Close()
+			return nil, fmt.Errorf("failed to set up redis connection: %w", err)
+		}
+	}
+	return cn, nil
+}
+
+// put returns a connection to the pool, closing it when the pool is full
+func (c *Client) put(cn *conn) {
+	select {
+	case c.idle <- cn:
+	default:
+		_ = cn.Close()
+	}
+}
+
+// do writes a command and reads its reply
+func (cn *conn) do(ctx context.Context, args []string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(cn, command.String()); err != nil {
+		return nil, err
+	}
+	return readReply(cn.reader)
+}
+
+// readReply reads a reply in the Redis serialization protocol
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("malformed redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("malformed redis reply %q", line)
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:length]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("malformed redis reply %q", line)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		// Error replies inside arrays are kept as items, so the whole reply is always read
+		items := make([]interface{}, count)
+		for i := range items {
+			item, err := readReply(reader)
+			var replyErr Error
+			if errors.As(err, &replyErr) {
+				item = replyErr
+			} else if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply %q", line)
+	}
+}
@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mcpify/internal/config"
+	"mcpify/internal/redis/redistest"
+)
+
+func TestClient(t *testing.T) {
+	server := redistest.NewServer(t)
+	client, err := New(config.RedisConfig{URL: server.URL + "/2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+	ctx := context.Background()
+
+	if reply, err := client.Do(ctx, "SET", "greeting", "hello\r\nworld"); err != nil || reply != "OK" {
+		t.Fatalf("SET returned %v, %v", reply, err)
+	}
+	if reply, err := client.Do(ctx, "GET", "greeting"); err != nil || reply != "hello\r\nworld" {
+		t.Errorf("GET returned %q, %v", reply, err)
+	}
+	if reply, err := client.Do(ctx, "GET", "missing"); err != nil || reply != nil {
+		t.Errorf("GET of a missing key returned %v, %v", reply, err)
+	}
+	if reply, err := client.Do(ctx, "INCR", "counter"); err != nil || reply != int64(1) {
+		t.Errorf("INCR returned %v, %v", reply, err)
+	}
+	reply, err := client.Do(ctx, "MGET", "greeting", "missing")
+	if items, ok := reply.([]interface{}); err != nil || !ok || len(items) != 2 || items[0] != "hello\r\nworld" || items[1] != nil {
+		t.Errorf("MGET returned %v, %v", reply, err)
+	}
+
+	// Error replies leave the connection usable
+	var replyErr Error
+	if _, err := client.Do(ctx, "INCR", "greeting"); !errors.As(err, &replyErr) {
+		t.Errorf("Expected an error reply, got %v", err)
+	}
+	if reply, err := client.Do(ctx, "PING"); err != nil || reply != "PONG" {
+		t.Errorf("PING returned %v, %v", reply, err)
+	}
+}
+
+func TestClient_ConnectionFailure(t *testing.T) {
+	client, err := New(config.RedisConfig{URL: "redis://127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.Do(context.Background(), "PING"); err == nil {
+		t.Error("Expected a connection error")
+	}
+}
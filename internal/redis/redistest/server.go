@@ -0,0 +1,275 @@
+// Package redistest provides an in-memory Redis server for tests
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Server is an in-memory Redis server speaking the commands mcpify uses
+type Server struct {
+	URL string
+
+	listener net.Listener
+	mu       sync.Mutex
+	values   map[string]string
+	expiries map[string]time.Time
+}
+
+// reply is a reply to a command: nil, a status string, a bulk string, an int64, an error or a list
+type reply interface{}
+
+// NewServer starts a server on a local port, stopped when the test ends
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &Server{
+		URL:      "redis://" + listener.Addr().String(),
+		listener: listener,
+		values:   make(map[string]string),
+		expiries: make(map[string]time.Time),
+	}
+	go s.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+	return s
+}
+
+// Keys returns the stored keys that have not expired, sorted
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.values {
+		if s.live(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, encode(s.execute(args))); err != nil {
+			return
+		}
+	}
+}
+
+// live reports whether key exists and has not expired, deleting it when it has
+func (s *Server) live(key string) bool {
+	if expiry, ok := s.expiries[key]; ok && !time.Now().Before(expiry) {
+		delete(s.values, key)
+		delete(s.expiries, key)
+	}
+	_, ok := s.values[key]
+	return ok
+}
+
+// redisError is an error reply
+type redisError string
+
+func (s *Server) execute(args []string) reply {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	command := strings.ToUpper(args[0])
+	switch {
+	case command == "PING":
+		return "PONG"
+	case command == "AUTH" || command == "SELECT":
+		return "OK"
+	case command == "GET" && len(args) == 2:
+		if !s.live(args[1]) {
+			return nil
+		}
+		return bulk(s.values[args[1]])
+	case command == "MGET":
+		values := make([]reply, 0, len(args)-1)
+		for _, key := range args[1:] {
+			if s.live(key) {
+				values = append(values, bulk(s.values[key]))
+			} else {
+				values = append(values, nil)
+			}
+		}
+		return values
+	case command == "SET" && len(args) >= 3:
+		var expiry time.Duration
+		for i := 3; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "NX":
+				if s.live(args[1]) {
+					return nil
+				}
+			case "EX", "PX":
+				if i+1 >= len(args) {
+					return redisError("ERR syntax error")
+				}
+				n, err := strconv.Atoi(args[i+1])
+				if err != nil || n <= 0 {
+					return redisError("ERR invalid expire time")
+				}
+				expiry = time.Duration(n) * time.Millisecond
+				if strings.ToUpper(args[i]) == "EX" {
+					expiry = time.Duration(n) * time.Second
+				}
+				i++
+			}
+		}
+		s.values[args[1]] = args[2]
+		delete(s.expiries, args[1])
+		if expiry > 0 {
+			s.expiries[args[1]] = time.Now().Add(expiry)
+		}
+		return "OK"
+	case command == "DEL":
+		var deleted int64
+		for _, key := range args[1:] {
+			if s.live(key) {
+				delete(s.values, key)
+				delete(s.expiries, key)
+				deleted++
+			}
+		}
+		return deleted
+	case command == "INCR" && len(args) == 2:
+		var n int64
+		if s.live(args[1]) {
+			var err error
+			if n, err = strconv.ParseInt(s.values[args[1]], 10, 64); err != nil {
+				return redisError("ERR value is not an integer or out of range")
+			}
+		}
+		n++
+		s.values[args[1]] = strconv.FormatInt(n, 10)
+		return n
+	case (command == "PEXPIRE" || command == "EXPIRE") && len(args) == 3:
+		if !s.live(args[1]) {
+			return int64(0)
+		}
+		n, err := strconv.Atoi(args[2])
+		if err != nil {
+			return redisError("ERR value is not an integer or out of range")
+		}
+		expiry := time.Duration(n) * time.Millisecond
+		if command == "EXPIRE" {
+			expiry = time.Duration(n) * time.Second
+		}
+		s.expiries[args[1]] = time.Now().Add(expiry)
+		return int64(1)
+	case command == "PTTL" && len(args) == 2:
+		if !s.live(args[1]) {
+			return int64(-2)
+		}
+		expiry, ok := s.expiries[args[1]]
+		if !ok {
+			return int64(-1)
+		}
+		return time.Until(expiry).Milliseconds()
+	case command == "SCAN":
+		// Every match is returned in one batch
+		pattern := "*"
+		for i := 2; i+1 < len(args); i += 2 {
+			if strings.ToUpper(args[i]) == "MATCH" {
+				pattern = args[i+1]
+			}
+		}
+		var keys []reply
+		for key := range s.values {
+			if matched, _ := path.Match(pattern, key); matched && s.live(key) {
+				keys = append(keys, bulk(key))
+			}
+		}
+		return []reply{bulk("0"), keys}
+	default:
+		return redisError(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+	}
+}
+
+// bulk is a bulk string reply
+type bulk string
+
+// encode writes a reply in the Redis serialization protocol
+func encode(r reply) string {
+	switch v := r.(type) {
+	case nil:
+		return "$-1\r\n"
+	case string:
+		return "+" + v + "\r\n"
+	case bulk:
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case int64:
+		return fmt.Sprintf(":%d\r\n", v)
+	case redisError:
+		return "-" + string(v) + "\r\n"
+	case []reply:
+		var b strings.Builder
+		fmt.Fprintf(&b, "*%d\r\n", len(v))
+		for _, item := range v {
+			b.WriteString(encode(item))
+		}
+		return b.String()
+	default:
+		panic(fmt.Sprintf("unsupported reply %T", r))
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("malformed command %q", line)
+	}
+	args := make([]string, count)
+	for i := range args {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "$")))
+		if err != nil {
+			return nil, fmt.Errorf("malformed argument %q", header)
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:length])
+	}
+	return args, nil
+}
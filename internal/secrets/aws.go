@@ -0,0 +1,197 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// awsCredentials are the static credentials used to sign Secrets Manager requests
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// AWSProvider reads secrets from AWS Secrets Manager.
+// References have the form "aws-sm:<secret-id or ARN>" with an optional "#key"
+// to select a field of a JSON secret.
+type AWSProvider struct {
+	region      string
+	endpoint    string // overrides the regional endpoint when set
+	credentials awsCredentials
+	client      *http.Client
+	now         func() time.Time
+}
+
+// NewAWSProvider creates a Secrets Manager provider configured from the standard
+// AWS_REGION (or AWS_DEFAULT_REGION), AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN environment variables
+func NewAWSProvider() *AWSProvider {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	return &AWSProvider{
+		region:   region,
+		endpoint: os.Getenv("AWS_ENDPOINT_URL_SECRETS_MANAGER"),
+		credentials: awsCredentials{
+			accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		},
+		client: newHTTPClient(),
+		now:    time.Now,
+	}
+}
+
+// Resolve reads a secret string from Secrets Manager
+func (a *AWSProvider) Resolve(ref string) (string, error) {
+	if a.credentials.accessKeyID == "" || a.credentials.secretAccessKey == "" {
+		return "", errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set")
+	}
+
+	secretID, key := splitRef(ref)
+
+	// Secret ARNs carry their own region: arn:aws:secretsmanager:<region>:<account>:secret:<name>
+	region := a.region
+	if parts := strings.Split(secretID, ":"); len(parts) > 3 && parts[0] == "arn" {
+		region = parts[3]
+	}
+	if region == "" {
+		return "", errors.New("AWS_REGION is not set")
+	}
+
+	endpoint := a.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	a.sign(req, body, region, "secretsmanager")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("secrets manager returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var payload struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("invalid secrets manager response: %w", err)
+	}
+	if payload.SecretString == nil {
+		return "", errors.New("secret has no string value")
+	}
+
+	return extractKey(*payload.SecretString, key)
+}
+
+// sign adds AWS Signature Version 4 headers to a request
+func (a *AWSProvider) sign(req *http.Request, body []byte, region, service string) {
+	now := a.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.credentials.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.credentials.sessionToken)
+	}
+
+	// Every request header is signed, along with the host; names must be lowercase and sorted
+	signedHeaders := []string{"host"}
+	for name := range req.Header {
+		signedHeaders = append(signedHeaders, strings.ToLower(name))
+	}
+	sort.Strings(signedHeaders)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		hashHex(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+a.credentials.secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		a.credentials.accessKeyID, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// canonicalQuery encodes query parameters in the sorted form SigV4 expects
+func canonicalQuery(values url.Values) string {
+	// url.Values.Encode sorts by key; SigV4 requires %20 rather than + for spaces
+	return strings.ReplaceAll(values.Encode(), "+", "%20")
+}
+
+// hashHex returns the hex-encoded SHA-256 of data
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 computes HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAWSProvider_Sign(t *testing.T) {
+	// "get-vanilla" from the AWS Signature Version 4 test suite
+	provider := &AWSProvider{
+		credentials: awsCredentials{
+			accessKeyID:     "AKIDEXAMPLE",
+			secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		},
+		now: func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) },
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	provider.sign(req, nil, "us-east-1", "service")
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != expected {
+		t.Errorf("Expected Authorization %q, got %q", expected, got)
+	}
+}
+
+func TestAWSProvider_Resolve(t *testing.T) {
+	var target, authorization string
+	var request map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target = r.Header.Get("X-Amz-Target")
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		switch request["SecretId"] {
+		case "prod/api":
+			_, _ = w.Write([]byte(`{"Name":"prod/api","SecretString":"{\"token\":\"aws-token\",\"port\":8443}"}`))
+		case "plain":
+			_, _ = w.Write([]byte(`{"Name":"plain","SecretString":"plain-secret"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := &AWSProvider{
+		region:      "eu-west-1",
+		endpoint:    server.URL,
+		credentials: awsCredentials{accessKeyID: "AKID", secretAccessKey: "secret", sessionToken: "session"},
+		client:      server.Client(),
+		now:         time.Now,
+	}
+
+	value, err := provider.Resolve("prod/api#token")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value != "aws-token" {
+		t.Errorf("Expected aws-token, got %q", value)
+	}
+	if target != "secretsmanager.GetSecretValue" {
+		t.Errorf("Expected GetSecretValue target, got %q", target)
+	}
+	if !strings.Contains(authorization, "/eu-west-1/secretsmanager/aws4_request") ||
+		!strings.Contains(authorization, "x-amz-security-token") {
+		t.Errorf("Unexpected Authorization header: %q", authorization)
+	}
+
+	if value, _ := provider.Resolve("prod/api#port"); value != "8443" {
+		t.Errorf("Expected non-string field to be formatted, got %q", value)
+	}
+	if value, _ := provider.Resolve("plain"); value != "plain-secret" {
+		t.Errorf("Expected plain-secret, got %q", value)
+	}
+	if _, err := provider.Resolve("plain#token"); err == nil {
+		t.Error("Expected error selecting a key from a non-JSON secret")
+	}
+	if _, err := provider.Resolve("missing"); err == nil {
+		t.Error("Expected error for missing secret")
+	}
+
+	// ARNs select their own region
+	if _, err := provider.Resolve("arn:aws:secretsmanager:us-west-2:123456789012:secret:prod/api#token"); err == nil {
+		t.Error("Expected error for unknown ARN secret")
+	}
+	if !strings.Contains(authorization, "/us-west-2/secretsmanager/") {
+		t.Errorf("Expected ARN region in signature scope, got %q", authorization)
+	}
+}
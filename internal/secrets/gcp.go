@@ -0,0 +1,146 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcpSecretManagerURL = "https://secretmanager.googleapis.com"
+	gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// GCPProvider reads secrets from Google Cloud Secret Manager.
+// References have the form "gcp-sm:projects/<project>/secrets/<name>[/versions/<version>]"
+// with an optional "#key" to select a field of a JSON secret; the version defaults to "latest".
+type GCPProvider struct {
+	endpoint    string
+	metadataURL string
+	accessToken string // static token from GOOGLE_OAUTH_ACCESS_TOKEN, skips the metadata server
+	client      *http.Client
+	now         func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewGCPProvider creates a Secret Manager provider. It authenticates with GOOGLE_OAUTH_ACCESS_TOKEN
+// when set, and otherwise with the instance service account from the metadata server (GCE, GKE, Cloud Run).
+func NewGCPProvider() *GCPProvider {
+	return &GCPProvider{
+		endpoint:    gcpSecretManagerURL,
+		metadataURL: gcpMetadataTokenURL,
+		accessToken: os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"),
+		client:      newHTTPClient(),
+		now:         time.Now,
+	}
+}
+
+// Resolve accesses a secret version in Secret Manager
+func (g *GCPProvider) Resolve(ref string) (string, error) {
+	name, key := splitRef(ref)
+	name = strings.Trim(name, "/")
+	if !strings.HasPrefix(name, "projects/") || !strings.Contains(name, "/secrets/") {
+		return "", fmt.Errorf("invalid secret name %q, expected projects/<project>/secrets/<name>", name)
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	token, err := g.getToken()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, g.endpoint+"/v1/"+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secret manager request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("secret manager returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("invalid secret manager response: %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret payload: %w", err)
+	}
+
+	return extractKey(string(data), key)
+}
+
+// getToken returns an OAuth access token, fetching one from the metadata server when needed
+func (g *GCPProvider) getToken() (string, error) {
+	if g.accessToken != "" {
+		return g.accessToken, nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Refresh a minute early so the token does not expire mid-request
+	if g.token != "" && g.now().Before(g.tokenExpiry.Add(-time.Minute)) {
+		return g.token, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, g.metadataURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch access token from metadata server (set GOOGLE_OAUTH_ACCESS_TOKEN outside GCP): %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server returned HTTP %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("invalid metadata server response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("metadata server returned no access token")
+	}
+
+	g.token = token.AccessToken
+	g.tokenExpiry = g.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return g.token, nil
+}
@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGCPProvider_Resolve(t *testing.T) {
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			tokenRequests++
+			_, _ = w.Write([]byte(`{"access_token":"gcp-access-token","expires_in":3600,"token_type":"Bearer"}`))
+		case "/v1/projects/demo/secrets/api/versions/latest:access":
+			if r.Header.Get("Authorization") != "Bearer gcp-access-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			data := base64.StdEncoding.EncodeToString([]byte(`{"token":"gcp-token"}`))
+			_, _ = w.Write([]byte(`{"name":"projects/demo/secrets/api/versions/3","payload":{"data":"` + data + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := &GCPProvider{
+		endpoint:    server.URL,
+		metadataURL: server.URL + "/token",
+		client:      server.Client(),
+		now:         time.Now,
+	}
+
+	value, err := provider.Resolve("projects/demo/secrets/api#token")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value != "gcp-token" {
+		t.Errorf("Expected gcp-token, got %q", value)
+	}
+
+	// The access token is reused until it nears expiry
+	if _, err := provider.Resolve("projects/demo/secrets/api/versions/latest#token"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("Expected 1 metadata token request, got %d", tokenRequests)
+	}
+
+	if _, err := provider.Resolve("projects/demo/secrets/missing"); err == nil {
+		t.Error("Expected error for missing secret")
+	}
+	if _, err := provider.Resolve("demo/api"); err == nil {
+		t.Error("Expected error for malformed secret name")
+	}
+}
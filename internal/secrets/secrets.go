@@ -0,0 +1,60 @@
+// Package secrets resolves auth secrets from external stores referenced in the configuration,
+// e.g. "vault:secret/data/api#token", "aws-sm:prod/api#token" or "gcp-sm:projects/p/secrets/api"
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+// requestTimeout bounds each call to a secret store
+const requestTimeout = 10 * time.Second
+
+// Register registers the Vault, AWS Secrets Manager and GCP Secret Manager providers
+// under the "vault", "aws-sm" and "gcp-sm" reference schemes
+func Register() {
+	config.RegisterSecretProvider("vault", NewVaultProvider())
+	config.RegisterSecretProvider("aws-sm", NewAWSProvider())
+	config.RegisterSecretProvider("gcp-sm", NewGCPProvider())
+}
+
+// newHTTPClient creates the HTTP client used to reach secret stores
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
+
+// splitRef splits a reference into the secret path and the optional "#key" selector
+func splitRef(ref string) (string, string) {
+	path, key, _ := strings.Cut(ref, "#")
+	return path, key
+}
+
+// extractKey returns a field from a JSON object secret, or the secret itself when key is empty
+func extractKey(secret, key string) (string, error) {
+	if key == "" {
+		return secret, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, cannot select key %q", key)
+	}
+	return fieldString(fields, key)
+}
+
+// fieldString returns a field of a secret object as a string
+func fieldString(fields map[string]interface{}, key string) (string, error) {
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in secret", key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprintf("%v", value), nil
+}
@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// VaultProvider reads secrets from HashiCorp Vault over its HTTP API.
+// References have the form "vault:<path>#<key>"; both KV v1 and KV v2 engines are supported.
+type VaultProvider struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// NewVaultProvider creates a Vault provider configured from VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE
+func NewVaultProvider() *VaultProvider {
+	return &VaultProvider{
+		addr:      os.Getenv("VAULT_ADDR"),
+		token:     os.Getenv("VAULT_TOKEN"),
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+		client:    newHTTPClient(),
+	}
+}
+
+// Resolve reads a secret from Vault
+func (v *VaultProvider) Resolve(ref string) (string, error) {
+	if v.addr == "" {
+		return "", errors.New("VAULT_ADDR is not set")
+	}
+	if v.token == "" {
+		return "", errors.New("VAULT_TOKEN is not set")
+	}
+
+	path, key := splitRef(ref)
+	if key == "" {
+		return "", fmt.Errorf("vault reference %q must select a key with #key", ref)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(v.addr, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("vault returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+
+	// KV v2 nests the secret under data.data
+	if nested, ok := payload.Data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := payload.Data["metadata"]; hasMetadata {
+			return fieldString(nested, key)
+		}
+	}
+	return fieldString(payload.Data, key)
+}
@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVaultProvider_Resolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/api":
+			_, _ = w.Write([]byte(`{"data":{"data":{"token":"kv2-token"},"metadata":{"version":3}}}`))
+		case "/v1/kv/api":
+			_, _ = w.Write([]byte(`{"data":{"token":"kv1-token"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := &VaultProvider{addr: server.URL, token: "vault-token", client: server.Client()}
+
+	tests := []struct {
+		name     string
+		ref      string
+		expected string
+		wantErr  bool
+	}{
+		{name: "kv v2", ref: "secret/data/api#token", expected: "kv2-token"},
+		{name: "kv v1", ref: "kv/api#token", expected: "kv1-token"},
+		{name: "missing key", ref: "kv/api#password", wantErr: true},
+		{name: "no key selector", ref: "kv/api", wantErr: true},
+		{name: "unknown path", ref: "secret/data/missing#token", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := provider.Resolve(tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got value %q", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, value)
+			}
+		})
+	}
+
+	unauthenticated := &VaultProvider{addr: server.URL, token: "wrong", client: server.Client()}
+	if _, err := unauthenticated.Resolve("secret/data/api#token"); err == nil {
+		t.Error("Expected error for rejected Vault token")
+	}
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrRateLimited is returned when a tool call exceeds the tool's rate limit
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// ErrAuthentication is returned when the upstream credentials of a call cannot be applied
+var ErrAuthentication = errors.New("failed to add authentication")
+
+// UpstreamError is returned when the upstream API answers a tool call with an error status
+type UpstreamError struct {
+	StatusCode int
+	Body       string
+	// RequestID is the request ID the upstream answered with, if any
+	RequestID string
+	// URL is the requested URL, with credentials removed
+	URL string
+}
+
+// Error describes the status of the response. The body is left out, so large or sensitive
+// payloads are only passed on where Body is read explicitly.
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("API request failed with status %d", e.StatusCode)
+}
+
+// RequestError is returned when an upstream request built for a tool call fails without an
+// answer, e.g. on a network error or a timeout
+type RequestError struct {
+	// URL is the requested URL, with credentials removed
+	URL string
+	Err error
+}
+
+// Error returns the message of the underlying error
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// ArgumentError is returned when the arguments of a tool call are missing or invalid, before
+// anything is sent upstream
+type ArgumentError struct {
+	err error
+}
+
+// ArgumentErrorf formats an ArgumentError like fmt.Errorf
+func ArgumentErrorf(format string, args ...interface{}) error {
+	return &ArgumentError{err: fmt.Errorf(format, args...)}
+}
+
+// Error returns the message of the error
+func (e *ArgumentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error wrapped by the message, if any
+func (e *ArgumentError) Unwrap() error {
+	return errors.Unwrap(e.err)
+}
@@ -0,0 +1,23 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DecodeJSON decodes JSON like json.Unmarshal, except that numbers in interface{} values
+// become json.Number instead of float64, so large integers such as snowflake IDs keep their
+// precision when tool arguments and upstream responses are passed on
+func DecodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+	return nil
+}
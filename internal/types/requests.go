@@ -2,6 +2,8 @@ package types
 
 import (
 	"encoding/json"
+	"sort"
+	"strings"
 	"time"
 
 	"mcpify/internal/config"
@@ -15,6 +17,13 @@ type MCPRequest struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 }
 
+// MCPNotification represents a JSON-RPC notification, a message that gets no response
+type MCPNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
 // MCPResponse represents a JSON-RPC response
 type MCPResponse struct {
 	JSONRPC string      `json:"jsonrpc"`
@@ -30,6 +39,18 @@ type MCPError struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// ToolErrorData is the data of the error answering a failed tool call, with the upstream
+// diagnostics known for it
+type ToolErrorData struct {
+	// Error is the full error message
+	Error      string `json:"error"`
+	StatusCode int    `json:"status_code,omitempty"`
+	// Body is the start of the upstream response body
+	Body      string `json:"body,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
 // Tool represents an MCP tool
 type Tool struct {
 	Name        string                 `json:"name"`
@@ -115,6 +136,30 @@ type OpenAPIParameter struct {
 	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
 	Required    bool        `json:"required,omitempty" yaml:"required,omitempty"`
 	Schema      interface{} `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example     interface{} `json:"example,omitempty" yaml:"example,omitempty"`
+	// ODataString marks a path parameter sent inside quotes as an OData string key, e.g.
+	// Products('{id}'), whose single quotes are doubled in the value
+	ODataString bool `json:"-" yaml:"-"`
+}
+
+// SchemaMap returns the parameter's schema as a map, whether it was parsed from a
+// specification or built as a map, or nil when it has none
+func (p OpenAPIParameter) SchemaMap() map[string]interface{} {
+	if p.Schema == nil {
+		return nil
+	}
+	if m, ok := p.Schema.(map[string]interface{}); ok {
+		return m
+	}
+	data, err := json.Marshal(p.Schema)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return m
 }
 
 // OpenAPIRequestBody represents a request body in OpenAPI spec
@@ -122,6 +167,113 @@ type OpenAPIRequestBody struct {
 	Description string                 `json:"description,omitempty" yaml:"description,omitempty"`
 	Required    bool                   `json:"required,omitempty" yaml:"required,omitempty"`
 	Content     map[string]interface{} `json:"content,omitempty" yaml:"content,omitempty"`
+	// ResolveContent builds Content on first use when the parser defers schema resolution
+	ResolveContent func() map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// MediaTypes returns the request body content by media type, resolving deferred schemas on first use
+func (b *OpenAPIRequestBody) MediaTypes() map[string]interface{} {
+	if b.Content == nil && b.ResolveContent != nil {
+		return b.ResolveContent()
+	}
+	return b.Content
+}
+
+// BodyArgument is the tool argument holding the request body, whatever the specification
+// calls it: OpenAPI 3 request bodies, Swagger 2 "in: body" parameters (converted to request
+// bodies when the spec is loaded) and the elements of SOAP requests all arrive in it
+const BodyArgument = "body"
+
+// MediaType returns the media type requests send the body as: application/json when the
+// operation accepts it, otherwise another JSON type, otherwise the first other media type by
+// name, or "" when the specification lists none
+func (b *OpenAPIRequestBody) MediaType() string {
+	if mediaTypes := b.sortedMediaTypes(); len(mediaTypes) > 0 {
+		return mediaTypes[0]
+	}
+	return ""
+}
+
+// Schema returns the schema of the body argument: the schema of the JSON content when there
+// is one, otherwise of the first other media type that has a schema, otherwise any object
+func (b *OpenAPIRequestBody) Schema() map[string]interface{} {
+	content := b.MediaTypes()
+	for _, mediaType := range b.sortedMediaTypes() {
+		if entry, ok := content[mediaType].(map[string]interface{}); ok {
+			if schema, ok := entry["schema"].(map[string]interface{}); ok {
+				return schema
+			}
+		}
+	}
+	return map[string]interface{}{
+		"type":        "object",
+		"description": "Request body data",
+	}
+}
+
+// Example returns the example body of the first media type, in the order of Schema, that has
+// one, or nil when the specification gives none
+func (b *OpenAPIRequestBody) Example() interface{} {
+	content := b.MediaTypes()
+	for _, mediaType := range b.sortedMediaTypes() {
+		if entry, ok := content[mediaType].(map[string]interface{}); ok && entry["example"] != nil {
+			return entry["example"]
+		}
+	}
+	return nil
+}
+
+// IsFileSchema reports whether a body property holds file content, which specifications
+// describe as a binary or base64 string, or as a string with a content media type
+func IsFileSchema(schema map[string]interface{}) bool {
+	if schema["type"] != "string" {
+		return false
+	}
+	format, _ := schema["format"].(string)
+	_, hasMediaType := schema["contentMediaType"]
+	return format == "binary" || format == "base64" || hasMediaType
+}
+
+// FileArgumentSchema returns the schema of the arguments passing a file to a multipart operation
+func FileArgumentSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "object",
+		"description": "File to upload, as base64 content or, when the server allows it, a local path",
+		"properties": map[string]interface{}{
+			"content":      map[string]interface{}{"type": "string", "contentEncoding": "base64", "description": "Base64-encoded file content"},
+			"path":         map[string]interface{}{"type": "string", "description": "Path of a file on the server, when local files are enabled"},
+			"filename":     map[string]interface{}{"type": "string", "description": "File name sent upstream, the base name of path by default"},
+			"content_type": map[string]interface{}{"type": "string", "description": "Media type of the file, guessed from the file name or content by default"},
+		},
+	}
+}
+
+// sortedMediaTypes returns the media types of the body in order of preference:
+// application/json first, then other JSON types, then the rest, each in name order
+func (b *OpenAPIRequestBody) sortedMediaTypes() []string {
+	content := b.MediaTypes()
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	rank := func(mediaType string) int {
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		switch {
+		case mediaType == "application/json":
+			return 0
+		case strings.HasSuffix(mediaType, "+json"):
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.Slice(mediaTypes, func(i, j int) bool {
+		if rank(mediaTypes[i]) != rank(mediaTypes[j]) {
+			return rank(mediaTypes[i]) < rank(mediaTypes[j])
+		}
+		return mediaTypes[i] < mediaTypes[j]
+	})
+	return mediaTypes
 }
 
 // OpenAPIResponse represents a response in OpenAPI spec
@@ -136,7 +288,78 @@ type APITool struct {
 	Description string
 	Method      string
 	Path        string
+	Tags        []string // Tags of the operation in the spec
 	Parameters  []OpenAPIParameter
 	RequestBody *OpenAPIRequestBody
 	Handler     func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error)
+	Timeout     time.Duration        // Per-tool upstream timeout from the tools section, 0 for the API default
+	Headers     config.HeadersConfig // Extra headers from the tools section
+	RateLimit   int                  // Maximum calls per minute from the tools section, 0 for no limit
+	Accept      string               // Accept header, from the operation's response media types or the tools section
+	BaseURL     string               // Base URL of the operation when it differs from the API's, relative URLs resolved against it
+	// SkipMiddleware names the upstream call pipeline stages not run for this tool
+	SkipMiddleware []string
+	// Pagination fetches every page of the operation in one call when set
+	Pagination *config.PaginationConfig
+	// SubpathParams names path parameters whose values keep their slashes
+	SubpathParams []string
+	// HedgeDelay is the wait before a GET is sent a second time, 0 when it is not hedged
+	HedgeDelay time.Duration
+	// Debug logs the requests and responses of the tool even when the API's debug is off
+	Debug bool
+	// CacheTTL is how long the tool's GET responses are reused, 0 when they are not cached
+	CacheTTL time.Duration
+	// PreCall and PostCall are the valueFrom expressions setting arguments before the call
+	// and the fields of the response body after it
+	PreCall  map[string]string
+	PostCall map[string]string
+	// Deprecated is set for operations the spec deprecates. DeprecatedBy names the tool that
+	// replaces it, from the x-deprecated-by extension, and DeprecationNote is the sentence of
+	// the operation's description about the deprecation when there is no such tool.
+	Deprecated      bool
+	DeprecatedBy    string
+	DeprecationNote string
+	// SOAP is set for tools generated from a WSDL, whose arguments are sent in a SOAP envelope
+	SOAP *SOAPOperation
+	// ResolveOutputSchema builds the schema of the successful JSON response, or returns nil
+	// when the specification does not describe one
+	ResolveOutputSchema func() map[string]interface{}
+}
+
+// Input schema keywords telling clients, and the people reading the schema, where each
+// argument is sent and which upstream parameter it sets when its name had to change
+const (
+	LocationKeyword      = "x-mcpify-in"
+	ParameterNameKeyword = "x-mcpify-name"
+)
+
+// ODataStringExtension marks the path parameters of a specification generated from OData
+// metadata that are string key values, quoted in the path
+const ODataStringExtension = "x-mcpify-odata-string"
+
+// ArgumentName returns the name of the tool argument setting a parameter: the parameter's
+// name, or when another parameter or the body argument has the same name, the name prefixed
+// with the location, e.g. query_id alongside a path parameter id
+func (t APITool) ArgumentName(param OpenAPIParameter) string {
+	if t.RequestBody != nil && param.Name == BodyArgument {
+		return param.In + "_" + param.Name
+	}
+	for _, other := range t.Parameters {
+		if other.Name == param.Name && other.In != param.In {
+			return param.In + "_" + param.Name
+		}
+	}
+	return param.Name
+}
+
+// SOAPOperation describes the request envelope of a tool generated from a WSDL operation
+type SOAPOperation struct {
+	Version   string // SOAP version of the binding, "1.1" or "1.2"
+	Action    string // SOAPAction of the operation
+	Element   string // Request element wrapping the arguments
+	Namespace string // Namespace of the request element
+	// Qualified puts the argument elements in Namespace too (elementFormDefault="qualified")
+	Qualified bool
+	// Parts names the arguments in the order the request element's sequence requires
+	Parts []string
 }
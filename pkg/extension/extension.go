@@ -0,0 +1,164 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package extension defines the hooks through which Go plugins customize tool calls and
+// upstream requests, e.g. to add bespoke authentication or transform responses.
+//
+// A plugin is built with "go build -buildmode=plugin" against the same mcpify source and
+// exports a NewExtension function:
+//
+//	func NewExtension(options map[string]interface{}) (extension.Extension, error)
+//
+// Untrusted transforms run as WebAssembly modules instead, loaded with OpenWASM.
+package extension
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ToolCall describes a tool call before it is turned into an upstream request
+type ToolCall struct {
+	Tool   string // Name of the tool
+	Method string // HTTP method of the upstream operation
+	Path   string // Path template of the upstream operation
+	// Arguments are the tool arguments; hooks may change them
+	Arguments map[string]interface{}
+	// Claims are the verified JWT claims of the MCP client, if any
+	Claims map[string]interface{}
+}
+
+// Extension hooks into every tool call served by the upstream APIs. A hook returning an
+// error aborts the tool call with that error.
+type Extension interface {
+	// OnToolCall runs before the upstream request is built
+	OnToolCall(ctx context.Context, call *ToolCall) error
+	// OnUpstreamRequest runs before the upstream request is sent, after auth and configured
+	// headers are applied
+	OnUpstreamRequest(ctx context.Context, req *http.Request) error
+	// OnUpstreamResponse runs when the upstream response arrives, before its body is read;
+	// hooks may replace the body
+	OnUpstreamResponse(ctx context.Context, resp *http.Response) error
+	// Close releases the resources of the extension once a reload replaces it or the server
+	// stops; hooks are not called after it
+	Close() error
+}
+
+// Base implements every hook as a no-op, so extensions can embed it and override only the
+// hooks they need
+type Base struct{}
+
+// OnToolCall implements Extension
+func (Base) OnToolCall(ctx context.Context, call *ToolCall) error { return nil }
+
+// OnUpstreamRequest implements Extension
+func (Base) OnUpstreamRequest(ctx context.Context, req *http.Request) error { return nil }
+
+// OnUpstreamResponse implements Extension
+func (Base) OnUpstreamResponse(ctx context.Context, resp *http.Response) error { return nil }
+
+// Close implements Extension
+func (Base) Close() error { return nil }
+
+// toolKey is the context key of the name of the tool being called
+type toolKey struct{}
+
+// WithTool returns a context carrying the name of the tool being called
+func WithTool(ctx context.Context, tool string) context.Context {
+	return context.WithValue(ctx, toolKey{}, tool)
+}
+
+// ToolFromContext returns the name of the tool a hook runs for, or "" when the context does
+// not carry one
+func ToolFromContext(ctx context.Context) string {
+	tool, _ := ctx.Value(toolKey{}).(string)
+	return tool
+}
+
+// ForTools runs the hooks of ext only for the tools applies accepts. Upstream hooks find the
+// tool in their context, so they are skipped when it carries none.
+func ForTools(ext Extension, applies func(tool string) bool) Extension {
+	return toolFilter{ext: ext, applies: applies}
+}
+
+// toolFilter is an extension limited to some tools
+type toolFilter struct {
+	ext     Extension
+	applies func(tool string) bool
+}
+
+// OnToolCall implements Extension
+func (f toolFilter) OnToolCall(ctx context.Context, call *ToolCall) error {
+	if !f.applies(call.Tool) {
+		return nil
+	}
+	return f.ext.OnToolCall(ctx, call)
+}
+
+// OnUpstreamRequest implements Extension
+func (f toolFilter) OnUpstreamRequest(ctx context.Context, req *http.Request) error {
+	if !f.applies(ToolFromContext(ctx)) {
+		return nil
+	}
+	return f.ext.OnUpstreamRequest(ctx, req)
+}
+
+// OnUpstreamResponse implements Extension
+func (f toolFilter) OnUpstreamResponse(ctx context.Context, resp *http.Response) error {
+	if !f.applies(ToolFromContext(ctx)) {
+		return nil
+	}
+	return f.ext.OnUpstreamResponse(ctx, resp)
+}
+
+// Close implements Extension
+func (f toolFilter) Close() error {
+	return f.ext.Close()
+}
+
+// Chain runs extensions in order, stopping at the first error
+type Chain []Extension
+
+// OnToolCall implements Extension
+func (c Chain) OnToolCall(ctx context.Context, call *ToolCall) error {
+	for _, ext := range c {
+		if err := ext.OnToolCall(ctx, call); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnUpstreamRequest implements Extension
+func (c Chain) OnUpstreamRequest(ctx context.Context, req *http.Request) error {
+	for _, ext := range c {
+		if err := ext.OnUpstreamRequest(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnUpstreamResponse implements Extension
+func (c Chain) OnUpstreamResponse(ctx context.Context, resp *http.Response) error {
+	for _, ext := range c {
+		if err := ext.OnUpstreamResponse(ctx, resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close implements Extension, closing every extension of the chain
+func (c Chain) Close() error {
+	var errs []error
+	for _, ext := range c {
+		if err := ext.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
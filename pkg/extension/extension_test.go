@@ -0,0 +1,55 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type recordingExtension struct {
+	Base
+	name  string
+	calls *[]string
+	err   error
+}
+
+func (e recordingExtension) OnToolCall(ctx context.Context, call *ToolCall) error {
+	*e.calls = append(*e.calls, e.name)
+	return e.err
+}
+
+func TestChain_RunsInOrderAndStopsAtFirstError(t *testing.T) {
+	var calls []string
+	failure := errors.New("rejected")
+	chain := Chain{
+		recordingExtension{name: "first", calls: &calls},
+		recordingExtension{name: "second", calls: &calls, err: failure},
+		recordingExtension{name: "third", calls: &calls},
+	}
+
+	if err := chain.OnToolCall(context.Background(), &ToolCall{Tool: "get_user"}); !errors.Is(err, failure) {
+		t.Errorf("Expected the second extension's error, got %v", err)
+	}
+	if strings.Join(calls, ",") != "first,second" {
+		t.Errorf("Expected the chain to stop after the failing extension, got %v", calls)
+	}
+
+	// Hooks inherited from Base do nothing
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err := chain.OnUpstreamRequest(context.Background(), req); err != nil {
+		t.Errorf("Expected no-op request hooks, got %v", err)
+	}
+	if err := chain.OnUpstreamResponse(context.Background(), &http.Response{}); err != nil {
+		t.Errorf("Expected no-op response hooks, got %v", err)
+	}
+}
+
+func TestOpen_MissingPlugin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.so")
+	if _, err := Open(path, nil); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("Expected an error naming the plugin path, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package extension
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// NewExtensionSymbol is the function a plugin exports to create its extension
+const NewExtensionSymbol = "NewExtension"
+
+// Factory creates an extension from its configured options
+type Factory func(options map[string]interface{}) (Extension, error)
+
+// Open loads the Go plugin at path and creates its extension with options
+func Open(path string, options map[string]interface{}) (Extension, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+	symbol, err := p.Lookup(NewExtensionSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", path, err)
+	}
+
+	var factory Factory
+	switch fn := symbol.(type) {
+	case func(map[string]interface{}) (Extension, error):
+		factory = fn
+	case *Factory:
+		factory = *fn
+	default:
+		return nil, fmt.Errorf("plugin %s: %s has type %T, expected func(map[string]interface{}) (extension.Extension, error)",
+			path, NewExtensionSymbol, symbol)
+	}
+
+	ext, err := factory(options)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", path, err)
+	}
+	return ext, nil
+}
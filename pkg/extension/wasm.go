@@ -0,0 +1,313 @@
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+package extension
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+)
+
+// Exports of a WASM transform module. The module exports its memory and an alloc function
+// returning a buffer of the requested size, where the input document is written, and at
+// least one of the transform functions.
+const (
+	wasmMemoryExport     = "memory"
+	wasmAllocExport      = "alloc"
+	wasmRequestExport    = "transform_request"
+	wasmResponseExport   = "transform_response"
+	wasmTimeout          = time.Second
+	wasmMemoryLimitPages = 1024    // 64 MiB
+	maxWASMBody          = 8 << 20 // Largest body passed to a transform
+)
+
+// WASMRequest is the document a WASM transform receives for an upstream request. The
+// transform returns one with the fields it changes: a URL on the same host, headers to set,
+// with an empty list removing the header, and a body. Error rejects the request.
+type WASMRequest struct {
+	Tool    string              `json:"tool,omitempty"`
+	Method  string              `json:"method,omitempty"`
+	URL     string              `json:"url,omitempty"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    *string             `json:"body,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// WASMResponse is the document a WASM transform receives for an upstream response, and returns
+// with the status, headers and body it changes. Error fails the tool call.
+type WASMResponse struct {
+	Tool    string              `json:"tool,omitempty"`
+	Status  int                 `json:"status,omitempty"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    *string             `json:"body,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// WASM is an extension running the transform functions of a WebAssembly module on upstream
+// requests and responses. The module runs in a sandbox: it may import nothing from the host,
+// so it has no access to files, the network or the clock, each call runs in a fresh instance
+// with bounded memory, and calls running longer than a second are stopped.
+//
+// A transform function takes the pointer and length of the input JSON document in the
+// module's memory and returns the pointer of its output document in the high 32 bits and its
+// length in the low 32 bits; a length of 0 leaves the request or response unchanged.
+type WASM struct {
+	Base
+	path     string
+	runtime  wazero.Runtime
+	module   wazero.CompiledModule
+	request  bool // Whether the module exports transform_request
+	response bool // Whether the module exports transform_response
+
+	mu     sync.RWMutex // Held for reading by running transforms, so Close waits for them
+	closed bool
+}
+
+// OpenWASM compiles the WebAssembly transform module at path
+func OpenWASM(path string) (*WASM, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WASM module %s: %w", path, err)
+	}
+
+	ctx := context.Background()
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(wasmMemoryLimitPages).
+		WithCloseOnContextDone(true))
+	module, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to compile WASM module %s: %w", path, err)
+	}
+
+	w := &WASM{path: path, runtime: runtime, module: module}
+	exports := module.ExportedFunctions()
+	_, w.request = exports[wasmRequestExport]
+	_, w.response = exports[wasmResponseExport]
+	_, alloc := exports[wasmAllocExport]
+	_, memory := module.ExportedMemories()[wasmMemoryExport]
+	switch {
+	case len(module.ImportedFunctions()) > 0 || len(module.ImportedMemories()) > 0:
+		err = errors.New("transforms run without host access and may not import anything")
+	case !alloc || !memory:
+		err = fmt.Errorf("the module must export %s and %s", wasmMemoryExport, wasmAllocExport)
+	case !w.request && !w.response:
+		err = fmt.Errorf("the module exports neither %s nor %s", wasmRequestExport, wasmResponseExport)
+	}
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("WASM module %s: %w", path, err)
+	}
+	return w, nil
+}
+
+// OnUpstreamRequest implements Extension, rewriting the request with transform_request
+func (w *WASM) OnUpstreamRequest(ctx context.Context, req *http.Request) error {
+	if !w.request {
+		return nil
+	}
+	body, err := readWASMBody(req.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	text := string(body)
+	input := WASMRequest{
+		Tool:    ToolFromContext(ctx),
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: req.Header,
+		Body:    &text,
+	}
+	var output WASMRequest
+	changed, err := w.transform(ctx, wasmRequestExport, input, &output)
+	if err != nil || !changed {
+		setRequestBody(req, body, req.Body != nil)
+		return err
+	}
+	if output.Error != "" {
+		return fmt.Errorf("WASM transform %s: %s", w.path, output.Error)
+	}
+
+	if output.URL != "" {
+		rewritten, err := url.Parse(output.URL)
+		if err != nil {
+			return fmt.Errorf("WASM transform %s returned an invalid URL: %w", w.path, err)
+		}
+		// The request already carries the upstream credentials
+		if rewritten.Scheme != req.URL.Scheme || rewritten.Host != req.URL.Host {
+			return fmt.Errorf("WASM transform %s may not send the request to another host", w.path)
+		}
+		req.URL = rewritten
+	}
+	setHeaders(req.Header, output.Headers)
+	if output.Body != nil {
+		body = []byte(*output.Body)
+	}
+	setRequestBody(req, body, req.Body != nil || output.Body != nil)
+	return nil
+}
+
+// OnUpstreamResponse implements Extension, rewriting the response with transform_response
+func (w *WASM) OnUpstreamResponse(ctx context.Context, resp *http.Response) error {
+	if !w.response {
+		return nil
+	}
+	body, err := readWASMBody(resp.Body)
+	if resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	text := string(body)
+	input := WASMResponse{
+		Tool:    ToolFromContext(ctx),
+		Status:  resp.StatusCode,
+		Headers: resp.Header,
+		Body:    &text,
+	}
+	var output WASMResponse
+	changed, err := w.transform(ctx, wasmResponseExport, input, &output)
+	if err == nil && changed && output.Error != "" {
+		err = fmt.Errorf("WASM transform %s: %s", w.path, output.Error)
+	}
+	if err != nil || !changed {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return err
+	}
+
+	if output.Status != 0 {
+		resp.StatusCode = output.Status
+		resp.Status = strconv.Itoa(output.Status) + " " + http.StatusText(output.Status)
+	}
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	setHeaders(resp.Header, output.Headers)
+	if output.Body != nil {
+		body = []byte(*output.Body)
+		resp.Header.Del("Content-Length")
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return nil
+}
+
+// Close implements Extension, waiting for running transforms before releasing the runtime
+func (w *WASM) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.runtime.Close(context.Background())
+}
+
+// transform runs a transform function on input in a fresh instance of the module, decoding
+// its output into output. It reports false when the function leaves its input unchanged.
+func (w *WASM) transform(ctx context.Context, name string, input, output interface{}) (bool, error) {
+	document, err := json.Marshal(input)
+	if err != nil {
+		return false, err
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return false, fmt.Errorf("WASM transform %s is closed", w.path)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wasmTimeout)
+	defer cancel()
+	// Instances are anonymous so calls can run concurrently, and fresh so no state is kept
+	// from one call to the next
+	instance, err := w.runtime.InstantiateModule(ctx, w.module, wazero.NewModuleConfig().WithName(""))
+	if err != nil {
+		return false, fmt.Errorf("WASM transform %s: %w", w.path, err)
+	}
+	defer instance.Close(context.Background())
+
+	results, err := instance.ExportedFunction(wasmAllocExport).Call(ctx, uint64(len(document)))
+	if err != nil {
+		return false, fmt.Errorf("WASM transform %s: %s: %w", w.path, wasmAllocExport, err)
+	}
+	pointer := uint32(results[0])
+	if !instance.Memory().Write(pointer, document) {
+		return false, fmt.Errorf("WASM transform %s: %s returned a buffer outside its memory", w.path, wasmAllocExport)
+	}
+
+	results, err = instance.ExportedFunction(name).Call(ctx, uint64(pointer), uint64(len(document)))
+	if err != nil {
+		return false, fmt.Errorf("WASM transform %s: %s: %w", w.path, name, err)
+	}
+	outputPointer, outputLength := uint32(results[0]>>32), uint32(results[0])
+	if outputLength == 0 {
+		return false, nil
+	}
+	result, ok := instance.Memory().Read(outputPointer, outputLength)
+	if !ok {
+		return false, fmt.Errorf("WASM transform %s: %s returned a document outside its memory", w.path, name)
+	}
+	if err := json.Unmarshal(result, output); err != nil {
+		return false, fmt.Errorf("WASM transform %s: %s returned an invalid document: %w", w.path, name, err)
+	}
+	return true, nil
+}
+
+// readWASMBody reads a body passed to a transform, refusing bodies above maxWASMBody
+func readWASMBody(body io.Reader) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxWASMBody+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxWASMBody {
+		return nil, fmt.Errorf("body exceeds the %d MB WASM transforms accept", maxWASMBody>>20)
+	}
+	return data, nil
+}
+
+// setRequestBody replaces the body of req with body, which retries can send again; without a
+// body, the request is left without one
+func setRequestBody(req *http.Request, body []byte, hasBody bool) {
+	if !hasBody {
+		return
+	}
+	if len(body) == 0 {
+		// An empty body that is not http.NoBody is sent chunked
+		req.Body, req.GetBody, req.ContentLength = http.NoBody, nil, 0
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+}
+
+// setHeaders applies the headers returned by a transform, an empty list removing the header
+func setHeaders(header http.Header, changes map[string][]string) {
+	for name, values := range changes {
+		if len(values) == 0 {
+			header.Del(name)
+			continue
+		}
+		header[http.CanonicalHeaderKey(name)] = values
+	}
+}
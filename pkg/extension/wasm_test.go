@@ -0,0 +1,252 @@
+package extension
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// WebAssembly instructions used by the test modules
+var (
+	wasmEcho = []byte{ // Returns its input unchanged: (pointer << 32) | length
+		0x20, 0x00, 0xad, 0x42, 0x20, 0x86, // local.get 0, i64.extend_i32_u, i64.const 32, i64.shl
+		0x20, 0x01, 0xad, 0x84, // local.get 1, i64.extend_i32_u, i64.or
+	}
+	wasmLoop = []byte{0x03, 0x40, 0x0c, 0x00, 0x0b, 0x42, 0x00} // loop, br 0, end, i64.const 0
+)
+
+// wasmReturn returns the instructions returning the document at offset in the data segment
+func wasmReturn(offset int, document string) []byte {
+	return append([]byte{0x42}, sleb128(int64(offset)<<32|int64(len(document)))...)
+}
+
+// wasmModule assembles a module exporting memory, alloc and, for non-nil code, the transform
+// functions; data is written to the start of its memory
+func wasmModule(request, response []byte, data string) []byte {
+	section := func(id byte, items ...[]byte) []byte {
+		content := uleb128(uint64(len(items)))
+		for _, item := range items {
+			content = append(content, item...)
+		}
+		return append(append([]byte{id}, uleb128(uint64(len(content)))...), content...)
+	}
+	name := func(s string) []byte { return append(uleb128(uint64(len(s))), s...) }
+	export := func(s string, kind, index byte) []byte { return append(name(s), kind, index) }
+	body := func(code []byte) []byte {
+		code = append(append([]byte{0x00}, code...), 0x0b) // No locals, code, end
+		return append(uleb128(uint64(len(code))), code...)
+	}
+
+	functions := [][]byte{{0x00}}
+	exports := [][]byte{export("memory", 0x02, 0), export("alloc", 0x00, 0)}
+	bodies := [][]byte{body([]byte{0x41, 0x80, 0x08})} // i32.const 1024
+	for _, transform := range []struct {
+		name string
+		code []byte
+	}{{"transform_request", request}, {"transform_response", response}} {
+		if transform.code == nil {
+			continue
+		}
+		exports = append(exports, export(transform.name, 0x00, byte(len(functions))))
+		functions = append(functions, []byte{0x01})
+		bodies = append(bodies, body(transform.code))
+	}
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = append(module, section(0x01,
+		[]byte{0x60, 0x01, 0x7f, 0x01, 0x7f},       // (i32) -> i32
+		[]byte{0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e}, // (i32, i32) -> i64
+	)...)
+	module = append(module, section(0x03, functions...)...)
+	module = append(module, section(0x05, []byte{0x00, 0x01})...) // One page
+	module = append(module, section(0x07, exports...)...)
+	module = append(module, section(0x0a, bodies...)...)
+	segment := append([]byte{0x00, 0x41, 0x00, 0x0b}, name(data)...) // At offset 0
+	return append(module, section(0x0b, segment)...)
+}
+
+func uleb128(v uint64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func sleb128(v int64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func openTestWASM(t *testing.T, module []byte) *WASM {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "transform.wasm")
+	if err := os.WriteFile(path, module, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w, err := OpenWASM(path)
+	if err != nil {
+		t.Fatalf("Failed to open module: %v", err)
+	}
+	return w
+}
+
+func TestWASM_Transforms(t *testing.T) {
+	response := `{"status":200,"headers":{"X-Upstream":[]},"body":"{\"rewritten\":true}"}`
+	w := openTestWASM(t, wasmModule(wasmEcho, wasmReturn(0, response), response))
+	ctx := WithTool(context.Background(), "get_user")
+
+	// Echoing the request leaves it as it was
+	req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/users?id=1", strings.NewReader(`{"name":"Ada"}`))
+	req.Header.Set("Authorization", "Bearer t")
+	if err := w.OnUpstreamRequest(ctx, req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"name":"Ada"}` || req.Header.Get("Authorization") != "Bearer t" || req.URL.String() != "https://api.example.com/users?id=1" {
+		t.Errorf("Expected the request unchanged, got %s %v %s", req.URL, req.Header, body)
+	}
+	if req.GetBody == nil || req.ContentLength != int64(len(body)) {
+		t.Error("Expected the body to be resendable")
+	}
+
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"X-Upstream": {"1"}, "Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id":1}`)),
+	}
+	if err := w.OnUpstreamResponse(ctx, resp); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	if string(body) != `{"rewritten":true}` || resp.ContentLength != int64(len(body)) {
+		t.Errorf("Expected the rewritten body, got %s", body)
+	}
+	if resp.Header.Get("X-Upstream") != "" || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected X-Upstream removed and the other headers kept, got %v", resp.Header)
+	}
+}
+
+func TestWASM_RequestRewrites(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		message string
+	}{
+		{"headers and path", `{"url":"https://api.example.com/v2/users","headers":{"X-Transform":["yes"],"X-Remove":[]}}`, ""},
+		{"other host", `{"url":"https://attacker.example.com/users"}`, "may not send the request to another host"},
+		{"rejected", `{"error":"blocked by policy"}`, "blocked by policy"},
+		{"invalid output", `{"headers":`, "returned an invalid document"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := openTestWASM(t, wasmModule(wasmReturn(0, tt.output), nil, tt.output))
+			req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/users", nil)
+			req.Header.Set("X-Remove", "1")
+			err := w.OnUpstreamRequest(context.Background(), req)
+			if tt.message != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.message) {
+					t.Errorf("Expected %q, got %v", tt.message, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if req.URL.Path != "/v2/users" || req.Header.Get("X-Transform") != "yes" || req.Header.Get("X-Remove") != "" {
+				t.Errorf("Expected the rewritten request, got %s %v", req.URL, req.Header)
+			}
+			if req.Body != nil {
+				t.Error("Expected a request without a body to stay without one")
+			}
+		})
+	}
+}
+
+func TestWASM_Sandbox(t *testing.T) {
+	// Transforms that never return are stopped
+	w := openTestWASM(t, wasmModule(wasmLoop, nil, ""))
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/users", nil)
+	start := time.Now()
+	if err := w.OnUpstreamRequest(context.Background(), req); err == nil {
+		t.Error("Expected a transform that never returns to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the transform to be stopped after its timeout, took %s", elapsed)
+	}
+
+	// Modules importing host functions are refused
+	module := wasmModule(wasmEcho, nil, "")
+	imports := []byte{0x02, 0x0d, 0x01, 0x03, 'e', 'n', 'v', 0x05, 'f', 'e', 't', 'c', 'h', 0x00, 0x00} // env.fetch
+	end := 8 + 14                                                                                       // The header and the type section
+	module = append(module[:end:end], append(imports, module[end:]...)...)
+	path := filepath.Join(t.TempDir(), "imports.wasm")
+	if err := os.WriteFile(path, module, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenWASM(path); err == nil || !strings.Contains(err.Error(), "may not import") {
+		t.Errorf("Expected a module with imports to be refused, got %v", err)
+	}
+
+	// Modules without transforms are refused
+	path = filepath.Join(t.TempDir(), "empty.wasm")
+	if err := os.WriteFile(path, wasmModule(nil, nil, ""), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenWASM(path); err == nil || !strings.Contains(err.Error(), "exports neither") {
+		t.Errorf("Expected a module without transforms to be refused, got %v", err)
+	}
+}
+
+func TestForTools(t *testing.T) {
+	var calls []string
+	ext := ForTools(recordingExtension{name: "filtered", calls: &calls}, func(tool string) bool {
+		return tool == "get_user"
+	})
+	_ = ext.OnToolCall(context.Background(), &ToolCall{Tool: "delete_user"})
+	_ = ext.OnToolCall(context.Background(), &ToolCall{Tool: "get_user"})
+	if strings.Join(calls, ",") != "filtered" {
+		t.Errorf("Expected the hook to run for get_user only, got %v", calls)
+	}
+
+	// Upstream hooks find the tool in the context
+	if ToolFromContext(WithTool(context.Background(), "get_user")) != "get_user" {
+		t.Error("Expected the tool name from the context")
+	}
+	if ToolFromContext(context.Background()) != "" {
+		t.Error("Expected no tool name without one in the context")
+	}
+}
+
+func TestWASM_Close(t *testing.T) {
+	w := openTestWASM(t, wasmModule(wasmEcho, nil, ""))
+	chain := Chain{ForTools(w, func(string) bool { return true }), Base{}}
+	if err := chain.Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Closing releases the runtime, so later calls fail instead of running the module
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/users", nil)
+	if err := w.OnUpstreamRequest(context.Background(), req); err == nil || !strings.Contains(err.Error(), "is closed") {
+		t.Errorf("Expected calls after Close to fail, got %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Expected closing twice to succeed, got %v", err)
+	}
+}
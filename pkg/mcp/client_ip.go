@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// clientIPContextKey is the context key for the client address of a request
+type clientIPContextKey struct{}
+
+// clientIP returns the address of the client that sent r. X-Forwarded-For and X-Real-IP are
+// only believed when the peer is a trusted proxy: X-Forwarded-For is then read from the right,
+// skipping the trusted proxies each hop added, so clients cannot pose as another address by
+// sending the header themselves.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+	addr, err := netip.ParseAddr(peer)
+	if err != nil || !isTrustedProxy(addr, trusted) {
+		return peer
+	}
+
+	if forwarded := forwardedFor(r.Header); len(forwarded) > 0 {
+		client := addr
+		for i := len(forwarded) - 1; i >= 0; i-- {
+			hop, ok := parseForwardedAddr(forwarded[i])
+			if !ok {
+				break
+			}
+			client = hop
+			if !isTrustedProxy(hop, trusted) {
+				break
+			}
+		}
+		return client.String()
+	}
+	if hop, ok := parseForwardedAddr(r.Header.Get("X-Real-IP")); ok {
+		return hop.String()
+	}
+	return addr.Unmap().String()
+}
+
+// forwardedFor returns the addresses of every X-Forwarded-For header, in order
+func forwardedFor(header http.Header) []string {
+	var addrs []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, addr := range strings.Split(value, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	return addrs
+}
+
+// parseForwardedAddr parses an address from a forwarding header, with or without a port
+func parseForwardedAddr(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return addr.Unmap(), true
+	}
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	return netip.Addr{}, false
+}
+
+// isTrustedProxy reports whether addr is in one of the trusted proxy ranges
+func isTrustedProxy(addr netip.Addr, trusted []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.0.2.1/32")}
+
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		forwardedFor   []string
+		realIP         string
+		expectedAddr   string
+		trustedProxies []netip.Prefix
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "", "203.0.113.7", trusted},
+		{"untrusted peer ignores headers", "203.0.113.7:5000", []string{"198.51.100.1"}, "198.51.100.2", "203.0.113.7", trusted},
+		{"no trusted proxies", "10.0.0.1:5000", []string{"198.51.100.1"}, "", "10.0.0.1", nil},
+		{"trusted proxy", "10.0.0.1:5000", []string{"198.51.100.1"}, "", "198.51.100.1", trusted},
+		{"spoofed entries before the client", "10.0.0.1:5000", []string{"1.2.3.4, 198.51.100.1"}, "", "198.51.100.1", trusted},
+		{"chain of trusted proxies", "10.0.0.1:5000", []string{"198.51.100.1, 192.0.2.1", "10.0.0.2"}, "", "198.51.100.1", trusted},
+		{"only trusted hops", "10.0.0.1:5000", []string{"10.0.0.3, 10.0.0.2"}, "", "10.0.0.3", trusted},
+		{"invalid entry", "10.0.0.1:5000", []string{"garbage, 10.0.0.2"}, "", "10.0.0.2", trusted},
+		{"real ip", "10.0.0.1:5000", nil, "198.51.100.9", "198.51.100.9", trusted},
+		{"entry with port", "10.0.0.1:5000", []string{"198.51.100.1:443"}, "", "198.51.100.1", trusted},
+		{"ipv6 client", "[2001:db8::1]:5000", nil, "", "2001:db8::1", trusted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/mcp", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := clientIP(req, tt.trustedProxies); got != tt.expectedAddr {
+				t.Errorf("Expected client %s, got %s", tt.expectedAddr, got)
+			}
+		})
+	}
+}
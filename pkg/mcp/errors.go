@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+// errorMapping is a configured error mapping with its pattern compiled
+type errorMapping struct {
+	statuses []string
+	match    *regexp.Regexp // nil when any message matches
+	code     int
+	message  string
+}
+
+// SetErrorMappings sets the MCP errors returned for the failed tool calls they match, ahead of
+// the built-in categories. Mappings with an invalid pattern are skipped.
+func (s *Server) SetErrorMappings(mappings []config.ErrorMapping) {
+	compiled := make([]errorMapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		entry := errorMapping{statuses: mapping.Statuses, code: mapping.Code, message: mapping.Message}
+		if mapping.Match != "" {
+			pattern, err := regexp.Compile(mapping.Match)
+			if err != nil {
+				log.Printf("WARNING: Ignoring error mapping with invalid match %q: %v", mapping.Match, err)
+				continue
+			}
+			entry.match = pattern
+		}
+		compiled = append(compiled, entry)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errorMappings = compiled
+}
+
+// matches reports whether the mapping applies to err, whose upstream status and response body
+// are status and body, or 0 and "" when the upstream did not answer
+func (m errorMapping) matches(err error, status int, body string) bool {
+	if len(m.statuses) > 0 {
+		matched := false
+		for _, pattern := range m.statuses {
+			if status != 0 && config.MatchStatus(pattern, status) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return m.match == nil || m.match.MatchString(err.Error()) || m.match.MatchString(body)
+}
+
+// categorizeToolError returns the MCP error code and message of a failed tool call: those of
+// the first configured mapping matching it, or else those of its type
+func (s *Server) categorizeToolError(err error) (int, string) {
+	if err == nil {
+		return 0, ""
+	}
+
+	if errors.Is(err, ErrShuttingDown) {
+		return ErrorCodeServiceUnavailable, "Server is shutting down"
+	}
+
+	status, body := 0, ""
+	var upstream *types.UpstreamError
+	if errors.As(err, &upstream) {
+		status, body = upstream.StatusCode, upstream.Body
+	}
+	s.mu.RLock()
+	mappings := s.errorMappings
+	s.mu.RUnlock()
+	for _, mapping := range mappings {
+		if mapping.matches(err, status, body) {
+			return mapping.code, mapping.message
+		}
+	}
+
+	if upstream != nil {
+		return categorizeStatus(status)
+	}
+
+	var argumentErr *types.ArgumentError
+	var netErr net.Error
+	isNetErr := errors.As(err, &netErr)
+	switch {
+	case errors.As(err, &argumentErr):
+		return ErrorCodeToolParameterError, "Invalid or missing parameters for tool execution"
+	case errors.Is(err, types.ErrRateLimited):
+		return ErrorCodeToolValidationError, "Rate limit exceeded"
+	case errors.Is(err, types.ErrAuthentication):
+		return ErrorCodeToolAuthenticationError, "Authentication failed during tool execution"
+	case errors.Is(err, context.DeadlineExceeded) || (isNetErr && netErr.Timeout()):
+		return ErrorCodeToolTimeoutError, "Tool execution timed out"
+	case isNetErr:
+		return ErrorCodeToolNetworkError, "Network error during tool execution"
+	case isSerializationError(err):
+		return ErrorCodeToolSerializationError, "Data serialization error during tool execution"
+	}
+	return ErrorCodeToolExecutionFailed, "Tool execution failed"
+}
+
+// maxErrorBodySnippet is the most bytes of the upstream response body given in the data of a
+// tool call error
+const maxErrorBodySnippet = 512
+
+// toolErrorData returns the data of the error answering a failed tool call: the error message
+// with, when the upstream was reached, its status, the start of its response body, its request
+// ID and the requested URL without credentials. Only the start of the upstream body reaches the
+// client.
+func toolErrorData(err error) types.ToolErrorData {
+	data := types.ToolErrorData{Error: err.Error()}
+	var upstream *types.UpstreamError
+	var requestErr *types.RequestError
+	switch {
+	case errors.As(err, &upstream):
+		data.StatusCode = upstream.StatusCode
+		data.Body = bodySnippet(upstream.Body)
+		data.RequestID = upstream.RequestID
+		data.URL = upstream.URL
+	case errors.As(err, &requestErr):
+		data.URL = requestErr.URL
+	}
+	return data
+}
+
+// bodySnippet returns the body whole or, when longer than maxErrorBodySnippet, cut at a rune
+// boundary and followed by "…"
+func bodySnippet(body string) string {
+	body = strings.TrimSpace(body)
+	if len(body) <= maxErrorBodySnippet {
+		return body
+	}
+	cut := maxErrorBodySnippet
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return body[:cut] + "…"
+}
+
+// isSerializationError reports whether err comes from encoding or decoding JSON
+func isSerializationError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var marshalerErr *json.MarshalerError
+	var unsupportedErr *json.UnsupportedTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) ||
+		errors.As(err, &marshalerErr) || errors.As(err, &unsupportedErr)
+}
+
+// categorizeStatus returns the MCP error code and message of an upstream error status
+func categorizeStatus(status int) (int, string) {
+	switch {
+	case status == 400:
+		return ErrorCodeToolValidationError, "Invalid request parameters"
+	case status == 401:
+		return ErrorCodeToolAuthenticationError, "Authentication required"
+	case status == 403:
+		return ErrorCodeToolAuthenticationError, "Access forbidden"
+	case status == 404:
+		return ErrorCodeToolValidationError, "Resource not found"
+	case status == 422:
+		return ErrorCodeToolValidationError, "Request validation failed"
+	case status == 429:
+		return ErrorCodeToolValidationError, "Rate limit exceeded"
+	case status >= 500:
+		return ErrorCodeToolExecutionFailed, "Server error during tool execution"
+	}
+	return ErrorCodeToolExecutionFailed, "Tool execution failed"
+}
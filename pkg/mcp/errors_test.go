@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+func TestServer_CategorizeToolError(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"upstream 404", &types.UpstreamError{StatusCode: 404, Body: "missing"}, ErrorCodeToolValidationError},
+		{"upstream 401 after retries", fmt.Errorf("failed to fetch page 2: %w", &types.UpstreamError{StatusCode: 401}), ErrorCodeToolAuthenticationError},
+		{"upstream 503", &types.UpstreamError{StatusCode: 503}, ErrorCodeToolExecutionFailed},
+		{"missing argument", types.ArgumentErrorf("required query parameter '%s' not provided", "q"), ErrorCodeToolParameterError},
+		{"rate limited", fmt.Errorf("%w for tool list_items", types.ErrRateLimited), ErrorCodeToolValidationError},
+		{"auth", fmt.Errorf("%w: no token", types.ErrAuthentication), ErrorCodeToolAuthenticationError},
+		{"timeout", fmt.Errorf("failed to make request after 1 attempts: %w", context.DeadlineExceeded), ErrorCodeToolTimeoutError},
+		{"network", &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}, ErrorCodeToolNetworkError},
+		{"serialization", fmt.Errorf("failed to encode: %w", syntaxErr), ErrorCodeToolSerializationError},
+		// Messages are not sniffed for keywords
+		{"untyped", fmt.Errorf("invalid parameter in json: status 404 timeout"), ErrorCodeToolExecutionFailed},
+	}
+
+	server := NewServer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _ := server.categorizeToolError(tt.err); code != tt.code {
+				t.Errorf("expected code %d, got %d", tt.code, code)
+			}
+		})
+	}
+}
+
+func TestServer_ErrorMappings(t *testing.T) {
+	server := NewServer()
+	server.SetErrorMappings([]config.ErrorMapping{
+		{Statuses: []string{"409"}, Match: "already exists", Code: ErrorCodeDuplicateResource, Message: "Item already exists"},
+		{Statuses: []string{"4xx"}, Code: ErrorCodeValidationFailed, Message: "Rejected by the API"},
+		{Match: "quota", Code: ErrorCodeQuotaExceeded, Message: "Quota exceeded"},
+	})
+
+	tests := []struct {
+		name    string
+		err     error
+		code    int
+		message string
+	}{
+		{"status and match", &types.UpstreamError{StatusCode: 409, Body: `{"error": "item already exists"}`}, ErrorCodeDuplicateResource, "Item already exists"},
+		{"status class", &types.UpstreamError{StatusCode: 409, Body: `{"error": "locked"}`}, ErrorCodeValidationFailed, "Rejected by the API"},
+		{"match only", &types.UpstreamError{StatusCode: 503, Body: "daily quota reached"}, ErrorCodeQuotaExceeded, "Quota exceeded"},
+		{"statuses need a response", fmt.Errorf("dial failed: 409"), ErrorCodeToolExecutionFailed, "Tool execution failed"},
+		{"built-in category", &types.UpstreamError{StatusCode: 500}, ErrorCodeToolExecutionFailed, "Server error during tool execution"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, message := server.categorizeToolError(tt.err)
+			if code != tt.code || message != tt.message {
+				t.Errorf("expected %d %q, got %d %q", tt.code, tt.message, code, message)
+			}
+		})
+	}
+}
+
+func TestToolErrorData(t *testing.T) {
+	long := strings.Repeat("é", maxErrorBodySnippet)
+	tests := []struct {
+		name     string
+		err      error
+		expected types.ToolErrorData
+	}{
+		{
+			"upstream",
+			fmt.Errorf("failed to fetch page 2: %w", &types.UpstreamError{StatusCode: 409, Body: "exists\n", RequestID: "req-7", URL: "https://api.example.com/items"}),
+			types.ToolErrorData{Error: "failed to fetch page 2: API request failed with status 409", StatusCode: 409, Body: "exists", RequestID: "req-7", URL: "https://api.example.com/items"},
+		},
+		{
+			"no answer",
+			&types.RequestError{URL: "https://api.example.com/items", Err: context.DeadlineExceeded},
+			types.ToolErrorData{Error: "context deadline exceeded", URL: "https://api.example.com/items"},
+		},
+		{
+			"argument",
+			types.ArgumentErrorf("required query parameter 'q' not provided"),
+			types.ToolErrorData{Error: "required query parameter 'q' not provided"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if data := toolErrorData(tt.err); data != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, data)
+			}
+		})
+	}
+
+	// Long bodies are cut at a rune boundary
+	data := toolErrorData(&types.UpstreamError{StatusCode: 500, Body: long})
+	if !strings.HasSuffix(data.Body, "…") || !utf8.ValidString(data.Body) || len(data.Body) > maxErrorBodySnippet+len("…") {
+		t.Errorf("expected a valid snippet of at most %d bytes, got %d bytes", maxErrorBodySnippet, len(data.Body))
+	}
+	if data.Error != "API request failed with status 500" {
+		t.Errorf("expected the body left out of the message, got %d bytes", len(data.Error))
+	}
+}
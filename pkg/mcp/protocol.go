@@ -2,12 +2,18 @@ package mcp
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"strings"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"mcpify/internal/config"
 	"mcpify/internal/types"
@@ -74,15 +80,58 @@ const (
 	ErrorCodeToolParameterError      = -4006
 )
 
+// ErrToolNotFound is returned when calling a tool that is not registered
+var ErrToolNotFound = errors.New("tool not found")
+
+// ErrToolDisabled is returned when calling a tool disabled with SetToolEnabled
+var ErrToolDisabled = errors.New("tool disabled")
+
+// ErrShuttingDown is returned for tool calls refused with StopCalls or aborted with AbortCalls
+var ErrShuttingDown = errors.New("server is shutting down")
+
 type Server struct {
+	mu      sync.RWMutex
 	tools   map[string]ToolHandler
 	schemas map[string]ToolSchema
+	version string
+	// disabled holds tools hidden from clients at runtime; it is kept when tools are replaced
+	disabled map[string]bool
+	// debug holds tools whose calls are logged at runtime; it is kept when tools are replaced
+	debug map[string]bool
+	// toolsList caches the encoded tools/list result until the registered tools change
+	toolsList json.RawMessage
+	// calls counts the tool calls in progress, which shutdown waits for
+	calls atomic.Int64
+	// stopped refuses new tool calls once shutdown starts waiting for those in progress
+	stopped atomic.Bool
+	// aborted is closed when shutdown gives up waiting, ending the calls still in progress
+	aborted   chan struct{}
+	abortOnce sync.Once
+	// notifiers deliver notifications to the clients of each transport
+	notifiersMu sync.RWMutex
+	notifiers   []func(types.MCPNotification)
+	// errorMappings are the configured MCP errors of failed tool calls, checked in order
+	errorMappings []errorMapping
+	// maxRequestSize caps the encoded parameters of tool calls in bytes; 0 means no limit
+	maxRequestSize int64
+	// tracer logs the messages of the transports, set with SetTracer
+	tracer *Tracer
 }
 
 type ToolSchema struct {
 	Name        string
 	Description string
 	InputSchema map[string]interface{}
+
+	resolve func() map[string]interface{} // builds InputSchema for tools registered with RegisterLazyTool
+}
+
+// resolved returns the schema with a deferred input schema built
+func (t ToolSchema) resolved() ToolSchema {
+	if t.InputSchema == nil && t.resolve != nil {
+		t.InputSchema = t.resolve()
+	}
+	return t
 }
 
 type ToolHandler func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error)
@@ -96,92 +145,337 @@ type Transport interface {
 // StdioTransport implements stdio transport for MCP protocol
 type StdioTransport struct {
 	server *Server
+	in     io.Reader
+	out    io.Writer
+	// framing is how messages are delimited, one of the config.StdioFraming values; with
+	// auto, the framing of the first message is used for the rest of the session
+	framing string
+	// busy is held while a request is handled and answered; Stop takes it for good
+	busy sync.Mutex
+	// writeMu keeps notifications from interleaving with responses on stdout, and guards
+	// the framing detected in auto mode
+	writeMu sync.Mutex
 }
 
 // NewStdioTransport creates a new stdio transport instance
 func NewStdioTransport(server *Server) *StdioTransport {
-	return &StdioTransport{server: server}
+	st := &StdioTransport{server: server, in: os.Stdin, out: os.Stdout, framing: config.StdioFramingAuto}
+	server.OnNotification(func(notification types.MCPNotification) { st.writeMessage(notification) })
+	return st
+}
+
+// SetFraming sets how messages are delimited, one of the config.StdioFraming values. Set it
+// before starting the transport.
+func (st *StdioTransport) SetFraming(framing string) {
+	st.writeMu.Lock()
+	defer st.writeMu.Unlock()
+	st.framing = framing
 }
 
 func NewServer() *Server {
 	return &Server{
-		tools:   make(map[string]ToolHandler),
-		schemas: make(map[string]ToolSchema),
+		tools:    make(map[string]ToolHandler),
+		schemas:  make(map[string]ToolSchema),
+		version:  "dev",
+		disabled: make(map[string]bool),
+		debug:    make(map[string]bool),
+		aborted:  make(chan struct{}),
 	}
 }
 
+// SetMaxRequestSize sets the largest tool call parameters, in bytes, that are handled; larger
+// calls fail with invalid params. Pass 0 for no limit.
+func (s *Server) SetMaxRequestSize(limit int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxRequestSize = limit
+}
+
+// MaxRequestSize returns the limit set with SetMaxRequestSize
+func (s *Server) MaxRequestSize() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.maxRequestSize
+}
+
+// SetVersion sets the version reported in the initialize serverInfo
+func (s *Server) SetVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = version
+}
+
 func (s *Server) RegisterTool(name string, description string, inputSchema map[string]interface{}, handler ToolHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.tools[name] = handler
 	s.schemas[name] = ToolSchema{
 		Name:        name,
 		Description: description,
 		InputSchema: inputSchema,
 	}
+	s.toolsList = nil
+}
+
+// RegisterLazyTool registers a tool whose input schema is built the first time the tool is listed.
+// The schema is built once and shared, which keeps startup fast for specs with thousands of schemas.
+func (s *Server) RegisterLazyTool(name string, description string, inputSchema func() map[string]interface{}, handler ToolHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools[name] = handler
+	s.schemas[name] = ToolSchema{
+		Name:        name,
+		Description: description,
+		resolve:     sync.OnceValue(inputSchema),
+	}
+	s.toolsList = nil
+}
+
+// UnregisterTool removes a tool, reporting whether it was registered. Calls already in
+// progress finish with the handler they started with.
+func (s *Server) UnregisterTool(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.tools[name]; !exists {
+		return false
+	}
+	delete(s.tools, name)
+	delete(s.schemas, name)
+	s.toolsList = nil
+	return true
 }
 
-// categorizeToolError analyzes an error and returns appropriate MCP error code and message
-func categorizeToolError(err error) (int, string) {
-	if err == nil {
-		return 0, ""
+// ReplaceTools atomically replaces all registered tools with the tools registered on other
+// Calls already in progress finish with the handler they started with
+func (s *Server) ReplaceTools(other *Server) {
+	other.mu.RLock()
+	tools := make(map[string]ToolHandler, len(other.tools))
+	for name, handler := range other.tools {
+		tools[name] = handler
+	}
+	schemas := make(map[string]ToolSchema, len(other.schemas))
+	for name, schema := range other.schemas {
+		schemas[name] = schema
 	}
+	other.mu.RUnlock()
+
+	s.mu.Lock()
+	s.tools = tools
+	s.schemas = schemas
+	s.toolsList = nil
+	s.mu.Unlock()
+	s.notifyToolsChanged()
+}
 
-	errStr := err.Error()
-	errLower := strings.ToLower(errStr)
+// OnNotification registers fn to deliver the server's notifications to the clients of a
+// transport. fn must not block.
+func (s *Server) OnNotification(fn func(types.MCPNotification)) {
+	s.notifiersMu.Lock()
+	defer s.notifiersMu.Unlock()
+	s.notifiers = append(s.notifiers, fn)
+}
 
-	// Network-related errors
-	if strings.Contains(errLower, "timeout") || strings.Contains(errLower, "deadline exceeded") {
-		return ErrorCodeToolTimeoutError, "Tool execution timed out"
+// notifyToolsChanged tells clients to list the tools again
+func (s *Server) notifyToolsChanged() {
+	s.notifiersMu.RLock()
+	defer s.notifiersMu.RUnlock()
+	notification := types.MCPNotification{JSONRPC: "2.0", Method: "notifications/tools/list_changed"}
+	for _, notify := range s.notifiers {
+		notify(notification)
 	}
-	if strings.Contains(errLower, "connection refused") || strings.Contains(errLower, "no such host") ||
-		strings.Contains(errLower, "network is unreachable") || strings.Contains(errLower, "connection reset") {
-		return ErrorCodeToolNetworkError, "Network error during tool execution"
+}
+
+// Tool returns the schema of a registered tool
+func (s *Server) Tool(name string) (ToolSchema, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	schema, exists := s.schemas[name]
+	return schema.resolved(), exists
+}
+
+// Tools returns the schemas of the registered tools sorted by name
+func (s *Server) Tools() []ToolSchema {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedTools()
+}
+
+// sortedTools returns the resolved schemas sorted by name; the caller must hold s.mu
+func (s *Server) sortedTools() []ToolSchema {
+	tools := make([]ToolSchema, 0, len(s.schemas))
+	for _, schema := range s.schemas {
+		tools = append(tools, schema.resolved())
 	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
 
-	// Authentication errors
-	if strings.Contains(errLower, "unauthorized") || strings.Contains(errLower, "authentication failed") ||
-		strings.Contains(errLower, "invalid credentials") || strings.Contains(errLower, "token expired") ||
-		strings.Contains(errLower, "forbidden") || strings.Contains(errLower, "access denied") {
-		return ErrorCodeToolAuthenticationError, "Authentication failed during tool execution"
+// listTools returns the encoded tools/list result, encoding it only when the tools have
+// changed since the last listing
+func (s *Server) listTools() (json.RawMessage, error) {
+	s.mu.RLock()
+	cached := s.toolsList
+	s.mu.RUnlock()
+	if cached != nil {
+		return cached, nil
 	}
 
-	// Validation errors
-	if strings.Contains(errLower, "required") && strings.Contains(errLower, "not provided") ||
-		strings.Contains(errLower, "invalid parameter") || strings.Contains(errLower, "validation failed") ||
-		strings.Contains(errLower, "invalid format") || strings.Contains(errLower, "missing required field") {
-		return ErrorCodeToolParameterError, "Invalid or missing parameters for tool execution"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.toolsList != nil {
+		return s.toolsList, nil
+	}
+	schemas := s.sortedTools()
+	tools := make([]types.Tool, 0, len(schemas))
+	for _, schema := range schemas {
+		if s.disabled[schema.Name] {
+			continue
+		}
+		tools = append(tools, types.Tool{
+			Name:        schema.Name,
+			Description: schema.Description,
+			InputSchema: schema.InputSchema,
+		})
+	}
+	encoded, err := json.Marshal(types.ListToolsResult{Tools: tools})
+	if err != nil {
+		return nil, err
 	}
+	s.toolsList = encoded
+	return encoded, nil
+}
 
-	// Serialization errors
-	if strings.Contains(errLower, "marshal") || strings.Contains(errLower, "unmarshal") ||
-		strings.Contains(errLower, "json") || strings.Contains(errLower, "serialization") {
-		return ErrorCodeToolSerializationError, "Data serialization error during tool execution"
+// SetToolEnabled enables or disables a registered tool. Disabled tools are left out of
+// tools/list and cannot be called, and stay disabled when the tools are replaced on reload.
+func (s *Server) SetToolEnabled(name string, enabled bool) error {
+	s.mu.Lock()
+	if _, exists := s.tools[name]; !exists {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	}
+	if enabled {
+		delete(s.disabled, name)
+	} else {
+		s.disabled[name] = true
 	}
+	s.toolsList = nil
+	s.mu.Unlock()
+	s.notifyToolsChanged()
+	return nil
+}
 
-	// API-specific errors (HTTP status codes)
-	if strings.Contains(errLower, "status 400") || strings.Contains(errLower, "bad request") {
-		return ErrorCodeToolValidationError, "Invalid request parameters"
+// IsToolEnabled reports whether a tool has not been disabled with SetToolEnabled
+func (s *Server) IsToolEnabled(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.disabled[name]
+}
+
+// SetToolDebug enables or disables debug logging of the upstream requests and responses of a
+// registered tool, whatever the debug setting of its API. It stays set when the tools are
+// replaced on reload.
+func (s *Server) SetToolDebug(name string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.tools[name]; !exists {
+		return fmt.Errorf("%w: %s", ErrToolNotFound, name)
 	}
-	if strings.Contains(errLower, "status 401") {
-		return ErrorCodeToolAuthenticationError, "Authentication required"
+	if enabled {
+		s.debug[name] = true
+	} else {
+		delete(s.debug, name)
+	}
+	return nil
+}
+
+// IsToolDebug reports whether debug logging was enabled for a tool with SetToolDebug
+func (s *Server) IsToolDebug(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.debug[name]
+}
+
+// handler returns the handler of an enabled tool
+func (s *Server) handler(name string) (ToolHandler, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	handler, exists := s.tools[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrToolNotFound, name)
 	}
-	if strings.Contains(errLower, "status 403") {
-		return ErrorCodeToolAuthenticationError, "Access forbidden"
+	if s.disabled[name] {
+		return nil, fmt.Errorf("%w: %s", ErrToolDisabled, name)
 	}
-	if strings.Contains(errLower, "status 404") {
-		return ErrorCodeToolValidationError, "Resource not found"
+	return handler, nil
+}
+
+// CallTool invokes a registered tool directly, without the JSON-RPC envelope
+func (s *Server) CallTool(name string, arguments map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+	handler, err := s.handler(name)
+	if err != nil {
+		return nil, err
 	}
-	if strings.Contains(errLower, "status 422") {
-		return ErrorCodeToolValidationError, "Request validation failed"
+	requestContext.Debug = requestContext.Debug || s.IsToolDebug(name)
+	return s.invoke(handler, arguments, requestContext)
+}
+
+// invoke runs a tool handler, counting the call as in flight until it returns. Calls fail
+// with ErrShuttingDown once StopCalls is called, and when AbortCalls is called before the
+// handler returns.
+func (s *Server) invoke(handler ToolHandler, arguments map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+	s.calls.Add(1)
+	defer s.calls.Add(-1)
+	if s.stopped.Load() {
+		return nil, ErrShuttingDown
 	}
-	if strings.Contains(errLower, "status 429") {
-		return ErrorCodeToolValidationError, "Rate limit exceeded"
+
+	type outcome struct {
+		result interface{}
+		err    error
 	}
-	if strings.Contains(errLower, "status 5") {
-		return ErrorCodeToolExecutionFailed, "Server error during tool execution"
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := handler(arguments, requestContext)
+		done <- outcome{result, err}
+	}()
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-s.aborted:
+		return nil, ErrShuttingDown
 	}
+}
+
+// StopCalls refuses new tool calls, letting those in progress finish
+func (s *Server) StopCalls() {
+	s.stopped.Store(true)
+}
+
+// AbortCalls ends the tool calls in progress with ErrShuttingDown, so their clients get an
+// error rather than a severed connection. Their upstream requests are left to finish unseen.
+func (s *Server) AbortCalls() {
+	s.StopCalls()
+	s.abortOnce.Do(func() { close(s.aborted) })
+}
 
-	// Default to generic tool execution error
-	return ErrorCodeToolExecutionFailed, "Tool execution failed"
+// InFlightCalls returns the number of tool calls in progress
+func (s *Server) InFlightCalls() int {
+	return int(s.calls.Load())
+}
+
+// WaitForCalls waits until no tool calls are in progress, or returns the context's error
+// when it ends first
+func (s *Server) WaitForCalls(ctx context.Context) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for s.InFlightCalls() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
 }
 
 func (s *Server) HandleRequest(req types.MCPRequest, requestContext config.RequestContext) types.MCPResponse {
@@ -192,35 +486,49 @@ func (s *Server) HandleRequest(req types.MCPRequest, requestContext config.Reque
 
 	switch req.Method {
 	case "initialize":
+		s.mu.RLock()
+		version := s.version
+		s.mu.RUnlock()
 		response.Result = map[string]interface{}{
 			"protocolVersion": "2024-11-05",
 			"capabilities": map[string]interface{}{
-				"tools": map[string]interface{}{},
+				"tools": map[string]interface{}{"listChanged": true},
 			},
 			"serverInfo": map[string]interface{}{
 				"name":    "mcpify",
-				"version": "1.0.0",
+				"version": version,
 			},
 		}
 	case "tools/list":
-		tools := []types.Tool{}
-		for _, schema := range s.schemas {
-			tool := types.Tool{
-				Name:        schema.Name,
-				Description: schema.Description,
-				InputSchema: schema.InputSchema,
+		// The encoded result is shared by every listing until the tools change
+		result, err := s.listTools()
+		if err != nil {
+			log.Printf("Failed to encode tools/list result: %v", err)
+			response.Error = &types.MCPError{
+				Code:    ErrorCodeInternalError,
+				Message: "Internal error",
+				Data:    err.Error(),
 			}
-			tools = append(tools, tool)
-
+			return response
 		}
-		response.Result = types.ListToolsResult{Tools: tools}
+		response.Result = result
 	case "notifications/initialized":
 		// Handle the initialized notification - this is sent by the client after initialize
 		// According to MCP spec, this should be acknowledged but doesn't require a response
 		response.Result = map[string]interface{}{}
 	case "tools/call":
+		if limit := s.MaxRequestSize(); limit > 0 && int64(len(req.Params)) > limit {
+			log.Printf("Tool call parameters too large - Size: %d bytes, Limit: %d bytes", len(req.Params), limit)
+			response.Error = &types.MCPError{
+				Code:    ErrorCodeInvalidParams,
+				Message: "Arguments too large",
+				Data:    fmt.Sprintf("tool call arguments of %d bytes exceed the limit of %d bytes", len(req.Params), limit),
+			}
+			return response
+		}
+
 		var params types.CallToolParams
-		if err := json.Unmarshal(req.Params, &params); err != nil {
+		if err := types.DecodeJSON(req.Params, &params); err != nil {
 			log.Printf("Tool call parameter parsing failed - Error: %v", err)
 			response.Error = &types.MCPError{
 				Code:    ErrorCodeInvalidParams,
@@ -230,20 +538,25 @@ func (s *Server) HandleRequest(req types.MCPRequest, requestContext config.Reque
 			return response
 		}
 
-		handler, exists := s.tools[params.Name]
-		if !exists {
-			log.Printf("Tool not found - Tool: %s", params.Name)
+		handler, err := s.handler(params.Name)
+		if err != nil {
+			message := "Tool not found"
+			if errors.Is(err, ErrToolDisabled) {
+				message = "Tool disabled"
+			}
+			log.Printf("%s - Tool: %s", message, params.Name)
 			response.Error = &types.MCPError{
 				Code:    ErrorCodeMethodNotFound,
-				Message: "Tool not found",
+				Message: message,
 				Data:    params.Name,
 			}
 			return response
 		}
 
-		result, err := handler(params.Arguments, requestContext)
+		requestContext.Debug = requestContext.Debug || s.IsToolDebug(params.Name)
+		result, err := s.invoke(handler, params.Arguments, requestContext)
 		if err != nil {
-			errorCode, errorMessage := categorizeToolError(err)
+			errorCode, errorMessage := s.categorizeToolError(err)
 
 			// Log the underlying error for debugging
 			log.Printf("Tool execution failed - Tool: %s, Error Code: %d, Message: %s, Details: %v",
@@ -252,20 +565,32 @@ func (s *Server) HandleRequest(req types.MCPRequest, requestContext config.Reque
 			response.Error = &types.MCPError{
 				Code:    errorCode,
 				Message: errorMessage,
-				Data:    err.Error(),
+				Data:    toolErrorData(err),
+			}
+			return response
+		}
+
+		// The result is encoded once, straight into the text of the content block
+		resultJSON, release, err := encodeJSON(result)
+		if err != nil {
+			log.Printf("Failed to encode tool result - Tool: %s, Error: %v", params.Name, err)
+			response.Error = &types.MCPError{
+				Code:    ErrorCodeInternalError,
+				Message: "Internal error",
+				Data:    fmt.Sprintf("failed to encode tool result: %v", err),
 			}
 			return response
 		}
+		text := string(bytes.TrimSuffix(resultJSON, []byte("\n")))
+		release()
 
 		// Log successful tool execution
 		log.Printf("Tool execution successful - Tool: %s", params.Name)
-
-		resultJSON, _ := json.Marshal(result)
 		response.Result = types.CallToolResult{
 			Content: []types.ContentBlock{
 				{
 					Type: "text",
-					Text: string(resultJSON),
+					Text: text,
 				},
 			},
 		}
@@ -281,6 +606,31 @@ func (s *Server) HandleRequest(req types.MCPRequest, requestContext config.Reque
 	return response
 }
 
+// maxPooledBufferSize keeps the pool from holding on to buffers grown by unusually large responses
+const maxPooledBufferSize = 1 << 20
+
+// bufferPool holds the buffers tool results and responses are encoded into
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// encodeJSON encodes v into a pooled buffer, producing the output of json.Marshal followed by a
+// newline. The bytes are valid until release is called.
+func encodeJSON(v interface{}) (data []byte, release func(), err error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	release = func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			bufferPool.Put(buf)
+		}
+	}
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		release()
+		return nil, nil, err
+	}
+	return buf.Bytes(), release, nil
+}
+
 // Run starts the stdio transport (maintained for backward compatibility)
 func (s *Server) Run() error {
 	transport := NewStdioTransport(s)
@@ -289,58 +639,128 @@ func (s *Server) Run() error {
 
 // Start implements the Transport interface for stdio transport
 func (st *StdioTransport) Start() error {
-	scanner := bufio.NewScanner(os.Stdin)
+	reader := bufio.NewReader(st.in)
+	st.writeMu.Lock()
+	framing := st.framing
+	st.writeMu.Unlock()
+	if framing == config.StdioFramingAuto {
+		framing = detectFraming(reader)
+		st.SetFraming(framing)
+	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
+	// Messages must fit the buffer, so calls just over the size limit get an error rather
+	// than ending the session
+	maxMessage := int64(bufio.MaxScanTokenSize)
+	if limit := st.server.MaxRequestSize(); limit > bufio.MaxScanTokenSize/2 {
+		maxMessage = 2 * limit
+	}
 
-		var req types.MCPRequest
-		if err := json.Unmarshal([]byte(line), &req); err != nil {
-			// Try to extract ID from the raw JSON for better error reporting
-			var rawMap map[string]interface{}
-			var responseID interface{}
-			if json.Unmarshal([]byte(line), &rawMap) == nil {
-				if id, exists := rawMap["id"]; exists {
-					responseID = id
-				}
+	if framing == config.StdioFramingContentLength {
+		for {
+			message, err := readFramedMessage(reader, maxMessage)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if errors.Is(err, errMessageTooLarge) {
+				st.writeResponse(types.MCPResponse{
+					JSONRPC: "2.0",
+					Error:   &types.MCPError{Code: ErrorCodeInvalidRequest, Message: "Parse error", Data: err.Error()},
+				})
+				continue
+			}
+			if err != nil {
+				return err
 			}
+			st.handleMessage(message)
+		}
+	}
 
-			response := types.MCPResponse{
-				JSONRPC: "2.0",
-				ID:      responseID, // Include ID if we could extract it
-				Error: &types.MCPError{
-					Code:    ErrorCodeInvalidRequest,
-					Message: "Parse error",
-					Data:    err.Error(),
-				},
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, int(maxMessage))
+	for scanner.Scan() {
+		if line := scanner.Bytes(); len(line) > 0 {
+			st.handleMessage(line)
+		}
+	}
+	return scanner.Err()
+}
+
+// handleMessage handles one JSON-RPC message and writes the response
+func (st *StdioTransport) handleMessage(message []byte) {
+	st.server.traceMessage(traceReceived, message)
+
+	var req types.MCPRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		// Try to extract ID from the raw JSON for better error reporting
+		var rawMap map[string]interface{}
+		var responseID interface{}
+		if json.Unmarshal(message, &rawMap) == nil {
+			if id, exists := rawMap["id"]; exists {
+				responseID = id
 			}
-			st.writeResponse(response)
-			continue
 		}
 
-		response := st.server.HandleRequest(req, config.RequestContext{})
+		response := types.MCPResponse{
+			JSONRPC: "2.0",
+			ID:      responseID, // Include ID if we could extract it
+			Error: &types.MCPError{
+				Code:    ErrorCodeInvalidRequest,
+				Message: "Parse error",
+				Data:    err.Error(),
+			},
+		}
 		st.writeResponse(response)
+		return
 	}
 
-	return scanner.Err()
+	st.busy.Lock()
+	response := st.server.HandleRequest(req, config.RequestContext{})
+	st.writeResponse(response)
+	st.busy.Unlock()
 }
 
-// Stop implements the Transport interface for stdio transport
+// Stop implements the Transport interface for stdio transport. It waits until the request
+// being handled has been answered, and no further requests are handled afterwards.
 func (st *StdioTransport) Stop(ctx context.Context) error {
-	// Stdio transport doesn't need explicit stopping
-	return nil
+	stopped := make(chan struct{})
+	go func() {
+		st.busy.Lock()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // writeResponse is now part of the StdioTransport
 func (st *StdioTransport) writeResponse(response types.MCPResponse) {
-	responseJSON, err := json.Marshal(response)
+	st.writeMessage(response)
+}
+
+// writeMessage writes a response or notification to stdout
+func (st *StdioTransport) writeMessage(message interface{}) {
+	messageJSON, release, err := encodeJSON(message)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error marshaling response: %v\n", err)
 		return
 	}
+	defer release()
 
-	fmt.Println(string(responseJSON))
+	st.writeMu.Lock()
+	defer st.writeMu.Unlock()
+	if st.framing == config.StdioFramingAuto {
+		// Nothing was received yet, so there is no client to notify and no known framing
+		return
+	}
+	st.server.traceMessage(traceSent, messageJSON)
+	if st.framing == config.StdioFramingContentLength {
+		body := bytes.TrimSuffix(messageJSON, []byte("\n"))
+		_, _ = fmt.Fprintf(st.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+		return
+	}
+	// The encoded message already ends with the newline that delimits messages
+	_, _ = st.out.Write(messageJSON)
 }
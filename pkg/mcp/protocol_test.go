@@ -0,0 +1,558 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/types"
+)
+
+func TestServer_InitializeVersion(t *testing.T) {
+	server := NewServer()
+	server.SetVersion("v1.2.3")
+
+	response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "initialize"}, config.RequestContext{})
+	result, ok := response.Result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected initialize result, got %+v", response)
+	}
+	serverInfo := result["serverInfo"].(map[string]interface{})
+	if serverInfo["version"] != "v1.2.3" {
+		t.Errorf("Expected serverInfo version v1.2.3, got %v", serverInfo["version"])
+	}
+}
+
+func TestServer_ToolsAndCallTool(t *testing.T) {
+	server := NewServer()
+	for _, name := range []string{"list_users", "get_user"} {
+		name := name
+		server.RegisterTool(name, "Tool "+name, map[string]interface{}{"type": "object"},
+			func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+				return name, nil
+			})
+	}
+
+	tools := server.Tools()
+	if len(tools) != 2 || tools[0].Name != "get_user" || tools[1].Name != "list_users" {
+		t.Errorf("Expected tools sorted by name, got %+v", tools)
+	}
+
+	result, err := server.CallTool("list_users", nil, config.RequestContext{})
+	if err != nil || result != "list_users" {
+		t.Errorf("Expected list_users result, got %v, %v", result, err)
+	}
+	if _, err := server.CallTool("missing", nil, config.RequestContext{}); !errors.Is(err, ErrToolNotFound) {
+		t.Errorf("Expected ErrToolNotFound, got %v", err)
+	}
+}
+
+func TestServer_RegisterLazyTool(t *testing.T) {
+	server := NewServer()
+	builds := 0
+	server.RegisterLazyTool("get_user", "Get a user", func() map[string]interface{} {
+		builds++
+		return map[string]interface{}{"type": "object"}
+	}, func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+		return "get_user", nil
+	})
+
+	if _, err := server.CallTool("get_user", nil, config.RequestContext{}); err != nil {
+		t.Fatalf("Expected call to succeed, got %v", err)
+	}
+	if builds != 0 {
+		t.Errorf("Expected input schema to be built on first listing, built %d times", builds)
+	}
+
+	for i := 0; i < 2; i++ {
+		result := listTools(t, server)
+		if len(result.Tools) != 1 || result.Tools[0].InputSchema["type"] != "object" {
+			t.Errorf("Expected listed tool with input schema, got %+v", result.Tools)
+		}
+	}
+	if tools := server.Tools(); tools[0].InputSchema["type"] != "object" {
+		t.Errorf("Expected Tools to resolve input schema, got %+v", tools[0])
+	}
+	if builds != 1 {
+		t.Errorf("Expected input schema to be built once, built %d times", builds)
+	}
+}
+
+func TestServer_ToolCallResultEncoding(t *testing.T) {
+	server := NewServer()
+	result := map[string]interface{}{"body": map[string]interface{}{"html": "<b>" + strings.Repeat("x", 4096) + "</b>"}}
+	server.RegisterTool("get_page", "Get a page", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			return result, nil
+		})
+
+	expected, _ := json.Marshal(result)
+	params, _ := json.Marshal(types.CallToolParams{Name: "get_page"})
+	for i := 0; i < 3; i++ {
+		response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: i, Method: "tools/call", Params: params}, config.RequestContext{})
+		callResult, ok := response.Result.(types.CallToolResult)
+		if !ok || len(callResult.Content) != 1 {
+			t.Fatalf("Expected tool call result, got %+v", response)
+		}
+		if callResult.Content[0].Text != string(expected) {
+			t.Errorf("Expected result encoded as json.Marshal does, got %q", callResult.Content[0].Text)
+		}
+	}
+}
+
+func TestServer_ToolCallNumberPrecision(t *testing.T) {
+	server := NewServer()
+	server.RegisterTool("get_tweet", "Get a tweet", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			return map[string]interface{}{"id": params["id"]}, nil
+		})
+
+	response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call",
+		Params: json.RawMessage(`{"name":"get_tweet","arguments":{"id":1453932123456789012}}`)}, config.RequestContext{})
+	callResult, ok := response.Result.(types.CallToolResult)
+	if !ok || len(callResult.Content) != 1 {
+		t.Fatalf("Expected tool call result, got %+v", response)
+	}
+	if text := callResult.Content[0].Text; text != `{"id":1453932123456789012}` {
+		t.Errorf("Expected the ID to keep its precision, got %s", text)
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	value := map[string]interface{}{"name": "a&b", "items": []int{1, 2}}
+	expected, _ := json.Marshal(value)
+
+	data, release, err := encodeJSON(value)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(data) != string(expected)+"\n" {
+		t.Errorf("Expected %s followed by a newline, got %q", expected, data)
+	}
+	release()
+
+	if _, _, err := encodeJSON(func() {}); err == nil {
+		t.Error("Expected error encoding a function")
+	}
+}
+
+func TestServer_UnencodableToolResult(t *testing.T) {
+	server := NewServer()
+	server.RegisterTool("broken", "Returns a function", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			return func() {}, nil
+		})
+
+	params, _ := json.Marshal(types.CallToolParams{Name: "broken"})
+	response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params}, config.RequestContext{})
+	if response.Error == nil || response.Error.Code != ErrorCodeInternalError {
+		t.Fatalf("Expected an internal error, got %+v", response)
+	}
+	if !strings.Contains(fmt.Sprint(response.Error.Data), "unsupported type") {
+		t.Errorf("Expected the encoding error in the data, got %v", response.Error.Data)
+	}
+}
+
+// listTools sends tools/list and decodes the result
+func listTools(t *testing.T, server *Server) types.ListToolsResult {
+	t.Helper()
+	response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"}, config.RequestContext{})
+	data, err := json.Marshal(response.Result)
+	if err != nil {
+		t.Fatalf("Failed to marshal tools/list result: %v", err)
+	}
+	var result types.ListToolsResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Failed to unmarshal tools/list result: %v", err)
+	}
+	return result
+}
+
+func TestServer_ToolsListCache(t *testing.T) {
+	server := NewServer()
+	handler := func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+		return nil, nil
+	}
+	server.RegisterTool("list_users", "List users", map[string]interface{}{"type": "object"}, handler)
+
+	first := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"}, config.RequestContext{})
+	second := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 2, Method: "tools/list"}, config.RequestContext{})
+	firstJSON, ok := first.Result.(json.RawMessage)
+	if !ok {
+		t.Fatalf("Expected encoded tools/list result, got %T", first.Result)
+	}
+	if secondJSON := second.Result.(json.RawMessage); &firstJSON[0] != &secondJSON[0] {
+		t.Error("Expected unchanged tools to reuse the encoded result")
+	}
+
+	// Registering and replacing tools invalidates the cached result
+	server.RegisterTool("get_user", "Get a user", map[string]interface{}{"type": "object"}, handler)
+	if result := listTools(t, server); len(result.Tools) != 2 || result.Tools[0].Name != "get_user" || result.Tools[1].Name != "list_users" {
+		t.Errorf("Expected both tools sorted by name, got %+v", result.Tools)
+	}
+
+	replacement := NewServer()
+	replacement.RegisterTool("delete_user", "Delete a user", map[string]interface{}{"type": "object"}, handler)
+	server.ReplaceTools(replacement)
+	if result := listTools(t, server); len(result.Tools) != 1 || result.Tools[0].Name != "delete_user" {
+		t.Errorf("Expected replaced tools, got %+v", result.Tools)
+	}
+}
+
+func TestServer_UnregisterTool(t *testing.T) {
+	server := NewServer()
+	server.RegisterTool("get_user", "Get a user", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			return nil, nil
+		})
+	if len(listTools(t, server).Tools) != 1 {
+		t.Fatal("Expected one listed tool")
+	}
+
+	if !server.UnregisterTool("get_user") {
+		t.Error("Expected get_user to be unregistered")
+	}
+	if server.UnregisterTool("get_user") {
+		t.Error("Expected second unregister to report a missing tool")
+	}
+	if tools := listTools(t, server).Tools; len(tools) != 0 {
+		t.Errorf("Expected no listed tools, got %+v", tools)
+	}
+	if _, err := server.CallTool("get_user", nil, config.RequestContext{}); !errors.Is(err, ErrToolNotFound) {
+		t.Errorf("Expected ErrToolNotFound, got %v", err)
+	}
+}
+
+func TestServer_ConcurrentRegistryUpdates(t *testing.T) {
+	server := NewServer()
+	handler := func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+		return "ok", nil
+	}
+	server.RegisterTool("stable", "Always registered", map[string]interface{}{"type": "object"}, handler)
+	params, _ := json.Marshal(types.CallToolParams{Name: "stable"})
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 4; worker++ {
+		wg.Add(3)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				name := fmt.Sprintf("dynamic_%d_%d", worker, i)
+				server.RegisterTool(name, "Dynamic", map[string]interface{}{"type": "object"}, handler)
+				server.UnregisterTool(name)
+			}
+		}(worker)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				replacement := NewServer()
+				replacement.RegisterTool("stable", "Always registered", map[string]interface{}{"type": "object"}, handler)
+				server.ReplaceTools(replacement)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: i, Method: "tools/list"}, config.RequestContext{})
+				response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: i, Method: "tools/call", Params: params}, config.RequestContext{})
+				if response.Error != nil {
+					t.Errorf("Expected stable tool call to succeed, got %+v", response.Error)
+				}
+				_ = server.Tools()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestServer_SetToolEnabled(t *testing.T) {
+	server := NewServer()
+	handler := func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+		return "ok", nil
+	}
+	server.RegisterTool("get_user", "Get a user", map[string]interface{}{"type": "object"}, handler)
+	server.RegisterTool("delete_user", "Delete a user", map[string]interface{}{"type": "object"}, handler)
+
+	if err := server.SetToolEnabled("missing", false); !errors.Is(err, ErrToolNotFound) {
+		t.Errorf("Expected ErrToolNotFound, got %v", err)
+	}
+	if err := server.SetToolEnabled("delete_user", false); err != nil {
+		t.Fatalf("SetToolEnabled failed: %v", err)
+	}
+	if server.IsToolEnabled("delete_user") || !server.IsToolEnabled("get_user") {
+		t.Error("Expected only delete_user to be disabled")
+	}
+
+	if tools := listTools(t, server).Tools; len(tools) != 1 || tools[0].Name != "get_user" {
+		t.Errorf("Expected disabled tool to be left out of tools/list, got %+v", tools)
+	}
+	if _, err := server.CallTool("delete_user", nil, config.RequestContext{}); !errors.Is(err, ErrToolDisabled) {
+		t.Errorf("Expected ErrToolDisabled, got %v", err)
+	}
+	params, _ := json.Marshal(types.CallToolParams{Name: "delete_user"})
+	response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params}, config.RequestContext{})
+	if response.Error == nil || response.Error.Message != "Tool disabled" {
+		t.Errorf("Expected Tool disabled error, got %+v", response.Error)
+	}
+
+	// The tool stays disabled when the tools are replaced on reload
+	replacement := NewServer()
+	replacement.RegisterTool("get_user", "Get a user", map[string]interface{}{"type": "object"}, handler)
+	replacement.RegisterTool("delete_user", "Delete a user", map[string]interface{}{"type": "object"}, handler)
+	server.ReplaceTools(replacement)
+	if server.IsToolEnabled("delete_user") {
+		t.Error("Expected delete_user to stay disabled after ReplaceTools")
+	}
+
+	if err := server.SetToolEnabled("delete_user", true); err != nil {
+		t.Fatalf("SetToolEnabled failed: %v", err)
+	}
+	if tools := listTools(t, server).Tools; len(tools) != 2 {
+		t.Errorf("Expected re-enabled tool to be listed, got %+v", tools)
+	}
+}
+
+func TestServer_SetToolDebug(t *testing.T) {
+	server := NewServer()
+	handler := func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+		return requestContext.Debug, nil
+	}
+	server.RegisterTool("get_user", "Get a user", map[string]interface{}{"type": "object"}, handler)
+
+	if err := server.SetToolDebug("missing", true); !errors.Is(err, ErrToolNotFound) {
+		t.Errorf("Expected ErrToolNotFound, got %v", err)
+	}
+	if debug, _ := server.CallTool("get_user", nil, config.RequestContext{}); debug != false {
+		t.Error("Expected calls without debug by default")
+	}
+	if err := server.SetToolDebug("get_user", true); err != nil {
+		t.Fatalf("SetToolDebug failed: %v", err)
+	}
+	if debug, _ := server.CallTool("get_user", nil, config.RequestContext{}); debug != true {
+		t.Error("Expected calls with debug once enabled")
+	}
+
+	// Debug stays enabled when the tools are replaced on reload, and reaches tools/call
+	replacement := NewServer()
+	replacement.RegisterTool("get_user", "Get a user", map[string]interface{}{"type": "object"}, handler)
+	server.ReplaceTools(replacement)
+	params, _ := json.Marshal(types.CallToolParams{Name: "get_user"})
+	response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params}, config.RequestContext{})
+	if result, ok := response.Result.(types.CallToolResult); !ok || result.Content[0].Text != "true" {
+		t.Errorf("Expected debug after ReplaceTools, got %+v", response)
+	}
+
+	if err := server.SetToolDebug("get_user", false); err != nil || server.IsToolDebug("get_user") {
+		t.Errorf("Expected debug disabled, got %v", err)
+	}
+}
+
+func TestServer_WaitForCalls(t *testing.T) {
+	server := NewServer()
+	release := make(chan struct{})
+	server.RegisterTool("slow", "Blocks until released", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			<-release
+			return "done", nil
+		})
+
+	if err := server.WaitForCalls(context.Background()); err != nil {
+		t.Fatalf("Expected no wait without calls, got %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call",
+			Params: json.RawMessage(`{"name":"slow","arguments":{}}`)}, config.RequestContext{})
+	}()
+	for server.InFlightCalls() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := server.WaitForCalls(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to time out with a call in progress, got %v", err)
+	}
+
+	close(release)
+	if err := server.WaitForCalls(context.Background()); err != nil {
+		t.Errorf("Expected the wait to end with the call, got %v", err)
+	}
+	<-done
+	if calls := server.InFlightCalls(); calls != 0 {
+		t.Errorf("Expected no calls in progress, got %d", calls)
+	}
+}
+
+func TestServer_StopAndAbortCalls(t *testing.T) {
+	server := NewServer()
+	release := make(chan struct{})
+	defer close(release)
+	server.RegisterTool("slow", "Blocks until released", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			<-release
+			return "done", nil
+		})
+	call := types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: json.RawMessage(`{"name":"slow","arguments":{}}`)}
+
+	responses := make(chan types.MCPResponse, 1)
+	go func() { responses <- server.HandleRequest(call, config.RequestContext{}) }()
+	for server.InFlightCalls() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// New calls are refused while the call in progress goes on
+	server.StopCalls()
+	refused := server.HandleRequest(call, config.RequestContext{})
+	if refused.Error == nil || refused.Error.Code != ErrorCodeServiceUnavailable {
+		t.Errorf("Expected a new call to be refused as unavailable, got %+v", refused)
+	}
+	if calls := server.InFlightCalls(); calls != 1 {
+		t.Errorf("Expected the call in progress to go on, got %d calls", calls)
+	}
+
+	// Aborting answers the call in progress with an error
+	server.AbortCalls()
+	select {
+	case response := <-responses:
+		if response.Error == nil || response.Error.Message != "Server is shutting down" {
+			t.Errorf("Expected a shutdown error, got %+v", response)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the call in progress to be aborted")
+	}
+	if calls := server.InFlightCalls(); calls != 0 {
+		t.Errorf("Expected no calls in progress, got %d", calls)
+	}
+}
+
+func TestStdioTransport_Stop(t *testing.T) {
+	transport := NewStdioTransport(NewServer())
+
+	// A request being handled holds the stop until it has been answered
+	transport.busy.Lock()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := transport.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected stop to wait for the request in progress, got %v", err)
+	}
+	transport.busy.Unlock()
+
+	transport = NewStdioTransport(NewServer())
+	if err := transport.Stop(context.Background()); err != nil {
+		t.Errorf("Expected an idle transport to stop, got %v", err)
+	}
+}
+
+func TestServer_MaxRequestSize(t *testing.T) {
+	server := NewServer()
+	server.RegisterTool("echo", "Echoes its arguments", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			return params, nil
+		})
+	server.SetMaxRequestSize(64)
+
+	call := func(text string) types.MCPResponse {
+		params := fmt.Sprintf(`{"name":"echo","arguments":{"text":%q}}`, text)
+		return server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: json.RawMessage(params)}, config.RequestContext{})
+	}
+	if response := call("hello"); response.Error != nil {
+		t.Errorf("Expected a small call to succeed, got %+v", response.Error)
+	}
+	response := call(strings.Repeat("x", 100))
+	if response.Error == nil || response.Error.Code != ErrorCodeInvalidParams {
+		t.Fatalf("Expected an invalid params error, got %+v", response)
+	}
+	if !strings.Contains(fmt.Sprint(response.Error.Data), "exceed the limit of 64 bytes") {
+		t.Errorf("Expected the limit in the error data, got %v", response.Error.Data)
+	}
+
+	server.SetMaxRequestSize(0)
+	if response := call(strings.Repeat("x", 100)); response.Error != nil {
+		t.Errorf("Expected no limit, got %+v", response.Error)
+	}
+}
+
+func TestStdioTransport_Framing(t *testing.T) {
+	request := func(id int) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/list"}`, id)
+	}
+	framed := func(body string) string {
+		return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	response := func(id int) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"tools":[]}}`, id)
+	}
+
+	tests := []struct {
+		name     string
+		framing  string
+		input    string
+		expected string
+		err      string
+	}{
+		{
+			name:     "newline",
+			framing:  config.StdioFramingAuto,
+			input:    "\n" + request(1) + "\n\n" + request(2) + "\n",
+			expected: response(1) + "\n" + response(2) + "\n",
+		},
+		{
+			name:     "detected content length",
+			framing:  config.StdioFramingAuto,
+			input:    framed(request(1)) + "\r\n" + fmt.Sprintf("content-length: %d\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n", len(request(2))) + request(2),
+			expected: framed(response(1)) + framed(response(2)),
+		},
+		{
+			name:     "too large",
+			framing:  config.StdioFramingContentLength,
+			input:    framed(`{"padding":"`+strings.Repeat("x", 70000)+`"}`) + framed(request(3)),
+			expected: framed(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Parse error","data":"message too large: 70014 bytes, the limit is 65536"}}`) + framed(response(3)),
+		},
+		{
+			name:    "missing length",
+			framing: config.StdioFramingContentLength,
+			input:   "Content-Type: application/json\r\n\r\n" + request(1),
+			err:     "message without Content-Length header",
+		},
+		{
+			name:    "forced newline",
+			framing: config.StdioFramingNewline,
+			input:   framed(request(1)) + "\n",
+			// The header line is not JSON, the body after it is answered as a line
+			expected: `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Parse error","data":"invalid character 'C' looking for beginning of value"}}` + "\n" + response(1) + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			transport := NewStdioTransport(NewServer())
+			transport.in = strings.NewReader(tt.input)
+			transport.out = &out
+			transport.SetFraming(tt.framing)
+
+			err := transport.Start()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("Expected\n%q\ngot\n%q", tt.expected, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package mcp
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"mcpify/internal/redis"
+	"mcpify/internal/types"
+)
+
+// SessionStore keeps the HTTP sessions of MCP clients. Persistent stores keep sessions across
+// restarts, and shared ones let every replica behind a load balancer serve every session.
+type SessionStore interface {
+	// Save creates or updates a session
+	Save(session types.Session) error
+	// Load returns a session, reporting false when it does not exist
+	Load(id string) (types.Session, bool, error)
+	// List returns the stored sessions in no particular order
+	List() ([]types.Session, error)
+	// DeleteIdle removes the sessions last seen before cutoff and returns their IDs
+	DeleteIdle(cutoff time.Time) ([]string, error)
+}
+
+// validSessionID reports whether id has the form of the IDs createSession generates, which
+// keeps IDs sent by clients from reaching file paths or key patterns
+func validSessionID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// MemorySessionStore keeps sessions in process memory, so they end with the process
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]types.Session
+}
+
+// NewMemorySessionStore creates an empty in-memory session store
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]types.Session)}
+}
+
+// Save implements SessionStore
+func (m *MemorySessionStore) Save(session types.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = session
+	return nil
+}
+
+// Load implements SessionStore
+func (m *MemorySessionStore) Load(id string) (types.Session, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	session, ok := m.sessions[id]
+	return session, ok, nil
+}
+
+// List implements SessionStore
+func (m *MemorySessionStore) List() ([]types.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sessions := make([]types.Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// DeleteIdle implements SessionStore
+func (m *MemorySessionStore) DeleteIdle(cutoff time.Time) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted []string
+	for id, session := range m.sessions {
+		if session.LastSeen.Before(cutoff) {
+			delete(m.sessions, id)
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted, nil
+}
+
+// FileSessionStore keeps each session in a JSON file of a directory, so sessions survive
+// restarts and can be shared by replicas on the same host or volume
+type FileSessionStore struct {
+	dir string
+}
+
+// NewFileSessionStore creates a session store in dir, creating the directory when missing
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	return &FileSessionStore{dir: dir}, nil
+}
+
+// path returns the file of a session
+func (f *FileSessionStore) path(id string) string {
+	return filepath.Join(f.dir, id+".json")
+}
+
+// Save implements SessionStore, replacing the file atomically so readers never see it half written
+func (f *FileSessionStore) Save(session types.Session) error {
+	if !validSessionID(session.ID) {
+		return fmt.Errorf("invalid session ID %q", session.ID)
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(f.dir, ".session-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(session.ID))
+}
+
+// Load implements SessionStore
+func (f *FileSessionStore) Load(id string) (types.Session, bool, error) {
+	if !validSessionID(id) {
+		return types.Session{}, false, nil
+	}
+	data, err := os.ReadFile(f.path(id))
+	if os.IsNotExist(err) {
+		return types.Session{}, false, nil
+	}
+	if err != nil {
+		return types.Session{}, false, err
+	}
+	var session types.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return types.Session{}, false, fmt.Errorf("invalid session file %s: %w", f.path(id), err)
+	}
+	return session, true, nil
+}
+
+// List implements SessionStore
+func (f *FileSessionStore) List() ([]types.Session, error) {
+	files, err := filepath.Glob(filepath.Join(f.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]types.Session, 0, len(files))
+	for _, file := range files {
+		session, ok, err := f.Load(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+// DeleteIdle implements SessionStore
+func (f *FileSessionStore) DeleteIdle(cutoff time.Time) ([]string, error) {
+	sessions, err := f.List()
+	if err != nil {
+		return nil, err
+	}
+	var deleted []string
+	for _, session := range sessions {
+		if !session.LastSeen.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(f.path(session.ID)); err != nil && !os.IsNotExist(err) {
+			return deleted, err
+		}
+		deleted = append(deleted, session.ID)
+	}
+	return deleted, nil
+}
+
+// RedisSessionStore keeps sessions in Redis, shared by every replica using the same server.
+// Sessions expire in Redis once idle for the session timeout.
+type RedisSessionStore struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisSessionStore creates a session store keeping sessions under keys starting with
+// prefix, expiring them after ttl without activity; a ttl of 0 keeps them until deleted
+func NewRedisSessionStore(client *redis.Client, prefix string, ttl time.Duration) *RedisSessionStore {
+	return &RedisSessionStore{client: client, prefix: prefix, ttl: ttl}
+}
+
+// Save implements SessionStore
+func (r *RedisSessionStore) Save(session types.Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	args := []string{"SET", r.prefix + session.ID, string(data)}
+	if r.ttl > 0 {
+		args = append(args, "PX", fmt.Sprint(r.ttl.Milliseconds()))
+	}
+	_, err = r.client.Do(context.Background(), args...)
+	return err
+}
+
+// Load implements SessionStore
+func (r *RedisSessionStore) Load(id string) (types.Session, bool, error) {
+	if !validSessionID(id) {
+		return types.Session{}, false, nil
+	}
+	reply, err := r.client.Do(context.Background(), "GET", r.prefix+id)
+	if err != nil || reply == nil {
+		return types.Session{}, false, err
+	}
+	return decodeSession(reply)
+}
+
+// List implements SessionStore
+func (r *RedisSessionStore) List() ([]types.Session, error) {
+	ctx := context.Background()
+	var sessions []types.Session
+	cursor := "0"
+	for {
+		reply, err := r.client.Do(ctx, "SCAN", cursor, "MATCH", r.prefix+"*", "COUNT", "100")
+		if err != nil {
+			return nil, err
+		}
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			return nil, fmt.Errorf("unexpected SCAN reply %v", reply)
+		}
+		cursor, _ = page[0].(string)
+		keys, _ := page[1].([]interface{})
+		if len(keys) > 0 {
+			args := []string{"MGET"}
+			for _, key := range keys {
+				if key, ok := key.(string); ok {
+					args = append(args, key)
+				}
+			}
+			values, err := r.client.Do(ctx, args...)
+			if err != nil {
+				return nil, err
+			}
+			items, _ := values.([]interface{})
+			for _, item := range items {
+				// Keys expiring between SCAN and MGET come back empty
+				if session, ok, err := decodeSession(item); err == nil && ok {
+					sessions = append(sessions, session)
+				}
+			}
+		}
+		if cursor == "0" || cursor == "" {
+			return sessions, nil
+		}
+	}
+}
+
+// DeleteIdle implements SessionStore; Redis expires idle sessions itself
+func (r *RedisSessionStore) DeleteIdle(cutoff time.Time) ([]string, error) {
+	return nil, nil
+}
+
+// decodeSession decodes a session stored as a JSON string reply
+func decodeSession(reply interface{}) (types.Session, bool, error) {
+	data, ok := reply.(string)
+	if !ok {
+		return types.Session{}, false, nil
+	}
+	var session types.Session
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return types.Session{}, false, fmt.Errorf("invalid stored session: %w", err)
+	}
+	return session, true, nil
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+	"mcpify/internal/redis"
+	"mcpify/internal/redis/redistest"
+	"mcpify/internal/types"
+)
+
+func TestSessionStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) SessionStore{
+		"memory": func(t *testing.T) SessionStore { return NewMemorySessionStore() },
+		"file": func(t *testing.T) SessionStore {
+			store, err := NewFileSessionStore(t.TempDir() + "/sessions")
+			if err != nil {
+				t.Fatalf("Failed to create store: %v", err)
+			}
+			return store
+		},
+		"redis": func(t *testing.T) SessionStore {
+			client, err := redis.New(config.RedisConfig{URL: redistest.NewServer(t).URL})
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			return NewRedisSessionStore(client, "test:session:", time.Hour)
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			active := types.Session{ID: "0123456789abcdef0123456789abcdef", CreatedAt: now, LastSeen: now, Active: true}
+			idle := types.Session{ID: "fedcba9876543210fedcba9876543210", CreatedAt: now, LastSeen: now.Add(-time.Hour), Active: true}
+			for _, session := range []types.Session{active, idle} {
+				if err := store.Save(session); err != nil {
+					t.Fatalf("Failed to save session: %v", err)
+				}
+			}
+
+			loaded, ok, err := store.Load(active.ID)
+			if err != nil || !ok || !loaded.LastSeen.Equal(active.LastSeen) || !loaded.Active {
+				t.Errorf("Expected to load %+v, got %+v, %v, %v", active, loaded, ok, err)
+			}
+			if _, ok, err := store.Load("../../etc/passwd"); ok || err != nil {
+				t.Errorf("Expected invalid IDs to be missing, got %v, %v", ok, err)
+			}
+
+			sessions, err := store.List()
+			if err != nil || len(sessions) != 2 {
+				t.Fatalf("Expected 2 sessions, got %+v, %v", sessions, err)
+			}
+
+			deleted, err := store.DeleteIdle(now.Add(-time.Minute))
+			if err != nil {
+				t.Fatalf("Failed to delete idle sessions: %v", err)
+			}
+			// Redis expires idle sessions itself
+			if name == "redis" {
+				return
+			}
+			sort.Strings(deleted)
+			if len(deleted) != 1 || deleted[0] != idle.ID {
+				t.Errorf("Expected the idle session to be deleted, got %v", deleted)
+			}
+			if _, ok, _ := store.Load(idle.ID); ok {
+				t.Error("Expected the idle session to be gone")
+			}
+		})
+	}
+}
+
+func TestStreamableHTTPTransport_SharedSessionStore(t *testing.T) {
+	store, err := NewFileSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	first := NewStreamableHTTPTransport(NewServer(), &StreamableHTTPConfig{SessionTimeout: time.Minute, SessionStore: store})
+	second := NewStreamableHTTPTransport(NewServer(), &StreamableHTTPConfig{SessionTimeout: time.Minute, SessionStore: store})
+
+	sessionID, err := first.createSession()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if valid, err := second.isValidSession(sessionID); err != nil || !valid {
+		t.Errorf("Expected the session to be valid on another transport, got %v, %v", valid, err)
+	}
+}
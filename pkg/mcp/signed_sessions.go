@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"mcpify/internal/types"
+)
+
+// sessionIssuer is implemented by stores whose session IDs carry the session itself, so the
+// transport hands out the ID they issue instead of saving the session
+type sessionIssuer interface {
+	Issue(session types.Session) (string, error)
+}
+
+// SignedSessionStore keeps no sessions: session IDs are tokens holding the random ID and the
+// creation time, signed with a shared secret. Every replica with the secret accepts the
+// sessions of the others, so no sticky sessions or shared store are needed. Sessions end
+// maxAge after they start, as their activity is not recorded anywhere.
+type SignedSessionStore struct {
+	key    []byte
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// NewSignedSessionStore creates a store signing session IDs with secret, valid for maxAge
+func NewSignedSessionStore(secret []byte, maxAge time.Duration) *SignedSessionStore {
+	return &SignedSessionStore{key: secret, maxAge: maxAge, now: time.Now}
+}
+
+// Issue returns the session ID of session: "<id>.<created unix seconds>.<signature>"
+func (s *SignedSessionStore) Issue(session types.Session) (string, error) {
+	payload := session.ID + "." + strconv.FormatInt(session.CreatedAt.Unix(), 10)
+	return payload + "." + s.sign(payload), nil
+}
+
+// Save implements SessionStore; signed sessions have no state to save
+func (s *SignedSessionStore) Save(session types.Session) error {
+	return nil
+}
+
+// Load implements SessionStore, verifying the signature and age of the session ID. The
+// session is reported as seen now, since its last activity is not known.
+func (s *SignedSessionStore) Load(id string) (types.Session, bool, error) {
+	payload, signature, ok := cutLast(id, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return types.Session{}, false, nil
+	}
+	random, created, ok := strings.Cut(payload, ".")
+	if !ok || !validSessionID(random) {
+		return types.Session{}, false, nil
+	}
+	seconds, err := strconv.ParseInt(created, 10, 64)
+	if err != nil {
+		return types.Session{}, false, nil
+	}
+
+	now := s.now()
+	createdAt := time.Unix(seconds, 0)
+	if now.Sub(createdAt) > s.maxAge {
+		return types.Session{}, false, nil
+	}
+	return types.Session{ID: id, CreatedAt: createdAt, LastSeen: now, Active: true}, true, nil
+}
+
+// List implements SessionStore; signed sessions cannot be listed
+func (s *SignedSessionStore) List() ([]types.Session, error) {
+	return []types.Session{}, nil
+}
+
+// DeleteIdle implements SessionStore; signed sessions expire with their age
+func (s *SignedSessionStore) DeleteIdle(cutoff time.Time) ([]string, error) {
+	return nil, nil
+}
+
+// sign returns the base64url HMAC-SHA256 of payload
+func (s *SignedSessionStore) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (string, string, bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
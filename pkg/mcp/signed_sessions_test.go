@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mcpify/internal/types"
+)
+
+func TestSignedSessionStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewSignedSessionStore([]byte("0123456789abcdef0123456789abcdef"), time.Hour)
+	store.now = func() time.Time { return now }
+
+	id, err := store.Issue(types.Session{ID: "0123456789abcdef0123456789abcdef", CreatedAt: now})
+	if err != nil {
+		t.Fatalf("Failed to issue session: %v", err)
+	}
+	if !strings.HasPrefix(id, "0123456789abcdef0123456789abcdef.") {
+		t.Errorf("Expected the ID to start with the random ID, got %s", id)
+	}
+
+	now = now.Add(30 * time.Minute)
+	session, ok, err := store.Load(id)
+	if err != nil || !ok || !session.Active || session.ID != id || !session.LastSeen.Equal(now) {
+		t.Errorf("Expected a valid session, got %+v, %v, %v", session, ok, err)
+	}
+
+	other := NewSignedSessionStore([]byte("another secret of thirty-two bytes"), time.Hour)
+	other.now = store.now
+	for name, invalid := range map[string]string{
+		"tampered":  strings.Replace(id, "0123", "4567", 1),
+		"unsigned":  id[:strings.LastIndex(id, ".")],
+		"plain ID":  "0123456789abcdef0123456789abcdef",
+		"empty":     "",
+		"path":      "../../etc/passwd",
+		"truncated": id[:len(id)-1],
+	} {
+		if _, ok, err := store.Load(invalid); ok || err != nil {
+			t.Errorf("Expected %s session to be missing, got %v, %v", name, ok, err)
+		}
+	}
+	if _, ok, _ := other.Load(id); ok {
+		t.Error("Expected a session signed with another secret to be missing")
+	}
+
+	now = now.Add(31 * time.Minute)
+	if _, ok, _ := store.Load(id); ok {
+		t.Error("Expected the session to end after its maximum age")
+	}
+}
+
+func TestStreamableHTTPTransport_SignedSessions(t *testing.T) {
+	// Replicas share nothing but the secret
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	first := NewStreamableHTTPTransport(NewServer(), &StreamableHTTPConfig{
+		SessionTimeout: time.Minute,
+		SessionStore:   NewSignedSessionStore(secret, time.Hour),
+	})
+	second := NewStreamableHTTPTransport(NewServer(), &StreamableHTTPConfig{
+		SessionTimeout: time.Minute,
+		SessionStore:   NewSignedSessionStore(secret, time.Hour),
+	})
+
+	sessionID, err := first.createSession()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if valid, err := second.isValidSession(sessionID); err != nil || !valid {
+		t.Errorf("Expected the session to be valid on another replica, got %v, %v", valid, err)
+	}
+	if sessions := first.Sessions(); len(sessions) != 0 {
+		t.Errorf("Expected no stored sessions, got %+v", sessions)
+	}
+}
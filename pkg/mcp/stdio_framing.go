@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"mcpify/internal/config"
+)
+
+// errMessageTooLarge is returned for framed messages longer than the transport accepts; the
+// message is skipped, so the session can go on
+var errMessageTooLarge = errors.New("message too large")
+
+// detectFraming returns the framing of the first message: newline-delimited when it starts
+// with JSON, otherwise Content-Length headers. Leading white space is skipped.
+func detectFraming(reader *bufio.Reader) string {
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return config.StdioFramingNewline
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		_ = reader.UnreadByte()
+		if b == '{' || b == '[' {
+			return config.StdioFramingNewline
+		}
+		return config.StdioFramingContentLength
+	}
+}
+
+// readFramedMessage reads the body of one message preceded by headers, of which
+// Content-Length is required, and a blank line, as in the Language Server Protocol. It
+// returns io.EOF when the input ends between messages.
+func readFramedMessage(reader *bufio.Reader, maxSize int64) ([]byte, error) {
+	length := int64(-1)
+	headers := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && headers == 0 && strings.TrimSpace(line) == "" {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("failed to read message headers: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			// Blank lines between messages are tolerated
+			if headers == 0 {
+				continue
+			}
+			break
+		}
+		headers++
+
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid message header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil || length < 0 {
+				return nil, fmt.Errorf("invalid Content-Length %q", strings.TrimSpace(value))
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("message without Content-Length header")
+	}
+
+	if length > maxSize {
+		if _, err := io.CopyN(io.Discard, reader, length); err != nil {
+			return nil, fmt.Errorf("failed to read message: %w", err)
+		}
+		return nil, fmt.Errorf("%w: %d bytes, the limit is %d", errMessageTooLarge, length, maxSize)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	return body, nil
+}
@@ -6,15 +6,21 @@ package mcp
 import (
 	"context"
 	"crypto/rand"
+	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/netip"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"mcpify/internal/config"
@@ -29,25 +35,65 @@ import (
 // - CORS support with origin validation
 // - Graceful shutdown capabilities
 type StreamableHTTPTransport struct {
-	server      *http.Server              // HTTP server instance
-	mcpServer   *Server                   // Reference to the MCP server
-	config      *StreamableHTTPConfig     // Transport configuration
-	sessions    map[string]*types.Session // Active session storage
-	sessionsMux sync.RWMutex              // Mutex for thread-safe session access
+	server    *http.Server          // HTTP server instance
+	mcpServer *Server               // Reference to the MCP server
+	config    *StreamableHTTPConfig // Transport configuration
+	sessions  SessionStore          // Active session storage
+	configMux sync.RWMutex          // Mutex for settings that can change at runtime
+	draining  atomic.Bool           // Set on shutdown: /readyz fails and no new sessions start
+	stopping  chan struct{}         // Closed by Stop to end open SSE streams
+	stopOnce  sync.Once
+	streamsMu sync.Mutex
+	streams   map[chan []byte]struct{} // Open SSE streams, sent server notifications
 }
 
 // StreamableHTTPConfig contains MCP-compliant HTTP transport configuration
 // All settings follow MCP specification requirements for streamable HTTP transport
 type StreamableHTTPConfig struct {
-	Host           string        // Server host (defaults to 127.0.0.1 for security)
-	Port           int           // Server port (e.g., 8080)
-	SessionTimeout time.Duration // How long sessions remain active without activity
-	MaxConnections int           // Maximum concurrent connections allowed
-	CORSEnabled    bool          // Whether to enable CORS headers
-	CORSOrigins    []string      // Allowed origins for CORS requests
-	MaxFormSize    int64         // Maximum form data size in bytes for dynamic header extraction (default: 1MB)
+	Host            string             // Server host (defaults to 127.0.0.1 for security)
+	Port            int                // Server port (e.g., 8080)
+	SessionTimeout  time.Duration      // How long sessions remain active without activity
+	MaxConnections  int                // Maximum concurrent connections allowed
+	CORSEnabled     bool               // Whether to enable CORS headers
+	CORSOrigins     []string           // Allowed origins for CORS requests, exact, "*" or like "https://*.example.com"
+	CORSMethods     []string           // Methods allowed in preflight responses (default: GET, POST, OPTIONS)
+	CORSHeaders     []string           // Request headers allowed in preflight responses (default: the MCP headers)
+	CORSExposed     []string           // Response headers browsers may read (default: Mcp-Session-Id)
+	CORSCredentials bool               // Whether browsers may send credentials with requests
+	CORSMaxAge      time.Duration      // How long browsers may cache preflight responses (default: 24h)
+	MaxFormSize     int64              // Maximum form data size in bytes for dynamic header extraction (default: 1MB)
+	MaxRequestSize  int64              // Maximum request body size in bytes; larger requests get 413 (0: no limit)
+	TokenValidator  TokenValidator     // Optional validator for client bearer tokens; nil disables token checks
+	BasicAuth       BasicAuthenticator // Optional checker of client Basic auth credentials; nil disables it
+	BasicAuthRealm  string             // Realm sent in Basic auth challenges
+	TLSConfig       *tls.Config        // TLS policy (minimum version, cipher suites) when serving HTTPS
+	TLSCertFile     string             // Certificate file; setting it together with TLSKeyFile enables HTTPS
+	TLSKeyFile      string             // Private key file for the certificate
+	Docs            http.Handler       // Optional API documentation served under /docs; nil disables it
+	SessionStore    SessionStore       // Where sessions are kept; nil keeps them in memory
+	TrustedProxies  []netip.Prefix     // Proxies whose X-Forwarded-For and X-Real-IP headers give the client address
+	RateLimiter     RateLimiter        // Optional limiter of tool calls per client address; nil disables it
 }
 
+// RateLimiter limits the requests of each client, identified by key
+type RateLimiter interface {
+	Allow(key string) bool
+}
+
+// TokenValidator validates bearer tokens presented by MCP clients
+// Implementations return the verified claims, which are exposed to header rules
+type TokenValidator interface {
+	Validate(token string) (map[string]interface{}, error)
+}
+
+// BasicAuthenticator checks the HTTP Basic auth credentials of MCP clients
+type BasicAuthenticator interface {
+	Authenticate(username, password string) bool
+}
+
+// claimsContextKey is the context key for verified token claims
+type claimsContextKey struct{}
+
 // NewStreamableHTTPTransport creates a new MCP-compliant HTTP transport instance
 // This constructor sets up the HTTP server with MCP protocol compliance:
 // - Defaults to localhost binding for security per MCP specification
@@ -72,11 +118,20 @@ func NewStreamableHTTPTransport(mcpServer *Server, config *StreamableHTTPConfig)
 		config.CORSOrigins = []string{"http://localhost:3000", "http://127.0.0.1:3000"}
 	}
 
-	// Initialize the transport with thread-safe session storage
+	// Initialize the transport with the configured session storage
+	sessions := config.SessionStore
+	if sessions == nil {
+		sessions = NewMemorySessionStore()
+	}
 	transport := &StreamableHTTPTransport{
 		mcpServer: mcpServer,
 		config:    config,
-		sessions:  make(map[string]*types.Session), // Thread-safe session map
+		sessions:  sessions,
+		stopping:  make(chan struct{}),
+		streams:   make(map[chan []byte]struct{}),
+	}
+	if mcpServer != nil {
+		mcpServer.OnNotification(transport.broadcast)
 	}
 
 	// Setup HTTP routing with MCP-compliant endpoints
@@ -85,8 +140,9 @@ func NewStreamableHTTPTransport(mcpServer *Server, config *StreamableHTTPConfig)
 
 	// Create HTTP server with CORS middleware
 	transport.server = &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", config.Host, config.Port),
-		Handler: transport.corsMiddleware(mux), // Wrap with CORS support
+		Addr:      fmt.Sprintf("%s:%d", config.Host, config.Port),
+		Handler:   transport.corsMiddleware(mux), // Wrap with CORS support
+		TLSConfig: config.TLSConfig,
 	}
 
 	// Start background session cleanup goroutine to prevent memory leaks
@@ -100,8 +156,36 @@ func NewStreamableHTTPTransport(mcpServer *Server, config *StreamableHTTPConfig)
 func (t *StreamableHTTPTransport) setupRoutes(mux *http.ServeMux) {
 	// Single MCP endpoint as per specification - handles both POST (JSON-RPC) and GET (SSE)
 	mux.HandleFunc("/mcp", t.handleMCP)
+
+	// Human-readable documentation of the served APIs, outside the MCP endpoint
+	if t.config.Docs != nil {
+		mux.Handle("/docs", t.config.Docs)
+		mux.Handle("/docs/", t.config.Docs)
+	}
+
+	// Liveness and readiness probes for orchestrators; readiness fails once draining starts
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok\n")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if t.draining.Load() {
+			http.Error(w, "draining", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, "ok\n")
+	})
 }
 
+// Default CORS settings, used for the settings left empty
+var (
+	defaultCORSMethods = []string{"GET", "POST", "OPTIONS"}
+	defaultCORSHeaders = []string{"Content-Type", "Accept", "Authorization", "MCP-Protocol-Version", "Mcp-Session-Id"}
+	defaultCORSExposed = []string{"Mcp-Session-Id"}
+)
+
+// defaultCORSMaxAge is how long browsers cache preflight responses by default
+const defaultCORSMaxAge = 24 * time.Hour
+
 // corsMiddleware adds CORS headers if enabled
 // This middleware handles CORS preflight requests and adds appropriate headers
 // for cross-origin requests from web browsers
@@ -110,14 +194,24 @@ func (t *StreamableHTTPTransport) corsMiddleware(handler http.Handler) http.Hand
 		// Apply CORS headers if enabled in configuration
 		if t.config.CORSEnabled {
 			origin := r.Header.Get("Origin")
+			// The allowed origin depends on the request's, so caches must keep responses apart
+			w.Header().Add("Vary", "Origin")
 			// Only allow configured origins for security
 			if t.isOriginAllowed(origin) {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
+				if t.config.CORSCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
 			}
 			// Set required CORS headers for MCP protocol
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, MCP-Protocol-Version, Mcp-Session-Id")
-			w.Header().Set("Access-Control-Max-Age", "86400") // Cache preflight for 24 hours
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(orDefault(t.config.CORSMethods, defaultCORSMethods), ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(orDefault(t.config.CORSHeaders, defaultCORSHeaders), ", "))
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(orDefault(t.config.CORSExposed, defaultCORSExposed), ", "))
+			maxAge := t.config.CORSMaxAge
+			if maxAge <= 0 {
+				maxAge = defaultCORSMaxAge
+			}
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(maxAge.Seconds())))
 
 			// Handle CORS preflight requests
 			if r.Method == "OPTIONS" {
@@ -135,7 +229,7 @@ func (t *StreamableHTTPTransport) corsMiddleware(handler http.Handler) http.Hand
 func (t *StreamableHTTPTransport) isOriginAllowed(origin string) bool {
 	// Check if the request origin matches any configured allowed origins
 	for _, allowed := range t.config.CORSOrigins {
-		if allowed == "*" || allowed == origin {
+		if allowed == "*" || allowed == origin || matchOriginPattern(allowed, origin) {
 			return true
 		}
 	}
@@ -143,10 +237,40 @@ func (t *StreamableHTTPTransport) isOriginAllowed(origin string) bool {
 	return false
 }
 
+// matchOriginPattern reports whether origin matches a pattern with a wildcard subdomain, such
+// as "https://*.example.com", which matches "https://api.example.com" and
+// "https://a.b.example.com" but not "https://example.com" or "http://api.example.com"
+func matchOriginPattern(pattern, origin string) bool {
+	prefix, suffix, ok := strings.Cut(pattern, "://*.")
+	if !ok {
+		return false
+	}
+	prefix += "://"
+	suffix = "." + suffix
+	if len(origin) <= len(prefix)+len(suffix) || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+	// The wildcard only stands for subdomain labels, never for a port, path or user info
+	subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(subdomain, ":/@?#")
+}
+
+// orDefault returns values, or defaults when values is empty
+func orDefault(values, defaults []string) []string {
+	if len(values) == 0 {
+		return defaults
+	}
+	return values
+}
+
 // handleMCP handles MCP requests according to the streamable HTTP specification
 // This is the main entry point for all MCP protocol interactions
 // Supports both POST (JSON-RPC) and GET (SSE stream establishment) methods
 func (t *StreamableHTTPTransport) handleMCP(w http.ResponseWriter, r *http.Request) {
+	trustedProxies, _ := t.clientPolicy()
+	client := clientIP(r, trustedProxies)
+	r = r.WithContext(context.WithValue(r.Context(), clientIPContextKey{}, client))
+
 	// Step 1: Check for MCP Protocol Version header (warn if missing)
 	protocolVersion := r.Header.Get("MCP-Protocol-Version")
 	if protocolVersion == "" {
@@ -154,12 +278,41 @@ func (t *StreamableHTTPTransport) handleMCP(w http.ResponseWriter, r *http.Reque
 		// Continue processing without the header
 	}
 
-	// Step 2: Handle optional session management
+	// Step 2: Authenticate the client when token validation is configured
+	if validator := t.tokenValidator(); validator != nil {
+		claims, err := t.authenticate(r, validator)
+		if err != nil {
+			log.Printf("Rejected unauthenticated request to %s from %s: %v", r.URL.Path, client, err)
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), claimsContextKey{}, claims))
+	}
+	if authenticator, realm := t.basicAuth(); authenticator != nil {
+		username, password, ok := r.BasicAuth()
+		if !ok || !authenticator.Authenticate(username, password) {
+			log.Printf("Rejected unauthenticated request to %s from %s: invalid basic auth credentials", r.URL.Path, client)
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Basic realm=%q, charset="UTF-8"`, realm))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		// The user is exposed to header rules like the subject of a token
+		r = r.WithContext(context.WithValue(r.Context(), claimsContextKey{}, map[string]interface{}{"sub": username}))
+	}
+
+	// Step 3: Handle optional session management
 	// Sessions provide state continuity across multiple requests
 	sessionID := r.Header.Get("Mcp-Session-Id")
 	if sessionID != "" {
 		// Validate session exists and hasn't expired
-		if !t.isValidSession(sessionID) {
+		valid, err := t.isValidSession(sessionID)
+		if err != nil {
+			log.Printf("Failed to load session %s: %v", sessionID, err)
+			http.Error(w, "Session store unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if !valid {
 			http.Error(w, "Invalid or expired session", http.StatusUnauthorized)
 			return
 		}
@@ -167,7 +320,7 @@ func (t *StreamableHTTPTransport) handleMCP(w http.ResponseWriter, r *http.Reque
 		t.updateSessionActivity(sessionID)
 	}
 
-	// Step 3: Route based on HTTP method
+	// Step 4: Route based on HTTP method
 	switch r.Method {
 	case http.MethodPost:
 		// Handle JSON-RPC requests (with optional SSE streaming)
@@ -181,6 +334,77 @@ func (t *StreamableHTTPTransport) handleMCP(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+// authenticate extracts the bearer token from the request and validates it
+func (t *StreamableHTTPTransport) authenticate(r *http.Request, validator TokenValidator) (map[string]interface{}, error) {
+	authorization := r.Header.Get("Authorization")
+	const prefix = "bearer "
+	if len(authorization) <= len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return nil, fmt.Errorf("missing bearer token")
+	}
+	return validator.Validate(strings.TrimSpace(authorization[len(prefix):]))
+}
+
+// tokenValidator returns the current token validator, or nil when token checks are disabled
+func (t *StreamableHTTPTransport) tokenValidator() TokenValidator {
+	t.configMux.RLock()
+	defer t.configMux.RUnlock()
+	return t.config.TokenValidator
+}
+
+// SetTokenValidator replaces the token validator used for new requests
+// Existing sessions are kept; pass nil to disable token checks
+func (t *StreamableHTTPTransport) SetTokenValidator(validator TokenValidator) {
+	t.configMux.Lock()
+	defer t.configMux.Unlock()
+	t.config.TokenValidator = validator
+}
+
+// basicAuth returns the current Basic auth checker and realm, or nil when Basic auth is disabled
+func (t *StreamableHTTPTransport) basicAuth() (BasicAuthenticator, string) {
+	t.configMux.RLock()
+	defer t.configMux.RUnlock()
+	return t.config.BasicAuth, t.config.BasicAuthRealm
+}
+
+// SetBasicAuth replaces the Basic auth checker and realm used for new requests; pass a nil
+// authenticator to disable Basic auth
+func (t *StreamableHTTPTransport) SetBasicAuth(authenticator BasicAuthenticator, realm string) {
+	t.configMux.Lock()
+	defer t.configMux.Unlock()
+	t.config.BasicAuth = authenticator
+	t.config.BasicAuthRealm = realm
+}
+
+// maxRequestSize returns the current request body limit, or 0 for no limit
+func (t *StreamableHTTPTransport) maxRequestSize() int64 {
+	t.configMux.RLock()
+	defer t.configMux.RUnlock()
+	return t.config.MaxRequestSize
+}
+
+// SetMaxRequestSize replaces the request body limit used for new requests; pass 0 for no limit
+func (t *StreamableHTTPTransport) SetMaxRequestSize(limit int64) {
+	t.configMux.Lock()
+	defer t.configMux.Unlock()
+	t.config.MaxRequestSize = limit
+}
+
+// clientPolicy returns the current trusted proxies and rate limiter
+func (t *StreamableHTTPTransport) clientPolicy() ([]netip.Prefix, RateLimiter) {
+	t.configMux.RLock()
+	defer t.configMux.RUnlock()
+	return t.config.TrustedProxies, t.config.RateLimiter
+}
+
+// SetClientPolicy replaces the trusted proxies and the rate limiter used for new requests;
+// pass a nil limiter to disable rate limiting
+func (t *StreamableHTTPTransport) SetClientPolicy(trustedProxies []netip.Prefix, limiter RateLimiter) {
+	t.configMux.Lock()
+	defer t.configMux.Unlock()
+	t.config.TrustedProxies = trustedProxies
+	t.config.RateLimiter = limiter
+}
+
 // handlePOST handles POST requests with JSON-RPC
 // This method processes standard MCP JSON-RPC requests and can optionally
 // stream responses via Server-Sent Events if the client accepts it
@@ -193,15 +417,24 @@ func (t *StreamableHTTPTransport) handlePOST(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Step 2: Read the JSON-RPC request from request body
+	// Step 2: Read the JSON-RPC request from request body, within the size limit
+	if limit := t.maxRequestSize(); limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("Request body exceeds the limit of %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
 		return
 	}
 	defer func() {
 		_ = r.Body.Close()
 	}()
+	t.traceMessage(traceReceived, body)
 
 	// Step 3: Parse JSON-RPC request according to MCP specification
 	var mcpReq types.MCPRequest
@@ -211,6 +444,20 @@ func (t *StreamableHTTPTransport) handlePOST(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// Tool calls are limited per client address
+	client, _ := r.Context().Value(clientIPContextKey{}).(string)
+	if _, limiter := t.clientPolicy(); limiter != nil && mcpReq.Method == "tools/call" && !limiter.Allow(client) {
+		log.Printf("Rate limited tool call from %s", client)
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	// New clients are sent elsewhere while shutting down; existing sessions carry on
+	if mcpReq.Method == "initialize" && t.draining.Load() {
+		t.rejectDraining(w)
+		return
+	}
+
 	// Step 4: Create request context for dynamic header forwarding
 	// Check if form data should be parsed based on size limits
 	var formData url.Values
@@ -242,6 +489,10 @@ func (t *StreamableHTTPTransport) handlePOST(w http.ResponseWriter, r *http.Requ
 		r.Method,
 		r.URL.Path,
 	)
+	if claims, ok := r.Context().Value(claimsContextKey{}).(map[string]interface{}); ok {
+		requestContext.Claims = claims
+	}
+	requestContext.ClientIP = client
 
 	// Step 5: Process the request through the MCP server
 	response := t.mcpServer.HandleRequest(mcpReq, requestContext)
@@ -269,8 +520,18 @@ func (t *StreamableHTTPTransport) handleGET(w http.ResponseWriter, r *http.Reque
 
 	// Create new session if not provided
 	if sessionID == "" {
-		sessionID = t.createSession()
-		log.Printf("Created new session: %s", sessionID)
+		if t.draining.Load() {
+			t.rejectDraining(w)
+			return
+		}
+		var err error
+		if sessionID, err = t.createSession(); err != nil {
+			log.Printf("Failed to create session: %v", err)
+			http.Error(w, "Session store unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		client, _ := r.Context().Value(clientIPContextKey{}).(string)
+		log.Printf("Created new session: %s for %s", sessionID, client)
 	}
 
 	// Setup SSE stream
@@ -302,7 +563,7 @@ func (t *StreamableHTTPTransport) writeSSEResponse(w http.ResponseWriter, respon
 
 	// Write SSE event
 	eventID := t.generateEventID()
-	responseJSON, err := json.Marshal(response)
+	responseJSON, release, err := encodeJSON(response)
 	if err != nil {
 		log.Printf("Failed to marshal response for session %s, event %s: %v", sessionID, eventID, err)
 		// Send error response to client
@@ -314,9 +575,11 @@ func (t *StreamableHTTPTransport) writeSSEResponse(w http.ResponseWriter, respon
 		return
 	}
 
+	t.traceMessage(traceSent, responseJSON)
 	_, _ = fmt.Fprintf(w, "id: %s\n", eventID)
 	_, _ = fmt.Fprintf(w, "event: message\n")
-	_, _ = fmt.Fprintf(w, "data: %s\n\n", responseJSON)
+	_, _ = fmt.Fprintf(w, "data: %s\n", responseJSON)
+	release()
 	flusher.Flush()
 }
 
@@ -334,6 +597,17 @@ func (t *StreamableHTTPTransport) setupSSEStream(w http.ResponseWriter, r *http.
 		return
 	}
 
+	// Receive server notifications, from before the client learns it is connected until the stream ends
+	events := make(chan []byte, streamBufferSize)
+	t.streamsMu.Lock()
+	t.streams[events] = struct{}{}
+	t.streamsMu.Unlock()
+	defer func() {
+		t.streamsMu.Lock()
+		delete(t.streams, events)
+		t.streamsMu.Unlock()
+	}()
+
 	// Send initial connection event
 	_, _ = fmt.Fprintf(w, "id: %s\n", t.generateEventID())
 	_, _ = fmt.Fprintf(w, "event: connection\n")
@@ -349,6 +623,13 @@ func (t *StreamableHTTPTransport) setupSSEStream(w http.ResponseWriter, r *http.
 		select {
 		case <-ctx.Done():
 			return
+		case <-t.stopping:
+			return
+		case data := <-events:
+			_, _ = fmt.Fprintf(w, "id: %s\n", t.generateEventID())
+			_, _ = fmt.Fprintf(w, "event: message\n")
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
 		case <-ticker.C:
 			_, _ = fmt.Fprintf(w, "id: %s\n", t.generateEventID())
 			_, _ = fmt.Fprintf(w, "event: heartbeat\n")
@@ -358,6 +639,29 @@ func (t *StreamableHTTPTransport) setupSSEStream(w http.ResponseWriter, r *http.
 	}
 }
 
+// streamBufferSize is the number of notifications an SSE stream holds while its client catches up
+const streamBufferSize = 16
+
+// broadcast sends a server notification to every open SSE stream. Streams whose buffer is
+// full miss it rather than holding up the others.
+func (t *StreamableHTTPTransport) broadcast(notification types.MCPNotification) {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		log.Printf("Failed to marshal notification %s: %v", notification.Method, err)
+		return
+	}
+	t.traceMessage(traceSent, data)
+	t.streamsMu.Lock()
+	defer t.streamsMu.Unlock()
+	for events := range t.streams {
+		select {
+		case events <- data:
+		default:
+			log.Printf("Dropped notification %s for a slow SSE stream", notification.Method)
+		}
+	}
+}
+
 // mapErrorCodeToHTTPStatus maps JSON-RPC error codes to appropriate HTTP status codes
 // This function provides semantic HTTP status mapping for both standard JSON-RPC codes
 // and application-specific error code ranges defined in protocol.go
@@ -430,9 +734,19 @@ func (t *StreamableHTTPTransport) writeJSONResponse(w http.ResponseWriter, respo
 	}
 
 	w.WriteHeader(statusCode)
+	if t.mcpServer != nil {
+		t.mcpServer.traceValue(traceSent, response)
+	}
 	_ = json.NewEncoder(w).Encode(response)
 }
 
+// traceMessage traces a message of the transport with the server's tracer
+func (t *StreamableHTTPTransport) traceMessage(direction string, data []byte) {
+	if t.mcpServer != nil {
+		t.mcpServer.traceMessage(direction, data)
+	}
+}
+
 // writeErrorResponse writes a JSON-RPC error response
 // This helper function creates properly formatted MCP error responses
 func (t *StreamableHTTPTransport) writeErrorResponse(w http.ResponseWriter, id interface{}, code int, message, data string) {
@@ -458,55 +772,64 @@ func (t *StreamableHTTPTransport) writeErrorResponse(w http.ResponseWriter, id i
 // createSession generates a new cryptographically secure session ID
 // Sessions are used to maintain state across multiple MCP requests
 // Per MCP specification, session IDs must be globally unique and secure
-func (t *StreamableHTTPTransport) createSession() string {
+func (t *StreamableHTTPTransport) createSession() (string, error) {
 	// Generate 16 random bytes for cryptographically secure session ID
 	bytes := make([]byte, 16)
 	_, _ = rand.Read(bytes)
 	sessionID := hex.EncodeToString(bytes) // Convert to hex string (32 characters)
 
-	// Store session with thread-safe access
-	t.sessionsMux.Lock()
-	defer t.sessionsMux.Unlock()
-
 	// Create new session record
-	t.sessions[sessionID] = &types.Session{
+	now := time.Now()
+	session := types.Session{
 		ID:        sessionID,
-		CreatedAt: time.Now(),
-		LastSeen:  time.Now(), // Initialize activity timestamp
-		Active:    true,       // Mark session as active
+		CreatedAt: now,
+		LastSeen:  now,  // Initialize activity timestamp
+		Active:    true, // Mark session as active
+	}
+
+	// Stateless stores hand out IDs carrying the session instead of saving it
+	if issuer, ok := t.sessions.(sessionIssuer); ok {
+		return issuer.Issue(session)
+	}
+	if err := t.sessions.Save(session); err != nil {
+		return "", err
 	}
+	return sessionID, nil
+}
 
-	return sessionID
+// Sessions returns the active sessions, oldest first
+func (t *StreamableHTTPTransport) Sessions() []types.Session {
+	sessions, err := t.sessions.List()
+	if err != nil {
+		log.Printf("Failed to list sessions: %v", err)
+		return []types.Session{}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.Before(sessions[j].CreatedAt) })
+	return sessions
 }
 
 // isValidSession checks if a session ID is valid and active
 // This validates both session existence and expiration status
-func (t *StreamableHTTPTransport) isValidSession(sessionID string) bool {
-	// Use read lock for thread-safe session access
-	t.sessionsMux.RLock()
-	defer t.sessionsMux.RUnlock()
-
+func (t *StreamableHTTPTransport) isValidSession(sessionID string) (bool, error) {
 	// Check if session exists and is marked as active
-	session, exists := t.sessions[sessionID]
-	if !exists || !session.Active {
-		return false
+	session, exists, err := t.sessions.Load(sessionID)
+	if err != nil || !exists || !session.Active {
+		return false, err
 	}
 
 	// Check if session has expired based on configured timeout
-	if time.Since(session.LastSeen) > t.config.SessionTimeout {
-		return false
-	}
-
-	return true
+	return time.Since(session.LastSeen) <= t.config.SessionTimeout, nil
 }
 
 // updateSessionActivity updates the last seen time for a session
 func (t *StreamableHTTPTransport) updateSessionActivity(sessionID string) {
-	t.sessionsMux.Lock()
-	defer t.sessionsMux.Unlock()
-
-	if session, exists := t.sessions[sessionID]; exists {
+	session, exists, err := t.sessions.Load(sessionID)
+	if err == nil && exists {
 		session.LastSeen = time.Now()
+		err = t.sessions.Save(session)
+	}
+	if err != nil {
+		log.Printf("Failed to update session %s: %v", sessionID, err)
 	}
 }
 
@@ -520,19 +843,14 @@ func (t *StreamableHTTPTransport) cleanupExpiredSessions() {
 
 	// Run cleanup loop until the transport is shut down
 	for range ticker.C {
-		// Use write lock since we'll be modifying the sessions map
-		t.sessionsMux.Lock()
-		now := time.Now()
-
-		// Check each session for expiration
-		for id, session := range t.sessions {
-			// If session hasn't been active within timeout period, remove it
-			if now.Sub(session.LastSeen) > t.config.SessionTimeout {
-				delete(t.sessions, id)
-				log.Printf("Cleaned up expired session: %s", id)
-			}
+		// Remove sessions that haven't been active within the timeout period
+		expired, err := t.sessions.DeleteIdle(time.Now().Add(-t.config.SessionTimeout))
+		if err != nil {
+			log.Printf("Failed to clean up expired sessions: %v", err)
+		}
+		for _, id := range expired {
+			log.Printf("Cleaned up expired session: %s", id)
 		}
-		t.sessionsMux.Unlock()
 	}
 }
 
@@ -554,15 +872,36 @@ func (t *StreamableHTTPTransport) generateEventID() string {
 // Start starts the HTTP server
 // This method blocks until the server shuts down or encounters an error
 func (t *StreamableHTTPTransport) Start() error {
+	if t.config.TLSCertFile != "" && t.config.TLSKeyFile != "" {
+		log.Printf("Starting MCP streamable HTTPS server on %s", t.server.Addr)
+		// ListenAndServeTLS blocks until server shutdown
+		return t.server.ListenAndServeTLS(t.config.TLSCertFile, t.config.TLSKeyFile)
+	}
+
 	log.Printf("Starting MCP streamable HTTP server on %s", t.server.Addr)
 	// ListenAndServe blocks until server shutdown
 	return t.server.ListenAndServe()
 }
 
+// Drain starts a graceful shutdown: /readyz reports the server unavailable so load balancers
+// stop routing to it, and new sessions are refused while existing ones keep working
+func (t *StreamableHTTPTransport) Drain() {
+	t.draining.Store(true)
+}
+
+// rejectDraining refuses a request that would start a session while draining
+func (t *StreamableHTTPTransport) rejectDraining(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+}
+
 // Stop gracefully shuts down the HTTP server
 // Uses context for timeout control and ensures clean shutdown of all connections
 func (t *StreamableHTTPTransport) Stop(ctx context.Context) error {
 	log.Println("Shutting down MCP streamable HTTP server...")
+	t.Drain()
+	// Open SSE streams would otherwise hold the shutdown until the deadline
+	t.stopOnce.Do(func() { close(t.stopping) })
 	// Graceful shutdown with context timeout
 	return t.server.Shutdown(ctx)
 }
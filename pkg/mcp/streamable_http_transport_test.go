@@ -1,16 +1,21 @@
 package mcp
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"net/url"
 	"strings"
 	"testing"
 	"time"
+
+	"mcpify/internal/config"
 )
 
 func TestStreamableHTTPTransport_FormSizeLimits(t *testing.T) {
@@ -262,3 +267,501 @@ func TestStreamableHTTPTransport_FormSizeLimitIntegration(t *testing.T) {
 		})
 	}
 }
+
+// staticTokenValidator accepts a single known token
+type staticTokenValidator struct {
+	token  string
+	claims map[string]interface{}
+}
+
+func (v *staticTokenValidator) Validate(token string) (map[string]interface{}, error) {
+	if token != v.token {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return v.claims, nil
+}
+
+func TestStreamableHTTPTransport_TokenValidation(t *testing.T) {
+	var seenClaims map[string]interface{}
+	mcpServer := NewServer()
+	mcpServer.RegisterTool("whoami", "Returns the caller", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			seenClaims = requestContext.Claims
+			return "ok", nil
+		})
+
+	transport := NewStreamableHTTPTransport(mcpServer, &StreamableHTTPConfig{
+		MaxFormSize: 1 << 20,
+		TokenValidator: &staticTokenValidator{
+			token:  "good-token",
+			claims: map[string]interface{}{"sub": "user-123"},
+		},
+	})
+	server := httptest.NewServer(transport.corsMiddleware(http.HandlerFunc(transport.handleMCP)))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"wrong scheme", "Basic dXNlcjpwYXNz", http.StatusUnauthorized},
+		{"invalid token", "Bearer bad-token", http.StatusUnauthorized},
+		{"valid token", "Bearer good-token", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seenClaims = nil
+			body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whoami","arguments":{}}}`
+			req, err := http.NewRequest("POST", server.URL+"/mcp", strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json")
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to send request: %v", err)
+			}
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if tt.expectedStatus == http.StatusUnauthorized {
+				if resp.Header.Get("WWW-Authenticate") == "" {
+					t.Error("Expected WWW-Authenticate header on 401 response")
+				}
+				return
+			}
+			if seenClaims["sub"] != "user-123" {
+				t.Errorf("Expected claims to reach the tool handler, got %v", seenClaims)
+			}
+		})
+	}
+}
+
+func TestStreamableHTTPTransport_Sessions(t *testing.T) {
+	transport := NewStreamableHTTPTransport(NewServer(), &StreamableHTTPConfig{})
+	if sessions := transport.Sessions(); len(sessions) != 0 {
+		t.Fatalf("Expected no sessions, got %+v", sessions)
+	}
+
+	first, err := transport.createSession()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	second, err := transport.createSession()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	session, _, _ := transport.sessions.Load(first)
+	session.CreatedAt = time.Now().Add(-time.Minute)
+	_ = transport.sessions.Save(session)
+	sessions := transport.Sessions()
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %+v", sessions)
+	}
+	if sessions[0].ID != first || sessions[1].ID != second {
+		t.Errorf("Expected sessions oldest first, got %+v", sessions)
+	}
+
+	// The returned sessions are copies
+	sessions[0].Active = false
+	if !transport.Sessions()[0].Active {
+		t.Error("Expected Sessions to return copies")
+	}
+}
+
+func TestStreamableHTTPTransport_Drain(t *testing.T) {
+	mcpServer := NewServer()
+	mcpServer.RegisterTool("ping", "Returns pong", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			return "pong", nil
+		})
+	transport := NewStreamableHTTPTransport(mcpServer, &StreamableHTTPConfig{MaxFormSize: 1 << 20})
+	mux := http.NewServeMux()
+	transport.setupRoutes(mux)
+	server := httptest.NewServer(transport.corsMiddleware(mux))
+	defer server.Close()
+
+	get := func(path string) *http.Response {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+	post := func(method string) *http.Response {
+		t.Helper()
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":{"name":"ping","arguments":{}}}`
+		req, err := http.NewRequest("POST", server.URL+"/mcp", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	if resp := get("/readyz"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected ready before draining, got %d", resp.StatusCode)
+	}
+	if resp := post("initialize"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected initialize to succeed before draining, got %d", resp.StatusCode)
+	}
+
+	transport.Drain()
+	if resp := get("/readyz"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected /readyz to fail while draining, got %d", resp.StatusCode)
+	}
+	if resp := get("/healthz"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected /healthz to pass while draining, got %d", resp.StatusCode)
+	}
+	if resp := post("initialize"); resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") == "" {
+		t.Errorf("Expected initialize to be refused while draining, got %d", resp.StatusCode)
+	}
+	if resp := post("tools/call"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected calls of existing clients to be served while draining, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableHTTPTransport_StopEndsStreams(t *testing.T) {
+	transport := NewStreamableHTTPTransport(NewServer(), &StreamableHTTPConfig{SessionTimeout: time.Minute})
+	server := httptest.NewServer(http.HandlerFunc(transport.handleMCP))
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL+"/mcp", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if err := transport.Stop(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.ReadAll(resp.Body)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the stream to end cleanly, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Stop to end open streams")
+	}
+}
+
+func TestStreamableHTTPTransport_StreamsNotifications(t *testing.T) {
+	mcpServer := NewServer()
+	transport := NewStreamableHTTPTransport(mcpServer, &StreamableHTTPConfig{SessionTimeout: time.Minute})
+	server := httptest.NewServer(http.HandlerFunc(transport.handleMCP))
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL+"/mcp", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	// Wait for the connection event, after which the stream receives notifications
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read stream: %v", err)
+		}
+		if line == "\n" {
+			break
+		}
+	}
+
+	staging := NewServer()
+	staging.RegisterTool("ping", "Pings", map[string]interface{}{"type": "object"}, nil)
+	mcpServer.ReplaceTools(staging)
+
+	lines := make(chan string)
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			lines <- line
+		}
+	}()
+	expected := `data: {"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("Expected the stream to stay open")
+			}
+			if strings.TrimSpace(line) == expected {
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected a list_changed notification on the stream")
+		}
+	}
+}
+
+func TestStreamableHTTPTransport_RequestSizeLimit(t *testing.T) {
+	mcpServer := NewServer()
+	mcpServer.RegisterTool("echo", "Echoes its arguments", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			return params, nil
+		})
+	transport := NewStreamableHTTPTransport(mcpServer, &StreamableHTTPConfig{MaxFormSize: 1 << 20, MaxRequestSize: 256})
+	server := httptest.NewServer(transport.corsMiddleware(http.HandlerFunc(transport.handleMCP)))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		text           string
+		expectedStatus int
+	}{
+		{"within limit", "hello", http.StatusOK},
+		{"over limit", strings.Repeat("x", 512), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":%q}}}`, tt.text)
+			req, err := http.NewRequest("POST", server.URL+"/mcp", strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to send request: %v", err)
+			}
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestStreamableHTTPTransport_CORS(t *testing.T) {
+	transport := NewStreamableHTTPTransport(NewServer(), &StreamableHTTPConfig{
+		MaxFormSize:     1 << 20,
+		CORSEnabled:     true,
+		CORSOrigins:     []string{"https://app.example.com", "https://*.example.org"},
+		CORSHeaders:     []string{"Content-Type", "X-Tenant"},
+		CORSExposed:     []string{"Mcp-Session-Id", "X-Request-Id"},
+		CORSCredentials: true,
+		CORSMaxAge:      10 * time.Minute,
+	})
+	handler := transport.corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://api.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"http://api.example.org", false},
+		{"https://evil.com:443.example.org", false},
+		{"https://other.example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req := httptest.NewRequest("OPTIONS", "/mcp", nil)
+			req.Header.Set("Origin", tt.origin)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			header := recorder.Header()
+			if allowed := header.Get("Access-Control-Allow-Origin") == tt.origin; allowed != tt.allowed {
+				t.Fatalf("Expected origin allowed %v, got Access-Control-Allow-Origin %q", tt.allowed, header.Get("Access-Control-Allow-Origin"))
+			}
+			if tt.allowed && header.Get("Access-Control-Allow-Credentials") != "true" {
+				t.Error("Expected credentials to be allowed")
+			}
+			if got := header.Get("Access-Control-Allow-Headers"); got != "Content-Type, X-Tenant" {
+				t.Errorf("Unexpected allowed headers %q", got)
+			}
+			if got := header.Get("Access-Control-Allow-Methods"); got != "GET, POST, OPTIONS" {
+				t.Errorf("Expected default methods, got %q", got)
+			}
+			if got := header.Get("Access-Control-Expose-Headers"); got != "Mcp-Session-Id, X-Request-Id" {
+				t.Errorf("Unexpected exposed headers %q", got)
+			}
+			if got := header.Get("Access-Control-Max-Age"); got != "600" {
+				t.Errorf("Expected max age 600, got %q", got)
+			}
+		})
+	}
+}
+
+// countingLimiter allows a number of requests per key
+type countingLimiter struct {
+	limit int
+	seen  map[string]int
+}
+
+func (l *countingLimiter) Allow(key string) bool {
+	l.seen[key]++
+	return l.seen[key] <= l.limit
+}
+
+func TestStreamableHTTPTransport_RateLimitPerClient(t *testing.T) {
+	var seenClient string
+	mcpServer := NewServer()
+	mcpServer.RegisterTool("whoami", "Returns the caller", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			seenClient = requestContext.ClientIP
+			return "ok", nil
+		})
+	limiter := &countingLimiter{limit: 1, seen: make(map[string]int)}
+	transport := NewStreamableHTTPTransport(mcpServer, &StreamableHTTPConfig{
+		MaxFormSize:    1 << 20,
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")},
+		RateLimiter:    limiter,
+	})
+	server := httptest.NewServer(transport.corsMiddleware(http.HandlerFunc(transport.handleMCP)))
+	defer server.Close()
+
+	call := func(client string) int {
+		body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whoami","arguments":{}}}`
+		req, err := http.NewRequest("POST", server.URL+"/mcp", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("X-Forwarded-For", client)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := call("198.51.100.1"); status != http.StatusOK {
+		t.Fatalf("Expected first call to succeed, got %d", status)
+	}
+	if seenClient != "198.51.100.1" {
+		t.Errorf("Expected client 198.51.100.1 in the request context, got %q", seenClient)
+	}
+	if status := call("198.51.100.1"); status != http.StatusTooManyRequests {
+		t.Errorf("Expected second call from the same client to be limited, got %d", status)
+	}
+	if status := call("198.51.100.2"); status != http.StatusOK {
+		t.Errorf("Expected call from another client to succeed, got %d", status)
+	}
+}
+
+// staticBasicAuthenticator accepts a single username and password
+type staticBasicAuthenticator struct {
+	username, password string
+}
+
+func (a *staticBasicAuthenticator) Authenticate(username, password string) bool {
+	return username == a.username && password == a.password
+}
+
+func TestStreamableHTTPTransport_BasicAuth(t *testing.T) {
+	var seenClaims map[string]interface{}
+	mcpServer := NewServer()
+	mcpServer.RegisterTool("whoami", "Returns the caller", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			seenClaims = requestContext.Claims
+			return "ok", nil
+		})
+	transport := NewStreamableHTTPTransport(mcpServer, &StreamableHTTPConfig{
+		MaxFormSize:    1 << 20,
+		BasicAuth:      &staticBasicAuthenticator{username: "alice", password: "secret"},
+		BasicAuthRealm: "internal",
+	})
+	server := httptest.NewServer(transport.corsMiddleware(http.HandlerFunc(transport.handleMCP)))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		expectedStatus int
+	}{
+		{"missing credentials", "", "", http.StatusUnauthorized},
+		{"wrong password", "alice", "wrong", http.StatusUnauthorized},
+		{"valid credentials", "alice", "secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seenClaims = nil
+			body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whoami","arguments":{}}}`
+			req, err := http.NewRequest("POST", server.URL+"/mcp", strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json")
+			if tt.username != "" {
+				req.SetBasicAuth(tt.username, tt.password)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to send request: %v", err)
+			}
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if tt.expectedStatus == http.StatusUnauthorized {
+				if got := resp.Header.Get("WWW-Authenticate"); got != `Basic realm="internal", charset="UTF-8"` {
+					t.Errorf("Unexpected challenge %q", got)
+				}
+				return
+			}
+			if seenClaims["sub"] != "alice" {
+				t.Errorf("Expected the user as subject, got %v", seenClaims)
+			}
+		})
+	}
+}
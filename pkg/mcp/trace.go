@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+// Directions of traced messages
+const (
+	traceReceived = "received"
+	traceSent     = "sent"
+)
+
+// Tracer writes every JSON-RPC message a server receives or sends, pretty-printed, with the
+// values of sensitive keys and known secrets redacted. It is separate from the debug logging
+// of upstream requests and meant for diagnosing protocol mismatches with clients.
+type Tracer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	secrets []string
+	now     func() time.Time
+}
+
+// NewTracer creates a tracer writing to w and replacing each of secrets wherever it appears
+func NewTracer(w io.Writer, secrets []string) *Tracer {
+	var known []string
+	for _, secret := range secrets {
+		// Short values would redact unrelated text
+		if len(secret) >= 4 {
+			known = append(known, secret)
+		}
+	}
+	return &Tracer{w: w, secrets: known, now: time.Now}
+}
+
+// trace writes one message. Messages that are not valid JSON, like the bodies of parse
+// errors, are written as received.
+func (t *Tracer) trace(direction string, data []byte) {
+	text := string(bytes.TrimSpace(data))
+	var message interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if decoder.Decode(&message) == nil {
+		if pretty, err := json.MarshalIndent(redactMessage(message), "", "  "); err == nil {
+			text = string(pretty)
+		}
+	}
+	for _, secret := range t.secrets {
+		text = strings.ReplaceAll(text, secret, config.Redacted)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintf(t.w, "%s %s\n%s\n", t.now().UTC().Format(time.RFC3339Nano), direction, text)
+}
+
+// redactMessage returns a copy of a decoded message with the values of sensitive keys redacted,
+// including those of JSON text in strings, such as the text content of tool results
+func redactMessage(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		trimmed := strings.TrimSpace(v)
+		if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+			return v
+		}
+		var embedded interface{}
+		decoder := json.NewDecoder(strings.NewReader(trimmed))
+		decoder.UseNumber()
+		if decoder.Decode(&embedded) != nil {
+			return v
+		}
+		redacted := redactMessage(embedded)
+		if reflect.DeepEqual(redacted, embedded) {
+			return v
+		}
+		data, err := json.Marshal(redacted)
+		if err != nil {
+			return v
+		}
+		return string(data)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if isSensitiveKey(key) && item != nil {
+				redacted[key] = config.Redacted
+				continue
+			}
+			redacted[key] = redactMessage(item)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactMessage(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// isSensitiveKey reports whether an object key names a credential
+func isSensitiveKey(key string) bool {
+	return config.IsSensitiveName(key)
+}
+
+// SetTracer traces the messages of every transport of the server with tracer, or stops
+// tracing when it is nil. Set it before starting the transports.
+func (s *Server) SetTracer(tracer *Tracer) {
+	s.tracer = tracer
+}
+
+// traceMessage traces an encoded message when a tracer is set
+func (s *Server) traceMessage(direction string, data []byte) {
+	if s.tracer != nil {
+		s.tracer.trace(direction, data)
+	}
+}
+
+// traceValue traces a message before it is encoded, encoding it only when a tracer is set
+func (s *Server) traceValue(direction string, message interface{}) {
+	if s.tracer == nil {
+		return
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	s.tracer.trace(direction, data)
+}
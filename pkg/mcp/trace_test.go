@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpify/internal/config"
+)
+
+func TestTracer(t *testing.T) {
+	var out bytes.Buffer
+	tracer := NewTracer(&out, []string{"s3cret-value", "ab"})
+	tracer.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	tracer.trace(traceReceived, []byte(`{"jsonrpc":"2.0","id":1,"params":{"arguments":{"Api-Key":"k-123","note":"uses s3cret-value","about":"ab"}}}`))
+	tracer.trace(traceSent, []byte(`{"result":{"content":[{"type":"text","text":"{\"password\":\"hunter22\",\"count\":2}"}]}}`))
+	tracer.trace(traceReceived, []byte("not json with s3cret-value\n"))
+
+	expected := `2025-01-02T03:04:05Z received
+{
+  "id": 1,
+  "jsonrpc": "2.0",
+  "params": {
+    "arguments": {
+      "Api-Key": "[REDACTED]",
+      "about": "ab",
+      "note": "uses [REDACTED]"
+    }
+  }
+}
+2025-01-02T03:04:05Z sent
+{
+  "result": {
+    "content": [
+      {
+        "text": "{\"count\":2,\"password\":\"[REDACTED]\"}",
+        "type": "text"
+      }
+    ]
+  }
+}
+2025-01-02T03:04:05Z received
+not json with [REDACTED]
+`
+	if out.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, out.String())
+	}
+}
+
+func TestStreamableHTTPTransport_Trace(t *testing.T) {
+	var out bytes.Buffer
+	mcpServer := NewServer()
+	mcpServer.SetTracer(NewTracer(&out, nil))
+	mcpServer.RegisterTool("echo", "Echoes its arguments", map[string]interface{}{"type": "object"},
+		func(params map[string]interface{}, requestContext config.RequestContext) (interface{}, error) {
+			return params, nil
+		})
+	transport := NewStreamableHTTPTransport(mcpServer, &StreamableHTTPConfig{MaxFormSize: 1 << 20})
+	server := httptest.NewServer(http.HandlerFunc(transport.handleMCP))
+	defer server.Close()
+
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"token":"t-1"}}}`,
+		`{"jsonrpc":`,
+	} {
+		req, err := http.NewRequest("POST", server.URL+"/mcp", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	trace := out.String()
+	if strings.Count(trace, " received\n") != 2 || strings.Count(trace, " sent\n") != 2 {
+		t.Errorf("Expected both requests and responses traced, got\n%s", trace)
+	}
+	if strings.Contains(trace, "t-1") {
+		t.Errorf("Expected the token redacted, got\n%s", trace)
+	}
+	if !strings.Contains(trace, "Invalid JSON-RPC request") {
+		t.Errorf("Expected the parse error traced, got\n%s", trace)
+	}
+}